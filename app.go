@@ -10,6 +10,7 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"github.com/peternagy/mongopal/internal/auth"
+	"github.com/peternagy/mongopal/internal/changestream"
 	"github.com/peternagy/mongopal/internal/connection"
 	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/credential"
@@ -17,13 +18,18 @@ import (
 	"github.com/peternagy/mongopal/internal/debug"
 	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/export"
+	"github.com/peternagy/mongopal/internal/generator"
+	"github.com/peternagy/mongopal/internal/gridfs"
 	"github.com/peternagy/mongopal/internal/importer"
+	"github.com/peternagy/mongopal/internal/monitoring"
 	"github.com/peternagy/mongopal/internal/performance"
 	"github.com/peternagy/mongopal/internal/schema"
 	"github.com/peternagy/mongopal/internal/script"
+	"github.com/peternagy/mongopal/internal/sqlquery"
 	"github.com/peternagy/mongopal/internal/storage"
 	"github.com/peternagy/mongopal/internal/theme"
 	"github.com/peternagy/mongopal/internal/types"
+	"github.com/peternagy/mongopal/internal/users"
 )
 
 // =============================================================================
@@ -35,6 +41,8 @@ type SavedConnection = types.SavedConnection
 type ExtendedConnection = types.ExtendedConnection
 type ConnectionInfo = types.ConnectionInfo
 type ConnectionStatus = types.ConnectionStatus
+type CredentialStoreStatus = types.CredentialStoreStatus
+type ConnectionImportResult = types.ConnectionImportResult
 type TestConnectionResult = types.TestConnectionResult
 type ConnectionShareResult = types.ConnectionShareResult
 type BulkConnectionShareResult = types.BulkConnectionShareResult
@@ -43,13 +51,69 @@ type DatabaseInfo = types.DatabaseInfo
 type CollectionInfo = types.CollectionInfo
 type CollectionExportInfo = types.CollectionExportInfo
 type CollectionStats = types.CollectionStats
+type CollectionValidation = types.CollectionValidation
+type CompactResult = types.CompactResult
+type CompactProgress = types.CompactProgress
+type DatabaseStats = types.DatabaseStats
+type ConvertToCappedResult = types.ConvertToCappedResult
+type TTLExpiryPreview = types.TTLExpiryPreview
+type TTLIndexPreview = types.TTLIndexPreview
+type ShardingInfo = types.ShardingInfo
+type BalancerStatus = types.BalancerStatus
+type BalancerWindow = types.BalancerWindow
+type ChunkMigration = types.ChunkMigration
+type ShardChunks = types.ShardChunks
+type ClusterTopology = types.ClusterTopology
+type ClusterReplSet = types.ClusterReplSet
+type ClusterShard = types.ClusterShard
+type ClusterRouter = types.ClusterRouter
 type IndexInfo = types.IndexInfo
 type IndexOptions = types.IndexOptions
+type IndexBuildProgress = types.IndexBuildProgress
+type IndexSuggestion = types.IndexSuggestion
+type IndexSuggestionResult = types.IndexSuggestionResult
 type ExplainResult = types.ExplainResult
+type BenchmarkResult = types.BenchmarkResult
 type QueryPlannerResult = types.QueryPlannerResult
 type ExecutionStatsResult = types.ExecutionStatsResult
 type QueryOptions = types.QueryOptions
+type WriteConcern = types.WriteConcern
 type QueryResult = types.QueryResult
+type QueryCount = types.QueryCount
+type DeleteManyResult = types.DeleteManyResult
+type DeleteProgress = types.DeleteProgress
+type DeleteDone = types.DeleteDone
+type InsertManyResult = types.InsertManyResult
+type DocumentError = types.DocumentError
+type FieldDiff = types.FieldDiff
+type DocumentDiff = types.DocumentDiff
+type DuplicateGroup = types.DuplicateGroup
+type DeleteDuplicatesResult = types.DeleteDuplicatesResult
+type DocumentSize = types.DocumentSize
+type SizeBucket = types.SizeBucket
+type DocumentSizeReport = types.DocumentSizeReport
+type FieldGap = types.FieldGap
+type MissingFieldsReport = types.MissingFieldsReport
+type BinaryFieldPreview = types.BinaryFieldPreview
+type TransformResult = types.TransformResult
+type TransformError = types.TransformError
+type TransformPreview = types.TransformPreview
+type TransactionResult = types.TransactionResult
+type MigrationResult = types.MigrationResult
+type MigrationProgress = types.MigrationProgress
+type ConversionFailure = types.ConversionFailure
+type FieldConversionPreview = types.FieldConversionPreview
+type FieldConversionResult = types.FieldConversionResult
+type CopyDocumentsOptions = types.CopyDocumentsOptions
+type CopyDocumentsResult = types.CopyDocumentsResult
+type CopyCollectionResult = types.CopyCollectionResult
+type CopyProgress = types.CopyProgress
+type CopyDatabaseOptions = types.CopyDatabaseOptions
+type CopyDatabaseResult = types.CopyDatabaseResult
+type CopyDatabaseCollection = types.CopyDatabaseCollection
+type CopyDatabaseProgress = types.CopyDatabaseProgress
+type GenerateResult = types.GenerateResult
+type GenerateProgress = types.GenerateProgress
 type SchemaField = types.SchemaField
 type SchemaResult = types.SchemaResult
 type DocumentExportEntry = types.DocumentExportEntry
@@ -83,17 +147,77 @@ type ArchivePreview = types.ArchivePreview
 type ArchivePreviewDatabase = types.ArchivePreviewDatabase
 type ArchivePreviewCollection = types.ArchivePreviewCollection
 type SavedQuery = types.SavedQuery
+type SavedPipeline = types.SavedPipeline
+type DocumentTemplate = types.DocumentTemplate
+type QueryParameter = types.QueryParameter
+type QueryHistoryEntry = types.QueryHistoryEntry
+type TrashEntry = types.TrashEntry
+type TrashRestoreResult = types.TrashRestoreResult
+type SchemaViolation = types.SchemaViolation
+type InvalidDocument = types.InvalidDocument
+type DocumentValidationResult = types.DocumentValidationResult
+type GridFSFile = types.GridFSFile
+type GridFSPreview = types.GridFSPreview
+type ChangeStreamEvent = types.ChangeStreamEvent
+type TailBatch = types.TailBatch
+type DistinctValuesResult = types.DistinctValuesResult
+type SearchOptions = types.SearchOptions
+type SearchResult = types.SearchResult
+type SearchCapabilities = types.SearchCapabilities
+type GeoQuery = types.GeoQuery
+type GeoPointsResult = types.GeoPointsResult
+type SQLTranslation = types.SQLTranslation
+type AggregateOptions = types.AggregateOptions
+type AggregationResult = types.AggregationResult
+type LintIssue = types.LintIssue
+type LintResult = types.LintResult
 type CollectionProfile = types.CollectionProfile
+type ProfilerStatus = types.ProfilerStatus
+type ProfiledOperation = types.ProfiledOperation
 type ServerInfo = types.ServerInfo
+type FeatureCompatibility = types.FeatureCompatibility
+type ReplicaConsistencyReport = types.ReplicaConsistencyReport
+type ReplSetConfig = types.ReplSetConfig
+type ReplSetMemberConfig = types.ReplSetMemberConfig
 type ServerHostInfo = types.ServerHostInfo
 type ServerStatusInfo = types.ServerStatusInfo
 type ReplicaSetInfo = types.ReplicaSetInfo
 type ReplicaSetMember = types.ReplicaSetMember
+type ReplicationLag = types.ReplicationLag
+type MemberLag = types.MemberLag
 type PerformanceMetrics = performance.Metrics
 type Theme = types.Theme
 type ThemeColors = types.ThemeColors
 type ThemeFonts = types.ThemeFonts
 type ThemeConfig = types.ThemeConfig
+type AppSettings = types.AppSettings
+type ConnectionHealth = types.ConnectionHealth
+type AutoConnectStatus = types.AutoConnectStatus
+type PoolStats = types.PoolStats
+type ServerPoolStats = types.ServerPoolStats
+type MetricsSample = types.MetricsSample
+type AlertRule = types.AlertRule
+type Alert = types.Alert
+type OpcounterRates = types.OpcounterRates
+type ServerConnections = types.ServerConnections
+type ServerMemory = types.ServerMemory
+type NetworkRates = types.NetworkRates
+type CurrentOpFilter = types.CurrentOpFilter
+type CurrentOperation = types.CurrentOperation
+type ServerLogFilter = types.ServerLogFilter
+type ServerLog = types.ServerLog
+type ServerLogEntry = types.ServerLogEntry
+type HotCollectionReport = types.HotCollectionReport
+type HotCollection = types.HotCollection
+type DestructiveConfirmation = types.DestructiveConfirmation
+type RoleRef = types.RoleRef
+type AuthenticationRestriction = types.AuthenticationRestriction
+type DatabaseUser = types.DatabaseUser
+type NewDatabaseUser = types.NewDatabaseUser
+type PrivilegeResource = types.PrivilegeResource
+type Privilege = types.Privilege
+type DatabaseRole = types.DatabaseRole
+type NewDatabaseRole = types.NewDatabaseRole
 
 // =============================================================================
 // App - Thin Facade for Wails Bindings
@@ -110,14 +234,23 @@ type App struct {
 	querySvc         *storage.QueryService
 	favoriteSvc      *storage.FavoriteService
 	dbMetaSvc        *storage.DatabaseMetadataService
+	settingsSvc      *storage.SettingsService
+	historySvc       *storage.QueryHistoryService
+	trashSvc         *storage.TrashService
+	alertSvc         *storage.AlertRuleService
 	connection       *connection.Service
 	database         *database.Service
 	document         *document.Service
+	generator        *generator.Service
+	gridfs           *gridfs.Service
 	schema           *schema.Service
 	export           *export.Service
 	importer         *importer.Service
 	script           *script.Service
 	performance      *performance.Service
+	changeStream     *changestream.Service
+	monitoring       *monitoring.Service
+	users            *users.Service
 	auth             *auth.Service
 	theme            *theme.ThemeManager
 }
@@ -166,9 +299,11 @@ func (a *App) startup(ctx context.Context) {
 	// Initialize connection service with encrypted storage
 	a.connStore = storage.NewConnectionService(a.state, a.storage, encStorage)
 
-	// Load connections from encrypted storage
-	if err := a.connStore.LoadAllConnections(); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to load connections: %v\n", err)
+	// Load connections from encrypted storage; with a credential vault, that waits for UnlockVault
+	if !credential.VaultExists(encStorage.VaultPath()) {
+		if err := a.connStore.LoadAllConnections(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to load connections: %v\n", err)
+		}
 	}
 
 	// Initialize all other services
@@ -176,20 +311,34 @@ func (a *App) startup(ctx context.Context) {
 	a.querySvc = storage.NewQueryService(configDir)
 	a.favoriteSvc = storage.NewFavoriteService(configDir)
 	a.dbMetaSvc = storage.NewDatabaseMetadataService(configDir)
-	a.connLifecycle = storage.NewConnectionLifecycle(a.connStore, a.favoriteSvc, a.dbMetaSvc, a.querySvc)
+	a.settingsSvc = storage.NewSettingsService(a.state, configDir)
+	a.historySvc = storage.NewQueryHistoryService(configDir)
+	a.trashSvc = storage.NewTrashService(configDir)
+	a.alertSvc = storage.NewAlertRuleService(configDir)
+	a.connLifecycle = storage.NewConnectionLifecycle(a.connStore, a.favoriteSvc, a.dbMetaSvc, a.querySvc, a.historySvc, a.trashSvc, a.alertSvc)
 	a.connection = connection.NewService(a.state, a.connStore)
+	a.connection.StartHealthMonitor()
+	go a.connection.AutoConnect()
 	a.database = database.NewService(a.state)
 	a.document = document.NewService(a.state)
+	a.document.SetArchiver(a.trashSvc)
+	a.generator = generator.NewService(a.state)
+	a.gridfs = gridfs.NewService(a.state)
 	a.schema = schema.NewService(a.state)
 	a.export = export.NewService(a.state, a.connStore)
 	a.importer = importer.NewService(a.state, a.connStore)
-	a.script = script.NewService(a.connStore)
+	a.script = script.NewService(a.state, a.connStore)
 	a.performance = performance.NewService(a.state)
+	a.changeStream = changestream.NewService(a.state)
+	a.monitoring = monitoring.NewService(a.state, a.alertSvc)
+	a.users = users.NewService(a.state)
 	a.theme = theme.NewThemeManager(a.state, configDir)
 }
 
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
+	a.changeStream.StopAll()
+	a.monitoring.StopAll()
 	a.connection.Shutdown(ctx)
 }
 
@@ -202,10 +351,14 @@ func (a *App) Connect(connID string) error {
 }
 
 func (a *App) Disconnect(connID string) error {
+	a.changeStream.StopWatchesForConnection(connID)
+	a.monitoring.StopMetrics(connID)
 	return a.connection.Disconnect(connID)
 }
 
 func (a *App) DisconnectAll() error {
+	a.changeStream.StopAll()
+	a.monitoring.StopAll()
 	return a.connection.DisconnectAll()
 }
 
@@ -217,10 +370,50 @@ func (a *App) TestConnection(uri string, connID string) (*TestConnectionResult,
 	return a.connection.TestConnection(uri)
 }
 
+// IsKerberosSupported reports whether this build can authenticate with Kerberos (GSSAPI),
+// so the connection form only offers it when it can work.
+func (a *App) IsKerberosSupported() bool {
+	return connection.KerberosSupported()
+}
+
+// TestConnectionWithSettings tests a URI with the TLS settings of a connection form. For
+// saved connections, stored passwords the form doesn't hold are filled back in.
+func (a *App) TestConnectionWithSettings(uri string, conn ExtendedConnection) (*TestConnectionResult, error) {
+	if conn.ID != "" {
+		uri = a.connStore.MergeStoredCredentials(conn.ID, uri)
+		if stored, err := a.connStore.GetExtendedConnection(conn.ID); err == nil && conn.TLSKeyPassword == "" {
+			conn.TLSKeyPassword = stored.TLSKeyPassword
+		}
+	}
+	return a.connection.TestConnectionWithSettings(uri, conn)
+}
+
 func (a *App) GetConnectionStatus(connID string) ConnectionStatus {
 	return a.connection.GetConnectionStatus(connID)
 }
 
+// GetConnectionHealth returns the latest background health check of a connection.
+func (a *App) GetConnectionHealth(connID string) (*ConnectionHealth, error) {
+	return a.connection.GetConnectionHealth(connID)
+}
+
+// GetAutoConnectStatus returns the status of each connection auto-connected on startup.
+// Progress is also emitted as "connection:autoconnect" events.
+func (a *App) GetAutoConnectStatus() []AutoConnectStatus {
+	return a.connection.GetAutoConnectStatus()
+}
+
+// GetPoolStats returns the driver connection pool statistics of a connected client.
+func (a *App) GetPoolStats(connID string) (*PoolStats, error) {
+	return a.connection.GetPoolStats(connID)
+}
+
+// KillSessions ends the app's server sessions on a connection, killing their cursors and
+// running operations. Returns the number of sessions killed.
+func (a *App) KillSessions(connID string) (int, error) {
+	return a.connection.KillSessions(connID)
+}
+
 func (a *App) GetConnectionInfo(connID string) ConnectionInfo {
 	return a.connection.GetConnectionInfo(connID)
 }
@@ -229,6 +422,55 @@ func (a *App) GetServerInfo(connID string) (*ServerInfo, error) {
 	return a.connection.GetServerInfo(connID)
 }
 
+// GetReplicationLag samples the replication lag of each replica set member. Lagging members
+// are also reported as a "replication:lag-warning" event.
+func (a *App) GetReplicationLag(connID string) (*ReplicationLag, error) {
+	return a.connection.GetReplicationLag(connID)
+}
+
+// GetReplicationLagHistory returns the recent replication lag samples of a connection.
+func (a *App) GetReplicationLagHistory(connID string) []ReplicationLag {
+	return a.connection.GetReplicationLagHistory(connID)
+}
+
+// GetFCV returns the feature compatibility version of a connection's deployment.
+func (a *App) GetFCV(connID string) (*FeatureCompatibility, error) {
+	return a.connection.GetFCV(connID)
+}
+
+// SetFCV sets the feature compatibility version. On connections with safety settings it
+// needs a confirmed "set feature compatibility version" token for the version.
+func (a *App) SetFCV(connID, version string) error {
+	return a.connection.SetFCV(connID, version)
+}
+
+// GetReplSetConfig returns the replica set configuration of a connection's deployment.
+func (a *App) GetReplSetConfig(connID string) (*ReplSetConfig, error) {
+	return a.connection.GetReplSetConfig(connID)
+}
+
+// AddReplSetMember adds a member to the replica set.
+func (a *App) AddReplSetMember(connID string, member ReplSetMemberConfig) error {
+	return a.connection.AddReplSetMember(connID, member)
+}
+
+// RemoveReplSetMember removes a secondary or arbiter from the replica set. On connections
+// with safety settings it needs a confirmed "remove replica set member" token for the host.
+func (a *App) RemoveReplSetMember(connID, host string) error {
+	return a.connection.RemoveReplSetMember(connID, host)
+}
+
+// SetMemberPriority changes the election priority of a replica set member.
+func (a *App) SetMemberPriority(connID, host string, priority float64) error {
+	return a.connection.SetMemberPriority(connID, host, priority)
+}
+
+// CompareReplicaMembers compares the dbHash of a database across the replica set members
+// and reports the collections that differ.
+func (a *App) CompareReplicaMembers(connID, dbName string) (*ReplicaConsistencyReport, error) {
+	return a.connection.CompareReplicaMembers(connID, dbName)
+}
+
 // =============================================================================
 // Storage - Connection Methods
 // =============================================================================
@@ -237,6 +479,12 @@ func (a *App) SaveExtendedConnection(conn ExtendedConnection) error {
 	return a.connStore.SaveExtendedConnection(conn)
 }
 
+// ResetSSHHostKey clears the pinned SSH host key of a connection, so the next connect pins the
+// key the server presents. Use it after the SSH server's host key was legitimately replaced.
+func (a *App) ResetSSHHostKey(connID string) error {
+	return a.connStore.SetSSHHostKey(connID, "")
+}
+
 func (a *App) GetExtendedConnection(connID string) (ExtendedConnection, error) {
 	return a.connStore.GetExtendedConnection(connID)
 }
@@ -245,6 +493,11 @@ func (a *App) ListSavedConnections() ([]SavedConnection, error) {
 	return a.connStore.ListSavedConnections()
 }
 
+// ListRecentConnections returns up to limit connections most recently connected to, newest first.
+func (a *App) ListRecentConnections(limit int) []SavedConnection {
+	return a.connStore.ListRecentConnections(limit)
+}
+
 func (a *App) GetSavedConnection(connID string) (SavedConnection, error) {
 	return a.connStore.GetSavedConnection(connID)
 }
@@ -257,6 +510,56 @@ func (a *App) DuplicateConnection(connID, newName string) (SavedConnection, erro
 	return a.connStore.DuplicateConnection(connID, newName)
 }
 
+// CleanupOrphanedCredentials deletes keyring entries for connections that no longer exist.
+func (a *App) CleanupOrphanedCredentials() (int, error) {
+	return a.connLifecycle.CleanupOrphanedCredentials()
+}
+
+// GetCredentialStoreStatus reports whether connection keys are kept in the OS keyring or
+// the encrypted credential vault, and whether the vault still needs its master password.
+func (a *App) GetCredentialStoreStatus() CredentialStoreStatus {
+	usingVault := a.encryptedStorage.UsingVault()
+	vaultExists := credential.VaultExists(a.encryptedStorage.VaultPath())
+	status := CredentialStoreStatus{
+		Backend:          "keyring",
+		KeyringAvailable: credential.KeyringAvailable(),
+		VaultExists:      vaultExists,
+		VaultLocked:      vaultExists && !usingVault,
+	}
+	if usingVault {
+		status.Backend = "vault"
+	}
+	return status
+}
+
+// CreateCredentialVault creates the encrypted credential vault for systems without an OS
+// keyring and moves existing connection keys into it. Returns the number of keys moved.
+func (a *App) CreateCredentialVault(masterPassword string) (int, error) {
+	moved, err := a.encryptedStorage.CreateVault(masterPassword)
+	if err != nil {
+		return 0, err
+	}
+	return moved, a.connStore.LoadAllConnections()
+}
+
+// UnlockCredentialVault unlocks the credential vault and loads the saved connections.
+func (a *App) UnlockCredentialVault(masterPassword string) error {
+	if err := a.encryptedStorage.UnlockVault(masterPassword); err != nil {
+		return err
+	}
+	if err := a.connStore.LoadAllConnections(); err != nil {
+		return err
+	}
+	go a.connection.AutoConnect()
+	return nil
+}
+
+// MigrateCredentialsToKeyring moves connection keys from the credential vault back to the
+// OS keyring and removes the vault. Returns the number of keys moved.
+func (a *App) MigrateCredentialsToKeyring() (int, error) {
+	return a.encryptedStorage.MigrateToKeyring()
+}
+
 // resolveFolderPath builds the folder name path (e.g. ["Work", "Backend"]) for a given folder ID.
 func (a *App) resolveFolderPath(folderID string) []string {
 	if folderID == "" {
@@ -334,10 +637,91 @@ func (a *App) DecryptConnectionImport(bundleJSON string, key string) (string, er
 	return string(data), nil
 }
 
+// ImportConnectionBundle decrypts a shared connection bundle and saves the connection,
+// recreating its folder path.
+func (a *App) ImportConnectionBundle(bundleJSON, key string) (*ConnectionImportResult, error) {
+	return a.connStore.ImportConnectionBundle(bundleJSON, key)
+}
+
+// ImportConnectionBundles imports the connections of a bulk share. An empty key uses the key
+// included in the share.
+func (a *App) ImportConnectionBundles(bulkJSON, key string) (*ConnectionImportResult, error) {
+	return a.connStore.ImportConnectionBundles(bulkJSON, key)
+}
+
+// ExportAllConnections saves every connection, including its credentials, SSH keys, and
+// TLS material, to a backup file encrypted with passphrase. Requires OS authentication.
+// Returns the path written, or "" if the save dialog was cancelled.
+func (a *App) ExportAllConnections(passphrase string) (string, error) {
+	if err := a.auth.Authenticate("MongoPal needs to verify your identity to export credentials"); err != nil {
+		return "", fmt.Errorf("authentication required to export credentials")
+	}
+
+	filePath, err := runtime.SaveFileDialog(a.state.Ctx, runtime.SaveDialogOptions{
+		DefaultFilename: fmt.Sprintf("mongopal-connections-%s.mpbackup", time.Now().Format("2006-01-02")),
+		Title:           "Save Connection Backup",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "MongoPal Backups (*.mpbackup)", Pattern: "*.mpbackup"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if filePath == "" {
+		return "", nil // User cancelled
+	}
+
+	if _, err := a.connStore.ExportAllConnections(filePath, passphrase); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// RestoreConnectionBackup restores the connections of a backup file chosen in an open
+// dialog. Returns nil if the dialog was cancelled.
+func (a *App) RestoreConnectionBackup(passphrase string) (*ConnectionImportResult, error) {
+	filePath, err := runtime.OpenFileDialog(a.state.Ctx, runtime.OpenDialogOptions{
+		Title: "Restore Connection Backup",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "MongoPal Backups (*.mpbackup)", Pattern: "*.mpbackup"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file dialog: %w", err)
+	}
+	if filePath == "" {
+		return nil, nil // User cancelled
+	}
+	return a.connStore.RestoreConnectionBackup(filePath, passphrase)
+}
+
+// ImportFromCompass imports connections from a MongoDB Compass export file (or, with an
+// empty path, from Compass's own connection store), decrypting passwords with passphrase.
+func (a *App) ImportFromCompass(path, passphrase string) (*ConnectionImportResult, error) {
+	return a.connStore.ImportFromCompass(path, passphrase)
+}
+
+// ImportFromStudio3T imports connections from a Studio 3T connection export file.
+func (a *App) ImportFromStudio3T(path string) (*ConnectionImportResult, error) {
+	return a.connStore.ImportFromStudio3T(path)
+}
+
+// ImportFromRobo3T imports connections from a Robo 3T robo3t.json file, or with an empty
+// path, from the local Robo 3T installation.
+func (a *App) ImportFromRobo3T(path string) (*ConnectionImportResult, error) {
+	return a.connStore.ImportFromRobo3T(path)
+}
+
 func (a *App) ConnectionToURI(connID string) (string, error) {
 	return a.connStore.ConnectionToURI(connID)
 }
 
+// BuildURIFromFormData builds the MongoDB URI of connection form data (JSON), without the
+// password, as it is built at connect time.
+func (a *App) BuildURIFromFormData(formJSON string) (string, error) {
+	return credential.BuildURIFromFormJSON(formJSON, "")
+}
+
 func (a *App) ConnectionFromURI(uri string) (SavedConnection, error) {
 	return a.connStore.ConnectionFromURI(uri)
 }
@@ -417,18 +801,129 @@ func (a *App) ListIndexes(connID, dbName, collName string) ([]IndexInfo, error)
 	return a.database.ListIndexes(connID, dbName, collName)
 }
 
-func (a *App) CreateIndex(connID, dbName, collName string, keys map[string]int, opts IndexOptions) error {
-	return a.database.CreateIndex(connID, dbName, collName, keys, opts)
+// CreateIndex creates an index from an Extended JSON key specification and returns its name.
+func (a *App) CreateIndex(connID, dbName, collName, keysJSON string, opts IndexOptions) (string, error) {
+	return a.database.CreateIndex(connID, dbName, collName, keysJSON, opts)
 }
 
 func (a *App) DropIndex(connID, dbName, collName, indexName string) error {
 	return a.database.DropIndex(connID, dbName, collName, indexName)
 }
 
+// ModifyCollection runs collMod with options given as Extended JSON.
+func (a *App) ModifyCollection(connID, dbName, collName, collModJSON string) error {
+	return a.database.ModifyCollection(connID, dbName, collName, collModJSON)
+}
+
+// SetValidator sets, replaces, or (with empty validatorJSON) removes a collection's validator.
+func (a *App) SetValidator(connID, dbName, collName, validatorJSON, validationLevel, validationAction string) error {
+	return a.database.SetValidator(connID, dbName, collName, validatorJSON, validationLevel, validationAction)
+}
+
+// SetIndexTTL changes the expireAfterSeconds of an existing TTL index.
+func (a *App) SetIndexTTL(connID, dbName, collName, indexName string, expireAfterSeconds int64) error {
+	return a.database.SetIndexTTL(connID, dbName, collName, indexName, expireAfterSeconds)
+}
+
+// PreviewTTLExpiry counts the documents each TTL index of a collection has left past due or
+// will remove within the next hour and day.
+func (a *App) PreviewTTLExpiry(connID, dbName, collName string) (*TTLExpiryPreview, error) {
+	return a.database.PreviewTTLExpiry(connID, dbName, collName)
+}
+
+// HideIndex hides an index from the query planner without dropping it.
+func (a *App) HideIndex(connID, dbName, collName, indexName string) error {
+	return a.database.HideIndex(connID, dbName, collName, indexName)
+}
+
+// UnhideIndex makes a hidden index visible to the query planner again.
+func (a *App) UnhideIndex(connID, dbName, collName, indexName string) error {
+	return a.database.UnhideIndex(connID, dbName, collName, indexName)
+}
+
+func (a *App) GetSearchCapabilities(connID, dbName, collName string) (*SearchCapabilities, error) {
+	return a.database.GetSearchCapabilities(connID, dbName, collName)
+}
+
+// RequestDestructiveConfirmation issues a confirmation token for a destructive operation
+// ("drop database", "drop collection", "clear collection", "delete many", "override import",
+// or "restore with drop") on a connection with safety settings.
+func (a *App) RequestDestructiveConfirmation(connID, operation, target string) DestructiveConfirmation {
+	return a.state.RequestConfirmation(connID, operation, target)
+}
+
+// ConfirmDestructiveOperation confirms a token once its delay has passed. The operation it was
+// issued for can then run once.
+func (a *App) ConfirmDestructiveOperation(token, typed string) error {
+	return a.state.ConfirmOperation(token, typed)
+}
+
 func (a *App) DropDatabase(connID, dbName string) error {
 	return a.database.DropDatabase(connID, dbName)
 }
 
+// CreateDatabase creates a database together with its first collection.
+func (a *App) CreateDatabase(connID, dbName, initialCollection string) error {
+	return a.database.CreateDatabase(connID, dbName, initialCollection)
+}
+
+// CreateCollection creates a collection with create command options given as Extended JSON
+// (capped, collation, validator, clusteredIndex, timeseries, ...).
+func (a *App) CreateCollection(connID, dbName, collName, optionsJSON string) error {
+	return a.database.CreateCollection(connID, dbName, collName, optionsJSON)
+}
+
+// RenameCollection renames a collection, optionally replacing an existing target.
+func (a *App) RenameCollection(connID, dbName, oldName, newName string, dropTarget bool) error {
+	return a.database.RenameCollection(connID, dbName, oldName, newName, dropTarget)
+}
+
+// CopyCollection copies a collection, its options, and optionally its indexes to a new
+// "db.collection" namespace on the same connection.
+func (a *App) CopyCollection(connID, sourceNS, targetNS string, includeIndexes bool) (*CopyCollectionResult, error) {
+	return a.document.CopyCollection(connID, sourceNS, targetNS, includeIndexes)
+}
+
+// CloneCollectionStructure creates an empty copy of a collection with its options and
+// indexes, and returns the number of indexes built.
+func (a *App) CloneCollectionStructure(connID, sourceNS, targetNS string) (int, error) {
+	return a.document.CloneCollectionStructure(connID, sourceNS, targetNS)
+}
+
+// CopyDatabase streams the collections, options, and optionally indexes of a database to a
+// database on the same or another connection.
+func (a *App) CopyDatabase(sourceConnID, sourceDB, targetConnID, targetDB string, opts CopyDatabaseOptions) (*CopyDatabaseResult, error) {
+	return a.document.CopyDatabase(sourceConnID, sourceDB, targetConnID, targetDB, opts)
+}
+
+// ValidateCollection runs the validate command; full also checks storage structures but
+// locks the collection while it runs.
+func (a *App) ValidateCollection(connID, dbName, collName string, full bool) (*CollectionValidation, error) {
+	return a.database.ValidateCollection(connID, dbName, collName, full)
+}
+
+// CompactCollection releases unused disk space of a collection on the connected member.
+// force allows running on a busy replica set primary.
+func (a *App) CompactCollection(connID, dbName, collName string, force bool) (*CompactResult, error) {
+	return a.database.CompactCollection(connID, dbName, collName, force)
+}
+
+// ConvertToCapped converts a collection to a capped collection; dryRun only reports the
+// current size, the documents expected to remain, and the indexes that would be dropped.
+func (a *App) ConvertToCapped(connID, dbName, collName string, sizeBytes, maxDocs int64, dryRun bool) (*ConvertToCappedResult, error) {
+	return a.database.ConvertToCapped(connID, dbName, collName, sizeBytes, maxDocs, dryRun)
+}
+
+// CreateView creates a view on viewOn defined by an Extended JSON pipeline.
+func (a *App) CreateView(connID, dbName, viewName, viewOn, pipelineJSON string) error {
+	return a.database.CreateView(connID, dbName, viewName, viewOn, pipelineJSON)
+}
+
+// UpdateViewPipeline replaces the pipeline of an existing view.
+func (a *App) UpdateViewPipeline(connID, dbName, viewName, pipelineJSON string) error {
+	return a.database.UpdateViewPipeline(connID, dbName, viewName, pipelineJSON)
+}
+
 func (a *App) DropCollection(connID, dbName, collName string) error {
 	return a.database.DropCollection(connID, dbName, collName)
 }
@@ -449,12 +944,86 @@ func (a *App) GetCollectionStats(connID, dbName, collName string) (*CollectionSt
 	return a.database.GetCollectionStats(connID, dbName, collName)
 }
 
+// GetDatabaseStats returns database totals and a per-collection size breakdown.
+func (a *App) GetDatabaseStats(connID, dbName string) (*DatabaseStats, error) {
+	return a.database.GetDatabaseStats(connID, dbName)
+}
+
+// GetShardingInfo returns the shard key, chunk distribution, and balancer state of a collection.
+func (a *App) GetShardingInfo(connID, dbName, collName string) (*ShardingInfo, error) {
+	return a.database.GetShardingInfo(connID, dbName, collName)
+}
+
+// EnableSharding enables sharding for a database (required before MongoDB 6.0).
+func (a *App) EnableSharding(connID, dbName string) error {
+	return a.database.EnableSharding(connID, dbName)
+}
+
+// ShardCollection shards a collection on an Extended JSON shard key after checking that an
+// index supports it.
+func (a *App) ShardCollection(connID, dbName, collName, shardKeyJSON string, unique bool) error {
+	return a.database.ShardCollection(connID, dbName, collName, shardKeyJSON, unique)
+}
+
+// GetClusterTopology returns the shards, config servers, and mongos routers of the sharded
+// cluster a mongos connection is routed through.
+func (a *App) GetClusterTopology(connID string) (*ClusterTopology, error) {
+	return a.database.GetClusterTopology(connID)
+}
+
+// GetBalancerStatus returns the balancer state, active window, and recent chunk migrations.
+func (a *App) GetBalancerStatus(connID string) (*BalancerStatus, error) {
+	return a.database.GetBalancerStatus(connID)
+}
+
+// SetBalancerEnabled starts or stops the balancer.
+func (a *App) SetBalancerEnabled(connID string, enabled bool) error {
+	return a.database.SetBalancerEnabled(connID, enabled)
+}
+
+// SetBalancerWindow limits chunk migrations to a daily "HH:MM" window. Empty start and stop
+// remove the window.
+func (a *App) SetBalancerWindow(connID, start, stop string) error {
+	return a.database.SetBalancerWindow(connID, start, stop)
+}
+
 func (a *App) GetCollectionProfile(connID, dbName, collName string) (*CollectionProfile, error) {
 	return a.database.GetCollectionProfile(connID, dbName, collName)
 }
 
-func (a *App) ExplainQuery(connID, dbName, collName, filter string) (*ExplainResult, error) {
-	return a.database.ExplainQuery(connID, dbName, collName, filter)
+// GetProfilerStatus returns the profiler level and slow operation threshold of a database.
+func (a *App) GetProfilerStatus(connID, dbName string) (*ProfilerStatus, error) {
+	return a.database.GetProfilerStatus(connID, dbName)
+}
+
+// SetProfilerLevel sets the profiler level (0 off, 1 slow, 2 all) of a database. A negative
+// slowms keeps the current threshold.
+func (a *App) SetProfilerLevel(connID, dbName string, level int, slowms int64) error {
+	return a.database.SetProfilerLevel(connID, dbName, level, slowms)
+}
+
+// ListProfiledOperations returns recent operations from a database's system.profile,
+// newest first. filter is an optional Extended JSON query.
+func (a *App) ListProfiledOperations(connID, dbName, filter string, limit int64) ([]ProfiledOperation, error) {
+	return a.database.ListProfiledOperations(connID, dbName, filter, limit)
+}
+
+// RunCommand runs a server command given as Extended JSON on a database and returns the
+// full reply as Extended JSON. Read-only connections only run commands that don't write,
+// and commands that take servers down or have a dedicated operation are refused.
+func (a *App) RunCommand(connID, dbName, commandJSON string) (string, error) {
+	return a.database.RunCommand(connID, dbName, commandJSON)
+}
+
+// ExplainQuery explains a find query. verbosity is "queryPlanner", "executionStats"
+// (default when empty), or "allPlansExecution".
+func (a *App) ExplainQuery(connID, dbName, collName, filter string, opts QueryOptions, verbosity string) (*ExplainResult, error) {
+	return a.database.ExplainQuery(connID, dbName, collName, filter, opts, verbosity)
+}
+
+// BenchmarkQuery runs a query repeatedly and reports latency percentiles and plan statistics.
+func (a *App) BenchmarkQuery(connID, dbName, collName, filter string, iterations int) (*BenchmarkResult, error) {
+	return a.database.BenchmarkQuery(connID, dbName, collName, filter, iterations)
 }
 
 // =============================================================================
@@ -462,29 +1031,434 @@ func (a *App) ExplainQuery(connID, dbName, collName, filter string) (*ExplainRes
 // =============================================================================
 
 func (a *App) FindDocuments(connID, dbName, collName, query string, opts QueryOptions) (*QueryResult, error) {
-	return a.document.FindDocuments(connID, dbName, collName, query, opts)
+	result, err := a.document.FindDocuments(connID, dbName, collName, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record in query history (ignore errors, history is secondary)
+	if a.historySvc != nil {
+		_ = a.historySvc.RecordQuery(QueryHistoryEntry{
+			ConnectionID: connID,
+			Database:     dbName,
+			Collection:   collName,
+			Filter:       query,
+			Sort:         opts.Sort,
+			Projection:   opts.Projection,
+			QueryTimeMs:  result.QueryTimeMs,
+		})
+	}
+
+	return result, nil
+}
+
+// GetQueryCount returns the background count of a FindDocuments result's CountID, or nil
+// while it is still counting. It covers a "query:count" event emitted before the listener
+// knew the CountID.
+func (a *App) GetQueryCount(countID string) *QueryCount {
+	return a.document.GetQueryCount(countID)
 }
 
 func (a *App) GetDocument(connID, dbName, collName, docID string) (string, error) {
 	return a.document.GetDocument(connID, dbName, collName, docID)
 }
 
-func (a *App) UpdateDocument(connID, dbName, collName, docID, jsonDoc string) error {
-	return a.document.UpdateDocument(connID, dbName, collName, docID, jsonDoc)
+// UpdateDocument replaces a document. With upsert set, a document with a new _id is created
+// instead of failing with "document not found". A nil writeConcern keeps the connection's.
+func (a *App) UpdateDocument(connID, dbName, collName, docID, jsonDoc string, upsert bool, writeConcern *WriteConcern) error {
+	return a.document.UpdateDocument(connID, dbName, collName, docID, jsonDoc, upsert, writeConcern)
+}
+
+// PatchDocument applies {"$set": {...}, "$unset": [...]} to only the changed paths of a
+// document, leaving fields edited by other clients untouched.
+func (a *App) PatchDocument(connID, dbName, collName, docID, patchJSON string, upsert bool, writeConcern *WriteConcern) error {
+	return a.document.PatchDocument(connID, dbName, collName, docID, patchJSON, upsert, writeConcern)
+}
+
+// DiffDocuments returns a field-level diff between two Extended JSON documents.
+func (a *App) DiffDocuments(docA, docB string) (*DocumentDiff, error) {
+	return document.DiffDocuments(docA, docB)
+}
+
+// DiffDocumentWithServer diffs the stored document against a locally edited version, for a
+// confirmation view before saving. ns is "database.collection".
+func (a *App) DiffDocumentWithServer(connID, ns, docID, localJSON string) (*DocumentDiff, error) {
+	return a.document.DiffDocumentWithServer(connID, ns, docID, localJSON)
+}
+
+func (a *App) InsertDocument(connID, dbName, collName, jsonDoc string, writeConcern *WriteConcern) (string, error) {
+	return a.document.InsertDocument(connID, dbName, collName, jsonDoc, writeConcern)
+}
+
+// InsertDocumentFull inserts a document and returns the stored document as Extended JSON.
+func (a *App) InsertDocumentFull(connID, dbName, collName, jsonDoc string, writeConcern *WriteConcern) (string, error) {
+	return a.document.InsertDocumentFull(connID, dbName, collName, jsonDoc, writeConcern)
+}
+
+// InsertManyDocuments inserts a JSON array or newline-delimited documents, reporting the
+// inserted IDs and any per-document errors.
+func (a *App) InsertManyDocuments(connID, dbName, collName, documents string, ordered bool, writeConcern *WriteConcern) (*InsertManyResult, error) {
+	return a.document.InsertManyDocuments(connID, dbName, collName, documents, ordered, writeConcern)
+}
+
+// DeleteManyDocuments deletes documents matching filter. Call with dryRun first to get the
+// match count and a sample for confirmation. A real delete returns its operation ID at once
+// and runs in the background, reporting "delete:progress" events and a final "delete:done".
+func (a *App) DeleteManyDocuments(connID, dbName, collName, filter string, dryRun bool, writeConcern *WriteConcern) (*DeleteManyResult, error) {
+	return a.document.DeleteManyDocuments(connID, dbName, collName, filter, dryRun, writeConcern)
+}
+
+// CopyDocuments copies documents matching filter between "db.collection" namespaces, possibly
+// across connections. Progress is reported as "copy:progress" events.
+func (a *App) CopyDocuments(sourceConnID, sourceNS, targetConnID, targetNS, filter string, opts CopyDocumentsOptions) (*CopyDocumentsResult, error) {
+	return a.document.CopyDocuments(sourceConnID, sourceNS, targetConnID, targetNS, filter, opts)
+}
+
+// RenameField renames a field in every document matching filter. Call with dryRun first to
+// get the number of affected documents; progress is reported as "migration:progress" events.
+func (a *App) RenameField(connID, dbName, collName, oldPath, newPath, filter string, dryRun bool) (*MigrationResult, error) {
+	return a.document.RenameField(connID, dbName, collName, oldPath, newPath, filter, dryRun)
+}
+
+// PreviewFieldConversion counts the values of a field that can and can't be converted from
+// fromType to toType, with a sample of the failures.
+func (a *App) PreviewFieldConversion(connID, dbName, collName, fieldPath, fromType, toType string) (*FieldConversionPreview, error) {
+	return a.document.PreviewFieldConversion(connID, dbName, collName, fieldPath, fromType, toType)
+}
+
+// ConvertFieldType converts a field's fromType values to toType across a collection, leaving
+// values that can't be converted unchanged and listing them in the result.
+func (a *App) ConvertFieldType(connID, dbName, collName, fieldPath, fromType, toType string) (*FieldConversionResult, error) {
+	return a.document.ConvertFieldType(connID, dbName, collName, fieldPath, fromType, toType)
+}
+
+// FindDuplicates returns groups of documents sharing the same values for fields, largest first.
+func (a *App) FindDuplicates(connID, dbName, collName string, fields []string, limit int) ([]DuplicateGroup, error) {
+	return a.document.FindDuplicates(connID, dbName, collName, fields, limit)
+}
+
+// DeleteDuplicates keeps one document ("first" or "newest" by _id) per duplicate group and
+// deletes the rest.
+func (a *App) DeleteDuplicates(connID, dbName, collName string, fields []string, keep string) (*DeleteDuplicatesResult, error) {
+	return a.document.DeleteDuplicates(connID, dbName, collName, fields, keep)
+}
+
+// AnalyzeDocumentSizes reports the topN largest documents in a collection and a histogram of
+// document sizes, to find documents approaching the 16MB limit.
+func (a *App) AnalyzeDocumentSizes(connID, dbName, collName string, topN int) (*DocumentSizeReport, error) {
+	return a.document.AnalyzeDocumentSizes(connID, dbName, collName, topN)
+}
+
+// FindMissingFields counts documents where a field is missing, null, or an empty string,
+// with sample _ids for each.
+func (a *App) FindMissingFields(connID, dbName, collName, fieldPath string) (*MissingFieldsReport, error) {
+	return a.document.FindMissingFields(connID, dbName, collName, fieldPath)
+}
+
+// TransformDocuments runs a JavaScript function body over each document matching filter (via
+// mongosh) and writes back the documents it changes. Call with dryRun first for a preview;
+// progress is reported as "migration:progress" events.
+func (a *App) TransformDocuments(connID, dbName, collName, filter, transformScript string, dryRun bool) (*TransformResult, error) {
+	return a.document.TransformDocuments(connID, dbName, collName, filter, transformScript, dryRun)
+}
+
+func (a *App) DeleteDocument(connID, dbName, collName, docID string, writeConcern *WriteConcern) error {
+	return a.document.DeleteDocument(connID, dbName, collName, docID, writeConcern)
+}
+
+// BeginTransaction starts a transaction on a replica set or sharded cluster and returns a
+// session token. Disconnecting aborts it and emits "transaction:aborted".
+func (a *App) BeginTransaction(connID string) (string, error) {
+	return a.document.BeginTransaction(connID)
+}
+
+// InsertInTransaction inserts a document inside the transaction identified by token.
+func (a *App) InsertInTransaction(token, dbName, collName, jsonDoc string) (string, error) {
+	return a.document.InsertInTransaction(token, dbName, collName, jsonDoc)
+}
+
+// UpdateInTransaction replaces a document inside the transaction identified by token.
+func (a *App) UpdateInTransaction(token, dbName, collName, docID, jsonDoc string) error {
+	return a.document.UpdateInTransaction(token, dbName, collName, docID, jsonDoc)
+}
+
+// DeleteInTransaction deletes a document inside the transaction identified by token.
+func (a *App) DeleteInTransaction(token, dbName, collName, docID string) error {
+	return a.document.DeleteInTransaction(token, dbName, collName, docID)
+}
+
+// CommitTransaction applies every write made in the transaction atomically.
+func (a *App) CommitTransaction(token string) (*TransactionResult, error) {
+	return a.document.CommitTransaction(token)
+}
+
+// AbortTransaction discards every write made in the transaction.
+func (a *App) AbortTransaction(token string) error {
+	return a.document.AbortTransaction(token)
+}
+
+// StreamDocuments runs a query in the background, delivering results as "query:batch"
+// events followed by "query:done". Returns a query ID for CancelQuery.
+func (a *App) StreamDocuments(connID, dbName, collName, query string, opts QueryOptions, batchSize int) (string, error) {
+	return a.document.StreamDocuments(connID, dbName, collName, query, opts, batchSize)
+}
+
+// CancelQuery stops a streaming query, or a bulk delete using its operation ID.
+func (a *App) CancelQuery(queryID string) error {
+	return a.document.CancelQuery(queryID)
+}
+
+// SearchDocuments runs a full-text search ($text or Atlas Search) and returns scored hits.
+func (a *App) SearchDocuments(connID, dbName, collName, text string, opts SearchOptions) (*SearchResult, error) {
+	return a.document.SearchDocuments(connID, dbName, collName, text, opts)
+}
+
+// BuildGeoQuery builds a $near, $geoWithin, or $geoIntersects filter as Extended JSON.
+func (a *App) BuildGeoQuery(query GeoQuery) (string, error) {
+	return document.BuildGeoQuery(query)
 }
 
-func (a *App) InsertDocument(connID, dbName, collName, jsonDoc string) (string, error) {
-	return a.document.InsertDocument(connID, dbName, collName, jsonDoc)
+// PreviewBinaryField returns the sniffed content type and a base64 snippet of a binary field,
+// for rendering images and PDFs stored in documents. ns is "database.collection".
+func (a *App) PreviewBinaryField(connID, ns, docID, fieldPath string) (*BinaryFieldPreview, error) {
+	return a.document.PreviewBinaryField(connID, ns, docID, fieldPath)
 }
 
-func (a *App) DeleteDocument(connID, dbName, collName, docID string) error {
-	return a.document.DeleteDocument(connID, dbName, collName, docID)
+// ExtractBinaryField saves a binary field to savePath, or to a location chosen in the save
+// dialog when savePath is empty. Returns the path written, or "" if cancelled.
+func (a *App) ExtractBinaryField(connID, ns, docID, fieldPath, savePath string) (string, error) {
+	return a.document.ExtractBinaryField(connID, ns, docID, fieldPath, savePath)
+}
+
+// FormatUUID renders a binary value (Extended JSON) as a UUID string, decoding legacy subtype 3
+// values with the given representation ("pythonLegacy", "javaLegacy", or "csharpLegacy").
+func (a *App) FormatUUID(binaryJSON, representation string) (string, error) {
+	return document.FormatUUID(binaryJSON, representation)
+}
+
+// UUIDToExtJSON converts a UUID string to binary Extended JSON ("standard" subtype 4, or a
+// legacy subtype 3 representation) for use in queries.
+func (a *App) UUIDToExtJSON(uuid, representation string) (string, error) {
+	return document.UUIDToExtJSON(uuid, representation)
+}
+
+// GetGeoPoints returns document locations as a GeoJSON FeatureCollection for map display.
+func (a *App) GetGeoPoints(connID, dbName, collName, filter, geoField string, limit int, maxTimeMS int64) (*GeoPointsResult, error) {
+	return a.document.GetGeoPoints(connID, dbName, collName, filter, geoField, limit, maxTimeMS)
+}
+
+// TranslateSQL converts a simple SQL SELECT statement into a MongoDB query or pipeline.
+func (a *App) TranslateSQL(sql string) (*SQLTranslation, error) {
+	return sqlquery.Translate(sql)
+}
+
+// DistinctValues returns the distinct values of a field with per-value document counts.
+func (a *App) DistinctValues(connID, dbName, collName, field, filter string, limit int, maxTimeMS int64) (*DistinctValuesResult, error) {
+	return a.document.DistinctValues(connID, dbName, collName, field, filter, limit, maxTimeMS)
+}
+
+// RunAggregation runs an aggregation pipeline (Extended JSON array of stages).
+// opts can route the read to a secondary or set a read concern without changing the URI.
+func (a *App) RunAggregation(connID, dbName, collName, pipeline string, opts AggregateOptions) (*AggregationResult, error) {
+	return a.document.RunAggregation(connID, dbName, collName, pipeline, opts)
 }
 
 func (a *App) ValidateJSON(jsonStr string) error {
 	return document.ValidateJSON(jsonStr)
 }
 
+// SuggestIndexes proposes indexes for a collection from its query history, profiled slow
+// queries, and explain output.
+func (a *App) SuggestIndexes(connID, dbName, collName string) (*IndexSuggestionResult, error) {
+	var history []QueryHistoryEntry
+	if a.historySvc != nil {
+		history = a.historySvc.ListHistory(connID, dbName, collName, 0)
+	}
+	return a.database.SuggestIndexes(connID, dbName, collName, history)
+}
+
+// LintQuery checks a filter, projection, and sort for common mistakes without a connection.
+func (a *App) LintQuery(filter, projection, sort string) *LintResult {
+	return document.LintQuery(filter, projection, sort)
+}
+
+// LintQueryForCollection lints a query and also warns when it cannot use the collection's indexes.
+func (a *App) LintQueryForCollection(connID, dbName, collName, filter, projection, sort string) (*LintResult, error) {
+	return a.database.LintQuery(connID, dbName, collName, filter, projection, sort)
+}
+
+// =============================================================================
+// GridFS Methods
+// =============================================================================
+
+// ListGridFSBuckets returns the GridFS bucket names in a database.
+func (a *App) ListGridFSBuckets(connID, dbName string) ([]string, error) {
+	return a.gridfs.ListBuckets(connID, dbName)
+}
+
+// ListGridFSFiles returns files in a bucket, newest first, optionally filtered by a query on
+// the files collection.
+func (a *App) ListGridFSFiles(connID, dbName, bucketName, filter string, limit int) ([]GridFSFile, error) {
+	return a.gridfs.ListFiles(connID, dbName, bucketName, filter, limit)
+}
+
+// UploadGridFSFile picks a local file with the native dialog and uploads it to a bucket.
+func (a *App) UploadGridFSFile(connID, dbName, bucketName, metadataJSON string) (*GridFSFile, error) {
+	return a.gridfs.UploadFile(connID, dbName, bucketName, metadataJSON)
+}
+
+// DownloadGridFSFile saves a file from a bucket to a location picked with the native dialog.
+func (a *App) DownloadGridFSFile(connID, dbName, bucketName, fileID string) (string, error) {
+	return a.gridfs.DownloadFile(connID, dbName, bucketName, fileID)
+}
+
+// PreviewGridFSFile returns the first part of a file as text or base64.
+func (a *App) PreviewGridFSFile(connID, dbName, bucketName, fileID string) (*GridFSPreview, error) {
+	return a.gridfs.PreviewFile(connID, dbName, bucketName, fileID)
+}
+
+// DeleteGridFSFile removes a file and its chunks from a bucket.
+func (a *App) DeleteGridFSFile(connID, dbName, bucketName, fileID string) error {
+	return a.gridfs.DeleteFile(connID, dbName, bucketName, fileID)
+}
+
+// =============================================================================
+// Change Stream Methods
+// =============================================================================
+
+// WatchCollection opens a change stream on a collection and returns a watch ID.
+// Changes are delivered as "changestream:event" events. pipelineJSON is an optional
+// Extended JSON array of aggregation stages used to filter the stream.
+func (a *App) WatchCollection(connID, dbName, collName, pipelineJSON string) (string, error) {
+	return a.changeStream.WatchCollection(connID, dbName, collName, pipelineJSON)
+}
+
+// StopWatch stops a change stream started by WatchCollection.
+func (a *App) StopWatch(watchID string) error {
+	return a.changeStream.StopWatch(watchID)
+}
+
+// TailCollection follows new documents in a capped collection and returns a tail ID.
+// Documents are delivered as "tail:documents" events.
+func (a *App) TailCollection(connID, dbName, collName string) (string, error) {
+	return a.changeStream.TailCollection(connID, dbName, collName)
+}
+
+// StopTail stops a tail started by TailCollection.
+func (a *App) StopTail(tailID string) error {
+	return a.changeStream.StopTail(tailID)
+}
+
+// =============================================================================
+// Monitoring Methods
+// =============================================================================
+
+// StartMetrics samples serverStatus of a connection every intervalSeconds (5 if 0) and
+// delivers each sample as a "metrics:sample" event. A running poller is replaced.
+func (a *App) StartMetrics(connID string, intervalSeconds int) error {
+	return a.monitoring.StartMetrics(connID, intervalSeconds)
+}
+
+// StopMetrics stops the metrics poller of a connection.
+func (a *App) StopMetrics(connID string) {
+	a.monitoring.StopMetrics(connID)
+}
+
+// GetMetricsHistory returns the recent metrics samples of a connection, oldest first.
+func (a *App) GetMetricsHistory(connID string) []MetricsSample {
+	return a.monitoring.GetMetricsHistory(connID)
+}
+
+// GetAlertRules returns the alert rules of a connection.
+func (a *App) GetAlertRules(connID string) []AlertRule {
+	return a.alertSvc.GetAlertRules(connID)
+}
+
+// SetAlertRules replaces the alert rules of a connection. Rules are checked on every metrics
+// sample; crossing a threshold emits "alert:triggered", going back emits "alert:resolved".
+func (a *App) SetAlertRules(connID string, rules []AlertRule) error {
+	return a.alertSvc.SetAlertRules(connID, rules)
+}
+
+// GetActiveAlerts returns the unresolved alerts of a connection, oldest first.
+func (a *App) GetActiveAlerts(connID string) []Alert {
+	return a.monitoring.GetActiveAlerts(connID)
+}
+
+// ListCurrentOperations returns the operations in progress on a connection's server,
+// longest running first.
+func (a *App) ListCurrentOperations(connID string, filter CurrentOpFilter) ([]CurrentOperation, error) {
+	return a.monitoring.ListCurrentOperations(connID, filter)
+}
+
+// KillOperation kills an operation in progress by its opId.
+func (a *App) KillOperation(connID, opID string) error {
+	return a.monitoring.KillOperation(connID, opID)
+}
+
+// GetServerLog returns the last tail entries of a server's in-memory log ("global" or
+// "startupWarnings") that match filter.
+func (a *App) GetServerLog(connID, logType string, tail int, filter ServerLogFilter) (*ServerLog, error) {
+	return a.monitoring.GetServerLog(connID, logType, tail, filter)
+}
+
+// GetHotCollections returns the server time spent per collection since the previous call,
+// busiest first.
+func (a *App) GetHotCollections(connID string) (*HotCollectionReport, error) {
+	return a.monitoring.GetHotCollections(connID)
+}
+
+// =============================================================================
+// User and Role Management Methods
+// =============================================================================
+
+// ListUsers returns the users of a database with their roles and authentication
+// restrictions. An empty dbName lists the users of all databases.
+func (a *App) ListUsers(connID, dbName string) ([]DatabaseUser, error) {
+	return a.users.ListUsers(connID, dbName)
+}
+
+// CreateUser creates a user in a database.
+func (a *App) CreateUser(connID, dbName string, user NewDatabaseUser) error {
+	return a.users.CreateUser(connID, dbName, user)
+}
+
+// UpdateUserRoles replaces the roles of a user.
+func (a *App) UpdateUserRoles(connID, dbName, username string, roles []RoleRef) error {
+	return a.users.UpdateUserRoles(connID, dbName, username, roles)
+}
+
+// ChangeUserPassword sets a new password for a user.
+func (a *App) ChangeUserPassword(connID, dbName, username, password string) error {
+	return a.users.ChangeUserPassword(connID, dbName, username, password)
+}
+
+// DropUser removes a user from a database.
+func (a *App) DropUser(connID, dbName, username string) error {
+	return a.users.DropUser(connID, dbName, username)
+}
+
+// ListRoles returns the roles of a database with their own and inherited privileges.
+// Built-in roles are included with showBuiltin.
+func (a *App) ListRoles(connID, dbName string, showBuiltin bool) ([]DatabaseRole, error) {
+	return a.users.ListRoles(connID, dbName, showBuiltin)
+}
+
+// CreateRole creates a custom role in a database.
+func (a *App) CreateRole(connID, dbName string, role NewDatabaseRole) error {
+	return a.users.CreateRole(connID, dbName, role)
+}
+
+// UpdateRolePrivileges replaces the privileges of a custom role.
+func (a *App) UpdateRolePrivileges(connID, dbName, roleName string, privileges []Privilege) error {
+	return a.users.UpdateRolePrivileges(connID, dbName, roleName, privileges)
+}
+
+// DropRole removes a custom role from a database.
+func (a *App) DropRole(connID, dbName, roleName string) error {
+	return a.users.DropRole(connID, dbName, roleName)
+}
+
 // =============================================================================
 // Schema Methods
 // =============================================================================
@@ -497,6 +1471,28 @@ func (a *App) ExportSchemaAsJSON(jsonContent, defaultFilename string) error {
 	return schema.ExportSchemaAsJSON(a.state.Ctx, jsonContent, defaultFilename)
 }
 
+// ValidateCollectionDocuments checks a sample of documents against the collection's validator
+// and returns those that would be rejected, with the rules they violate.
+func (a *App) ValidateCollectionDocuments(connID, dbName, collName string, sampleSize int) (*DocumentValidationResult, error) {
+	return a.schema.ValidateCollectionDocuments(connID, dbName, collName, sampleSize)
+}
+
+// =============================================================================
+// Data Generation Methods
+// =============================================================================
+
+// GenerateDocuments seeds a collection with count documents built from a template with
+// faker-style tokens like {{name}}, {{email}}, and {{int 1 100}}. Progress is reported as
+// "generate:progress" events.
+func (a *App) GenerateDocuments(connID, dbName, collName, templateJSON string, count int) (*GenerateResult, error) {
+	return a.generator.GenerateDocuments(connID, dbName, collName, templateJSON, count)
+}
+
+// PreviewGeneratedDocuments expands a generator template n times without inserting anything.
+func (a *App) PreviewGeneratedDocuments(templateJSON string, n int) ([]string, error) {
+	return generator.PreviewDocuments(templateJSON, n)
+}
+
 // =============================================================================
 // Export Methods
 // =============================================================================
@@ -566,8 +1562,8 @@ func (a *App) ExportCollections(connID, dbName string, collNames []string) error
 	return a.export.ExportCollections(connID, dbName, collNames)
 }
 
-func (a *App) ExportDocumentsAsZip(entries []DocumentExportEntry, defaultFilename string) error {
-	return a.export.ExportDocumentsAsZip(entries, defaultFilename)
+func (a *App) ExportDocumentsAsZip(connID string, entries []DocumentExportEntry, defaultFilename string) error {
+	return a.export.ExportDocumentsAsZip(connID, entries, defaultFilename)
 }
 
 func (a *App) ExportCollectionAsCSV(connID, dbName, collName, defaultFilename string, opts CSVExportOptions) error {
@@ -740,6 +1736,158 @@ func (a *App) DeleteSavedQuery(queryID string) error {
 	return a.querySvc.DeleteQuery(queryID)
 }
 
+// RunSavedQuery substitutes paramsJSON (an object of parameter values) into a saved query's
+// {{name}} placeholders and runs it against the query's collection.
+func (a *App) RunSavedQuery(queryID, paramsJSON string) (*QueryResult, error) {
+	query, err := a.querySvc.GetQuery(queryID)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := document.SubstituteParameters(query.Query, query.Parameters, paramsJSON)
+	if err != nil {
+		return nil, err
+	}
+	return a.FindDocuments(query.ConnectionID, query.Database, query.Collection, filter, QueryOptions{})
+}
+
+// =============================================================================
+// Saved Pipeline Methods
+// =============================================================================
+
+// SavePipeline creates a saved aggregation pipeline (empty ID) or updates an existing one.
+func (a *App) SavePipeline(pipeline SavedPipeline) (SavedPipeline, error) {
+	return a.querySvc.SavePipeline(pipeline)
+}
+
+func (a *App) GetSavedPipeline(pipelineID string) (SavedPipeline, error) {
+	return a.querySvc.GetPipeline(pipelineID)
+}
+
+func (a *App) ListSavedPipelines(connectionID, database, collection string) ([]SavedPipeline, error) {
+	return a.querySvc.ListPipelines(connectionID, database, collection)
+}
+
+func (a *App) DeleteSavedPipeline(pipelineID string) error {
+	return a.querySvc.DeletePipeline(pipelineID)
+}
+
+// =============================================================================
+// Document Template Methods
+// =============================================================================
+
+// SaveDocumentTemplate creates a collection's insert template (empty ID) or updates an existing one.
+func (a *App) SaveDocumentTemplate(tmpl DocumentTemplate) (DocumentTemplate, error) {
+	return a.querySvc.SaveTemplate(tmpl)
+}
+
+func (a *App) GetDocumentTemplate(templateID string) (DocumentTemplate, error) {
+	return a.querySvc.GetTemplate(templateID)
+}
+
+func (a *App) ListDocumentTemplates(connectionID, database, collection string) ([]DocumentTemplate, error) {
+	return a.querySvc.ListTemplates(connectionID, database, collection)
+}
+
+func (a *App) DeleteDocumentTemplate(templateID string) error {
+	return a.querySvc.DeleteTemplate(templateID)
+}
+
+// RenderDocumentTemplate materializes a template's placeholders without inserting, to prefill
+// the document editor.
+func (a *App) RenderDocumentTemplate(templateID string) (string, error) {
+	tmpl, err := a.querySvc.GetTemplate(templateID)
+	if err != nil {
+		return "", err
+	}
+	return generator.RenderDocument(tmpl.Template)
+}
+
+// InsertFromTemplate materializes a template's placeholders and inserts the document into the
+// template's collection, returning the stored document as Extended JSON.
+func (a *App) InsertFromTemplate(templateID string) (string, error) {
+	tmpl, err := a.querySvc.GetTemplate(templateID)
+	if err != nil {
+		return "", err
+	}
+	doc, err := generator.RenderDocument(tmpl.Template)
+	if err != nil {
+		return "", err
+	}
+	return a.document.InsertDocumentFull(tmpl.ConnectionID, tmpl.Database, tmpl.Collection, doc, nil)
+}
+
+// =============================================================================
+// Query History Methods
+// =============================================================================
+
+// ListQueryHistory returns recent queries, newest first. Empty filters match everything;
+// a limit of 0 returns all entries.
+func (a *App) ListQueryHistory(connectionID, database, collection string, limit int) []QueryHistoryEntry {
+	return a.historySvc.ListHistory(connectionID, database, collection, limit)
+}
+
+// SearchQueryHistory returns history entries containing the given text.
+func (a *App) SearchQueryHistory(connectionID, text string) []QueryHistoryEntry {
+	return a.historySvc.SearchHistory(connectionID, text)
+}
+
+// ClearQueryHistory removes history for a connection, or all history if connectionID is empty.
+func (a *App) ClearQueryHistory(connectionID string) error {
+	return a.historySvc.ClearHistory(connectionID)
+}
+
+// =============================================================================
+// Trash Methods
+// =============================================================================
+
+// ListTrash returns documents deleted on a connection, newest first. Empty database and
+// collection match everything.
+func (a *App) ListTrash(connectionID, database, collection string) []TrashEntry {
+	return a.trashSvc.ListTrash(connectionID, database, collection)
+}
+
+// RestoreFromTrash re-inserts the given trashed documents into their original collections.
+// Restored entries leave the trash; entries that fail (e.g. the _id was reused) stay.
+func (a *App) RestoreFromTrash(entryIDs []string) (*TrashRestoreResult, error) {
+	entries, err := a.trashSvc.GetTrashEntries(entryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TrashRestoreResult{}
+	var restored []string
+	for _, e := range entries {
+		if err := a.document.RestoreDocument(e.ConnectionID, e.Database, e.Collection, e.Document); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s.%s %s: %v", e.Database, e.Collection, e.DocumentID, err))
+			continue
+		}
+		restored = append(restored, e.ID)
+	}
+	result.Restored = len(restored)
+
+	if err := a.trashSvc.RemoveTrashEntries(restored); err != nil {
+		return result, fmt.Errorf("documents restored but trash could not be updated: %w", err)
+	}
+	return result, nil
+}
+
+// EmptyTrash permanently discards trashed documents for a connection, or all if connectionID is empty.
+func (a *App) EmptyTrash(connectionID string) error {
+	return a.trashSvc.EmptyTrash(connectionID)
+}
+
+// =============================================================================
+// Settings Methods
+// =============================================================================
+
+func (a *App) GetSettings() AppSettings {
+	return a.settingsSvc.GetSettings()
+}
+
+func (a *App) UpdateSettings(settings AppSettings) error {
+	return a.settingsSvc.UpdateSettings(settings)
+}
+
 // =============================================================================
 // Performance Methods
 // =============================================================================
@@ -505,8 +505,18 @@ func TestValidateJSON(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:    "Invalid JSON - syntax error (trailing comma)",
+			name:    "Relaxed JSON - trailing comma",
 			jsonStr: `{"name": "John",}`,
+			wantErr: false,
+		},
+		{
+			name:    "Relaxed JSON - shell syntax",
+			jsonStr: `{_id: ObjectId("507f1f77bcf86cd799439011"), name: 'John'}`,
+			wantErr: false,
+		},
+		{
+			name:    "Invalid JSON - unknown shell helper",
+			jsonStr: `{name: Code("x")}`,
 			wantErr: true,
 		},
 		{
@@ -23,7 +23,8 @@ import { useTab } from './components/contexts/TabContext'
 import { useStatus } from './components/contexts/StatusContext'
 import { useOperation } from './components/contexts/OperationContext'
 import ExportManager from './components/ExportManager'
-import type { WailsAppBindings } from './types/wails.d'
+import type { WailsAppBindings, Alert } from './types/wails.d'
+import { showSystemNotification } from './utils/systemNotification'
 
 // Constants
 const DEFAULT_SIDEBAR_WIDTH = 260
@@ -166,6 +167,20 @@ function App(): JSX.Element {
     }
   }, [notify])
 
+  // Raise monitoring alerts as OS notifications, falling back to an in-app warning
+  useEffect(() => {
+    const _unsubscribe = EventsOn('alert:triggered', (alert: Alert) => {
+      if (!alert?.message) return
+      void showSystemNotification('MongoPal alert', alert.message).then((shown) => {
+        if (!shown) notify.warning(alert.message)
+      })
+    })
+    void _unsubscribe // Suppress unused warning - cleanup uses EventsOff
+    return () => {
+      EventsOff('alert:triggered')
+    }
+  }, [notify])
+
   // Global keyboard shortcuts
   useEffect(() => {
     const handleKeyDown = (e: KeyboardEvent): void => {
@@ -20,7 +20,13 @@ export interface CollectionStats {
   avgObjSize: number
   indexCount: number
   totalIndexSize: number
+  indexSizes?: Record<string, number>
+  freeStorageSize?: number
+  compressionRatio?: number
   capped: boolean
+  maxSize?: number
+  maxDocuments?: number
+  sharded?: boolean
 }
 
 export interface CollectionStatsModalProps {
@@ -169,6 +175,12 @@ Total Index Size: ${formatBytes(stats.totalIndexSize)}${stats.capped ? '\nCapped
                     <div className="text-lg font-medium text-text">{formatBytes(stats.storageSize)}</div>
                   </div>
                 </div>
+                {!!stats.compressionRatio && (
+                  <div className="text-xs text-text-muted mt-2">
+                    Compression {stats.compressionRatio.toFixed(2)}x
+                    {!!stats.freeStorageSize && ` \u00b7 ${formatBytes(stats.freeStorageSize)} reusable`}
+                  </div>
+                )}
               </div>
 
               {/* Index stats */}
@@ -184,6 +196,18 @@ Total Index Size: ${formatBytes(stats.totalIndexSize)}${stats.capped ? '\nCapped
                     <div className="text-lg font-medium text-text">{formatBytes(stats.totalIndexSize)}</div>
                   </div>
                 </div>
+                {stats.indexSizes && Object.keys(stats.indexSizes).length > 0 && (
+                  <div className="mt-2 space-y-1">
+                    {Object.entries(stats.indexSizes)
+                      .sort(([, a], [, b]) => b - a)
+                      .map(([name, size]) => (
+                        <div key={name} className="flex justify-between text-xs">
+                          <span className="font-mono text-text-secondary truncate">{name}</span>
+                          <span className="text-text-muted ml-2">{formatBytes(size)}</span>
+                        </div>
+                      ))}
+                  </div>
+                )}
               </div>
 
               {/* Flags */}
@@ -193,6 +217,8 @@ Total Index Size: ${formatBytes(stats.totalIndexSize)}${stats.capped ? '\nCapped
                     <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M13 16h-1v-4h-1m1-4h.01M21 12a9 9 0 11-18 0 9 9 0 0118 0z" />
                   </svg>
                   This is a capped collection
+                  {!!stats.maxSize &&
+                    ` (max ${formatBytes(stats.maxSize)}${stats.maxDocuments ? `, ${formatNumber(stats.maxDocuments)} documents` : ''})`}
                 </div>
               )}
             </div>
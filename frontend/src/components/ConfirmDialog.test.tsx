@@ -1,5 +1,5 @@
 import { describe, it, expect, vi, beforeEach } from 'vitest'
-import { render, screen, fireEvent } from '@testing-library/react'
+import { render, screen, fireEvent, act } from '@testing-library/react'
 import ConfirmDialog, { ConfirmDialogProps } from './ConfirmDialog'
 
 describe('ConfirmDialog', () => {
@@ -117,6 +117,32 @@ describe('ConfirmDialog', () => {
     })
   })
 
+  describe('destructive safety settings', () => {
+    it('enables confirm only after the required text is typed', () => {
+      render(<ConfirmDialog {...defaultProps} requireText="DELETE" />)
+      const confirmBtn = screen.getByText('Confirm')
+      expect(confirmBtn).toBeDisabled()
+
+      fireEvent.change(screen.getByRole('textbox'), { target: { value: 'DELETE' } })
+      expect(confirmBtn).not.toBeDisabled()
+      fireEvent.click(confirmBtn)
+      expect(defaultProps.onConfirm).toHaveBeenCalledWith('DELETE')
+    })
+
+    it('counts down the delay before enabling confirm', () => {
+      vi.useFakeTimers()
+      try {
+        render(<ConfirmDialog {...defaultProps} delaySeconds={3} />)
+        expect(screen.getByText('Confirm (3s)')).toBeDisabled()
+
+        act(() => { vi.advanceTimersByTime(3000) })
+        expect(screen.getByText('Confirm')).not.toBeDisabled()
+      } finally {
+        vi.useRealTimers()
+      }
+    })
+  })
+
   describe('focus management', () => {
     it('focuses confirm button when dialog opens', () => {
       render(<ConfirmDialog {...defaultProps} />)
@@ -1,4 +1,4 @@
-import { useEffect, useRef, ReactNode } from 'react'
+import { useEffect, useRef, useState, ReactNode } from 'react'
 
 export interface ConfirmDialogProps {
   /** Whether the dialog is open */
@@ -13,8 +13,12 @@ export interface ConfirmDialogProps {
   cancelLabel?: string
   /** Whether this is a dangerous action (affects styling and focus behavior) */
   danger?: boolean
-  /** Callback when user confirms the action */
-  onConfirm: () => void
+  /** Text the user must type before the confirm button is enabled */
+  requireText?: string
+  /** Seconds to wait before the confirm button is enabled */
+  delaySeconds?: number
+  /** Callback when user confirms the action, with the text typed (if required) */
+  onConfirm: (typed: string) => void
   /** Callback when user cancels the action */
   onCancel: () => void
 }
@@ -26,11 +30,31 @@ export default function ConfirmDialog({
   confirmLabel = 'Confirm',
   cancelLabel = 'Cancel',
   danger = false,
+  requireText,
+  delaySeconds = 0,
   onConfirm,
   onCancel,
 }: ConfirmDialogProps): ReactNode {
   const confirmRef = useRef<HTMLButtonElement>(null)
   const cancelRef = useRef<HTMLButtonElement>(null)
+  const [typed, setTyped] = useState('')
+  const [remaining, setRemaining] = useState(delaySeconds)
+
+  useEffect(() => {
+    if (!open) return
+    setTyped('')
+    setRemaining(delaySeconds)
+    if (delaySeconds <= 0) return
+    const started = Date.now()
+    const timer = setInterval(() => {
+      const left = Math.max(0, delaySeconds - Math.floor((Date.now() - started) / 1000))
+      setRemaining(left)
+      if (left === 0) clearInterval(timer)
+    }, 250)
+    return () => clearInterval(timer)
+  }, [open, delaySeconds])
+
+  const canConfirm = remaining <= 0 && (!requireText || typed === requireText)
 
   useEffect(() => {
     if (open) {
@@ -49,15 +73,15 @@ export default function ConfirmDialog({
     const handleKeyDown = (e: KeyboardEvent): void => {
       if (e.key === 'Escape') {
         onCancel()
-      } else if (e.key === 'Enter' && !danger) {
+      } else if (e.key === 'Enter' && !danger && canConfirm) {
         // Only auto-confirm on Enter for non-danger dialogs
-        onConfirm()
+        onConfirm(typed)
       }
     }
 
     window.addEventListener('keydown', handleKeyDown)
     return () => window.removeEventListener('keydown', handleKeyDown)
-  }, [open, danger, onConfirm, onCancel])
+  }, [open, danger, canConfirm, typed, onConfirm, onCancel])
 
   if (!open) return null
 
@@ -74,6 +98,18 @@ export default function ConfirmDialog({
           ) : (
             message
           )}
+          {requireText && (
+            <label className="block mt-3">
+              <span className="text-xs">Type <strong>{requireText}</strong> to confirm</span>
+              <input
+                className="input mt-1 w-full"
+                value={typed}
+                onChange={e => setTyped(e.target.value)}
+                autoComplete="off"
+                spellCheck={false}
+              />
+            </label>
+          )}
         </div>
 
         <div className="px-4 py-3 border-t border-border flex justify-end gap-2">
@@ -83,9 +119,10 @@ export default function ConfirmDialog({
           <button
             ref={confirmRef}
             className={`btn ${danger ? 'btn-danger' : 'btn-primary'}`}
-            onClick={onConfirm}
+            onClick={() => onConfirm(typed)}
+            disabled={!canConfirm}
           >
-            {confirmLabel}
+            {remaining > 0 ? `${confirmLabel} (${remaining}s)` : confirmLabel}
           </button>
         </div>
       </div>
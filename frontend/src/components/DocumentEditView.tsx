@@ -85,8 +85,16 @@ interface IconProps {
  */
 interface DocumentGoBindings {
   GetDocument?: (connectionId: string, database: string, collection: string, documentId: MongoDocumentId) => Promise<string>
-  UpdateDocument?: (connectionId: string, database: string, collection: string, documentId: MongoDocumentId, content: string) => Promise<void>
-  InsertDocument?: (connectionId: string, database: string, collection: string, content: string) => Promise<string>
+  UpdateDocument?: (
+    connectionId: string,
+    database: string,
+    collection: string,
+    documentId: MongoDocumentId,
+    content: string,
+    upsert: boolean,
+    writeConcern: unknown
+  ) => Promise<void>
+  InsertDocument?: (connectionId: string, database: string, collection: string, content: string, writeConcern: unknown) => Promise<string>
 }
 
 // =============================================================================
@@ -582,7 +590,7 @@ export default function DocumentEditView({
     setSaving(true)
     try {
       if (go?.UpdateDocument && documentId) {
-        await go.UpdateDocument(connectionId, database, collection, documentId, currentContent)
+        await go.UpdateDocument(connectionId, database, collection, documentId, currentContent, false, null)
         notify.success('Document saved')
         // Add the PREVIOUS saved version to history (what we're replacing)
         if (originalContent && originalContent !== baselineEntry?.content) {
@@ -617,7 +625,7 @@ export default function DocumentEditView({
     setInserting(true)
     try {
       if (go?.InsertDocument) {
-        const newId = await go.InsertDocument(connectionId, database, collection, currentContent)
+        const newId = await go.InsertDocument(connectionId, database, collection, currentContent, null)
         notify.success(`Document inserted: ${newId}`)
 
         // Fetch the inserted document and convert tab to edit mode
@@ -1,6 +1,7 @@
 import { useState, useEffect, ReactNode, FormEvent, ChangeEvent } from 'react'
 import { useNotification } from './NotificationContext'
 import ConfirmDialog from './ConfirmDialog'
+import type { DestructiveConfirmation } from './sidebar/types'
 
 // =============================================================================
 // Type Definitions
@@ -38,6 +39,10 @@ export interface MongoIndex {
   size: number
   /** Number of operations using this index */
   usageCount: number
+  /** When the usage count started (server restart or index build) */
+  usageSince?: string
+  /** Whether the index is hidden from the query planner */
+  hidden?: boolean
 }
 
 /**
@@ -99,15 +104,17 @@ interface IndexGoBindings {
     connectionId: string,
     database: string,
     collection: string,
-    keys: Record<string, number>,
+    keysJson: string,
     options: CreateIndexOptions
-  ) => Promise<void>
+  ) => Promise<string>
   DropIndex?: (
     connectionId: string,
     database: string,
     collection: string,
     indexName: string
   ) => Promise<void>
+  RequestDestructiveConfirmation?: (connectionId: string, operation: string, target: string) => Promise<DestructiveConfirmation>
+  ConfirmDestructiveOperation?: (token: string, typed: string) => Promise<void>
 }
 
 // Access window.go dynamically for testability
@@ -386,6 +393,7 @@ export default function IndexManagerModal({
   const [showCreateForm, setShowCreateForm] = useState<boolean>(false)
   const [creating, setCreating] = useState<boolean>(false)
   const [confirmDelete, setConfirmDelete] = useState<string | null>(null)
+  const [dropConfirmation, setDropConfirmation] = useState<DestructiveConfirmation | undefined>(undefined)
   const [deleting, setDeleting] = useState<boolean>(false)
 
   useEffect(() => {
@@ -429,7 +437,7 @@ export default function IndexManagerModal({
     try {
       const go = getGo()
       if (go?.CreateIndex) {
-        await go.CreateIndex(connectionId, database, collection, keys, opts)
+        await go.CreateIndex(connectionId, database, collection, JSON.stringify(keys), opts)
         notify.success('Index created successfully')
         setShowCreateForm(false)
         await loadIndexes()
@@ -442,11 +450,30 @@ export default function IndexManagerModal({
     }
   }
 
-  const handleDropIndex = async (indexName: string): Promise<void> => {
+  // Connections with safety settings get a backend-issued token, confirmed before the drop runs
+  const openDropConfirm = async (indexName: string): Promise<void> => {
+    let confirmation: DestructiveConfirmation | undefined
+    try {
+      confirmation = await getGo()?.RequestDestructiveConfirmation?.(
+        connectionId, 'drop index', `${database}.${collection}.${indexName}`
+      )
+    } catch (err) {
+      const errorMsg = err instanceof Error ? err.message : String(err)
+      notify.error(`Failed to drop index: ${errorMsg}`)
+      return
+    }
+    setDropConfirmation(confirmation)
+    setConfirmDelete(indexName)
+  }
+
+  const handleDropIndex = async (indexName: string, typed: string): Promise<void> => {
     setDeleting(true)
     try {
       const go = getGo()
       if (go?.DropIndex) {
+        if (dropConfirmation?.required && dropConfirmation.token) {
+          await go.ConfirmDestructiveOperation?.(dropConfirmation.token, typed)
+        }
         await go.DropIndex(connectionId, database, collection, indexName)
         notify.success(`Index "${indexName}" dropped`)
         setConfirmDelete(null)
@@ -539,7 +566,7 @@ export default function IndexManagerModal({
                           {!isDefaultId && (
                             <button
                               className="p-1.5 rounded hover:bg-surface-hover text-text-muted hover:text-error flex-shrink-0"
-                              onClick={() => setConfirmDelete(index.name)}
+                              onClick={() => void openDropConfirm(index.name)}
                               title="Drop index"
                             >
                               <TrashIcon className="w-4 h-4" />
@@ -584,7 +611,9 @@ export default function IndexManagerModal({
           message={`Are you sure you want to drop the index "${confirmDelete}"? This action cannot be undone.`}
           confirmLabel={deleting ? 'Dropping...' : 'Drop Index'}
           danger={true}
-          onConfirm={() => handleDropIndex(confirmDelete)}
+          requireText={dropConfirmation?.required ? dropConfirmation.confirmationText : undefined}
+          delaySeconds={dropConfirmation?.required ? dropConfirmation.delaySeconds : 0}
+          onConfirm={(typed) => handleDropIndex(confirmDelete, typed)}
           onCancel={() => setConfirmDelete(null)}
         />
       )}
@@ -2,6 +2,7 @@ import { useState, useEffect, ReactNode, FormEvent, ChangeEvent } from 'react'
 import { useNotification } from './NotificationContext'
 import { useConnection } from './contexts/ConnectionContext'
 import ConfirmDialog from './ConfirmDialog'
+import type { DestructiveConfirmation } from './sidebar/types'
 
 // =============================================================================
 // Type Definitions
@@ -37,6 +38,10 @@ export interface MongoIndex {
   size: number
   /** Number of operations using this index */
   usageCount: number
+  /** When the usage count started (server restart or index build) */
+  usageSince?: string
+  /** Whether the index is hidden from the query planner */
+  hidden?: boolean
   /** Index version */
   version?: number
   /** Partial filter expression for partial indexes */
@@ -102,15 +107,17 @@ interface IndexGoBindings {
     connectionId: string,
     database: string,
     collection: string,
-    keys: Record<string, number>,
+    keysJson: string,
     options: CreateIndexOptions
-  ) => Promise<void>
+  ) => Promise<string>
   DropIndex?: (
     connectionId: string,
     database: string,
     collection: string,
     indexName: string
   ) => Promise<void>
+  RequestDestructiveConfirmation?: (connectionId: string, operation: string, target: string) => Promise<DestructiveConfirmation>
+  ConfirmDestructiveOperation?: (token: string, typed: string) => Promise<void>
 }
 
 // Access window.go dynamically for testability
@@ -430,6 +437,7 @@ export default function IndexView({
   const [showCreateForm, setShowCreateForm] = useState<boolean>(false)
   const [creating, setCreating] = useState<boolean>(false)
   const [confirmDelete, setConfirmDelete] = useState<string | null>(null)
+  const [dropConfirmation, setDropConfirmation] = useState<DestructiveConfirmation | undefined>(undefined)
   const [deleting, setDeleting] = useState<boolean>(false)
   const [expandedIndexes, setExpandedIndexes] = useState<Set<string>>(new Set())
   const [copiedIndex, setCopiedIndex] = useState<string | null>(null)
@@ -511,7 +519,7 @@ Statistics:
     try {
       const go = getGo()
       if (go?.CreateIndex) {
-        await go.CreateIndex(connectionId, database, collection, keys, opts)
+        await go.CreateIndex(connectionId, database, collection, JSON.stringify(keys), opts)
         notify.success('Index created successfully')
         setShowCreateForm(false)
         await loadIndexes()
@@ -524,11 +532,30 @@ Statistics:
     }
   }
 
-  const handleDropIndex = async (indexName: string): Promise<void> => {
+  // Connections with safety settings get a backend-issued token, confirmed before the drop runs
+  const openDropConfirm = async (indexName: string): Promise<void> => {
+    let confirmation: DestructiveConfirmation | undefined
+    try {
+      confirmation = await getGo()?.RequestDestructiveConfirmation?.(
+        connectionId, 'drop index', `${database}.${collection}.${indexName}`
+      )
+    } catch (err) {
+      const errorMsg = err instanceof Error ? err.message : String(err)
+      notify.error(`Failed to drop index: ${errorMsg}`)
+      return
+    }
+    setDropConfirmation(confirmation)
+    setConfirmDelete(indexName)
+  }
+
+  const handleDropIndex = async (indexName: string, typed: string): Promise<void> => {
     setDeleting(true)
     try {
       const go = getGo()
       if (go?.DropIndex) {
+        if (dropConfirmation?.required && dropConfirmation.token) {
+          await go.ConfirmDestructiveOperation?.(dropConfirmation.token, typed)
+        }
         await go.DropIndex(connectionId, database, collection, indexName)
         notify.success(`Index "${indexName}" dropped`)
         setConfirmDelete(null)
@@ -741,7 +768,7 @@ Statistics:
                           className="p-2 rounded hover:bg-surface-active text-text-muted hover:text-error"
                           onClick={(e) => {
                             e.stopPropagation()
-                            setConfirmDelete(index.name)
+                            void openDropConfirm(index.name)
                           }}
                           title="Drop index"
                         >
@@ -861,7 +888,9 @@ Statistics:
           message={`Are you sure you want to drop the index "${confirmDelete}"? This action cannot be undone.`}
           confirmLabel={deleting ? 'Dropping...' : 'Drop Index'}
           danger={true}
-          onConfirm={() => handleDropIndex(confirmDelete)}
+          requireText={dropConfirmation?.required ? dropConfirmation.confirmationText : undefined}
+          delaySeconds={dropConfirmation?.required ? dropConfirmation.delaySeconds : 0}
+          onConfirm={(typed) => handleDropIndex(confirmDelete, typed)}
           onCancel={() => setConfirmDelete(null)}
         />
       )}
@@ -70,6 +70,7 @@ vi.mock('./contexts/ConnectionContext', () => ({
     ] as MockConnection[],
     folders: [],
     activeConnections: ['conn1'],
+    connectionHealth: {},
     isConnecting: mockIsConnecting,
     connect: mockConnect,
     disconnect: mockDisconnect,
@@ -1,7 +1,7 @@
 // Types for the advanced connection form (F074)
 
 export type ConnectionType = 'standalone' | 'replicaset' | 'sharded' | 'srv';
-export type AuthMechanism = 'none' | 'scram-sha-1' | 'scram-sha-256' | 'x509' | 'mongodb-aws' | 'kerberos';
+export type AuthMechanism = 'none' | 'scram-sha-1' | 'scram-sha-256' | 'x509' | 'mongodb-aws' | 'kerberos' | 'plain';
 export type FormMode = 'form' | 'uri';
 export type TabId = 'connection' | 'authentication' | 'network' | 'options' | 'safety' | 'appearance';
 
@@ -17,6 +17,7 @@ export interface ConnectionFormData {
   folderId?: string;
   color: string;
   readOnly: boolean;
+  autoConnect: boolean;
 
   // Connection tab
   connectionType: ConnectionType;
@@ -30,6 +31,9 @@ export interface ConnectionFormData {
   username?: string;
   password?: string;
   authDatabase?: string;
+  gssapiServiceName?: string; // Kerberos service name (default: mongodb)
+  gssapiServiceRealm?: string;
+  gssapiCanonicalizeHost?: boolean;
 
   // TLS/SSL (Network tab)
   tlsEnabled: boolean;
@@ -48,6 +52,7 @@ export interface ConnectionFormData {
   sshPassword?: string;
   sshPrivateKey?: string;
   sshPassphrase?: string;
+  sshHostKeyFingerprint?: string;
 
   // Network tab - SOCKS5 Proxy
   socks5Enabled: boolean;
@@ -73,8 +78,11 @@ export interface ConnectionFormData {
   compressors: string[]; // ['snappy', 'zlib', 'zstd']
 
   // Safety tab
+  environment: '' | 'prod' | 'staging' | 'dev';
   destructiveDelay: number; // seconds
   requireDeleteConfirmation: boolean;
+  allowedDatabases: string[]; // Database names or glob patterns; empty allows all
+  deniedDatabases: string[];
 }
 
 export interface ValidationError {
@@ -93,6 +101,7 @@ export interface TestConnectionResult {
   latency?: number;
   tlsEnabled?: boolean;
   replicaSet?: string;
+  warnings?: string[];
 }
 
 export interface TabInfo {
@@ -107,6 +116,7 @@ export const DEFAULT_FORM_DATA: Omit<ConnectionFormData, 'id' | 'name'> = {
   folderId: '',
   color: '#4CC38A',
   readOnly: false,
+  autoConnect: false,
 
   // Connection
   connectionType: 'standalone',
@@ -163,6 +173,9 @@ export const DEFAULT_FORM_DATA: Omit<ConnectionFormData, 'id' | 'name'> = {
   compressors: [],
 
   // Safety
+  environment: '',
   destructiveDelay: 0,
   requireDeleteConfirmation: false,
+  allowedDatabases: [],
+  deniedDatabases: [],
 };
@@ -53,13 +53,32 @@ export function generateURIFromForm(data: ConnectionFormData, options?: Generate
     'x509': 'MONGODB-X509',
     'mongodb-aws': 'MONGODB-AWS',
     'kerberos': 'GSSAPI',
+    'plain': 'PLAIN',
   };
   if (data.authMechanism !== 'none' && authMechanismToDriver[data.authMechanism]) {
     params.append('authMechanism', authMechanismToDriver[data.authMechanism]);
   }
-  if (data.authDatabase && data.authDatabase !== 'admin') {
+  if (data.authMechanism === 'plain') {
+    // LDAP users only exist in $external
+    params.append('authSource', '$external');
+  } else if (data.authDatabase && data.authDatabase !== 'admin') {
     params.append('authSource', data.authDatabase);
   }
+  if (data.authMechanism === 'kerberos') {
+    const props: string[] = [];
+    if (data.gssapiServiceName && data.gssapiServiceName !== 'mongodb') {
+      props.push(`SERVICE_NAME:${data.gssapiServiceName}`);
+    }
+    if (data.gssapiServiceRealm) {
+      props.push(`SERVICE_REALM:${data.gssapiServiceRealm}`);
+    }
+    if (data.gssapiCanonicalizeHost) {
+      props.push('CANONICALIZE_HOST_NAME:true');
+    }
+    if (props.length > 0) {
+      params.append('authMechanismProperties', props.join(','));
+    }
+  }
 
   // Direct connection for standalone (skip replica set discovery)
   if (data.connectionType === 'standalone') {
@@ -257,6 +276,7 @@ export function parseURIIntoForm(uri: string): Partial<ConnectionFormData> {
       'MONGODB-X509': 'x509',
       'MONGODB-AWS': 'mongodb-aws',
       'GSSAPI': 'kerberos',
+      'PLAIN': 'plain',
     };
     const authMechanismStr = params.get('authMechanism');
     let authMechanism: ConnectionFormData['authMechanism'] = 'none';
@@ -273,6 +293,7 @@ export function parseURIIntoForm(uri: string): Partial<ConnectionFormData> {
       defaultDatabase: database,
       authMechanism,
       authDatabase: params.get('authSource') || 'admin',
+      ...parseGSSAPIProperties(params.get('authMechanismProperties')),
 
       // Hosts
       ...(isSRV ? { srvHostname: hostsStr } : { hosts }),
@@ -454,3 +475,27 @@ export function parseMultiURIText(text: string): Array<{
     return { uri, parsed: formData, connectionName };
   });
 }
+
+/**
+ * Reads Kerberos settings from authMechanismProperties (e.g. "SERVICE_NAME:mongodb,CANONICALIZE_HOST_NAME:true").
+ */
+function parseGSSAPIProperties(value: string | null): Partial<ConnectionFormData> {
+  const result: Partial<ConnectionFormData> = {};
+  if (!value) {
+    return result;
+  }
+  for (const prop of value.split(',')) {
+    const idx = prop.indexOf(':');
+    if (idx < 0) continue;
+    const key = prop.slice(0, idx);
+    const val = prop.slice(idx + 1);
+    if (key === 'SERVICE_NAME') {
+      result.gssapiServiceName = val;
+    } else if (key === 'SERVICE_REALM') {
+      result.gssapiServiceRealm = val;
+    } else if (key === 'CANONICALIZE_HOST_NAME') {
+      result.gssapiCanonicalizeHost = val === 'true';
+    }
+  }
+  return result;
+}
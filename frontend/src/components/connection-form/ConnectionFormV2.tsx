@@ -126,6 +126,7 @@ export function ConnectionFormV2({ connection, folders, onSave, onCancel }: Conn
           folderId: extendedConn.folderId || '',
           color: extendedConn.color || '#4CC38A',
           readOnly: extendedConn.readOnly || false,
+          autoConnect: extendedConn.autoConnect || false,
 
           // Parse MongoDB URI to extract connection details (password will be empty)
           ...(extendedConn.mongoUri ? parseURIIntoForm(extendedConn.mongoUri) : {}),
@@ -160,8 +161,11 @@ export function ConnectionFormV2({ connection, folders, onSave, onCancel }: Conn
           socks5RequiresAuth: !!(extendedConn.socks5User || extendedConn.socks5Password),
 
           // Safety settings
+          environment: extendedConn.environment || '',
           destructiveDelay: extendedConn.destructiveDelay || 0,
           requireDeleteConfirmation: extendedConn.requireDeleteConfirmation || false,
+          allowedDatabases: extendedConn.allowedDatabases || [],
+          deniedDatabases: extendedConn.deniedDatabases || [],
         };
 
         setFormData(parsedFormData);
@@ -403,7 +407,19 @@ export function ConnectionFormV2({ connection, folders, onSave, onCancel }: Conn
 
     try {
       const testURI = mode === 'uri' ? uriText : generatedURI;
-      const result = await window.go?.main?.App?.TestConnection(testURI, connection?.id || '');
+      const app = window.go?.main?.App;
+      // TLS certificates live outside the URI, so test them with the unsaved form settings
+      const result = formData.tlsEnabled && app?.TestConnectionWithSettings
+        ? await app.TestConnectionWithSettings(testURI, {
+            id: connection?.id || '',
+            tlsEnabled: formData.tlsEnabled,
+            tlsInsecure: formData.tlsInsecure,
+            tlsCAFile: formData.tlsCACert || '',
+            tlsCertFile: formData.tlsClientCert || '',
+            tlsKeyFile: formData.tlsClientKey || '',
+            tlsKeyPassword: formData.tlsClientKeyPassword || '',
+          })
+        : await app?.TestConnection(testURI, connection?.id || '');
       if (result) {
         setTestResult(result);
       } else {
@@ -437,6 +453,7 @@ export function ConnectionFormV2({ connection, folders, onSave, onCancel }: Conn
       folderId: formData.folderId,
       color: formData.color,
       readOnly: formData.readOnly,
+      autoConnect: formData.autoConnect,
       createdAt: connection?.createdAt || new Date().toISOString(),
       lastAccessedAt: connection?.lastAccessedAt || new Date(0).toISOString(),
 
@@ -469,8 +486,11 @@ export function ConnectionFormV2({ connection, folders, onSave, onCancel }: Conn
       socks5Password: formData.socks5Password || '',
 
       // Safety
+      environment: formData.environment || '',
       destructiveDelay: formData.destructiveDelay,
       requireDeleteConfirmation: formData.requireDeleteConfirmation,
+      allowedDatabases: formData.allowedDatabases || [],
+      deniedDatabases: formData.deniedDatabases || [],
 
       // Store form data as JSON for future editing
       formData: JSON.stringify(formData),
@@ -809,6 +829,9 @@ export function ConnectionFormV2({ connection, folders, onSave, onCancel }: Conn
                     {testResult.tlsEnabled && <span>TLS</span>}
                   </div>
                 )}
+                {testResult.warnings?.map(warning => (
+                  <div key={warning} className="text-xs text-yellow-300 mt-1">{warning}</div>
+                ))}
                 {!testResult.success && (
                   <>
                     <div className="text-xs text-red-300 mt-1">{testResult.error}</div>
@@ -96,8 +96,8 @@ function validateConnectionTab(data: ConnectionFormData): ValidationError[] {
 function validateAuthenticationTab(data: ConnectionFormData): ValidationError[] {
   const errors: ValidationError[] = [];
 
-  // Username required for most auth mechanisms
-  if (data.authMechanism !== 'none' && data.authMechanism !== 'mongodb-aws') {
+  // Username required for most auth mechanisms (X.509 takes it from the certificate subject)
+  if (data.authMechanism !== 'none' && data.authMechanism !== 'mongodb-aws' && data.authMechanism !== 'x509') {
     if (!data.username || data.username.trim() === '') {
       errors.push({
         field: 'username',
@@ -118,6 +118,26 @@ function validateAuthenticationTab(data: ConnectionFormData): ValidationError[]
     });
   }
 
+  // PLAIN sends the LDAP password as is
+  if (data.authMechanism === 'plain' && !data.tlsEnabled) {
+    errors.push({
+      field: 'authMechanism',
+      tab: 'authentication',
+      message: 'LDAP (PLAIN) authentication without TLS sends the password unencrypted (see Network tab)',
+      severity: 'warning',
+    });
+  }
+
+  // The client certificate is the X.509 identity
+  if (data.authMechanism === 'x509' && data.tlsEnabled && !data.tlsClientCert?.trim()) {
+    errors.push({
+      field: 'authMechanism',
+      tab: 'authentication',
+      message: 'X.509 authentication requires a client certificate (see Network tab)',
+      severity: 'error',
+    });
+  }
+
   return errors;
 }
 
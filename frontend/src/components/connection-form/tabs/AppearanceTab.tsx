@@ -64,6 +64,23 @@ export function AppearanceTab({ data, errors: _errors, onChange }: AppearanceTab
             </p>
           </div>
         </div>
+        <div className="flex items-start gap-2 mt-4">
+          <input
+            type="checkbox"
+            id="autoConnect"
+            checked={data.autoConnect}
+            onChange={e => onChange({ autoConnect: e.target.checked })}
+            className="mt-1 w-4 h-4 bg-surface border-border rounded-md focus:ring-2 focus:ring-primary"
+          />
+          <div>
+            <label htmlFor="autoConnect" className="text-sm text-text-secondary block">
+              Connect on Startup
+            </label>
+            <p className="text-xs text-text-dim mt-1">
+              Connects automatically when MongoPal opens
+            </p>
+          </div>
+        </div>
       </div>
 
       {/* Visual Preview */}
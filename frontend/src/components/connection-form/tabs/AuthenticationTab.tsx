@@ -1,3 +1,4 @@
+import { useEffect, useState } from 'react';
 import type { ConnectionFormData, AuthMechanism } from '../ConnectionFormTypes';
 import type { ValidationError } from '../ConnectionFormTypes';
 import { FieldWithError } from '../components/FieldWithError';
@@ -22,14 +23,27 @@ export function AuthenticationTab({
 }: AuthenticationTabProps) {
   const getError = (field: string) => errors.find(e => e.field === field && e.severity === 'error')?.message;
 
-  const authMechanisms: { value: AuthMechanism; label: string }[] = [
+  // Builds without the gssapi tag cannot authenticate with Kerberos
+  const [kerberosSupported, setKerberosSupported] = useState(true);
+  useEffect(() => {
+    window.go?.main?.App?.IsKerberosSupported?.()
+      .then(setKerberosSupported)
+      .catch(() => {});
+  }, []);
+
+  const allAuthMechanisms: { value: AuthMechanism; label: string }[] = [
     { value: 'none', label: 'None' },
     { value: 'scram-sha-1', label: 'SCRAM-SHA-1' },
     { value: 'scram-sha-256', label: 'SCRAM-SHA-256' },
     { value: 'x509', label: 'X.509 Certificate' },
     { value: 'mongodb-aws', label: 'MongoDB AWS (IAM)' },
-    { value: 'kerberos', label: 'Kerberos (GSSAPI)' },
+    { value: 'kerberos', label: kerberosSupported ? 'Kerberos (GSSAPI)' : 'Kerberos (GSSAPI, not in this build)' },
+    { value: 'plain', label: 'LDAP (PLAIN)' },
   ];
+  // Kerberos stays listed for a connection that already uses it, so its mechanism still shows
+  const authMechanisms = allAuthMechanisms.filter(
+    mech => mech.value !== 'kerberos' || kerberosSupported || data.authMechanism === 'kerberos'
+  );
 
   const handleAuthMechanismChange = (mechanism: AuthMechanism) => {
     const updates: Partial<ConnectionFormData> = { authMechanism: mechanism };
@@ -37,7 +51,7 @@ export function AuthenticationTab({
     // Auto-set authSource for specific mechanisms
     if (mechanism === 'mongodb-aws') {
       updates.authDatabase = '$external';
-    } else if (mechanism === 'x509') {
+    } else if (mechanism === 'x509' || mechanism === 'kerberos' || mechanism === 'plain') {
       updates.authDatabase = '$external';
     }
 
@@ -75,7 +89,8 @@ export function AuthenticationTab({
           <FieldWithError
             label="Username"
             error={getError('username')}
-            required
+            required={data.authMechanism !== 'x509'}
+            helpText={data.authMechanism === 'x509' ? 'Optional: taken from the client certificate subject when empty' : undefined}
           >
             <input
               type="text"
@@ -118,7 +133,7 @@ export function AuthenticationTab({
                 onChange={e => onChange({ authDatabase: e.target.value })}
                 className="w-full px-2 py-1.5 bg-surface border border-border rounded text-text text-sm focus:outline-none focus:ring-2 focus:ring-primary"
                 placeholder="admin"
-                disabled={data.authMechanism === 'mongodb-aws' || data.authMechanism === 'x509'}
+                disabled={data.authMechanism === 'mongodb-aws' || data.authMechanism === 'x509' || data.authMechanism === 'kerberos' || data.authMechanism === 'plain'}
                 id="field-authDatabase"
               />
             </FieldWithError>
@@ -126,6 +141,52 @@ export function AuthenticationTab({
         </>
       )}
 
+      {/* Kerberos service settings */}
+      {data.authMechanism === 'kerberos' && (
+        <>
+          <FieldWithError
+            label="Service Name"
+            helpText="Kerberos service name of the MongoDB servers (default: mongodb)"
+          >
+            <input
+              type="text"
+              value={data.gssapiServiceName || ''}
+              onChange={e => onChange({ gssapiServiceName: e.target.value })}
+              className="w-full px-2 py-1.5 bg-surface border border-border rounded text-text text-sm focus:outline-none focus:ring-2 focus:ring-primary"
+              placeholder="mongodb"
+              id="field-gssapiServiceName"
+            />
+          </FieldWithError>
+
+          <FieldWithError
+            label="Service Realm"
+            helpText="Only needed when the service is in a different realm than the user"
+          >
+            <input
+              type="text"
+              value={data.gssapiServiceRealm || ''}
+              onChange={e => onChange({ gssapiServiceRealm: e.target.value })}
+              className="w-full px-2 py-1.5 bg-surface border border-border rounded text-text text-sm focus:outline-none focus:ring-2 focus:ring-primary"
+              placeholder="EXAMPLE.COM"
+              id="field-gssapiServiceRealm"
+            />
+          </FieldWithError>
+
+          <div className="flex items-center gap-2">
+            <input
+              type="checkbox"
+              id="gssapiCanonicalizeHost"
+              checked={data.gssapiCanonicalizeHost || false}
+              onChange={e => onChange({ gssapiCanonicalizeHost: e.target.checked })}
+              className="w-4 h-4 bg-surface border-border rounded focus:ring-2 focus:ring-primary"
+            />
+            <label htmlFor="gssapiCanonicalizeHost" className="text-sm text-text-secondary">
+              Canonicalize host name (use the host's DNS canonical name in the service principal)
+            </label>
+          </div>
+        </>
+      )}
+
       {/* AWS IAM info */}
       {data.authMechanism === 'mongodb-aws' && (
         <div className="p-4 bg-blue-500/10 border border-blue-500/30 rounded-md">
@@ -136,6 +197,15 @@ export function AuthenticationTab({
         </div>
       )}
 
+      {/* LDAP without TLS sends the password in clear text */}
+      {data.authMechanism === 'plain' && !data.tlsEnabled && (
+        <div className="p-4 bg-yellow-500/10 border border-yellow-500/30 rounded-md">
+          <p className="text-sm text-yellow-300">
+            LDAP (PLAIN) authentication sends the password unencrypted. Enable TLS in the Network tab.
+          </p>
+        </div>
+      )}
+
       {/* X.509 requires TLS notice */}
       {data.authMechanism === 'x509' && !data.tlsEnabled && (
         <div className="p-4 bg-yellow-500/10 border border-yellow-500/30 rounded-md">
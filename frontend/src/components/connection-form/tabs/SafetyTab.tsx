@@ -8,6 +8,11 @@ interface SafetyTabProps {
   onChange: (updates: Partial<ConnectionFormData>) => void;
 }
 
+// parseDatabaseList splits a comma-separated list of database names or patterns.
+function parseDatabaseList(value: string): string[] {
+  return value.split(',').map(s => s.trim()).filter(Boolean);
+}
+
 export function SafetyTab({ data, errors, onChange }: SafetyTabProps) {
   const getError = (field: string) => errors.find(e => e.field === field && e.severity === 'error')?.message;
 
@@ -15,6 +20,24 @@ export function SafetyTab({ data, errors, onChange }: SafetyTabProps) {
     <div className="space-y-4 p-4">
       <h3 className="text-base font-semibold text-text mb-4">Destructive Operation Safety</h3>
       <div className="space-y-4">
+        <FieldWithError
+          label="Environment"
+          error={getError('environment')}
+          helpText="Production connections always require typed confirmation and at least the delay set in app settings"
+        >
+          <select
+            value={data.environment || ''}
+            onChange={e => onChange({ environment: e.target.value as ConnectionFormData['environment'] })}
+            className="w-full px-3 py-2 bg-surface border border-border rounded-md text-text"
+            id="field-environment"
+          >
+            <option value="">None</option>
+            <option value="dev">Development</option>
+            <option value="staging">Staging</option>
+            <option value="prod">Production</option>
+          </select>
+        </FieldWithError>
+
         <FieldWithError
           label="Delay Before Destructive Operations"
           error={getError('destructiveDelay')}
@@ -55,6 +78,39 @@ export function SafetyTab({ data, errors, onChange }: SafetyTabProps) {
           </div>
         </div>
       </div>
+
+      <h3 className="text-base font-semibold text-text mt-6 mb-4">Database Access</h3>
+      <div className="space-y-4">
+        <FieldWithError
+          label="Allowed Databases"
+          error={getError('allowedDatabases')}
+          helpText="Comma-separated names or patterns (e.g. app, team_*). When set, only these databases are listed and usable"
+        >
+          <input
+            type="text"
+            defaultValue={(data.allowedDatabases || []).join(', ')}
+            onBlur={e => onChange({ allowedDatabases: parseDatabaseList(e.target.value) })}
+            placeholder="All databases"
+            className="w-full px-3 py-2 bg-surface border border-border rounded-md text-text"
+            id="field-allowedDatabases"
+          />
+        </FieldWithError>
+
+        <FieldWithError
+          label="Denied Databases"
+          error={getError('deniedDatabases')}
+          helpText="Comma-separated names or patterns that are never listed or usable, even if allowed"
+        >
+          <input
+            type="text"
+            defaultValue={(data.deniedDatabases || []).join(', ')}
+            onBlur={e => onChange({ deniedDatabases: parseDatabaseList(e.target.value) })}
+            placeholder="None"
+            className="w-full px-3 py-2 bg-surface border border-border rounded-md text-text"
+            id="field-deniedDatabases"
+          />
+        </FieldWithError>
+      </div>
     </div>
   );
 }
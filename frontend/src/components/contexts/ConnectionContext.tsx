@@ -2,6 +2,7 @@ import React, { createContext, useContext, useState, useEffect, useCallback, use
 import { useNotification } from '../NotificationContext'
 import { useDebugLog, DEBUG_CATEGORIES } from './DebugContext'
 import { getErrorSummary } from '../../utils/errorParser'
+import { EventsOn } from '../../../wailsjs/runtime/runtime'
 
 // =============================================================================
 // Type Definitions
@@ -16,6 +17,8 @@ export interface SavedConnection {
   folderId?: string
   uri: string
   color: string
+  environment?: 'prod' | 'staging' | 'dev'
+  autoConnect?: boolean
   createdAt: string | Date
 }
 
@@ -28,6 +31,30 @@ export interface Folder {
   parentId?: string
 }
 
+/**
+ * Background health check of a connected client (matches Go types.ConnectionHealth)
+ */
+export interface ConnectionHealth {
+  connectionId: string
+  status: 'healthy' | 'degraded' | 'down'
+  event?: 'degraded' | 'down' | 'recovered'
+  latencyMs: number
+  avgLatencyMs: number
+  failures: number
+  error?: string
+  checkedAt: string
+}
+
+/**
+ * Progress of connecting a saved connection on startup (matches Go types.AutoConnectStatus)
+ */
+export interface AutoConnectStatus {
+  connectionId: string
+  status: 'connecting' | 'connected' | 'failed'
+  error?: string
+  durationMs?: number
+}
+
 /**
  * Go bindings interface for connection operations (local partial type)
  */
@@ -39,6 +66,7 @@ interface ConnectionAppBindings {
   DisconnectAll?: () => Promise<void>
   DeleteSavedConnection?: (connId: string) => Promise<void>
   DuplicateConnection?: (connId: string, newName: string) => Promise<SavedConnection>
+  GetAutoConnectStatus?: () => Promise<AutoConnectStatus[]>
 
   // Folder management
   ListFolders?: () => Promise<Folder[]>
@@ -64,6 +92,7 @@ export interface ConnectionContextValue {
   folders: Folder[]
   activeConnections: string[]
   connectingIds: Set<string>
+  connectionHealth: Record<string, ConnectionHealth>
   selectedConnection: string | null
   selectedDatabase: string | null
   selectedCollection: string | null
@@ -136,11 +165,66 @@ export function ConnectionProvider({ children }: ConnectionProviderProps): React
   // UI state - track multiple simultaneous connections
   const [connectingIds, setConnectingIds] = useState<Set<string>>(new Set())
 
+  // Live health of connected clients, reported by the backend health monitor
+  const [connectionHealth, setConnectionHealth] = useState<Record<string, ConnectionHealth>>({})
+
   // Load saved connections on mount
   useEffect(() => {
     loadConnections()
   }, [])
 
+  useEffect(() => {
+    const unsub = EventsOn('connection:health', (health: ConnectionHealth) => {
+      setConnectionHealth(prev => ({ ...prev, [health.connectionId]: health }))
+      const connName = connections.find(c => c.id === health.connectionId)?.name || 'Connection'
+      if (health.event === 'down') {
+        notify.error(`${connName} is not responding${health.error ? `: ${getErrorSummary(health.error)}` : ''}`)
+      } else if (health.event === 'recovered') {
+        notify.success(`${connName} is responding again`, { silent: true })
+      }
+    })
+    return () => unsub?.()
+  }, [connections, notify])
+
+  // Follow connections the backend connects on startup
+  const applyAutoConnect = useCallback((status: AutoConnectStatus): void => {
+    setConnectingIds(prev => {
+      const next = new Set(prev)
+      if (status.status === 'connecting') next.add(status.connectionId)
+      else next.delete(status.connectionId)
+      return next
+    })
+    if (status.status === 'connected') {
+      setActiveConnections(prev => prev.includes(status.connectionId) ? prev : [...prev, status.connectionId])
+    }
+  }, [])
+
+  // Catch up on auto-connect events emitted before the listener below was registered
+  useEffect(() => {
+    getGo()?.GetAutoConnectStatus?.()
+      .then(statuses => (statuses || []).forEach(applyAutoConnect))
+      .catch(err => console.error('Failed to get auto-connect status:', err))
+  }, [applyAutoConnect])
+
+  useEffect(() => {
+    const unsub = EventsOn('connection:autoconnect', (status: AutoConnectStatus) => {
+      applyAutoConnect(status)
+      if (status.status === 'failed') {
+        const connName = connections.find(c => c.id === status.connectionId)?.name || 'Connection'
+        notify.error(`${connName}: ${getErrorSummary(status.error || 'auto-connect failed')}`)
+      }
+    })
+    return () => unsub?.()
+  }, [connections, notify, applyAutoConnect])
+
+  // Forget the health of disconnected clients
+  useEffect(() => {
+    setConnectionHealth(prev => {
+      const kept = Object.fromEntries(Object.entries(prev).filter(([id]) => activeConnections.includes(id)))
+      return Object.keys(kept).length === Object.keys(prev).length ? prev : kept
+    })
+  }, [activeConnections])
+
   const loadConnections = useCallback(async (): Promise<void> => {
     try {
       const go = getGo()
@@ -376,6 +460,7 @@ export function ConnectionProvider({ children }: ConnectionProviderProps): React
     folders,
     activeConnections,
     connectingIds,
+    connectionHealth,
     selectedConnection,
     selectedDatabase,
     selectedCollection,
@@ -410,7 +495,7 @@ export function ConnectionProvider({ children }: ConnectionProviderProps): React
     loadConnections,
     isConnecting,
   }), [
-    connections, folders, activeConnections, connectingIds,
+    connections, folders, activeConnections, connectingIds, connectionHealth,
     selectedConnection, selectedDatabase, selectedCollection,
     connect, disconnect, disconnectAll, disconnectOthers,
     deleteConnection, duplicateConnection, refreshConnection,
@@ -17,6 +17,7 @@ export function ConnectionNode({
   connection,
   isConnected,
   isConnecting,
+  health,
   databases,
 
   // Per-connection callbacks
@@ -301,12 +302,21 @@ export function ConnectionNode({
     if (isConnecting) {
       return `Connecting to ${connection.name}... Please wait`
     }
+    if (isConnected && health && health.status !== 'healthy') {
+      if (health.status === 'down') {
+        return `${connection.name} is not responding${health.error ? ` (${health.error})` : ''}`
+      }
+      return health.failures > 0
+        ? `${connection.name} missed a health check - Right-click for options`
+        : `${connection.name} is slow (${Math.round(health.avgLatencyMs)} ms average) - Right-click for options`
+    }
     if (isConnected) {
+      const latency = health ? `, ${Math.round(health.avgLatencyMs)} ms` : ''
       const dbCount = databases.length
       if (dbCount > 0) {
-        return `Connected to ${connection.name} (${dbCount} database${dbCount !== 1 ? 's' : ''}) - Right-click for options`
+        return `Connected to ${connection.name} (${dbCount} database${dbCount !== 1 ? 's' : ''})${latency} - Right-click for options`
       }
-      return `Connected to ${connection.name} - Right-click for options`
+      return `Connected to ${connection.name}${latency} - Right-click for options`
     }
     return `Disconnected - Click to connect to ${connection.name}`
   }
@@ -330,6 +340,7 @@ export function ConnectionNode({
       icon={<ServerIcon />}
       color={connection.color}
       connectionStatus={connectionStatus}
+      healthStatus={health?.status}
       statusTooltip={getStatusTooltip()}
       level={level}
       expanded={expanded}
@@ -12,6 +12,7 @@ import type {
   ContextMenuItem,
   ContextMenuState,
   ConfirmDialogState,
+  DestructiveConfirmation,
   SearchResults,
   ConnectionMatchInfo,
   ExtendedSavedConnection,
@@ -56,6 +57,7 @@ export default function Sidebar({
     connections,
     folders,
     activeConnections,
+    connectionHealth,
     isConnecting,
     connect,
     disconnect,
@@ -716,22 +718,33 @@ export default function Sidebar({
     await disconnectOthers(keepConnId, keepOnlyConnectionTabs)
   }
 
-  const handleDropDatabase = (connId: string, dbName: string, removeFromState: (dbName: string) => void): void => {
+  // Opens a confirmation dialog for a destructive operation. Connections with safety settings
+  // get a backend-issued token: the dialog waits out its delay and asks for the typed text, and
+  // the token is confirmed before the operation runs.
+  const openDestructiveDialog = async (
+    connId: string,
+    operation: string,
+    target: string,
+    dialog: Omit<ConfirmDialogState, 'onConfirm' | 'requireText' | 'delaySeconds'>,
+    action: () => Promise<void>
+  ): Promise<void> => {
+    let confirmation: DestructiveConfirmation | undefined
+    try {
+      confirmation = await go?.RequestDestructiveConfirmation?.(connId, operation, target)
+    } catch (err) {
+      notify.error(getErrorSummary(err instanceof Error ? err.message : String(err)))
+      return
+    }
     setConfirmDialog({
-      title: `Drop Database "${dbName}"?`,
-      message: `This will permanently delete the database "${dbName}" and ALL its collections. This action cannot be undone.`,
-      confirmText: 'Drop Database',
-      confirmStyle: 'danger',
-      onConfirm: async () => {
+      ...dialog,
+      requireText: confirmation?.required ? confirmation.confirmationText : undefined,
+      delaySeconds: confirmation?.required ? confirmation.delaySeconds : 0,
+      onConfirm: async (typed: string) => {
         try {
-          await dropDatabase(connId, dbName)
-          closeTabsForDatabase(connId, dbName)
-          removeFromState?.(dbName)
-          setDatabases(prev => ({
-            ...prev,
-            [connId]: (prev[connId] || []).filter(db => db.name !== dbName)
-          }))
-          notify.success(`Database "${dbName}" dropped`)
+          if (confirmation?.required && confirmation.token) {
+            await go?.ConfirmDestructiveOperation?.(confirmation.token, typed)
+          }
+          await action()
           setConfirmDialog(null)
         } catch (err) {
           notify.error(getErrorSummary(err instanceof Error ? err.message : String(err)))
@@ -740,41 +753,47 @@ export default function Sidebar({
     })
   }
 
+  const handleDropDatabase = (connId: string, dbName: string, removeFromState: (dbName: string) => void): void => {
+    void openDestructiveDialog(connId, 'drop database', dbName, {
+      title: `Drop Database "${dbName}"?`,
+      message: `This will permanently delete the database "${dbName}" and ALL its collections. This action cannot be undone.`,
+      confirmText: 'Drop Database',
+      confirmStyle: 'danger',
+    }, async () => {
+      await dropDatabase(connId, dbName)
+      closeTabsForDatabase(connId, dbName)
+      removeFromState?.(dbName)
+      setDatabases(prev => ({
+        ...prev,
+        [connId]: (prev[connId] || []).filter(db => db.name !== dbName)
+      }))
+      notify.success(`Database "${dbName}" dropped`)
+    })
+  }
+
   const handleDropCollection = (connId: string, dbName: string, collName: string, removeFromState: (dbName: string, collName: string) => void): void => {
-    setConfirmDialog({
+    void openDestructiveDialog(connId, 'drop collection', `${dbName}.${collName}`, {
       title: `Drop Collection "${collName}"?`,
       message: `This will permanently delete the collection "${collName}" and ALL its documents. This action cannot be undone.`,
       confirmText: 'Drop Collection',
       confirmStyle: 'danger',
-      onConfirm: async () => {
-        try {
-          await dropCollection(connId, dbName, collName)
-          closeTabsForCollection(connId, dbName, collName)
-          removeFromState?.(dbName, collName)
-          notify.success(`Collection "${collName}" dropped`)
-          setConfirmDialog(null)
-        } catch (err) {
-          notify.error(getErrorSummary(err instanceof Error ? err.message : String(err)))
-        }
-      },
+    }, async () => {
+      await dropCollection(connId, dbName, collName)
+      closeTabsForCollection(connId, dbName, collName)
+      removeFromState?.(dbName, collName)
+      notify.success(`Collection "${collName}" dropped`)
     })
   }
 
   const handleClearCollection = (connId: string, dbName: string, collName: string): void => {
-    setConfirmDialog({
+    void openDestructiveDialog(connId, 'clear collection', `${dbName}.${collName}`, {
       title: `Clear Collection "${collName}"?`,
       message: `This will delete ALL documents in the collection "${collName}". The collection structure will be preserved. This action cannot be undone.`,
       confirmText: 'Clear Collection',
       confirmStyle: 'danger',
-      onConfirm: async () => {
-        try {
-          await clearCollection(connId, dbName, collName)
-          notify.success(`Collection "${collName}" cleared`)
-          setConfirmDialog(null)
-        } catch (err) {
-          notify.error(getErrorSummary(err instanceof Error ? err.message : String(err)))
-        }
-      },
+    }, async () => {
+      await clearCollection(connId, dbName, collName)
+      notify.success(`Collection "${collName}" cleared`)
     })
   }
 
@@ -969,6 +988,7 @@ export default function Sidebar({
         connection={conn}
         isConnected={activeConnections.includes(conn.id)}
         isConnecting={isConnecting(conn.id)}
+        health={connectionHealth[conn.id]}
         databases={databases[conn.id] || []}
         onEdit={() => onEditConnection(conn)}
         onDelete={() => onDeleteConnection(conn.id)}
@@ -1182,6 +1202,8 @@ export default function Sidebar({
             message={confirmDialog.message}
             confirmLabel={confirmDialog.confirmText}
             danger={confirmDialog.confirmStyle === 'danger'}
+            requireText={confirmDialog.requireText}
+            delaySeconds={confirmDialog.delaySeconds}
             onConfirm={confirmDialog.onConfirm}
             onCancel={() => setConfirmDialog(null)}
           />
@@ -17,6 +17,7 @@ export function TreeNode({
   level = 0,
   color,
   connectionStatus,
+  healthStatus,
   statusTooltip,
   isFavorite,
   onToggleFavorite,
@@ -45,9 +46,10 @@ export function TreeNode({
     }
 
     if (connectionStatus === 'connected') {
+      const dotColor = healthStatus === 'down' ? 'bg-red-500' : healthStatus === 'degraded' ? 'bg-yellow-500' : 'bg-green-500'
       return (
         <span
-          className="w-2 h-2 rounded-full flex-shrink-0 bg-green-500"
+          className={`w-2 h-2 rounded-full flex-shrink-0 ${dotColor}`}
           title={statusTooltip || "Connected - Right-click for options"}
         />
       )
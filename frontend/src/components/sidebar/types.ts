@@ -1,5 +1,5 @@
 import type { ReactNode } from 'react'
-import type { SavedConnection, Folder } from '../contexts/ConnectionContext'
+import type { SavedConnection, Folder, ConnectionHealth } from '../contexts/ConnectionContext'
 
 // =============================================================================
 // Go Bindings
@@ -22,6 +22,8 @@ export interface CollectionInfo {
   name: string
   type?: string
   count: number
+  viewOn?: string
+  pipeline?: string
 }
 
 /**
@@ -38,6 +40,23 @@ export interface SidebarGoBindings {
   RemoveDatabaseFavorite?: (connId: string, dbName: string) => Promise<void>
   UpdateDatabaseAccessed?: (connId: string, dbName: string) => Promise<void>
   UpdateFolder?: (folderId: string, name: string, parentId: string) => Promise<void>
+  RequestDestructiveConfirmation?: (connId: string, operation: string, target: string) => Promise<DestructiveConfirmation>
+  ConfirmDestructiveOperation?: (token: string, typed: string) => Promise<void>
+}
+
+/**
+ * Backend-issued token that authorizes one destructive operation on a connection
+ * with safety settings (destructive delay, typed confirmation)
+ */
+export interface DestructiveConfirmation {
+  required: boolean
+  token?: string
+  operation: string
+  target: string
+  delaySeconds: number
+  confirmationText?: string
+  validAfter: string
+  expiresAt: string
 }
 
 export const go: SidebarGoBindings | undefined = window.go?.main?.App as SidebarGoBindings | undefined
@@ -141,7 +160,11 @@ export interface ConfirmDialogState {
   message: string
   confirmText: string
   confirmStyle: 'danger' | 'primary'
-  onConfirm: () => Promise<void>
+  /** Text the user must type before confirming */
+  requireText?: string
+  /** Seconds before the confirm button is enabled */
+  delaySeconds?: number
+  onConfirm: (typed: string) => Promise<void>
 }
 
 // =============================================================================
@@ -211,6 +234,7 @@ export interface TreeNodeProps {
   level?: number
   color?: string
   connectionStatus?: ConnectionStatus
+  healthStatus?: ConnectionHealth['status']
   statusTooltip?: string
   isFavorite?: boolean
   onToggleFavorite?: () => void
@@ -324,6 +348,7 @@ export interface ConnectionNodeProps {
   connection: ExtendedSavedConnection
   isConnected: boolean
   isConnecting: boolean
+  health?: ConnectionHealth
   databases: DatabaseInfoWithAccess[]
 
   // Per-connection callbacks
@@ -172,7 +172,7 @@ export function useBulkActions({
       if (go?.DeleteDocument) {
         const docId = getDocIdForApi(deleteDoc)
         if (docId) {
-          await go.DeleteDocument(connectionId, database, collection, docId)
+          await go.DeleteDocument(connectionId, database, collection, docId, null)
           notify.success('Document deleted')
           setDeleteDoc(null)
           onRefresh()
@@ -206,7 +206,7 @@ export function useBulkActions({
     for (let i = 0; i < idsToDelete.length; i++) {
       try {
         if (go?.DeleteDocument) {
-          await go.DeleteDocument(connectionId, database, collection, idsToDelete[i])
+          await go.DeleteDocument(connectionId, database, collection, idsToDelete[i], null)
           successCount++
         }
       } catch (err) {
@@ -275,7 +275,7 @@ export function useBulkActions({
       const defaultFilename = `${collection}-export-${timestamp}.zip`
 
       if (go?.ExportDocumentsAsZip) {
-        await go.ExportDocumentsAsZip(entries, defaultFilename)
+        await go.ExportDocumentsAsZip(connectionId, entries, defaultFilename)
         notify.success(`Exported ${entries.length} document${entries.length !== 1 ? 's' : ''}`)
       }
     } catch (err) {
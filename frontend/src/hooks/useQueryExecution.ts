@@ -688,7 +688,14 @@ export function useQueryExecution({
       const filter = parseFilterFromQuery(query)
       const go = getGo()
       if (go?.ExplainQuery) {
-        const result = await go.ExplainQuery(connectionId, database, collection, filter)
+        const result = await go.ExplainQuery(
+          connectionId,
+          database,
+          collection,
+          filter,
+          { skip, limit, sort: '', projection: '' } as Parameters<typeof go.ExplainQuery>[4],
+          'executionStats'
+        )
         setExplainResult(result as unknown as ExplainResult)
       }
     } catch (err) {
@@ -702,7 +709,7 @@ export function useQueryExecution({
     } finally {
       setExplaining(false)
     }
-  }, [query, notify, connectionId, database, collection])
+  }, [query, notify, connectionId, database, collection, skip, limit])
 
   return {
     // Query state
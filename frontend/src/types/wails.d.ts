@@ -8,6 +8,8 @@
 
 import { main } from '../../wailsjs/go/models'
 import type { TestConnectionResult } from '../components/connection-form/ConnectionFormTypes'
+import type { ConnectionHealth, AutoConnectStatus } from '../components/contexts/ConnectionContext'
+import type { DestructiveConfirmation } from '../components/sidebar/types'
 
 /**
  * Wails App bindings - all methods exposed from the Go backend
@@ -18,24 +20,62 @@ export interface WailsAppBindings {
   Disconnect(connectionId: string): Promise<void>
   DisconnectAll(): Promise<void>
   TestConnection(uri: string, connID: string): Promise<TestConnectionResult>
+  TestConnectionWithSettings?(uri: string, connection: Partial<main.ExtendedConnection>): Promise<TestConnectionResult>
+  IsKerberosSupported?(): Promise<boolean>
+  GetConnectionHealth?(connectionId: string): Promise<ConnectionHealth>
+  GetPoolStats?(connectionId: string): Promise<PoolStats>
+  KillSessions?(connId: string): Promise<number>
+
+  // Monitoring
+  StartMetrics?(connId: string, intervalSeconds: number): Promise<void>
+  StopMetrics?(connId: string): Promise<void>
+  GetMetricsHistory?(connId: string): Promise<MetricsSample[]>
+  GetAlertRules?(connId: string): Promise<AlertRule[]>
+  SetAlertRules?(connId: string, rules: AlertRule[]): Promise<void>
+  GetActiveAlerts?(connId: string): Promise<Alert[]>
+  ListCurrentOperations?(connId: string, filter: CurrentOpFilter): Promise<CurrentOperation[]>
+  KillOperation?(connId: string, opId: string): Promise<void>
+  GetServerLog?(
+    connId: string,
+    logType: 'global' | 'startupWarnings' | '',
+    tail: number,
+    filter: ServerLogFilter
+  ): Promise<ServerLog>
+  GetHotCollections?(connId: string): Promise<HotCollectionReport>
+  GetAutoConnectStatus?(): Promise<AutoConnectStatus[]>
 
   // Saved connections
   ListSavedConnections(): Promise<main.SavedConnection[]>
+  ListRecentConnections?(limit: number): Promise<main.SavedConnection[]>
   DeleteSavedConnection(connectionId: string): Promise<void>
   DuplicateConnection(connectionId: string, newName: string): Promise<main.SavedConnection>
   ConnectionFromURI(uri: string): Promise<main.SavedConnection>
   ConnectionToURI(connectionId: string): Promise<string>
+  BuildURIFromFormData?(formJSON: string): Promise<string>
   MoveConnectionToFolder(connectionId: string, folderId: string): Promise<void>
 
   // Extended connections (F074)
   GetExtendedConnection(connectionId: string): Promise<main.ExtendedConnection>
   SaveExtendedConnection(connection: main.ExtendedConnection): Promise<void>
 
+  // Credential vault (fallback for systems without an OS keyring)
+  GetCredentialStoreStatus?(): Promise<CredentialStoreStatus>
+  CreateCredentialVault?(masterPassword: string): Promise<number>
+  UnlockCredentialVault?(masterPassword: string): Promise<void>
+  MigrateCredentialsToKeyring?(): Promise<number>
+
   // Encrypted connection sharing
   ExportEncryptedConnection(connectionId: string): Promise<ConnectionShareResult>
   ExportEncryptedConnectionFromForm(formDataJSON: string): Promise<ConnectionShareResult>
   ExportEncryptedConnections(connectionIds: string[]): Promise<BulkConnectionShareResult>
   DecryptConnectionImport(bundleJSON: string, key: string): Promise<string>
+  ImportConnectionBundle?(bundleJSON: string, key: string): Promise<ConnectionImportResult>
+  ImportConnectionBundles?(bulkJSON: string, key: string): Promise<ConnectionImportResult>
+  ExportAllConnections?(passphrase: string): Promise<string>
+  RestoreConnectionBackup?(passphrase: string): Promise<ConnectionImportResult | null>
+  ImportFromCompass?(path: string, passphrase: string): Promise<ConnectionImportResult>
+  ImportFromStudio3T?(path: string): Promise<ConnectionImportResult>
+  ImportFromRobo3T?(path: string): Promise<ConnectionImportResult>
 
   // Folder methods
   ListFolders(): Promise<main.Folder[]>
@@ -47,8 +87,37 @@ export interface WailsAppBindings {
   ListDatabases(connectionId: string): Promise<main.DatabaseInfo[]>
   ListCollections(connectionId: string, database: string): Promise<main.CollectionInfo[]>
   DropDatabase(connectionId: string, database: string): Promise<void>
+  RequestDestructiveConfirmation?(connectionId: string, operation: string, target: string): Promise<DestructiveConfirmation>
+  ConfirmDestructiveOperation?(token: string, typed: string): Promise<void>
+  CreateDatabase?(connectionId: string, database: string, initialCollection: string): Promise<void>
+  CreateCollection?(
+    connectionId: string,
+    database: string,
+    collection: string,
+    optionsJson: string
+  ): Promise<void>
   DropCollection(connectionId: string, database: string, collection: string): Promise<void>
+  RenameCollection?(
+    connectionId: string,
+    database: string,
+    oldName: string,
+    newName: string,
+    dropTarget: boolean
+  ): Promise<void>
   ClearCollection(connectionId: string, database: string, collection: string): Promise<void>
+  CreateView?(
+    connectionId: string,
+    database: string,
+    viewName: string,
+    viewOn: string,
+    pipelineJson: string
+  ): Promise<void>
+  UpdateViewPipeline?(
+    connectionId: string,
+    database: string,
+    viewName: string,
+    pipelineJson: string
+  ): Promise<void>
 
   // Document methods
   FindDocuments(
@@ -58,16 +127,31 @@ export interface WailsAppBindings {
     query: string,
     options: main.QueryOptions
   ): Promise<main.QueryResult>
+  GetQueryCount?(countId: string): Promise<QueryCount | null>
   GetDocument(connectionId: string, database: string, collection: string, documentId: string): Promise<string>
-  InsertDocument(connectionId: string, database: string, collection: string, document: string): Promise<string>
+  InsertDocument(
+    connectionId: string,
+    database: string,
+    collection: string,
+    document: string,
+    writeConcern: main.WriteConcern | null
+  ): Promise<string>
   UpdateDocument(
     connectionId: string,
     database: string,
     collection: string,
     documentId: string,
-    document: string
+    document: string,
+    upsert: boolean,
+    writeConcern: main.WriteConcern | null
+  ): Promise<void>
+  DeleteDocument(
+    connectionId: string,
+    database: string,
+    collection: string,
+    documentId: string,
+    writeConcern: main.WriteConcern | null
   ): Promise<void>
-  DeleteDocument(connectionId: string, database: string, collection: string, documentId: string): Promise<void>
 
   // Index methods
   ListIndexes(connectionId: string, database: string, collection: string): Promise<main.IndexInfo[]>
@@ -75,15 +159,27 @@ export interface WailsAppBindings {
     connectionId: string,
     database: string,
     collection: string,
-    keys: Record<string, number>,
+    keysJson: string,
     options: CreateIndexOptions
-  ): Promise<void>
+  ): Promise<string>
   DropIndex?(
     connectionId: string,
     database: string,
     collection: string,
     indexName: string
   ): Promise<void>
+  HideIndex?(
+    connectionId: string,
+    database: string,
+    collection: string,
+    indexName: string
+  ): Promise<void>
+  UnhideIndex?(
+    connectionId: string,
+    database: string,
+    collection: string,
+    indexName: string
+  ): Promise<void>
 
   // Validation
   ValidateJSON(json: string): Promise<void>
@@ -102,12 +198,40 @@ export interface WailsAppBindings {
     collection: string
   ): Promise<CollectionProfile>
 
+  // Database profiler
+  GetProfilerStatus?(connectionId: string, database: string): Promise<ProfilerStatus>
+  SetProfilerLevel?(connectionId: string, database: string, level: number, slowms: number): Promise<void>
+  ListProfiledOperations?(
+    connectionId: string,
+    database: string,
+    filter: string,
+    limit: number
+  ): Promise<ProfiledOperation[]>
+
   // Collection stats
   GetCollectionStats?(
     connectionId: string,
     database: string,
     collection: string
   ): Promise<CollectionStats>
+  GetDatabaseStats?(connectionId: string, database: string): Promise<DatabaseStats>
+  GetShardingInfo?(
+    connectionId: string,
+    database: string,
+    collection: string
+  ): Promise<ShardingInfo>
+  EnableSharding?(connectionId: string, database: string): Promise<void>
+  ShardCollection?(
+    connectionId: string,
+    database: string,
+    collection: string,
+    shardKeyJson: string,
+    unique: boolean
+  ): Promise<void>
+  GetClusterTopology?(connectionId: string): Promise<ClusterTopology>
+  GetBalancerStatus?(connectionId: string): Promise<BalancerStatus>
+  SetBalancerEnabled?(connectionId: string, enabled: boolean): Promise<void>
+  SetBalancerWindow?(connectionId: string, start: string, stop: string): Promise<void>
 
   // Schema methods (may be added via backend)
   InferCollectionSchema?(
@@ -153,7 +277,8 @@ export interface WailsAppBindings {
     connectionId: string,
     database: string,
     collection: string,
-    pipeline: string
+    pipeline: string,
+    options: AggregateOptions
   ): Promise<AggregationResult>
   ExplainAggregation?(
     connectionId: string,
@@ -162,12 +287,17 @@ export interface WailsAppBindings {
     pipeline: string
   ): Promise<ExplainResult>
 
+  // Raw server command (reply as Extended JSON)
+  RunCommand?(connectionId: string, database: string, commandJson: string): Promise<string>
+
   // Query explain methods
   ExplainQuery?(
     connectionId: string,
     database: string,
     collection: string,
-    query: string
+    query: string,
+    options: main.QueryOptions,
+    verbosity: string
   ): Promise<ExplainResult>
 
   // Script execution methods (mongosh)
@@ -239,12 +369,39 @@ export interface WailsAppBindings {
 
   // Document export methods
   ExportDocumentsAsZip?(
+    connectionId: string,
     entries: ExportEntry[],
     filename: string
   ): Promise<void>
 
   // Server info
   GetServerInfo?(connectionId: string): Promise<ServerInfo>
+  GetReplicationLag?(connectionId: string): Promise<ReplicationLag>
+  GetReplicationLagHistory?(connectionId: string): Promise<ReplicationLag[]>
+  GetFCV?(connectionId: string): Promise<FeatureCompatibility>
+  SetFCV?(connectionId: string, version: string): Promise<void>
+  GetReplSetConfig?(connectionId: string): Promise<ReplSetConfig>
+  AddReplSetMember?(connectionId: string, member: ReplSetMemberConfig): Promise<void>
+  RemoveReplSetMember?(connectionId: string, host: string): Promise<void>
+  SetMemberPriority?(connectionId: string, host: string, priority: number): Promise<void>
+  CompareReplicaMembers?(connectionId: string, database: string): Promise<ReplicaConsistencyReport>
+  ResetSSHHostKey?(connectionId: string): Promise<void>
+
+  // User and role management
+  ListUsers?(connectionId: string, database: string): Promise<DatabaseUser[]>
+  CreateUser?(connectionId: string, database: string, user: NewDatabaseUser): Promise<void>
+  UpdateUserRoles?(connectionId: string, database: string, username: string, roles: RoleRef[]): Promise<void>
+  ChangeUserPassword?(connectionId: string, database: string, username: string, password: string): Promise<void>
+  DropUser?(connectionId: string, database: string, username: string): Promise<void>
+  ListRoles?(connectionId: string, database: string, showBuiltin: boolean): Promise<DatabaseRole[]>
+  CreateRole?(connectionId: string, database: string, role: NewDatabaseRole): Promise<void>
+  UpdateRolePrivileges?(
+    connectionId: string,
+    database: string,
+    roleName: string,
+    privileges: Privilege[]
+  ): Promise<void>
+  DropRole?(connectionId: string, database: string, roleName: string): Promise<void>
 
   // Theme methods
   GetThemes?(): Promise<Theme[]>
@@ -267,6 +424,36 @@ export interface CollectionProfile {
   topFields: string[]
 }
 
+/**
+ * Database profiler setting
+ */
+export interface ProfilerStatus {
+  database: string
+  level: number
+  slowms: number
+  sampleRate: number
+  filter?: string
+}
+
+/**
+ * Operation recorded in a database's system.profile collection
+ */
+export interface ProfiledOperation {
+  ts: string
+  op: string
+  ns: string
+  millis: number
+  planSummary?: string
+  keysExamined: number
+  docsExamined: number
+  nreturned: number
+  responseLength: number
+  user?: string
+  client?: string
+  appName?: string
+  command?: string
+}
+
 /**
  * Collection statistics from collStats command
  */
@@ -278,7 +465,87 @@ export interface CollectionStats {
   avgObjSize: number
   indexCount: number
   totalIndexSize: number
+  indexSizes?: Record<string, number>
+  freeStorageSize?: number
+  compressionRatio?: number
   capped: boolean
+  maxSize?: number
+  maxDocuments?: number
+  sharded?: boolean
+}
+
+/**
+ * Database totals with a per-collection breakdown
+ */
+export interface DatabaseStats {
+  database: string
+  collections: number
+  views: number
+  objects: number
+  dataSize: number
+  storageSize: number
+  indexCount: number
+  indexSize: number
+  totalSize: number
+  fsUsedSize: number
+  fsTotalSize: number
+  perCollection: CollectionStats[]
+}
+
+/**
+ * Sharding metadata of a collection
+ */
+export interface ShardingInfo {
+  namespace: string
+  clusterSharded: boolean
+  sharded: boolean
+  primaryShard?: string
+  shardKey?: string
+  unique: boolean
+  balancingEnabled: boolean
+  balancerCompliant: boolean
+  complianceViolation?: string
+  balancer?: { mode: string; inRound: boolean; rounds: number }
+  chunks: { shard: string; chunks: number; percent: number }[]
+  totalChunks: number
+}
+
+/**
+ * Cluster-wide balancer state with its active window and recent chunk migrations
+ */
+export interface BalancerStatus {
+  mode: string
+  inRound: boolean
+  rounds: number
+  window?: { start: string; stop: string }
+  migrations?: ChunkMigration[]
+}
+
+export interface ChunkMigration {
+  time: string
+  ns: string
+  from: string
+  to: string
+  status: 'success' | 'aborted' | 'error'
+  error?: string
+}
+
+/**
+ * Replica set of a sharded cluster (standalone shards have no set name)
+ */
+export interface ClusterReplSet {
+  setName?: string
+  hosts: string[]
+}
+
+/**
+ * Shards, config servers, and mongos routers of a sharded cluster
+ */
+export interface ClusterTopology {
+  version: string
+  configServers: ClusterReplSet
+  shards: { id: string; replSet: ClusterReplSet; draining: boolean; tags?: string[] }[]
+  routers: { host: string; version: string; lastPing: string; uptimeSeconds: number }[]
 }
 
 /**
@@ -331,9 +598,19 @@ export interface SavedQueryInput {
 /**
  * Aggregation result types
  */
+export interface AggregateOptions {
+  maxTimeMS?: number
+  allowDiskUse?: boolean
+  collation?: { locale: string; strength?: number; caseLevel?: boolean }
+  readPreference?: string
+  readConcern?: string
+}
+
 export interface AggregationResult {
   documents: string[]
   executionTimeMs: number
+  truncated: boolean
+  warnings?: string[]
 }
 
 /**
@@ -363,6 +640,12 @@ export interface CreateIndexOptions {
   background: boolean
   name: string
   expireAfterSeconds: number
+  partialFilterExpression?: string
+  collation?: { locale: string; strength?: number; caseLevel?: boolean }
+  wildcardProjection?: string
+  weights?: string
+  defaultLanguage?: string
+  languageOverride?: string
 }
 
 /**
@@ -398,6 +681,191 @@ export interface BulkConnectionShareResult {
   key: string
 }
 
+/**
+ * Result of importing connections from another tool
+ */
+export interface ConnectionImportResult {
+  imported: number
+  skipped: number
+  warnings: string[]
+}
+
+/**
+ * Connection pool of one server (the total across servers has no address)
+ */
+export interface ServerPoolStats {
+  address?: string
+  maxPoolSize?: number
+  checkedOut: number
+  waitQueue: number
+  open: number
+  totalCreated: number
+  totalClosed: number
+  checkouts: number
+  checkoutFailures: number
+  avgCheckoutMs: number
+  maxCheckoutMs: number
+  cleared: number
+}
+
+/**
+ * Driver connection pool statistics of a connected client
+ */
+export interface PoolStats {
+  connectionId: string
+  since: string
+  total: ServerPoolStats
+  servers: ServerPoolStats[]
+}
+
+/**
+ * One serverStatus sample of a connected server (rates are per second)
+ */
+export interface MetricsSample {
+  connectionId: string
+  time: string
+  opcounters: {
+    insert: number
+    query: number
+    update: number
+    delete: number
+    getMore: number
+    command: number
+  }
+  connections: {
+    current: number
+    available: number
+    totalCreated: number
+  }
+  memory: {
+    residentMb: number
+    virtualMb: number
+  }
+  network: {
+    bytesInPerSec: number
+    bytesOutPerSec: number
+    requestsPerSec: number
+  }
+}
+
+/**
+ * Selects the operations ListCurrentOperations returns
+ */
+export interface CurrentOpFilter {
+  match?: string
+  idleConnections: boolean
+  allUsers: boolean
+}
+
+/**
+ * An operation in progress on a server, from $currentOp
+ */
+export interface CurrentOperation {
+  opId: string
+  type?: string
+  op?: string
+  ns?: string
+  active: boolean
+  durationMs: number
+  planSummary?: string
+  client?: string
+  appName?: string
+  user?: string
+  host?: string
+  shard?: string
+  desc?: string
+  waitingForLock: boolean
+  command?: string
+}
+
+/**
+ * Selects server log entries (empty lists select all)
+ */
+export interface ServerLogFilter {
+  severities?: string[]
+  components?: string[]
+  search?: string
+}
+
+/**
+ * Recent in-memory log of a server, from getLog
+ */
+export interface ServerLog {
+  logType: string
+  totalLinesWritten: number
+  entries: ServerLogEntry[]
+}
+
+export interface ServerLogEntry {
+  time: string
+  severity: string
+  component: string
+  id?: number
+  context?: string
+  message: string
+  attributes?: string
+  raw: string
+}
+
+/**
+ * Raises an alert while a polled metric is above threshold
+ * (replicationLag in seconds, diskUsage in percent)
+ */
+export interface AlertRule {
+  id: string
+  metric: 'connections' | 'replicationLag' | 'diskUsage'
+  threshold: number
+  enabled: boolean
+}
+
+/**
+ * Triggered alert, sent with the alert:triggered and alert:resolved events
+ */
+export interface Alert {
+  connectionId: string
+  ruleId: string
+  metric: AlertRule['metric']
+  threshold: number
+  value: number
+  message: string
+  triggeredAt: string
+  resolvedAt?: string
+}
+
+/**
+ * Server time spent per collection (intervalSeconds 0 means since the server started)
+ */
+export interface HotCollectionReport {
+  connectionId: string
+  time: string
+  intervalSeconds: number
+  source: 'top' | 'collStats'
+  collections: HotCollection[]
+}
+
+/**
+ * Time a server spent on one namespace
+ */
+export interface HotCollection {
+  ns: string
+  readTimeMs: number
+  writeTimeMs: number
+  totalTimeMs: number
+  readCount: number
+  writeCount: number
+  totalCount: number
+}
+
+/**
+ * Where the encryption keys of saved connections are kept
+ */
+export interface CredentialStoreStatus {
+  backend: 'keyring' | 'vault'
+  keyringAvailable: boolean
+  vaultExists: boolean
+  vaultLocked: boolean
+}
+
 /**
  * Theme color tokens (27 colors)
  */
@@ -517,11 +985,184 @@ export interface ReplicaSetMember {
   self: boolean
 }
 
+/**
+ * Feature compatibility version (targetVersion is set while an upgrade or downgrade runs)
+ */
+export interface FeatureCompatibility {
+  version: string
+  targetVersion?: string
+  previousVersion?: string
+  serverVersion: string
+}
+
+/**
+ * dbHash of one database compared across the data-bearing replica set members
+ */
+export interface ReplicaConsistencyReport {
+  database: string
+  members: MemberHash[]
+  mismatches: CollectionMismatch[]
+  consistent: boolean
+}
+
+/**
+ * dbHash result of one replica set member
+ */
+export interface MemberHash {
+  host: string
+  state: string
+  optimeDate: string
+  md5?: string
+  collections: number
+  error?: string
+}
+
+/**
+ * Collection whose hash differs between members (empty hash: collection missing)
+ */
+export interface CollectionMismatch {
+  collection: string
+  hashes: Record<string, string>
+}
+
+/**
+ * Replica set configuration, from replSetGetConfig
+ */
+export interface ReplSetConfig {
+  setName: string
+  version: number
+  term: number
+  members: ReplSetMemberConfig[]
+}
+
+/**
+ * Configuration of one replica set member (id is assigned when adding)
+ */
+export interface ReplSetMemberConfig {
+  id: number
+  host: string
+  priority: number
+  votes: number
+  arbiterOnly: boolean
+  hidden: boolean
+  secondaryDelaySecs: number
+  tags?: Record<string, string>
+}
+
+/**
+ * Sample of how far each replica set member is behind the primary
+ */
+export interface QueryCount {
+  countId: string
+  total: number
+  estimated: boolean
+  error?: string
+}
+
+export interface ReplicationLag {
+  connectionId: string
+  time: string
+  setName: string
+  primary?: string
+  maxLagSeconds: number
+  members: MemberLag[]
+}
+
+export interface MemberLag {
+  name: string
+  stateStr: string
+  health: number
+  self: boolean
+  optimeDate: string
+  lagSeconds: number
+  lagging: boolean
+}
+
+/**
+ * A role and the database it is defined in
+ */
+export interface RoleRef {
+  role: string
+  db: string
+}
+
+/**
+ * Where a user may authenticate from and to (IP addresses or CIDR ranges)
+ */
+export interface AuthenticationRestriction {
+  clientSource?: string[]
+  serverAddress?: string[]
+}
+
+/**
+ * A user defined in a database
+ */
+export interface DatabaseUser {
+  user: string
+  db: string
+  roles: RoleRef[]
+  mechanisms?: string[]
+  authenticationRestrictions?: AuthenticationRestriction[]
+  customData?: string
+}
+
+/**
+ * A user to create ($external users have no password)
+ */
+export interface NewDatabaseUser {
+  user: string
+  password: string
+  roles: RoleRef[]
+  mechanisms?: string[]
+  authenticationRestrictions?: AuthenticationRestriction[]
+  customData?: string
+}
+
+/**
+ * What a privilege applies to (empty names match all databases or collections)
+ */
+export interface PrivilegeResource {
+  db: string
+  collection: string
+  cluster?: boolean
+  anyResource?: boolean
+}
+
+export interface Privilege {
+  resource: PrivilegeResource
+  actions: string[]
+}
+
+/**
+ * A role with its own and inherited (transitively resolved) roles and privileges
+ */
+export interface DatabaseRole {
+  role: string
+  db: string
+  isBuiltin: boolean
+  roles: RoleRef[]
+  inheritedRoles: RoleRef[]
+  privileges: Privilege[]
+  inheritedPrivileges: Privilege[]
+  authenticationRestrictions?: AuthenticationRestriction[]
+}
+
+/**
+ * A custom role to create
+ */
+export interface NewDatabaseRole {
+  role: string
+  privileges: Privilege[]
+  roles: RoleRef[]
+  authenticationRestrictions?: AuthenticationRestriction[]
+}
+
 /**
  * JSON export options
  */
 export interface JSONExportOptions {
   filter?: string
+  pipeline?: string
   filePath?: string
   pretty?: boolean
   array?: boolean
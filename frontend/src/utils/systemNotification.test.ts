@@ -0,0 +1,38 @@
+import { describe, it, expect, vi, afterEach } from 'vitest'
+import { showSystemNotification } from './systemNotification'
+
+describe('showSystemNotification', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals()
+  })
+
+  it('returns false when notifications are unsupported', async () => {
+    delete (window as { Notification?: unknown }).Notification
+    expect(await showSystemNotification('Title', 'Body')).toBe(false)
+  })
+
+  it('shows a notification when permission is granted', async () => {
+    const created: Array<{ title: string; body?: string }> = []
+    class FakeNotification {
+      static permission = 'granted'
+      static requestPermission = vi.fn()
+      constructor(title: string, options?: { body?: string }) {
+        created.push({ title, body: options?.body })
+      }
+    }
+    vi.stubGlobal('Notification', FakeNotification)
+    expect(await showSystemNotification('Alert', '150 connections open')).toBe(true)
+    expect(created).toEqual([{ title: 'Alert', body: '150 connections open' }])
+    expect(FakeNotification.requestPermission).not.toHaveBeenCalled()
+  })
+
+  it('requests permission once and respects a denial', async () => {
+    class FakeNotification {
+      static permission = 'default'
+      static requestPermission = vi.fn().mockResolvedValue('denied')
+    }
+    vi.stubGlobal('Notification', FakeNotification)
+    expect(await showSystemNotification('Alert', 'Body')).toBe(false)
+    expect(FakeNotification.requestPermission).toHaveBeenCalledTimes(1)
+  })
+})
@@ -0,0 +1,27 @@
+/**
+ * Shows a native OS notification through the webview's Notification API.
+ * Permission is requested on first use; returns false when notifications are
+ * unavailable or denied, so callers can fall back to in-app toasts.
+ */
+export async function showSystemNotification(title: string, body: string): Promise<boolean> {
+  if (typeof window === 'undefined' || !('Notification' in window)) {
+    return false
+  }
+  let permission = Notification.permission
+  if (permission === 'default') {
+    try {
+      permission = await Notification.requestPermission()
+    } catch {
+      return false
+    }
+  }
+  if (permission !== 'granted') {
+    return false
+  }
+  try {
+    new Notification(title, { body })
+    return true
+  } catch {
+    return false
+  }
+}
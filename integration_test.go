@@ -316,7 +316,7 @@ func TestIntegration_InsertDocument(t *testing.T) {
 
 	// Insert a document
 	docJSON := `{"name": "NewUser", "email": "new@test.com"}`
-	insertedID, err := tc.app.InsertDocument(tc.connID, "testdb", "users", docJSON)
+	insertedID, err := tc.app.InsertDocument(tc.connID, "testdb", "users", docJSON, nil)
 	require.NoError(t, err)
 
 	assert.NotEmpty(t, insertedID, "Should return inserted ID")
@@ -355,7 +355,7 @@ func TestIntegration_UpdateDocument(t *testing.T) {
 
 	// Update the document
 	updatedJSON := `{"name": "Alice Updated", "age": 31}`
-	err = tc.app.UpdateDocument(tc.connID, "testdb", "users", docID, updatedJSON)
+	err = tc.app.UpdateDocument(tc.connID, "testdb", "users", docID, updatedJSON, false, nil)
 	require.NoError(t, err)
 
 	// Verify the update
@@ -412,7 +412,7 @@ func TestIntegration_DeleteDocument(t *testing.T) {
 	docID := idMap["$oid"].(string)
 
 	// Delete the document
-	err = tc.app.DeleteDocument(tc.connID, "testdb", "users", docID)
+	err = tc.app.DeleteDocument(tc.connID, "testdb", "users", docID, nil)
 	require.NoError(t, err)
 
 	// Verify deletion
@@ -473,6 +473,55 @@ func TestIntegration_DocumentWithNumericID(t *testing.T) {
 	assert.Contains(t, docJSON, "NumericID User", "Should retrieve document with numeric ID")
 }
 
+func TestIntegration_DocumentWithCompositeID(t *testing.T) {
+	tc := setupTestContainer(t)
+	defer tc.teardown(t)
+
+	// Field order is part of an embedded document's identity
+	ctx := context.Background()
+	coll := tc.client.Database("testdb").Collection("orders")
+	id := bson.D{{Key: "tenant", Value: "acme"}, {Key: "seq", Value: int64(7)}}
+	_, err := coll.InsertOne(ctx, bson.D{{Key: "_id", Value: id}, {Key: "status", Value: "new"}})
+	require.NoError(t, err)
+
+	err = tc.app.Connect(tc.connID)
+	require.NoError(t, err)
+
+	// Read the _id back from query results, as the UI does
+	result, err := tc.app.FindDocuments(tc.connID, "testdb", "orders", "{}", QueryOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 1)
+	var found bson.D
+	require.NoError(t, bson.UnmarshalExtJSON([]byte(result.Documents[0]), true, &found))
+	docID, err := document.MarshalValue(found[0].Value)
+	require.NoError(t, err)
+	assert.Equal(t, `{"tenant":"acme","seq":{"$numberLong":"7"}}`, docID)
+
+	docJSON, err := tc.app.GetDocument(tc.connID, "testdb", "orders", docID)
+	require.NoError(t, err)
+	assert.Contains(t, docJSON, `"status":"new"`)
+
+	err = tc.app.UpdateDocument(tc.connID, "testdb", "orders", docID, `{"_id": `+docID+`, "status": "shipped"}`, false, nil)
+	require.NoError(t, err)
+	err = tc.app.PatchDocument(tc.connID, "testdb", "orders", docID, `{"$set": {"carrier": "ups"}}`, false, nil)
+	require.NoError(t, err)
+
+	docJSON, err = tc.app.GetDocument(tc.connID, "testdb", "orders", docID)
+	require.NoError(t, err)
+	assert.Contains(t, docJSON, "shipped")
+	assert.Contains(t, docJSON, "ups")
+
+	// The same fields in a different order are a different _id
+	_, err = tc.app.GetDocument(tc.connID, "testdb", "orders", `{"seq":{"$numberLong":"7"},"tenant":"acme"}`)
+	assert.Error(t, err)
+
+	err = tc.app.DeleteDocument(tc.connID, "testdb", "orders", docID, nil)
+	require.NoError(t, err)
+	count, err := coll.CountDocuments(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
 // =============================================================================
 // Schema Inference Tests
 // =============================================================================
@@ -1106,7 +1155,7 @@ func TestIntegration_LargeDocument(t *testing.T) {
 	docJSON := fmt.Sprintf(`{"name": "LargeDoc", "data": "%s"}`, string(largeString))
 
 	// Insert large document
-	insertedID, err := tc.app.InsertDocument(tc.connID, "testdb", "largedocs", docJSON)
+	insertedID, err := tc.app.InsertDocument(tc.connID, "testdb", "largedocs", docJSON, nil)
 	require.NoError(t, err)
 	assert.NotEmpty(t, insertedID)
 
@@ -1297,14 +1346,42 @@ func TestIntegration_DocumentNotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 
 	// Try to update a non-existent document
-	err = tc.app.UpdateDocument(tc.connID, "testdb", "users", "000000000000000000000000", `{"name": "Updated"}`)
+	err = tc.app.UpdateDocument(tc.connID, "testdb", "users", "000000000000000000000000", `{"name": "Updated"}`, false, nil)
 	assert.Error(t, err, "Should error when updating non-existent document")
 
 	// Try to delete a non-existent document
-	err = tc.app.DeleteDocument(tc.connID, "testdb", "users", "000000000000000000000000")
+	err = tc.app.DeleteDocument(tc.connID, "testdb", "users", "000000000000000000000000", nil)
 	assert.Error(t, err, "Should error when deleting non-existent document")
 }
 
+func TestIntegration_UpdateDocumentUpsert(t *testing.T) {
+	tc := setupTestContainer(t)
+	defer tc.teardown(t)
+
+	err := tc.app.Connect(tc.connID)
+	require.NoError(t, err)
+
+	// Saving a document with a new _id creates it when upserting
+	err = tc.app.UpdateDocument(tc.connID, "testdb", "users", "migrated-1", `{"_id": "migrated-1", "name": "Alice"}`, true, nil)
+	require.NoError(t, err)
+
+	docJSON, err := tc.app.GetDocument(tc.connID, "testdb", "users", "migrated-1")
+	require.NoError(t, err)
+	assert.Contains(t, docJSON, "Alice")
+
+	// Patches can create documents too
+	err = tc.app.PatchDocument(tc.connID, "testdb", "users", "migrated-2", `{"$set": {"name": "Bob"}}`, true, nil)
+	require.NoError(t, err)
+
+	docJSON, err = tc.app.GetDocument(tc.connID, "testdb", "users", "migrated-2")
+	require.NoError(t, err)
+	assert.Contains(t, docJSON, "Bob")
+
+	// Without upsert a missing document is still an error
+	err = tc.app.PatchDocument(tc.connID, "testdb", "users", "missing", `{"$set": {"name": "Eve"}}`, false, nil)
+	assert.Error(t, err)
+}
+
 func TestIntegration_DuplicateKeyError(t *testing.T) {
 	tc := setupTestContainer(t)
 	defer tc.teardown(t)
@@ -1323,11 +1400,11 @@ func TestIntegration_DuplicateKeyError(t *testing.T) {
 	require.NoError(t, err)
 
 	// Insert first document
-	_, err = tc.app.InsertDocument(tc.connID, "testdb", "unique", `{"email": "test@test.com", "name": "First"}`)
+	_, err = tc.app.InsertDocument(tc.connID, "testdb", "unique", `{"email": "test@test.com", "name": "First"}`, nil)
 	require.NoError(t, err)
 
 	// Try to insert duplicate
-	_, err = tc.app.InsertDocument(tc.connID, "testdb", "unique", `{"email": "test@test.com", "name": "Second"}`)
+	_, err = tc.app.InsertDocument(tc.connID, "testdb", "unique", `{"email": "test@test.com", "name": "Second"}`, nil)
 	assert.Error(t, err, "Should error on duplicate key")
 	assert.Contains(t, err.Error(), "duplicate", "Error should mention duplicate")
 }
@@ -1341,11 +1418,11 @@ func TestIntegration_MalformedDocument(t *testing.T) {
 	require.NoError(t, err)
 
 	// Try to insert malformed JSON
-	_, err = tc.app.InsertDocument(tc.connID, "testdb", "docs", `{not valid json}`)
+	_, err = tc.app.InsertDocument(tc.connID, "testdb", "docs", `{not valid json}`, nil)
 	assert.Error(t, err, "Should error on malformed JSON")
 
 	// Try to insert with invalid BSON types
-	_, err = tc.app.InsertDocument(tc.connID, "testdb", "docs", `{"_id": {"$oid": "not-a-valid-oid"}}`)
+	_, err = tc.app.InsertDocument(tc.connID, "testdb", "docs", `{"_id": {"$oid": "not-a-valid-oid"}}`, nil)
 	assert.Error(t, err, "Should error on invalid ObjectId format")
 }
 
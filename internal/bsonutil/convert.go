@@ -3,7 +3,12 @@
 // float64, etc.) into Go types without panicking on unexpected types.
 package bsonutil
 
-import "fmt"
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // ToString converts a BSON value to string. Returns "" for nil.
 // Non-string values are formatted with fmt.Sprintf.
@@ -85,3 +90,46 @@ func Int64FromMap(m map[string]interface{}, key string) int64 {
 func BoolFromMap(m map[string]interface{}, key string) bool {
 	return ToBool(m[key])
 }
+
+// TypeName returns a human-readable type name for a BSON value, as schema inference and
+// document diffs show it. Arrays are named after their first element.
+func TypeName(value interface{}) string {
+	if value == nil {
+		return "Null"
+	}
+
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return "ObjectId"
+	case string:
+		return "String"
+	case int32:
+		return "Int32"
+	case int64:
+		return "Int64"
+	case float64:
+		return "Double"
+	case bool:
+		return "Boolean"
+	case primitive.DateTime:
+		return "Date"
+	case primitive.Timestamp:
+		return "Timestamp"
+	case bson.M, bson.D:
+		return "Object"
+	case bson.A:
+		if len(v) > 0 {
+			elemType := TypeName(v[0])
+			return "Array<" + elemType + ">"
+		}
+		return "Array"
+	case primitive.Binary:
+		return "Binary"
+	case primitive.Decimal128:
+		return "Decimal128"
+	case primitive.Regex:
+		return "Regex"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
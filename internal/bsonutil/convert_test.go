@@ -2,6 +2,9 @@ package bsonutil
 
 import (
 	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestToString(t *testing.T) {
@@ -158,3 +161,27 @@ func TestBoolFromMap(t *testing.T) {
 		t.Errorf("BoolFromMap(m, 'missing') = %v, want false", got)
 	}
 }
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"nil", nil, "Null"},
+		{"object id", primitive.NewObjectID(), "ObjectId"},
+		{"int32", int32(1), "Int32"},
+		{"map document", bson.M{"a": 1}, "Object"},
+		{"ordered document", bson.D{{Key: "a", Value: 1}}, "Object"},
+		{"array", bson.A{"x", int32(1)}, "Array<String>"},
+		{"empty array", bson.A{}, "Array"},
+		{"other", primitive.MinKey{}, "primitive.MinKey"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TypeName(tt.in); got != tt.want {
+				t.Errorf("TypeName(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,580 @@
+package bsonutil
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UnmarshalExtJSON is bson.UnmarshalExtJSON that also accepts relaxed, shell-style input
+// (see NormalizeExtJSON) and the legacy UUIDs RenderLegacyUUIDs writes. Strict Extended JSON
+// is parsed as-is; if it fails and the input can't be normalized either, the original error
+// is returned.
+func UnmarshalExtJSON(data []byte, canonical bool, val interface{}) error {
+	data = RestoreLegacyUUIDs(data)
+	err := bson.UnmarshalExtJSON(data, canonical, val)
+	if err == nil {
+		return nil
+	}
+	normalized, nerr := NormalizeExtJSON(string(data))
+	if nerr != nil {
+		return err
+	}
+	return bson.UnmarshalExtJSON([]byte(normalized), canonical, val)
+}
+
+// NormalizeExtJSON converts relaxed JSON as typed in the mongo shell into strict Extended
+// JSON. On top of standard JSON it accepts:
+//   - unquoted keys, single-quoted strings, trailing commas, and // or /* */ comments
+//   - ObjectId("..."), ISODate("..."), new Date(...), NumberInt, NumberLong, NumberDecimal,
+//     UUID("...") and the legacy CSUUID/JUUID/PYUUID("..."), BinData(subtype, "base64"),
+//     Timestamp(t, i), MinKey, and MaxKey
+//   - /regex/flags literals, Infinity, NaN, and undefined
+func NormalizeExtJSON(input string) (string, error) {
+	p := &relaxedParser{src: input}
+	if err := p.value(); err != nil {
+		return "", err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return "", p.errorf("unexpected %q after the end of the value", p.src[p.pos])
+	}
+	return p.out.String(), nil
+}
+
+// relaxedParser is a recursive descent parser that writes strict JSON as it reads.
+type relaxedParser struct {
+	src string
+	pos int
+	out strings.Builder
+}
+
+// shellUUIDHelpers maps the UUID helpers of the shell and of GUI tools to representations.
+var shellUUIDHelpers = map[string]string{
+	"UUID":   UUIDStandard,
+	"LUUID":  UUIDPythonLegacy,
+	"PYUUID": UUIDPythonLegacy,
+	"JUUID":  UUIDJavaLegacy,
+	"CSUUID": UUIDCSharpLegacy,
+}
+
+// helperArg is a literal argument to a shell helper such as NumberLong(...).
+type helperArg struct {
+	text     string
+	isString bool
+}
+
+func (p *relaxedParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid JSON at position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *relaxedParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// skipSpace skips whitespace and comments.
+func (p *relaxedParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "//"):
+			if end := strings.IndexByte(p.src[p.pos:], '\n'); end >= 0 {
+				p.pos += end + 1
+			} else {
+				p.pos = len(p.src)
+			}
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			if end := strings.Index(p.src[p.pos+2:], "*/"); end >= 0 {
+				p.pos += end + 4
+			} else {
+				p.pos = len(p.src)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *relaxedParser) value() error {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == 0:
+		return p.errorf("unexpected end of input")
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"' || c == '\'':
+		s, err := p.str()
+		if err != nil {
+			return err
+		}
+		p.writeString(s)
+		return nil
+	case c == '/':
+		return p.regex()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case isIdentStart(c):
+		return p.identifier()
+	default:
+		return p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *relaxedParser) object() error {
+	p.pos++ // {
+	p.out.WriteByte('{')
+	first := true
+	for {
+		p.skipSpace()
+		if p.peek() == '}' {
+			p.pos++
+			p.out.WriteByte('}')
+			return nil
+		}
+		if !first {
+			p.out.WriteByte(',')
+		}
+		first = false
+
+		var key string
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			s, err := p.str()
+			if err != nil {
+				return err
+			}
+			key = s
+		case isIdentStart(c) || (c >= '0' && c <= '9'):
+			key = p.ident(true)
+		default:
+			return p.errorf("expected a field name")
+		}
+		p.writeString(key)
+
+		p.skipSpace()
+		if p.peek() != ':' {
+			return p.errorf("expected ':' after field %q", key)
+		}
+		p.pos++
+		p.out.WriteByte(':')
+		if err := p.value(); err != nil {
+			return err
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return p.errorf("expected ',' or '}' in object")
+		}
+	}
+}
+
+func (p *relaxedParser) array() error {
+	p.pos++ // [
+	p.out.WriteByte('[')
+	first := true
+	for {
+		p.skipSpace()
+		if p.peek() == ']' {
+			p.pos++
+			p.out.WriteByte(']')
+			return nil
+		}
+		if !first {
+			p.out.WriteByte(',')
+		}
+		first = false
+		if err := p.value(); err != nil {
+			return err
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// str reads a single- or double-quoted string and returns its decoded value.
+func (p *relaxedParser) str() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		case c != '\\':
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		p.pos++ // backslash
+		if p.pos >= len(p.src) {
+			break
+		}
+		esc := p.src[p.pos]
+		p.pos++
+		switch esc {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, err := p.unicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			// Combine UTF-16 surrogate pairs
+			if utf16.IsSurrogate(r) && strings.HasPrefix(p.src[p.pos:], `\u`) {
+				p.pos += 2
+				r2, err := p.unicodeEscape()
+				if err != nil {
+					return "", err
+				}
+				r = utf16.DecodeRune(r, r2)
+			}
+			b.WriteRune(r)
+		default:
+			// \" \' \\ \/ and any other escaped character stand for themselves
+			b.WriteByte(esc)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *relaxedParser) unicodeEscape() (rune, error) {
+	if p.pos+4 > len(p.src) {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	n, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.pos += 4
+	return rune(n), nil
+}
+
+func (p *relaxedParser) number() error {
+	start := p.pos
+	sign := ""
+	if c := p.peek(); c == '-' || c == '+' {
+		if c == '-' {
+			sign = "-"
+		}
+		p.pos++
+	}
+	if strings.HasPrefix(p.src[p.pos:], "Infinity") {
+		p.pos += len("Infinity")
+		p.writeDoc("$numberDouble", sign+"Infinity")
+		return nil
+	}
+	for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	num := strings.TrimPrefix(strings.TrimPrefix(p.src[start:p.pos], "+"), "-")
+	// JavaScript allows .5 and 5. but JSON doesn't
+	if strings.HasPrefix(num, ".") {
+		num = "0" + num
+	}
+	num = strings.TrimSuffix(num, ".")
+	num = sign + strings.Replace(num, ".e", ".0e", 1)
+	if !json.Valid([]byte(num)) {
+		return p.errorf("invalid number %q", p.src[start:p.pos])
+	}
+	p.out.WriteString(num)
+	return nil
+}
+
+func (p *relaxedParser) regex() error {
+	p.pos++ // /
+	var pattern strings.Builder
+	inClass := false
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' {
+			return p.errorf("unterminated regular expression")
+		}
+		c := p.src[p.pos]
+		p.pos++
+		if c == '\\' && p.pos < len(p.src) {
+			pattern.WriteByte(c)
+			pattern.WriteByte(p.src[p.pos])
+			p.pos++
+			continue
+		}
+		if c == '/' && !inClass {
+			break
+		}
+		if c == '[' {
+			inClass = true
+		} else if c == ']' {
+			inClass = false
+		}
+		pattern.WriteByte(c)
+	}
+	flags := []byte(p.ident(false))
+	sort.Slice(flags, func(i, j int) bool { return flags[i] < flags[j] })
+	p.out.WriteString(`{"$regularExpression":{"pattern":`)
+	p.writeString(pattern.String())
+	p.out.WriteString(`,"options":`)
+	p.writeString(string(flags))
+	p.out.WriteString(`}}`)
+	return nil
+}
+
+func (p *relaxedParser) identifier() error {
+	start := p.pos
+	name := p.ident(false)
+	if name == "new" {
+		p.skipSpace()
+		name = p.ident(false)
+		if name == "" {
+			return p.errorf("expected a constructor after \"new\"")
+		}
+	}
+
+	switch name {
+	case "true", "false", "null":
+		p.out.WriteString(name)
+		return nil
+	case "undefined":
+		p.out.WriteString(`{"$undefined":true}`)
+		return nil
+	case "Infinity", "NaN":
+		p.writeDoc("$numberDouble", name)
+		return nil
+	}
+
+	p.skipSpace()
+	var args []helperArg
+	if p.peek() == '(' {
+		var err error
+		if args, err = p.helperArgs(); err != nil {
+			return err
+		}
+	} else if name != "MinKey" && name != "MaxKey" {
+		p.pos = start
+		return p.errorf("unexpected identifier %q", name)
+	}
+	return p.helper(name, args)
+}
+
+// helperArgs reads the literal arguments of a shell helper call.
+func (p *relaxedParser) helperArgs() ([]helperArg, error) {
+	p.pos++ // (
+	var args []helperArg
+	for {
+		p.skipSpace()
+		switch c := p.peek(); {
+		case c == ')':
+			p.pos++
+			return args, nil
+		case c == '"' || c == '\'':
+			s, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, helperArg{text: s, isString: true})
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			start := p.pos
+			p.pos++
+			for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+				p.pos++
+			}
+			args = append(args, helperArg{text: p.src[start:p.pos]})
+		default:
+			return nil, p.errorf("helper arguments must be strings or numbers")
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+		default:
+			return nil, p.errorf("expected ',' or ')' in helper arguments")
+		}
+	}
+}
+
+// helper writes the Extended JSON form of a shell helper call.
+func (p *relaxedParser) helper(name string, args []helperArg) error {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i].text
+		}
+		return ""
+	}
+	wantArgs := func(n int) error {
+		if len(args) != n {
+			return p.errorf("%s expects %d argument(s), got %d", name, n, len(args))
+		}
+		return nil
+	}
+
+	switch name {
+	case "ObjectId":
+		if len(args) == 0 {
+			p.writeDoc("$oid", primitive.NewObjectID().Hex())
+			return nil
+		}
+		if err := wantArgs(1); err != nil {
+			return err
+		}
+		if _, err := primitive.ObjectIDFromHex(arg(0)); err != nil {
+			return p.errorf("invalid ObjectId %q", arg(0))
+		}
+		p.writeDoc("$oid", arg(0))
+	case "ISODate", "Date":
+		t := time.Now()
+		if len(args) > 0 {
+			if err := wantArgs(1); err != nil {
+				return err
+			}
+			var err error
+			if t, err = parseShellDate(args[0]); err != nil {
+				return p.errorf("%s: %v", name, err)
+			}
+		}
+		p.out.WriteString(`{"$date":`)
+		p.writeDoc("$numberLong", strconv.FormatInt(t.UnixMilli(), 10))
+		p.out.WriteByte('}')
+	case "NumberInt", "NumberLong", "NumberDecimal":
+		if err := wantArgs(1); err != nil {
+			return err
+		}
+		key := map[string]string{"NumberInt": "$numberInt", "NumberLong": "$numberLong", "NumberDecimal": "$numberDecimal"}[name]
+		p.writeDoc(key, arg(0))
+	case "UUID", "LUUID", "PYUUID", "JUUID", "CSUUID":
+		if err := wantArgs(1); err != nil {
+			return err
+		}
+		b, err := UUIDToBinary(arg(0), shellUUIDHelpers[name])
+		if err != nil {
+			return p.errorf("%v", err)
+		}
+		p.writeBinary(b.Data, hex.EncodeToString([]byte{b.Subtype}))
+	case "BinData":
+		if err := wantArgs(2); err != nil {
+			return err
+		}
+		subtype, err := strconv.ParseUint(arg(0), 10, 8)
+		if err != nil {
+			return p.errorf("invalid BinData subtype %q", arg(0))
+		}
+		data, err := base64.StdEncoding.DecodeString(arg(1))
+		if err != nil {
+			return p.errorf("invalid BinData base64 data")
+		}
+		p.writeBinary(data, hex.EncodeToString([]byte{byte(subtype)}))
+	case "Timestamp":
+		if err := wantArgs(2); err != nil {
+			return err
+		}
+		t, err1 := strconv.ParseUint(arg(0), 10, 32)
+		i, err2 := strconv.ParseUint(arg(1), 10, 32)
+		if err1 != nil || err2 != nil {
+			return p.errorf("invalid Timestamp arguments")
+		}
+		fmt.Fprintf(&p.out, `{"$timestamp":{"t":%d,"i":%d}}`, t, i)
+	case "MinKey":
+		p.out.WriteString(`{"$minKey":1}`)
+	case "MaxKey":
+		p.out.WriteString(`{"$maxKey":1}`)
+	default:
+		return p.errorf("unknown helper %s()", name)
+	}
+	return nil
+}
+
+// ident reads an identifier. Field names may also contain dots and digits.
+func (p *relaxedParser) ident(fieldName bool) string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if !isIdentStart(c) && !(c >= '0' && c <= '9') && !(fieldName && c == '.') {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *relaxedParser) writeString(s string) {
+	b, _ := json.Marshal(s)
+	p.out.Write(b)
+}
+
+// writeDoc writes a single-field document with a string value, e.g. {"$oid":"..."}.
+func (p *relaxedParser) writeDoc(key, value string) {
+	p.out.WriteByte('{')
+	p.writeString(key)
+	p.out.WriteByte(':')
+	p.writeString(value)
+	p.out.WriteByte('}')
+}
+
+func (p *relaxedParser) writeBinary(data []byte, subtype string) {
+	fmt.Fprintf(&p.out, `{"$binary":{"base64":"%s","subType":"%s"}}`, base64.StdEncoding.EncodeToString(data), subtype)
+}
+
+// parseShellDate parses a date argument: an ISO 8601 string or milliseconds since the epoch.
+func parseShellDate(arg helperArg) (time.Time, error) {
+	if !arg.isString {
+		ms, err := strconv.ParseInt(arg.text, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid milliseconds %q", arg.text)
+		}
+		return time.UnixMilli(ms), nil
+	}
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999Z0700",
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, arg.text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", arg.text)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,84 @@
+package bsonutil
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeExtJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"strict JSON", `{"a": 1, "b": [true, null, "x"]}`, `{"a":1,"b":[true,null,"x"]}`},
+		{"unquoted keys", `{name: "x", $gt: 1, address.city: 'Oslo'}`, `{"name":"x","$gt":1,"address.city":"Oslo"}`},
+		{"single quotes", `{'a': 'it\'s "quoted"'}`, `{"a":"it's \"quoted\""}`},
+		{"trailing commas", `{a: [1, 2,], b: 3,}`, `{"a":[1,2],"b":3}`},
+		{"comments", "{a: 1, // line\n /* block */ b: 2}", `{"a":1,"b":2}`},
+		{"numbers", `[+1, .5, 5., -2.5e3, -Infinity, NaN]`, `[1,0.5,5,-2.5e3,{"$numberDouble":"-Infinity"},{"$numberDouble":"NaN"}]`},
+		{"ObjectId", `{_id: ObjectId("507f1f77bcf86cd799439011")}`, `{"_id":{"$oid":"507f1f77bcf86cd799439011"}}`},
+		{"ISODate", `{at: ISODate("2024-01-02T03:04:05Z")}`, `{"at":{"$date":{"$numberLong":"1704164645000"}}}`},
+		{"ISODate date only", `ISODate('2024-01-02')`, `{"$date":{"$numberLong":"1704153600000"}}`},
+		{"new Date millis", `new Date(1704153600000)`, `{"$date":{"$numberLong":"1704153600000"}}`},
+		{"numbers helpers", `[NumberInt(5), NumberLong("9007199254740993"), NumberDecimal("1.10")]`,
+			`[{"$numberInt":"5"},{"$numberLong":"9007199254740993"},{"$numberDecimal":"1.10"}]`},
+		{"UUID", `UUID("123e4567-e89b-12d3-a456-426614174000")`, `{"$binary":{"base64":"Ej5FZ+ibEtOkVkJmFBdAAA==","subType":"04"}}`},
+		{"BinData", `BinData(0, "AQI=")`, `{"$binary":{"base64":"AQI=","subType":"00"}}`},
+		{"Timestamp", `Timestamp(1700000000, 2)`, `{"$timestamp":{"t":1700000000,"i":2}}`},
+		{"keys", `[MinKey, MaxKey()]`, `[{"$minKey":1},{"$maxKey":1}]`},
+		{"regex", `{name: /^a\/b[/]/mi}`, `{"name":{"$regularExpression":{"pattern":"^a\\/b[/]","options":"im"}}}`},
+		{"undefined", `{a: undefined}`, `{"a":{"$undefined":true}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeExtJSON(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeExtJSON(%s) failed: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeExtJSON(%s) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeExtJSON_Errors(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`{a: }`,
+		`{a: 1} {b: 2}`,
+		`{a: foo}`,
+		`{a: 'unterminated}`,
+		`{a: ObjectId("xyz")}`,
+		`{a: ISODate("yesterday")}`,
+		`{a: NumberLong()}`,
+		`{a: Code("x")}`,
+		`[1 2]`,
+	} {
+		if got, err := NormalizeExtJSON(input); err == nil {
+			t.Errorf("Expected error for %q, got %s", input, got)
+		}
+	}
+}
+
+func TestUnmarshalExtJSON(t *testing.T) {
+	var doc bson.M
+	if err := UnmarshalExtJSON([]byte(`{_id: ObjectId("507f1f77bcf86cd799439011"), n: NumberLong(5), tags: ['a',],}`), true, &doc); err != nil {
+		t.Fatalf("UnmarshalExtJSON failed: %v", err)
+	}
+	if oid, ok := doc["_id"].(primitive.ObjectID); !ok || oid.Hex() != "507f1f77bcf86cd799439011" {
+		t.Errorf("Expected ObjectId, got %T %v", doc["_id"], doc["_id"])
+	}
+	if n, ok := doc["n"].(int64); !ok || n != 5 {
+		t.Errorf("Expected int64 5, got %T %v", doc["n"], doc["n"])
+	}
+
+	// Input that is neither strict nor relaxed JSON reports the strict parser's error
+	strictErr := bson.UnmarshalExtJSON([]byte(`{"a": }`), true, &doc)
+	if err := UnmarshalExtJSON([]byte(`{"a": }`), true, &doc); err == nil || err.Error() != strictErr.Error() {
+		t.Errorf("Expected strict error %v, got %v", strictErr, err)
+	}
+}
@@ -0,0 +1,150 @@
+package bsonutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UUID representations, named after the driver uuidRepresentation settings. Standard UUIDs
+// are binary subtype 4; the legacy drivers stored subtype 3 with their own byte orders.
+const (
+	UUIDStandard     = "standard"     // Subtype 4, RFC 4122 byte order
+	UUIDPythonLegacy = "pythonLegacy" // Subtype 3, RFC 4122 byte order
+	UUIDJavaLegacy   = "javaLegacy"   // Subtype 3, each 8-byte half reversed
+	UUIDCSharpLegacy = "csharpLegacy" // Subtype 3, first three groups little-endian
+)
+
+var (
+	// standardUUIDPattern matches a subtype 4 binary as written by canonical bson.MarshalExtJSON.
+	standardUUIDPattern = regexp.MustCompile(`\{"\$binary":\{"base64":"([A-Za-z0-9+/=]{24})","subType":"04"\}\}`)
+	// legacyUUIDPattern matches a 16-byte subtype 3 binary as written by bson.MarshalExtJSON.
+	legacyUUIDPattern = regexp.MustCompile(`\{"\$binary":\{"base64":"([A-Za-z0-9+/=]{24})","subType":"03"\}\}`)
+	// renderedLegacyUUIDPattern matches the form RenderLegacyUUIDs writes, with the whitespace
+	// of reformatted JSON allowed.
+	renderedLegacyUUIDPattern = regexp.MustCompile(`\{\s*"\$uuid"\s*:\s*"([0-9A-Fa-f-]{36})"\s*,\s*"\$uuidRepresentation"\s*:\s*"(\w+)"\s*\}`)
+)
+
+// UUIDToBinary converts a UUID string to BSON binary using the given representation.
+// An empty representation means standard.
+func UUIDToBinary(s, representation string) (primitive.Binary, error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("invalid UUID %q", s)
+	}
+	data := make([]byte, 16)
+	copy(data, u[:])
+	if representation == "" || representation == UUIDStandard {
+		return primitive.Binary{Subtype: 0x04, Data: data}, nil
+	}
+	if err := swapUUIDBytes(data, representation); err != nil {
+		return primitive.Binary{}, err
+	}
+	return primitive.Binary{Subtype: 0x03, Data: data}, nil
+}
+
+// BinaryToUUID formats a subtype 3 or 4 binary as a UUID string. Subtype 4 is always
+// standard; representation selects the byte order of legacy subtype 3 values.
+func BinaryToUUID(b primitive.Binary, representation string) (string, error) {
+	if len(b.Data) != 16 {
+		return "", fmt.Errorf("binary is %d bytes, a UUID is 16", len(b.Data))
+	}
+	data := make([]byte, 16)
+	copy(data, b.Data)
+	switch b.Subtype {
+	case 0x04:
+	case 0x03:
+		if representation == "" || representation == UUIDStandard {
+			representation = UUIDPythonLegacy
+		}
+		if err := swapUUIDBytes(data, representation); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("binary subtype %d is not a UUID", b.Subtype)
+	}
+	u, _ := uuid.FromBytes(data)
+	return u.String(), nil
+}
+
+// swapUUIDBytes converts between RFC 4122 byte order and a legacy byte order, in place.
+// Every legacy order is its own inverse.
+func swapUUIDBytes(data []byte, representation string) error {
+	reverse := func(b []byte) {
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+	}
+	switch representation {
+	case UUIDPythonLegacy:
+	case UUIDJavaLegacy:
+		reverse(data[0:8])
+		reverse(data[8:16])
+	case UUIDCSharpLegacy:
+		reverse(data[0:4])
+		reverse(data[4:6])
+		reverse(data[6:8])
+	default:
+		return fmt.Errorf("unknown UUID representation %q", representation)
+	}
+	return nil
+}
+
+// RenderUUIDs rewrites subtype 4 binaries in canonical Extended JSON as {"$uuid": "..."},
+// which reads far better and parses back to the same binary. Legacy subtype 3 values are
+// left alone since their byte order is ambiguous; see RenderLegacyUUIDs.
+func RenderUUIDs(extJSON []byte) []byte {
+	return standardUUIDPattern.ReplaceAllFunc(extJSON, func(m []byte) []byte {
+		data, err := base64.StdEncoding.DecodeString(string(standardUUIDPattern.FindSubmatch(m)[1]))
+		if err != nil || len(data) != 16 {
+			return m
+		}
+		u, _ := uuid.FromBytes(data)
+		return []byte(`{"$uuid":"` + u.String() + `"}`)
+	})
+}
+
+// RenderLegacyUUIDs rewrites subtype 3 binaries in Extended JSON as
+// {"$uuid": "...", "$uuidRepresentation": "..."}, reading their bytes in the given legacy
+// order. This is not Extended JSON; RestoreLegacyUUIDs turns it back into the same binaries.
+// An empty or standard representation leaves the binaries alone.
+func RenderLegacyUUIDs(extJSON []byte, representation string) []byte {
+	if representation == "" || representation == UUIDStandard {
+		return extJSON
+	}
+	return legacyUUIDPattern.ReplaceAllFunc(extJSON, func(m []byte) []byte {
+		data, err := base64.StdEncoding.DecodeString(string(legacyUUIDPattern.FindSubmatch(m)[1]))
+		if err != nil {
+			return m
+		}
+		u, err := BinaryToUUID(primitive.Binary{Subtype: 0x03, Data: data}, representation)
+		if err != nil {
+			return m
+		}
+		return []byte(`{"$uuid":"` + u + `","$uuidRepresentation":"` + representation + `"}`)
+	})
+}
+
+// RestoreLegacyUUIDs rewrites the UUIDs RenderLegacyUUIDs wrote back to subtype 3 binaries in
+// canonical Extended JSON. Values that don't convert are left for the parser to reject.
+func RestoreLegacyUUIDs(extJSON []byte) []byte {
+	if !bytes.Contains(extJSON, []byte(`"$uuidRepresentation"`)) {
+		return extJSON
+	}
+	return renderedLegacyUUIDPattern.ReplaceAllFunc(extJSON, func(m []byte) []byte {
+		sub := renderedLegacyUUIDPattern.FindSubmatch(m)
+		representation := string(sub[2])
+		if representation == UUIDStandard {
+			return m
+		}
+		b, err := UUIDToBinary(string(sub[1]), representation)
+		if err != nil {
+			return m
+		}
+		return []byte(`{"$binary":{"base64":"` + base64.StdEncoding.EncodeToString(b.Data) + `","subType":"03"}}`)
+	})
+}
@@ -0,0 +1,115 @@
+package bsonutil
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUUIDRepresentations(t *testing.T) {
+	const id = "00112233-4455-6677-8899-aabbccddeeff"
+	tests := []struct {
+		representation string
+		subtype        byte
+		bytes          string
+	}{
+		{UUIDStandard, 0x04, "00112233445566778899aabbccddeeff"},
+		{"", 0x04, "00112233445566778899aabbccddeeff"},
+		{UUIDPythonLegacy, 0x03, "00112233445566778899aabbccddeeff"},
+		{UUIDJavaLegacy, 0x03, "7766554433221100ffeeddccbbaa9988"},
+		{UUIDCSharpLegacy, 0x03, "33221100554477668899aabbccddeeff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.representation, func(t *testing.T) {
+			b, err := UUIDToBinary(id, tt.representation)
+			if err != nil {
+				t.Fatalf("UUIDToBinary failed: %v", err)
+			}
+			if b.Subtype != tt.subtype || hex.EncodeToString(b.Data) != tt.bytes {
+				t.Errorf("UUIDToBinary = subtype %d %x, want subtype %d %s", b.Subtype, b.Data, tt.subtype, tt.bytes)
+			}
+			got, err := BinaryToUUID(b, tt.representation)
+			if err != nil {
+				t.Fatalf("BinaryToUUID failed: %v", err)
+			}
+			if got != id {
+				t.Errorf("BinaryToUUID = %s, want %s", got, id)
+			}
+		})
+	}
+}
+
+func TestUUIDConversion_Errors(t *testing.T) {
+	if _, err := UUIDToBinary("not-a-uuid", UUIDStandard); err == nil {
+		t.Error("Expected error for invalid UUID")
+	}
+	if _, err := UUIDToBinary("00112233-4455-6677-8899-aabbccddeeff", "goLegacy"); err == nil {
+		t.Error("Expected error for unknown representation")
+	}
+	if _, err := BinaryToUUID(primitive.Binary{Subtype: 0x00, Data: make([]byte, 16)}, ""); err == nil {
+		t.Error("Expected error for generic binary subtype")
+	}
+	if _, err := BinaryToUUID(primitive.Binary{Subtype: 0x04, Data: make([]byte, 4)}, ""); err == nil {
+		t.Error("Expected error for short binary")
+	}
+}
+
+func TestRenderUUIDs(t *testing.T) {
+	std, _ := UUIDToBinary("00112233-4455-6677-8899-aabbccddeeff", UUIDStandard)
+	legacy, _ := UUIDToBinary("00112233-4455-6677-8899-aabbccddeeff", UUIDCSharpLegacy)
+	doc := bson.D{{Key: "a", Value: std}, {Key: "b", Value: legacy}}
+	out, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(RenderUUIDs(out))
+	want := `{"a":{"$uuid":"00112233-4455-6677-8899-aabbccddeeff"},"b":{"$binary":{"base64":"MyIRAFVEd2aImaq7zN3u/w==","subType":"03"}}}`
+	if got != want {
+		t.Errorf("RenderUUIDs = %s, want %s", got, want)
+	}
+
+	// The rendered form parses back to the same binary
+	var back bson.D
+	if err := bson.UnmarshalExtJSON([]byte(got), true, &back); err != nil {
+		t.Fatalf("Failed to parse rendered JSON: %v", err)
+	}
+	if b, ok := back[0].Value.(primitive.Binary); !ok || b.Subtype != 0x04 || string(b.Data) != string(std.Data) {
+		t.Errorf("Expected round trip to subtype 4 binary, got %#v", back[0].Value)
+	}
+}
+
+func TestRenderLegacyUUIDs(t *testing.T) {
+	const id = "00112233-4455-6677-8899-aabbccddeeff"
+	for _, rep := range []string{UUIDPythonLegacy, UUIDJavaLegacy, UUIDCSharpLegacy} {
+		legacy, _ := UUIDToBinary(id, rep)
+		out, err := bson.MarshalExtJSON(bson.D{{Key: "_id", Value: legacy}}, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := string(RenderLegacyUUIDs(out, rep))
+		want := `{"_id":{"$uuid":"` + id + `","$uuidRepresentation":"` + rep + `"}}`
+		if got != want {
+			t.Errorf("RenderLegacyUUIDs(%s) = %s, want %s", rep, got, want)
+		}
+
+		// Reformatted output still parses back to the same subtype 3 binary
+		var back bson.D
+		reformatted := `{"_id": { "$uuid": "` + id + `",` + "\n" + `  "$uuidRepresentation": "` + rep + `" }}`
+		if err := UnmarshalExtJSON([]byte(reformatted), true, &back); err != nil {
+			t.Fatalf("Failed to parse rendered %s UUID: %v", rep, err)
+		}
+		if b, ok := back[0].Value.(primitive.Binary); !ok || b.Subtype != 0x03 || string(b.Data) != string(legacy.Data) {
+			t.Errorf("Expected %s round trip to the same subtype 3 binary, got %#v", rep, back[0].Value)
+		}
+	}
+
+	legacy, _ := UUIDToBinary(id, UUIDCSharpLegacy)
+	out, _ := bson.MarshalExtJSON(bson.D{{Key: "_id", Value: legacy}}, true, false)
+	if got := RenderLegacyUUIDs(out, ""); string(got) != string(out) {
+		t.Errorf("Expected binaries to be left alone without a representation, got %s", got)
+	}
+}
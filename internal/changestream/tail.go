@@ -0,0 +1,216 @@
+package changestream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/database"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// TailBacklog is the number of existing documents emitted when a tail starts,
+// so the view opens with recent context rather than empty.
+const TailBacklog = 50
+
+// tailAwaitTime is how long the server waits for new documents before returning an empty batch.
+const tailAwaitTime = time.Second
+
+// TailCollection opens a tailable cursor on a capped collection and returns a tail ID.
+// The last TailBacklog documents are emitted first, then new documents are emitted as
+// "tail:documents" events until StopTail is called or the connection goes away.
+// Tails share lifecycle with watches, so disconnecting stops them too.
+func (s *Service) TailCollection(connID, dbName, collName string) (string, error) {
+	if err := database.ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return "", err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return "", err
+	}
+
+	db := client.Database(dbName)
+	checkCtx, checkCancel := s.state.ContextWithTimeout()
+	capped, err := isCapped(checkCtx, db, collName)
+	checkCancel()
+	if err != nil {
+		return "", err
+	}
+	if !capped {
+		return "", fmt.Errorf("collection %s is not capped; tailing requires a capped collection", collName)
+	}
+
+	coll := db.Collection(collName)
+	ctx, cancel := context.WithCancel(context.Background())
+	cursor, err := openTail(ctx, coll)
+	if err != nil {
+		cancel()
+		return "", fmt.Errorf("failed to open tailable cursor: %w", err)
+	}
+
+	tailID := uuid.New().String()
+	s.mu.Lock()
+	s.watches[tailID] = &watch{connID: connID, cancel: cancel}
+	s.mu.Unlock()
+
+	debug.LogQuery("Tail opened", map[string]interface{}{
+		"tailId":     tailID,
+		"database":   dbName,
+		"collection": collName,
+	})
+
+	go s.tail(ctx, tailID, connID, coll, cursor)
+	return tailID, nil
+}
+
+// StopTail stops a tail started by TailCollection.
+func (s *Service) StopTail(tailID string) error {
+	return s.StopWatch(tailID)
+}
+
+// tail consumes a tailable cursor, reopening it when the server closes it (for example
+// while the collection is still empty) or after transient errors.
+func (s *Service) tail(ctx context.Context, tailID, connID string, coll *mongo.Collection, cursor *mongo.Cursor) {
+	var lastErr error
+	attempts := 0
+	// Existing documents are skipped on the first open, and on reopens once documents
+	// have been seen, since a new cursor starts from the beginning of the collection
+	skipExisting := true
+	seen := false
+	backlog := TailBacklog
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.watches, tailID)
+		s.mu.Unlock()
+
+		data := map[string]interface{}{"tailId": tailID}
+		if lastErr != nil {
+			data["error"] = lastErr.Error()
+		}
+		s.state.EmitConnectionEvent(connID, "tail:stopped", data)
+	}()
+
+	for {
+		if cursor != nil {
+			if skipExisting {
+				if docs := drainCursor(ctx, cursor, backlog); len(docs) > 0 {
+					s.emitTail(connID, tailID, docs, true)
+					seen = true
+				}
+				backlog = 0
+			}
+
+			var batch []string
+			for cursor.Next(ctx) {
+				attempts = 0
+				seen = true
+				if doc, err := bson.MarshalExtJSON(cursor.Current, true, false); err == nil {
+					batch = append(batch, string(doc))
+				}
+				if cursor.RemainingBatchLength() == 0 && len(batch) > 0 {
+					s.emitTail(connID, tailID, batch, false)
+					batch = nil
+				}
+			}
+			if len(batch) > 0 {
+				s.emitTail(connID, tailID, batch, false)
+			}
+			lastErr = cursor.Err()
+			cursor.Close(context.Background())
+			cursor = nil
+			skipExisting = seen
+		}
+
+		if ctx.Err() != nil {
+			lastErr = nil
+			return
+		}
+		if !s.state.HasClient(connID) {
+			lastErr = &core.NotConnectedError{ConnID: connID}
+			return
+		}
+
+		// A cursor closed without error just means there was nothing to tail yet
+		delay := tailAwaitTime
+		if lastErr != nil {
+			attempts++
+			if attempts > MaxResumeAttempts {
+				return
+			}
+			delay = resumeBackoff(attempts)
+		}
+
+		select {
+		case <-ctx.Done():
+			lastErr = nil
+			return
+		case <-time.After(delay):
+		}
+
+		var err error
+		cursor, err = openTail(ctx, coll)
+		if err != nil {
+			lastErr = err
+		}
+	}
+}
+
+// emitTail emits a batch of tailed documents.
+func (s *Service) emitTail(connID, tailID string, docs []string, backlog bool) {
+	s.state.EmitConnectionEvent(connID, "tail:documents", types.TailBatch{
+		TailID:    tailID,
+		Documents: docs,
+		Backlog:   backlog,
+	})
+}
+
+// openTail opens a tailable, awaitData cursor over the whole collection in natural order.
+func openTail(ctx context.Context, coll *mongo.Collection) (*mongo.Cursor, error) {
+	opts := options.Find().
+		SetCursorType(options.TailableAwait).
+		SetMaxAwaitTime(tailAwaitTime)
+	return coll.Find(ctx, bson.D{}, opts)
+}
+
+// drainCursor reads past the documents currently available on a cursor without waiting
+// for new ones, returning the last keep of them as Extended JSON.
+func drainCursor(ctx context.Context, cursor *mongo.Cursor, keep int) []string {
+	var docs []string
+	for cursor.TryNext(ctx) {
+		if keep == 0 {
+			continue
+		}
+		if b, err := bson.MarshalExtJSON(cursor.Current, true, false); err == nil {
+			docs = append(docs, string(b))
+			if len(docs) > keep {
+				docs = docs[1:]
+			}
+		}
+	}
+	return docs
+}
+
+// isCapped reports whether a collection is capped.
+func isCapped(ctx context.Context, db *mongo.Database, collName string) (bool, error) {
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: collName}})
+	if err != nil {
+		return false, fmt.Errorf("failed to get collection info: %w", err)
+	}
+	if len(specs) == 0 {
+		return false, fmt.Errorf("collection %s not found", collName)
+	}
+	if specs[0].Options == nil {
+		return false, nil
+	}
+	capped, _ := specs[0].Options.Lookup("capped").BooleanOK()
+	return capped, nil
+}
@@ -0,0 +1,38 @@
+package changestream
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestTailCollection_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.TailCollection("conn-1", "db", "logs")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestTailCollection_InvalidNames(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.TailCollection("conn-1", "", "logs"); err == nil {
+		t.Error("Expected error for empty database name")
+	}
+	if _, err := svc.TailCollection("conn-1", "db", ""); err == nil {
+		t.Error("Expected error for empty collection name")
+	}
+}
+
+func TestStopTail_NotFound(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	var notFound *WatchNotFoundError
+	if err := svc.StopTail("missing"); !errors.As(err, &notFound) {
+		t.Fatalf("Expected WatchNotFoundError, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+// Package changestream watches MongoDB collections and streams changes to the UI.
+package changestream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/database"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MaxResumeAttempts is the number of consecutive failed resume attempts
+// before a watch gives up and stops.
+const MaxResumeAttempts = 5
+
+// WatchNotFoundError is returned when stopping a watch that is not running.
+type WatchNotFoundError struct {
+	WatchID string
+}
+
+func (e *WatchNotFoundError) Error() string {
+	return fmt.Sprintf("watch not found: %s", e.WatchID)
+}
+
+// watch tracks a running change stream.
+type watch struct {
+	connID string
+	cancel context.CancelFunc
+}
+
+// Service manages collection change streams.
+type Service struct {
+	state   *core.AppState
+	mu      sync.Mutex
+	watches map[string]*watch
+}
+
+// NewService creates a new change stream service.
+func NewService(state *core.AppState) *Service {
+	return &Service{
+		state:   state,
+		watches: make(map[string]*watch),
+	}
+}
+
+// WatchCollection opens a change stream on a collection and returns a watch ID.
+// Changes are emitted as "changestream:event" events until StopWatch is called,
+// the connection goes away, or resuming fails MaxResumeAttempts times in a row.
+func (s *Service) WatchCollection(connID, dbName, collName, pipelineJSON string) (string, error) {
+	if err := database.ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return "", err
+	}
+
+	pipeline, err := document.ParsePipeline(pipelineJSON)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return "", err
+	}
+
+	coll := client.Database(dbName).Collection(collName)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Open the first stream synchronously so setup errors (e.g. standalone server) reach the caller
+	stream, err := coll.Watch(ctx, pipeline, changeStreamOptions(nil))
+	if err != nil {
+		cancel()
+		return "", fmt.Errorf("failed to open change stream: %w", err)
+	}
+
+	watchID := uuid.New().String()
+	s.mu.Lock()
+	s.watches[watchID] = &watch{connID: connID, cancel: cancel}
+	s.mu.Unlock()
+
+	debug.LogQuery("Change stream opened", map[string]interface{}{
+		"watchId":    watchID,
+		"database":   dbName,
+		"collection": collName,
+		"stages":     len(pipeline),
+	})
+
+	go s.run(ctx, watchID, connID, coll, pipeline, stream)
+	return watchID, nil
+}
+
+// StopWatch stops a running change stream.
+func (s *Service) StopWatch(watchID string) error {
+	s.mu.Lock()
+	w, ok := s.watches[watchID]
+	if ok {
+		delete(s.watches, watchID)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return &WatchNotFoundError{WatchID: watchID}
+	}
+	w.cancel()
+	return nil
+}
+
+// StopWatchesForConnection stops all change streams opened on a connection.
+func (s *Service) StopWatchesForConnection(connID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, w := range s.watches {
+		if w.connID == connID {
+			w.cancel()
+			delete(s.watches, id)
+		}
+	}
+}
+
+// StopAll stops every running change stream.
+func (s *Service) StopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, w := range s.watches {
+		w.cancel()
+		delete(s.watches, id)
+	}
+}
+
+// run consumes a change stream, reopening it from the last resume token after
+// transient failures.
+func (s *Service) run(ctx context.Context, watchID, connID string, coll *mongo.Collection, pipeline mongo.Pipeline, stream *mongo.ChangeStream) {
+	var resumeToken bson.Raw
+	var lastErr error
+	attempts := 0
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.watches, watchID)
+		s.mu.Unlock()
+
+		data := map[string]interface{}{"watchId": watchID}
+		if lastErr != nil {
+			data["error"] = lastErr.Error()
+		}
+		s.state.EmitConnectionEvent(connID, "changestream:stopped", data)
+	}()
+
+	for {
+		if stream != nil {
+			for stream.Next(ctx) {
+				attempts = 0
+				resumeToken = stream.ResumeToken()
+				s.emitChange(connID, watchID, stream.Current, resumeToken)
+			}
+			lastErr = stream.Err()
+			stream.Close(context.Background())
+			stream = nil
+		}
+
+		if ctx.Err() != nil {
+			lastErr = nil
+			return
+		}
+		// A removed client means the user disconnected; nothing to resume
+		if !s.state.HasClient(connID) {
+			lastErr = &core.NotConnectedError{ConnID: connID}
+			return
+		}
+
+		attempts++
+		if attempts > MaxResumeAttempts {
+			return
+		}
+
+		errMsg := ""
+		if lastErr != nil {
+			errMsg = lastErr.Error()
+		}
+		s.state.EmitConnectionEvent(connID, "changestream:resuming", map[string]interface{}{
+			"watchId": watchID,
+			"attempt": attempts,
+			"error":   errMsg,
+		})
+
+		select {
+		case <-ctx.Done():
+			lastErr = nil
+			return
+		case <-time.After(resumeBackoff(attempts)):
+		}
+
+		var err error
+		stream, err = coll.Watch(ctx, pipeline, changeStreamOptions(resumeToken))
+		if err != nil {
+			lastErr = err
+		}
+	}
+}
+
+// emitChange converts a raw change event into a ChangeStreamEvent and emits it.
+func (s *Service) emitChange(connID, watchID string, raw bson.Raw, resumeToken bson.Raw) {
+	event := types.ChangeStreamEvent{WatchID: watchID}
+
+	if op, ok := raw.Lookup("operationType").StringValueOK(); ok {
+		event.OperationType = op
+	}
+	if key, err := raw.LookupErr("documentKey"); err == nil {
+		if doc, ok := key.DocumentOK(); ok {
+			if b, err := bson.MarshalExtJSON(doc, true, false); err == nil {
+				event.DocumentKey = string(b)
+			}
+		}
+	}
+	if b, err := bson.MarshalExtJSON(raw, true, false); err == nil {
+		event.Event = string(b)
+	}
+	if resumeToken != nil {
+		if b, err := bson.MarshalExtJSON(resumeToken, true, false); err == nil {
+			event.ResumeToken = string(b)
+		}
+	}
+
+	s.state.EmitConnectionEvent(connID, "changestream:event", event)
+}
+
+// changeStreamOptions returns the options used for every stream, resuming after
+// the given token when set.
+func changeStreamOptions(resumeToken bson.Raw) *options.ChangeStreamOptions {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != nil {
+		opts.SetResumeAfter(resumeToken)
+	}
+	return opts
+}
+
+// resumeBackoff returns the delay before the given resume attempt (1-based),
+// doubling from 500ms and capped at 10s.
+func resumeBackoff(attempt int) time.Duration {
+	const maxBackoff = 10 * time.Second
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := 500 * time.Millisecond
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,58 @@
+package changestream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestResumeBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 500 * time.Millisecond},
+		{attempt: 1, want: 500 * time.Millisecond},
+		{attempt: 2, want: time.Second},
+		{attempt: 3, want: 2 * time.Second},
+		{attempt: 5, want: 8 * time.Second},
+		{attempt: 6, want: 10 * time.Second},
+		{attempt: 50, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := resumeBackoff(tt.attempt); got != tt.want {
+			t.Errorf("resumeBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestStopWatch_NotFound(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	err := svc.StopWatch("missing")
+	var notFound *WatchNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected WatchNotFoundError, got %v", err)
+	}
+}
+
+func TestWatchCollection_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.WatchCollection("conn-1", "db", "coll", "")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestWatchCollection_InvalidPipeline(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.WatchCollection("conn-1", "db", "coll", `{"$match": {}}`); err == nil {
+		t.Error("Expected error for non-array pipeline")
+	}
+}
@@ -0,0 +1,93 @@
+package connection
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// authMechanismX509 authenticates with the TLS client certificate instead of a password.
+const authMechanismX509 = "MONGODB-X509"
+
+// authMechanismGSSAPI authenticates with Kerberos.
+const authMechanismGSSAPI = "GSSAPI"
+
+// authMechanismPLAIN authenticates against LDAP with the password sent as is.
+const authMechanismPLAIN = "PLAIN"
+
+// externalAuthSource is the database of users managed outside MongoDB (LDAP, X.509, Kerberos).
+const externalAuthSource = "$external"
+
+// KerberosSupported reports whether this build can authenticate with Kerberos (GSSAPI).
+func KerberosSupported() bool {
+	return gssapiSupported
+}
+
+// validateAuthSettings checks that the authentication mechanism of clientOpts has what it
+// needs once the URI and connection settings are applied.
+func validateAuthSettings(clientOpts *options.ClientOptions) error {
+	auth := clientOpts.Auth
+	if auth == nil {
+		return nil
+	}
+	switch strings.ToUpper(auth.AuthMechanism) {
+	case authMechanismX509:
+		return validateX509Auth(clientOpts)
+	case authMechanismGSSAPI:
+		return validateGSSAPIAuth(clientOpts, gssapiSupported)
+	case authMechanismPLAIN:
+		return validatePLAINAuth(clientOpts)
+	}
+	return nil
+}
+
+// authWarnings returns what is unsafe about the authentication settings of clientOpts
+// without preventing the connection.
+func authWarnings(clientOpts *options.ClientOptions) []string {
+	var warnings []string
+	auth := clientOpts.Auth
+	if auth != nil && strings.EqualFold(auth.AuthMechanism, authMechanismPLAIN) && clientOpts.TLSConfig == nil {
+		warnings = append(warnings, "LDAP (PLAIN) authentication sends the password unencrypted; enable TLS")
+	}
+	return warnings
+}
+
+// validateX509Auth checks MONGODB-X509 authentication: the client certificate of the TLS
+// settings (or the URI's tlsCertificateKeyFile) is the identity. The username may be left
+// empty, in which case the server takes it from the certificate subject. The driver already
+// rejects passwords and auth sources other than $external.
+func validateX509Auth(clientOpts *options.ClientOptions) error {
+	if clientOpts.TLSConfig == nil {
+		return fmt.Errorf("X.509 authentication requires TLS; enable TLS in the connection settings")
+	}
+	if len(clientOpts.TLSConfig.Certificates) == 0 && clientOpts.TLSConfig.GetClientCertificate == nil {
+		return fmt.Errorf("X.509 authentication requires a client certificate; add the client certificate and key in the TLS settings")
+	}
+	return nil
+}
+
+// validateGSSAPIAuth checks Kerberos authentication: the username is the Kerberos principal
+// (user@REALM), and the password is optional when a ticket cache or keytab is available.
+func validateGSSAPIAuth(clientOpts *options.ClientOptions, supported bool) error {
+	if !supported {
+		return fmt.Errorf("Kerberos (GSSAPI) authentication is not available in this build; it must be built with -tags gssapi")
+	}
+	if clientOpts.Auth.Username == "" {
+		return fmt.Errorf("Kerberos authentication requires the user principal (user@REALM) as the username")
+	}
+	return nil
+}
+
+// validatePLAINAuth checks LDAP authentication over PLAIN: LDAP users exist only in
+// $external, which the driver uses when no authSource is given.
+func validatePLAINAuth(clientOpts *options.ClientOptions) error {
+	auth := clientOpts.Auth
+	if auth.Username == "" {
+		return fmt.Errorf("LDAP authentication requires a username")
+	}
+	if auth.AuthSource != "" && auth.AuthSource != externalAuthSource {
+		return fmt.Errorf("LDAP users authenticate against %s, not %q; set authSource=%s", externalAuthSource, auth.AuthSource, externalAuthSource)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package connection
+
+import (
+	"crypto/tls"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestValidateAuthSettings_X509(t *testing.T) {
+	withCert := &tls.Config{Certificates: []tls.Certificate{{}}}
+
+	tests := []struct {
+		name   string
+		uri    string
+		tls    *tls.Config
+		errMsg string
+	}{
+		{"no auth", "mongodb://localhost", nil, ""},
+		{"scram", "mongodb://u:p@localhost", nil, ""},
+		{"x509 with certificate", "mongodb://localhost/?authMechanism=MONGODB-X509", withCert, ""},
+		{"x509 with username", "mongodb://CN=client@localhost/?authMechanism=MONGODB-X509&authSource=$external", withCert, ""},
+		{"x509 without TLS", "mongodb://localhost/?authMechanism=MONGODB-X509", nil, "requires TLS"},
+		{"x509 without certificate", "mongodb://localhost/?authMechanism=MONGODB-X509&tls=true", nil, "requires a client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options.Client().ApplyURI(tt.uri)
+			if tt.tls != nil {
+				opts.SetTLSConfig(tt.tls)
+			}
+			err := validateAuthSettings(opts)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateGSSAPIAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		uri       string
+		supported bool
+		errMsg    string
+	}{
+		{"principal", "mongodb://user%40EXAMPLE.COM@localhost/?authMechanism=GSSAPI", true, ""},
+		{"service name", "mongodb://user%40EXAMPLE.COM@localhost/?authMechanism=GSSAPI&authMechanismProperties=SERVICE_NAME:mongo,CANONICALIZE_HOST_NAME:true", true, ""},
+		{"not built in", "mongodb://user%40EXAMPLE.COM@localhost/?authMechanism=GSSAPI", false, "-tags gssapi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options.Client().ApplyURI(tt.uri)
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("invalid test URI: %v", err)
+			}
+			err := validateGSSAPIAuth(opts, tt.supported)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateAuthSettings_PLAIN(t *testing.T) {
+	tests := []struct {
+		name        string
+		uri         string
+		errMsg      string
+		wantWarning bool
+	}{
+		{"over TLS", "mongodb://ldapuser:pw@localhost/?authMechanism=PLAIN&tls=true", "", false},
+		{"explicit $external", "mongodb://ldapuser:pw@localhost/?authMechanism=PLAIN&authSource=$external&tls=true", "", false},
+		{"without TLS", "mongodb://ldapuser:pw@localhost/?authMechanism=PLAIN", "", true},
+		{"admin auth source", "mongodb://ldapuser:pw@localhost/?authMechanism=PLAIN&authSource=admin&tls=true", "$external", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options.Client().ApplyURI(tt.uri)
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("invalid test URI: %v", err)
+			}
+			err := validateAuthSettings(opts)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(authWarnings(opts)) > 0; got != tt.wantWarning {
+				t.Errorf("warning = %v, want %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
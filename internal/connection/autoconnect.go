@@ -0,0 +1,83 @@
+package connection
+
+import (
+	"sync"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Auto-connect statuses.
+const (
+	AutoConnectConnecting = "connecting"
+	AutoConnectConnected  = "connected"
+	AutoConnectFailed     = "failed"
+)
+
+// AutoConnect connects every saved connection marked AutoConnect that is not connected yet,
+// in parallel, emitting a "connection:autoconnect" event as each starts and when it connects
+// or fails. It returns once all attempts have finished.
+func (s *Service) AutoConnect() {
+	s.autoConnect(s.Connect)
+}
+
+// autoConnect runs AutoConnect with the given connect function.
+func (s *Service) autoConnect(connect func(connID string) error) {
+	s.state.Mu.RLock()
+	var connIDs []string
+	for _, c := range s.state.SavedConnections {
+		if c.AutoConnect {
+			connIDs = append(connIDs, c.ID)
+		}
+	}
+	s.state.Mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, connID := range connIDs {
+		if s.state.HasClient(connID) {
+			continue
+		}
+		wg.Add(1)
+		go func(connID string) {
+			defer wg.Done()
+			start := time.Now()
+			s.setAutoConnectStatus(types.AutoConnectStatus{ConnectionID: connID, Status: AutoConnectConnecting})
+
+			status := types.AutoConnectStatus{ConnectionID: connID, Status: AutoConnectConnected}
+			if err := connect(connID); err != nil {
+				status.Status = AutoConnectFailed
+				status.Error = err.Error()
+			}
+			status.DurationMS = time.Since(start).Milliseconds()
+			s.setAutoConnectStatus(status)
+		}(connID)
+	}
+	wg.Wait()
+
+	if len(connIDs) > 0 {
+		debug.LogConnection("Auto-connect finished", map[string]interface{}{
+			"connections": len(connIDs),
+		})
+	}
+}
+
+// setAutoConnectStatus records and emits the auto-connect status of a connection.
+func (s *Service) setAutoConnectStatus(status types.AutoConnectStatus) {
+	s.autoMu.Lock()
+	s.autoStatus[status.ConnectionID] = status
+	s.autoMu.Unlock()
+	s.state.EmitConnectionEvent(status.ConnectionID, "connection:autoconnect", status)
+}
+
+// GetAutoConnectStatus returns the latest auto-connect status of each connection attempted
+// since startup, so a UI that loads after the events were emitted can catch up.
+func (s *Service) GetAutoConnectStatus() []types.AutoConnectStatus {
+	s.autoMu.Lock()
+	defer s.autoMu.Unlock()
+	result := make([]types.AutoConnectStatus, 0, len(s.autoStatus))
+	for _, status := range s.autoStatus {
+		result = append(result, status)
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package connection
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// statusEmitter records the auto-connect events emitted through it.
+type statusEmitter struct {
+	mu     sync.Mutex
+	events []types.AutoConnectStatus
+}
+
+func (e *statusEmitter) Emit(eventName string, data interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if status, ok := data.(types.AutoConnectStatus); ok && eventName == "connection:autoconnect" {
+		e.events = append(e.events, status)
+	}
+}
+
+func TestAutoConnect(t *testing.T) {
+	state := core.NewAppState()
+	emitter := &statusEmitter{}
+	state.Emitter = emitter
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "daily", AutoConnect: true},
+		{ID: "broken", AutoConnect: true},
+		{ID: "manual"},
+	}
+	svc := NewService(state, nil)
+
+	var mu sync.Mutex
+	var connected []string
+	svc.autoConnect(func(connID string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		connected = append(connected, connID)
+		if connID == "broken" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	sort.Strings(connected)
+	if len(connected) != 2 || connected[0] != "broken" || connected[1] != "daily" {
+		t.Fatalf("connected %v, want [broken daily]", connected)
+	}
+	if len(emitter.events) != 4 {
+		t.Errorf("expected a connecting and a final event per connection, got %+v", emitter.events)
+	}
+
+	statuses := svc.GetAutoConnectStatus()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ConnectionID < statuses[j].ConnectionID })
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %+v", statuses)
+	}
+	if statuses[0].Status != AutoConnectFailed || statuses[0].Error != "connection refused" {
+		t.Errorf("unexpected status for broken: %+v", statuses[0])
+	}
+	if statuses[1].Status != AutoConnectConnected {
+		t.Errorf("unexpected status for daily: %+v", statuses[1])
+	}
+}
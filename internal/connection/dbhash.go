@@ -0,0 +1,161 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// memberHashes is the dbHash of one member, by collection.
+type memberHashes struct {
+	host   string
+	hashes map[string]string
+}
+
+// CompareReplicaMembers runs dbHash for a database on every data-bearing member of a
+// connection's replica set, each through its own direct connection, and reports the
+// collections whose hashes differ. Members still applying writes can differ briefly, so
+// compare again or check the member optimes before acting on a mismatch.
+func (s *Service) CompareReplicaMembers(connID, dbName string) (*types.ReplicaConsistencyReport, error) {
+	if dbName == "" {
+		return nil, fmt.Errorf("database name cannot be empty")
+	}
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	var status struct {
+		Members []struct {
+			Name       string    `bson:"name"`
+			State      int       `bson:"state"`
+			StateStr   string    `bson:"stateStr"`
+			Health     float64   `bson:"health"`
+			OptimeDate time.Time `bson:"optimeDate"`
+		} `bson:"members"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to get replica set status: %w", err)
+	}
+
+	uri, err := s.connStore.GetConnectionURI(connID)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.connStore.GetExtendedConnection(connID)
+	if err != nil {
+		conn = types.ExtendedConnection{}
+	}
+	if conn.SSHEnabled {
+		return nil, fmt.Errorf("replica members cannot be compared through an SSH tunnel")
+	}
+
+	report := &types.ReplicaConsistencyReport{
+		Database:   dbName,
+		Members:    []types.MemberHash{},
+		Mismatches: []types.CollectionMismatch{},
+	}
+	var results []memberHashes
+	for _, m := range status.Members {
+		// Only primaries and secondaries hold data that can be hashed
+		if m.State != 1 && m.State != 2 {
+			continue
+		}
+		member := types.MemberHash{Host: m.Name, State: m.StateStr, OptimeDate: m.OptimeDate}
+		if m.Health == 0 {
+			member.Error = "member is unreachable"
+			report.Members = append(report.Members, member)
+			continue
+		}
+		hashes, md5, err := s.memberDBHash(uri, conn, m.Name, dbName)
+		if err != nil {
+			member.Error = err.Error()
+		} else {
+			member.MD5 = md5
+			member.Collections = len(hashes)
+			results = append(results, memberHashes{host: m.Name, hashes: hashes})
+		}
+		report.Members = append(report.Members, member)
+	}
+	if len(report.Members) == 0 {
+		return nil, fmt.Errorf("no data-bearing replica set members found")
+	}
+
+	report.Mismatches = compareMemberHashes(results)
+	report.Consistent = len(report.Mismatches) == 0 && len(results) == len(report.Members)
+
+	debug.LogConnection("Replica members compared", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"members":      len(report.Members),
+		"mismatches":   len(report.Mismatches),
+	})
+	return report, nil
+}
+
+// memberDBHash runs dbHash on one member through a direct connection with the connection's
+// credentials and TLS settings, returning the hash of each collection and of the database.
+func (s *Service) memberDBHash(uri string, conn types.ExtendedConnection, host, dbName string) (map[string]string, string, error) {
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	clientOpts := options.Client().ApplyURI(uri)
+	if err := applyTLSConfig(clientOpts, conn, nil); err != nil {
+		return nil, "", fmt.Errorf("invalid TLS settings: %w", err)
+	}
+	clientOpts.SetHosts([]string{host}).SetDirect(true)
+	client, err := mongo.Connect(ctx, clientOpts)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect: %w", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	var result struct {
+		Collections map[string]string `bson:"collections"`
+		MD5         string            `bson:"md5"`
+	}
+	if err := client.Database(dbName).RunCommand(ctx, bson.D{{Key: "dbHash", Value: 1}}).Decode(&result); err != nil {
+		return nil, "", fmt.Errorf("dbHash failed: %w", err)
+	}
+	if result.Collections == nil {
+		result.Collections = map[string]string{}
+	}
+	return result.Collections, result.MD5, nil
+}
+
+// compareMemberHashes returns the collections whose hash is not the same on every member,
+// sorted by name.
+func compareMemberHashes(members []memberHashes) []types.CollectionMismatch {
+	names := make(map[string]bool)
+	for _, m := range members {
+		for name := range m.hashes {
+			names[name] = true
+		}
+	}
+
+	mismatches := []types.CollectionMismatch{}
+	for name := range names {
+		hashes := make(map[string]string, len(members))
+		distinct := make(map[string]bool)
+		for _, m := range members {
+			hashes[m.host] = m.hashes[name]
+			distinct[m.hashes[name]] = true
+		}
+		if len(distinct) > 1 {
+			mismatches = append(mismatches, types.CollectionMismatch{Collection: name, Hashes: hashes})
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Collection < mismatches[j].Collection })
+	return mismatches
+}
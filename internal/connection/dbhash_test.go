@@ -0,0 +1,44 @@
+package connection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestCompareMemberHashes(t *testing.T) {
+	mismatches := compareMemberHashes([]memberHashes{
+		{host: "a:27017", hashes: map[string]string{"orders": "h1", "users": "h2", "carts": "h3"}},
+		{host: "b:27017", hashes: map[string]string{"orders": "h1", "users": "hX", "carts": "h3"}},
+		{host: "c:27017", hashes: map[string]string{"orders": "h1", "users": "h2"}},
+	})
+	if len(mismatches) != 2 {
+		t.Fatalf("expected 2 mismatches, got %+v", mismatches)
+	}
+	if mismatches[0].Collection != "carts" || mismatches[0].Hashes["c:27017"] != "" {
+		t.Errorf("missing collection should be reported with an empty hash: %+v", mismatches[0])
+	}
+	if mismatches[1].Collection != "users" || mismatches[1].Hashes["b:27017"] != "hX" {
+		t.Errorf("unexpected mismatch: %+v", mismatches[1])
+	}
+
+	if got := compareMemberHashes([]memberHashes{
+		{host: "a:27017", hashes: map[string]string{"orders": "h1"}},
+		{host: "b:27017", hashes: map[string]string{"orders": "h1"}},
+	}); len(got) != 0 {
+		t.Errorf("expected no mismatches, got %+v", got)
+	}
+}
+
+func TestCompareReplicaMembers_Validation(t *testing.T) {
+	s := NewService(core.NewAppState(), nil)
+
+	if _, err := s.CompareReplicaMembers("conn-1", ""); err == nil {
+		t.Error("expected error for empty database name")
+	}
+	var notConnected *core.NotConnectedError
+	if _, err := s.CompareReplicaMembers("conn-1", "shop"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
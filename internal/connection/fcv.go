@@ -0,0 +1,111 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// fcvVersion matches feature compatibility versions such as "7.0".
+var fcvVersion = regexp.MustCompile(`^\d+\.\d+$`)
+
+// GetFCV returns the feature compatibility version of a connection's deployment.
+func (s *Service) GetFCV(connID string) (*types.FeatureCompatibility, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	return readFCV(ctx, client)
+}
+
+// SetFCV sets the feature compatibility version of a connection's deployment, as done before
+// a downgrade or after an upgrade. On connections with safety settings it needs a confirmed
+// "set feature compatibility version" token for the version.
+func (s *Service) SetFCV(connID, version string) error {
+	if err := s.state.CheckWritable(connID, "set feature compatibility version"); err != nil {
+		return err
+	}
+
+	if !fcvVersion.MatchString(version) {
+		return fmt.Errorf("invalid feature compatibility version %q: expected major.minor, e.g. 7.0", version)
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return err
+	}
+	if err := s.state.RequireConfirmation(connID, "set feature compatibility version", version); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	current, err := readFCV(ctx, client)
+	if err != nil {
+		return err
+	}
+	cmd := bson.D{{Key: "setFeatureCompatibilityVersion", Value: version}}
+	// 7.0 made the confirm field mandatory; older servers reject it
+	if majorVersion(current.ServerVersion) >= 7 {
+		cmd = append(cmd, bson.E{Key: "confirm", Value: true})
+	}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to set feature compatibility version: %w", err)
+	}
+
+	debug.LogConnection("Feature compatibility version changed", map[string]interface{}{
+		"connectionId": connID,
+		"from":         current.Version,
+		"to":           version,
+	})
+	return nil
+}
+
+// readFCV reads the feature compatibility version and server version.
+func readFCV(ctx context.Context, client *mongo.Client) (*types.FeatureCompatibility, error) {
+	admin := client.Database("admin")
+
+	var result struct {
+		FCV struct {
+			Version         string `bson:"version"`
+			TargetVersion   string `bson:"targetVersion"`
+			PreviousVersion string `bson:"previousVersion"`
+		} `bson:"featureCompatibilityVersion"`
+	}
+	cmd := bson.D{{Key: "getParameter", Value: 1}, {Key: "featureCompatibilityVersion", Value: 1}}
+	if err := admin.RunCommand(ctx, cmd).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to get feature compatibility version: %w", err)
+	}
+	fcv := &types.FeatureCompatibility{
+		Version:         result.FCV.Version,
+		TargetVersion:   result.FCV.TargetVersion,
+		PreviousVersion: result.FCV.PreviousVersion,
+	}
+
+	var buildInfo bson.M
+	if err := admin.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo); err == nil {
+		fcv.ServerVersion = bsonutil.ToString(buildInfo["version"])
+	}
+	return fcv, nil
+}
+
+// majorVersion returns the major version of a server version string, or 0 if unknown.
+func majorVersion(version string) int {
+	major, _, _ := strings.Cut(version, ".")
+	n, _ := strconv.Atoi(major)
+	return n
+}
@@ -0,0 +1,59 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestSetFCV_Validation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "ro", ReadOnly: true},
+		{ID: "safe", RequireDeleteConfirmation: true},
+	}
+	s := NewService(state, nil)
+
+	var readOnly *core.ReadOnlyError
+	if err := s.SetFCV("ro", "7.0"); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+	for _, v := range []string{"", "7", "7.0.1", "v7.0"} {
+		if err := s.SetFCV("conn-1", v); err == nil || !strings.Contains(err.Error(), "invalid feature compatibility version") {
+			t.Errorf("version %q: expected validation error, got %v", v, err)
+		}
+	}
+	var notConnected *core.NotConnectedError
+	if err := s.SetFCV("conn-1", "7.0"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+	if _, err := s.GetFCV("conn-1"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+	state.SetClient("safe", client)
+	var required *core.ConfirmationRequiredError
+	if err := s.SetFCV("safe", "7.0"); !errors.As(err, &required) {
+		t.Errorf("expected ConfirmationRequiredError, got %v", err)
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	for version, want := range map[string]int{"7.0.12": 7, "4.4.0-rc1": 4, "": 0, "dev": 0} {
+		if got := majorVersion(version); got != want {
+			t.Errorf("majorVersion(%q) = %d, want %d", version, got, want)
+		}
+	}
+}
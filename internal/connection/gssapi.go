@@ -0,0 +1,8 @@
+//go:build gssapi
+// +build gssapi
+
+package connection
+
+// gssapiSupported reports whether the driver was built with Kerberos support (-tags gssapi,
+// which needs cgo and the system GSSAPI or SSPI libraries).
+const gssapiSupported = true
@@ -0,0 +1,218 @@
+package connection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// HealthCheckInterval is how often the health monitor pings each connected client.
+const HealthCheckInterval = 15 * time.Second
+
+const (
+	// healthPingTimeout bounds a single health check ping.
+	healthPingTimeout = 5 * time.Second
+	// degradedLatency is the average ping latency above which a connection is degraded.
+	degradedLatency = 500 * time.Millisecond
+	// latencyWindow is how many recent pings the rolling latency averages.
+	latencyWindow = 10
+	// downAfterFailures is how many pings in a row must fail before a connection is down;
+	// a single failure only degrades it.
+	downAfterFailures = 2
+)
+
+// Health statuses and the events emitted when a connection changes between them.
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthDown      = "down"
+	HealthRecovered = "recovered"
+)
+
+// connHealth is the health check history of one connection.
+type connHealth struct {
+	latencies []time.Duration
+	current   types.ConnectionHealth
+}
+
+// HealthMonitor pings every connected client in the background and emits a
+// "connection:health" event when a connection becomes degraded or down, or recovers.
+type HealthMonitor struct {
+	state    *core.AppState
+	interval time.Duration
+	timeout  time.Duration
+	ping     func(ctx context.Context, client *mongo.Client) error
+
+	mu     sync.Mutex
+	health map[string]*connHealth
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewHealthMonitor creates a health monitor for the clients of state. It does nothing
+// until Start is called.
+func NewHealthMonitor(state *core.AppState) *HealthMonitor {
+	return &HealthMonitor{
+		state:    state,
+		interval: HealthCheckInterval,
+		timeout:  healthPingTimeout,
+		ping: func(ctx context.Context, client *mongo.Client) error {
+			return client.Ping(ctx, nil)
+		},
+		health: make(map[string]*connHealth),
+	}
+}
+
+// Start begins checking connections every interval. Calling Start on a running monitor
+// does nothing.
+func (m *HealthMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	m.wg.Add(1)
+	go m.run(m.stop)
+}
+
+// Stop stops the background checks and waits for a running check to finish.
+func (m *HealthMonitor) Stop() {
+	m.mu.Lock()
+	stop := m.stop
+	m.stop = nil
+	m.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		m.wg.Wait()
+	}
+}
+
+// Get returns the latest health of a connection, or false if it has not been checked since
+// it connected.
+func (m *HealthMonitor) Get(connID string) (types.ConnectionHealth, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.health[connID]
+	if !ok {
+		return types.ConnectionHealth{}, false
+	}
+	return h.current, true
+}
+
+// run checks all connections every interval until stop is closed.
+func (m *HealthMonitor) run(stop chan struct{}) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.CheckAll()
+		}
+	}
+}
+
+// CheckAll pings all connected clients concurrently and records the results. Connections
+// that were disconnected since the last check are forgotten.
+func (m *HealthMonitor) CheckAll() {
+	clients := m.state.GetAllClients()
+
+	m.mu.Lock()
+	for id := range m.health {
+		if _, ok := clients[id]; !ok {
+			delete(m.health, id)
+		}
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for id, client := range clients {
+		wg.Add(1)
+		go func(connID string, client *mongo.Client) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+			defer cancel()
+			start := time.Now()
+			err := m.ping(ctx, client)
+			m.record(connID, time.Since(start), err)
+		}(id, client)
+	}
+	wg.Wait()
+}
+
+// record adds a ping result to a connection's history, updates its status, and emits an
+// event if the status changed.
+func (m *HealthMonitor) record(connID string, latency time.Duration, pingErr error) types.ConnectionHealth {
+	m.mu.Lock()
+	h, seen := m.health[connID]
+	if !seen {
+		h = &connHealth{current: types.ConnectionHealth{ConnectionID: connID, Status: HealthHealthy}}
+		m.health[connID] = h
+	}
+	previous := h.current.Status
+
+	health := h.current
+	health.CheckedAt = time.Now()
+	health.Event = ""
+	if pingErr != nil {
+		health.Failures++
+		health.Error = pingErr.Error()
+		health.Status = HealthDegraded
+		if health.Failures >= downAfterFailures {
+			health.Status = HealthDown
+		}
+	} else {
+		h.latencies = append(h.latencies, latency)
+		if len(h.latencies) > latencyWindow {
+			h.latencies = h.latencies[len(h.latencies)-latencyWindow:]
+		}
+		var total time.Duration
+		for _, l := range h.latencies {
+			total += l
+		}
+		avg := total / time.Duration(len(h.latencies))
+
+		health.Failures = 0
+		health.Error = ""
+		health.LatencyMS = durationMS(latency)
+		health.AvgLatencyMS = durationMS(avg)
+		health.Status = HealthHealthy
+		if avg > degradedLatency {
+			health.Status = HealthDegraded
+		}
+	}
+
+	if health.Status != previous {
+		health.Event = health.Status
+		if health.Status == HealthHealthy {
+			health.Event = HealthRecovered
+		}
+	}
+	h.current = health
+	m.mu.Unlock()
+
+	if health.Event != "" {
+		debug.LogConnection("Connection health changed", map[string]interface{}{
+			"connectionId": connID,
+			"status":       health.Status,
+			"latencyMs":    health.AvgLatencyMS,
+			"error":        health.Error,
+		})
+		m.state.EmitConnectionEvent(connID, "connection:health", health)
+	}
+	return health
+}
+
+// durationMS converts a duration to fractional milliseconds.
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,130 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// recordingEmitter records the events emitted through it.
+type recordingEmitter struct {
+	mu     sync.Mutex
+	events []types.ConnectionHealth
+}
+
+func (e *recordingEmitter) Emit(eventName string, data interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if h, ok := data.(types.ConnectionHealth); ok && eventName == "connection:health" {
+		e.events = append(e.events, h)
+	}
+}
+
+func (e *recordingEmitter) eventNames() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var names []string
+	for _, h := range e.events {
+		names = append(names, h.Event)
+	}
+	return names
+}
+
+func TestHealthMonitor_Transitions(t *testing.T) {
+	state := core.NewAppState()
+	emitter := &recordingEmitter{}
+	state.Emitter = emitter
+	m := NewHealthMonitor(state)
+
+	steps := []struct {
+		latency    time.Duration
+		err        error
+		wantStatus string
+		wantEvent  string
+	}{
+		{10 * time.Millisecond, nil, HealthHealthy, ""},
+		{20 * time.Millisecond, nil, HealthHealthy, ""},
+		{0, errors.New("timeout"), HealthDegraded, HealthDegraded},
+		{0, errors.New("timeout"), HealthDown, HealthDown},
+		{0, errors.New("timeout"), HealthDown, ""},
+		{30 * time.Millisecond, nil, HealthHealthy, HealthRecovered},
+		{3 * time.Second, nil, HealthDegraded, HealthDegraded}, // Average (3060ms / 4) over the threshold
+	}
+	for i, step := range steps {
+		got := m.record("conn-1", step.latency, step.err)
+		if got.Status != step.wantStatus || got.Event != step.wantEvent {
+			t.Fatalf("step %d: status %q event %q, want %q %q", i, got.Status, got.Event, step.wantStatus, step.wantEvent)
+		}
+	}
+
+	health, ok := m.Get("conn-1")
+	if !ok || health.LatencyMS != 3000 || health.AvgLatencyMS != 765 || health.Failures != 0 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+
+	want := []string{HealthDegraded, HealthDown, HealthRecovered, HealthDegraded}
+	if got := emitter.eventNames(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestHealthMonitor_CheckAll(t *testing.T) {
+	state := core.NewAppState()
+	state.DisableEvents = true
+	m := NewHealthMonitor(state)
+
+	var mu sync.Mutex
+	pinged := 0
+	m.ping = func(ctx context.Context, client *mongo.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+		pinged++
+		return errors.New("server selection timeout")
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	state.SetClient("conn-1", client)
+	m.record("gone", time.Millisecond, nil)
+
+	m.CheckAll()
+	m.CheckAll()
+
+	if pinged != 2 {
+		t.Errorf("pinged %d times, want 2", pinged)
+	}
+	health, ok := m.Get("conn-1")
+	if !ok || health.Status != HealthDown || health.Error != "server selection timeout" {
+		t.Errorf("unexpected health: %+v", health)
+	}
+	if _, ok := m.Get("gone"); ok {
+		t.Error("health of a disconnected connection should be forgotten")
+	}
+
+	state.RemoveClient("conn-1")
+	m.CheckAll()
+	if _, ok := m.Get("conn-1"); ok {
+		t.Error("health should be forgotten after disconnect")
+	}
+}
+
+func TestHealthMonitor_StartStop(t *testing.T) {
+	m := NewHealthMonitor(core.NewAppState())
+	m.interval = time.Millisecond
+	m.Start()
+	m.Start() // No second loop
+	time.Sleep(5 * time.Millisecond)
+	m.Stop()
+	m.Stop()
+}
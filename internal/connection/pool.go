@@ -0,0 +1,110 @@
+package connection
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// poolMonitor counts the driver's connection pool events of one client.
+type poolMonitor struct {
+	mu      sync.Mutex
+	since   time.Time
+	servers map[string]*serverPool
+}
+
+// serverPool is the pool event history of one server.
+type serverPool struct {
+	stats         types.ServerPoolStats
+	checkoutTotal time.Duration
+	checkoutMax   time.Duration
+}
+
+func newPoolMonitor() *poolMonitor {
+	return &poolMonitor{since: time.Now(), servers: make(map[string]*serverPool)}
+}
+
+// monitor returns the driver pool monitor that feeds m.
+func (m *poolMonitor) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: m.handle}
+}
+
+func (m *poolMonitor) handle(e *event.PoolEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.servers[e.Address]
+	if !ok {
+		p = &serverPool{stats: types.ServerPoolStats{Address: e.Address}}
+		m.servers[e.Address] = p
+	}
+	s := &p.stats
+	switch e.Type {
+	case event.PoolCreated:
+		if e.PoolOptions != nil {
+			s.MaxPoolSize = e.PoolOptions.MaxPoolSize
+		}
+	case event.PoolCleared:
+		s.Cleared++
+	case event.ConnectionCreated:
+		s.TotalCreated++
+		s.Open++
+	case event.ConnectionClosed:
+		s.TotalClosed++
+		s.Open = max(s.Open-1, 0)
+	case event.GetStarted:
+		s.WaitQueue++
+	case event.GetSucceeded:
+		s.WaitQueue = max(s.WaitQueue-1, 0)
+		s.CheckedOut++
+		s.Checkouts++
+		p.checkoutTotal += e.Duration
+		p.checkoutMax = max(p.checkoutMax, e.Duration)
+	case event.GetFailed:
+		s.WaitQueue = max(s.WaitQueue-1, 0)
+		s.CheckoutFailures++
+	case event.ConnectionReturned:
+		s.CheckedOut = max(s.CheckedOut-1, 0)
+	}
+}
+
+// snapshot returns the current statistics, per server and summed.
+func (m *poolMonitor) snapshot(connID string) *types.PoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &types.PoolStats{ConnectionID: connID, Since: m.since, Servers: []types.ServerPoolStats{}}
+	var checkoutTotal, checkoutMax time.Duration
+	for _, p := range m.servers {
+		s := p.stats
+		if s.Checkouts > 0 {
+			s.AvgCheckoutMS = durationMS(p.checkoutTotal / time.Duration(s.Checkouts))
+		}
+		s.MaxCheckoutMS = durationMS(p.checkoutMax)
+		result.Servers = append(result.Servers, s)
+
+		t := &result.Total
+		t.CheckedOut += s.CheckedOut
+		t.WaitQueue += s.WaitQueue
+		t.Open += s.Open
+		t.TotalCreated += s.TotalCreated
+		t.TotalClosed += s.TotalClosed
+		t.Checkouts += s.Checkouts
+		t.CheckoutFailures += s.CheckoutFailures
+		t.Cleared += s.Cleared
+		checkoutTotal += p.checkoutTotal
+		checkoutMax = max(checkoutMax, p.checkoutMax)
+	}
+	if result.Total.Checkouts > 0 {
+		result.Total.AvgCheckoutMS = durationMS(checkoutTotal / time.Duration(result.Total.Checkouts))
+	}
+	result.Total.MaxCheckoutMS = durationMS(checkoutMax)
+	sort.Slice(result.Servers, func(i, j int) bool {
+		return result.Servers[i].Address < result.Servers[j].Address
+	})
+	return result
+}
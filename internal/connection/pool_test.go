@@ -0,0 +1,68 @@
+package connection
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestPoolMonitor_Snapshot(t *testing.T) {
+	m := newPoolMonitor()
+	events := []event.PoolEvent{
+		{Type: event.PoolCreated, Address: "db2:27017", PoolOptions: &event.MonitorPoolOptions{MaxPoolSize: 100}},
+		{Type: event.PoolCreated, Address: "db1:27017", PoolOptions: &event.MonitorPoolOptions{MaxPoolSize: 100}},
+		{Type: event.ConnectionCreated, Address: "db1:27017"},
+		{Type: event.ConnectionCreated, Address: "db1:27017"},
+		{Type: event.ConnectionCreated, Address: "db2:27017"},
+		{Type: event.GetStarted, Address: "db1:27017"},
+		{Type: event.GetStarted, Address: "db1:27017"},
+		{Type: event.GetStarted, Address: "db1:27017"},
+		{Type: event.GetSucceeded, Address: "db1:27017", Duration: 2 * time.Millisecond},
+		{Type: event.GetSucceeded, Address: "db1:27017", Duration: 6 * time.Millisecond},
+		{Type: event.ConnectionReturned, Address: "db1:27017"},
+		{Type: event.GetStarted, Address: "db2:27017"},
+		{Type: event.GetFailed, Address: "db2:27017", Duration: time.Second},
+		{Type: event.PoolCleared, Address: "db2:27017"},
+		{Type: event.ConnectionClosed, Address: "db2:27017"},
+	}
+	for i := range events {
+		m.handle(&events[i])
+	}
+
+	stats := m.snapshot("conn-1")
+	if stats.ConnectionID != "conn-1" || len(stats.Servers) != 2 || stats.Servers[0].Address != "db1:27017" {
+		t.Fatalf("unexpected servers: %+v", stats.Servers)
+	}
+
+	db1 := stats.Servers[0]
+	if db1.MaxPoolSize != 100 || db1.CheckedOut != 1 || db1.WaitQueue != 1 || db1.Open != 2 || db1.Checkouts != 2 {
+		t.Errorf("unexpected db1 pool: %+v", db1)
+	}
+	if db1.AvgCheckoutMS != 4 || db1.MaxCheckoutMS != 6 {
+		t.Errorf("checkout latency = avg %v max %v, want 4 and 6", db1.AvgCheckoutMS, db1.MaxCheckoutMS)
+	}
+
+	db2 := stats.Servers[1]
+	if db2.WaitQueue != 0 || db2.CheckoutFailures != 1 || db2.Cleared != 1 || db2.Open != 0 || db2.TotalClosed != 1 {
+		t.Errorf("unexpected db2 pool: %+v", db2)
+	}
+
+	total := stats.Total
+	if total.TotalCreated != 3 || total.CheckedOut != 1 || total.WaitQueue != 1 || total.Checkouts != 2 || total.AvgCheckoutMS != 4 {
+		t.Errorf("unexpected total: %+v", total)
+	}
+}
+
+func TestGetPoolStats_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState(), nil)
+
+	_, err := svc.GetPoolStats("conn-1")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package connection
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+const (
+	// ReplicationLagWarning is the lag above which a member is reported as lagging.
+	ReplicationLagWarning = 10 * time.Second
+	// ReplicationLagHistorySize is how many lag samples are kept per connection.
+	ReplicationLagHistorySize = 60
+)
+
+// GetReplicationLag samples how far each member of a connection's replica set is behind the
+// primary, from the member optimes of replSetGetStatus. Members behind by more than
+// ReplicationLagWarning are flagged and reported in a "replication:lag-warning" event. The
+// sample is added to the connection's lag history.
+func (s *Service) GetReplicationLag(connID string) (*types.ReplicationLag, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	var status bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to get replica set status: %w", err)
+	}
+
+	lag := BuildReplicationLag(connID, status, time.Now())
+	s.recordReplicationLag(lag)
+
+	var lagging []types.MemberLag
+	for _, m := range lag.Members {
+		if m.Lagging {
+			lagging = append(lagging, m)
+		}
+	}
+	if len(lagging) > 0 {
+		debug.LogConnection("Replication lag above threshold", map[string]interface{}{
+			"connectionId":  connID,
+			"maxLagSeconds": lag.MaxLagSeconds,
+		})
+		s.state.EmitConnectionEvent(connID, "replication:lag-warning", map[string]interface{}{
+			"connectionId":     connID,
+			"thresholdSeconds": ReplicationLagWarning.Seconds(),
+			"members":          lagging,
+		})
+	}
+	return lag, nil
+}
+
+// GetReplicationLagHistory returns the lag samples GetReplicationLag took on a connection,
+// oldest first.
+func (s *Service) GetReplicationLagHistory(connID string) []types.ReplicationLag {
+	s.lagMu.Lock()
+	defer s.lagMu.Unlock()
+	return append([]types.ReplicationLag{}, s.lagHistory[connID]...)
+}
+
+// recordReplicationLag adds a sample to its connection's lag history, dropping the oldest
+// beyond ReplicationLagHistorySize.
+func (s *Service) recordReplicationLag(lag *types.ReplicationLag) {
+	s.lagMu.Lock()
+	defer s.lagMu.Unlock()
+	h := append(s.lagHistory[lag.ConnectionID], *lag)
+	if len(h) > ReplicationLagHistorySize {
+		h = h[len(h)-ReplicationLagHistorySize:]
+	}
+	s.lagHistory[lag.ConnectionID] = h
+}
+
+// BuildReplicationLag computes member lag from a replSetGetStatus result. Lag is measured
+// against the primary's optime, or the most recent optime while there is no primary.
+func BuildReplicationLag(connID string, status bson.M, now time.Time) *types.ReplicationLag {
+	lag := &types.ReplicationLag{
+		ConnectionID: connID,
+		Time:         now,
+		SetName:      bsonutil.ToString(status["set"]),
+		Members:      []types.MemberLag{},
+	}
+
+	var newest time.Time
+	var primaryOptime time.Time
+	members, _ := status["members"].(bson.A)
+	for _, m := range members {
+		member, ok := m.(bson.M)
+		if !ok {
+			continue
+		}
+		ml := types.MemberLag{
+			Name:     bsonutil.ToString(member["name"]),
+			StateStr: bsonutil.ToString(member["stateStr"]),
+			Health:   bsonutil.ToInt(member["health"]),
+			Self:     bsonutil.ToBool(member["self"]),
+		}
+		if optime, ok := member["optimeDate"].(primitive.DateTime); ok && optime > 0 {
+			ml.OptimeDate = optime.Time().UTC()
+		}
+		if ml.OptimeDate.After(newest) {
+			newest = ml.OptimeDate
+		}
+		if ml.StateStr == "PRIMARY" {
+			lag.Primary = ml.Name
+			primaryOptime = ml.OptimeDate
+		}
+		lag.Members = append(lag.Members, ml)
+	}
+
+	reference := primaryOptime
+	if reference.IsZero() {
+		reference = newest
+	}
+	for i := range lag.Members {
+		m := &lag.Members[i]
+		if m.OptimeDate.IsZero() || reference.IsZero() {
+			continue
+		}
+		m.LagSeconds = max(reference.Sub(m.OptimeDate).Seconds(), 0)
+		m.Lagging = m.LagSeconds > ReplicationLagWarning.Seconds()
+		lag.MaxLagSeconds = max(lag.MaxLagSeconds, m.LagSeconds)
+	}
+	return lag
+}
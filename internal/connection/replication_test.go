@@ -0,0 +1,72 @@
+package connection
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestBuildReplicationLag(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	optime := func(behind time.Duration) primitive.DateTime {
+		return primitive.NewDateTimeFromTime(now.Add(-behind))
+	}
+	status := bson.M{
+		"set": "rs0",
+		"members": bson.A{
+			bson.M{"name": "a:27017", "stateStr": "PRIMARY", "health": 1.0, "optimeDate": optime(time.Second), "self": true},
+			bson.M{"name": "b:27017", "stateStr": "SECONDARY", "health": 1.0, "optimeDate": optime(3 * time.Second)},
+			bson.M{"name": "c:27017", "stateStr": "SECONDARY", "health": 1.0, "optimeDate": optime(31 * time.Second)},
+			bson.M{"name": "d:27017", "stateStr": "ARBITER", "health": 1.0},
+		},
+	}
+
+	lag := BuildReplicationLag("conn-1", status, now)
+	if lag.SetName != "rs0" || lag.Primary != "a:27017" || len(lag.Members) != 4 {
+		t.Fatalf("unexpected lag: %+v", lag)
+	}
+	want := []struct {
+		lag     float64
+		lagging bool
+	}{{0, false}, {2, false}, {30, true}, {0, false}}
+	for i, w := range want {
+		if m := lag.Members[i]; m.LagSeconds != w.lag || m.Lagging != w.lagging {
+			t.Errorf("member %s: lag %v lagging %v, want %v %v", m.Name, m.LagSeconds, m.Lagging, w.lag, w.lagging)
+		}
+	}
+	if lag.MaxLagSeconds != 30 {
+		t.Errorf("MaxLagSeconds = %v, want 30", lag.MaxLagSeconds)
+	}
+
+	// Without a primary, lag is measured against the most recent optime
+	status["members"].(bson.A)[0].(bson.M)["stateStr"] = "SECONDARY"
+	lag = BuildReplicationLag("conn-1", status, now)
+	if lag.Primary != "" || lag.Members[2].LagSeconds != 30 {
+		t.Errorf("unexpected lag without primary: %+v", lag)
+	}
+}
+
+func TestReplicationLagHistory(t *testing.T) {
+	s := NewService(core.NewAppState(), nil)
+	for i := 0; i < ReplicationLagHistorySize+3; i++ {
+		s.recordReplicationLag(&types.ReplicationLag{ConnectionID: "conn-1", MaxLagSeconds: float64(i)})
+	}
+	history := s.GetReplicationLagHistory("conn-1")
+	if len(history) != ReplicationLagHistorySize || history[0].MaxLagSeconds != 3 {
+		t.Errorf("history has %d samples starting at %v", len(history), history[0].MaxLagSeconds)
+	}
+	if len(s.GetReplicationLagHistory("conn-2")) != 0 {
+		t.Error("expected no history for another connection")
+	}
+
+	var notConnected *core.NotConnectedError
+	if _, err := s.GetReplicationLag("conn-1"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
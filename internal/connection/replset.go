@@ -0,0 +1,316 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Replica set limits the server enforces, checked before reconfiguring.
+const (
+	maxReplSetMembers       = 50
+	maxReplSetVotingMembers = 7
+	maxMemberPriority       = 1000
+)
+
+// GetReplSetConfig returns the replica set configuration of a connection's deployment.
+func (s *Service) GetReplSetConfig(connID string) (*types.ReplSetConfig, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cfg, err := readReplSetConfig(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	config := &types.ReplSetConfig{
+		SetName: bsonutil.ToString(docValue(cfg, "_id")),
+		Version: bsonutil.ToInt64(docValue(cfg, "version")),
+		Term:    bsonutil.ToInt64(docValue(cfg, "term")),
+		Members: parseMemberConfigs(docValue(cfg, "members")),
+	}
+	return config, nil
+}
+
+// AddReplSetMember adds a member to the replica set, with the next free member ID.
+func (s *Service) AddReplSetMember(connID string, member types.ReplSetMemberConfig) error {
+	member.Host = strings.TrimSpace(member.Host)
+	if member.Host == "" {
+		return fmt.Errorf("member host cannot be empty")
+	}
+	return s.reconfigReplSet(connID, "add replica set member", func(_ context.Context, _ *mongo.Client, members bson.A) (bson.A, error) {
+		nextID := 0
+		for _, m := range parseMemberConfigs(members) {
+			if strings.EqualFold(m.Host, member.Host) {
+				return nil, fmt.Errorf("%s is already a member", member.Host)
+			}
+			nextID = max(nextID, m.ID+1)
+		}
+		member.ID = nextID
+		return append(members, memberDocument(member)), nil
+	})
+}
+
+// RemoveReplSetMember removes a member from the replica set. The primary cannot be removed;
+// step it down first. On connections with safety settings it needs a confirmed
+// "remove replica set member" token for the host.
+func (s *Service) RemoveReplSetMember(connID, host string) error {
+	if host == "" {
+		return fmt.Errorf("member host cannot be empty")
+	}
+	return s.reconfigReplSet(connID, "remove replica set member", func(ctx context.Context, client *mongo.Client, members bson.A) (bson.A, error) {
+		var hello struct {
+			Primary string `bson:"primary"`
+		}
+		if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+			return nil, fmt.Errorf("failed to find the primary: %w", err)
+		}
+		if strings.EqualFold(hello.Primary, host) {
+			return nil, fmt.Errorf("%s is the primary and cannot be removed", host)
+		}
+
+		kept := bson.A{}
+		for _, m := range members {
+			if d, ok := m.(bson.D); ok && strings.EqualFold(bsonutil.ToString(docValue(d, "host")), host) {
+				continue
+			}
+			kept = append(kept, m)
+		}
+		if len(kept) == len(members) {
+			return nil, fmt.Errorf("%s is not a member", host)
+		}
+		if err := s.state.RequireConfirmation(connID, "remove replica set member", host); err != nil {
+			return nil, err
+		}
+		return kept, nil
+	})
+}
+
+// SetMemberPriority changes the election priority of a member. A priority of 0 keeps the
+// member from becoming primary.
+func (s *Service) SetMemberPriority(connID, host string, priority float64) error {
+	if host == "" {
+		return fmt.Errorf("member host cannot be empty")
+	}
+	return s.reconfigReplSet(connID, "set member priority", func(_ context.Context, _ *mongo.Client, members bson.A) (bson.A, error) {
+		for i, m := range members {
+			if d, ok := m.(bson.D); ok && strings.EqualFold(bsonutil.ToString(docValue(d, "host")), host) {
+				members[i] = setDocValue(d, "priority", priority)
+				return members, nil
+			}
+		}
+		return nil, fmt.Errorf("%s is not a member", host)
+	})
+}
+
+// reconfigReplSet applies change to the members of the current replica set configuration,
+// validates the result, and sends it with replSetReconfig under the next config version.
+func (s *Service) reconfigReplSet(connID, operation string, change func(ctx context.Context, client *mongo.Client, members bson.A) (bson.A, error)) error {
+	if err := s.state.CheckWritable(connID, operation); err != nil {
+		return err
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cfg, err := readReplSetConfig(ctx, client)
+	if err != nil {
+		return err
+	}
+	members, _ := docValue(cfg, "members").(bson.A)
+	members, err = change(ctx, client, members)
+	if err != nil {
+		return err
+	}
+	if err := validateReplSetMembers(parseMemberConfigs(members)); err != nil {
+		return err
+	}
+
+	version := bsonutil.ToInt64(docValue(cfg, "version")) + 1
+	cfg = setDocValue(cfg, "members", members)
+	cfg = setDocValue(cfg, "version", int32(version))
+	// The server sets the term of a new config itself
+	cfg = deleteDocValue(cfg, "term")
+
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetReconfig", Value: cfg}}).Err(); err != nil {
+		return fmt.Errorf("failed to %s: %w", operation, err)
+	}
+
+	debug.LogConnection("Replica set reconfigured", map[string]interface{}{
+		"connectionId": connID,
+		"operation":    operation,
+		"version":      version,
+	})
+	return nil
+}
+
+// readReplSetConfig reads the replica set configuration, keeping every field in order so it
+// can be sent back.
+func readReplSetConfig(ctx context.Context, client *mongo.Client) (bson.D, error) {
+	var result struct {
+		Config bson.D `bson:"config"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetConfig", Value: 1}}).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to get replica set config: %w", err)
+	}
+	return result.Config, nil
+}
+
+// validateReplSetMembers checks a member list against the server's replica set rules, so a
+// bad change fails with a clear message before reaching the server.
+func validateReplSetMembers(members []types.ReplSetMemberConfig) error {
+	if len(members) > maxReplSetMembers {
+		return fmt.Errorf("a replica set can have at most %d members", maxReplSetMembers)
+	}
+	hosts := make(map[string]bool, len(members))
+	voting, electable := 0, 0
+	for _, m := range members {
+		host := strings.ToLower(m.Host)
+		if hosts[host] {
+			return fmt.Errorf("%s is listed more than once", m.Host)
+		}
+		hosts[host] = true
+
+		if m.Priority < 0 || m.Priority > maxMemberPriority {
+			return fmt.Errorf("priority of %s must be between 0 and %d", m.Host, maxMemberPriority)
+		}
+		if m.Votes != 0 && m.Votes != 1 {
+			return fmt.Errorf("votes of %s must be 0 or 1", m.Host)
+		}
+		if m.Priority > 0 {
+			switch {
+			case m.ArbiterOnly:
+				return fmt.Errorf("arbiter %s must have priority 0", m.Host)
+			case m.Hidden:
+				return fmt.Errorf("hidden member %s must have priority 0", m.Host)
+			case m.SecondaryDelaySecs > 0:
+				return fmt.Errorf("delayed member %s must have priority 0", m.Host)
+			case m.Votes == 0:
+				return fmt.Errorf("non-voting member %s must have priority 0", m.Host)
+			}
+			electable++
+		}
+		voting += m.Votes
+	}
+	if voting > maxReplSetVotingMembers {
+		return fmt.Errorf("a replica set can have at most %d voting members", maxReplSetVotingMembers)
+	}
+	if electable == 0 {
+		return fmt.Errorf("at least one member must be able to become primary (priority above 0)")
+	}
+	return nil
+}
+
+// parseMemberConfigs converts the members array of a replica set config.
+func parseMemberConfigs(v interface{}) []types.ReplSetMemberConfig {
+	members := []types.ReplSetMemberConfig{}
+	arr, _ := v.(bson.A)
+	for _, item := range arr {
+		d, ok := item.(bson.D)
+		if !ok {
+			continue
+		}
+		m := types.ReplSetMemberConfig{
+			ID:                 bsonutil.ToInt(docValue(d, "_id")),
+			Host:               bsonutil.ToString(docValue(d, "host")),
+			Priority:           1,
+			Votes:              1,
+			ArbiterOnly:        bsonutil.ToBool(docValue(d, "arbiterOnly")),
+			Hidden:             bsonutil.ToBool(docValue(d, "hidden")),
+			SecondaryDelaySecs: bsonutil.ToInt64(docValue(d, "secondaryDelaySecs")),
+		}
+		if p := docValue(d, "priority"); p != nil {
+			m.Priority = bsonutil.ToFloat64(p)
+		}
+		if v := docValue(d, "votes"); v != nil {
+			m.Votes = bsonutil.ToInt(v)
+		}
+		if delay := docValue(d, "slaveDelay"); delay != nil && m.SecondaryDelaySecs == 0 {
+			m.SecondaryDelaySecs = bsonutil.ToInt64(delay) // Before 5.0
+		}
+		if tags, ok := docValue(d, "tags").(bson.D); ok && len(tags) > 0 {
+			m.Tags = make(map[string]string, len(tags))
+			for _, t := range tags {
+				m.Tags[t.Key] = bsonutil.ToString(t.Value)
+			}
+		}
+		members = append(members, m)
+	}
+	return members
+}
+
+// memberDocument converts a member configuration for replSetReconfig. The member delay is
+// left out unless set, since its field name depends on the server version.
+func memberDocument(m types.ReplSetMemberConfig) bson.D {
+	d := bson.D{
+		{Key: "_id", Value: m.ID},
+		{Key: "host", Value: m.Host},
+		{Key: "priority", Value: m.Priority},
+		{Key: "votes", Value: m.Votes},
+	}
+	if m.ArbiterOnly {
+		d = append(d, bson.E{Key: "arbiterOnly", Value: true})
+	}
+	if m.Hidden {
+		d = append(d, bson.E{Key: "hidden", Value: true})
+	}
+	if m.SecondaryDelaySecs > 0 {
+		d = append(d, bson.E{Key: "secondaryDelaySecs", Value: m.SecondaryDelaySecs})
+	}
+	if len(m.Tags) > 0 {
+		tags := bson.D{}
+		for k, v := range m.Tags {
+			tags = append(tags, bson.E{Key: k, Value: v})
+		}
+		d = append(d, bson.E{Key: "tags", Value: tags})
+	}
+	return d
+}
+
+// docValue returns the value of key in d, or nil.
+func docValue(d bson.D, key string) interface{} {
+	for _, e := range d {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// setDocValue sets key in d, appending it if missing.
+func setDocValue(d bson.D, key string, value interface{}) bson.D {
+	for i, e := range d {
+		if e.Key == key {
+			d[i].Value = value
+			return d
+		}
+	}
+	return append(d, bson.E{Key: key, Value: value})
+}
+
+// deleteDocValue removes key from d.
+func deleteDocValue(d bson.D, key string) bson.D {
+	out := d[:0]
+	for _, e := range d {
+		if e.Key != key {
+			out = append(out, e)
+		}
+	}
+	return out
+}
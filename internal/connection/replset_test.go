@@ -0,0 +1,110 @@
+package connection
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestValidateReplSetMembers(t *testing.T) {
+	member := func(host string, priority float64, votes int) types.ReplSetMemberConfig {
+		return types.ReplSetMemberConfig{Host: host, Priority: priority, Votes: votes}
+	}
+	tests := []struct {
+		name    string
+		members []types.ReplSetMemberConfig
+		errMsg  string
+	}{
+		{"valid", []types.ReplSetMemberConfig{member("a:1", 2, 1), member("b:1", 1, 1), {Host: "c:1", ArbiterOnly: true, Votes: 1}}, ""},
+		{"duplicate host", []types.ReplSetMemberConfig{member("a:1", 1, 1), member("A:1", 1, 1)}, "more than once"},
+		{"priority range", []types.ReplSetMemberConfig{member("a:1", 1001, 1)}, "between 0 and"},
+		{"votes range", []types.ReplSetMemberConfig{member("a:1", 1, 2)}, "0 or 1"},
+		{"arbiter priority", []types.ReplSetMemberConfig{member("a:1", 1, 1), {Host: "b:1", ArbiterOnly: true, Priority: 1, Votes: 1}}, "arbiter"},
+		{"hidden priority", []types.ReplSetMemberConfig{member("a:1", 1, 1), {Host: "b:1", Hidden: true, Priority: 1, Votes: 1}}, "hidden"},
+		{"non-voting priority", []types.ReplSetMemberConfig{member("a:1", 1, 0)}, "non-voting"},
+		{"no electable", []types.ReplSetMemberConfig{member("a:1", 0, 1)}, "become primary"},
+		{"too many voters", []types.ReplSetMemberConfig{
+			member("a:1", 1, 1), member("b:1", 1, 1), member("c:1", 1, 1), member("d:1", 1, 1),
+			member("e:1", 1, 1), member("f:1", 1, 1), member("g:1", 1, 1), member("h:1", 1, 1),
+		}, "voting members"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReplSetMembers(tt.members)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestParseMemberConfigs(t *testing.T) {
+	members := parseMemberConfigs(bson.A{
+		bson.D{{Key: "_id", Value: int32(0)}, {Key: "host", Value: "a:27017"}, {Key: "priority", Value: 2.0}, {Key: "votes", Value: int32(1)}},
+		bson.D{{Key: "_id", Value: int32(1)}, {Key: "host", Value: "b:27017"}, {Key: "priority", Value: 0.0}, {Key: "votes", Value: int32(1)},
+			{Key: "hidden", Value: true}, {Key: "slaveDelay", Value: int64(3600)}, {Key: "tags", Value: bson.D{{Key: "dc", Value: "east"}}}},
+		bson.D{{Key: "_id", Value: int32(2)}, {Key: "host", Value: "c:27017"}},
+	})
+	if len(members) != 3 || members[0].Priority != 2 || members[0].Votes != 1 {
+		t.Fatalf("unexpected members: %+v", members)
+	}
+	if b := members[1]; !b.Hidden || b.SecondaryDelaySecs != 3600 || b.Tags["dc"] != "east" || b.Priority != 0 {
+		t.Errorf("unexpected hidden member: %+v", b)
+	}
+	if c := members[2]; c.Priority != 1 || c.Votes != 1 {
+		t.Errorf("missing fields should default to priority 1 and one vote: %+v", c)
+	}
+
+	doc := memberDocument(types.ReplSetMemberConfig{ID: 3, Host: "d:27017", Priority: 0, Votes: 1, ArbiterOnly: true})
+	if docValue(doc, "arbiterOnly") != true || docValue(doc, "hidden") != nil || docValue(doc, "_id") != 3 {
+		t.Errorf("unexpected member document: %v", doc)
+	}
+}
+
+func TestDocHelpers(t *testing.T) {
+	d := bson.D{{Key: "_id", Value: "rs0"}, {Key: "version", Value: int32(4)}, {Key: "term", Value: int64(2)}}
+	d = setDocValue(d, "version", int32(5))
+	d = setDocValue(d, "settings", bson.D{})
+	d = deleteDocValue(d, "term")
+	want := []string{"_id", "version", "settings"}
+	if len(d) != len(want) || docValue(d, "version") != int32(5) {
+		t.Fatalf("unexpected document: %v", d)
+	}
+	for i, k := range want {
+		if d[i].Key != k {
+			t.Errorf("key %d = %s, want %s", i, d[i].Key, k)
+		}
+	}
+}
+
+func TestReplSetReconfig_Validation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	s := NewService(state, nil)
+
+	if err := s.AddReplSetMember("conn-1", types.ReplSetMemberConfig{Host: " "}); err == nil || !strings.Contains(err.Error(), "host") {
+		t.Errorf("expected host error, got %v", err)
+	}
+	var readOnly *core.ReadOnlyError
+	if err := s.SetMemberPriority("ro", "a:27017", 2); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+	var notConnected *core.NotConnectedError
+	if err := s.RemoveReplSetMember("conn-1", "a:27017"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+	if _, err := s.GetReplSetConfig("conn-1"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
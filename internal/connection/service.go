@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,13 +26,31 @@ import (
 type Service struct {
 	state     *core.AppState
 	connStore *storage.ConnectionService
+	health    *HealthMonitor
+
+	poolsMu sync.Mutex
+	pools   map[string]*poolMonitor // Pool event counters of connected clients
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*sessionTracker // Server sessions used by connected clients
+
+	lagMu      sync.Mutex
+	lagHistory map[string][]types.ReplicationLag // Recent replication lag samples by connection
+
+	autoMu     sync.Mutex
+	autoStatus map[string]types.AutoConnectStatus // Latest auto-connect status by connection
 }
 
 // NewService creates a new connection service.
 func NewService(state *core.AppState, connStore *storage.ConnectionService) *Service {
 	return &Service{
-		state:     state,
-		connStore: connStore,
+		state:      state,
+		connStore:  connStore,
+		health:     NewHealthMonitor(state),
+		pools:      make(map[string]*poolMonitor),
+		sessions:   make(map[string]*sessionTracker),
+		lagHistory: make(map[string][]types.ReplicationLag),
+		autoStatus: make(map[string]types.AutoConnectStatus),
 	}
 }
 
@@ -60,12 +80,66 @@ func (s *Service) Connect(connID string) error {
 		return err
 	}
 
+	// Connections saved before extended settings existed have none; connect by URI only
+	conn, err := s.connStore.GetExtendedConnection(connID)
+	if err != nil {
+		conn = types.ExtendedConnection{}
+	}
+
+	// Route through the SSH tunnel if the connection has one; it lives as long as the client
+	var tunnel *Tunnel
+	if conn.SSHEnabled {
+		tunnel, uri, err = openTunnelForURI(conn, uri)
+		if err != nil {
+			debug.LogConnection("Failed to open SSH tunnel", map[string]interface{}{
+				"connectionId": connID,
+				"error":        err.Error(),
+			})
+			return fmt.Errorf("failed to open SSH tunnel: %w", err)
+		}
+		if conn.SSHHostKeyFingerprint == "" {
+			s.pinSSHHostKey(connID, conn.SSHHost, tunnel.HostKeyFingerprint())
+		}
+	}
+	closeTunnel := func() {
+		if tunnel != nil {
+			tunnel.Close()
+		}
+	}
+
 	ctx, cancel := core.ContextWithConnectTimeout()
 	defer cancel()
 
 	clientOpts := options.Client().ApplyURI(uri)
+	if err := applyTLSConfig(clientOpts, conn, tunnel); err != nil {
+		closeTunnel()
+		debug.LogConnection("Invalid TLS settings", map[string]interface{}{
+			"connectionId": connID,
+			"error":        err.Error(),
+		})
+		return fmt.Errorf("invalid TLS settings: %w", err)
+	}
+	if err := validateAuthSettings(clientOpts); err != nil {
+		closeTunnel()
+		debug.LogConnection("Invalid authentication settings", map[string]interface{}{
+			"connectionId": connID,
+			"error":        err.Error(),
+		})
+		return err
+	}
+	for _, warning := range authWarnings(clientOpts) {
+		debug.LogConnection("Connection warning", map[string]interface{}{
+			"connectionId": connID,
+			"warning":      warning,
+		})
+	}
+	pool := newPoolMonitor()
+	clientOpts.SetPoolMonitor(pool.monitor())
+	sessions := newSessionTracker()
+	clientOpts.SetMonitor(sessions.monitor())
 	client, err := mongo.Connect(ctx, clientOpts)
 	if err != nil {
+		closeTunnel()
 		debug.LogConnection("Failed to connect", map[string]interface{}{
 			"connectionId": connID,
 			"error":        err.Error(),
@@ -77,6 +151,7 @@ func (s *Service) Connect(connID string) error {
 	// Ping to verify connection
 	if err := client.Ping(ctx, nil); err != nil {
 		client.Disconnect(context.Background())
+		closeTunnel()
 		debug.LogConnection("Failed to ping", map[string]interface{}{
 			"connectionId": connID,
 			"error":        err.Error(),
@@ -85,7 +160,19 @@ func (s *Service) Connect(connID string) error {
 		return fmt.Errorf("failed to ping: %w", err)
 	}
 
+	// Replace the tunnel of a previous client, if any, before the client itself
+	var registered io.Closer
+	if tunnel != nil {
+		registered = tunnel
+	}
+	s.state.SetTunnel(connID, registered)
 	s.state.SetClient(connID, client)
+	s.poolsMu.Lock()
+	s.pools[connID] = pool
+	s.poolsMu.Unlock()
+	s.sessionsMu.Lock()
+	s.sessions[connID] = sessions
+	s.sessionsMu.Unlock()
 
 	// Update last accessed time (ignore error - non-critical)
 	_ = s.connStore.UpdateLastAccessed(connID)
@@ -103,7 +190,29 @@ func (s *Service) Disconnect(connID string) error {
 	debug.LogConnection("Disconnecting", map[string]interface{}{
 		"connectionId": connID,
 	})
+
+	// End the app's server sessions so abandoned cursors and operations don't linger
+	if client, err := s.state.GetClient(connID); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), disconnectKillTimeout)
+		if _, err := s.killSessions(ctx, connID, client); err != nil {
+			debug.LogConnection("Failed to kill sessions on disconnect", map[string]interface{}{
+				"connectionId": connID,
+				"error":        err.Error(),
+			})
+		}
+		cancel()
+	}
+
 	s.state.RemoveClient(connID)
+	s.poolsMu.Lock()
+	delete(s.pools, connID)
+	s.poolsMu.Unlock()
+	s.sessionsMu.Lock()
+	delete(s.sessions, connID)
+	s.sessionsMu.Unlock()
+	s.lagMu.Lock()
+	delete(s.lagHistory, connID)
+	s.lagMu.Unlock()
 	debug.LogConnection("Disconnected", map[string]interface{}{
 		"connectionId": connID,
 	})
@@ -121,6 +230,13 @@ func (s *Service) DisconnectAll() error {
 
 // TestConnection tests a MongoDB URI and returns detailed server information.
 func (s *Service) TestConnection(uri string) (*types.TestConnectionResult, error) {
+	return s.TestConnectionWithSettings(uri, types.ExtendedConnection{})
+}
+
+// TestConnectionWithSettings tests a MongoDB URI with the TLS and SSH tunnel settings of
+// conn (CA and client certificates, insecure mode), which may not be saved yet. X.509
+// authentication uses the client certificate of these settings.
+func (s *Service) TestConnectionWithSettings(uri string, conn types.ExtendedConnection) (*types.TestConnectionResult, error) {
 	start := time.Now()
 	result := &types.TestConnectionResult{}
 
@@ -152,10 +268,39 @@ func (s *Service) TestConnection(uri string) (*types.TestConnectionResult, error
 	// Strip vendor-specific params (mongopal.*, 3t.*) before passing to driver
 	uri = credential.StripVendorParams(uri)
 
+	// Test through the SSH tunnel the connection will use; it closes once the test is done
+	var tunnel *Tunnel
+	if conn.SSHEnabled {
+		var err error
+		tunnel, uri, err = openTunnelForURI(conn, uri)
+		if err != nil {
+			debug.LogConnection("Test connection failed to open SSH tunnel", map[string]interface{}{
+				"uri":   maskedURI,
+				"error": err.Error(),
+			})
+			result.Error = fmt.Sprintf("Failed to open SSH tunnel: %s", err.Error())
+			result.Hint = "Check the SSH host, port, user and key or password"
+			result.Latency = time.Since(start).Milliseconds()
+			return result, nil
+		}
+		defer tunnel.Close()
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
 	clientOpts := options.Client().ApplyURI(uri)
+	if err := applyTLSConfig(clientOpts, conn, tunnel); err != nil {
+		result.Error = fmt.Sprintf("Invalid TLS settings: %s", err.Error())
+		result.Hint = "Paste the PEM contents (-----BEGIN ...-----) of the certificates and key, and enter the key password if the key is encrypted"
+		return result, nil
+	}
+	if err := validateAuthSettings(clientOpts); err != nil {
+		result.Error = err.Error()
+		result.Hint = "Check the authentication mechanism against the TLS settings"
+		return result, nil
+	}
+	result.Warnings = authWarnings(clientOpts)
 	client, err := mongo.Connect(ctx, clientOpts)
 	if err != nil {
 		debug.LogConnection("Test connection failed", map[string]interface{}{
@@ -186,7 +331,7 @@ func (s *Service) TestConnection(uri string) (*types.TestConnectionResult, error
 	result.Success = true
 
 	// Detect TLS from URI
-	result.TLSEnabled = strings.Contains(uri, "tls=true") || strings.Contains(uri, "ssl=true") || strings.HasPrefix(uri, "mongodb+srv://")
+	result.TLSEnabled = conn.TLSEnabled || strings.Contains(uri, "tls=true") || strings.Contains(uri, "ssl=true") || strings.HasPrefix(uri, "mongodb+srv://")
 
 	// Get server info via buildInfo command
 	var buildInfo bson.M
@@ -225,8 +370,22 @@ func connectionErrorHint(err error) string {
 	switch {
 	case strings.Contains(msg, "connection refused"):
 		return "Check that MongoDB is running and the host/port are correct"
+	case strings.Contains(msg, "GSSAPI"):
+		return "Kerberos authentication failed. Check that you have a valid ticket (kinit), the user principal, and the service name"
+	case strings.Contains(msg, "\"PLAIN\""):
+		return "LDAP authentication failed. Verify your LDAP username and password, and that the server has LDAP authentication enabled"
 	case strings.Contains(msg, "authentication failed"):
 		return "Verify your username, password, and authentication database"
+	case strings.Contains(msg, "certificate signed by unknown authority"):
+		return "The server certificate is not trusted. Add the CA certificate that signed it in the TLS settings, or allow invalid certificates for testing"
+	case strings.Contains(msg, "certificate is valid for") || strings.Contains(msg, "doesn't contain any IP SANs"):
+		return "The server certificate does not match the hostname. Connect using a hostname listed in the certificate, or allow invalid certificates for testing"
+	case strings.Contains(msg, "certificate has expired") || strings.Contains(msg, "not yet valid"):
+		return "The server certificate has expired or is not yet valid. Check the server certificate and your system clock"
+	case strings.Contains(msg, "bad certificate") || strings.Contains(msg, "certificate required") || strings.Contains(msg, "unknown certificate authority"):
+		return "The server rejected the client certificate. Use a client certificate and key signed by a CA the server trusts"
+	case strings.Contains(msg, "first record does not look like a TLS handshake"):
+		return "The server does not use TLS. Disable TLS for this connection"
 	case strings.Contains(msg, "tls") || strings.Contains(msg, "certificate"):
 		return "Check your TLS/SSL certificate configuration"
 	case strings.Contains(msg, "timeout") || strings.Contains(msg, "context deadline"):
@@ -259,6 +418,46 @@ func (s *Service) GetConnectionStatus(connID string) types.ConnectionStatus {
 	return types.ConnectionStatus{Connected: true}
 }
 
+// StartHealthMonitor starts pinging connected clients in the background, emitting
+// "connection:health" events when their health changes.
+func (s *Service) StartHealthMonitor() {
+	s.health.Start()
+}
+
+// GetConnectionHealth returns the latest health check of a connection, pinging it now if
+// the monitor has not checked it yet.
+func (s *Service) GetConnectionHealth(connID string) (*types.ConnectionHealth, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+	if health, ok := s.health.Get(connID); ok {
+		return &health, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.health.timeout)
+	defer cancel()
+	start := time.Now()
+	pingErr := s.health.ping(ctx, client)
+	health := s.health.record(connID, time.Since(start), pingErr)
+	return &health, nil
+}
+
+// GetPoolStats returns the connection pool statistics of a connected client: connections
+// checked out, operations waiting for one, checkout latency, and connections created.
+func (s *Service) GetPoolStats(connID string) (*types.PoolStats, error) {
+	if _, err := s.state.GetClient(connID); err != nil {
+		return nil, err
+	}
+	s.poolsMu.Lock()
+	pool, ok := s.pools[connID]
+	s.poolsMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no pool statistics for connection %s", connID)
+	}
+	return pool.snapshot(connID), nil
+}
+
 // GetConnectionInfo returns detailed info about a connection.
 func (s *Service) GetConnectionInfo(connID string) types.ConnectionInfo {
 	client, err := s.state.GetClient(connID)
@@ -266,7 +465,7 @@ func (s *Service) GetConnectionInfo(connID string) types.ConnectionInfo {
 		return types.ConnectionInfo{ID: connID}
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	info := types.ConnectionInfo{ID: connID, Type: "standalone"}
@@ -484,11 +683,33 @@ func marshalBsonToJSON(m bson.M) (string, error) {
 
 // Shutdown closes all connections and cleans up resources.
 func (s *Service) Shutdown(ctx context.Context) {
+	s.health.Stop()
 	clients := s.state.GetAllClients()
 	for id, client := range clients {
 		_ = client.Disconnect(ctx)
 		s.state.Mu.Lock()
 		delete(s.state.Clients, id)
+		if tunnel, ok := s.state.Tunnels[id]; ok {
+			tunnel.Close()
+			delete(s.state.Tunnels, id)
+		}
 		s.state.Mu.Unlock()
 	}
 }
+
+// pinSSHHostKey records the host key fingerprint of a connection's SSH server the first time
+// it connects, so later connects reject a different key, and tells the user which key was pinned.
+func (s *Service) pinSSHHostKey(connID, host, fingerprint string) {
+	if err := s.connStore.SetSSHHostKey(connID, fingerprint); err != nil {
+		debug.LogConnection("Failed to pin SSH host key", map[string]interface{}{
+			"connectionId": connID,
+			"error":        err.Error(),
+		})
+		return
+	}
+	s.state.EmitConnectionEvent(connID, "ssh:host-key-pinned", map[string]interface{}{
+		"connectionId": connID,
+		"host":         host,
+		"fingerprint":  fingerprint,
+	})
+}
@@ -0,0 +1,112 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/debug"
+)
+
+// disconnectKillTimeout bounds killing a client's sessions when it disconnects, so an
+// unreachable server does not hold up the disconnect.
+const disconnectKillTimeout = 5 * time.Second
+
+// sessionTracker records the server sessions (lsids) a client sent commands in, so they can
+// be killed along with their cursors and running operations.
+type sessionTracker struct {
+	mu  sync.Mutex
+	ids map[string]bson.Raw // Session ID documents by their raw bytes
+}
+
+func newSessionTracker() *sessionTracker {
+	return &sessionTracker{ids: make(map[string]bson.Raw)}
+}
+
+// monitor returns the driver command monitor that feeds t.
+func (t *sessionTracker) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{Started: t.started}
+}
+
+func (t *sessionTracker) started(_ context.Context, e *event.CommandStartedEvent) {
+	if e.CommandName == "killSessions" || e.CommandName == "endSessions" {
+		return
+	}
+	lsid, ok := e.Command.Lookup("lsid").DocumentOK()
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, seen := t.ids[string(lsid)]; !seen {
+		// The command buffer is reused by the driver once the event returns
+		t.ids[string(lsid)] = bson.Raw(append([]byte(nil), lsid...))
+	}
+}
+
+// list returns the recorded session IDs.
+func (t *sessionTracker) list() []bson.Raw {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]bson.Raw, 0, len(t.ids))
+	for _, id := range t.ids {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// forget removes session IDs that were killed.
+func (t *sessionTracker) forget(ids []bson.Raw) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range ids {
+		delete(t.ids, string(id))
+	}
+}
+
+// KillSessions ends the server sessions the app used on a connection with killSessions,
+// which also kills their open cursors and running operations. Returns the number of sessions
+// killed.
+func (s *Service) KillSessions(connID string) (int, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+	return s.killSessions(ctx, connID, client)
+}
+
+// killSessions kills the recorded sessions of a connection's client.
+func (s *Service) killSessions(ctx context.Context, connID string, client *mongo.Client) (int, error) {
+	s.sessionsMu.Lock()
+	tracker, ok := s.sessions[connID]
+	s.sessionsMu.Unlock()
+	if !ok {
+		return 0, nil
+	}
+	ids := tracker.list()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	sessions := make(bson.A, 0, len(ids))
+	for _, id := range ids {
+		sessions = append(sessions, id)
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "killSessions", Value: sessions}}).Err(); err != nil {
+		return 0, fmt.Errorf("failed to kill sessions: %w", err)
+	}
+	tracker.forget(ids)
+
+	debug.LogConnection("Killed server sessions", map[string]interface{}{
+		"connectionId": connID,
+		"sessions":     len(ids),
+	})
+	return len(ids), nil
+}
@@ -0,0 +1,49 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestSessionTracker(t *testing.T) {
+	tracker := newSessionTracker()
+	command := func(name string, lsid interface{}) *event.CommandStartedEvent {
+		doc := bson.D{{Key: name, Value: 1}}
+		if lsid != nil {
+			doc = append(doc, bson.E{Key: "lsid", Value: bson.D{{Key: "id", Value: lsid}}})
+		}
+		raw, _ := bson.Marshal(doc)
+		return &event.CommandStartedEvent{CommandName: name, Command: raw}
+	}
+
+	ctx := context.Background()
+	tracker.started(ctx, command("find", "session-a"))
+	tracker.started(ctx, command("getMore", "session-a"))
+	tracker.started(ctx, command("insert", "session-b"))
+	tracker.started(ctx, command("hello", nil))
+	tracker.started(ctx, command("endSessions", "session-c"))
+
+	ids := tracker.list()
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(ids))
+	}
+	tracker.forget(ids[:1])
+	if got := tracker.list(); len(got) != 1 || string(got[0]) != string(ids[1]) {
+		t.Errorf("after forget: %v", got)
+	}
+}
+
+func TestKillSessions_NotConnected(t *testing.T) {
+	s := NewService(core.NewAppState(), nil)
+	_, err := s.KillSessions("missing")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package connection
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/youmark/pkcs8"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// buildTLSConfig builds the TLS configuration of a connection from its stored PEM contents:
+// the CA certificate to trust, the client certificate and key (which may be encrypted with
+// TLSKeyPassword), and whether invalid certificates are allowed. It returns nil when TLS is
+// not enabled in the connection settings, leaving TLS to the URI options.
+func buildTLSConfig(conn types.ExtendedConnection) (*tls.Config, error) {
+	if !conn.TLSEnabled {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: conn.TLSInsecure,
+	}
+
+	if strings.TrimSpace(conn.TLSCAFile) != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(conn.TLSCAFile)) {
+			return nil, fmt.Errorf("invalid CA certificate: no PEM certificates found")
+		}
+		config.RootCAs = pool
+	}
+
+	certPEM := strings.TrimSpace(conn.TLSCertFile)
+	keyPEM := strings.TrimSpace(conn.TLSKeyFile)
+	if keyPEM != "" && certPEM == "" {
+		return nil, fmt.Errorf("a client key requires a client certificate")
+	}
+	if certPEM != "" {
+		// MongoDB tools take the certificate and key in one PEM file; accept that too
+		if keyPEM == "" {
+			keyPEM = certPEM
+		}
+		cert, err := loadClientCertificate(certPEM, keyPEM, conn.TLSKeyPassword)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// loadClientCertificate pairs the certificates of certPEM with the private key of keyPEM,
+// decrypting the key with password if it is encrypted.
+func loadClientCertificate(certPEM, keyPEM, password string) (tls.Certificate, error) {
+	var certs []byte
+	rest := []byte(certPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, pem.EncodeToMemory(block)...)
+		}
+	}
+	if len(certs) == 0 {
+		return tls.Certificate{}, fmt.Errorf("invalid client certificate: no PEM certificates found")
+	}
+
+	key, err := parseClientKey(keyPEM, password)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unsupported client key: %w", err)
+	}
+	keyBlock := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	cert, err := tls.X509KeyPair(certs, keyBlock)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("client certificate and key do not match: %w", err)
+	}
+	return cert, nil
+}
+
+// parseClientKey returns the first private key of keyPEM. Encrypted PKCS#8 keys
+// ("ENCRYPTED PRIVATE KEY") and legacy encrypted PEM keys (Proc-Type: 4,ENCRYPTED) are
+// decrypted with password.
+func parseClientKey(keyPEM, password string) (interface{}, error) {
+	rest := []byte(keyPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("invalid client key: no PEM private key found")
+		}
+		if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			continue
+		}
+
+		encrypted := block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block)
+		if encrypted && password == "" {
+			return nil, fmt.Errorf("client key is encrypted; a key password is required")
+		}
+
+		if block.Type == "ENCRYPTED PRIVATE KEY" {
+			key, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(password))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt client key (wrong key password?): %w", err)
+			}
+			return key, nil
+		}
+
+		der := block.Bytes
+		if encrypted {
+			var err error
+			der, err = x509.DecryptPEMBlock(block, []byte(password))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt client key (wrong key password?): %w", err)
+			}
+		}
+		return parseDERPrivateKey(der)
+	}
+}
+
+// parseDERPrivateKey parses a PKCS#1, PKCS#8, or SEC 1 (EC) private key.
+func parseDERPrivateKey(der []byte) (interface{}, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("invalid client key: unsupported private key format")
+}
+
+// applyTLSConfig sets the TLS configuration of conn on clientOpts. Through an SSH tunnel
+// the driver connects to 127.0.0.1, so the server certificate is checked against the
+// tunneled host instead.
+func applyTLSConfig(clientOpts *options.ClientOptions, conn types.ExtendedConnection, tunnel *Tunnel) error {
+	config, err := buildTLSConfig(conn)
+	if err != nil || config == nil {
+		return err
+	}
+	if tunnel != nil {
+		if host, _, err := net.SplitHostPort(tunnel.remote); err == nil {
+			config.ServerName = host
+		}
+	}
+	clientOpts.SetTLSConfig(config)
+	return nil
+}
@@ -0,0 +1,175 @@
+package connection
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youmark/pkcs8"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// testCertificate is a self-signed certificate with its key in the encodings clients use.
+type testCertificate struct {
+	cert        string
+	key         string // PKCS#8
+	ecKey       string // SEC 1
+	encrypted   string // Encrypted PKCS#8
+	legacyCrypt string // Legacy encrypted PEM
+	otherKey    string // A key that does not match cert
+	keyPassword string
+}
+
+func newTestCertificate(t *testing.T) testCertificate {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mongopal-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkcs8DER, _ := x509.MarshalPKCS8PrivateKey(priv)
+	ecDER, _ := x509.MarshalECPrivateKey(priv)
+	encDER, err := pkcs8.MarshalPrivateKey(priv, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", ecDER, []byte("secret"), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherDER, _ := x509.MarshalPKCS8PrivateKey(other)
+
+	encode := func(typ string, b []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: b}))
+	}
+	return testCertificate{
+		cert:        encode("CERTIFICATE", der),
+		key:         encode("PRIVATE KEY", pkcs8DER),
+		ecKey:       encode("EC PRIVATE KEY", ecDER),
+		encrypted:   encode("ENCRYPTED PRIVATE KEY", encDER),
+		legacyCrypt: string(pem.EncodeToMemory(legacy)),
+		otherKey:    encode("PRIVATE KEY", otherDER),
+		keyPassword: "secret",
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	c := newTestCertificate(t)
+
+	tests := []struct {
+		name     string
+		conn     types.ExtendedConnection
+		errMsg   string
+		wantNil  bool
+		wantCA   bool
+		wantCert bool
+		wantSkip bool
+	}{
+		{"disabled", types.ExtendedConnection{TLSCAFile: c.cert}, "", true, false, false, false},
+		{"system roots", types.ExtendedConnection{TLSEnabled: true}, "", false, false, false, false},
+		{"insecure", types.ExtendedConnection{TLSEnabled: true, TLSInsecure: true}, "", false, false, false, true},
+		{"custom CA", types.ExtendedConnection{TLSEnabled: true, TLSCAFile: c.cert}, "", false, true, false, false},
+		{"invalid CA", types.ExtendedConnection{TLSEnabled: true, TLSCAFile: "not pem"}, "invalid CA certificate", false, false, false, false},
+		{"client certificate", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert, TLSKeyFile: c.key}, "", false, false, true, false},
+		{"EC key", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert, TLSKeyFile: c.ecKey}, "", false, false, true, false},
+		{"combined PEM", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert + c.key}, "", false, false, true, false},
+		{"encrypted key", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert, TLSKeyFile: c.encrypted, TLSKeyPassword: c.keyPassword}, "", false, false, true, false},
+		{"legacy encrypted key", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert, TLSKeyFile: c.legacyCrypt, TLSKeyPassword: c.keyPassword}, "", false, false, true, false},
+		{"encrypted key without password", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert, TLSKeyFile: c.encrypted}, "key password is required", false, false, false, false},
+		{"wrong key password", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert, TLSKeyFile: c.legacyCrypt, TLSKeyPassword: "nope"}, "wrong key password", false, false, false, false},
+		{"mismatched key", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert, TLSKeyFile: c.otherKey}, "do not match", false, false, false, false},
+		{"certificate without key", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.cert}, "no PEM private key", false, false, false, false},
+		{"key without certificate", types.ExtendedConnection{TLSEnabled: true, TLSKeyFile: c.key}, "requires a client certificate", false, false, false, false},
+		{"invalid certificate", types.ExtendedConnection{TLSEnabled: true, TLSCertFile: c.key}, "invalid client certificate", false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := buildTLSConfig(tt.conn)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantNil {
+				if config != nil {
+					t.Fatal("Expected no TLS config")
+				}
+				return
+			}
+			if (config.RootCAs != nil) != tt.wantCA {
+				t.Errorf("RootCAs set = %v, want %v", config.RootCAs != nil, tt.wantCA)
+			}
+			if (len(config.Certificates) == 1) != tt.wantCert {
+				t.Errorf("got %d client certificates, want cert = %v", len(config.Certificates), tt.wantCert)
+			}
+			if config.InsecureSkipVerify != tt.wantSkip {
+				t.Errorf("InsecureSkipVerify = %v, want %v", config.InsecureSkipVerify, tt.wantSkip)
+			}
+		})
+	}
+}
+
+func TestApplyTLSConfig_TunnelServerName(t *testing.T) {
+	opts := options.Client()
+	err := applyTLSConfig(opts, types.ExtendedConnection{TLSEnabled: true}, &Tunnel{remote: "db.internal:27017"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.TLSConfig == nil || opts.TLSConfig.ServerName != "db.internal" {
+		t.Fatalf("ServerName should be the tunneled host, got %+v", opts.TLSConfig)
+	}
+
+	opts = options.Client()
+	if err := applyTLSConfig(opts, types.ExtendedConnection{}, nil); err != nil || opts.TLSConfig != nil {
+		t.Fatalf("TLS disabled should leave options unchanged, got %v, %v", opts.TLSConfig, err)
+	}
+}
+
+func TestConnectionErrorHint_Certificates(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"x509: certificate signed by unknown authority", "Add the CA certificate"},
+		{"x509: certificate is valid for db1, not db2", "does not match the hostname"},
+		{"x509: certificate has expired or is not yet valid", "has expired"},
+		{"remote error: tls: bad certificate", "rejected the client certificate"},
+		{"tls: first record does not look like a TLS handshake", "does not use TLS"},
+		{"tls: handshake failure", "TLS/SSL certificate configuration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := connectionErrorHint(errors.New(tt.msg)); !strings.Contains(got, tt.want) {
+				t.Errorf("hint = %q, want containing %q", got, tt.want)
+			}
+		})
+	}
+}
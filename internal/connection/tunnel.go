@@ -0,0 +1,346 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// defaultSSHPort is used when a connection does not set an SSH port.
+const defaultSSHPort = 22
+
+// defaultMongoPort is used for URI hosts without a port.
+const defaultMongoPort = 27017
+
+// sshKeepAliveInterval is how often an idle tunnel checks that the SSH server is alive.
+const sshKeepAliveInterval = 30 * time.Second
+
+// Tunnel forwards connections made to a local port through an SSH server to a remote
+// address, as "ssh -L" does. It closes itself when the SSH server stops answering
+// keepalives.
+type Tunnel struct {
+	client   *ssh.Client
+	listener net.Listener
+	remote   string
+	hostKey  string // SHA256 fingerprint of the SSH server's host key
+
+	closeOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// OpenTunnel connects to the SSH server configured on conn and forwards a free port on
+// 127.0.0.1 to remote ("host:port" as reached from the SSH server).
+func OpenTunnel(conn types.ExtendedConnection, remote string) (*Tunnel, error) {
+	var hostKey string
+	config, err := sshClientConfig(conn, &hostKey)
+	if err != nil {
+		return nil, err
+	}
+	port := conn.SSHPort
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	sshAddr := net.JoinHostPort(conn.SSHHost, strconv.Itoa(port))
+
+	client, err := ssh.Dial("tcp", sshAddr, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SSH server %s: %w", sshAddr, err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to open local tunnel port: %w", err)
+	}
+
+	t := &Tunnel{
+		client:   client,
+		listener: listener,
+		remote:   remote,
+		hostKey:  hostKey,
+		done:     make(chan struct{}),
+	}
+	t.wg.Add(2)
+	go t.acceptLoop()
+	go t.keepAlive()
+
+	debug.LogConnection("SSH tunnel opened", map[string]interface{}{
+		"sshServer": sshAddr,
+		"remote":    remote,
+		"local":     t.LocalAddr(),
+	})
+
+	return t, nil
+}
+
+// LocalAddr returns the "127.0.0.1:port" address that forwards to the remote address.
+func (t *Tunnel) LocalAddr() string {
+	return t.listener.Addr().String()
+}
+
+// HostKeyFingerprint returns the SHA256 fingerprint of the SSH server's host key.
+func (t *Tunnel) HostKeyFingerprint() string {
+	return t.hostKey
+}
+
+// Close stops forwarding, closes open forwarded connections, and disconnects from the SSH
+// server. It is safe to call more than once.
+func (t *Tunnel) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.listener.Close()
+		t.client.Close()
+		t.wg.Wait()
+		debug.LogConnection("SSH tunnel closed", map[string]interface{}{
+			"remote": t.remote,
+		})
+	})
+	return nil
+}
+
+// acceptLoop forwards each local connection until the listener is closed.
+func (t *Tunnel) acceptLoop() {
+	defer t.wg.Done()
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+		t.wg.Add(1)
+		go t.forward(local)
+	}
+}
+
+// forward copies data between a local connection and a new channel to the remote address.
+func (t *Tunnel) forward(local net.Conn) {
+	defer t.wg.Done()
+	defer local.Close()
+
+	remote, err := t.client.Dial("tcp", t.remote)
+	if err != nil {
+		debug.LogConnection("SSH tunnel could not reach remote", map[string]interface{}{
+			"remote": t.remote,
+			"error":  err.Error(),
+		})
+		return
+	}
+	defer remote.Close()
+
+	// Closing both ends when either direction finishes unblocks the other copy
+	copied := make(chan struct{}, 2)
+	go func() { io.Copy(remote, local); copied <- struct{}{} }()
+	go func() { io.Copy(local, remote); copied <- struct{}{} }()
+	select {
+	case <-copied:
+	case <-t.done:
+	}
+}
+
+// keepAlive pings the SSH server periodically and closes the tunnel once it stops answering.
+func (t *Tunnel) keepAlive() {
+	defer t.wg.Done()
+	ticker := time.NewTicker(sshKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+		if _, _, err := t.client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+			debug.LogConnection("SSH keepalive failed", map[string]interface{}{
+				"remote": t.remote,
+				"error":  err.Error(),
+			})
+			// Close waits for this goroutine, so finish it first
+			go t.Close()
+			return
+		}
+	}
+}
+
+// sshClientConfig builds the SSH client configuration of a connection: private key auth
+// (decrypted with the passphrase if set) and/or password auth, which is also offered as
+// keyboard-interactive since many servers only enable that. The fingerprint of the host key
+// the server presents is stored in hostKey.
+func sshClientConfig(conn types.ExtendedConnection, hostKey *string) (*ssh.ClientConfig, error) {
+	if conn.SSHHost == "" {
+		return nil, fmt.Errorf("SSH host is required")
+	}
+	if conn.SSHUser == "" {
+		return nil, fmt.Errorf("SSH user is required")
+	}
+
+	var auth []ssh.AuthMethod
+	if strings.TrimSpace(conn.SSHPrivateKey) != "" {
+		signer, err := parseSSHPrivateKey(conn.SSHPrivateKey, conn.SSHPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if conn.SSHPassword != "" {
+		password := conn.SSHPassword
+		auth = append(auth,
+			ssh.Password(password),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("SSH password or private key is required")
+	}
+
+	return &ssh.ClientConfig{
+		User:            conn.SSHUser,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback(knownHostsFile(), conn.SSHHostKeyFingerprint, hostKey),
+		Timeout:         core.DefaultConnectTimeout,
+	}, nil
+}
+
+// parseSSHPrivateKey parses a PEM or OpenSSH private key, decrypting it with passphrase if
+// the key is encrypted.
+func parseSSHPrivateKey(key, passphrase string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(key))
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("SSH private key is encrypted; a passphrase is required")
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH private key: %w", err)
+	}
+	return signer, nil
+}
+
+// knownHostsFile returns the path of ~/.ssh/known_hosts, or "" without a home directory.
+func knownHostsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// hostKeyCallback verifies SSH host keys. Hosts listed in the knownHosts file must present the
+// recorded key. Other hosts must present the pinned fingerprint, which the connection records
+// the first time it connects; with no pin yet, the key is accepted so it can be pinned. The
+// fingerprint of the presented key is stored in seen.
+func hostKeyCallback(knownHosts, pinned string, seen *string) ssh.HostKeyCallback {
+	var known ssh.HostKeyCallback
+	if knownHosts != "" {
+		known, _ = knownhosts.New(knownHosts)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+		*seen = fingerprint
+		if known != nil {
+			err := known(hostname, remote, key)
+			if err == nil {
+				return nil
+			}
+			var keyErr *knownhosts.KeyError
+			if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+				return fmt.Errorf("SSH host key verification failed for %s: %w", hostname, err)
+			}
+		}
+		switch pinned {
+		case fingerprint:
+			return nil
+		case "":
+			debug.LogConnection("Pinning new SSH host key", map[string]interface{}{
+				"host":        hostname,
+				"fingerprint": fingerprint,
+			})
+			return nil
+		}
+		return fmt.Errorf("SSH host key of %s has changed (pinned %s, got %s); if the server was "+
+			"reinstalled, reset the pinned host key of the connection", hostname, pinned, fingerprint)
+	}
+}
+
+// tunnelURI rewrites a mongodb:// URI to go through a tunnel: it returns the first host
+// of the URI, which the tunnel should forward to, and the URI pointing at localAddr
+// instead. directConnection is set because the driver would otherwise try to reach the
+// other replica set members by their own addresses, which are not tunneled.
+func tunnelURI(uri, localAddr string) (remote, tunneled string, err error) {
+	if strings.HasPrefix(uri, "mongodb+srv://") {
+		return "", "", fmt.Errorf("SSH tunnels need a mongodb:// URI with explicit hosts; mongodb+srv:// can't be resolved through the tunnel")
+	}
+	const scheme = "mongodb://"
+	if !strings.HasPrefix(uri, scheme) {
+		return "", "", fmt.Errorf("invalid URI scheme: must start with mongodb://")
+	}
+
+	rest := uri[len(scheme):]
+	hostsEnd := strings.IndexAny(rest, "/?")
+	if hostsEnd < 0 {
+		hostsEnd = len(rest)
+	}
+	hostsStart := strings.LastIndex(rest[:hostsEnd], "@") + 1
+	hosts := rest[hostsStart:hostsEnd]
+	first := strings.Split(hosts, ",")[0]
+	if first == "" {
+		return "", "", fmt.Errorf("URI has no host")
+	}
+
+	remote = first
+	if _, _, err := net.SplitHostPort(first); err != nil {
+		remote = net.JoinHostPort(strings.Trim(first, "[]"), strconv.Itoa(defaultMongoPort))
+	}
+
+	tail := rest[hostsEnd:]
+	if !strings.Contains(tail, "directConnection=") {
+		switch {
+		case strings.Contains(tail, "?"):
+			tail += "&directConnection=true"
+		case strings.HasPrefix(tail, "/"):
+			tail += "?directConnection=true"
+		default:
+			tail = "/?directConnection=true"
+		}
+	}
+	tunneled = scheme + rest[:hostsStart] + localAddr + tail
+	return remote, tunneled, nil
+}
+
+// openTunnelForURI opens the SSH tunnel of a connection and returns it with the URI to
+// connect through it.
+func openTunnelForURI(conn types.ExtendedConnection, uri string) (*Tunnel, string, error) {
+	remote, _, err := tunnelURI(uri, "")
+	if err != nil {
+		return nil, "", err
+	}
+	tunnel, err := OpenTunnel(conn, remote)
+	if err != nil {
+		return nil, "", err
+	}
+	_, tunneled, err := tunnelURI(uri, tunnel.LocalAddr())
+	if err != nil {
+		tunnel.Close()
+		return nil, "", err
+	}
+	return tunnel, tunneled, nil
+}
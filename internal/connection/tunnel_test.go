@@ -0,0 +1,373 @@
+package connection
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestTunnelURI(t *testing.T) {
+	tests := []struct {
+		name       string
+		uri        string
+		wantRemote string
+		wantURI    string
+		errMsg     string
+	}{
+		{"host only", "mongodb://db.internal", "db.internal:27017", "mongodb://127.0.0.1:5000/?directConnection=true", ""},
+		{"credentials and database", "mongodb://u:p@db.internal:27018/app", "db.internal:27018", "mongodb://u:p@127.0.0.1:5000/app?directConnection=true", ""},
+		{"options", "mongodb://db:27017/?authSource=admin", "db:27017", "mongodb://127.0.0.1:5000/?authSource=admin&directConnection=true", ""},
+		{"replica set uses first host", "mongodb://a:1,b:2/?replicaSet=rs0", "a:1", "mongodb://127.0.0.1:5000/?replicaSet=rs0&directConnection=true", ""},
+		{"keeps directConnection", "mongodb://a:1/?directConnection=false", "a:1", "mongodb://127.0.0.1:5000/?directConnection=false", ""},
+		{"ipv6", "mongodb://[::1]", "[::1]:27017", "mongodb://127.0.0.1:5000/?directConnection=true", ""},
+		{"srv", "mongodb+srv://cluster.example.com", "", "", "mongodb+srv://"},
+		{"no host", "mongodb:///db", "", "", "no host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote, uri, err := tunnelURI(tt.uri, "127.0.0.1:5000")
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if remote != tt.wantRemote {
+				t.Errorf("remote = %q, want %q", remote, tt.wantRemote)
+			}
+			if uri != tt.wantURI {
+				t.Errorf("uri = %q, want %q", uri, tt.wantURI)
+			}
+		})
+	}
+}
+
+func TestSSHClientConfig(t *testing.T) {
+	key, encrypted := testPrivateKeys(t, "secret")
+
+	tests := []struct {
+		name     string
+		conn     types.ExtendedConnection
+		errMsg   string
+		wantAuth int
+	}{
+		{"password", types.ExtendedConnection{SSHHost: "h", SSHUser: "u", SSHPassword: "pw"}, "", 2},
+		{"key", types.ExtendedConnection{SSHHost: "h", SSHUser: "u", SSHPrivateKey: key}, "", 1},
+		{"encrypted key", types.ExtendedConnection{SSHHost: "h", SSHUser: "u", SSHPrivateKey: encrypted, SSHPassphrase: "secret"}, "", 1},
+		{"key and password", types.ExtendedConnection{SSHHost: "h", SSHUser: "u", SSHPrivateKey: key, SSHPassword: "pw"}, "", 3},
+		{"missing passphrase", types.ExtendedConnection{SSHHost: "h", SSHUser: "u", SSHPrivateKey: encrypted}, "passphrase is required", 0},
+		{"wrong passphrase", types.ExtendedConnection{SSHHost: "h", SSHUser: "u", SSHPrivateKey: encrypted, SSHPassphrase: "nope"}, "invalid SSH private key", 0},
+		{"garbage key", types.ExtendedConnection{SSHHost: "h", SSHUser: "u", SSHPrivateKey: "not a key"}, "invalid SSH private key", 0},
+		{"no credentials", types.ExtendedConnection{SSHHost: "h", SSHUser: "u"}, "password or private key", 0},
+		{"no user", types.ExtendedConnection{SSHHost: "h", SSHPassword: "pw"}, "user is required", 0},
+		{"no host", types.ExtendedConnection{SSHUser: "u", SSHPassword: "pw"}, "host is required", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hostKey string
+			config, err := sshClientConfig(tt.conn, &hostKey)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(config.Auth) != tt.wantAuth {
+				t.Errorf("got %d auth methods, want %d", len(config.Auth), tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	key := testHostKey(t)
+	other := testHostKey(t)
+	fingerprint := ssh.FingerprintSHA256(key)
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 22}
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize("known.example:22")}, key)
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		knownHosts string
+		pinned     string
+		host       string
+		key        ssh.PublicKey
+		errMsg     string
+	}{
+		{"first use", "", "", "new.example:22", key, ""},
+		{"pinned key", "", fingerprint, "new.example:22", key, ""},
+		{"changed key", "", fingerprint, "new.example:22", other, "has changed"},
+		{"known host", knownHosts, "", "known.example:22", key, ""},
+		{"known host with other key", knownHosts, "", "known.example:22", other, "verification failed"},
+		{"host not in known_hosts uses pin", knownHosts, ssh.FingerprintSHA256(other), "new.example:22", key, "has changed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			err := hostKeyCallback(tt.knownHosts, tt.pinned, &seen)(tt.host, addr, tt.key)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := ssh.FingerprintSHA256(tt.key); seen != want {
+				t.Errorf("seen = %q, want %q", seen, want)
+			}
+		})
+	}
+}
+
+func TestTunnel_ForwardsThroughSSHServer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // No known_hosts: the test server's key is accepted
+
+	echoAddr := startEchoServer(t)
+	sshAddr := startSSHServer(t, "tunnel", "pw")
+	host, port, _ := net.SplitHostPort(sshAddr)
+	portNum, _ := strconv.Atoi(port)
+
+	tunnel, err := OpenTunnel(types.ExtendedConnection{
+		SSHHost:     host,
+		SSHPort:     portNum,
+		SSHUser:     "tunnel",
+		SSHPassword: "pw",
+	}, echoAddr)
+	if err != nil {
+		t.Fatalf("OpenTunnel: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", tunnel.LocalAddr())
+	if err != nil {
+		t.Fatalf("dial tunnel: %v", err)
+	}
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "ping\n" {
+		t.Fatalf("read = %q, %v; want echoed ping", line, err)
+	}
+	conn.Close()
+	if !strings.HasPrefix(tunnel.HostKeyFingerprint(), "SHA256:") {
+		t.Errorf("HostKeyFingerprint = %q, want the server's SHA256 fingerprint", tunnel.HostKeyFingerprint())
+	}
+
+	tunnel.Close()
+	tunnel.Close() // Idempotent
+	if _, err := net.Dial("tcp", tunnel.LocalAddr()); err == nil {
+		t.Error("Expected the local port to be closed")
+	}
+}
+
+func TestOpenTunnel_WrongPassword(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sshAddr := startSSHServer(t, "tunnel", "pw")
+	host, port, _ := net.SplitHostPort(sshAddr)
+	portNum, _ := strconv.Atoi(port)
+
+	_, err := OpenTunnel(types.ExtendedConnection{SSHHost: host, SSHPort: portNum, SSHUser: "tunnel", SSHPassword: "wrong"}, "127.0.0.1:1")
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to SSH server") {
+		t.Fatalf("error = %v, want authentication failure", err)
+	}
+}
+
+func TestOpenTunnel_PinnedHostKeyMismatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sshAddr := startSSHServer(t, "tunnel", "pw")
+	host, port, _ := net.SplitHostPort(sshAddr)
+	portNum, _ := strconv.Atoi(port)
+
+	_, err := OpenTunnel(types.ExtendedConnection{
+		SSHHost:               host,
+		SSHPort:               portNum,
+		SSHUser:               "tunnel",
+		SSHPassword:           "pw",
+		SSHHostKeyFingerprint: ssh.FingerprintSHA256(testHostKey(t)),
+	}, "127.0.0.1:1")
+	if err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("error = %v, want host key change", err)
+	}
+}
+
+func TestTestConnectionWithSettings_OpensSSHTunnel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sshAddr := startSSHServer(t, "tunnel", "pw")
+	host, port, _ := net.SplitHostPort(sshAddr)
+	portNum, _ := strconv.Atoi(port)
+
+	svc := NewService(core.NewAppState(), nil)
+	result, err := svc.TestConnectionWithSettings("mongodb://db.internal:27017", types.ExtendedConnection{
+		SSHEnabled:  true,
+		SSHHost:     host,
+		SSHPort:     portNum,
+		SSHUser:     "tunnel",
+		SSHPassword: "wrong",
+	})
+	if err != nil {
+		t.Fatalf("TestConnectionWithSettings: %v", err)
+	}
+	if !strings.Contains(result.Error, "Failed to open SSH tunnel") {
+		t.Errorf("result.Error = %q, want the SSH tunnel failure", result.Error)
+	}
+}
+
+// testHostKey returns a new ed25519 public key.
+func testHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// testPrivateKeys returns an OpenSSH private key, plain and encrypted with passphrase.
+func testPrivateKeys(t *testing.T, passphrase string) (plain, encrypted string) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encBlock, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(block)), string(pem.EncodeToMemory(encBlock))
+}
+
+// startEchoServer listens on a local port and echoes back whatever it receives.
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				io.Copy(c, c)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// startSSHServer runs a minimal SSH server that accepts one user and supports direct-tcpip
+// (local port forwarding) channels.
+func startSSHServer(t *testing.T, user, password string) string {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, pw []byte) (*ssh.Permissions, error) {
+			if meta.User() == user && string(pw) == password {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(c, config)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func serveSSH(c net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(c, config)
+	if err != nil {
+		c.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "direct-tcpip" {
+			newChan.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		// Payload: string host, uint32 port, string origin host, uint32 origin port
+		payload := newChan.ExtraData()
+		hostLen := binary.BigEndian.Uint32(payload)
+		host := string(payload[4 : 4+hostLen])
+		port := binary.BigEndian.Uint32(payload[4+hostLen:])
+		target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+		if err != nil {
+			newChan.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go ssh.DiscardRequests(chReqs)
+		go func() {
+			defer ch.Close()
+			defer target.Close()
+			go io.Copy(target, ch)
+			io.Copy(ch, target)
+		}()
+	}
+}
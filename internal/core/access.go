@@ -0,0 +1,99 @@
+package core
+
+import (
+	"fmt"
+	"path"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DatabaseAllowed reports whether the database allow/deny lists of a connection permit dbName.
+// Connections without lists permit every database.
+func (s *AppState) DatabaseAllowed(connID, dbName string) bool {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+	for _, c := range s.SavedConnections {
+		if c.ID == connID {
+			return databaseAllowed(c.AllowedDatabases, c.DeniedDatabases, dbName)
+		}
+	}
+	return true
+}
+
+// HasDatabaseRestrictions reports whether a connection has database allow or deny lists.
+func (s *AppState) HasDatabaseRestrictions(connID string) bool {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+	for _, c := range s.SavedConnections {
+		if c.ID == connID {
+			return len(c.AllowedDatabases) > 0 || len(c.DeniedDatabases) > 0
+		}
+	}
+	return false
+}
+
+// CheckDatabaseAccess returns a DatabaseAccessError if the connection's allow/deny lists do
+// not permit dbName.
+func (s *AppState) CheckDatabaseAccess(connID, dbName string) error {
+	if !s.DatabaseAllowed(connID, dbName) {
+		return &DatabaseAccessError{ConnID: connID, Database: dbName}
+	}
+	return nil
+}
+
+// GetDatabaseClient returns the client of a connection for an operation on dbName, or an
+// error if not connected or the database is not allowed. Every service operation scoped to
+// a database gets its client through it.
+func (s *AppState) GetDatabaseClient(connID, dbName string) (*mongo.Client, error) {
+	client, err := s.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckDatabaseAccess(connID, dbName); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// FilterDatabases returns the names the connection's allow/deny lists permit, in order.
+func (s *AppState) FilterDatabases(connID string, names []string) []string {
+	filtered := make([]string, 0, len(names))
+	for _, name := range names {
+		if s.DatabaseAllowed(connID, name) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
+
+// databaseAllowed applies allow/deny lists of database names or glob patterns to dbName.
+// The deny list wins; an empty allow list allows every database.
+func databaseAllowed(allowed, denied []string, dbName string) bool {
+	if matchesDatabase(denied, dbName) {
+		return false
+	}
+	return len(allowed) == 0 || matchesDatabase(allowed, dbName)
+}
+
+// matchesDatabase reports whether dbName matches one of patterns.
+func matchesDatabase(patterns []string, dbName string) bool {
+	for _, p := range patterns {
+		if ok, err := path.Match(p, dbName); p == dbName || err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDatabasePatterns checks the names and glob patterns of a database access list.
+func ValidateDatabasePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if p == "" {
+			return fmt.Errorf("database access lists cannot contain empty names")
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid database pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
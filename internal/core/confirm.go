@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// DeleteConfirmationText is what the user must type to confirm a destructive operation on a
+// connection with RequireDeleteConfirmation set.
+const DeleteConfirmationText = "DELETE"
+
+// ConfirmationTTL is how long a confirmation token stays usable after its delay has passed.
+const ConfirmationTTL = 2 * time.Minute
+
+// Confirmation is an issued destructive operation confirmation token. Operations on a
+// connection with safety settings consume a confirmed token matching their connection,
+// operation, and target.
+type Confirmation struct {
+	types.DestructiveConfirmation
+	ConnID    string
+	Confirmed bool
+}
+
+// connectionSafety returns the effective safety settings of a saved connection.
+func (s *AppState) connectionSafety(connID string) (delay time.Duration, requireText bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+	p := s.policyLocked(connID)
+	return p.delay, p.requireText
+}
+
+// RequestConfirmation issues a token for a destructive operation. The target names what the
+// operation destroys (a database, a namespace, or an import file). On connections without
+// safety settings nothing is required and no token is issued.
+func (s *AppState) RequestConfirmation(connID, operation, target string) types.DestructiveConfirmation {
+	delay, requireText := s.connectionSafety(connID)
+	if delay <= 0 && !requireText {
+		return types.DestructiveConfirmation{Operation: operation, Target: target}
+	}
+
+	now := time.Now()
+	c := &Confirmation{
+		DestructiveConfirmation: types.DestructiveConfirmation{
+			Required:     true,
+			Token:        uuid.New().String(),
+			Operation:    operation,
+			Target:       target,
+			DelaySeconds: int(delay / time.Second),
+			ValidAfter:   now.Add(delay),
+			ExpiresAt:    now.Add(delay + ConfirmationTTL),
+		},
+		ConnID: connID,
+	}
+	if requireText {
+		c.ConfirmationText = DeleteConfirmationText
+	}
+
+	s.ConfirmMu.Lock()
+	defer s.ConfirmMu.Unlock()
+	s.pruneConfirmationsLocked(now)
+	s.Confirmations[c.Token] = c
+	return c.DestructiveConfirmation
+}
+
+// ConfirmOperation confirms a token once its delay has passed, checking the typed text if the
+// connection requires it. The confirmed token authorizes one matching operation.
+func (s *AppState) ConfirmOperation(token, typed string) error {
+	s.ConfirmMu.Lock()
+	defer s.ConfirmMu.Unlock()
+
+	now := time.Now()
+	s.pruneConfirmationsLocked(now)
+	c, ok := s.Confirmations[token]
+	if !ok {
+		return fmt.Errorf("confirmation token is unknown or expired")
+	}
+	if wait := c.ValidAfter.Sub(now); wait > 0 {
+		return fmt.Errorf("confirmation is not valid yet: wait %d more seconds", int((wait+time.Second-1)/time.Second))
+	}
+	if c.ConfirmationText != "" && typed != c.ConfirmationText {
+		return fmt.Errorf("type %s to confirm", c.ConfirmationText)
+	}
+	c.Confirmed = true
+	return nil
+}
+
+// RequireConfirmation consumes a confirmed token for a destructive operation on a connection
+// with safety settings, or returns a ConfirmationRequiredError if there is none. Connections
+// without safety settings need no token.
+func (s *AppState) RequireConfirmation(connID, operation, target string) error {
+	delay, requireText := s.connectionSafety(connID)
+	if delay <= 0 && !requireText {
+		return nil
+	}
+
+	s.ConfirmMu.Lock()
+	defer s.ConfirmMu.Unlock()
+	s.pruneConfirmationsLocked(time.Now())
+	for token, c := range s.Confirmations {
+		if c.Confirmed && c.ConnID == connID && c.Operation == operation && c.Target == target {
+			delete(s.Confirmations, token)
+			return nil
+		}
+	}
+	return &ConfirmationRequiredError{ConnID: connID, Operation: operation, Target: target}
+}
+
+// pruneConfirmationsLocked forgets expired tokens. Caller must hold ConfirmMu.
+func (s *AppState) pruneConfirmationsLocked(now time.Time) {
+	for token, c := range s.Confirmations {
+		if now.After(c.ExpiresAt) {
+			delete(s.Confirmations, token)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Environment tags of saved connections.
+const (
+	EnvProd    = "prod"
+	EnvStaging = "staging"
+	EnvDev     = "dev"
+)
+
+// DefaultProdDestructiveDelay is the default minimum destructive delay of prod-tagged connections.
+const DefaultProdDestructiveDelay = 5 * time.Second
+
+// ValidateEnvironment checks an environment tag. Empty means untagged.
+func ValidateEnvironment(env string) error {
+	switch env {
+	case "", EnvProd, EnvStaging, EnvDev:
+		return nil
+	}
+	return fmt.Errorf("invalid environment %q: must be prod, staging, or dev", env)
+}
+
+// connectionPolicy is the effective safety policy of a saved connection: its own settings,
+// tightened by the app settings for its environment.
+type connectionPolicy struct {
+	readOnly    bool
+	delay       time.Duration
+	requireText bool
+}
+
+// policyLocked returns the effective policy of a connection. Caller must hold Mu.
+func (s *AppState) policyLocked(connID string) connectionPolicy {
+	for _, c := range s.SavedConnections {
+		if c.ID != connID {
+			continue
+		}
+		p := connectionPolicy{
+			readOnly:    c.ReadOnly,
+			delay:       time.Duration(c.DestructiveDelay) * time.Second,
+			requireText: c.RequireDeleteConfirmation,
+		}
+		if c.Environment == EnvProd {
+			p.readOnly = p.readOnly || s.Settings.ProdReadOnly
+			p.delay = max(p.delay, time.Duration(s.Settings.ProdDestructiveDelaySeconds)*time.Second)
+			p.requireText = true
+		}
+		return p
+	}
+	return connectionPolicy{}
+}
+
+// ConnectionEnvironment returns the environment tag of a saved connection, or "" if untagged.
+func (s *AppState) ConnectionEnvironment(connID string) string {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+	for _, c := range s.SavedConnections {
+		if c.ID == connID {
+			return c.Environment
+		}
+	}
+	return ""
+}
+
+// EmitConnectionEvent emits an event about a connection, adding the connection's
+// "environment" tag to the payload so the UI can color-code it. Payloads of tagged
+// connections are sent as JSON objects with the same fields plus the tag.
+func (s *AppState) EmitConnectionEvent(connID, eventName string, data interface{}) {
+	if s.DisableEvents || s.Emitter == nil {
+		return
+	}
+	env := s.ConnectionEnvironment(connID)
+	if env == "" {
+		s.Emitter.Emit(eventName, data)
+		return
+	}
+	s.Emitter.Emit(eventName, withEnvironment(data, env))
+}
+
+// withEnvironment returns data as a JSON object with an "environment" field. Payloads that
+// are not objects are wrapped as {"data": ..., "environment": ...}.
+func withEnvironment(data interface{}, env string) map[string]interface{} {
+	tagged := map[string]interface{}{}
+	switch d := data.(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, v := range d {
+			tagged[k] = v
+		}
+	default:
+		raw, err := json.Marshal(data)
+		if err != nil || json.Unmarshal(raw, &tagged) != nil {
+			tagged = map[string]interface{}{"data": data}
+		}
+	}
+	tagged["environment"] = env
+	return tagged
+}
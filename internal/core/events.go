@@ -43,6 +43,16 @@ func (e *NotConnectedError) Error() string {
 	return fmt.Sprintf("not connected: %s", e.ConnID)
 }
 
+// TransactionNotFoundError indicates a transaction token is unknown, already finished,
+// or was aborted when its connection closed.
+type TransactionNotFoundError struct {
+	Token string
+}
+
+func (e *TransactionNotFoundError) Error() string {
+	return fmt.Sprintf("transaction not found: %s", e.Token)
+}
+
 // ConnectionNotFoundError indicates a saved connection was not found.
 type ConnectionNotFoundError struct {
 	ConnID string
@@ -60,3 +70,36 @@ type FolderNotFoundError struct {
 func (e *FolderNotFoundError) Error() string {
 	return fmt.Sprintf("folder not found: %s", e.FolderID)
 }
+
+// ReadOnlyError indicates a write operation was attempted on a connection marked read-only.
+type ReadOnlyError struct {
+	ConnID    string
+	Operation string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("connection is read-only: %s is not allowed", e.Operation)
+}
+
+// DatabaseAccessError indicates an operation on a database the connection's allow/deny list
+// does not permit.
+type DatabaseAccessError struct {
+	ConnID   string
+	Database string
+}
+
+func (e *DatabaseAccessError) Error() string {
+	return fmt.Sprintf("access to database %q is not allowed on this connection", e.Database)
+}
+
+// ConfirmationRequiredError indicates a destructive operation was attempted on a connection
+// with safety settings without a confirmed confirmation token.
+type ConfirmationRequiredError struct {
+	ConnID    string
+	Operation string
+	Target    string
+}
+
+func (e *ConfirmationRequiredError) Error() string {
+	return fmt.Sprintf("confirmation required: %s of %s must be confirmed first", e.Operation, e.Target)
+}
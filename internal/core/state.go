@@ -3,7 +3,9 @@ package core
 
 import (
 	"context"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -14,40 +16,92 @@ import (
 // DefaultQueryTimeout is the default timeout for database queries.
 const DefaultQueryTimeout = 30 * time.Second
 
+// maxTimeGrace is how much longer the client waits than the server-side maxTimeMS,
+// so the server's MaxTimeMSExpired error surfaces instead of a client deadline.
+const maxTimeGrace = 2 * time.Second
+
 // DefaultConnectTimeout is the default timeout for connection attempts.
 const DefaultConnectTimeout = 10 * time.Second
 
+// IndexBuildTimeout bounds how long to wait for an index build to finish.
+// Builds on large collections routinely outlast the query timeout.
+const IndexBuildTimeout = 24 * time.Hour
+
+// DefaultEstimatedCountThreshold is the default collection size above which
+// empty-filter queries report an estimated rather than exact document count.
+const DefaultEstimatedCountThreshold int64 = 1_000_000
+
 // AppState holds the shared application state.
 type AppState struct {
-	Clients          map[string]*mongo.Client        // Active connections by ID
-	Connecting       map[string]bool                 // Connection IDs currently being connected (to prevent races)
-	SavedConnections []types.SavedConnection         // In-memory cache of saved connections
-	Folders          []types.Folder                  // Connection folders
-	ConfigDir        string                          // Config directory path
+	Clients          map[string]*mongo.Client // Active connections by ID
+	Tunnels          map[string]io.Closer     // SSH tunnels of active connections, closed with the client
+	Connecting       map[string]bool          // Connection IDs currently being connected (to prevent races)
+	SavedConnections []types.SavedConnection  // In-memory cache of saved connections
+	Folders          []types.Folder           // Connection folders
+	ConfigDir        string                   // Config directory path
 	Mu               sync.RWMutex
-	CancelMu         sync.Mutex                      // Mutex for export/import cancel functions
-	ExportCancels    map[string]context.CancelFunc   // Cancel functions for ongoing exports (keyed by export ID)
-	ImportCancel     context.CancelFunc              // Cancel function for ongoing import
-	ExportPause      *PauseController                // Pause controller for export operations
-	ImportPause      *PauseController                // Pause controller for import operations
-	Ctx              context.Context                 // Wails context
-	DisableEvents    bool                            // Disable event emission (for tests)
-	Emitter          EventEmitter                    // Event emitter for UI notifications
+	CancelMu         sync.Mutex                    // Mutex for export/import cancel functions
+	ExportCancels    map[string]context.CancelFunc // Cancel functions for ongoing exports (keyed by export ID)
+	QueryCancels     map[string]context.CancelFunc // Cancel functions for streaming queries and bulk operations (keyed by ID)
+	ImportCancel     context.CancelFunc            // Cancel function for ongoing import
+	ExportPause      *PauseController              // Pause controller for export operations
+	ImportPause      *PauseController              // Pause controller for import operations
+	Ctx              context.Context               // Wails context
+	DisableEvents    bool                          // Disable event emission (for tests)
+	Emitter          EventEmitter                  // Event emitter for UI notifications
+	Settings         types.AppSettings             // Global application settings (guarded by Mu)
+	TxnMu            sync.Mutex                    // Mutex for open transactions
+	Transactions     map[string]*Transaction       // Open transactions (keyed by session token)
+	ConfirmMu        sync.Mutex                    // Mutex for destructive operation confirmations
+	Confirmations    map[string]*Confirmation      // Issued confirmation tokens (keyed by token)
+
+	queryTimeout atomic.Int64 // Configured query timeout in nanoseconds (0 = DefaultQueryTimeout)
 }
 
 // NewAppState creates a new AppState with initialized maps.
 func NewAppState() *AppState {
 	return &AppState{
 		Clients:          make(map[string]*mongo.Client),
+		Tunnels:          make(map[string]io.Closer),
 		Connecting:       make(map[string]bool),
 		SavedConnections: []types.SavedConnection{},
 		Folders:          []types.Folder{},
 		ExportCancels:    make(map[string]context.CancelFunc),
+		QueryCancels:     make(map[string]context.CancelFunc),
+		Transactions:     make(map[string]*Transaction),
+		Confirmations:    make(map[string]*Confirmation),
 		ExportPause:      NewPauseController(),
 		ImportPause:      NewPauseController(),
+		Settings:         DefaultSettings(),
+	}
+}
+
+// DefaultSettings returns the application settings used when none are persisted.
+func DefaultSettings() types.AppSettings {
+	return types.AppSettings{
+		EstimatedCountThreshold: DefaultEstimatedCountThreshold,
+		QueryTimeoutSeconds:     int(DefaultQueryTimeout / time.Second),
+		OutputMode:              "canonical",
+
+		ProdDestructiveDelaySeconds: int(DefaultProdDestructiveDelay / time.Second),
 	}
 }
 
+// GetSettings returns a copy of the current application settings.
+func (s *AppState) GetSettings() types.AppSettings {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+	return s.Settings
+}
+
+// SetSettings replaces the current application settings and applies the query timeout.
+func (s *AppState) SetSettings(settings types.AppSettings) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	s.Settings = settings
+	s.SetQueryTimeout(time.Duration(settings.QueryTimeoutSeconds) * time.Second)
+}
+
 // ConnectionInProgressError is returned when a connection attempt is already in progress.
 type ConnectionInProgressError struct {
 	ConnID string
@@ -88,6 +142,7 @@ func (s *AppState) GetClient(connID string) (*mongo.Client, error) {
 
 // SetClient stores a client for a connection ID.
 func (s *AppState) SetClient(connID string, client *mongo.Client) {
+	s.abortTransactions(connID)
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
 	// Disconnect existing client if any
@@ -97,14 +152,55 @@ func (s *AppState) SetClient(connID string, client *mongo.Client) {
 	s.Clients[connID] = client
 }
 
-// RemoveClient removes a client for a connection ID.
+// RemoveClient removes a client for a connection ID, aborting any open transactions on it.
 func (s *AppState) RemoveClient(connID string) {
+	s.abortTransactions(connID)
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
 	if client, ok := s.Clients[connID]; ok {
 		client.Disconnect(context.Background())
 		delete(s.Clients, connID)
 	}
+	s.closeTunnelLocked(connID)
+}
+
+// SetTunnel registers the tunnel a connection's client goes through, closing any previous
+// one. A nil tunnel only closes the previous one.
+func (s *AppState) SetTunnel(connID string, tunnel io.Closer) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	if existing, ok := s.Tunnels[connID]; ok && existing == tunnel {
+		return
+	}
+	s.closeTunnelLocked(connID)
+	if tunnel != nil {
+		s.Tunnels[connID] = tunnel
+	}
+}
+
+// closeTunnelLocked closes and forgets a connection's tunnel. Caller must hold Mu.
+func (s *AppState) closeTunnelLocked(connID string) {
+	if tunnel, ok := s.Tunnels[connID]; ok {
+		tunnel.Close()
+		delete(s.Tunnels, connID)
+	}
+}
+
+// IsReadOnly reports whether a saved connection is read-only, either marked so or by the
+// policy for its environment.
+func (s *AppState) IsReadOnly(connID string) bool {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+	return s.policyLocked(connID).readOnly
+}
+
+// CheckWritable returns a ReadOnlyError if the connection is marked read-only. Every service
+// operation that writes to the server calls it before doing so.
+func (s *AppState) CheckWritable(connID, operation string) error {
+	if s.IsReadOnly(connID) {
+		return &ReadOnlyError{ConnID: connID, Operation: operation}
+	}
+	return nil
 }
 
 // HasClient checks if a client exists for a connection ID.
@@ -126,9 +222,40 @@ func (s *AppState) GetAllClients() map[string]*mongo.Client {
 	return result
 }
 
-// ContextWithTimeout creates a context with the default query timeout.
-func ContextWithTimeout() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), DefaultQueryTimeout)
+// QueryTimeout returns the configured query timeout.
+func (s *AppState) QueryTimeout() time.Duration {
+	if d := time.Duration(s.queryTimeout.Load()); d > 0 {
+		return d
+	}
+	return DefaultQueryTimeout
+}
+
+// SetQueryTimeout sets the query timeout used by ContextWithTimeout. 0 restores the default.
+func (s *AppState) SetQueryTimeout(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	s.queryTimeout.Store(int64(d))
+}
+
+// ContextWithTimeout creates a context with the configured query timeout.
+func (s *AppState) ContextWithTimeout() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.QueryTimeout())
+}
+
+// QueryMaxTime returns the server-side time limit for a query: maxTimeMS when set,
+// otherwise the configured query timeout.
+func (s *AppState) QueryMaxTime(maxTimeMS int64) time.Duration {
+	if maxTimeMS > 0 {
+		return time.Duration(maxTimeMS) * time.Millisecond
+	}
+	return s.QueryTimeout()
+}
+
+// ContextWithMaxTime creates a context for a query limited server-side to QueryMaxTime(maxTimeMS).
+// The client deadline is slightly longer so the server reports the timeout.
+func (s *AppState) ContextWithMaxTime(maxTimeMS int64) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.QueryMaxTime(maxTimeMS)+maxTimeGrace)
 }
 
 // ContextWithConnectTimeout creates a context with the default connect timeout.
@@ -172,6 +299,35 @@ func (s *AppState) CancelExport(exportID string) {
 	s.ExportPause.Broadcast()
 }
 
+// SetQueryCancel safely sets a streaming query cancel function by ID.
+func (s *AppState) SetQueryCancel(queryID string, cancel context.CancelFunc) {
+	s.CancelMu.Lock()
+	defer s.CancelMu.Unlock()
+	s.QueryCancels[queryID] = cancel
+}
+
+// ClearQueryCancel safely removes a streaming query cancel function by ID (does NOT call it).
+func (s *AppState) ClearQueryCancel(queryID string) {
+	s.CancelMu.Lock()
+	defer s.CancelMu.Unlock()
+	delete(s.QueryCancels, queryID)
+}
+
+// CancelQuery cancels a streaming query or bulk operation by ID. Returns false if none is running.
+func (s *AppState) CancelQuery(queryID string) bool {
+	s.CancelMu.Lock()
+	defer s.CancelMu.Unlock()
+	cancel, ok := s.QueryCancels[queryID]
+	if !ok {
+		return false
+	}
+	if cancel != nil {
+		cancel()
+	}
+	delete(s.QueryCancels, queryID)
+	return true
+}
+
 // SetImportCancel safely sets the import cancel function.
 func (s *AppState) SetImportCancel(cancel context.CancelFunc) {
 	s.CancelMu.Lock()
@@ -2,9 +2,16 @@ package core
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/types"
 )
 
 func TestExportPauseResume(t *testing.T) {
@@ -204,3 +211,391 @@ func TestResetImportPause(t *testing.T) {
 		t.Error("Import should not be paused after reset")
 	}
 }
+
+func TestCancelQuery(t *testing.T) {
+	state := NewAppState()
+	ctx, cancel := context.WithCancel(context.Background())
+	state.SetQueryCancel("q1", cancel)
+
+	if !state.CancelQuery("q1") {
+		t.Fatal("Expected CancelQuery to find running query")
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected query context to be cancelled")
+	}
+	if state.CancelQuery("q1") {
+		t.Error("Expected second CancelQuery to report not running")
+	}
+}
+
+func TestClearQueryCancel_DoesNotCancel(t *testing.T) {
+	state := NewAppState()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	state.SetQueryCancel("q1", cancel)
+
+	state.ClearQueryCancel("q1")
+	if ctx.Err() != nil {
+		t.Error("ClearQueryCancel must not cancel the context")
+	}
+	if state.CancelQuery("q1") {
+		t.Error("Expected cleared query to be gone")
+	}
+}
+
+func TestQueryTimeoutSettings(t *testing.T) {
+	state := NewAppState()
+	if got := state.QueryTimeout(); got != DefaultQueryTimeout {
+		t.Errorf("Expected default timeout %v, got %v", DefaultQueryTimeout, got)
+	}
+
+	settings := DefaultSettings()
+	settings.QueryTimeoutSeconds = 90
+	state.SetSettings(settings)
+	if got := state.QueryTimeout(); got != 90*time.Second {
+		t.Errorf("Expected configured timeout 90s, got %v", got)
+	}
+	// The timeout belongs to the state it was set on
+	if got := NewAppState().QueryTimeout(); got != DefaultQueryTimeout {
+		t.Errorf("Expected another state to keep the default timeout, got %v", got)
+	}
+
+	// 0 restores the built-in default
+	settings.QueryTimeoutSeconds = 0
+	state.SetSettings(settings)
+	if got := state.QueryTimeout(); got != DefaultQueryTimeout {
+		t.Errorf("Expected default timeout after reset, got %v", got)
+	}
+}
+
+func TestQueryMaxTime(t *testing.T) {
+	state := NewAppState()
+	state.SetQueryTimeout(45 * time.Second)
+
+	if got := state.QueryMaxTime(0); got != 45*time.Second {
+		t.Errorf("Expected configured timeout when maxTimeMS unset, got %v", got)
+	}
+	if got := state.QueryMaxTime(1500); got != 1500*time.Millisecond {
+		t.Errorf("Expected explicit maxTimeMS, got %v", got)
+	}
+
+	ctx, cancel := state.ContextWithMaxTime(1000)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("Expected context deadline")
+	}
+	if remaining := time.Until(deadline); remaining <= time.Second {
+		t.Errorf("Expected client deadline to exceed server maxTime, got %v", remaining)
+	}
+}
+
+func TestRemoveClient_AbortsTransactions(t *testing.T) {
+	state := NewAppState()
+
+	// Connecting is lazy, so sessions can be started without a server
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	state.SetClient("conn-1", client)
+
+	for _, token := range []string{"t1", "t2"} {
+		session, err := client.StartSession()
+		if err != nil {
+			t.Fatalf("Failed to start session: %v", err)
+		}
+		if err := session.StartTransaction(); err != nil {
+			t.Fatalf("Failed to start transaction: %v", err)
+		}
+		state.AddTransaction(&Transaction{Token: token, ConnID: "conn-1", Session: session, Started: time.Now()})
+	}
+	state.AddTransaction(&Transaction{Token: "other", ConnID: "conn-2"})
+
+	if _, err := state.GetTransaction("t1"); err != nil {
+		t.Fatalf("Expected open transaction, got %v", err)
+	}
+
+	state.RemoveClient("conn-1")
+
+	for _, token := range []string{"t1", "t2"} {
+		var notFound *TransactionNotFoundError
+		if _, err := state.GetTransaction(token); !errors.As(err, &notFound) {
+			t.Errorf("Expected %s to be aborted, got %v", token, err)
+		}
+	}
+	if _, err := state.GetTransaction("other"); err != nil {
+		t.Errorf("Transaction on another connection should stay open, got %v", err)
+	}
+}
+
+func TestTakeTransaction(t *testing.T) {
+	state := NewAppState()
+	state.AddTransaction(&Transaction{Token: "t1", ConnID: "conn-1"})
+
+	if _, err := state.TakeTransaction("t1"); err != nil {
+		t.Fatalf("TakeTransaction failed: %v", err)
+	}
+	var notFound *TransactionNotFoundError
+	if _, err := state.TakeTransaction("t1"); !errors.As(err, &notFound) {
+		t.Errorf("Expected TransactionNotFoundError on second take, got %v", err)
+	}
+}
+
+type fakeTunnel struct{ closed int }
+
+func (f *fakeTunnel) Close() error {
+	f.closed++
+	return nil
+}
+
+func TestTunnels_ClosedWithClient(t *testing.T) {
+	state := NewAppState()
+	first := &fakeTunnel{}
+	second := &fakeTunnel{}
+
+	state.SetTunnel("conn-1", first)
+	state.SetTunnel("conn-1", first)
+	if first.closed != 0 {
+		t.Fatal("Re-registering the same tunnel must not close it")
+	}
+
+	state.SetTunnel("conn-1", second)
+	if first.closed != 1 {
+		t.Errorf("Replaced tunnel closed %d times, want 1", first.closed)
+	}
+
+	state.RemoveClient("conn-1")
+	if second.closed != 1 {
+		t.Errorf("Tunnel closed %d times after RemoveClient, want 1", second.closed)
+	}
+	if _, ok := state.Tunnels["conn-1"]; ok {
+		t.Error("Tunnel should be forgotten after RemoveClient")
+	}
+
+	state.SetTunnel("conn-1", nil)
+	if len(state.Tunnels) != 0 {
+		t.Error("A nil tunnel must not be registered")
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	state := NewAppState()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "prod", ReadOnly: true},
+		{ID: "dev"},
+	}
+
+	err := state.CheckWritable("prod", "drop database")
+	var readOnly *ReadOnlyError
+	if !errors.As(err, &readOnly) || readOnly.ConnID != "prod" || readOnly.Operation != "drop database" {
+		t.Fatalf("Expected ReadOnlyError for prod, got %v", err)
+	}
+	if err.Error() != "connection is read-only: drop database is not allowed" {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+
+	if err := state.CheckWritable("dev", "drop database"); err != nil {
+		t.Errorf("Expected dev to be writable, got %v", err)
+	}
+	if err := state.CheckWritable("unknown", "drop database"); err != nil {
+		t.Errorf("Expected unknown connections to be writable, got %v", err)
+	}
+}
+
+func TestDatabaseAccess(t *testing.T) {
+	state := NewAppState()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "team", AllowedDatabases: []string{"app", "team_*"}, DeniedDatabases: []string{"team_secrets"}},
+		{ID: "deny", DeniedDatabases: []string{"admin"}},
+		{ID: "open"},
+	}
+
+	tests := []struct {
+		connID string
+		dbName string
+		want   bool
+	}{
+		{"team", "app", true},
+		{"team", "team_billing", true},
+		{"team", "team_secrets", false},
+		{"team", "other", false},
+		{"deny", "admin", false},
+		{"deny", "other", true},
+		{"open", "anything", true},
+		{"unknown", "anything", true},
+	}
+	for _, tt := range tests {
+		if got := state.DatabaseAllowed(tt.connID, tt.dbName); got != tt.want {
+			t.Errorf("DatabaseAllowed(%q, %q) = %v, want %v", tt.connID, tt.dbName, got, tt.want)
+		}
+	}
+
+	if got := state.FilterDatabases("team", []string{"admin", "app", "team_a", "team_secrets"}); strings.Join(got, ",") != "app,team_a" {
+		t.Errorf("FilterDatabases = %v", got)
+	}
+	if !state.HasDatabaseRestrictions("deny") || state.HasDatabaseRestrictions("open") {
+		t.Error("HasDatabaseRestrictions reported the wrong connections")
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	state.Clients["team"] = client
+	if _, err := state.GetDatabaseClient("team", "app"); err != nil {
+		t.Errorf("Expected app to be accessible, got %v", err)
+	}
+	_, err = state.GetDatabaseClient("team", "other")
+	var denied *DatabaseAccessError
+	if !errors.As(err, &denied) || denied.Database != "other" {
+		t.Errorf("Expected DatabaseAccessError, got %v", err)
+	}
+	var notConnected *NotConnectedError
+	if _, err := state.GetDatabaseClient("deny", "admin"); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError before the access check, got %v", err)
+	}
+}
+
+func TestValidateDatabasePatterns(t *testing.T) {
+	if err := ValidateDatabasePatterns([]string{"app", "team_*", "db?"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateDatabasePatterns([]string{"[bad"}); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+	if err := ValidateDatabasePatterns([]string{""}); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+}
+
+func TestDestructiveConfirmation(t *testing.T) {
+	state := NewAppState()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "prod", DestructiveDelay: 5, RequireDeleteConfirmation: true},
+		{ID: "dev"},
+	}
+
+	if c := state.RequestConfirmation("dev", "drop database", "shop"); c.Required || c.Token != "" {
+		t.Errorf("Expected no confirmation for dev, got %+v", c)
+	}
+	if err := state.RequireConfirmation("dev", "drop database", "shop"); err != nil {
+		t.Errorf("Expected dev to need no confirmation, got %v", err)
+	}
+
+	var required *ConfirmationRequiredError
+	if err := state.RequireConfirmation("prod", "drop database", "shop"); !errors.As(err, &required) {
+		t.Fatalf("Expected ConfirmationRequiredError without a token, got %v", err)
+	}
+
+	c := state.RequestConfirmation("prod", "drop database", "shop")
+	if !c.Required || c.Token == "" || c.DelaySeconds != 5 || c.ConfirmationText != DeleteConfirmationText {
+		t.Fatalf("Unexpected confirmation: %+v", c)
+	}
+	if err := state.ConfirmOperation(c.Token, DeleteConfirmationText); err == nil || !strings.Contains(err.Error(), "not valid yet") {
+		t.Fatalf("Expected the delay to be enforced, got %v", err)
+	}
+
+	// Skip the delay
+	state.Confirmations[c.Token].ValidAfter = time.Now().Add(-time.Second)
+	if err := state.ConfirmOperation(c.Token, "delete"); err == nil || !strings.Contains(err.Error(), "type DELETE") {
+		t.Fatalf("Expected the typed text to be checked, got %v", err)
+	}
+	if err := state.RequireConfirmation("prod", "drop database", "shop"); !errors.As(err, &required) {
+		t.Fatalf("Expected an unconfirmed token to be rejected, got %v", err)
+	}
+	if err := state.ConfirmOperation(c.Token, DeleteConfirmationText); err != nil {
+		t.Fatalf("Unexpected error confirming: %v", err)
+	}
+
+	if err := state.RequireConfirmation("prod", "drop database", "other"); !errors.As(err, &required) {
+		t.Errorf("Expected a token for another target to be rejected, got %v", err)
+	}
+	if err := state.RequireConfirmation("prod", "drop database", "shop"); err != nil {
+		t.Errorf("Expected the confirmed token to authorize the drop, got %v", err)
+	}
+	if err := state.RequireConfirmation("prod", "drop database", "shop"); !errors.As(err, &required) {
+		t.Errorf("Expected the token to be single use, got %v", err)
+	}
+
+	expired := state.RequestConfirmation("prod", "clear collection", "shop.orders")
+	state.Confirmations[expired.Token].ExpiresAt = time.Now().Add(-time.Second)
+	if err := state.ConfirmOperation(expired.Token, DeleteConfirmationText); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected an expired token to be rejected, got %v", err)
+	}
+}
+
+func TestEnvironmentPolicy(t *testing.T) {
+	state := NewAppState()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "prod", Environment: EnvProd},
+		{ID: "prod-slow", Environment: EnvProd, DestructiveDelay: 30},
+		{ID: "dev", Environment: EnvDev},
+	}
+
+	c := state.RequestConfirmation("prod", "drop database", "shop")
+	if !c.Required || c.DelaySeconds != 5 || c.ConfirmationText != DeleteConfirmationText {
+		t.Errorf("Expected prod to require typed confirmation after the default delay, got %+v", c)
+	}
+	if c := state.RequestConfirmation("prod-slow", "drop database", "shop"); c.DelaySeconds != 30 {
+		t.Errorf("Expected the longer connection delay to win, got %d", c.DelaySeconds)
+	}
+	if c := state.RequestConfirmation("dev", "drop database", "shop"); c.Required {
+		t.Errorf("Expected dev to need no confirmation, got %+v", c)
+	}
+
+	if state.IsReadOnly("prod") {
+		t.Error("Expected prod to be writable by default")
+	}
+	settings := state.GetSettings()
+	settings.ProdReadOnly = true
+	state.SetSettings(settings)
+	if !state.IsReadOnly("prod") || state.IsReadOnly("dev") {
+		t.Error("Expected ProdReadOnly to make only prod connections read-only")
+	}
+}
+
+func TestValidateEnvironment(t *testing.T) {
+	for _, env := range []string{"", EnvProd, EnvStaging, EnvDev} {
+		if err := ValidateEnvironment(env); err != nil {
+			t.Errorf("ValidateEnvironment(%q) = %v", env, err)
+		}
+	}
+	if err := ValidateEnvironment("production"); err == nil {
+		t.Error("Expected an error for an unknown environment")
+	}
+}
+
+// recordingEmitter records the last event emitted through it.
+type recordingEmitter struct {
+	name string
+	data interface{}
+}
+
+func (e *recordingEmitter) Emit(eventName string, data interface{}) {
+	e.name, e.data = eventName, data
+}
+
+func TestEmitConnectionEvent(t *testing.T) {
+	state := NewAppState()
+	emitter := &recordingEmitter{}
+	state.Emitter = emitter
+	state.SavedConnections = []types.SavedConnection{{ID: "prod", Environment: EnvProd}, {ID: "plain"}}
+
+	progress := types.DeleteProgress{OperationID: "op-1", Deleted: 3}
+	state.EmitConnectionEvent("plain", "delete:progress", progress)
+	if emitter.data != progress {
+		t.Errorf("Expected untagged payloads to pass through, got %#v", emitter.data)
+	}
+
+	state.EmitConnectionEvent("prod", "delete:progress", progress)
+	tagged, ok := emitter.data.(map[string]interface{})
+	if !ok || tagged["environment"] != EnvProd || tagged["operationId"] != "op-1" || tagged["deleted"] != float64(3) {
+		t.Errorf("Expected the struct fields plus the environment, got %#v", emitter.data)
+	}
+
+	state.EmitConnectionEvent("prod", "export:paused", nil)
+	if tagged := emitter.data.(map[string]interface{}); len(tagged) != 1 || tagged["environment"] != EnvProd {
+		t.Errorf("Expected only the environment for a nil payload, got %#v", tagged)
+	}
+}
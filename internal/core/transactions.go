@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transaction is an open multi-document transaction started from the UI.
+type Transaction struct {
+	Token   string
+	ConnID  string
+	Session mongo.Session
+	Started time.Time
+
+	// Mu serializes operations on the transaction; a session is not safe for concurrent use.
+	Mu sync.Mutex
+	// Operations counts the writes applied so far (guarded by Mu).
+	Operations int
+	// AfterCommit holds work deferred until the transaction commits (guarded by Mu).
+	AfterCommit []func()
+}
+
+// AddTransaction registers an open transaction under its token.
+func (s *AppState) AddTransaction(txn *Transaction) {
+	s.TxnMu.Lock()
+	defer s.TxnMu.Unlock()
+	s.Transactions[txn.Token] = txn
+}
+
+// GetTransaction returns the open transaction for a token.
+func (s *AppState) GetTransaction(token string) (*Transaction, error) {
+	s.TxnMu.Lock()
+	defer s.TxnMu.Unlock()
+	txn, ok := s.Transactions[token]
+	if !ok {
+		return nil, &TransactionNotFoundError{Token: token}
+	}
+	return txn, nil
+}
+
+// TakeTransaction removes and returns the open transaction for a token, so exactly one
+// caller gets to commit or abort it.
+func (s *AppState) TakeTransaction(token string) (*Transaction, error) {
+	s.TxnMu.Lock()
+	defer s.TxnMu.Unlock()
+	txn, ok := s.Transactions[token]
+	if !ok {
+		return nil, &TransactionNotFoundError{Token: token}
+	}
+	delete(s.Transactions, token)
+	return txn, nil
+}
+
+// abortTransactions aborts every open transaction on a connection. Called before the
+// connection's client is disconnected or replaced; emits "transaction:aborted" for each.
+func (s *AppState) abortTransactions(connID string) {
+	s.TxnMu.Lock()
+	var open []*Transaction
+	for token, txn := range s.Transactions {
+		if txn.ConnID == connID {
+			open = append(open, txn)
+			delete(s.Transactions, token)
+		}
+	}
+	s.TxnMu.Unlock()
+
+	for _, txn := range open {
+		txn.Mu.Lock()
+		ctx, cancel := s.ContextWithTimeout()
+		txn.Session.AbortTransaction(ctx)
+		txn.Session.EndSession(ctx)
+		cancel()
+		txn.Mu.Unlock()
+		s.EmitConnectionEvent(connID, "transaction:aborted", map[string]interface{}{
+			"token":        txn.Token,
+			"connectionId": connID,
+			"reason":       "disconnected",
+		})
+	}
+}
+
+// Context returns a context bound to a transaction's session.
+func (txn *Transaction) Context(ctx context.Context) context.Context {
+	return mongo.NewSessionContext(ctx, txn.Session)
+}
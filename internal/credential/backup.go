@@ -0,0 +1,81 @@
+package credential
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	backupApp     = "mongopal"
+	backupVersion = 1
+)
+
+// ErrWrongBackupPassphrase is returned when a backup cannot be decrypted with the given
+// passphrase.
+var ErrWrongBackupPassphrase = errors.New("wrong backup passphrase")
+
+// backupFile is the format of a passphrase protected backup. Data is encrypted with
+// AES-256-GCM under a key derived from the passphrase like a vault's; the nonce is prepended.
+type backupFile struct {
+	App     string `json:"app"`
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Data    []byte `json:"data"`
+}
+
+// EncryptBackup encrypts plaintext with a passphrase and returns the backup file content.
+func EncryptBackup(plaintext []byte, passphrase string) ([]byte, error) {
+	if len(passphrase) < minMasterPassword {
+		return nil, fmt.Errorf("backup passphrase must be at least %d characters", minMasterPassword)
+	}
+	salt := make([]byte, vaultSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := newGCM(deriveVaultKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data, err := json.Marshal(backupFile{
+		App:     backupApp,
+		Version: backupVersion,
+		Salt:    salt,
+		Data:    gcm.Seal(nonce, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup: %w", err)
+	}
+	return data, nil
+}
+
+// DecryptBackup decrypts the content of a backup file created by EncryptBackup.
+func DecryptBackup(content []byte, passphrase string) ([]byte, error) {
+	var file backupFile
+	if err := json.Unmarshal(content, &file); err != nil || file.App != backupApp {
+		return nil, fmt.Errorf("not a MongoPal backup file")
+	}
+	if file.Version != backupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d (update MongoPal to restore)", file.Version)
+	}
+
+	gcm, err := newGCM(deriveVaultKey(passphrase, file.Salt))
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("corrupted backup: too short")
+	}
+	nonce, ciphertext := file.Data[:gcm.NonceSize()], file.Data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongBackupPassphrase
+	}
+	return plaintext, nil
+}
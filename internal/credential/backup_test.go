@@ -0,0 +1,31 @@
+package credential
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBackup_RoundTrip(t *testing.T) {
+	data, err := EncryptBackup([]byte(`{"secret": true}`), "correct horse")
+	if err != nil {
+		t.Fatalf("EncryptBackup: %v", err)
+	}
+
+	plaintext, err := DecryptBackup(data, "correct horse")
+	if err != nil {
+		t.Fatalf("DecryptBackup: %v", err)
+	}
+	if string(plaintext) != `{"secret": true}` {
+		t.Errorf("plaintext = %s", plaintext)
+	}
+
+	if _, err := DecryptBackup(data, "wrong horse"); !errors.Is(err, ErrWrongBackupPassphrase) {
+		t.Errorf("expected ErrWrongBackupPassphrase, got %v", err)
+	}
+	if _, err := DecryptBackup([]byte(`{"app": "other"}`), "correct horse"); err == nil {
+		t.Error("expected an error for a foreign file")
+	}
+	if _, err := EncryptBackup([]byte("x"), "short"); err == nil {
+		t.Error("expected an error for a short passphrase")
+	}
+}
@@ -5,23 +5,29 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-
-	"github.com/zalando/go-keyring"
+	"sort"
+	"sync"
 )
 
 const (
 	encryptionKeyringService = "mongopal"
 	encryptionKeyPrefix      = "mongopal-key-"
 	encryptedFileExt         = ".encrypted"
+	keyIndexFile             = "keyring_index.json"
 )
 
-// EncryptedStorage handles AES-256-GCM encryption/decryption with OS keyring keys.
+// EncryptedStorage handles AES-256-GCM encryption/decryption with per-connection keys held
+// in a KeyStore: the OS keyring, or the credential vault where no keyring is available.
 type EncryptedStorage struct {
 	storageDir string
+	indexMu    sync.Mutex // Guards the keyring index file
+	keysMu     sync.RWMutex
+	keys       KeyStore
 }
 
 // NewEncryptedStorage creates a new encrypted storage instance.
@@ -34,15 +40,31 @@ func NewEncryptedStorage(storageDir string) (*EncryptedStorage, error) {
 
 	return &EncryptedStorage{
 		storageDir: storageDir,
+		keys:       OSKeyring{},
 	}, nil
 }
 
-// getOrCreateEncryptionKey retrieves or creates a 32-byte AES-256 key from OS keyring.
+// keyStore returns the KeyStore currently holding the encryption keys.
+func (s *EncryptedStorage) keyStore() KeyStore {
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
+	return s.keys
+}
+
+// SetKeyStore replaces the KeyStore of the encryption keys without moving any keys; see
+// MigrateKeys.
+func (s *EncryptedStorage) SetKeyStore(keys KeyStore) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	s.keys = keys
+}
+
+// getOrCreateEncryptionKey retrieves or creates a 32-byte AES-256 key from the key store.
 func (s *EncryptedStorage) getOrCreateEncryptionKey(connID string) ([]byte, error) {
 	keyName := encryptionKeyPrefix + connID
 
 	// Try to retrieve existing key
-	keyStr, err := keyring.Get(encryptionKeyringService, keyName)
+	keyStr, err := s.keyStore().Get(keyName)
 	if err == nil {
 		// Key exists, decode from hex
 		key := []byte(keyStr)
@@ -50,11 +72,13 @@ func (s *EncryptedStorage) getOrCreateEncryptionKey(connID string) ([]byte, erro
 			// Invalid key length, regenerate
 			return s.createNewKey(keyName)
 		}
+		// Backfill the index for keys created before it existed
+		s.addToKeyIndex(connID)
 		return key, nil
 	}
 
 	// Key doesn't exist (or keyring unavailable), create new one
-	if err == keyring.ErrNotFound {
+	if errors.Is(err, ErrKeyNotFound) {
 		return s.createNewKey(keyName)
 	}
 
@@ -63,7 +87,7 @@ func (s *EncryptedStorage) getOrCreateEncryptionKey(connID string) ([]byte, erro
 	return key, fmt.Errorf("keyring unavailable: %w", err)
 }
 
-// createNewKey generates a new 32-byte random key and stores it in keyring.
+// createNewKey generates a new 32-byte random key and stores it in the key store.
 func (s *EncryptedStorage) createNewKey(keyName string) ([]byte, error) {
 	key := make([]byte, 32) // AES-256 requires 32-byte key
 	if _, err := rand.Read(key); err != nil {
@@ -71,25 +95,154 @@ func (s *EncryptedStorage) createNewKey(keyName string) ([]byte, error) {
 	}
 
 	// Store in keyring (best effort - may fail on some platforms)
-	if err := keyring.Set(encryptionKeyringService, keyName, string(key)); err != nil {
+	if err := s.keyStore().Set(keyName, string(key)); err != nil {
 		// Don't fail if keyring unavailable - key will be regenerated on each load
 		// This means encrypted files won't be portable, but better than no encryption
 		return key, fmt.Errorf("keyring unavailable (encryption key not persistent): %w", err)
 	}
 
+	s.addToKeyIndex(keyName[len(encryptionKeyPrefix):])
 	return key, nil
 }
 
-// deleteEncryptionKey removes the encryption key from OS keyring.
+// deleteEncryptionKey removes the encryption key from the key store.
 func (s *EncryptedStorage) deleteEncryptionKey(connID string) error {
 	keyName := encryptionKeyPrefix + connID
-	err := keyring.Delete(encryptionKeyringService, keyName)
-	if err == keyring.ErrNotFound {
+	err := s.keyStore().Delete(keyName)
+	if errors.Is(err, ErrKeyNotFound) {
+		s.removeFromKeyIndex(connID)
 		return nil // Already gone
 	}
+	if err == nil {
+		s.removeFromKeyIndex(connID)
+	}
 	return err
 }
 
+// =============================================================================
+// Keyring Index
+// =============================================================================
+
+// The OS keyring cannot be enumerated portably, so connection IDs that have a
+// keyring entry are tracked in an index file next to the encrypted files.
+
+// keyIndexPath returns the path to the keyring index file.
+func (s *EncryptedStorage) keyIndexPath() string {
+	return filepath.Join(s.storageDir, keyIndexFile)
+}
+
+// loadKeyIndex reads the keyring index. Caller must hold indexMu.
+func (s *EncryptedStorage) loadKeyIndex() map[string]bool {
+	index := make(map[string]bool)
+	data, err := os.ReadFile(s.keyIndexPath())
+	if err != nil {
+		return index
+	}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return index
+	}
+	for _, id := range ids {
+		index[id] = true
+	}
+	return index
+}
+
+// saveKeyIndex writes the keyring index. Caller must hold indexMu.
+func (s *EncryptedStorage) saveKeyIndex(index map[string]bool) error {
+	ids := make([]string, 0, len(index))
+	for id := range index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.keyIndexPath(), data, 0600)
+}
+
+// addToKeyIndex records that a keyring entry exists for connID (best effort).
+func (s *EncryptedStorage) addToKeyIndex(connID string) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	index := s.loadKeyIndex()
+	if index[connID] {
+		return
+	}
+	index[connID] = true
+	_ = s.saveKeyIndex(index)
+}
+
+// removeFromKeyIndex forgets the keyring entry for connID (best effort).
+func (s *EncryptedStorage) removeFromKeyIndex(connID string) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	index := s.loadKeyIndex()
+	if !index[connID] {
+		return
+	}
+	delete(index, connID)
+	_ = s.saveKeyIndex(index)
+}
+
+// ListKeyringConnectionIDs returns the connection IDs known to have a keyring entry.
+func (s *EncryptedStorage) ListKeyringConnectionIDs() []string {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	index := s.loadKeyIndex()
+	ids := make([]string, 0, len(index))
+	for id := range index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// IndexKeys adds the connections of connIDs that have a key in the key store to the keyring
+// index, so DeleteOrphanedKeys also sees keys created before the index existed. Returns the
+// number of keys added.
+func (s *EncryptedStorage) IndexKeys(connIDs []string) int {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	index := s.loadKeyIndex()
+	added := 0
+	for _, connID := range connIDs {
+		if connID == "" || index[connID] {
+			continue
+		}
+		if _, err := s.keyStore().Get(encryptionKeyPrefix + connID); err != nil {
+			continue
+		}
+		index[connID] = true
+		added++
+	}
+	if added > 0 {
+		_ = s.saveKeyIndex(index)
+	}
+	return added
+}
+
+// DeleteOrphanedKeys removes keyring entries for connections that are neither in
+// validIDs nor backed by an encrypted file. Returns the number of entries removed.
+func (s *EncryptedStorage) DeleteOrphanedKeys(validIDs map[string]bool) (int, error) {
+	removed := 0
+	var firstErr error
+	for _, connID := range s.ListKeyringConnectionIDs() {
+		if validIDs[connID] || s.ConnectionExists(connID) {
+			continue
+		}
+		if err := s.deleteEncryptionKey(connID); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete keyring entry for %s: %w", connID, err)
+			}
+			continue
+		}
+		removed++
+	}
+	return removed, firstErr
+}
+
 // encryptData encrypts data using AES-256-GCM with the connection's key.
 func (s *EncryptedStorage) encryptData(connID string, data []byte) ([]byte, error) {
 	key, err := s.getOrCreateEncryptionKey(connID)
@@ -255,3 +408,104 @@ func (s *EncryptedStorage) ConnectionExists(connID string) bool {
 	_, err := os.Stat(filePath)
 	return err == nil
 }
+
+// =============================================================================
+// Credential Vault
+// =============================================================================
+
+// VaultPath returns the path of the credential vault file.
+func (s *EncryptedStorage) VaultPath() string {
+	return filepath.Join(s.storageDir, vaultFileName)
+}
+
+// UsingVault reports whether the encryption keys are held in the credential vault.
+func (s *EncryptedStorage) UsingVault() bool {
+	_, ok := s.keyStore().(*Vault)
+	return ok
+}
+
+// UnlockVault opens the credential vault with masterPassword and uses it for the
+// encryption keys.
+func (s *EncryptedStorage) UnlockVault(masterPassword string) error {
+	vault, err := OpenVault(s.VaultPath(), masterPassword)
+	if err != nil {
+		return err
+	}
+	s.SetKeyStore(vault)
+	return nil
+}
+
+// CreateVault creates the credential vault and moves the encryption keys into it from the
+// OS keyring. Returns the number of keys moved.
+func (s *EncryptedStorage) CreateVault(masterPassword string) (int, error) {
+	vault, err := CreateVault(s.VaultPath(), masterPassword)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := s.MigrateKeys(vault)
+	if err != nil {
+		os.Remove(s.VaultPath())
+		return 0, err
+	}
+	return moved, nil
+}
+
+// MigrateToKeyring moves the encryption keys from the unlocked credential vault back to
+// the OS keyring and removes the vault. Returns the number of keys moved.
+func (s *EncryptedStorage) MigrateToKeyring() (int, error) {
+	if !s.UsingVault() {
+		return 0, fmt.Errorf("the credential vault is not in use")
+	}
+	if !KeyringAvailable() {
+		return 0, fmt.Errorf("the OS keyring is not available")
+	}
+	moved, err := s.MigrateKeys(OSKeyring{})
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Remove(s.VaultPath()); err != nil && !os.IsNotExist(err) {
+		return moved, fmt.Errorf("keys moved, but failed to remove the credential vault: %w", err)
+	}
+	return moved, nil
+}
+
+// MigrateKeys copies every connection's encryption key to target and switches to it. Keys
+// are removed from the previous store only after all were copied, so a failure leaves the
+// current store in use and intact. Returns the number of keys moved.
+func (s *EncryptedStorage) MigrateKeys(target KeyStore) (int, error) {
+	source := s.keyStore()
+
+	ids := make(map[string]bool)
+	for _, id := range s.ListKeyringConnectionIDs() {
+		ids[id] = true
+	}
+	fileIDs, err := s.ListConnectionIDs()
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range fileIDs {
+		ids[id] = true
+	}
+
+	var moved []string
+	for id := range ids {
+		keyName := encryptionKeyPrefix + id
+		key, err := source.Get(keyName)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read key of connection %s: %w", id, err)
+		}
+		if err := target.Set(keyName, key); err != nil {
+			return 0, fmt.Errorf("failed to store key of connection %s: %w", id, err)
+		}
+		moved = append(moved, keyName)
+	}
+
+	s.SetKeyStore(target)
+	for _, keyName := range moved {
+		_ = source.Delete(keyName) // Best effort: the key is safe in target
+	}
+	return len(moved), nil
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/zalando/go-keyring"
 )
 
 type testConnection struct {
@@ -239,3 +241,76 @@ MIIEpAIBAAKCAQEA0Z0...
 		}
 	}
 }
+
+func TestEncryptedStorage_DeleteOrphanedKeys(t *testing.T) {
+	keyring.MockInit()
+
+	tmpDir := t.TempDir()
+	storage, err := NewEncryptedStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create encrypted storage: %v", err)
+	}
+
+	for _, id := range []string{"live", "file-only", "orphan"} {
+		if err := storage.SaveConnection(id, testConnection{ID: id}); err != nil {
+			t.Fatalf("Failed to save connection %s: %v", id, err)
+		}
+	}
+
+	// Simulate a connection deleted outside the app: file gone, keyring entry left behind
+	if err := os.Remove(filepath.Join(tmpDir, "orphan"+encryptedFileExt)); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	removed, err := storage.DeleteOrphanedKeys(map[string]bool{"live": true})
+	if err != nil {
+		t.Fatalf("DeleteOrphanedKeys failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 orphan removed, got %d", removed)
+	}
+
+	if _, err := keyring.Get(encryptionKeyringService, encryptionKeyPrefix+"orphan"); err != keyring.ErrNotFound {
+		t.Errorf("Expected orphan key to be deleted, got err=%v", err)
+	}
+	// A connection with an encrypted file is never orphaned, even if not in the valid set
+	if _, err := keyring.Get(encryptionKeyringService, encryptionKeyPrefix+"file-only"); err != nil {
+		t.Errorf("Expected file-backed key to be kept, got err=%v", err)
+	}
+
+	ids := storage.ListKeyringConnectionIDs()
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 indexed keys after cleanup, got %v", ids)
+	}
+}
+
+func TestEncryptedStorage_DeleteOrphanedKeys_BeforeIndex(t *testing.T) {
+	keyring.MockInit()
+
+	storage, err := NewEncryptedStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create encrypted storage: %v", err)
+	}
+
+	// A key left behind by a connection deleted before the keyring index existed
+	if err := keyring.Set(encryptionKeyringService, encryptionKeyPrefix+"legacy", "0123456789abcdef0123456789abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	if removed, _ := storage.DeleteOrphanedKeys(nil); removed != 0 {
+		t.Fatalf("Expected the unindexed key to be invisible, got %d removed", removed)
+	}
+
+	if added := storage.IndexKeys([]string{"legacy", "never-existed", "legacy", ""}); added != 1 {
+		t.Errorf("Expected 1 key indexed, got %d", added)
+	}
+	removed, err := storage.DeleteOrphanedKeys(nil)
+	if err != nil {
+		t.Fatalf("DeleteOrphanedKeys failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 orphan removed, got %d", removed)
+	}
+	if _, err := keyring.Get(encryptionKeyringService, encryptionKeyPrefix+"legacy"); err != keyring.ErrNotFound {
+		t.Errorf("Expected legacy key to be deleted, got err=%v", err)
+	}
+}
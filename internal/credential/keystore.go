@@ -0,0 +1,57 @@
+package credential
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// ErrKeyNotFound is returned by a KeyStore for names it holds no key for.
+var ErrKeyNotFound = errors.New("key not found")
+
+// keyringProbeName is written and removed to check that the OS keyring works.
+const keyringProbeName = "mongopal-probe"
+
+// KeyStore holds the per-connection encryption keys of EncryptedStorage: the OS keyring by
+// default, or a Vault on systems without a keyring service.
+type KeyStore interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+}
+
+// OSKeyring stores keys in the OS keyring (Keychain, Credential Manager, Secret Service).
+type OSKeyring struct{}
+
+// Get returns the key stored under name.
+func (OSKeyring) Get(name string) (string, error) {
+	value, err := keyring.Get(encryptionKeyringService, name)
+	if err == keyring.ErrNotFound {
+		return "", ErrKeyNotFound
+	}
+	return value, err
+}
+
+// Set stores value under name.
+func (OSKeyring) Set(name, value string) error {
+	return keyring.Set(encryptionKeyringService, name, value)
+}
+
+// Delete removes the key stored under name.
+func (OSKeyring) Delete(name string) error {
+	err := keyring.Delete(encryptionKeyringService, name)
+	if err == keyring.ErrNotFound {
+		return ErrKeyNotFound
+	}
+	return err
+}
+
+// KeyringAvailable reports whether the OS keyring can store keys. On Linux without a
+// Secret Service daemon every keyring call fails.
+func KeyringAvailable() bool {
+	if err := keyring.Set(encryptionKeyringService, keyringProbeName, "ok"); err != nil {
+		return false
+	}
+	_ = keyring.Delete(encryptionKeyringService, keyringProbeName)
+	return true
+}
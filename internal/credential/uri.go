@@ -1,6 +1,7 @@
 package credential
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
@@ -60,6 +61,7 @@ var authMechanismMap = map[string]string{
 	"x509":          "MONGODB-X509",
 	"mongodb-aws":   "MONGODB-AWS",
 	"kerberos":      "GSSAPI",
+	"plain":         "PLAIN",
 }
 
 // BuildURIFromFormData constructs a MongoDB URI from stored form data.
@@ -97,20 +99,18 @@ func BuildURIFromFormData(fd *types.ConnectionFormData, password string) string
 			b.WriteString("localhost:27017")
 		}
 	default: // replicaset, sharded
-		for i, hp := range fd.Hosts {
-			if hp.Host == "" {
-				continue
-			}
-			if i > 0 {
-				b.WriteByte(',')
+		var hosts []string
+		for _, hp := range fd.Hosts {
+			if hp.Host != "" {
+				hosts = append(hosts, formatHost(hp.Host, hp.Port))
 			}
-			b.WriteString(formatHost(hp.Host, hp.Port))
 		}
+		b.WriteString(strings.Join(hosts, ","))
 	}
 
 	// Database path
 	b.WriteByte('/')
-	b.WriteString(fd.DefaultDatabase)
+	b.WriteString(url.PathEscape(fd.DefaultDatabase))
 
 	// Query parameters — collect non-default values
 	var params []string
@@ -124,9 +124,17 @@ func BuildURIFromFormData(fd *types.ConnectionFormData, password string) string
 			addParam("authMechanism", driverName)
 		}
 	}
-	if fd.AuthDatabase != "" && fd.AuthDatabase != "admin" {
+	if fd.AuthMechanism == "plain" {
+		// LDAP users only exist in $external; "admin" is just the form default
+		addParam("authSource", "$external")
+	} else if fd.AuthDatabase != "" && fd.AuthDatabase != "admin" {
 		addParam("authSource", url.PathEscape(fd.AuthDatabase))
 	}
+	if fd.AuthMechanism == "kerberos" {
+		if props := gssapiProperties(fd); props != "" {
+			addParam("authMechanismProperties", props)
+		}
+	}
 
 	// Direct connection for standalone
 	if fd.ConnectionType == "standalone" {
@@ -135,7 +143,7 @@ func BuildURIFromFormData(fd *types.ConnectionFormData, password string) string
 
 	// Replica set name
 	if fd.ConnectionType == "replicaset" && fd.ReplicaSetName != "" {
-		addParam("replicaSet", fd.ReplicaSetName)
+		addParam("replicaSet", url.QueryEscape(fd.ReplicaSetName))
 	}
 
 	// TLS
@@ -207,6 +215,52 @@ func BuildURIFromFormData(fd *types.ConnectionFormData, password string) string
 	return b.String()
 }
 
+// BuildURIFromFormJSON constructs a MongoDB URI from the JSON form data stored with a
+// connection (ExtendedConnection.FormData).
+func BuildURIFromFormJSON(formJSON, password string) (string, error) {
+	var fd types.ConnectionFormData
+	if err := json.Unmarshal([]byte(formJSON), &fd); err != nil {
+		return "", fmt.Errorf("invalid connection form data: %w", err)
+	}
+	return BuildURIFromFormData(&fd, password), nil
+}
+
+// SetURIDatabase replaces the database path of a MongoDB URI, keeping the credentials and
+// query parameters exactly as they are.
+func SetURIDatabase(uri, database string) string {
+	schemeEnd := strings.Index(uri, "://")
+	if schemeEnd < 0 {
+		return uri
+	}
+	rest := uri[schemeEnd+3:]
+	// The password may contain '/' or '?' only when escaped, so the hosts end at the first one
+	hostsEnd := strings.IndexAny(rest, "/?")
+	if hostsEnd < 0 {
+		hostsEnd = len(rest)
+	}
+	query := ""
+	if qIdx := strings.Index(rest[hostsEnd:], "?"); qIdx >= 0 {
+		query = rest[hostsEnd+qIdx:]
+	}
+	return uri[:schemeEnd+3] + rest[:hostsEnd] + "/" + url.PathEscape(database) + query
+}
+
+// gssapiProperties returns the authMechanismProperties of Kerberos authentication, omitting
+// defaults. Values are escaped since ',' and ':' separate properties.
+func gssapiProperties(fd *types.ConnectionFormData) string {
+	var props []string
+	if fd.GSSAPIServiceName != "" && fd.GSSAPIServiceName != "mongodb" {
+		props = append(props, "SERVICE_NAME:"+url.QueryEscape(fd.GSSAPIServiceName))
+	}
+	if fd.GSSAPIServiceRealm != "" {
+		props = append(props, "SERVICE_REALM:"+url.QueryEscape(fd.GSSAPIServiceRealm))
+	}
+	if fd.GSSAPICanonicalizeHost {
+		props = append(props, "CANONICALIZE_HOST_NAME:true")
+	}
+	return strings.Join(props, ",")
+}
+
 // formatHost formats a host:port pair, handling IPv6 addresses.
 func formatHost(host string, port int) string {
 	// Wrap IPv6 in brackets if not already wrapped
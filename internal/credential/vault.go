@@ -0,0 +1,214 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	vaultFileName      = "credentials.vault"
+	vaultVersion       = 1
+	vaultSaltSize      = 16
+	minMasterPassword  = 8
+	vaultArgonTime     = 3
+	vaultArgonMemoryKB = 64 * 1024
+	vaultArgonThreads  = 4
+)
+
+// ErrWrongMasterPassword is returned when a vault cannot be decrypted with the given password.
+var ErrWrongMasterPassword = errors.New("wrong master password")
+
+// vaultFile is the on-disk format of a vault. Data is the JSON of the entries, encrypted with
+// AES-256-GCM under a key derived from the master password with Argon2id; the nonce is
+// prepended.
+type vaultFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Data    []byte `json:"data"`
+}
+
+// Vault is a KeyStore in an encrypted file, for systems where the OS keyring is unavailable.
+// It is unlocked with a master password and keeps its entries in memory once opened.
+type Vault struct {
+	path    string
+	mu      sync.Mutex
+	salt    []byte
+	key     []byte
+	entries map[string][]byte // Keys are raw bytes; []byte keeps them intact through JSON
+}
+
+// VaultExists reports whether a vault file exists at path.
+func VaultExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// CreateVault creates an empty vault at path protected by masterPassword.
+func CreateVault(path, masterPassword string) (*Vault, error) {
+	if len(masterPassword) < minMasterPassword {
+		return nil, fmt.Errorf("master password must be at least %d characters", minMasterPassword)
+	}
+	if VaultExists(path) {
+		return nil, fmt.Errorf("a credential vault already exists")
+	}
+
+	salt := make([]byte, vaultSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	v := &Vault{
+		path:    path,
+		salt:    salt,
+		key:     deriveVaultKey(masterPassword, salt),
+		entries: make(map[string][]byte),
+	}
+	if err := v.save(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// OpenVault decrypts the vault at path with masterPassword.
+func OpenVault(path, masterPassword string) (*Vault, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no credential vault found")
+		}
+		return nil, fmt.Errorf("failed to read credential vault: %w", err)
+	}
+	var file vaultFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("corrupted credential vault: %w", err)
+	}
+	if file.Version != vaultVersion {
+		return nil, fmt.Errorf("unsupported credential vault version %d", file.Version)
+	}
+
+	key := deriveVaultKey(masterPassword, file.Salt)
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("corrupted credential vault: too short")
+	}
+	nonce, ciphertext := file.Data[:gcm.NonceSize()], file.Data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongMasterPassword
+	}
+
+	entries := make(map[string][]byte)
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("corrupted credential vault: %w", err)
+	}
+	return &Vault{path: path, salt: file.Salt, key: key, entries: entries}, nil
+}
+
+// Get returns the key stored under name.
+func (v *Vault) Get(name string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	value, ok := v.entries[name]
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return string(value), nil
+}
+
+// Set stores value under name and rewrites the vault file.
+func (v *Vault) Set(name, value string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	previous, existed := v.entries[name]
+	v.entries[name] = []byte(value)
+	if err := v.save(); err != nil {
+		if existed {
+			v.entries[name] = previous
+		} else {
+			delete(v.entries, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the key stored under name and rewrites the vault file.
+func (v *Vault) Delete(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	previous, ok := v.entries[name]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	delete(v.entries, name)
+	if err := v.save(); err != nil {
+		v.entries[name] = previous
+		return err
+	}
+	return nil
+}
+
+// save encrypts the entries and replaces the vault file. Caller must hold mu, except
+// during creation.
+func (v *Vault) save() error {
+	plaintext, err := json.Marshal(v.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode credential vault: %w", err)
+	}
+	gcm, err := newGCM(v.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data, err := json.Marshal(vaultFile{
+		Version: vaultVersion,
+		Salt:    v.salt,
+		Data:    gcm.Seal(nonce, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode credential vault: %w", err)
+	}
+
+	// Write then rename so a crash never leaves a half-written vault
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credential vault: %w", err)
+	}
+	if err := os.Rename(tmp, v.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write credential vault: %w", err)
+	}
+	return nil
+}
+
+// deriveVaultKey derives the AES-256 key of a vault from its master password.
+func deriveVaultKey(masterPassword string, salt []byte) []byte {
+	return argon2.IDKey([]byte(masterPassword), salt, vaultArgonTime, vaultArgonMemoryKB, vaultArgonThreads, 32)
+}
+
+// newGCM returns AES-256-GCM for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
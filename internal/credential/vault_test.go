@@ -0,0 +1,151 @@
+package credential
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVault_CreateOpenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), vaultFileName)
+
+	vault, err := CreateVault(path, "correct horse")
+	if err != nil {
+		t.Fatalf("CreateVault: %v", err)
+	}
+	if err := vault.Set("mongopal-key-a", "key-a"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := vault.Set("mongopal-key-b", "key-b"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := vault.Delete("mongopal-key-b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := vault.Delete("mongopal-key-b"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Delete of missing key = %v, want ErrKeyNotFound", err)
+	}
+
+	reopened, err := OpenVault(path, "correct horse")
+	if err != nil {
+		t.Fatalf("OpenVault: %v", err)
+	}
+	if got, err := reopened.Get("mongopal-key-a"); err != nil || got != "key-a" {
+		t.Errorf("Get = %q, %v; want key-a", got, err)
+	}
+	if _, err := reopened.Get("mongopal-key-b"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Get of deleted key = %v, want ErrKeyNotFound", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("vault permissions = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestVault_Errors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, vaultFileName)
+
+	if _, err := CreateVault(path, "short"); err == nil {
+		t.Error("Expected an error for a short master password")
+	}
+	if _, err := CreateVault(path, "long enough"); err != nil {
+		t.Fatalf("CreateVault: %v", err)
+	}
+	if _, err := CreateVault(path, "long enough"); err == nil {
+		t.Error("Expected an error when the vault already exists")
+	}
+	if _, err := OpenVault(path, "wrong password"); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Errorf("OpenVault with wrong password = %v, want ErrWrongMasterPassword", err)
+	}
+	if _, err := OpenVault(filepath.Join(dir, "missing.vault"), "long enough"); err == nil {
+		t.Error("Expected an error for a missing vault")
+	}
+}
+
+// memoryKeyStore is a KeyStore standing in for the OS keyring.
+type memoryKeyStore map[string]string
+
+func (m memoryKeyStore) Get(name string) (string, error) {
+	v, ok := m[name]
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return v, nil
+}
+
+func (m memoryKeyStore) Set(name, value string) error {
+	m[name] = value
+	return nil
+}
+
+func (m memoryKeyStore) Delete(name string) error {
+	if _, ok := m[name]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(m, name)
+	return nil
+}
+
+func TestEncryptedStorage_MigrateKeysToVault(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewEncryptedStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := memoryKeyStore{}
+	storage.SetKeyStore(keyring)
+
+	conn := testConnection{ID: "conn-1", Password: "secret"}
+	if err := storage.SaveConnection(conn.ID, conn); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	moved, err := storage.CreateVault("master password")
+	if err != nil {
+		t.Fatalf("CreateVault: %v", err)
+	}
+	if moved != 1 || len(keyring) != 0 {
+		t.Fatalf("moved %d keys, %d left in keyring; want 1 and 0", moved, len(keyring))
+	}
+	if !storage.UsingVault() {
+		t.Fatal("Storage should use the vault after CreateVault")
+	}
+
+	// A new session unlocks the vault to read the connection
+	reopened, err := NewEncryptedStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened.SetKeyStore(memoryKeyStore{})
+	if err := reopened.UnlockVault("wrong password"); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Fatalf("UnlockVault with wrong password = %v", err)
+	}
+	if err := reopened.UnlockVault("master password"); err != nil {
+		t.Fatalf("UnlockVault: %v", err)
+	}
+	var loaded testConnection
+	if err := reopened.LoadConnection(conn.ID, &loaded); err != nil {
+		t.Fatalf("LoadConnection: %v", err)
+	}
+	if loaded.Password != conn.Password {
+		t.Errorf("Password = %q, want %q", loaded.Password, conn.Password)
+	}
+
+	// And back: keys move to the other store and the connection still decrypts
+	back := memoryKeyStore{}
+	if moved, err := reopened.MigrateKeys(back); err != nil || moved != 1 {
+		t.Fatalf("MigrateKeys = %d, %v", moved, err)
+	}
+	if reopened.UsingVault() {
+		t.Error("Storage should no longer use the vault")
+	}
+	if err := reopened.LoadConnection(conn.ID, &loaded); err != nil {
+		t.Fatalf("LoadConnection after migrating back: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// balancerMigrationLimit is how many recent chunk migrations GetBalancerStatus returns.
+const balancerMigrationLimit = 20
+
+// balancerTime matches the "HH:MM" times of a balancer window.
+var balancerTime = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// GetBalancerStatus returns the balancer state of the sharded cluster a connection is routed
+// through, with its active window and recent chunk migrations.
+func (s *Service) GetBalancerStatus(connID string) (*types.BalancerStatus, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := requireMongos(ctx, client); err != nil {
+		return nil, err
+	}
+	status := balancerStatus(ctx, client)
+	if status == nil {
+		return nil, fmt.Errorf("failed to get balancer status")
+	}
+	config := client.Database("config")
+
+	var settings struct {
+		ActiveWindow *types.BalancerWindow `bson:"activeWindow"`
+	}
+	if err := config.Collection("settings").FindOne(ctx, bson.D{{Key: "_id", Value: "balancer"}}).Decode(&settings); err == nil {
+		status.Window = settings.ActiveWindow
+	}
+
+	filter := bson.D{{Key: "what", Value: bson.D{{Key: "$in", Value: bson.A{"moveChunk.from", "moveChunk.error"}}}}}
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}}).SetLimit(balancerMigrationLimit)
+	cursor, err := config.Collection("changelog").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	var entries []bson.M
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	status.Migrations = make([]types.ChunkMigration, 0, len(entries))
+	for _, entry := range entries {
+		status.Migrations = append(status.Migrations, parseChunkMigration(entry))
+	}
+	return status, nil
+}
+
+// SetBalancerEnabled starts or stops the balancer. Stopping waits for a running balancing
+// round to finish.
+func (s *Service) SetBalancerEnabled(connID string, enabled bool) error {
+	operation, command := "stop balancer", "balancerStop"
+	if enabled {
+		operation, command = "start balancer", "balancerStart"
+	}
+	if err := s.state.CheckWritable(connID, operation); err != nil {
+		return err
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := requireMongos(ctx, client); err != nil {
+		return err
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: command, Value: 1}}).Err(); err != nil {
+		return fmt.Errorf("failed to %s: %w", operation, err)
+	}
+
+	debug.LogQuery("Balancer state changed", map[string]interface{}{
+		"connectionId": connID,
+		"enabled":      enabled,
+	})
+	return nil
+}
+
+// SetBalancerWindow limits chunk migrations to the daily window from start to stop, given
+// as "HH:MM" in the config servers' local time. Empty start and stop remove the window.
+func (s *Service) SetBalancerWindow(connID, start, stop string) error {
+	if err := s.state.CheckWritable(connID, "set balancer window"); err != nil {
+		return err
+	}
+
+	var update bson.D
+	switch {
+	case start == "" && stop == "":
+		update = bson.D{{Key: "$unset", Value: bson.D{{Key: "activeWindow", Value: ""}}}}
+	case !balancerTime.MatchString(start) || !balancerTime.MatchString(stop):
+		return fmt.Errorf("balancer window times must be HH:MM")
+	case start == stop:
+		return fmt.Errorf("balancer window start and stop must differ")
+	default:
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "activeWindow", Value: bson.D{
+			{Key: "start", Value: start},
+			{Key: "stop", Value: stop},
+		}}}}}
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := requireMongos(ctx, client); err != nil {
+		return err
+	}
+	_, err = client.Database("config").Collection("settings").UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: "balancer"}}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to set balancer window: %w", err)
+	}
+
+	debug.LogQuery("Balancer window changed", map[string]interface{}{
+		"connectionId": connID,
+		"start":        start,
+		"stop":         stop,
+	})
+	return nil
+}
+
+// parseChunkMigration converts a moveChunk.from or moveChunk.error changelog entry.
+func parseChunkMigration(entry bson.M) types.ChunkMigration {
+	m := types.ChunkMigration{Namespace: bsonutil.ToString(entry["ns"])}
+	if t, ok := entry["time"].(primitive.DateTime); ok {
+		m.Time = t.Time()
+	}
+	details, _ := entry["details"].(bson.M)
+	m.From = bsonutil.ToString(details["from"])
+	m.To = bsonutil.ToString(details["to"])
+	m.Error = bsonutil.ToString(details["errmsg"])
+
+	switch {
+	case bsonutil.ToString(entry["what"]) == "moveChunk.error":
+		m.Status = "error"
+	case bsonutil.ToString(details["note"]) == "success" && m.Error == "":
+		m.Status = "success"
+	default:
+		m.Status = "aborted"
+	}
+	return m
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseChunkMigration(t *testing.T) {
+	at := time.Date(2026, 4, 2, 3, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		entry  bson.M
+		status string
+	}{
+		{"success", bson.M{"what": "moveChunk.from", "details": bson.M{"from": "s1", "to": "s2", "note": "success"}}, "success"},
+		{"aborted", bson.M{"what": "moveChunk.from", "details": bson.M{"from": "s1", "to": "s2", "note": "aborted", "errmsg": "timed out"}}, "aborted"},
+		{"error", bson.M{"what": "moveChunk.error", "details": bson.M{"from": "s1", "to": "s2", "errmsg": "no space"}}, "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.entry["ns"] = "shop.orders"
+			tt.entry["time"] = primitive.NewDateTimeFromTime(at)
+			m := parseChunkMigration(tt.entry)
+			if m.Status != tt.status || m.From != "s1" || m.To != "s2" || m.Namespace != "shop.orders" || !m.Time.Equal(at) {
+				t.Errorf("unexpected migration: %+v", m)
+			}
+		})
+	}
+}
+
+func TestBalancerValidation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	svc := NewService(state)
+
+	var readOnly *core.ReadOnlyError
+	if err := svc.SetBalancerEnabled("ro", false); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+	for _, w := range [][2]string{{"2:00", "06:00"}, {"23:00", "24:00"}, {"01:00", ""}, {"05:00", "05:00"}} {
+		if err := svc.SetBalancerWindow("conn-1", w[0], w[1]); err == nil || !strings.Contains(err.Error(), "balancer window") {
+			t.Errorf("window %v: expected validation error, got %v", w, err)
+		}
+	}
+	var notConnected *core.NotConnectedError
+	if err := svc.SetBalancerWindow("conn-1", "23:00", "06:00"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+	if err := svc.SetBalancerWindow("conn-1", "", ""); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// DefaultBenchmarkIterations is the number of timed runs when none is given.
+const DefaultBenchmarkIterations = 10
+
+// MaxBenchmarkIterations caps the number of timed runs.
+const MaxBenchmarkIterations = 1000
+
+// BenchmarkQuery runs a find query repeatedly, reading and discarding every result, and
+// reports latency percentiles alongside the plan's keys/docs examined from explain.
+// One untimed warm-up run precedes the timed iterations so cold caches don't skew results.
+func (s *Service) BenchmarkQuery(connID, dbName, collName, filter string, iterations int) (*types.BenchmarkResult, error) {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+	if iterations <= 0 {
+		iterations = DefaultBenchmarkIterations
+	}
+	if iterations > MaxBenchmarkIterations {
+		return nil, fmt.Errorf("iterations cannot exceed %d", MaxBenchmarkIterations)
+	}
+
+	filterDoc, err := document.ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+
+	debug.LogQuery("Benchmarking query", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"filter":     filter,
+		"iterations": iterations,
+	})
+
+	// runOnce executes the query and drains the cursor, returning the document count
+	runOnce := func() (int64, error) {
+		ctx, cancel := s.state.ContextWithTimeout()
+		defer cancel()
+		cursor, err := coll.Find(ctx, filterDoc)
+		if err != nil {
+			return 0, err
+		}
+		defer cursor.Close(ctx)
+		var n int64
+		for cursor.Next(ctx) {
+			n++
+		}
+		return n, cursor.Err()
+	}
+
+	if _, err := runOnce(); err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+
+	latencies := make([]float64, 0, iterations)
+	var docs int64
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		n, err := runOnce()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run query (iteration %d): %w", i+1, err)
+		}
+		latencies = append(latencies, float64(time.Since(start).Microseconds())/1000)
+		docs = n
+	}
+
+	result := summarizeLatencies(latencies)
+	result.DocsReturned = docs
+
+	explain, err := s.ExplainQuery(connID, dbName, collName, filter, types.QueryOptions{}, VerbosityExecutionStats)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("explain failed: %v", err))
+	} else {
+		result.TotalKeysExamined = explain.ExecutionStats.TotalKeysExamined
+		result.TotalDocsExamined = explain.ExecutionStats.TotalDocsExamined
+		result.IndexUsed = explain.IndexUsed
+		result.IsCollectionScan = explain.IsCollectionScan
+		result.WinningPlan = explain.WinningPlan
+	}
+
+	return result, nil
+}
+
+// summarizeLatencies computes latency statistics (in milliseconds) for a benchmark.
+func summarizeLatencies(latencies []float64) *types.BenchmarkResult {
+	result := &types.BenchmarkResult{Iterations: len(latencies)}
+	if len(latencies) == 0 {
+		return result
+	}
+
+	sorted := append([]float64(nil), latencies...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, l := range sorted {
+		sum += l
+	}
+	result.MinMs = sorted[0]
+	result.MaxMs = sorted[len(sorted)-1]
+	result.MeanMs = round3(sum / float64(len(sorted)))
+	result.P50Ms = percentile(sorted, 50)
+	result.P90Ms = percentile(sorted, 90)
+	result.P95Ms = percentile(sorted, 95)
+	result.P99Ms = percentile(sorted, 99)
+	return result
+}
+
+// percentile returns the nearest-rank percentile p (0-100] of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// round3 rounds to microsecond precision for display.
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
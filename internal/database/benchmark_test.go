@@ -0,0 +1,38 @@
+package database
+
+import "testing"
+
+func TestSummarizeLatencies(t *testing.T) {
+	latencies := make([]float64, 0, 100)
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, float64(i))
+	}
+
+	got := summarizeLatencies(latencies)
+	if got.Iterations != 100 {
+		t.Errorf("Iterations = %d, want 100", got.Iterations)
+	}
+	if got.MinMs != 1 || got.MaxMs != 100 {
+		t.Errorf("Min/Max = %v/%v, want 1/100", got.MinMs, got.MaxMs)
+	}
+	if got.MeanMs != 50.5 {
+		t.Errorf("MeanMs = %v, want 50.5", got.MeanMs)
+	}
+	if got.P50Ms != 50 || got.P90Ms != 90 || got.P95Ms != 95 || got.P99Ms != 99 {
+		t.Errorf("percentiles = %v/%v/%v/%v, want 50/90/95/99", got.P50Ms, got.P90Ms, got.P95Ms, got.P99Ms)
+	}
+
+	// Input must not be reordered
+	if latencies[0] != 100 {
+		t.Error("summarizeLatencies modified its input")
+	}
+
+	single := summarizeLatencies([]float64{2.5})
+	if single.P50Ms != 2.5 || single.P99Ms != 2.5 {
+		t.Errorf("single-sample percentiles = %v/%v, want 2.5", single.P50Ms, single.P99Ms)
+	}
+
+	if empty := summarizeLatencies(nil); empty.Iterations != 0 || empty.MaxMs != 0 {
+		t.Errorf("empty summary = %+v", empty)
+	}
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// ConvertToCapped converts a collection to a capped collection of sizeBytes, optionally also
+// capped at maxDocs documents. The conversion keeps the newest documents that fit, and
+// drops every index except _id, so the result lists the indexes to recreate. With dryRun
+// nothing is changed and the result compares the current size with the requested cap.
+func (s *Service) ConvertToCapped(connID, dbName, collName string, sizeBytes, maxDocs int64, dryRun bool) (*types.ConvertToCappedResult, error) {
+	if !dryRun {
+		if err := s.state.CheckWritable(connID, "convert to capped"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+	if sizeBytes <= 0 {
+		return nil, fmt.Errorf("capped size must be positive")
+	}
+	if maxDocs < 0 {
+		return nil, fmt.Errorf("maximum document count cannot be negative")
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	db := client.Database(dbName)
+	var stats bson.M
+	if err := db.RunCommand(ctx, bson.D{{Key: "collStats", Value: collName}}).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to get collection stats: %w", err)
+	}
+	if bsonutil.ToBool(stats["capped"]) {
+		return nil, fmt.Errorf("%s.%s is already capped; use ModifyCollection to resize it", dbName, collName)
+	}
+	specs, err := db.Collection(collName).Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	var names []string
+	for _, idx := range specs {
+		names = append(names, idx.Name)
+	}
+
+	result := cappedConversionPlan(stats, names, sizeBytes, maxDocs)
+	result.DryRun = dryRun
+	if dryRun {
+		return result, nil
+	}
+
+	cmd := bson.D{{Key: "convertToCapped", Value: collName}, {Key: "size", Value: sizeBytes}}
+	if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+		return nil, fmt.Errorf("failed to convert to capped: %w", err)
+	}
+	// convertToCapped takes no document limit; collMod can set one since MongoDB 6.0
+	if maxDocs > 0 {
+		cmd := bson.D{{Key: "collMod", Value: collName}, {Key: "cappedMax", Value: maxDocs}}
+		if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+			return result, fmt.Errorf("converted to capped, but failed to set the document limit: %w", err)
+		}
+	}
+
+	debug.LogQuery("Collection converted to capped", map[string]interface{}{
+		"database":       dbName,
+		"collection":     collName,
+		"size":           sizeBytes,
+		"max":            maxDocs,
+		"indexesDropped": result.IndexesDropped,
+	})
+
+	return result, nil
+}
+
+// cappedConversionPlan estimates the outcome of converting a collection with the given
+// collStats and indexes to a capped collection.
+func cappedConversionPlan(stats bson.M, indexNames []string, sizeBytes, maxDocs int64) *types.ConvertToCappedResult {
+	result := &types.ConvertToCappedResult{
+		Count:          bsonutil.ToInt64(stats["count"]),
+		DataSize:       bsonutil.ToInt64(stats["size"]),
+		RequestedSize:  sizeBytes,
+		RequestedMax:   maxDocs,
+		IndexesDropped: []string{},
+		Warnings:       []string{},
+	}
+
+	for _, name := range indexNames {
+		if name != "_id_" {
+			result.IndexesDropped = append(result.IndexesDropped, name)
+		}
+	}
+	if len(result.IndexesDropped) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d index(es) will be dropped and must be recreated", len(result.IndexesDropped)))
+	}
+
+	result.EstimatedKept = result.Count
+	if avg := bsonutil.ToInt64(stats["avgObjSize"]); avg > 0 && result.DataSize > sizeBytes {
+		result.EstimatedKept = sizeBytes / avg
+	}
+	if maxDocs > 0 && result.EstimatedKept > maxDocs {
+		result.EstimatedKept = maxDocs
+	}
+	if dropped := result.Count - result.EstimatedKept; dropped > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("about %d of %d documents (the oldest) will be discarded", dropped, result.Count))
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestCappedConversionPlan(t *testing.T) {
+	stats := bson.M{"count": int32(1000), "size": int64(100000), "avgObjSize": int32(100)}
+	indexes := []string{"_id_", "createdAt_1", "email_1"}
+
+	tests := []struct {
+		name     string
+		size     int64
+		max      int64
+		wantKept int64
+		warnings int
+	}{
+		{"fits", 1 << 20, 0, 1000, 1},
+		{"size cap", 50000, 0, 500, 2},
+		{"document cap", 1 << 20, 200, 200, 2},
+		{"both caps", 50000, 800, 500, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := cappedConversionPlan(stats, indexes, tt.size, tt.max)
+			if plan.EstimatedKept != tt.wantKept {
+				t.Errorf("EstimatedKept = %d, want %d", plan.EstimatedKept, tt.wantKept)
+			}
+			if len(plan.Warnings) != tt.warnings {
+				t.Errorf("Warnings = %v, want %d", plan.Warnings, tt.warnings)
+			}
+			if strings.Join(plan.IndexesDropped, ",") != "createdAt_1,email_1" {
+				t.Errorf("IndexesDropped = %v", plan.IndexesDropped)
+			}
+		})
+	}
+
+	plan := cappedConversionPlan(bson.M{}, []string{"_id_"}, 1024, 0)
+	if len(plan.IndexesDropped) != 0 || len(plan.Warnings) != 0 {
+		t.Errorf("empty collection plan = %+v", plan)
+	}
+}
+
+func TestConvertToCapped_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.ConvertToCapped("conn-1", "db", "logs", 0, 0, true); err == nil || !strings.Contains(err.Error(), "must be positive") {
+		t.Errorf("error = %v, want size rejection", err)
+	}
+	if _, err := svc.ConvertToCapped("conn-1", "db", "logs", 1024, -1, true); err == nil || !strings.Contains(err.Error(), "cannot be negative") {
+		t.Errorf("error = %v, want max rejection", err)
+	}
+
+	_, err := svc.ConvertToCapped("conn-1", "db", "logs", 1024, 0, false)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
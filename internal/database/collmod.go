@@ -0,0 +1,210 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+)
+
+// collModOptions are the collMod options ModifyCollection accepts.
+var collModOptions = map[string]bool{
+	"validator":                    true,
+	"validationLevel":              true,
+	"validationAction":             true,
+	"index":                        true,
+	"expireAfterSeconds":           true,
+	"timeseries":                   true,
+	"changeStreamPreAndPostImages": true,
+}
+
+// collModIndexOptions are the index changes collMod accepts, besides the index identifier.
+var collModIndexOptions = map[string]bool{
+	"expireAfterSeconds": true,
+	"hidden":             true,
+	"prepareUnique":      true,
+	"unique":             true,
+}
+
+// ModifyCollection runs collMod with options given as Extended JSON, e.g.
+// {"validator": {"$jsonSchema": {...}}, "validationLevel": "moderate"} or
+// {"index": {"name": "createdAt_1", "expireAfterSeconds": 86400}}.
+// SetValidator, SetIndexTTL, HideIndex, and UnhideIndex cover the common cases.
+func (s *Service) ModifyCollection(connID, dbName, collName, collModJSON string) error {
+	if err := s.state.CheckWritable(connID, "modify collection"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return err
+	}
+	cmd, err := BuildCollModCommand(collName, collModJSON)
+	if err != nil {
+		return err
+	}
+	return s.runCollMod(connID, dbName, collName, cmd)
+}
+
+// SetValidator sets or replaces a collection's validator. An empty validatorJSON removes
+// validation. validationLevel ("off", "strict", "moderate") and validationAction ("error",
+// "warn") are left unchanged when empty.
+func (s *Service) SetValidator(connID, dbName, collName, validatorJSON, validationLevel, validationAction string) error {
+	if err := s.state.CheckWritable(connID, "set validator"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return err
+	}
+
+	validator := bson.D{}
+	if strings.TrimSpace(validatorJSON) != "" {
+		if err := bsonutil.UnmarshalExtJSON([]byte(validatorJSON), false, &validator); err != nil {
+			return fmt.Errorf("invalid validator: %w", err)
+		}
+	}
+	cmd := bson.D{{Key: "collMod", Value: collName}, {Key: "validator", Value: validator}}
+	if validationLevel != "" {
+		cmd = append(cmd, bson.E{Key: "validationLevel", Value: validationLevel})
+	}
+	if validationAction != "" {
+		cmd = append(cmd, bson.E{Key: "validationAction", Value: validationAction})
+	}
+	if err := validateCollModOptions(cmd[1:]); err != nil {
+		return err
+	}
+	return s.runCollMod(connID, dbName, collName, cmd)
+}
+
+// SetIndexTTL changes how long documents live under an existing TTL index, without
+// rebuilding it.
+func (s *Service) SetIndexTTL(connID, dbName, collName, indexName string, expireAfterSeconds int64) error {
+	if err := s.state.CheckWritable(connID, "change index TTL"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return err
+	}
+	if indexName == "" {
+		return fmt.Errorf("index name cannot be empty")
+	}
+	if expireAfterSeconds < 0 {
+		return fmt.Errorf("expireAfterSeconds cannot be negative")
+	}
+
+	cmd := bson.D{
+		{Key: "collMod", Value: collName},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: indexName},
+			{Key: "expireAfterSeconds", Value: expireAfterSeconds},
+		}},
+	}
+	return s.runCollMod(connID, dbName, collName, cmd)
+}
+
+// BuildCollModCommand validates collMod options and returns the collMod command.
+func BuildCollModCommand(collName, collModJSON string) (bson.D, error) {
+	if strings.TrimSpace(collModJSON) == "" {
+		return nil, fmt.Errorf("collMod options cannot be empty")
+	}
+	var opts bson.D
+	if err := bsonutil.UnmarshalExtJSON([]byte(collModJSON), false, &opts); err != nil {
+		return nil, fmt.Errorf("invalid collMod options: %w", err)
+	}
+	if len(opts) == 0 {
+		return nil, fmt.Errorf("collMod options cannot be empty")
+	}
+	if err := validateCollModOptions(opts); err != nil {
+		return nil, err
+	}
+	return append(bson.D{{Key: "collMod", Value: collName}}, opts...), nil
+}
+
+// validateCollModOptions checks collMod options before they are sent to the server.
+func validateCollModOptions(opts bson.D) error {
+	for _, opt := range opts {
+		if !collModOptions[opt.Key] {
+			return fmt.Errorf("unsupported collMod option %q", opt.Key)
+		}
+		switch opt.Key {
+		case "validator":
+			if _, ok := opt.Value.(bson.D); !ok {
+				return fmt.Errorf("validator must be a document")
+			}
+		case "validationLevel":
+			switch opt.Value {
+			case "off", "strict", "moderate":
+			default:
+				return fmt.Errorf("validationLevel must be \"off\", \"strict\", or \"moderate\"")
+			}
+		case "validationAction":
+			switch opt.Value {
+			case "error", "warn":
+			default:
+				return fmt.Errorf("validationAction must be \"error\" or \"warn\"")
+			}
+		case "index":
+			if err := validateCollModIndex(opt.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateCollModIndex checks the index option of collMod: it names the index by name or
+// keyPattern and changes at least one supported setting.
+func validateCollModIndex(v interface{}) error {
+	spec, ok := v.(bson.D)
+	if !ok {
+		return fmt.Errorf("index must be a document")
+	}
+	identified, changed := false, false
+	for _, e := range spec {
+		switch {
+		case e.Key == "name" || e.Key == "keyPattern":
+			identified = true
+		case collModIndexOptions[e.Key]:
+			changed = true
+			if e.Key == "expireAfterSeconds" && bsonutil.ToInt64(e.Value) < 0 {
+				return fmt.Errorf("expireAfterSeconds cannot be negative")
+			}
+		default:
+			return fmt.Errorf("unsupported index option %q", e.Key)
+		}
+	}
+	if !identified {
+		return fmt.Errorf("index must have a name or keyPattern")
+	}
+	if !changed {
+		return fmt.Errorf("index must change expireAfterSeconds, hidden, prepareUnique, or unique")
+	}
+	return nil
+}
+
+// runCollMod runs a prepared collMod command.
+func (s *Service) runCollMod(connID, dbName, collName string, cmd bson.D) error {
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := client.Database(dbName).RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to modify collection: %w", err)
+	}
+
+	debug.LogQuery("Collection modified", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"command":    cmd,
+	})
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestBuildCollModCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		errMsg  string
+	}{
+		{"validator", `{"validator": {"$jsonSchema": {"required": ["email"]}}, "validationLevel": "strict", "validationAction": "error"}`, ""},
+		{"ttl by name", `{"index": {"name": "createdAt_1", "expireAfterSeconds": 86400}}`, ""},
+		{"hide by key pattern", `{"index": {"keyPattern": {"email": 1}, "hidden": true}}`, ""},
+		{"clustered ttl", `{"expireAfterSeconds": "off"}`, ""},
+		{"empty", "", "cannot be empty"},
+		{"empty document", `{}`, "cannot be empty"},
+		{"invalid json", `{"validator": }`, "invalid collMod options"},
+		{"unknown option", `{"capped": true}`, "unsupported collMod option"},
+		{"validator not a document", `{"validator": []}`, "must be a document"},
+		{"bad validationLevel", `{"validationLevel": "some"}`, "validationLevel"},
+		{"bad validationAction", `{"validationAction": "log"}`, "validationAction"},
+		{"index without identifier", `{"index": {"hidden": true}}`, "name or keyPattern"},
+		{"index without change", `{"index": {"name": "a_1"}}`, "must change"},
+		{"index unknown option", `{"index": {"name": "a_1", "sparse": true}}`, "unsupported index option"},
+		{"negative ttl", `{"index": {"name": "a_1", "expireAfterSeconds": -5}}`, "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := BuildCollModCommand("users", tt.options)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cmd[0].Key != "collMod" || cmd[0].Value != "users" {
+				t.Errorf("command must start with collMod: users, got %v", cmd)
+			}
+		})
+	}
+}
+
+func TestSetValidator_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if err := svc.SetValidator("conn-1", "db", "users", `{"a": }`, "", ""); err == nil || !strings.Contains(err.Error(), "invalid validator") {
+		t.Errorf("invalid JSON: got %v", err)
+	}
+	if err := svc.SetValidator("conn-1", "db", "users", "", "relaxed", ""); err == nil || !strings.Contains(err.Error(), "validationLevel") {
+		t.Errorf("bad level: got %v", err)
+	}
+
+	err := svc.SetValidator("conn-1", "db", "users", `{"$jsonSchema": {}}`, "moderate", "warn")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestSetIndexTTL_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if err := svc.SetIndexTTL("conn-1", "db", "sessions", "", 60); err == nil {
+		t.Error("expected error for empty index name")
+	}
+	if err := svc.SetIndexTTL("conn-1", "db", "sessions", "createdAt_1", -1); err == nil {
+		t.Error("expected error for negative TTL")
+	}
+	err := svc.SetIndexTTL("conn-1", "db", "sessions", "createdAt_1", 60)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
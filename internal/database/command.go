@@ -0,0 +1,320 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+)
+
+// readOnlyCommands are the commands RunCommand allows on read-only connections, by lowercase
+// name. aggregate is allowed unless its pipeline writes with $out or $merge, and profile
+// only to read the level.
+var readOnlyCommands = map[string]bool{
+	"aggregate":                true,
+	"balancercollectionstatus": true,
+	"balancerstatus":           true,
+	"buildinfo":                true,
+	"collstats":                true,
+	"connectionstatus":         true,
+	"connpoolstats":            true,
+	"count":                    true,
+	"currentop":                true,
+	"datasize":                 true,
+	"dbhash":                   true,
+	"dbstats":                  true,
+	"distinct":                 true,
+	"explain":                  true,
+	"features":                 true,
+	"find":                     true,
+	"getcmdlineopts":           true,
+	"getdefaultrwconcern":      true,
+	"getlog":                   true,
+	"getmore":                  true,
+	"getparameter":             true,
+	"getshardmap":              true,
+	"hello":                    true,
+	"hostinfo":                 true,
+	"ismaster":                 true,
+	"listcollections":          true,
+	"listcommands":             true,
+	"listdatabases":            true,
+	"listindexes":              true,
+	"listshards":               true,
+	"ping":                     true,
+	"replsetgetconfig":         true,
+	"replsetgetstatus":         true,
+	"rolesinfo":                true,
+	"serverstatus":             true,
+	"shardingstate":            true,
+	"top":                      true,
+	"usersinfo":                true,
+	"validate":                 true,
+}
+
+// blockedCommands are never run by RunCommand, by lowercase name: they take servers down, or
+// have a dedicated operation with confirmation or safety checks.
+var blockedCommands = map[string]string{
+	"shutdown":                       "it shuts the server down",
+	"dropdatabase":                   "use Drop Database instead",
+	"drop":                           "use Drop Collection instead",
+	"delete":                         "use Delete Many instead",
+	"dropindexes":                    "use Drop Index instead",
+	"deleteindexes":                  "use Drop Index instead",
+	"dropuser":                       "use Drop User instead",
+	"droprole":                       "use Drop Role instead",
+	"dropallusersfromdatabase":       "it removes every user of the database",
+	"dropallrolesfromdatabase":       "it removes every role of the database",
+	"setfeaturecompatibilityversion": "use Set FCV, which asks for confirmation",
+	"replsetreconfig":                "use the replica set member operations, which validate the new config",
+	"replsetstepdown":                "it forces an election",
+	"removeshard":                    "it drains and removes a shard",
+	"killallsessions":                "it kills the sessions of every user",
+	"killallsessionsbypattern":       "it kills the sessions of every user",
+	"applyops":                       "it applies raw oplog entries, including drops, without any checks",
+	"killop":                         "use Kill Operation instead",
+	"dropconnections":                "it closes the server's outgoing connections to other members",
+}
+
+// RunCommand runs a server command given as Extended JSON, e.g. {"collStats": "orders"}, on
+// dbName and returns the full reply as canonical Extended JSON. Read-only connections only
+// run commands that don't write; destructive commands with a dedicated operation are refused
+// everywhere. Every database the command names must pass the connection's allow/deny lists,
+// and listDatabases replies only list the databases they allow. Aggregates writing with $out
+// or $merge need a confirmed "run aggregate with output" token for the output namespace on
+// connections with safety settings.
+func (s *Service) RunCommand(connID, dbName, commandJSON string) (string, error) {
+	if err := ValidateDatabaseName(dbName); err != nil {
+		return "", err
+	}
+	var cmd bson.D
+	if err := bsonutil.UnmarshalExtJSON([]byte(commandJSON), false, &cmd); err != nil {
+		return "", fmt.Errorf("invalid command: %w", err)
+	}
+	if len(cmd) == 0 {
+		return "", fmt.Errorf("command cannot be empty")
+	}
+	name := cmd[0].Key
+	if reason, ok := blockedReason(cmd); ok {
+		return "", fmt.Errorf("command %q cannot be run here: %s", name, reason)
+	}
+	if !isReadOnlyCommand(cmd) {
+		if err := s.state.CheckWritable(connID, "run "+name); err != nil {
+			return "", err
+		}
+	}
+	// $out replaces its target collection and $merge rewrites documents in it
+	if outDB, outColl, ok := outputNamespace(cmd, dbName); ok {
+		if err := s.state.CheckDatabaseAccess(connID, outDB); err != nil {
+			return "", err
+		}
+		if err := s.state.RequireConfirmation(connID, "run aggregate with output", outDB+"."+outColl); err != nil {
+			return "", err
+		}
+	}
+
+	for _, db := range referencedDatabases(cmd, dbName) {
+		if err := s.state.CheckDatabaseAccess(connID, db); err != nil {
+			return "", err
+		}
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	reply, err := client.Database(dbName).RunCommand(ctx, cmd).Raw()
+	if err != nil {
+		return "", fmt.Errorf("command %s failed: %w", name, err)
+	}
+	var result interface{} = reply
+	if strings.EqualFold(name, "listDatabases") && s.state.HasDatabaseRestrictions(connID) {
+		if result, err = s.filterDatabaseList(connID, reply); err != nil {
+			return "", fmt.Errorf("failed to decode reply: %w", err)
+		}
+	}
+	out, err := document.MarshalDocument(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode reply: %w", err)
+	}
+
+	debug.LogQuery("Command run", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"command":      name,
+	})
+	return string(out), nil
+}
+
+// isReadOnlyCommand reports whether a command only reads.
+func isReadOnlyCommand(cmd bson.D) bool {
+	name := strings.ToLower(cmd[0].Key)
+	switch name {
+	case "aggregate":
+		for _, e := range cmd {
+			if e.Key == "pipeline" {
+				return !pipelineWrites(e.Value)
+			}
+		}
+		return true
+	case "profile":
+		return bsonutil.ToInt64(cmd[0].Value) == -1
+	}
+	return readOnlyCommands[name]
+}
+
+// blockedReason returns why RunCommand refuses a command: it is in blockedCommands, it is a
+// renameCollection that drops an existing target, or an fsync that locks the server.
+func blockedReason(cmd bson.D) (string, bool) {
+	name := strings.ToLower(cmd[0].Key)
+	if reason, ok := blockedCommands[name]; ok {
+		return reason, true
+	}
+	truthy := func(v interface{}) bool { return bsonutil.ToBool(v) || bsonutil.ToInt64(v) != 0 }
+	for _, e := range cmd {
+		switch {
+		case name == "renamecollection" && e.Key == "dropTarget" && truthy(e.Value):
+			return "use Rename Collection, which asks for confirmation before dropping the target", true
+		case name == "fsync" && e.Key == "lock" && truthy(e.Value):
+			return "it blocks every write until the server is unlocked", true
+		}
+	}
+	return "", false
+}
+
+// referencedDatabases returns the databases a command reaches besides dbName: the source and
+// target of renameCollection, the databases of the command explain wraps, and the $lookup,
+// $graphLookup and $unionWith targets of an aggregate pipeline, at any depth.
+func referencedDatabases(cmd bson.D, dbName string) []string {
+	var dbs []string
+	add := func(db string) {
+		if db != "" && db != dbName && !slices.Contains(dbs, db) {
+			dbs = append(dbs, db)
+		}
+	}
+	switch strings.ToLower(cmd[0].Key) {
+	case "renamecollection":
+		for _, e := range cmd {
+			if e.Key == "renameCollection" || e.Key == "to" {
+				db, _, _ := strings.Cut(bsonutil.ToString(e.Value), ".")
+				add(db)
+			}
+		}
+	case "explain":
+		if inner, ok := cmd[0].Value.(bson.D); ok && len(inner) > 0 {
+			for _, db := range referencedDatabases(inner, dbName) {
+				add(db)
+			}
+		}
+	case "aggregate":
+		for _, e := range cmd {
+			if e.Key == "pipeline" {
+				pipelineDatabases(e.Value, add)
+			}
+		}
+	}
+	return dbs
+}
+
+// pipelineDatabases calls add with the database of each $lookup, $graphLookup and $unionWith
+// stage in a pipeline, including the pipelines nested in them and in $facet. Targets without
+// a database are in the pipeline's own database and are skipped.
+func pipelineDatabases(pipeline interface{}, add func(string)) {
+	stages, _ := pipeline.(bson.A)
+	for _, stage := range stages {
+		d, ok := stage.(bson.D)
+		if !ok || len(d) == 0 {
+			continue
+		}
+		spec, _ := d[0].Value.(bson.D)
+		switch d[0].Key {
+		case "$lookup", "$graphLookup", "$unionWith":
+			for _, e := range spec {
+				switch e.Key {
+				case "from", "coll":
+					// Since 8.0, a $lookup from may name another database as {db, coll}
+					if from, ok := e.Value.(bson.D); ok {
+						for _, f := range from {
+							if f.Key == "db" {
+								add(bsonutil.ToString(f.Value))
+							}
+						}
+					}
+				case "db":
+					add(bsonutil.ToString(e.Value))
+				case "pipeline":
+					pipelineDatabases(e.Value, add)
+				}
+			}
+		case "$facet":
+			for _, e := range spec {
+				pipelineDatabases(e.Value, add)
+			}
+		}
+	}
+}
+
+// filterDatabaseList removes the databases a connection may not access from a listDatabases
+// reply.
+func (s *Service) filterDatabaseList(connID string, reply bson.Raw) (bson.D, error) {
+	var doc bson.D
+	if err := bson.Unmarshal(reply, &doc); err != nil {
+		return nil, err
+	}
+	for i, e := range doc {
+		if e.Key != "databases" {
+			continue
+		}
+		dbs, _ := e.Value.(bson.A)
+		allowed := bson.A{}
+		for _, db := range dbs {
+			if d, ok := db.(bson.D); ok && s.state.DatabaseAllowed(connID, bsonutil.ToString(d.Map()["name"])) {
+				allowed = append(allowed, d)
+			}
+		}
+		doc[i].Value = allowed
+	}
+	return doc, nil
+}
+
+// outputNamespace returns the namespace the $out or $merge stage of an aggregate command
+// writes to, and false for other commands and pipelines that do not write.
+func outputNamespace(cmd bson.D, dbName string) (db, coll string, ok bool) {
+	if !strings.EqualFold(cmd[0].Key, "aggregate") {
+		return "", "", false
+	}
+	for _, e := range cmd {
+		if e.Key != "pipeline" {
+			continue
+		}
+		stages, _ := e.Value.(bson.A)
+		for _, stage := range stages {
+			if d, isDoc := stage.(bson.D); isDoc {
+				if db, coll, ok = document.OutputNamespace(d, dbName); ok {
+					return db, coll, true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
+
+// pipelineWrites reports whether an aggregation pipeline has an $out or $merge stage.
+func pipelineWrites(pipeline interface{}) bool {
+	stages, _ := pipeline.(bson.A)
+	for _, stage := range stages {
+		if d, ok := stage.(bson.D); ok && len(d) > 0 && (d[0].Key == "$out" || d[0].Key == "$merge") {
+			return true
+		}
+	}
+	return false
+}
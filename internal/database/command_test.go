@@ -0,0 +1,152 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestIsReadOnlyCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  bson.D
+		want bool
+	}{
+		{"collStats", bson.D{{Key: "collStats", Value: "orders"}}, true},
+		{"case insensitive", bson.D{{Key: "serverstatus", Value: 1}}, true},
+		{"aggregate", bson.D{{Key: "aggregate", Value: "orders"}, {Key: "pipeline", Value: bson.A{bson.D{{Key: "$match", Value: bson.D{}}}}}}, true},
+		{"aggregate $out", bson.D{{Key: "aggregate", Value: "orders"}, {Key: "pipeline", Value: bson.A{bson.D{{Key: "$out", Value: "copy"}}}}}, false},
+		{"aggregate $merge", bson.D{{Key: "aggregate", Value: "orders"}, {Key: "pipeline", Value: bson.A{bson.D{{Key: "$merge", Value: "copy"}}}}}, false},
+		{"profile read", bson.D{{Key: "profile", Value: int32(-1)}}, true},
+		{"profile set", bson.D{{Key: "profile", Value: int32(2)}}, false},
+		{"insert", bson.D{{Key: "insert", Value: "orders"}}, false},
+		{"unknown", bson.D{{Key: "compact", Value: "orders"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReadOnlyCommand(tt.cmd); got != tt.want {
+				t.Errorf("isReadOnlyCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCommand_Validation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	svc := NewService(state)
+
+	tests := []struct {
+		name   string
+		connID string
+		cmd    string
+		errMsg string
+	}{
+		{"invalid json", "conn-1", `{"ping": }`, "invalid command"},
+		{"empty", "conn-1", `{}`, "cannot be empty"},
+		{"blocked", "conn-1", `{"shutdown": 1}`, "cannot be run here"},
+		{"blocked any case", "conn-1", `{"dropDatabase": 1}`, "cannot be run here"},
+		{"drop", "conn-1", `{"drop": "orders"}`, "use Drop Collection"},
+		{"delete", "conn-1", `{"delete": "orders", "deletes": [{"q": {}, "limit": 0}]}`, "use Delete Many"},
+		{"dropIndexes", "conn-1", `{"dropIndexes": "orders", "index": "*"}`, "use Drop Index"},
+		{"rename with drop", "conn-1", `{"renameCollection": "shop.a", "to": "shop.b", "dropTarget": true}`, "use Rename Collection"},
+		{"rename with numeric drop", "conn-1", `{"renameCollection": "shop.a", "to": "shop.b", "dropTarget": 1}`, "use Rename Collection"},
+		{"applyOps", "conn-1", `{"applyOps": [{"op": "c", "ns": "shop.$cmd", "o": {"drop": "orders"}}]}`, "cannot be run here"},
+		{"fsync lock", "conn-1", `{"fsync": 1, "lock": true}`, "blocks every write"},
+		{"killOp", "conn-1", `{"killOp": 1, "op": 123}`, "use Kill Operation"},
+		{"dropConnections", "conn-1", `{"dropConnections": 1, "hostAndPort": ["a:27017"]}`, "cannot be run here"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.RunCommand(tt.connID, "shop", tt.cmd); err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	var readOnly *core.ReadOnlyError
+	if _, err := svc.RunCommand("ro", "shop", `{"insert": "orders", "documents": [{"a": 1}]}`); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+	var notConnected *core.NotConnectedError
+	if _, err := svc.RunCommand("ro", "shop", `{collStats: "orders"}`); !errors.As(err, &notConnected) {
+		t.Errorf("read-only command: expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestRunCommand_OutputDatabaseAccess(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "team", AllowedDatabases: []string{"shop"}}}
+	svc := NewService(state)
+
+	cmd := `{"aggregate": "orders", "pipeline": [{"$merge": {"into": {"db": "denied", "coll": "copy"}}}], "cursor": {}}`
+	var denied *core.DatabaseAccessError
+	if _, err := svc.RunCommand("team", "shop", cmd); !errors.As(err, &denied) || denied.Database != "denied" {
+		t.Errorf("expected DatabaseAccessError for denied, got %v", err)
+	}
+}
+
+func TestRunCommand_OutputConfirmation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "safe", RequireDeleteConfirmation: true}}
+	svc := NewService(state)
+
+	cmd := `{"aggregate": "orders", "pipeline": [{"$match": {}}, {"$out": "copy"}], "cursor": {}}`
+	var required *core.ConfirmationRequiredError
+	if _, err := svc.RunCommand("safe", "shop", cmd); !errors.As(err, &required) || required.Target != "shop.copy" {
+		t.Errorf("expected ConfirmationRequiredError for shop.copy, got %v", err)
+	}
+
+	var notConnected *core.NotConnectedError
+	if _, err := svc.RunCommand("safe", "shop", `{"renameCollection": "shop.a", "to": "shop.b"}`); !errors.As(err, &notConnected) {
+		t.Errorf("rename without drop should not be blocked, got %v", err)
+	}
+}
+
+func TestRunCommand_ReferencedDatabaseAccess(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "team", AllowedDatabases: []string{"shop", "admin"}}}
+	svc := NewService(state)
+
+	tests := []struct {
+		name, db, cmd string
+	}{
+		{"rename source", "admin", `{"renameCollection": "denied.x", "to": "shop.y"}`},
+		{"rename target", "admin", `{"renameCollection": "shop.x", "to": "denied.y"}`},
+		{"lookup", "shop", `{"aggregate": "orders", "pipeline": [{"$lookup": {"from": {"db": "denied", "coll": "c"}, "as": "c", "pipeline": []}}], "cursor": {}}`},
+		{"nested unionWith", "shop", `{"aggregate": "orders", "pipeline": [{"$facet": {"a": [{"$lookup": {"from": "x", "as": "x", "pipeline": [{"$unionWith": {"coll": "y", "db": "denied"}}]}}]}}], "cursor": {}}`},
+		{"explain", "shop", `{"explain": {"aggregate": "orders", "pipeline": [{"$lookup": {"from": {"db": "denied", "coll": "c"}, "as": "c", "pipeline": []}}], "cursor": {}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var denied *core.DatabaseAccessError
+			if _, err := svc.RunCommand("team", tt.db, tt.cmd); !errors.As(err, &denied) || denied.Database != "denied" {
+				t.Errorf("expected DatabaseAccessError for denied, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFilterDatabaseList(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "team", DeniedDatabases: []string{"secret*"}}}
+	svc := NewService(state)
+
+	reply, _ := bson.Marshal(bson.D{
+		{Key: "databases", Value: bson.A{bson.D{{Key: "name", Value: "shop"}}, bson.D{{Key: "name", Value: "secrets"}}}},
+		{Key: "ok", Value: 1.0},
+	})
+	got, err := svc.filterDatabaseList("team", reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbs := got[0].Value.(bson.A)
+	if len(dbs) != 1 || dbs[0].(bson.D)[0].Value != "shop" {
+		t.Errorf("expected only shop to be listed, got %v", dbs)
+	}
+}
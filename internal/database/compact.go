@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// compactTimeout bounds how long CompactCollection waits for the server.
+const compactTimeout = 24 * time.Hour
+
+// compactProgressInterval is how often CompactCollection samples collStats.
+const compactProgressInterval = 2 * time.Second
+
+// CompactBusyThreshold is the number of active operations above which a primary is
+// considered too busy to compact without force.
+const CompactBusyThreshold = 10
+
+// CompactCollection rewrites a collection and its indexes to release unused space to the
+// operating system, typically after large deletions. Compact runs on the connected member
+// only, so replica set members must be compacted one at a time, and it cannot run through
+// mongos. Unless force is set it refuses to run on a replica set primary with more than
+// CompactBusyThreshold active operations. While it runs, "compact:progress" events report
+// the shrinking storage size and an estimated time remaining.
+func (s *Service) CompactCollection(connID, dbName, collName string, force bool) (*types.CompactResult, error) {
+	if err := s.state.CheckWritable(connID, "compact"); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCompactSafety(client, force); err != nil {
+		return nil, err
+	}
+
+	db := client.Database(dbName)
+	before, reclaimable, err := s.compactStorageStats(context.Background(), db, collName)
+	if err != nil {
+		return nil, err
+	}
+
+	operationID := uuid.New().String()
+	started := time.Now()
+
+	debug.LogQuery("Compacting collection", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"storageSize": before,
+		"reclaimable": reclaimable,
+		"operationId": operationID,
+	})
+
+	pollCtx, stopPolling := context.WithCancel(context.Background())
+	var polling sync.WaitGroup
+	polling.Add(1)
+	go func() {
+		defer polling.Done()
+		ticker := time.NewTicker(compactProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			size, _, err := s.compactStorageStats(pollCtx, db, collName)
+			if err != nil {
+				continue
+			}
+			s.state.EmitConnectionEvent(connID, "compact:progress", compactProgress(operationID, dbName, collName, before, size, reclaimable, time.Since(started)))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), compactTimeout)
+	defer cancel()
+	var res bson.M
+	err = db.RunCommand(ctx, bson.D{{Key: "compact", Value: collName}, {Key: "force", Value: force}}).Decode(&res)
+	stopPolling()
+	polling.Wait()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compact collection: %w", err)
+	}
+
+	result := &types.CompactResult{
+		OperationID:       operationID,
+		BytesFreed:        bsonutil.ToInt64(res["bytesFreed"]),
+		StorageSizeBefore: before,
+		DurationMs:        time.Since(started).Milliseconds(),
+	}
+	if after, _, err := s.compactStorageStats(context.Background(), db, collName); err == nil {
+		result.StorageSizeAfter = after
+	}
+
+	debug.LogQuery("Compact finished", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"bytesFreed":  result.BytesFreed,
+		"durationMs":  result.DurationMs,
+		"operationId": operationID,
+	})
+
+	return result, nil
+}
+
+// checkCompactSafety refuses to compact through mongos, and on a busy replica set primary
+// unless forced.
+func (s *Service) checkCompactSafety(client *mongo.Client, force bool) error {
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	var hello bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return fmt.Errorf("failed to check server role: %w", err)
+	}
+	if bsonutil.ToString(hello["msg"]) == "isdbgrid" {
+		return fmt.Errorf("compact cannot run through mongos; connect to each shard member directly")
+	}
+	if force || bsonutil.ToString(hello["setName"]) == "" || !bsonutil.ToBool(hello["isWritablePrimary"]) {
+		return nil
+	}
+
+	var current struct {
+		InProg []bson.M `bson:"inprog"`
+	}
+	cmd := bson.D{{Key: "currentOp", Value: 1}, {Key: "active", Value: true}, {Key: "op", Value: bson.M{"$ne": "none"}}}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Decode(&current); err != nil {
+		return fmt.Errorf("failed to check server load: %w", err)
+	}
+	// currentOp lists itself
+	if active := len(current.InProg) - 1; active > CompactBusyThreshold {
+		return fmt.Errorf("refusing to compact on a busy primary (%d active operations); compact secondaries first, step down, or force", active)
+	}
+	return nil
+}
+
+// compactStorageStats returns a collection's storage size and the bytes WiredTiger reports
+// as available for reuse, which is roughly what compact can release.
+func (s *Service) compactStorageStats(ctx context.Context, db *mongo.Database, collName string) (storageSize, reclaimable int64, err error) {
+	statsCtx, cancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+	defer cancel()
+
+	var stats bson.M
+	if err := db.RunCommand(statsCtx, bson.D{{Key: "collStats", Value: collName}}).Decode(&stats); err != nil {
+		return 0, 0, fmt.Errorf("failed to get collection stats: %w", err)
+	}
+	return bsonutil.ToInt64(stats["storageSize"]), reusableBytes(stats), nil
+}
+
+// compactProgress builds a progress event, extrapolating the time remaining from the rate
+// the storage size has shrunk so far.
+func compactProgress(operationID, dbName, collName string, before, current, reclaimable int64, elapsed time.Duration) types.CompactProgress {
+	progress := types.CompactProgress{
+		OperationID:          operationID,
+		Database:             dbName,
+		Collection:           collName,
+		StorageSize:          current,
+		Reclaimable:          reclaimable,
+		ElapsedMs:            elapsed.Milliseconds(),
+		EstimatedRemainingMs: -1,
+	}
+	if reclaimed := before - current; reclaimed > 0 {
+		progress.Reclaimed = reclaimed
+		remaining := reclaimable - reclaimed
+		if remaining < 0 {
+			remaining = 0
+		}
+		progress.EstimatedRemainingMs = remaining * progress.ElapsedMs / reclaimed
+	}
+	return progress
+}
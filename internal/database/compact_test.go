@@ -0,0 +1,48 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestCompactProgress(t *testing.T) {
+	tests := []struct {
+		name          string
+		before        int64
+		current       int64
+		reclaimable   int64
+		elapsed       time.Duration
+		wantReclaimed int64
+		wantRemaining int64
+	}{
+		{"nothing reclaimed yet", 1000, 1000, 400, 2 * time.Second, 0, -1},
+		{"halfway", 1000, 800, 400, 2 * time.Second, 200, 2000},
+		{"past estimate", 1000, 500, 400, 4 * time.Second, 500, 0},
+		{"file grew", 1000, 1100, 400, time.Second, 0, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := compactProgress("op", "db", "coll", tt.before, tt.current, tt.reclaimable, tt.elapsed)
+			if p.Reclaimed != tt.wantReclaimed || p.EstimatedRemainingMs != tt.wantRemaining {
+				t.Errorf("Reclaimed/EstimatedRemainingMs = %d/%d, want %d/%d",
+					p.Reclaimed, p.EstimatedRemainingMs, tt.wantReclaimed, tt.wantRemaining)
+			}
+			if p.StorageSize != tt.current || p.Reclaimable != tt.reclaimable {
+				t.Errorf("StorageSize/Reclaimable = %d/%d", p.StorageSize, p.Reclaimable)
+			}
+		})
+	}
+}
+
+func TestCompactCollection_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.CompactCollection("conn-1", "db", "events", false)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
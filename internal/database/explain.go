@@ -7,22 +7,39 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/peternagy/mongopal/internal/bsonutil"
-	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
-// ExplainQuery runs explain on a query and returns the execution plan.
-func (s *Service) ExplainQuery(connID, dbName, collName, filter string) (*types.ExplainResult, error) {
+// Explain verbosity modes accepted by the explain command.
+const (
+	VerbosityQueryPlanner      = "queryPlanner"
+	VerbosityExecutionStats    = "executionStats"
+	VerbosityAllPlansExecution = "allPlansExecution"
+)
+
+// ExplainQuery runs explain on a find query and returns the execution plan.
+// Sort, projection, skip, limit, and collation from opts are applied so the plan matches what
+// FindDocuments would execute. verbosity defaults to executionStats when empty.
+func (s *Service) ExplainQuery(connID, dbName, collName, filter string, opts types.QueryOptions, verbosity string) (*types.ExplainResult, error) {
 	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
 		return nil, err
 	}
 
-	client, err := s.state.GetClient(connID)
+	switch verbosity {
+	case "":
+		verbosity = VerbosityExecutionStats
+	case VerbosityQueryPlanner, VerbosityExecutionStats, VerbosityAllPlansExecution:
+	default:
+		return nil, fmt.Errorf("invalid explain verbosity %q", verbosity)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithMaxTime(opts.MaxTimeMS)
 	defer cancel()
 
 	// Parse filter
@@ -30,20 +47,45 @@ func (s *Service) ExplainQuery(connID, dbName, collName, filter string) (*types.
 	if filter == "" || filter == "{}" {
 		filterDoc = bson.M{}
 	} else {
-		if err := bson.UnmarshalExtJSON([]byte(filter), true, &filterDoc); err != nil {
+		if err := bsonutil.UnmarshalExtJSON([]byte(filter), true, &filterDoc); err != nil {
 			return nil, fmt.Errorf("invalid filter: %w", err)
 		}
 	}
 
+	findCmd := bson.D{
+		{Key: "find", Value: collName},
+		{Key: "filter", Value: filterDoc},
+	}
+	if sortDoc := document.ParseSort(opts.Sort); len(sortDoc) > 0 {
+		findCmd = append(findCmd, bson.E{Key: "sort", Value: sortDoc})
+	}
+	if opts.Projection != "" && opts.Projection != "{}" {
+		var projection bson.M
+		if err := bsonutil.UnmarshalExtJSON([]byte(opts.Projection), true, &projection); err != nil {
+			return nil, fmt.Errorf("invalid projection: %w", err)
+		}
+		findCmd = append(findCmd, bson.E{Key: "projection", Value: projection})
+	}
+	if opts.Skip > 0 {
+		findCmd = append(findCmd, bson.E{Key: "skip", Value: opts.Skip})
+	}
+	if opts.Limit > 0 {
+		findCmd = append(findCmd, bson.E{Key: "limit", Value: opts.Limit})
+	}
+	collation, err := document.ParseCollation(opts.Collation)
+	if err != nil {
+		return nil, err
+	}
+	if collation != nil {
+		findCmd = append(findCmd, bson.E{Key: "collation", Value: bson.Raw(collation.ToDocument())})
+	}
+
 	db := client.Database(dbName)
 
-	// Run explain command with executionStats verbosity
 	explainCmd := bson.D{
-		{Key: "explain", Value: bson.D{
-			{Key: "find", Value: collName},
-			{Key: "filter", Value: filterDoc},
-		}},
-		{Key: "verbosity", Value: "executionStats"},
+		{Key: "explain", Value: findCmd},
+		{Key: "verbosity", Value: verbosity},
+		{Key: "maxTimeMS", Value: s.state.QueryMaxTime(opts.MaxTimeMS).Milliseconds()},
 	}
 
 	var explainResult bson.M
@@ -77,6 +119,10 @@ func (s *Service) ExplainQuery(connID, dbName, collName, filter string) (*types.
 
 		// Parse winning plan
 		if wp, ok := qp["winningPlan"].(bson.M); ok {
+			// Slot-based execution engine (5.0+) nests the classic plan under queryPlan
+			if inner, ok := wp["queryPlan"].(bson.M); ok {
+				wp = inner
+			}
 			result.WinningPlan = extractPlanSummary(wp)
 			result.QueryPlanner.WinningPlanStage = extractTopStage(wp)
 			result.IndexUsed = extractIndexName(wp)
@@ -191,4 +237,3 @@ func isCollectionScan(plan bson.M) bool {
 
 	return false
 }
-
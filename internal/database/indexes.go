@@ -1,13 +1,21 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/peternagy/mongopal/internal/bsonutil"
 	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -17,12 +25,12 @@ func (s *Service) ListIndexes(connID, dbName, collName string) ([]types.IndexInf
 		return nil, err
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	coll := client.Database(dbName).Collection(collName)
@@ -32,46 +40,8 @@ func (s *Service) ListIndexes(connID, dbName, collName string) ([]types.IndexInf
 	}
 	defer cursor.Close(ctx)
 
-	// Get index stats for usage counts
-	indexStats := make(map[string]int64)
-	statsCmd := bson.D{{Key: "aggregate", Value: collName}, {Key: "pipeline", Value: bson.A{bson.D{{Key: "$indexStats", Value: bson.D{}}}}}, {Key: "cursor", Value: bson.D{}}}
-	var statsResult bson.M
-	if err := client.Database(dbName).RunCommand(ctx, statsCmd).Decode(&statsResult); err == nil {
-		if cursorDoc, ok := statsResult["cursor"].(bson.M); ok {
-			if firstBatch, ok := cursorDoc["firstBatch"].(bson.A); ok {
-				for _, item := range firstBatch {
-					if doc, ok := item.(bson.M); ok {
-						name, _ := doc["name"].(string)
-						if accesses, ok := doc["accesses"].(bson.M); ok {
-							if ops, ok := accesses["ops"].(int64); ok {
-								indexStats[name] = ops
-							} else if ops32, ok := accesses["ops"].(int32); ok {
-								indexStats[name] = int64(ops32)
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// Get collection stats for index sizes
-	indexSizes := make(map[string]int64)
-	var collStats bson.M
-	if err := client.Database(dbName).RunCommand(ctx, bson.D{{Key: "collStats", Value: collName}}).Decode(&collStats); err == nil {
-		if sizes, ok := collStats["indexSizes"].(bson.M); ok {
-			for name, size := range sizes {
-				switch v := size.(type) {
-				case int64:
-					indexSizes[name] = v
-				case int32:
-					indexSizes[name] = int64(v)
-				case float64:
-					indexSizes[name] = int64(v)
-				}
-			}
-		}
-	}
+	usage := indexUsageStats(ctx, client.Database(dbName).Collection(collName))
+	indexSizes := indexSizeStats(ctx, client.Database(dbName), collName)
 
 	var indexes []types.IndexInfo
 	for cursor.Next(ctx) {
@@ -83,6 +53,7 @@ func (s *Service) ListIndexes(connID, dbName, collName string) ([]types.IndexInf
 		name, _ := result["name"].(string)
 		unique, _ := result["unique"].(bool)
 		sparse, _ := result["sparse"].(bool)
+		hidden, _ := result["hidden"].(bool)
 
 		// Parse TTL
 		var ttl int64
@@ -118,69 +89,351 @@ func (s *Service) ListIndexes(connID, dbName, collName string) ([]types.IndexInf
 			Sparse:     sparse,
 			TTL:        ttl,
 			Size:       indexSizes[name],
-			UsageCount: indexStats[name],
+			UsageCount: usage[name].ops,
+			UsageSince: usage[name].since,
+			Hidden:     hidden,
 		})
 	}
 
 	return indexes, nil
 }
 
-// CreateIndex creates a new index on a collection.
-func (s *Service) CreateIndex(connID, dbName, collName string, keys map[string]int, opts types.IndexOptions) error {
+// indexProgressInterval is how often CreateIndex polls currentOp for build progress.
+const indexProgressInterval = time.Second
+
+// indexKeyTypes are the string values accepted in an index key specification.
+var indexKeyTypes = map[string]bool{
+	"text":     true,
+	"2dsphere": true,
+	"2d":       true,
+	"hashed":   true,
+}
+
+// indexUsage is the $indexStats access count for one index.
+type indexUsage struct {
+	ops   int64
+	since time.Time
+}
+
+// indexUsageStats returns per-index access counts from $indexStats. Counters reset when
+// the server restarts or the index is rebuilt, which is what since records. Errors (e.g.
+// missing privileges) yield no stats rather than failing the listing.
+func indexUsageStats(ctx context.Context, coll *mongo.Collection) map[string]indexUsage {
+	cursor, err := coll.Aggregate(ctx, bson.A{bson.D{{Key: "$indexStats", Value: bson.D{}}}})
+	if err != nil {
+		return map[string]indexUsage{}
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return map[string]indexUsage{}
+	}
+	return mergeIndexUsage(docs)
+}
+
+// mergeIndexUsage folds $indexStats documents into per-index usage. A sharded cluster
+// reports one document per shard, so counts are summed and the earliest since is kept.
+func mergeIndexUsage(docs []bson.M) map[string]indexUsage {
+	usage := make(map[string]indexUsage)
+	for _, doc := range docs {
+		name := bsonutil.ToString(doc["name"])
+		accesses, ok := doc["accesses"].(bson.M)
+		if name == "" || !ok {
+			continue
+		}
+		u := usage[name]
+		u.ops += bsonutil.ToInt64(accesses["ops"])
+		if since, ok := accesses["since"].(primitive.DateTime); ok {
+			if t := since.Time(); u.since.IsZero() || t.Before(u.since) {
+				u.since = t
+			}
+		}
+		usage[name] = u
+	}
+	return usage
+}
+
+// indexSizeStats returns index sizes in bytes from collStats, falling back to the
+// $collStats stage on servers where the collStats command has been removed.
+func indexSizeStats(ctx context.Context, db *mongo.Database, collName string) map[string]int64 {
+	var sizes bson.M
+	var collStats bson.M
+	if err := db.RunCommand(ctx, bson.D{{Key: "collStats", Value: collName}}).Decode(&collStats); err == nil {
+		sizes, _ = collStats["indexSizes"].(bson.M)
+	} else {
+		pipeline := bson.A{bson.D{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}}}
+		cursor, err := db.Collection(collName).Aggregate(ctx, pipeline)
+		if err == nil {
+			var docs []bson.M
+			if cursor.All(ctx, &docs) == nil {
+				sizes = make(bson.M)
+				for _, doc := range docs {
+					storage, _ := doc["storageStats"].(bson.M)
+					shardSizes, _ := storage["indexSizes"].(bson.M)
+					for name, size := range shardSizes {
+						sizes[name] = bsonutil.ToInt64(sizes[name]) + bsonutil.ToInt64(size)
+					}
+				}
+			}
+		}
+	}
+
+	indexSizes := make(map[string]int64, len(sizes))
+	for name, size := range sizes {
+		indexSizes[name] = bsonutil.ToInt64(size)
+	}
+	return indexSizes
+}
+
+// CreateIndex creates an index and returns its name. keysJSON is an Extended JSON key
+// specification whose field order is kept, e.g. {"status": 1, "createdAt": -1}; values are
+// 1, -1, or an index type ("text", "2dsphere", "2d", "hashed"), and "$**" or "path.$**"
+// keys create wildcard indexes. While the build runs, "index:progress" events report the
+// progress the server shows in currentOp.
+func (s *Service) CreateIndex(connID, dbName, collName, keysJSON string, opts types.IndexOptions) (string, error) {
+	if err := s.state.CheckWritable(connID, "create index"); err != nil {
+		return "", err
+	}
+
 	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
-		return err
+		return "", err
 	}
 
-	if len(keys) == 0 {
-		return fmt.Errorf("index keys cannot be empty")
+	keys, err := ParseIndexKeys(keysJSON)
+	if err != nil {
+		return "", err
+	}
+	indexOpts, err := buildIndexOptions(keys, opts)
+	if err != nil {
+		return "", err
+	}
+	name := opts.Name
+	if name == "" {
+		name = DefaultIndexName(keys)
 	}
+	indexOpts.SetName(name)
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), core.IndexBuildTimeout)
 	defer cancel()
 
+	debug.LogQuery("Creating index", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"index":      name,
+		"keys":       keysJSON,
+	})
+
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	var polling sync.WaitGroup
+	polling.Add(1)
+	go func() {
+		defer polling.Done()
+		s.pollIndexBuild(pollCtx, client, connID, dbName, collName, name)
+	}()
+
 	coll := client.Database(dbName).Collection(collName)
+	_, err = coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys, Options: indexOpts})
+	stopPolling()
+	polling.Wait()
+	if err != nil {
+		return "", fmt.Errorf("failed to create index: %w", err)
+	}
+
+	return name, nil
+}
 
-	// Build the index keys document
-	keysDoc := bson.D{}
-	for field, order := range keys {
-		keysDoc = append(keysDoc, bson.E{Key: field, Value: order})
+// ParseIndexKeys parses an Extended JSON index key specification, keeping field order.
+// Numeric directions are normalized to 1 or -1.
+func ParseIndexKeys(keysJSON string) (bson.D, error) {
+	if strings.TrimSpace(keysJSON) == "" {
+		return nil, fmt.Errorf("index keys cannot be empty")
+	}
+	var keys bson.D
+	if err := bsonutil.UnmarshalExtJSON([]byte(keysJSON), false, &keys); err != nil {
+		return nil, fmt.Errorf("invalid index keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("index keys cannot be empty")
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("index key field cannot be empty")
+		}
+		if seen[key.Key] {
+			return nil, fmt.Errorf("index key %q is repeated", key.Key)
+		}
+		seen[key.Key] = true
+
+		switch v := key.Value.(type) {
+		case int32, int64, float64:
+			direction := bsonutil.ToFloat64(v)
+			if direction != 1 && direction != -1 {
+				return nil, fmt.Errorf("index key %q must be 1 or -1, got %v", key.Key, v)
+			}
+			keys[i].Value = int32(direction)
+		case string:
+			if !indexKeyTypes[v] {
+				return nil, fmt.Errorf("index key %q has unsupported type %q", key.Key, v)
+			}
+		default:
+			return nil, fmt.Errorf("index key %q must be 1, -1, or an index type", key.Key)
+		}
+
+		if isWildcardKey(key.Key) && len(keys) > 1 {
+			return nil, fmt.Errorf("wildcard key %q cannot be part of a compound index", key.Key)
+		}
+	}
+	return keys, nil
+}
+
+// DefaultIndexName returns the name the server gives an index with these keys,
+// e.g. "status_1_createdAt_-1".
+func DefaultIndexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// isWildcardKey reports whether an index key field is "$**" or "path.$**".
+func isWildcardKey(field string) bool {
+	return field == "$**" || strings.HasSuffix(field, ".$**")
+}
+
+// buildIndexOptions validates opts against the parsed keys and converts them to driver options.
+func buildIndexOptions(keys bson.D, opts types.IndexOptions) (*options.IndexOptions, error) {
+	hasText := false
+	for _, key := range keys {
+		if key.Value == "text" {
+			hasText = true
+		}
 	}
 
-	// Build index options
 	indexOpts := options.Index()
 	if opts.Unique {
+		if hasText || isWildcardKey(keys[0].Key) {
+			return nil, fmt.Errorf("text and wildcard indexes cannot be unique")
+		}
 		indexOpts.SetUnique(true)
 	}
 	if opts.Sparse {
 		indexOpts.SetSparse(true)
 	}
+	if opts.ExpireAfterSeconds < 0 {
+		return nil, fmt.Errorf("expireAfterSeconds cannot be negative")
+	}
 	if opts.ExpireAfterSeconds > 0 {
+		if len(keys) != 1 {
+			return nil, fmt.Errorf("TTL indexes must have a single key")
+		}
 		indexOpts.SetExpireAfterSeconds(int32(opts.ExpireAfterSeconds))
 	}
-	if opts.Name != "" {
-		indexOpts.SetName(opts.Name)
+	if strings.TrimSpace(opts.PartialFilterExpression) != "" {
+		if opts.Sparse {
+			return nil, fmt.Errorf("an index cannot be both sparse and partial")
+		}
+		var filter bson.D
+		if err := bsonutil.UnmarshalExtJSON([]byte(opts.PartialFilterExpression), false, &filter); err != nil {
+			return nil, fmt.Errorf("invalid partial filter expression: %w", err)
+		}
+		indexOpts.SetPartialFilterExpression(filter)
+	}
+	if opts.Collation != nil {
+		collation, err := document.ParseCollation(opts.Collation)
+		if err != nil {
+			return nil, err
+		}
+		indexOpts.SetCollation(collation)
+	}
+	if strings.TrimSpace(opts.WildcardProjection) != "" {
+		if keys[0].Key != "$**" {
+			return nil, fmt.Errorf("wildcardProjection requires a \"$**\" key")
+		}
+		var projection bson.D
+		if err := bsonutil.UnmarshalExtJSON([]byte(opts.WildcardProjection), false, &projection); err != nil {
+			return nil, fmt.Errorf("invalid wildcard projection: %w", err)
+		}
+		indexOpts.SetWildcardProjection(projection)
 	}
 
-	indexModel := mongo.IndexModel{
-		Keys:    keysDoc,
-		Options: indexOpts,
+	if !hasText && (opts.Weights != "" || opts.DefaultLanguage != "" || opts.LanguageOverride != "") {
+		return nil, fmt.Errorf("weights, defaultLanguage, and languageOverride require a text index")
+	}
+	if strings.TrimSpace(opts.Weights) != "" {
+		var weights bson.D
+		if err := bsonutil.UnmarshalExtJSON([]byte(opts.Weights), false, &weights); err != nil {
+			return nil, fmt.Errorf("invalid text index weights: %w", err)
+		}
+		indexOpts.SetWeights(weights)
+	}
+	if opts.DefaultLanguage != "" {
+		indexOpts.SetDefaultLanguage(opts.DefaultLanguage)
+	}
+	if opts.LanguageOverride != "" {
+		indexOpts.SetLanguageOverride(opts.LanguageOverride)
 	}
 
-	_, err = coll.Indexes().CreateOne(ctx, indexModel)
-	if err != nil {
-		return fmt.Errorf("failed to create index: %w", err)
+	return indexOpts, nil
+}
+
+// pollIndexBuild emits "index:progress" events from currentOp until ctx is cancelled.
+// Servers that refuse currentOp (e.g. missing privileges) just produce no events.
+func (s *Service) pollIndexBuild(ctx context.Context, client *mongo.Client, connID, dbName, collName, indexName string) {
+	ticker := time.NewTicker(indexProgressInterval)
+	defer ticker.Stop()
+
+	cmd := bson.D{
+		{Key: "currentOp", Value: 1},
+		{Key: "ns", Value: dbName + "." + collName},
+		{Key: "command.createIndexes", Value: collName},
 	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 
-	return nil
+		var result struct {
+			InProg []bson.M `bson:"inprog"`
+		}
+		if err := client.Database("admin").RunCommand(ctx, cmd).Decode(&result); err != nil {
+			if ctx.Err() == nil {
+				debug.LogQuery("Index build progress unavailable", map[string]interface{}{"error": err.Error()})
+			}
+			return
+		}
+		for _, op := range result.InProg {
+			progress := types.IndexBuildProgress{
+				Database:   dbName,
+				Collection: collName,
+				Index:      indexName,
+				Message:    bsonutil.ToString(op["msg"]),
+			}
+			if p, ok := op["progress"].(bson.M); ok {
+				progress.Done = bsonutil.ToInt64(p["done"])
+				progress.Total = bsonutil.ToInt64(p["total"])
+			}
+			s.state.EmitConnectionEvent(connID, "index:progress", progress)
+		}
+	}
 }
 
 // DropIndex drops an index from a collection.
 func (s *Service) DropIndex(connID, dbName, collName, indexName string) error {
+	if err := s.state.CheckWritable(connID, "drop index"); err != nil {
+		return err
+	}
+
 	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
 		return err
 	}
@@ -193,12 +446,16 @@ func (s *Service) DropIndex(connID, dbName, collName, indexName string) error {
 		return fmt.Errorf("cannot drop the default _id index")
 	}
 
-	client, err := s.state.GetClient(connID)
+	if err := s.state.RequireConfirmation(connID, "drop index", dbName+"."+collName+"."+indexName); err != nil {
+		return err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	coll := client.Database(dbName).Collection(collName)
@@ -209,3 +466,114 @@ func (s *Service) DropIndex(connID, dbName, collName, indexName string) error {
 
 	return nil
 }
+
+// HideIndex hides an index from the query planner. The index is still maintained on
+// writes, so queries can be checked without it and UnhideIndex restores it instantly,
+// with no rebuild. Requires MongoDB 4.4+.
+func (s *Service) HideIndex(connID, dbName, collName, indexName string) error {
+	if err := s.state.CheckWritable(connID, "hide index"); err != nil {
+		return err
+	}
+	return s.setIndexHidden(connID, dbName, collName, indexName, true)
+}
+
+// UnhideIndex makes a hidden index available to the query planner again.
+func (s *Service) UnhideIndex(connID, dbName, collName, indexName string) error {
+	if err := s.state.CheckWritable(connID, "unhide index"); err != nil {
+		return err
+	}
+	return s.setIndexHidden(connID, dbName, collName, indexName, false)
+}
+
+// setIndexHidden runs collMod to change an index's hidden flag.
+func (s *Service) setIndexHidden(connID, dbName, collName, indexName string, hidden bool) error {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return err
+	}
+
+	if indexName == "" {
+		return fmt.Errorf("index name cannot be empty")
+	}
+
+	if indexName == "_id_" {
+		return fmt.Errorf("cannot hide the default _id index")
+	}
+
+	cmd := bson.D{
+		{Key: "collMod", Value: collName},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: indexName},
+			{Key: "hidden", Value: hidden},
+		}},
+	}
+	return s.runCollMod(connID, dbName, collName, cmd)
+}
+
+// GetSearchCapabilities reports whether a collection has a text index and/or Atlas Search indexes.
+func (s *Service) GetSearchCapabilities(connID, dbName, collName string) (*types.SearchCapabilities, error) {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	caps, err := document.DetectSearchCapabilities(ctx, client.Database(dbName).Collection(collName))
+	if err != nil {
+		return nil, err
+	}
+	return &caps, nil
+}
+
+// LintQuery lints a query and cross-references the collection's indexes to warn about
+// filters that will scan the whole collection.
+func (s *Service) LintQuery(connID, dbName, collName, filter, projection, sort string) (*types.LintResult, error) {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	indexKeys, err := listIndexKeys(ctx, client.Database(dbName).Collection(collName))
+	if err != nil {
+		return nil, err
+	}
+
+	return document.LintQueryWithIndexes(filter, projection, sort, indexKeys), nil
+}
+
+// listIndexKeys returns the key document of each index on a collection.
+func listIndexKeys(ctx context.Context, coll *mongo.Collection) ([]bson.D, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	// Decode keys as bson.D to keep compound key order
+	var indexKeys []bson.D
+	for cursor.Next(ctx) {
+		var spec struct {
+			Key bson.D `bson:"key"`
+		}
+		if err := cursor.Decode(&spec); err != nil {
+			continue
+		}
+		indexKeys = append(indexKeys, spec.Key)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	return indexKeys, nil
+}
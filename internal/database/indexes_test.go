@@ -0,0 +1,206 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseIndexKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantName string
+		errMsg   string
+	}{
+		{"single ascending", `{"email": 1}`, "email_1", ""},
+		{"compound keeps order", `{"status": 1, "createdAt": -1, "age": 1}`, "status_1_createdAt_-1_age_1", ""},
+		{"float direction", `{"a": 1.0}`, "a_1", ""},
+		{"text", `{"title": "text", "body": "text"}`, "title_text_body_text", ""},
+		{"hashed", `{"userId": "hashed"}`, "userId_hashed", ""},
+		{"geo", `{"location": "2dsphere"}`, "location_2dsphere", ""},
+		{"wildcard", `{"$**": 1}`, "$**_1", ""},
+		{"path wildcard", `{"attrs.$**": 1}`, "attrs.$**_1", ""},
+		{"empty string", "", "", "cannot be empty"},
+		{"empty object", `{}`, "", "cannot be empty"},
+		{"invalid json", `{"a": }`, "", "invalid index keys"},
+		{"bad direction", `{"a": 2}`, "", "must be 1 or -1"},
+		{"unknown type", `{"a": "btree"}`, "", "unsupported type"},
+		{"object value", `{"a": {"b": 1}}`, "", "must be 1, -1, or an index type"},
+		{"compound wildcard", `{"$**": 1, "a": 1}`, "", "compound index"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseIndexKeys(tt.input)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("ParseIndexKeys(%q) error = %v, want containing %q", tt.input, err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIndexKeys(%q) unexpected error: %v", tt.input, err)
+			}
+			if got := DefaultIndexName(keys); got != tt.wantName {
+				t.Errorf("DefaultIndexName = %q, want %q", got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParseIndexKeys_NormalizesDirection(t *testing.T) {
+	keys, err := ParseIndexKeys(`{"a": -1.0}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, ok := keys[0].Value.(int32); !ok || v != -1 {
+		t.Errorf("direction = %#v, want int32(-1)", keys[0].Value)
+	}
+}
+
+func TestBuildIndexOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   string
+		opts   types.IndexOptions
+		errMsg string
+	}{
+		{"unique sparse", `{"email": 1}`, types.IndexOptions{Unique: true, Sparse: true}, ""},
+		{"ttl", `{"createdAt": 1}`, types.IndexOptions{ExpireAfterSeconds: 3600}, ""},
+		{"ttl compound", `{"a": 1, "b": 1}`, types.IndexOptions{ExpireAfterSeconds: 3600}, "single key"},
+		{"ttl negative", `{"a": 1}`, types.IndexOptions{ExpireAfterSeconds: -1}, "cannot be negative"},
+		{"partial", `{"a": 1}`, types.IndexOptions{PartialFilterExpression: `{"a": {"$exists": true}}`}, ""},
+		{"partial invalid", `{"a": 1}`, types.IndexOptions{PartialFilterExpression: `{"a": }`}, "invalid partial filter"},
+		{"partial and sparse", `{"a": 1}`, types.IndexOptions{Sparse: true, PartialFilterExpression: `{"a": 1}`}, "sparse and partial"},
+		{"collation", `{"name": 1}`, types.IndexOptions{Collation: &types.Collation{Locale: "en", Strength: 2}}, ""},
+		{"collation without locale", `{"name": 1}`, types.IndexOptions{Collation: &types.Collation{}}, "locale is required"},
+		{"wildcard projection", `{"$**": 1}`, types.IndexOptions{WildcardProjection: `{"secret": 0}`}, ""},
+		{"projection without wildcard", `{"a.$**": 1}`, types.IndexOptions{WildcardProjection: `{"secret": 0}`}, "requires a \"$**\" key"},
+		{"unique wildcard", `{"$**": 1}`, types.IndexOptions{Unique: true}, "cannot be unique"},
+		{"text options", `{"title": "text"}`, types.IndexOptions{Weights: `{"title": 10}`, DefaultLanguage: "english", LanguageOverride: "lang"}, ""},
+		{"unique text", `{"title": "text"}`, types.IndexOptions{Unique: true}, "cannot be unique"},
+		{"weights without text", `{"title": 1}`, types.IndexOptions{Weights: `{"title": 10}`}, "require a text index"},
+		{"invalid weights", `{"title": "text"}`, types.IndexOptions{Weights: `nope`}, "invalid text index weights"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseIndexKeys(tt.keys)
+			if err != nil {
+				t.Fatalf("ParseIndexKeys(%q) unexpected error: %v", tt.keys, err)
+			}
+			opts, err := buildIndexOptions(keys, tt.opts)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("buildIndexOptions error = %v, want containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildIndexOptions unexpected error: %v", err)
+			}
+			if tt.opts.ExpireAfterSeconds > 0 && (opts.ExpireAfterSeconds == nil || int64(*opts.ExpireAfterSeconds) != tt.opts.ExpireAfterSeconds) {
+				t.Errorf("ExpireAfterSeconds = %v, want %d", opts.ExpireAfterSeconds, tt.opts.ExpireAfterSeconds)
+			}
+			if tt.opts.PartialFilterExpression != "" {
+				if _, ok := opts.PartialFilterExpression.(bson.D); !ok {
+					t.Errorf("PartialFilterExpression = %#v, want bson.D", opts.PartialFilterExpression)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateIndex_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.CreateIndex("conn-1", "db", "users", `{"email": 1}`, types.IndexOptions{})
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestCreateIndex_InvalidKeysBeforeConnecting(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.CreateIndex("conn-1", "db", "users", `{"email": 5}`, types.IndexOptions{})
+	if err == nil || !strings.Contains(err.Error(), "must be 1 or -1") {
+		t.Fatalf("Expected key validation error, got %v", err)
+	}
+}
+
+func TestHideIndex_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name      string
+		indexName string
+		errMsg    string
+	}{
+		{"empty name", "", "cannot be empty"},
+		{"default index", "_id_", "cannot hide the default _id index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.HideIndex("conn-1", "db", "users", tt.indexName)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("HideIndex error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	err := svc.UnhideIndex("conn-1", "db", "users", "email_1")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestDropIndex_RequiresConfirmation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "safe", RequireDeleteConfirmation: true}}
+	svc := NewService(state)
+
+	err := svc.DropIndex("safe", "db", "users", "email_1")
+	var required *core.ConfirmationRequiredError
+	if !errors.As(err, &required) || required.Target != "db.users.email_1" {
+		t.Fatalf("Expected ConfirmationRequiredError for db.users.email_1, got %v", err)
+	}
+
+	err = svc.DropIndex("conn-1", "db", "users", "email_1")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError without safety settings, got %v", err)
+	}
+}
+
+func TestMergeIndexUsage(t *testing.T) {
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+	docs := []bson.M{
+		{"name": "email_1", "shard": "s0", "accesses": bson.M{"ops": int64(5), "since": primitive.NewDateTimeFromTime(late)}},
+		{"name": "email_1", "shard": "s1", "accesses": bson.M{"ops": int64(7), "since": primitive.NewDateTimeFromTime(early)}},
+		{"name": "_id_", "accesses": bson.M{"ops": int32(0), "since": primitive.NewDateTimeFromTime(late)}},
+		{"name": "broken"},
+	}
+
+	usage := mergeIndexUsage(docs)
+	if got := usage["email_1"]; got.ops != 12 || !got.since.Equal(early) {
+		t.Errorf("email_1 = %+v, want 12 ops since %v", got, early)
+	}
+	if got := usage["_id_"]; got.ops != 0 || !got.since.Equal(late) {
+		t.Errorf("_id_ = %+v, want 0 ops since %v", got, late)
+	}
+	if _, ok := usage["broken"]; ok {
+		t.Error("index without accesses should be skipped")
+	}
+}
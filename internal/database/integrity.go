@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// validateTimeout bounds a validate run; a full validation reads every document and index key.
+const validateTimeout = time.Hour
+
+// ValidateCollection runs the validate command and returns its findings. A full validation
+// also checks the storage engine's data structures but takes an exclusive lock on the
+// collection for its duration; the default (full = false) runs in the background on 5.0+.
+func (s *Service) ValidateCollection(connID, dbName, collName string, full bool) (*types.CollectionValidation, error) {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+
+	var result bson.M
+	cmd := bson.D{{Key: "validate", Value: collName}, {Key: "full", Value: full}}
+	if err := client.Database(dbName).RunCommand(ctx, cmd).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to validate collection: %w", err)
+	}
+
+	validation := parseValidateResult(result)
+	validation.Namespace = dbName + "." + collName
+	validation.Full = full
+
+	debug.LogQuery("Collection validated", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"full":       full,
+		"valid":      validation.Valid,
+	})
+
+	return validation, nil
+}
+
+// parseValidateResult converts validate output into a CollectionValidation. Through mongos
+// the per-shard results are under "raw"; they are merged, with shard names prefixed to
+// messages and index names.
+func parseValidateResult(result bson.M) *types.CollectionValidation {
+	validation := &types.CollectionValidation{
+		Valid:          true,
+		CorruptRecords: []string{},
+		Indexes:        []types.IndexValidation{},
+		Warnings:       []string{},
+		Errors:         []string{},
+	}
+	raw, _ := json.MarshalIndent(result, "", "  ")
+	validation.Raw = string(raw)
+
+	shards, ok := result["raw"].(bson.M)
+	if !ok {
+		mergeValidateResult(validation, "", result)
+		return validation
+	}
+	names := make([]string, 0, len(shards))
+	for name := range shards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if shard, ok := shards[name].(bson.M); ok {
+			mergeValidateResult(validation, name+": ", shard)
+		}
+	}
+	return validation
+}
+
+// mergeValidateResult adds one server's validate output to validation.
+func mergeValidateResult(validation *types.CollectionValidation, prefix string, result bson.M) {
+	if valid, ok := result["valid"].(bool); ok && !valid {
+		validation.Valid = false
+	}
+	validation.Records += bsonutil.ToInt64(result["nrecords"])
+	validation.InvalidDocuments += bsonutil.ToInt64(result["nInvalidDocuments"])
+
+	for _, v := range toArray(result["warnings"]) {
+		validation.Warnings = append(validation.Warnings, prefix+bsonutil.ToString(v))
+	}
+	for _, v := range toArray(result["errors"]) {
+		validation.Errors = append(validation.Errors, prefix+bsonutil.ToString(v))
+	}
+	for _, v := range toArray(result["corruptRecords"]) {
+		validation.CorruptRecords = append(validation.CorruptRecords, prefix+bsonutil.ToString(v))
+	}
+	validation.ExtraIndexEntries += len(toArray(result["extraIndexEntries"]))
+	validation.MissingIndexEntries += len(toArray(result["missingIndexEntries"]))
+
+	details, _ := result["indexDetails"].(bson.M)
+	keys, _ := result["keysPerIndex"].(bson.M)
+	names := make([]string, 0, len(details))
+	for name := range details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		detail, _ := details[name].(bson.M)
+		valid, _ := detail["valid"].(bool)
+		validation.Indexes = append(validation.Indexes, types.IndexValidation{
+			Name:  prefix + name,
+			Valid: valid,
+			Keys:  bsonutil.ToInt64(keys[name]),
+		})
+	}
+}
+
+// toArray returns v as a bson.A, or nil if it is not an array.
+func toArray(v interface{}) bson.A {
+	arr, _ := v.(bson.A)
+	return arr
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestParseValidateResult(t *testing.T) {
+	result := bson.M{
+		"ns":                "shop.orders",
+		"nrecords":          int32(100),
+		"nInvalidDocuments": int64(2),
+		"valid":             false,
+		"warnings":          bson.A{"Detected one or more invalid documents"},
+		"errors":            bson.A{"index 'a_1' is inconsistent"},
+		"corruptRecords":    bson.A{int64(17)},
+		"extraIndexEntries": bson.A{bson.M{"indexName": "a_1"}},
+		"missingIndexEntries": bson.A{
+			bson.M{"indexName": "a_1"},
+			bson.M{"indexName": "a_1"},
+		},
+		"keysPerIndex": bson.M{"_id_": int32(100), "a_1": int32(99)},
+		"indexDetails": bson.M{
+			"_id_": bson.M{"valid": true},
+			"a_1":  bson.M{"valid": false},
+		},
+		"ok": float64(1),
+	}
+
+	v := parseValidateResult(result)
+	if v.Valid {
+		t.Error("Valid = true, want false")
+	}
+	if v.Records != 100 || v.InvalidDocuments != 2 {
+		t.Errorf("Records/InvalidDocuments = %d/%d, want 100/2", v.Records, v.InvalidDocuments)
+	}
+	if len(v.CorruptRecords) != 1 || v.CorruptRecords[0] != "17" {
+		t.Errorf("CorruptRecords = %v", v.CorruptRecords)
+	}
+	if v.ExtraIndexEntries != 1 || v.MissingIndexEntries != 2 {
+		t.Errorf("Extra/Missing = %d/%d, want 1/2", v.ExtraIndexEntries, v.MissingIndexEntries)
+	}
+	if len(v.Indexes) != 2 || v.Indexes[0].Name != "_id_" || !v.Indexes[0].Valid || v.Indexes[1].Valid || v.Indexes[1].Keys != 99 {
+		t.Errorf("Indexes = %+v", v.Indexes)
+	}
+	if len(v.Warnings) != 1 || len(v.Errors) != 1 || v.Raw == "" {
+		t.Errorf("Warnings/Errors/Raw = %v/%v/%q", v.Warnings, v.Errors, v.Raw)
+	}
+}
+
+func TestParseValidateResult_Sharded(t *testing.T) {
+	result := bson.M{
+		"raw": bson.M{
+			"rs1/host:27018": bson.M{"valid": true, "nrecords": int32(10), "warnings": bson.A{}},
+			"rs0/host:27017": bson.M{"valid": true, "nrecords": int32(5), "warnings": bson.A{"slow"}},
+		},
+		"valid": true,
+	}
+
+	v := parseValidateResult(result)
+	if !v.Valid || v.Records != 15 {
+		t.Errorf("Valid/Records = %v/%d, want true/15", v.Valid, v.Records)
+	}
+	if len(v.Warnings) != 1 || v.Warnings[0] != "rs0/host:27017: slow" {
+		t.Errorf("Warnings = %v", v.Warnings)
+	}
+}
+
+func TestValidateCollection_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.ValidateCollection("conn-1", "shop", "orders", false)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 
+	"github.com/peternagy/mongopal/internal/bsonutil"
 	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/types"
 )
@@ -21,14 +22,14 @@ func NewService(state *core.AppState) *Service {
 	return &Service{state: state}
 }
 
-// ListDatabases returns all databases for a connection.
+// ListDatabases returns the databases of a connection its allow/deny lists permit.
 func (s *Service) ListDatabases(connID string) ([]types.DatabaseInfo, error) {
 	client, err := s.state.GetClient(connID)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	result, err := client.ListDatabases(ctx, bson.D{})
@@ -38,6 +39,9 @@ func (s *Service) ListDatabases(connID string) ([]types.DatabaseInfo, error) {
 
 	databases := make([]types.DatabaseInfo, 0, len(result.Databases))
 	for _, db := range result.Databases {
+		if !s.state.DatabaseAllowed(connID, db.Name) {
+			continue
+		}
 		databases = append(databases, types.DatabaseInfo{
 			Name:       db.Name,
 			SizeOnDisk: db.SizeOnDisk,
@@ -59,12 +63,12 @@ func (s *Service) ListCollections(connID, dbName string) ([]types.CollectionInfo
 		return nil, err
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	db := client.Database(dbName)
@@ -91,15 +95,22 @@ func (s *Service) ListCollections(connID, dbName string) ([]types.CollectionInfo
 
 		// Get document count (skip for views)
 		var count int64
-		if collType == "collection" {
+		if collType == "collection" || collType == "timeseries" {
 			count, _ = db.Collection(name).EstimatedDocumentCount(ctx)
 		}
 
-		collections = append(collections, types.CollectionInfo{
+		info := types.CollectionInfo{
 			Name:  name,
 			Type:  collType,
 			Count: count,
-		})
+		}
+		if collOpts, ok := result["options"].(bson.M); ok {
+			info.TimeSeries = timeSeriesOptions(collOpts)
+			if collType == "view" {
+				info.ViewOn, info.Pipeline = viewDefinition(collOpts)
+			}
+		}
+		collections = append(collections, info)
 	}
 
 	// Sort by name
@@ -109,3 +120,19 @@ func (s *Service) ListCollections(connID, dbName string) ([]types.CollectionInfo
 
 	return collections, nil
 }
+
+// timeSeriesOptions extracts the time-series settings from listCollections options,
+// or returns nil for other collections.
+func timeSeriesOptions(collOpts bson.M) *types.TimeSeriesOptions {
+	ts, ok := collOpts["timeseries"].(bson.M)
+	if !ok {
+		return nil
+	}
+	return &types.TimeSeriesOptions{
+		TimeField:            bsonutil.ToString(ts["timeField"]),
+		MetaField:            bsonutil.ToString(ts["metaField"]),
+		Granularity:          bsonutil.ToString(ts["granularity"]),
+		BucketMaxSpanSeconds: bsonutil.ToInt64(ts["bucketMaxSpanSeconds"]),
+		ExpireAfterSeconds:   bsonutil.ToInt64(collOpts["expireAfterSeconds"]),
+	}
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTimeSeriesOptions(t *testing.T) {
+	if got := timeSeriesOptions(bson.M{"capped": true, "size": int64(4096)}); got != nil {
+		t.Errorf("regular collection: got %+v, want nil", got)
+	}
+
+	got := timeSeriesOptions(bson.M{
+		"timeseries": bson.M{
+			"timeField":            "ts",
+			"metaField":            "sensor",
+			"granularity":          "minutes",
+			"bucketMaxSpanSeconds": int32(86400),
+		},
+		"expireAfterSeconds": int64(3600),
+	})
+	if got == nil {
+		t.Fatal("time-series collection: got nil")
+	}
+	if got.TimeField != "ts" || got.MetaField != "sensor" || got.Granularity != "minutes" {
+		t.Errorf("fields = %+v", got)
+	}
+	if got.BucketMaxSpanSeconds != 86400 || got.ExpireAfterSeconds != 3600 {
+		t.Errorf("bucketMaxSpanSeconds/expireAfterSeconds = %d/%d, want 86400/3600", got.BucketMaxSpanSeconds, got.ExpireAfterSeconds)
+	}
+}
@@ -3,25 +3,34 @@ package database
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 
-	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
 // DropDatabase drops an entire database.
 func (s *Service) DropDatabase(connID, dbName string) error {
+	if err := s.state.CheckWritable(connID, "drop database"); err != nil {
+		return err
+	}
+
 	if err := ValidateDatabaseName(dbName); err != nil {
 		return err
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
+	if err := s.state.RequireConfirmation(connID, "drop database", dbName); err != nil {
+		return err
+	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	err = client.Database(dbName).Drop(ctx)
@@ -34,16 +43,23 @@ func (s *Service) DropDatabase(connID, dbName string) error {
 
 // DropCollection drops a collection from a database.
 func (s *Service) DropCollection(connID, dbName, collName string) error {
+	if err := s.state.CheckWritable(connID, "drop collection"); err != nil {
+		return err
+	}
+
 	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
 		return err
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
+	if err := s.state.RequireConfirmation(connID, "drop collection", dbName+"."+collName); err != nil {
+		return err
+	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	err = client.Database(dbName).Collection(collName).Drop(ctx)
@@ -54,18 +70,76 @@ func (s *Service) DropCollection(connID, dbName, collName string) error {
 	return nil
 }
 
+// RenameCollection renames a collection within its database. With dropTarget, an existing
+// collection named newName is dropped first, which on connections with safety settings needs
+// a confirmed "rename collection with drop" token for the target namespace; otherwise the
+// rename fails if it exists.
+func (s *Service) RenameCollection(connID, dbName, oldName, newName string, dropTarget bool) error {
+	if err := s.state.CheckWritable(connID, "rename collection"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, oldName); err != nil {
+		return err
+	}
+	if err := ValidateCollectionName(newName); err != nil {
+		return err
+	}
+	if oldName == newName {
+		return fmt.Errorf("new name is the same as the current name")
+	}
+	if dropTarget {
+		if err := s.state.RequireConfirmation(connID, "rename collection with drop", dbName+"."+newName); err != nil {
+			return err
+		}
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cmd := bson.D{
+		{Key: "renameCollection", Value: dbName + "." + oldName},
+		{Key: "to", Value: dbName + "." + newName},
+		{Key: "dropTarget", Value: dropTarget},
+	}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to rename collection: %w", err)
+	}
+
+	debug.LogQuery("Collection renamed", map[string]interface{}{
+		"database":   dbName,
+		"from":       oldName,
+		"to":         newName,
+		"dropTarget": dropTarget,
+	})
+
+	return nil
+}
+
 // ClearCollection deletes all documents from a collection but keeps the collection.
 func (s *Service) ClearCollection(connID, dbName, collName string) error {
+	if err := s.state.CheckWritable(connID, "clear collection"); err != nil {
+		return err
+	}
+
 	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
 		return err
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
+	if err := s.state.RequireConfirmation(connID, "clear collection", dbName+"."+collName); err != nil {
+		return err
+	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	coll := client.Database(dbName).Collection(collName)
@@ -79,12 +153,12 @@ func (s *Service) ClearCollection(connID, dbName, collName string) error {
 
 // GetCollectionsForExport returns collections with their stats for export selection.
 func (s *Service) GetCollectionsForExport(connID, dbName string) ([]types.CollectionExportInfo, error) {
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	db := client.Database(dbName)
@@ -143,3 +217,241 @@ func (s *Service) GetCollectionsForExport(connID, dbName string) ([]types.Collec
 
 	return collections, nil
 }
+
+// createCollectionOptions are the create command options CreateCollection accepts.
+var createCollectionOptions = map[string]bool{
+	"capped":                       true,
+	"size":                         true,
+	"max":                          true,
+	"collation":                    true,
+	"validator":                    true,
+	"validationLevel":              true,
+	"validationAction":             true,
+	"clusteredIndex":               true,
+	"timeseries":                   true,
+	"expireAfterSeconds":           true,
+	"storageEngine":                true,
+	"indexOptionDefaults":          true,
+	"changeStreamPreAndPostImages": true,
+}
+
+// CreateDatabase creates a database by creating its first collection, since MongoDB has no
+// empty databases. It refuses names that exist already, including ones differing only in
+// case, which the server would reject on first write.
+func (s *Service) CreateDatabase(connID, dbName, initialCollection string) error {
+	if err := s.state.CheckWritable(connID, "create database"); err != nil {
+		return err
+	}
+
+	if err := ValidateNewDatabaseName(dbName); err != nil {
+		return err
+	}
+	if err := ValidateCollectionName(initialCollection); err != nil {
+		return err
+	}
+	if strings.HasPrefix(initialCollection, "system.") {
+		return &InvalidNameError{Type: "collection", Name: initialCollection, Reason: "system collections cannot be created"}
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	names, err := client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return fmt.Errorf("failed to list databases: %w", err)
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, dbName) {
+			return fmt.Errorf("database %q already exists", name)
+		}
+	}
+
+	if err := client.Database(dbName).CreateCollection(ctx, initialCollection); err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	debug.LogQuery("Database created", map[string]interface{}{
+		"database":   dbName,
+		"collection": initialCollection,
+	})
+
+	return nil
+}
+
+// CreateCollection explicitly creates a collection. optionsJSON is an Extended JSON document
+// of create command options (empty for defaults), e.g. {"capped": true, "size": 1048576},
+// {"validator": {"$jsonSchema": {...}}, "validationLevel": "moderate"}, or
+// {"timeseries": {"timeField": "ts"}}.
+func (s *Service) CreateCollection(connID, dbName, collName, optionsJSON string) error {
+	if err := s.state.CheckWritable(connID, "create collection"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return err
+	}
+
+	cmd, err := BuildCreateCollectionCommand(collName, optionsJSON)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := client.Database(dbName).RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	debug.LogQuery("Collection created", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"options":    optionsJSON,
+	})
+
+	return nil
+}
+
+// BuildCreateCollectionCommand validates create options and returns the create command.
+// Options are checked here so mistakes are reported clearly instead of as server errors.
+func BuildCreateCollectionCommand(collName, optionsJSON string) (bson.D, error) {
+	var opts bson.D
+	if strings.TrimSpace(optionsJSON) != "" {
+		if err := bsonutil.UnmarshalExtJSON([]byte(optionsJSON), false, &opts); err != nil {
+			return nil, fmt.Errorf("invalid collection options: %w", err)
+		}
+	}
+
+	values := make(map[string]interface{}, len(opts))
+	for _, opt := range opts {
+		if !createCollectionOptions[opt.Key] {
+			return nil, fmt.Errorf("unsupported collection option %q", opt.Key)
+		}
+		values[opt.Key] = opt.Value
+	}
+
+	capped, _ := values["capped"].(bool)
+	if capped {
+		if bsonutil.ToInt64(values["size"]) <= 0 {
+			return nil, fmt.Errorf("capped collections require a positive size in bytes")
+		}
+	} else if values["size"] != nil || values["max"] != nil {
+		return nil, fmt.Errorf("size and max apply only to capped collections")
+	}
+	if values["max"] != nil && bsonutil.ToInt64(values["max"]) <= 0 {
+		return nil, fmt.Errorf("max must be a positive document count")
+	}
+
+	if v, ok := values["validator"]; ok {
+		if _, isDoc := v.(bson.D); !isDoc {
+			return nil, fmt.Errorf("validator must be a document")
+		}
+	}
+	if v, ok := values["validationLevel"]; ok {
+		switch v {
+		case "off", "strict", "moderate":
+		default:
+			return nil, fmt.Errorf("validationLevel must be \"off\", \"strict\", or \"moderate\"")
+		}
+	}
+	if v, ok := values["validationAction"]; ok {
+		switch v {
+		case "error", "warn":
+		default:
+			return nil, fmt.Errorf("validationAction must be \"error\" or \"warn\"")
+		}
+	}
+
+	if v, ok := values["clusteredIndex"]; ok {
+		if err := validateClusteredIndex(v); err != nil {
+			return nil, err
+		}
+		if capped {
+			return nil, fmt.Errorf("clustered collections cannot be capped")
+		}
+	}
+	if v, ok := values["timeseries"]; ok {
+		if err := validateTimeseriesOptions(v); err != nil {
+			return nil, err
+		}
+		if capped || values["clusteredIndex"] != nil {
+			return nil, fmt.Errorf("time-series collections cannot be capped or clustered")
+		}
+	}
+	if v, ok := values["expireAfterSeconds"]; ok {
+		if values["timeseries"] == nil && values["clusteredIndex"] == nil {
+			return nil, fmt.Errorf("expireAfterSeconds applies only to time-series and clustered collections")
+		}
+		if bsonutil.ToInt64(v) <= 0 {
+			return nil, fmt.Errorf("expireAfterSeconds must be positive")
+		}
+	}
+
+	return append(bson.D{{Key: "create", Value: collName}}, opts...), nil
+}
+
+// validateClusteredIndex checks a clusteredIndex option, which must be {key: {_id: 1}, unique: true}.
+func validateClusteredIndex(v interface{}) error {
+	spec, ok := v.(bson.D)
+	if !ok {
+		return fmt.Errorf("clusteredIndex must be a document")
+	}
+	key, _ := docValue(spec, "key").(bson.D)
+	if len(key) != 1 || key[0].Key != "_id" || bsonutil.ToInt64(key[0].Value) != 1 {
+		return fmt.Errorf("clusteredIndex key must be {\"_id\": 1}")
+	}
+	if unique, _ := docValue(spec, "unique").(bool); !unique {
+		return fmt.Errorf("clusteredIndex must be unique")
+	}
+	return nil
+}
+
+// validateTimeseriesOptions checks a timeseries option: timeField is required, and
+// granularity and the bucket settings cannot be combined.
+func validateTimeseriesOptions(v interface{}) error {
+	spec, ok := v.(bson.D)
+	if !ok {
+		return fmt.Errorf("timeseries must be a document")
+	}
+	m := make(map[string]interface{}, len(spec))
+	for _, e := range spec {
+		m[e.Key] = e.Value
+	}
+	if bsonutil.ToString(m["timeField"]) == "" {
+		return fmt.Errorf("timeseries requires a timeField")
+	}
+	if meta, ok := m["metaField"]; ok && (bsonutil.ToString(meta) == "" || meta == m["timeField"]) {
+		return fmt.Errorf("timeseries metaField must be a field other than timeField")
+	}
+	if g, ok := m["granularity"]; ok {
+		switch g {
+		case "seconds", "minutes", "hours":
+		default:
+			return fmt.Errorf("timeseries granularity must be \"seconds\", \"minutes\", or \"hours\"")
+		}
+		if m["bucketMaxSpanSeconds"] != nil || m["bucketRoundingSeconds"] != nil {
+			return fmt.Errorf("timeseries granularity cannot be combined with bucketMaxSpanSeconds or bucketRoundingSeconds")
+		}
+	}
+	return nil
+}
+
+// docValue returns the value of key in doc, or nil.
+func docValue(doc bson.D, key string) interface{} {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestBuildCreateCollectionCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		errMsg  string
+	}{
+		{"no options", "", ""},
+		{"empty document", `{}`, ""},
+		{"capped", `{"capped": true, "size": 1048576, "max": 1000}`, ""},
+		{"capped without size", `{"capped": true}`, "positive size"},
+		{"size without capped", `{"size": 1024}`, "only to capped"},
+		{"capped zero max", `{"capped": true, "size": 1024, "max": 0}`, "positive document count"},
+		{"collation", `{"collation": {"locale": "en", "strength": 2}}`, ""},
+		{"validator", `{"validator": {"$jsonSchema": {"required": ["name"]}}, "validationLevel": "moderate", "validationAction": "warn"}`, ""},
+		{"validator not a document", `{"validator": "x"}`, "must be a document"},
+		{"bad validationLevel", `{"validationLevel": "loose"}`, "validationLevel"},
+		{"bad validationAction", `{"validationAction": "reject"}`, "validationAction"},
+		{"clustered", `{"clusteredIndex": {"key": {"_id": 1}, "unique": true}, "expireAfterSeconds": 3600}`, ""},
+		{"clustered wrong key", `{"clusteredIndex": {"key": {"ts": 1}, "unique": true}}`, "key must be"},
+		{"clustered not unique", `{"clusteredIndex": {"key": {"_id": 1}}}`, "must be unique"},
+		{"timeseries", `{"timeseries": {"timeField": "ts", "metaField": "sensor", "granularity": "minutes"}}`, ""},
+		{"timeseries without timeField", `{"timeseries": {"metaField": "sensor"}}`, "requires a timeField"},
+		{"timeseries same meta field", `{"timeseries": {"timeField": "ts", "metaField": "ts"}}`, "metaField"},
+		{"timeseries bad granularity", `{"timeseries": {"timeField": "ts", "granularity": "days"}}`, "granularity"},
+		{"timeseries capped", `{"timeseries": {"timeField": "ts"}, "capped": true, "size": 10}`, "cannot be capped"},
+		{"ttl on regular collection", `{"expireAfterSeconds": 60}`, "only to time-series"},
+		{"unknown option", `{"autoIndexId": false}`, "unsupported collection option"},
+		{"invalid json", `{"capped": }`, "invalid collection options"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := BuildCreateCollectionCommand("events", tt.options)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(cmd) == 0 || cmd[0].Key != "create" || cmd[0].Value != "events" {
+				t.Errorf("command must start with create: events, got %v", cmd)
+			}
+		})
+	}
+}
+
+func TestCreateCollection_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	err := svc.CreateCollection("conn-1", "db", "events", `{"capped": true, "size": 4096}`)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestOperations_ReadOnly(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	svc := NewService(state)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"drop database", func() error { return svc.DropDatabase("ro", "db") }},
+		{"drop collection", func() error { return svc.DropCollection("ro", "db", "coll") }},
+		{"rename collection", func() error { return svc.RenameCollection("ro", "db", "a", "b", false) }},
+		{"clear collection", func() error { return svc.ClearCollection("ro", "db", "coll") }},
+		{"create collection", func() error { return svc.CreateCollection("ro", "db", "coll", "") }},
+		{"create index", func() error {
+			_, err := svc.CreateIndex("ro", "db", "coll", `{"a": 1}`, types.IndexOptions{})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var readOnly *core.ReadOnlyError
+			if !errors.As(err, &readOnly) {
+				t.Fatalf("Expected ReadOnlyError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRenameCollection_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+		errMsg  string
+	}{
+		{"same name", "users", "users", "same as the current name"},
+		{"empty new name", "users", "", "cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.RenameCollection("conn-1", "db", tt.oldName, tt.newName, false)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	err := svc.RenameCollection("conn-1", "db", "users", "people", true)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+
+	svc.state.SavedConnections = []types.SavedConnection{{ID: "safe", RequireDeleteConfirmation: true}}
+	var required *core.ConfirmationRequiredError
+	if err := svc.RenameCollection("safe", "db", "users", "people", true); !errors.As(err, &required) || required.Target != "db.people" {
+		t.Errorf("Expected ConfirmationRequiredError for db.people, got %v", err)
+	}
+	if err := svc.RenameCollection("safe", "db", "users", "people", false); !errors.As(err, &notConnected) {
+		t.Errorf("Rename without drop should need no confirmation, got %v", err)
+	}
+}
+
+func TestCreateDatabase_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name       string
+		dbName     string
+		collection string
+		errMsg     string
+	}{
+		{"reserved", "admin", "users", "reserved"},
+		{"slash", "a/b", "users", "invalid character"},
+		{"missing collection", "shop", "", "cannot be empty"},
+		{"system collection", "shop", "system.js", "system collections"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CreateDatabase("conn-1", tt.dbName, tt.collection)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	err := svc.CreateDatabase("conn-1", "shop", "orders")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
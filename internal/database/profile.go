@@ -8,7 +8,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/peternagy/mongopal/internal/bsonutil"
-	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -23,12 +22,12 @@ func (s *Service) GetCollectionProfile(connID, dbName, collName string) (*types.
 		return nil, err
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	db := client.Database(dbName)
@@ -113,4 +112,3 @@ func walkFields(prefix string, doc bson.M, paths map[string]bool, depth int, max
 		}
 	}
 }
-
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+const (
+	// DefaultProfiledOperations is how many profiled operations are listed when no limit is given.
+	DefaultProfiledOperations = 100
+	// MaxProfiledOperations caps the profiled operations listed at once.
+	MaxProfiledOperations = 1000
+	// maxProfiledCommandLength caps the command shown for a profiled operation.
+	maxProfiledCommandLength = 4096
+)
+
+// GetProfilerStatus returns the profiler level and slow operation threshold of a database.
+func (s *Service) GetProfilerStatus(connID, dbName string) (*types.ProfilerStatus, error) {
+	if err := ValidateDatabaseName(dbName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	var result bson.M
+	if err := client.Database(dbName).RunCommand(ctx, bson.D{{Key: "profile", Value: -1}}).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to get profiler status: %w", err)
+	}
+	return parseProfilerStatus(dbName, result), nil
+}
+
+// SetProfilerLevel sets the profiler level of a database: 0 off, 1 operations slower than
+// slowms, 2 all operations. A negative slowms keeps the current threshold.
+func (s *Service) SetProfilerLevel(connID, dbName string, level int, slowms int64) error {
+	if err := s.state.CheckWritable(connID, "set profiler level"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseName(dbName); err != nil {
+		return err
+	}
+	if level < 0 || level > 2 {
+		return fmt.Errorf("profiler level must be 0, 1, or 2")
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cmd := bson.D{{Key: "profile", Value: level}}
+	if slowms >= 0 {
+		cmd = append(cmd, bson.E{Key: "slowms", Value: slowms})
+	}
+	if err := client.Database(dbName).RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to set profiler level: %w", err)
+	}
+
+	debug.LogQuery("Profiler level changed", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"level":        level,
+		"slowms":       slowms,
+	})
+	return nil
+}
+
+// ListProfiledOperations returns the most recent operations in a database's system.profile
+// collection, newest first. filter is an optional Extended JSON query on the profile
+// documents, e.g. {"millis": {"$gt": 100}}.
+func (s *Service) ListProfiledOperations(connID, dbName, filter string, limit int64) ([]types.ProfiledOperation, error) {
+	if err := ValidateDatabaseName(dbName); err != nil {
+		return nil, err
+	}
+	query := bson.M{}
+	if f := strings.TrimSpace(filter); f != "" && f != "{}" {
+		if err := bsonutil.UnmarshalExtJSON([]byte(f), true, &query); err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultProfiledOperations
+	}
+	limit = min(limit, MaxProfiledOperations)
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "ts", Value: -1}}).SetLimit(limit)
+	cursor, err := client.Database(dbName).Collection("system.profile").Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiled operations: %w", err)
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to list profiled operations: %w", err)
+	}
+
+	ops := make([]types.ProfiledOperation, 0, len(docs))
+	for _, doc := range docs {
+		ops = append(ops, parseProfiledOperation(doc))
+	}
+	return ops, nil
+}
+
+// parseProfilerStatus converts the result of {profile: -1}.
+func parseProfilerStatus(dbName string, result bson.M) *types.ProfilerStatus {
+	status := &types.ProfilerStatus{
+		Database:   dbName,
+		Level:      bsonutil.ToInt(result["was"]),
+		SlowMs:     bsonutil.ToInt64(result["slowms"]),
+		SampleRate: bsonutil.ToFloat64(result["sampleRate"]),
+	}
+	if f, ok := result["filter"].(bson.M); ok {
+		if data, err := bson.MarshalExtJSON(f, false, false); err == nil {
+			status.Filter = string(data)
+		}
+	}
+	return status
+}
+
+// parseProfiledOperation converts a system.profile document.
+func parseProfiledOperation(doc bson.M) types.ProfiledOperation {
+	op := types.ProfiledOperation{
+		Op:             bsonutil.ToString(doc["op"]),
+		Namespace:      bsonutil.ToString(doc["ns"]),
+		Millis:         bsonutil.ToInt64(doc["millis"]),
+		PlanSummary:    bsonutil.ToString(doc["planSummary"]),
+		KeysExamined:   bsonutil.ToInt64(doc["keysExamined"]),
+		DocsExamined:   bsonutil.ToInt64(doc["docsExamined"]),
+		NReturned:      bsonutil.ToInt64(doc["nreturned"]),
+		ResponseLength: bsonutil.ToInt64(doc["responseLength"]),
+		User:           bsonutil.ToString(doc["user"]),
+		Client:         bsonutil.ToString(doc["client"]),
+		AppName:        bsonutil.ToString(doc["appName"]),
+	}
+	if ts, ok := doc["ts"].(primitive.DateTime); ok {
+		op.Timestamp = ts.Time()
+	}
+	if cmd, ok := doc["command"].(bson.M); ok {
+		if data, err := bson.MarshalExtJSON(cmd, false, false); err == nil {
+			if len(data) > maxProfiledCommandLength {
+				data = append(data[:maxProfiledCommandLength], "…"...)
+			}
+			op.Command = string(data)
+		}
+	}
+	return op
+}
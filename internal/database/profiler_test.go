@@ -0,0 +1,68 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseProfiledOperation(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	op := parseProfiledOperation(bson.M{
+		"ts":           primitive.NewDateTimeFromTime(ts),
+		"op":           "query",
+		"ns":           "shop.orders",
+		"millis":       int32(742),
+		"planSummary":  "COLLSCAN",
+		"keysExamined": int32(0),
+		"docsExamined": int64(250000),
+		"nreturned":    int32(12),
+		"user":         "app@admin",
+		"command":      bson.M{"find": "orders", "filter": bson.M{"status": "open"}},
+	})
+	if !op.Timestamp.Equal(ts) || op.Millis != 742 || op.PlanSummary != "COLLSCAN" {
+		t.Errorf("unexpected operation: %+v", op)
+	}
+	if op.DocsExamined != 250000 || op.NReturned != 12 || op.KeysExamined != 0 {
+		t.Errorf("unexpected counters: %+v", op)
+	}
+	if !strings.Contains(op.Command, `"find":"orders"`) {
+		t.Errorf("command = %s", op.Command)
+	}
+
+	status := parseProfilerStatus("shop", bson.M{"was": int32(1), "slowms": int32(100), "sampleRate": 1.0})
+	if status.Level != 1 || status.SlowMs != 100 || status.SampleRate != 1 || status.Database != "shop" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestProfiler_Validation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	svc := NewService(state)
+
+	var readOnly *core.ReadOnlyError
+	if err := svc.SetProfilerLevel("ro", "shop", 1, 100); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+	if err := svc.SetProfilerLevel("conn-1", "shop", 3, 100); err == nil || !strings.Contains(err.Error(), "level") {
+		t.Errorf("bad level: got %v", err)
+	}
+	if _, err := svc.ListProfiledOperations("conn-1", "shop", `{"millis": }`, 10); err == nil || !strings.Contains(err.Error(), "invalid filter") {
+		t.Errorf("bad filter: got %v", err)
+	}
+	if _, err := svc.GetProfilerStatus("conn-1", ""); err == nil {
+		t.Error("expected error for empty database name")
+	}
+	var notConnected *core.NotConnectedError
+	if _, err := svc.ListProfiledOperations("conn-1", "shop", `{"millis": {"$gt": 100}}`, 0); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
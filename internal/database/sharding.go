@@ -0,0 +1,385 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// GetShardingInfo reads a collection's sharding metadata from the config database: its
+// shard key, how its chunks are spread over the shards, and the balancer state. On a
+// deployment that is not sharded it returns ClusterSharded false rather than an error.
+func (s *Service) GetShardingInfo(connID, dbName, collName string) (*types.ShardingInfo, error) {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	ns := dbName + "." + collName
+	info := &types.ShardingInfo{Namespace: ns, Chunks: []types.ShardChunks{}}
+
+	mongos, err := isMongos(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if !mongos {
+		return info, nil
+	}
+	info.ClusterSharded = true
+
+	config := client.Database("config")
+
+	var dbDoc bson.M
+	if err := config.Collection("databases").FindOne(ctx, bson.D{{Key: "_id", Value: dbName}}).Decode(&dbDoc); err == nil {
+		info.PrimaryShard = bsonutil.ToString(dbDoc["primary"])
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to read database metadata: %w", err)
+	}
+
+	var collDoc struct {
+		Key       bson.D      `bson:"key"`
+		Unique    bool        `bson:"unique"`
+		Dropped   bool        `bson:"dropped"`
+		NoBalance bool        `bson:"noBalance"`
+		UUID      interface{} `bson:"uuid"`
+	}
+	err = config.Collection("collections").FindOne(ctx, bson.D{{Key: "_id", Value: ns}}).Decode(&collDoc)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to read collection metadata: %w", err)
+	}
+	info.Balancer = balancerStatus(ctx, client)
+	if err != nil || collDoc.Dropped {
+		return info, nil
+	}
+
+	info.Sharded = true
+	info.ShardKey = indexKeysJSON(collDoc.Key)
+	info.Unique = collDoc.Unique
+	info.BalancingEnabled = !collDoc.NoBalance
+
+	shards, err := listShardNames(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chunks reference their collection by UUID since 5.0 and by namespace before
+	chunkFilter := bson.D{{Key: "ns", Value: ns}}
+	if collDoc.UUID != nil {
+		chunkFilter = bson.D{{Key: "$or", Value: bson.A{chunkFilter, bson.D{{Key: "uuid", Value: collDoc.UUID}}}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: chunkFilter}},
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$shard"}, {Key: "chunks", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	}
+	cursor, err := config.Collection("chunks").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk distribution: %w", err)
+	}
+	var counts []bson.M
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to read chunk distribution: %w", err)
+	}
+	info.Chunks, info.TotalChunks = chunkDistribution(shards, counts)
+
+	// balancerCollectionStatus needs 4.4+
+	var compliance bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "balancerCollectionStatus", Value: ns}}).Decode(&compliance); err == nil {
+		info.BalancerCompliant = bsonutil.ToBool(compliance["balancerCompliant"])
+		info.ComplianceViolation = bsonutil.ToString(compliance["firstComplianceViolation"])
+	}
+
+	debug.LogQuery("Sharding info loaded", map[string]interface{}{
+		"namespace":   ns,
+		"shardKey":    info.ShardKey,
+		"totalChunks": info.TotalChunks,
+	})
+
+	return info, nil
+}
+
+// EnableSharding enables sharding for a database. Since MongoDB 6.0 this is implicit, but
+// older clusters require it before ShardCollection.
+func (s *Service) EnableSharding(connID, dbName string) error {
+	if err := s.state.CheckWritable(connID, "enable sharding"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseName(dbName); err != nil {
+		return err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := requireMongos(ctx, client); err != nil {
+		return err
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "enableSharding", Value: dbName}}).Err(); err != nil {
+		return fmt.Errorf("failed to enable sharding: %w", err)
+	}
+
+	debug.LogQuery("Sharding enabled", map[string]interface{}{
+		"database": dbName,
+	})
+
+	return nil
+}
+
+// ShardCollection shards a collection on shardKeyJSON, e.g. {"customerId": 1} or
+// {"_id": "hashed"}. Before sending the command it checks that a non-empty collection has
+// an index starting with the shard key (a unique one if unique is set), since the server
+// only creates that index itself for empty collections.
+func (s *Service) ShardCollection(connID, dbName, collName, shardKeyJSON string, unique bool) error {
+	if err := s.state.CheckWritable(connID, "shard collection"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return err
+	}
+	key, err := ParseShardKey(shardKeyJSON)
+	if err != nil {
+		return err
+	}
+	if unique && isHashedShardKey(key) {
+		return fmt.Errorf("a hashed shard key cannot be unique")
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := requireMongos(ctx, client); err != nil {
+		return err
+	}
+
+	coll := client.Database(dbName).Collection(collName)
+	indexes, err := listShardKeyIndexes(ctx, coll)
+	if err != nil {
+		return err
+	}
+	if !hasSupportingIndex(indexes, key, unique) {
+		// The server creates the shard key index for an empty collection
+		count, err := coll.EstimatedDocumentCount(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count documents: %w", err)
+		}
+		if count > 0 {
+			kind := "an index"
+			if unique {
+				kind = "a unique index"
+			}
+			return fmt.Errorf("no index supports shard key %s; create %s starting with the shard key first", indexKeysJSON(key), kind)
+		}
+	}
+
+	cmd := bson.D{
+		{Key: "shardCollection", Value: dbName + "." + collName},
+		{Key: "key", Value: key},
+		{Key: "unique", Value: unique},
+	}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to shard collection: %w", err)
+	}
+
+	debug.LogQuery("Collection sharded", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"shardKey":   indexKeysJSON(key),
+		"unique":     unique,
+	})
+
+	return nil
+}
+
+// ParseShardKey parses a shard key given as Extended JSON. Fields are ranged (1) or, for at
+// most one field, "hashed".
+func ParseShardKey(shardKeyJSON string) (bson.D, error) {
+	key, err := ParseIndexKeys(shardKeyJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard key: %w", err)
+	}
+	hashed := 0
+	for _, field := range key {
+		switch field.Value {
+		case int32(1):
+		case "hashed":
+			hashed++
+		default:
+			return nil, fmt.Errorf("shard key field %q must be 1 or \"hashed\"", field.Key)
+		}
+		if strings.Contains(field.Key, "$") {
+			return nil, fmt.Errorf("shard key field %q cannot contain '$'", field.Key)
+		}
+	}
+	if hashed > 1 {
+		return nil, fmt.Errorf("a shard key can have only one hashed field")
+	}
+	return key, nil
+}
+
+// isHashedShardKey reports whether a shard key has a hashed field.
+func isHashedShardKey(key bson.D) bool {
+	for _, field := range key {
+		if field.Value == "hashed" {
+			return true
+		}
+	}
+	return false
+}
+
+// shardKeyIndex is the part of an index spec that matters for sharding.
+type shardKeyIndex struct {
+	Key    bson.D `bson:"key"`
+	Unique bool   `bson:"unique"`
+}
+
+// listShardKeyIndexes returns the key and uniqueness of each index on a collection.
+func listShardKeyIndexes(ctx context.Context, coll *mongo.Collection) ([]shardKeyIndex, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	var indexes []shardKeyIndex
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+// hasSupportingIndex reports whether an index starts with the shard key, and is unique if
+// required. The _id index counts as unique.
+func hasSupportingIndex(indexes []shardKeyIndex, key bson.D, unique bool) bool {
+	for _, idx := range indexes {
+		if len(idx.Key) < len(key) {
+			continue
+		}
+		isUnique := idx.Unique || (len(idx.Key) == 1 && idx.Key[0].Key == "_id" && idx.Key[0].Value != "hashed")
+		if unique && (!isUnique || len(idx.Key) != len(key)) {
+			continue
+		}
+		prefix := true
+		for i, field := range key {
+			if idx.Key[i].Key != field.Key || !sameKeyType(idx.Key[i].Value, field.Value) {
+				prefix = false
+				break
+			}
+		}
+		if prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// sameKeyType compares index key values, treating numeric directions of any BSON type alike.
+func sameKeyType(a, b interface{}) bool {
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+	if aIsString || bIsString {
+		return aIsString && bIsString && as == bs
+	}
+	return bsonutil.ToFloat64(a) == bsonutil.ToFloat64(b)
+}
+
+// requireMongos returns an error unless the client is connected to a mongos router.
+func requireMongos(ctx context.Context, client *mongo.Client) error {
+	mongos, err := isMongos(ctx, client)
+	if err != nil {
+		return err
+	}
+	if !mongos {
+		return fmt.Errorf("sharding commands must be run through mongos")
+	}
+	return nil
+}
+
+// isMongos reports whether the client is connected to a mongos router.
+func isMongos(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, fmt.Errorf("failed to check server role: %w", err)
+	}
+	return bsonutil.ToString(hello["msg"]) == "isdbgrid", nil
+}
+
+// listShardNames returns the names of the shards in the cluster.
+func listShardNames(ctx context.Context, client *mongo.Client) ([]string, error) {
+	cursor, err := client.Database("config").Collection("shards").Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shards: %w", err)
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to list shards: %w", err)
+	}
+	names := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		names = append(names, bsonutil.ToString(doc["_id"]))
+	}
+	return names, nil
+}
+
+// balancerStatus returns the balancer state, or nil if it can't be read.
+func balancerStatus(ctx context.Context, client *mongo.Client) *types.BalancerStatus {
+	var res bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "balancerStatus", Value: 1}}).Decode(&res); err != nil {
+		return nil
+	}
+	return &types.BalancerStatus{
+		Mode:    bsonutil.ToString(res["mode"]),
+		InRound: bsonutil.ToBool(res["inBalancerRound"]),
+		Rounds:  bsonutil.ToInt64(res["numBalancerRounds"]),
+	}
+}
+
+// chunkDistribution turns per-shard chunk counts into a list covering every shard, sorted
+// by shard name, so shards without chunks are shown too.
+func chunkDistribution(shards []string, counts []bson.M) ([]types.ShardChunks, int64) {
+	byShard := make(map[string]int64, len(shards))
+	for _, name := range shards {
+		byShard[name] = 0
+	}
+	var total int64
+	for _, c := range counts {
+		n := bsonutil.ToInt64(c["chunks"])
+		byShard[bsonutil.ToString(c["_id"])] += n
+		total += n
+	}
+
+	dist := make([]types.ShardChunks, 0, len(byShard))
+	for name, n := range byShard {
+		sc := types.ShardChunks{Shard: name, Chunks: n}
+		if total > 0 {
+			sc.Percent = float64(n) * 100 / float64(total)
+		}
+		dist = append(dist, sc)
+	}
+	sort.Slice(dist, func(i, j int) bool { return dist[i].Shard < dist[j].Shard })
+	return dist, total
+}
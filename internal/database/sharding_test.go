@@ -0,0 +1,139 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestChunkDistribution(t *testing.T) {
+	shards := []string{"shard-b", "shard-a", "shard-c"}
+	counts := []bson.M{
+		{"_id": "shard-a", "chunks": int32(3)},
+		{"_id": "shard-b", "chunks": int32(1)},
+	}
+
+	dist, total := chunkDistribution(shards, counts)
+	if total != 4 {
+		t.Fatalf("total = %d, want 4", total)
+	}
+	if len(dist) != 3 {
+		t.Fatalf("got %d shards, want 3", len(dist))
+	}
+	want := []struct {
+		shard   string
+		chunks  int64
+		percent float64
+	}{
+		{"shard-a", 3, 75},
+		{"shard-b", 1, 25},
+		{"shard-c", 0, 0},
+	}
+	for i, w := range want {
+		if dist[i].Shard != w.shard || dist[i].Chunks != w.chunks || dist[i].Percent != w.percent {
+			t.Errorf("dist[%d] = %+v, want %+v", i, dist[i], w)
+		}
+	}
+}
+
+func TestChunkDistribution_NoChunks(t *testing.T) {
+	dist, total := chunkDistribution([]string{"s0"}, nil)
+	if total != 0 || len(dist) != 1 || dist[0].Percent != 0 {
+		t.Errorf("dist = %+v total = %d", dist, total)
+	}
+}
+
+func TestGetShardingInfo_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.GetShardingInfo("conn-1", "db", "users")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestParseShardKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		errMsg string
+	}{
+		{"ranged", `{"customerId": 1}`, ""},
+		{"compound", `{"region": 1, "customerId": 1}`, ""},
+		{"hashed", `{"_id": "hashed"}`, ""},
+		{"compound hashed", `{"region": 1, "userId": "hashed"}`, ""},
+		{"descending", `{"a": -1}`, "must be 1 or \"hashed\""},
+		{"text", `{"a": "text"}`, "must be 1 or \"hashed\""},
+		{"two hashed", `{"a": "hashed", "b": "hashed"}`, "only one hashed field"},
+		{"wildcard", `{"$**": 1}`, "cannot contain '$'"},
+		{"invalid", ``, "invalid shard key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseShardKey(tt.input)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestHasSupportingIndex(t *testing.T) {
+	indexes := []shardKeyIndex{
+		{Key: bson.D{{Key: "_id", Value: int32(1)}}},
+		{Key: bson.D{{Key: "region", Value: int64(1)}, {Key: "createdAt", Value: float64(1)}}},
+		{Key: bson.D{{Key: "userId", Value: "hashed"}}},
+		{Key: bson.D{{Key: "email", Value: int32(1)}}, Unique: true},
+	}
+	tests := []struct {
+		name   string
+		key    bson.D
+		unique bool
+		want   bool
+	}{
+		{"exact", bson.D{{Key: "region", Value: int32(1)}, {Key: "createdAt", Value: int32(1)}}, false, true},
+		{"prefix", bson.D{{Key: "region", Value: int32(1)}}, false, true},
+		{"not a prefix", bson.D{{Key: "createdAt", Value: int32(1)}}, false, false},
+		{"hashed", bson.D{{Key: "userId", Value: "hashed"}}, false, true},
+		{"ranged on hashed index", bson.D{{Key: "userId", Value: int32(1)}}, false, false},
+		{"unique", bson.D{{Key: "email", Value: int32(1)}}, true, true},
+		{"unique on _id", bson.D{{Key: "_id", Value: int32(1)}}, true, true},
+		{"unique needs unique index", bson.D{{Key: "region", Value: int32(1)}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasSupportingIndex(indexes, tt.key, tt.unique); got != tt.want {
+				t.Errorf("hasSupportingIndex = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShardCollection_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	err := svc.ShardCollection("conn-1", "db", "users", `{"_id": "hashed"}`, true)
+	if err == nil || !strings.Contains(err.Error(), "cannot be unique") {
+		t.Fatalf("error = %v, want hashed unique rejection", err)
+	}
+
+	err = svc.ShardCollection("conn-1", "db", "users", `{"region": 1}`, false)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+	if err := svc.EnableSharding("conn-1", "db"); !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -2,11 +2,11 @@ package database
 
 import (
 	"fmt"
+	"sort"
 
 	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/peternagy/mongopal/internal/bsonutil"
-	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -16,12 +16,12 @@ func (s *Service) GetCollectionStats(connID, dbName, collName string) (*types.Co
 		return nil, err
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	db := client.Database(dbName)
@@ -33,17 +33,127 @@ func (s *Service) GetCollectionStats(connID, dbName, collName string) (*types.Co
 		return nil, fmt.Errorf("failed to get collection stats: %w", err)
 	}
 
+	return parseCollectionStats(fmt.Sprintf("%s.%s", dbName, collName), result), nil
+}
+
+// parseCollectionStats builds CollectionStats from collStats output. On mongos the
+// WiredTiger details are only reported per shard, so reusable bytes are summed across
+// shards.
+func parseCollectionStats(namespace string, result bson.M) *types.CollectionStats {
 	stats := &types.CollectionStats{
-		Namespace: fmt.Sprintf("%s.%s", dbName, collName),
+		Namespace:      namespace,
+		Count:          bsonutil.ToInt64(result["count"]),
+		Size:           bsonutil.ToInt64(result["size"]),
+		StorageSize:    bsonutil.ToInt64(result["storageSize"]),
+		AvgObjSize:     bsonutil.ToInt64(result["avgObjSize"]),
+		IndexCount:     bsonutil.ToInt(result["nindexes"]),
+		TotalIndexSize: bsonutil.ToInt64(result["totalIndexSize"]),
+		IndexSizes:     make(map[string]int64),
+		Capped:         bsonutil.ToBool(result["capped"]),
+		Sharded:        bsonutil.ToBool(result["sharded"]),
+	}
+	if stats.Capped {
+		stats.MaxSize = bsonutil.ToInt64(result["maxSize"])
+		stats.MaxDocuments = bsonutil.ToInt64(result["max"])
+	}
+
+	if sizes, ok := result["indexSizes"].(bson.M); ok {
+		for name, size := range sizes {
+			stats.IndexSizes[name] = bsonutil.ToInt64(size)
+		}
+	}
+
+	if shards, ok := result["shards"].(bson.M); ok && result["wiredTiger"] == nil {
+		for _, shard := range shards {
+			if shardStats, ok := shard.(bson.M); ok {
+				stats.FreeStorageSize += reusableBytes(shardStats)
+			}
+		}
+	} else {
+		stats.FreeStorageSize = reusableBytes(result)
+	}
+
+	if used := stats.StorageSize - stats.FreeStorageSize; used > 0 && stats.Size > 0 {
+		stats.CompressionRatio = float64(stats.Size) / float64(used)
+	}
+
+	return stats
+}
+
+// reusableBytes returns the WiredTiger bytes available for reuse from collStats output.
+func reusableBytes(stats bson.M) int64 {
+	wt, _ := stats["wiredTiger"].(bson.M)
+	bm, _ := wt["block-manager"].(bson.M)
+	return bsonutil.ToInt64(bm["file bytes available for reuse"])
+}
+
+// GetDatabaseStats returns dbStats for a database together with collStats for each of its
+// collections, so an overview can be shown in one call. Collections whose stats can't be
+// read are listed with zero sizes.
+func (s *Service) GetDatabaseStats(connID, dbName string) (*types.DatabaseStats, error) {
+	if err := ValidateDatabaseName(dbName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	db := client.Database(dbName)
+
+	var result bson.M
+	if err := db.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to get database stats: %w", err)
 	}
+	stats := parseDatabaseStats(dbName, result)
 
-	stats.Count = bsonutil.ToInt64(result["count"])
-	stats.Size = bsonutil.ToInt64(result["size"])
-	stats.StorageSize = bsonutil.ToInt64(result["storageSize"])
-	stats.AvgObjSize = bsonutil.ToInt64(result["avgObjSize"])
-	stats.IndexCount = bsonutil.ToInt(result["nindexes"])
-	stats.TotalIndexSize = bsonutil.ToInt64(result["totalIndexSize"])
-	stats.Capped = bsonutil.ToBool(result["capped"])
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	stats.Views = 0
+	for _, spec := range specs {
+		if spec.Type == "view" {
+			stats.Views++
+			continue
+		}
+		var collStats bson.M
+		_ = db.RunCommand(ctx, bson.D{{Key: "collStats", Value: spec.Name}}).Decode(&collStats)
+		stats.PerCollection = append(stats.PerCollection, *parseCollectionStats(dbName+"."+spec.Name, collStats))
+	}
+	stats.Collections = len(stats.PerCollection)
+
+	sort.Slice(stats.PerCollection, func(i, j int) bool {
+		return stats.PerCollection[i].Namespace < stats.PerCollection[j].Namespace
+	})
 
 	return stats, nil
 }
+
+// parseDatabaseStats builds DatabaseStats from dbStats output, without the per-collection
+// breakdown. totalSize is only reported since 4.4, so it is derived on older servers.
+func parseDatabaseStats(dbName string, result bson.M) *types.DatabaseStats {
+	stats := &types.DatabaseStats{
+		Database:      dbName,
+		Collections:   bsonutil.ToInt(result["collections"]),
+		Views:         bsonutil.ToInt(result["views"]),
+		Objects:       bsonutil.ToInt64(result["objects"]),
+		DataSize:      bsonutil.ToInt64(result["dataSize"]),
+		StorageSize:   bsonutil.ToInt64(result["storageSize"]),
+		IndexCount:    bsonutil.ToInt(result["indexes"]),
+		IndexSize:     bsonutil.ToInt64(result["indexSize"]),
+		TotalSize:     bsonutil.ToInt64(result["totalSize"]),
+		FsUsedSize:    bsonutil.ToInt64(result["fsUsedSize"]),
+		FsTotalSize:   bsonutil.ToInt64(result["fsTotalSize"]),
+		PerCollection: []types.CollectionStats{},
+	}
+	if stats.TotalSize == 0 {
+		stats.TotalSize = stats.StorageSize + stats.IndexSize
+	}
+	return stats
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestParseCollectionStats(t *testing.T) {
+	result := bson.M{
+		"count":          int32(1000),
+		"size":           int64(400000),
+		"storageSize":    int64(150000),
+		"avgObjSize":     int32(400),
+		"nindexes":       int32(2),
+		"totalIndexSize": int64(60000),
+		"indexSizes":     bson.M{"_id_": int32(40000), "email_1": int64(20000)},
+		"capped":         true,
+		"maxSize":        int64(1 << 20),
+		"max":            int32(5000),
+		"wiredTiger": bson.M{
+			"block-manager": bson.M{"file bytes available for reuse": int64(50000)},
+		},
+	}
+
+	stats := parseCollectionStats("db.events", result)
+	if stats.Count != 1000 || stats.AvgObjSize != 400 || stats.IndexCount != 2 {
+		t.Errorf("counts = %+v", stats)
+	}
+	if stats.IndexSizes["_id_"] != 40000 || stats.IndexSizes["email_1"] != 20000 {
+		t.Errorf("IndexSizes = %v", stats.IndexSizes)
+	}
+	if stats.FreeStorageSize != 50000 {
+		t.Errorf("FreeStorageSize = %d, want 50000", stats.FreeStorageSize)
+	}
+	if stats.CompressionRatio != 4 {
+		t.Errorf("CompressionRatio = %v, want 4", stats.CompressionRatio)
+	}
+	if !stats.Capped || stats.MaxSize != 1<<20 || stats.MaxDocuments != 5000 {
+		t.Errorf("capped = %v maxSize = %d max = %d", stats.Capped, stats.MaxSize, stats.MaxDocuments)
+	}
+}
+
+func TestParseCollectionStats_Sharded(t *testing.T) {
+	shard := func(free int64) bson.M {
+		return bson.M{"wiredTiger": bson.M{"block-manager": bson.M{"file bytes available for reuse": free}}}
+	}
+	result := bson.M{
+		"sharded":     true,
+		"size":        int64(1000),
+		"storageSize": int64(800),
+		"maxSize":     int64(100),
+		"shards":      bson.M{"s0": shard(200), "s1": shard(100)},
+	}
+
+	stats := parseCollectionStats("db.events", result)
+	if !stats.Sharded || stats.FreeStorageSize != 300 {
+		t.Errorf("sharded = %v free = %d, want true and 300", stats.Sharded, stats.FreeStorageSize)
+	}
+	if stats.CompressionRatio != 2 {
+		t.Errorf("CompressionRatio = %v, want 2", stats.CompressionRatio)
+	}
+	if stats.MaxSize != 0 {
+		t.Errorf("MaxSize = %d, want 0 for uncapped collection", stats.MaxSize)
+	}
+}
+
+func TestParseCollectionStats_Empty(t *testing.T) {
+	stats := parseCollectionStats("db.empty", bson.M{})
+	if stats.CompressionRatio != 0 || stats.IndexSizes == nil {
+		t.Errorf("empty stats = %+v", stats)
+	}
+}
+
+func TestParseDatabaseStats(t *testing.T) {
+	result := bson.M{
+		"collections": int32(3),
+		"views":       int32(1),
+		"objects":     int64(5000),
+		"dataSize":    float64(2000000),
+		"storageSize": float64(800000),
+		"indexes":     int32(4),
+		"indexSize":   float64(100000),
+		"fsUsedSize":  float64(1 << 30),
+		"fsTotalSize": float64(1 << 32),
+	}
+
+	stats := parseDatabaseStats("shop", result)
+	if stats.Database != "shop" || stats.Collections != 3 || stats.Views != 1 || stats.Objects != 5000 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.TotalSize != 900000 {
+		t.Errorf("TotalSize = %d, want storage plus index size", stats.TotalSize)
+	}
+	if stats.PerCollection == nil {
+		t.Error("PerCollection should be empty, not nil")
+	}
+
+	result["totalSize"] = float64(950000)
+	if got := parseDatabaseStats("shop", result).TotalSize; got != 950000 {
+		t.Errorf("TotalSize = %d, want reported 950000", got)
+	}
+}
+
+func TestGetDatabaseStats_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.GetDatabaseStats("conn-1", "shop")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,377 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Limits for SuggestIndexes.
+const (
+	// suggestProfileLimit is the number of recent system.profile entries read.
+	suggestProfileLimit = 500
+	// suggestExplainLimit is the number of candidate indexes checked with explain.
+	suggestExplainLimit = 10
+)
+
+// Query shape sources reported in IndexSuggestion.Sources.
+const (
+	SuggestionSourceHistory  = "history"
+	SuggestionSourceProfiler = "profiler"
+)
+
+// queryShape is one analyzed query: its filter and sort, and the index that would serve it.
+type queryShape struct {
+	filter bson.M
+	sort   bson.D
+	keys   bson.D
+	source string
+}
+
+// indexCandidate groups the queries that one proposed index would serve.
+type indexCandidate struct {
+	keys        bson.D
+	example     queryShape
+	sources     map[string]bool
+	occurrences int
+}
+
+// SuggestIndexes proposes indexes for a collection from the given query history, the
+// slow queries recorded in system.profile (when the profiler is on and readable), and
+// explain output for each candidate. Keys follow the equality, sort, range rule. A
+// candidate is dropped when an existing index already starts with its keys, or when
+// explain shows the current plan already examines about as many documents as it returns.
+func (s *Service) SuggestIndexes(connID, dbName, collName string, history []types.QueryHistoryEntry) (*types.IndexSuggestionResult, error) {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.IndexSuggestionResult{Suggestions: []types.IndexSuggestion{}}
+
+	var shapes []queryShape
+	for _, entry := range history {
+		if entry.Database != dbName || entry.Collection != collName {
+			continue
+		}
+		var filter bson.M
+		if strings.TrimSpace(entry.Filter) != "" {
+			if err := bsonutil.UnmarshalExtJSON([]byte(entry.Filter), false, &filter); err != nil {
+				continue
+			}
+		}
+		shapes = append(shapes, newQueryShape(filter, document.ParseSort(entry.Sort), SuggestionSourceHistory))
+	}
+
+	profiled, err := s.profiledQueries(connID, dbName, collName)
+	if err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("Slow queries from system.profile were not included: %v", err))
+	} else {
+		result.ProfilerUsed = true
+		shapes = append(shapes, profiled...)
+	}
+	result.QueriesAnalyzed = len(shapes)
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	existing, err := listIndexKeys(ctx, client.Database(dbName).Collection(collName))
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := groupCandidates(shapes, existing)
+	if len(candidates) > suggestExplainLimit {
+		result.Notes = append(result.Notes, fmt.Sprintf("Only the %d most frequent query shapes were checked with explain", suggestExplainLimit))
+		candidates = candidates[:suggestExplainLimit]
+	}
+
+	for _, c := range candidates {
+		suggestion := types.IndexSuggestion{
+			Keys:        indexKeysJSON(c.keys),
+			Options:     types.IndexOptions{Name: DefaultIndexName(c.keys)},
+			Occurrences: c.occurrences,
+			Sources:     sortedSources(c.sources),
+		}
+		if b, err := bson.MarshalExtJSON(c.example.filter, false, false); err == nil {
+			suggestion.ExampleFilter = string(b)
+		}
+		suggestion.ExampleSort = formatSimpleSort(c.example.sort)
+
+		explain, err := s.ExplainQuery(connID, dbName, collName, suggestion.ExampleFilter,
+			types.QueryOptions{Sort: suggestion.ExampleSort}, VerbosityExecutionStats)
+		if err != nil {
+			suggestion.Reason = fmt.Sprintf("No existing index starts with these keys (explain failed: %v)", err)
+			result.Suggestions = append(result.Suggestions, suggestion)
+			continue
+		}
+
+		examined := explain.ExecutionStats.TotalDocsExamined
+		returned := explain.ExecutionStats.NReturned
+		inMemorySort := explain.QueryPlanner.WinningPlanStage == "SORT"
+		if !explain.IsCollectionScan && !inMemorySort && examined <= returned*2 {
+			continue
+		}
+		suggestion.DocsExamined = examined
+		suggestion.NReturned = returned
+		if examined > returned {
+			suggestion.EstimatedBenefit = (examined - returned) * int64(c.occurrences)
+		}
+		switch {
+		case explain.IsCollectionScan:
+			suggestion.Reason = fmt.Sprintf("Collection scan examined %d documents to return %d", examined, returned)
+		case inMemorySort:
+			suggestion.Reason = fmt.Sprintf("Results are sorted in memory; index %s cannot provide the sort", explain.IndexUsed)
+		default:
+			suggestion.Reason = fmt.Sprintf("Index %s examined %d documents to return %d", explain.IndexUsed, examined, returned)
+		}
+		result.Suggestions = append(result.Suggestions, suggestion)
+	}
+
+	sort.SliceStable(result.Suggestions, func(i, j int) bool {
+		return result.Suggestions[i].EstimatedBenefit > result.Suggestions[j].EstimatedBenefit
+	})
+
+	debug.LogQuery("Index suggestions computed", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"queries":     result.QueriesAnalyzed,
+		"suggestions": len(result.Suggestions),
+	})
+
+	return result, nil
+}
+
+// profiledQueries reads the most recent reads, updates, and deletes on a collection from
+// system.profile. It fails when the profiler is off or the user cannot read the profile.
+func (s *Service) profiledQueries(connID, dbName, collName string) ([]queryShape, error) {
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	db := client.Database(dbName)
+	var status bson.M
+	if err := db.RunCommand(ctx, bson.D{{Key: "profile", Value: -1}}).Decode(&status); err != nil {
+		return nil, err
+	}
+	if bsonutil.ToInt(status["was"]) == 0 {
+		return nil, fmt.Errorf("the profiler is off for %s", dbName)
+	}
+
+	filter := bson.M{
+		"ns": dbName + "." + collName,
+		"op": bson.M{"$in": bson.A{"query", "update", "remove"}},
+	}
+	findOpts := options.Find().SetSort(bson.D{{Key: "ts", Value: -1}}).SetLimit(suggestProfileLimit)
+	cursor, err := db.Collection("system.profile").Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var shapes []queryShape
+	for cursor.Next(ctx) {
+		var entry struct {
+			Command struct {
+				Filter bson.M `bson:"filter"`
+				Q      bson.M `bson:"q"`
+				Sort   bson.D `bson:"sort"`
+			} `bson:"command"`
+		}
+		if err := cursor.Decode(&entry); err != nil {
+			continue
+		}
+		filter := entry.Command.Filter
+		if filter == nil {
+			filter = entry.Command.Q
+		}
+		shapes = append(shapes, newQueryShape(filter, entry.Command.Sort, SuggestionSourceProfiler))
+	}
+	return shapes, cursor.Err()
+}
+
+// newQueryShape derives the index that would serve a query, ordering its keys by the
+// equality, sort, range rule. Equality and range fields are sorted by name so the same
+// query written in a different field order maps to the same index.
+func newQueryShape(filter bson.M, sortDoc bson.D, source string) queryShape {
+	var equality, ranges []string
+	collectFilterFields(filter, &equality, &ranges)
+	sort.Strings(equality)
+	sort.Strings(ranges)
+
+	var keys bson.D
+	seen := map[string]bool{}
+	add := func(field string, direction int32) {
+		if !seen[field] {
+			seen[field] = true
+			keys = append(keys, bson.E{Key: field, Value: direction})
+		}
+	}
+	for _, field := range equality {
+		add(field, 1)
+	}
+	for _, e := range sortDoc {
+		direction := int32(1)
+		if bsonutil.ToInt64(e.Value) < 0 {
+			direction = -1
+		}
+		add(e.Key, direction)
+	}
+	for _, field := range ranges {
+		add(field, 1)
+	}
+
+	return queryShape{filter: filter, sort: sortDoc, keys: keys, source: source}
+}
+
+// collectFilterFields sorts a filter's index-eligible fields into equality and range
+// conditions. Top-level $and is flattened; $or, $nor, $text, $expr, and negations are
+// ignored since a single compound index does not serve them.
+func collectFilterFields(filter bson.M, equality, ranges *[]string) {
+	for field, value := range filter {
+		if field == "$and" {
+			if arr, ok := value.(bson.A); ok {
+				for _, item := range arr {
+					if sub, ok := item.(bson.M); ok {
+						collectFilterFields(sub, equality, ranges)
+					}
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(field, "$") {
+			continue
+		}
+
+		ops, ok := value.(bson.M)
+		if !ok || len(ops) == 0 || !hasOperatorKeys(ops) {
+			*equality = append(*equality, field)
+			continue
+		}
+		switch {
+		case ops["$eq"] != nil || ops["$in"] != nil:
+			*equality = append(*equality, field)
+		case ops["$gt"] != nil || ops["$gte"] != nil || ops["$lt"] != nil || ops["$lte"] != nil:
+			*ranges = append(*ranges, field)
+		case ops["$regex"] != nil && strings.HasPrefix(bsonutil.ToString(ops["$regex"]), "^"):
+			*ranges = append(*ranges, field)
+		case ops["$exists"] == true:
+			*ranges = append(*ranges, field)
+		}
+	}
+}
+
+// hasOperatorKeys reports whether a condition document is made of query operators
+// rather than being an embedded document matched by equality.
+func hasOperatorKeys(doc bson.M) bool {
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// groupCandidates merges query shapes that need the same index, drops shapes an existing
+// index already serves, and returns the rest most frequent first.
+func groupCandidates(shapes []queryShape, existing []bson.D) []*indexCandidate {
+	byName := map[string]*indexCandidate{}
+	var candidates []*indexCandidate
+	for _, shape := range shapes {
+		if len(shape.keys) == 0 || (len(shape.keys) == 1 && shape.keys[0].Key == "_id") {
+			continue
+		}
+		if indexServes(existing, shape.keys) {
+			continue
+		}
+		name := DefaultIndexName(shape.keys)
+		c, ok := byName[name]
+		if !ok {
+			c = &indexCandidate{keys: shape.keys, example: shape, sources: map[string]bool{}}
+			byName[name] = c
+			candidates = append(candidates, c)
+		}
+		c.occurrences++
+		c.sources[shape.source] = true
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].occurrences > candidates[j].occurrences
+	})
+	return candidates
+}
+
+// indexServes reports whether some index starts with the given keys. Directions must
+// match exactly or be reversed throughout, as either way the index provides the order.
+func indexServes(indexes []bson.D, keys bson.D) bool {
+	for _, index := range indexes {
+		if len(index) < len(keys) {
+			continue
+		}
+		same, reversed := true, true
+		for i, k := range keys {
+			if index[i].Key != k.Key {
+				same, reversed = false, false
+				break
+			}
+			want := bsonutil.ToInt64(k.Value)
+			got := bsonutil.ToFloat64(index[i].Value)
+			if got != float64(want) {
+				same = false
+			}
+			if got != float64(-want) {
+				reversed = false
+			}
+		}
+		if same || reversed {
+			return true
+		}
+	}
+	return false
+}
+
+// formatSimpleSort converts a sort document to the "field,-field" format.
+func formatSimpleSort(sortDoc bson.D) string {
+	parts := make([]string, 0, len(sortDoc))
+	for _, e := range sortDoc {
+		if bsonutil.ToInt64(e.Value) < 0 {
+			parts = append(parts, "-"+e.Key)
+		} else {
+			parts = append(parts, e.Key)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// indexKeysJSON renders index keys as the JSON key specification CreateIndex accepts.
+func indexKeysJSON(keys bson.D) string {
+	b, err := bson.MarshalExtJSON(keys, false, false)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// sortedSources returns the source names of a candidate in a stable order.
+func sortedSources(sources map[string]bool) []string {
+	out := make([]string, 0, len(sources))
+	for source := range sources {
+		out = append(out, source)
+	}
+	sort.Strings(out)
+	return out
+}
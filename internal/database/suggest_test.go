@@ -0,0 +1,113 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/document"
+)
+
+func TestNewQueryShape(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		sort     string
+		wantKeys string
+	}{
+		{"equality", `{"status": "active"}`, "", "status_1"},
+		{"equality sorted by name", `{"b": 1, "a": 2}`, "", "a_1_b_1"},
+		{"equality sort range", `{"age": {"$gt": 21}, "status": "active"}`, "-createdAt", "status_1_createdAt_-1_age_1"},
+		{"in is equality", `{"type": {"$in": ["a", "b"]}}`, "", "type_1"},
+		{"anchored regex is range", `{"name": {"$regex": "^Jo"}}`, "", "name_1"},
+		{"unanchored regex ignored", `{"name": {"$regex": "oh"}}`, "", ""},
+		{"negation ignored", `{"status": {"$ne": "x"}}`, "", ""},
+		{"embedded document is equality", `{"address": {"city": "Oslo"}}`, "", "address_1"},
+		{"and flattened", `{"$and": [{"a": 1}, {"b": {"$lt": 5}}]}`, "", "a_1_b_1"},
+		{"or ignored", `{"$or": [{"a": 1}, {"b": 2}]}`, "", ""},
+		{"sort only", `{}`, "name,-age", "name_1_age_-1"},
+		{"sort field also equality", `{"a": 1}`, "a", "a_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter bson.M
+			if err := bsonutil.UnmarshalExtJSON([]byte(tt.filter), false, &filter); err != nil {
+				t.Fatalf("invalid test filter: %v", err)
+			}
+			shape := newQueryShape(filter, document.ParseSort(tt.sort), SuggestionSourceHistory)
+			if got := DefaultIndexName(shape.keys); got != tt.wantKeys {
+				t.Errorf("keys = %q, want %q", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestIndexServes(t *testing.T) {
+	indexes := []bson.D{
+		{{Key: "_id", Value: int32(1)}},
+		{{Key: "status", Value: int32(1)}, {Key: "createdAt", Value: int32(-1)}},
+	}
+	tests := []struct {
+		name string
+		keys bson.D
+		want bool
+	}{
+		{"exact", bson.D{{Key: "status", Value: int32(1)}, {Key: "createdAt", Value: int32(-1)}}, true},
+		{"prefix", bson.D{{Key: "status", Value: int32(1)}}, true},
+		{"reversed", bson.D{{Key: "status", Value: int32(-1)}, {Key: "createdAt", Value: int32(1)}}, true},
+		{"mixed directions", bson.D{{Key: "status", Value: int32(1)}, {Key: "createdAt", Value: int32(1)}}, false},
+		{"not a prefix", bson.D{{Key: "createdAt", Value: int32(-1)}}, false},
+		{"longer than index", bson.D{{Key: "status", Value: int32(1)}, {Key: "createdAt", Value: int32(-1)}, {Key: "x", Value: int32(1)}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indexServes(indexes, tt.keys); got != tt.want {
+				t.Errorf("indexServes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupCandidates(t *testing.T) {
+	shape := func(filter bson.M, source string) queryShape {
+		return newQueryShape(filter, nil, source)
+	}
+	shapes := []queryShape{
+		shape(bson.M{"email": "a"}, SuggestionSourceHistory),
+		shape(bson.M{"status": "x", "age": bson.M{"$gt": 1}}, SuggestionSourceHistory),
+		shape(bson.M{"status": "y", "age": bson.M{"$lt": 9}}, SuggestionSourceProfiler),
+		shape(bson.M{"_id": 1}, SuggestionSourceHistory),
+		shape(bson.M{"indexed": 1}, SuggestionSourceHistory),
+		shape(bson.M{}, SuggestionSourceHistory),
+	}
+	existing := []bson.D{{{Key: "indexed", Value: int32(1)}}}
+
+	candidates := groupCandidates(shapes, existing)
+	if len(candidates) != 2 {
+		t.Fatalf("got %d candidates, want 2", len(candidates))
+	}
+	first := candidates[0]
+	if DefaultIndexName(first.keys) != "status_1_age_1" || first.occurrences != 2 {
+		t.Errorf("first candidate = %s x%d, want status_1_age_1 x2", DefaultIndexName(first.keys), first.occurrences)
+	}
+	if got := sortedSources(first.sources); len(got) != 2 {
+		t.Errorf("sources = %v, want history and profiler", got)
+	}
+	if got := indexKeysJSON(first.keys); got != `{"status":1,"age":1}` {
+		t.Errorf("indexKeysJSON = %s", got)
+	}
+}
+
+func TestSuggestIndexes_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.SuggestIndexes("conn-1", "db", "users", nil)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// GetClusterTopology describes the shards, config servers, and mongos routers of the sharded
+// cluster a connection is routed through. The connection must be to a mongos.
+func (s *Service) GetClusterTopology(connID string) (*types.ClusterTopology, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := requireMongos(ctx, client); err != nil {
+		return nil, err
+	}
+
+	topology := &types.ClusterTopology{
+		ConfigServers: types.ClusterReplSet{Hosts: []string{}},
+		Shards:        []types.ClusterShard{},
+		Routers:       []types.ClusterRouter{},
+	}
+	admin := client.Database("admin")
+	config := client.Database("config")
+
+	var buildInfo bson.M
+	if err := admin.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo); err == nil {
+		topology.Version = bsonutil.ToString(buildInfo["version"])
+	}
+
+	// The config server connection string is only reported by mongos serverStatus
+	var status struct {
+		Sharding struct {
+			ConfigServers string `bson:"configsvrConnectionString"`
+		} `bson:"sharding"`
+	}
+	cmd := bson.D{{Key: "serverStatus", Value: 1}, {Key: "sharding", Value: 1}}
+	if err := admin.RunCommand(ctx, cmd).Decode(&status); err == nil {
+		topology.ConfigServers = parseShardHost(status.Sharding.ConfigServers)
+	}
+
+	cursor, err := config.Collection("shards").Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shards: %w", err)
+	}
+	var shards []bson.M
+	if err := cursor.All(ctx, &shards); err != nil {
+		return nil, fmt.Errorf("failed to list shards: %w", err)
+	}
+	for _, doc := range shards {
+		shard := types.ClusterShard{
+			ID:       bsonutil.ToString(doc["_id"]),
+			ReplSet:  parseShardHost(bsonutil.ToString(doc["host"])),
+			Draining: bsonutil.ToBool(doc["draining"]),
+		}
+		if tags, ok := doc["tags"].(bson.A); ok {
+			for _, t := range tags {
+				shard.Tags = append(shard.Tags, bsonutil.ToString(t))
+			}
+		}
+		topology.Shards = append(topology.Shards, shard)
+	}
+	sort.Slice(topology.Shards, func(i, j int) bool { return topology.Shards[i].ID < topology.Shards[j].ID })
+
+	cursor, err = config.Collection("mongos").Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mongos routers: %w", err)
+	}
+	var routers []bson.M
+	if err := cursor.All(ctx, &routers); err != nil {
+		return nil, fmt.Errorf("failed to list mongos routers: %w", err)
+	}
+	for _, doc := range routers {
+		router := types.ClusterRouter{
+			Host:          bsonutil.ToString(doc["_id"]),
+			Version:       bsonutil.ToString(doc["mongoVersion"]),
+			UptimeSeconds: bsonutil.ToInt64(doc["up"]),
+		}
+		if ping, ok := doc["ping"].(primitive.DateTime); ok {
+			router.LastPing = ping.Time()
+		}
+		topology.Routers = append(topology.Routers, router)
+	}
+	sort.Slice(topology.Routers, func(i, j int) bool { return topology.Routers[i].Host < topology.Routers[j].Host })
+
+	return topology, nil
+}
+
+// parseShardHost parses a shard or config server connection string, "setName/host1,host2"
+// for replica sets or a bare host list.
+func parseShardHost(host string) types.ClusterReplSet {
+	rs := types.ClusterReplSet{Hosts: []string{}}
+	if name, hosts, ok := strings.Cut(host, "/"); ok {
+		rs.SetName = name
+		host = hosts
+	}
+	for _, h := range strings.Split(host, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			rs.Hosts = append(rs.Hosts, h)
+		}
+	}
+	return rs
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseShardHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want types.ClusterReplSet
+	}{
+		{"shard01/a:27018,b:27018", types.ClusterReplSet{SetName: "shard01", Hosts: []string{"a:27018", "b:27018"}}},
+		{"a:27018", types.ClusterReplSet{Hosts: []string{"a:27018"}}},
+		{"", types.ClusterReplSet{Hosts: []string{}}},
+	}
+	for _, tt := range tests {
+		if got := parseShardHost(tt.host); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseShardHost(%q) = %+v, want %+v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestGetClusterTopology_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+	var notConnected *core.NotConnectedError
+	if _, err := svc.GetClusterTopology("conn-1"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// PreviewTTLExpiry finds the TTL indexes of a collection and counts, for each, the documents
+// already past their expiry, those expiring within the next hour and day, and those the
+// index will never remove because the field holds no date. The TTL monitor runs about once
+// a minute, so a few past-due documents are normal; many suggest it is falling behind.
+func (s *Service) PreviewTTLExpiry(connID, dbName, collName string) (*types.TTLExpiryPreview, error) {
+	if err := ValidateDatabaseAndCollection(dbName, collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	coll := client.Database(dbName).Collection(collName)
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	var specs []struct {
+		Name                    string      `bson:"name"`
+		Key                     bson.D      `bson:"key"`
+		ExpireAfterSeconds      interface{} `bson:"expireAfterSeconds"`
+		PartialFilterExpression bson.D      `bson:"partialFilterExpression"`
+	}
+	if err := cursor.All(ctx, &specs); err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	now := time.Now().UTC()
+	preview := &types.TTLExpiryPreview{
+		Namespace:   dbName + "." + collName,
+		GeneratedAt: now,
+		Indexes:     []types.TTLIndexPreview{},
+	}
+	for _, spec := range specs {
+		if spec.ExpireAfterSeconds == nil || len(spec.Key) != 1 {
+			continue
+		}
+		index := types.TTLIndexPreview{
+			Name:               spec.Name,
+			Field:              spec.Key[0].Key,
+			ExpireAfterSeconds: bsonutil.ToInt64(spec.ExpireAfterSeconds),
+		}
+		if len(spec.PartialFilterExpression) > 0 {
+			index.PartialFilter = indexKeysJSON(spec.PartialFilterExpression)
+		}
+
+		filters := ttlExpiryFilters(index.Field, index.ExpireAfterSeconds, now, spec.PartialFilterExpression)
+		counts := []*int64{&index.PastDue, &index.WithinHour, &index.WithinDay, &index.NeverExpire}
+		for i, filter := range filters {
+			// Each count gets its own timeout; the listing context may be nearly spent
+			n, err := s.countWithTimeout(context.Background(), coll, filter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count documents for index %s: %w", spec.Name, err)
+			}
+			*counts[i] = n
+		}
+		preview.Indexes = append(preview.Indexes, index)
+	}
+
+	debug.LogQuery("TTL expiry previewed", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"ttlIndexes": len(preview.Indexes),
+	})
+
+	return preview, nil
+}
+
+// ttlExpiryFilters returns the filters counting documents past due, expiring within an
+// hour, expiring within a day, and never expiring under a TTL index on field. A document
+// expires expireAfterSeconds after the date in field (the earliest date for arrays).
+func ttlExpiryFilters(field string, expireAfterSeconds int64, now time.Time, partial bson.D) []bson.D {
+	cutoff := now.Add(-time.Duration(expireAfterSeconds) * time.Second)
+	withPartial := func(cond bson.D) bson.D {
+		if len(partial) == 0 {
+			return cond
+		}
+		return bson.D{{Key: "$and", Value: bson.A{partial, cond}}}
+	}
+	between := func(from, to time.Time) bson.D {
+		return withPartial(bson.D{{Key: field, Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lt", Value: to}}}})
+	}
+	return []bson.D{
+		withPartial(bson.D{{Key: field, Value: bson.D{{Key: "$lt", Value: cutoff}}}}),
+		between(cutoff, cutoff.Add(time.Hour)),
+		between(cutoff, cutoff.Add(24*time.Hour)),
+		withPartial(bson.D{{Key: field, Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$type", Value: "date"}}}}}}),
+	}
+}
+
+// countWithTimeout counts documents matching filter within the query timeout.
+func (s *Service) countWithTimeout(ctx context.Context, coll *mongo.Collection, filter bson.D) (int64, error) {
+	countCtx, cancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+	defer cancel()
+	return coll.CountDocuments(countCtx, filter)
+}
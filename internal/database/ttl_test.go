@@ -0,0 +1,52 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestTTLExpiryFilters(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-time.Hour)
+
+	filters := ttlExpiryFilters("createdAt", 3600, now, nil)
+	if len(filters) != 4 {
+		t.Fatalf("got %d filters, want 4", len(filters))
+	}
+
+	cond := func(f bson.D) bson.D { return f[0].Value.(bson.D) }
+	if got := cond(filters[0]); got[0].Key != "$lt" || !got[0].Value.(time.Time).Equal(cutoff) {
+		t.Errorf("past due filter = %v", filters[0])
+	}
+	if got := cond(filters[1]); !got[0].Value.(time.Time).Equal(cutoff) || !got[1].Value.(time.Time).Equal(cutoff.Add(time.Hour)) {
+		t.Errorf("within hour filter = %v", filters[1])
+	}
+	if got := cond(filters[2]); !got[1].Value.(time.Time).Equal(cutoff.Add(24 * time.Hour)) {
+		t.Errorf("within day filter = %v", filters[2])
+	}
+	if filters[3][0].Key != "createdAt" {
+		t.Errorf("never expire filter = %v", filters[3])
+	}
+
+	partial := bson.D{{Key: "status", Value: "closed"}}
+	for _, f := range ttlExpiryFilters("createdAt", 60, now, partial) {
+		if f[0].Key != "$and" {
+			t.Errorf("filter %v does not include the partial filter", f)
+		}
+	}
+}
+
+func TestPreviewTTLExpiry_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	_, err := svc.PreviewTTLExpiry("conn-1", "db", "sessions")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -45,6 +45,25 @@ func ValidateDatabaseName(name string) error {
 	return nil
 }
 
+// reservedDatabaseNames are databases the server manages itself.
+var reservedDatabaseNames = map[string]bool{
+	"admin":  true,
+	"local":  true,
+	"config": true,
+}
+
+// ValidateNewDatabaseName checks a name for a database about to be created: it must be
+// valid and must not be one of the server's own databases.
+func ValidateNewDatabaseName(name string) error {
+	if err := ValidateDatabaseName(name); err != nil {
+		return err
+	}
+	if reservedDatabaseNames[strings.ToLower(name)] {
+		return &InvalidNameError{Type: "database", Name: name, Reason: "name is reserved"}
+	}
+	return nil
+}
+
 // ValidateCollectionName checks if a collection name is valid according to MongoDB rules.
 func ValidateCollectionName(name string) error {
 	if name == "" {
@@ -54,6 +54,37 @@ func TestValidateDatabaseName(t *testing.T) {
 	}
 }
 
+func TestValidateNewDatabaseName(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		errMsg string
+	}{
+		{"valid", "shop", ""},
+		{"admin", "admin", "reserved"},
+		{"local", "local", "reserved"},
+		{"config uppercase", "Config", "reserved"},
+		{"admin prefix is fine", "admin2", ""},
+		{"contains dot", "my.db", "invalid character"},
+		{"empty", "", "cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNewDatabaseName(tt.input)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("ValidateNewDatabaseName(%q) unexpected error: %v", tt.input, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidateNewDatabaseName(%q) error = %v, want to contain %q", tt.input, err, tt.errMsg)
+			}
+		})
+	}
+}
+
 func TestValidateCollectionName(t *testing.T) {
 	tests := []struct {
 		name    string
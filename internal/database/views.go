@@ -0,0 +1,131 @@
+package database
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+)
+
+// CreateView creates a read-only view of viewOn (a collection or view in the same
+// database) defined by an aggregation pipeline given as an Extended JSON array.
+func (s *Service) CreateView(connID, dbName, viewName, viewOn, pipelineJSON string) error {
+	if err := s.state.CheckWritable(connID, "create view"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, viewName); err != nil {
+		return err
+	}
+	if err := ValidateCollectionName(viewOn); err != nil {
+		return err
+	}
+	if viewName == viewOn {
+		return fmt.Errorf("a view cannot be defined on itself")
+	}
+	pipeline, err := document.ParsePipeline(pipelineJSON)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cmd := bson.D{
+		{Key: "create", Value: viewName},
+		{Key: "viewOn", Value: viewOn},
+		{Key: "pipeline", Value: pipeline},
+	}
+	if err := client.Database(dbName).RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to create view: %w", err)
+	}
+
+	debug.LogQuery("View created", map[string]interface{}{
+		"database": dbName,
+		"view":     viewName,
+		"viewOn":   viewOn,
+	})
+
+	return nil
+}
+
+// UpdateViewPipeline replaces the pipeline of an existing view, keeping its source.
+func (s *Service) UpdateViewPipeline(connID, dbName, viewName, pipelineJSON string) error {
+	if err := s.state.CheckWritable(connID, "update view"); err != nil {
+		return err
+	}
+
+	if err := ValidateDatabaseAndCollection(dbName, viewName); err != nil {
+		return err
+	}
+	pipeline, err := document.ParsePipeline(pipelineJSON)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	db := client.Database(dbName)
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: viewName}})
+	if err != nil {
+		return fmt.Errorf("failed to read view: %w", err)
+	}
+	if len(specs) == 0 || specs[0].Type != "view" {
+		return fmt.Errorf("%s.%s is not a view", dbName, viewName)
+	}
+	viewOn, _ := specs[0].Options.Lookup("viewOn").StringValueOK()
+
+	cmd := bson.D{
+		{Key: "collMod", Value: viewName},
+		{Key: "viewOn", Value: viewOn},
+		{Key: "pipeline", Value: pipeline},
+	}
+	if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to update view: %w", err)
+	}
+
+	debug.LogQuery("View pipeline updated", map[string]interface{}{
+		"database": dbName,
+		"view":     viewName,
+		"viewOn":   viewOn,
+	})
+
+	return nil
+}
+
+// viewDefinition returns a view's source collection and its pipeline as relaxed
+// Extended JSON, from listCollections options.
+func viewDefinition(collOpts bson.M) (viewOn, pipeline string) {
+	viewOn = bsonutil.ToString(collOpts["viewOn"])
+	stages, ok := collOpts["pipeline"].(bson.A)
+	if !ok {
+		return viewOn, "[]"
+	}
+	// Arrays can't be marshaled on their own, so marshal each stage
+	pipeline = "["
+	for i, stage := range stages {
+		b, err := bson.MarshalExtJSON(stage, false, false)
+		if err != nil {
+			return viewOn, ""
+		}
+		if i > 0 {
+			pipeline += ","
+		}
+		pipeline += string(b)
+	}
+	return viewOn, pipeline + "]"
+}
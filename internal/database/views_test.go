@@ -0,0 +1,72 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestViewDefinition(t *testing.T) {
+	opts := bson.M{
+		"viewOn": "orders",
+		"pipeline": bson.A{
+			bson.D{{Key: "$match", Value: bson.D{{Key: "status", Value: "paid"}}}},
+			bson.D{{Key: "$project", Value: bson.D{{Key: "total", Value: int32(1)}}}},
+		},
+	}
+	viewOn, pipeline := viewDefinition(opts)
+	if viewOn != "orders" {
+		t.Errorf("viewOn = %q, want orders", viewOn)
+	}
+	if want := `[{"$match":{"status":"paid"}},{"$project":{"total":1}}]`; pipeline != want {
+		t.Errorf("pipeline = %s, want %s", pipeline, want)
+	}
+
+	if _, pipeline := viewDefinition(bson.M{"viewOn": "orders"}); pipeline != "[]" {
+		t.Errorf("missing pipeline = %s, want []", pipeline)
+	}
+}
+
+func TestCreateView_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name     string
+		viewName string
+		viewOn   string
+		pipeline string
+		errMsg   string
+	}{
+		{"view on itself", "paid", "paid", `[]`, "cannot be defined on itself"},
+		{"empty source", "paid", "", `[]`, "cannot be empty"},
+		{"invalid pipeline", "paid", "orders", `{"$match": {}}`, "invalid pipeline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CreateView("conn-1", "db", tt.viewName, tt.viewOn, tt.pipeline)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	err := svc.CreateView("conn-1", "db", "paid", "orders", `[{"$match": {"status": "paid"}}]`)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestUpdateViewPipeline_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	err := svc.UpdateViewPipeline("conn-1", "db", "paid", `[{"$match": {"status": "paid"}}]`)
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
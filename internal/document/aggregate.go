@@ -0,0 +1,161 @@
+package document
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MaxAggregationDocuments caps the number of documents RunAggregation returns.
+const MaxAggregationDocuments = 1000
+
+// RunAggregation runs an aggregation pipeline (an Extended JSON array of stages) and returns
+// up to MaxAggregationDocuments output documents.
+func (s *Service) RunAggregation(connID, dbName, collName, pipelineJSON string, opts types.AggregateOptions) (*types.AggregationResult, error) {
+	pipeline, err := ParsePipeline(pipelineJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline: %w", err)
+	}
+	if stage := writeStage(pipeline); stage != "" {
+		if err := s.state.CheckWritable(connID, "aggregation with "+stage); err != nil {
+			return nil, err
+		}
+		// $out and $merge can write into another database than the one the pipeline reads
+		target, _, _ := OutputNamespace(pipeline[len(pipeline)-1], dbName)
+		if err := s.state.CheckDatabaseAccess(connID, target); err != nil {
+			return nil, err
+		}
+	}
+
+	collation, err := ParseCollation(opts.Collation)
+	if err != nil {
+		return nil, err
+	}
+	collOpts, err := ParseReadOptions(opts.ReadPreference, opts.ReadConcern)
+	if err != nil {
+		return nil, err
+	}
+	format, err := s.resultFormat(opts.OutputMode)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithMaxTime(opts.MaxTimeMS)
+	defer cancel()
+
+	debug.LogQuery("Running aggregation", map[string]interface{}{
+		"database":       dbName,
+		"collection":     collName,
+		"stages":         len(pipeline),
+		"readPreference": opts.ReadPreference,
+		"readConcern":    opts.ReadConcern,
+	})
+
+	aggOpts := options.Aggregate().SetMaxTime(s.state.QueryMaxTime(opts.MaxTimeMS))
+	if opts.AllowDiskUse {
+		aggOpts.SetAllowDiskUse(true)
+	}
+	if collation != nil {
+		aggOpts.SetCollation(collation)
+	}
+
+	startTime := time.Now()
+	coll := client.Database(dbName).Collection(collName, collOpts)
+	cursor, err := coll.Aggregate(ctx, pipeline, aggOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run aggregation: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := &types.AggregationResult{Documents: []string{}}
+	var marshalErrors int
+	for cursor.Next(ctx) {
+		if len(result.Documents) >= MaxAggregationDocuments {
+			result.Truncated = true
+			break
+		}
+		jsonBytes, err := marshalResult(cursor.Current, format)
+		if err != nil {
+			marshalErrors++
+			continue
+		}
+		result.Documents = append(result.Documents, string(jsonBytes))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read aggregation results: %w", err)
+	}
+
+	if marshalErrors > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d document(s) failed to marshal to JSON", marshalErrors))
+	}
+	if result.Truncated {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Showing the first %d documents; add a $limit stage to control the output", MaxAggregationDocuments))
+	}
+	result.ExecutionTimeMs = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// writeStage returns "$out" or "$merge" if the pipeline ends writing its output to a
+// collection, or "" otherwise.
+func writeStage(pipeline mongo.Pipeline) string {
+	if len(pipeline) == 0 || len(pipeline[len(pipeline)-1]) == 0 {
+		return ""
+	}
+	switch key := pipeline[len(pipeline)-1][0].Key; key {
+	case "$out", "$merge":
+		return key
+	}
+	return ""
+}
+
+// OutputNamespace returns the database and collection an $out or $merge stage writes to,
+// and false for any other stage. Targets given as a bare collection name are in dbName.
+func OutputNamespace(stage bson.D, dbName string) (db, coll string, ok bool) {
+	if len(stage) == 0 {
+		return "", "", false
+	}
+	var target interface{}
+	switch stage[0].Key {
+	case "$out":
+		target = stage[0].Value
+	case "$merge":
+		target = stage[0].Value
+		if spec, ok := stageDocument(target); ok {
+			target = spec["into"]
+		}
+	default:
+		return "", "", false
+	}
+	if spec, ok := stageDocument(target); ok {
+		db, _ = spec["db"].(string)
+		coll, _ = spec["coll"].(string)
+	} else {
+		coll, _ = target.(string)
+	}
+	if db == "" {
+		db = dbName
+	}
+	return db, coll, true
+}
+
+// stageDocument returns a stage argument that is a document as a map.
+func stageDocument(v interface{}) (bson.M, bool) {
+	switch d := v.(type) {
+	case bson.D:
+		return d.Map(), true
+	case bson.M:
+		return d, true
+	}
+	return nil, false
+}
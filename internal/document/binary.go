@@ -0,0 +1,141 @@
+package document
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MaxBinaryPreviewBytes is the number of bytes PreviewBinaryField returns, enough to render
+// most thumbnails and the first pages of a PDF.
+const MaxBinaryPreviewBytes = 256 * 1024
+
+// binaryExtensions maps sniffed content types to file extensions for the save dialog.
+var binaryExtensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+}
+
+// PreviewBinaryField returns the sniffed content type and a base64 snippet of a binary field.
+// ns is "database.collection"; fieldPath is dotted and may index into arrays ("files.0.data").
+func (s *Service) PreviewBinaryField(connID, ns, docID, fieldPath string) (*types.BinaryFieldPreview, error) {
+	bin, err := s.readBinaryField(connID, ns, docID, fieldPath)
+	if err != nil {
+		return nil, err
+	}
+	return buildBinaryPreview(fieldPath, bin), nil
+}
+
+// ExtractBinaryField writes the contents of a binary field to savePath. With an empty
+// savePath the native save dialog is shown; an empty result means the user cancelled.
+func (s *Service) ExtractBinaryField(connID, ns, docID, fieldPath, savePath string) (string, error) {
+	bin, err := s.readBinaryField(connID, ns, docID, fieldPath)
+	if err != nil {
+		return "", err
+	}
+
+	if savePath == "" {
+		name := fieldPath[strings.LastIndex(fieldPath, ".")+1:]
+		savePath, err = runtime.SaveFileDialog(s.state.Ctx, runtime.SaveDialogOptions{
+			DefaultFilename: name + binaryExtensions[http.DetectContentType(bin.Data)],
+			Title:           "Save Binary Field",
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to open save dialog: %w", err)
+		}
+		if savePath == "" {
+			return "", nil
+		}
+	}
+
+	if err := os.WriteFile(savePath, bin.Data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	debug.LogDocument("Binary field extracted", map[string]interface{}{
+		"namespace":  ns,
+		"documentId": docID,
+		"field":      fieldPath,
+		"bytes":      len(bin.Data),
+		"path":       savePath,
+	})
+	return savePath, nil
+}
+
+// readBinaryField fetches one document and returns the binary value at fieldPath.
+func (s *Service) readBinaryField(connID, ns, docID, fieldPath string) (primitive.Binary, error) {
+	dbName, collName, err := parseNamespace(ns)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+	fieldPath = strings.TrimSpace(fieldPath)
+	if fieldPath == "" || strings.HasPrefix(fieldPath, "$") {
+		return primitive.Binary{}, fmt.Errorf("invalid field path %q", fieldPath)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	// Only fetch the top-level field; projections can't index into arrays
+	path := strings.Split(fieldPath, ".")
+	raw, err := client.Database(dbName).Collection(collName).
+		FindOne(ctx, bson.M{"_id": ParseDocumentID(docID)}, options.FindOne().SetProjection(bson.M{path[0]: 1})).
+		Raw()
+	if err == mongo.ErrNoDocuments {
+		return primitive.Binary{}, fmt.Errorf("document not found")
+	}
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("failed to get document: %w", err)
+	}
+	return lookupBinary(raw, path)
+}
+
+// lookupBinary returns the binary value at path within a document.
+func lookupBinary(doc bson.Raw, path []string) (primitive.Binary, error) {
+	val, err := doc.LookupErr(path...)
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("field %q not found", strings.Join(path, "."))
+	}
+	subtype, data, ok := val.BinaryOK()
+	if !ok {
+		return primitive.Binary{}, fmt.Errorf("field %q is %s, not binary", strings.Join(path, "."), val.Type)
+	}
+	return primitive.Binary{Subtype: subtype, Data: data}, nil
+}
+
+// buildBinaryPreview sniffs a binary value's content type and encodes its leading bytes.
+func buildBinaryPreview(fieldPath string, bin primitive.Binary) *types.BinaryFieldPreview {
+	snippet := bin.Data
+	if len(snippet) > MaxBinaryPreviewBytes {
+		snippet = snippet[:MaxBinaryPreviewBytes]
+	}
+	return &types.BinaryFieldPreview{
+		Field:       fieldPath,
+		Subtype:     int(bin.Subtype),
+		Length:      int64(len(bin.Data)),
+		ContentType: http.DetectContentType(bin.Data),
+		Snippet:     base64.StdEncoding.EncodeToString(snippet),
+		Truncated:   len(snippet) < len(bin.Data),
+	}
+}
@@ -0,0 +1,73 @@
+package document
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestLookupBinary(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	doc, err := bson.Marshal(bson.D{
+		{Key: "name", Value: "x"},
+		{Key: "files", Value: bson.A{bson.D{{Key: "data", Value: primitive.Binary{Data: png}}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bin, err := lookupBinary(doc, []string{"files", "0", "data"})
+	if err != nil {
+		t.Fatalf("lookupBinary failed: %v", err)
+	}
+	if string(bin.Data) != string(png) {
+		t.Errorf("Unexpected data %x", bin.Data)
+	}
+	if _, err := lookupBinary(doc, []string{"name"}); err == nil {
+		t.Error("Expected error for non-binary field")
+	}
+	if _, err := lookupBinary(doc, []string{"files", "1", "data"}); err == nil {
+		t.Error("Expected error for missing field")
+	}
+}
+
+func TestBuildBinaryPreview(t *testing.T) {
+	pdf := append([]byte("%PDF-1.7\n"), make([]byte, MaxBinaryPreviewBytes)...)
+	preview := buildBinaryPreview("doc", primitive.Binary{Subtype: 0x00, Data: pdf})
+
+	if preview.ContentType != "application/pdf" {
+		t.Errorf("Expected application/pdf, got %s", preview.ContentType)
+	}
+	if preview.Length != int64(len(pdf)) || !preview.Truncated {
+		t.Errorf("Expected truncated preview of %d bytes, got %+v", len(pdf), preview.Length)
+	}
+	snippet, err := base64.StdEncoding.DecodeString(preview.Snippet)
+	if err != nil || len(snippet) != MaxBinaryPreviewBytes {
+		t.Errorf("Expected %d byte snippet, got %d (%v)", MaxBinaryPreviewBytes, len(snippet), err)
+	}
+
+	small := buildBinaryPreview("a", primitive.Binary{Subtype: 0x05, Data: []byte{1, 2, 3}})
+	if small.Truncated || small.Subtype != 5 || small.ContentType != "application/octet-stream" {
+		t.Errorf("Unexpected preview %+v", small)
+	}
+}
+
+func TestBinaryField_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.PreviewBinaryField("conn-1", "nodot", "1", "data"); err == nil {
+		t.Error("Expected error for invalid namespace")
+	}
+	if _, err := svc.PreviewBinaryField("conn-1", "db.coll", "1", "$data"); err == nil {
+		t.Error("Expected error for invalid field path")
+	}
+	var notConnected *core.NotConnectedError
+	if _, err := svc.ExtractBinaryField("conn-1", "db.coll", "1", "data", "/tmp/out"); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// DeleteSampleSize is the number of matched documents returned by a dry-run delete.
+const DeleteSampleSize = 10
+
+// deleteBatchSize is the number of documents removed per DeleteMany round trip.
+const deleteBatchSize = 1000
+
+// DeleteManyDocuments deletes every document matching filter. With dryRun set, nothing is
+// deleted; the result holds the match count and a sample of matching documents so the user
+// can confirm. Otherwise the result holds the match count and an operation ID, returned before
+// any document is deleted: the documents are then deleted in the background in batches, each
+// copied to the trash after it when an Archiver is set, emitting "delete:progress" events and a
+// final "delete:done" event tagged with the ID. Pass the ID to CancelQuery to stop between
+// batches. A nil writeConcern keeps the connection's write concern.
+func (s *Service) DeleteManyDocuments(connID, dbName, collName, filter string, dryRun bool, writeConcern *types.WriteConcern) (*types.DeleteManyResult, error) {
+	if !dryRun {
+		if err := s.state.CheckWritable(connID, "delete"); err != nil {
+			return nil, err
+		}
+	}
+
+	filterDoc, err := ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	coll, err := writeCollection(client, dbName, collName, writeConcern)
+	if err != nil {
+		return nil, err
+	}
+
+	countCtx, countCancel := s.state.ContextWithTimeout()
+	matched, err := coll.CountDocuments(countCtx, filterDoc)
+	countCancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	if dryRun {
+		sample, err := s.sampleDocuments(coll, filterDoc, DeleteSampleSize)
+		if err != nil {
+			return nil, err
+		}
+		return &types.DeleteManyResult{DryRun: true, Matched: matched, Sample: sample}, nil
+	}
+	if err := s.state.RequireConfirmation(connID, "delete many", dbName+"."+collName); err != nil {
+		return nil, err
+	}
+
+	operationID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.state.SetQueryCancel(operationID, cancel)
+
+	debug.LogDocument("Deleting documents", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"filter":      filter,
+		"matched":     matched,
+		"operationId": operationID,
+	})
+
+	go func() {
+		defer cancel()
+		defer s.state.ClearQueryCancel(operationID)
+
+		progress := types.DeleteProgress{OperationID: operationID, Database: dbName, Collection: collName, Total: matched}
+		done := types.DeleteDone{OperationID: operationID, Database: dbName, Collection: collName}
+		cancelled, err := s.deleteInBatches(ctx, connID, coll, filterDoc, &progress)
+		done.Deleted, done.Cancelled = progress.Deleted, cancelled
+		if err != nil {
+			done.Error = err.Error()
+		}
+
+		debug.LogDocument("Bulk delete finished", map[string]interface{}{
+			"database":    dbName,
+			"collection":  collName,
+			"deleted":     done.Deleted,
+			"cancelled":   done.Cancelled,
+			"operationId": operationID,
+		})
+		s.state.EmitConnectionEvent(connID, "delete:done", done)
+	}()
+
+	return &types.DeleteManyResult{OperationID: operationID, Matched: matched}, nil
+}
+
+// deleteInBatches deletes the documents matching filter a batch at a time until none are
+// left or ctx is cancelled, emitting progress after each batch. It reports whether it was
+// cancelled.
+func (s *Service) deleteInBatches(ctx context.Context, connID string, coll *mongo.Collection, filterDoc bson.M, progress *types.DeleteProgress) (bool, error) {
+	dbName, collName := progress.Database, progress.Collection
+	s.state.EmitConnectionEvent(connID, "delete:progress", *progress)
+
+	for {
+		if ctx.Err() != nil {
+			return true, nil
+		}
+		docs, err := s.nextDeleteBatch(ctx, coll, filterDoc, s.archiver == nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return true, nil
+			}
+			return false, fmt.Errorf("failed to read documents to delete: %w", err)
+		}
+		if len(docs) == 0 {
+			return false, nil
+		}
+		ids := make([]interface{}, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc.Lookup("_id")
+		}
+
+		batchCtx, batchCancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+		res, err := coll.DeleteMany(batchCtx, batchDeleteFilter(filterDoc, ids))
+		batchCancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return true, nil
+			}
+			return false, fmt.Errorf("failed to delete documents: %w", err)
+		}
+		progress.Deleted += res.DeletedCount
+		s.state.EmitConnectionEvent(connID, "delete:progress", *progress)
+
+		if s.archiver != nil && res.DeletedCount > 0 {
+			archiveCtx, archiveCancel := context.WithTimeout(context.Background(), s.state.QueryTimeout())
+			deleted, err := deletedDocuments(archiveCtx, coll, docs, res.DeletedCount)
+			archiveCancel()
+			if err != nil {
+				return false, fmt.Errorf("documents deleted, but failed to check which to move to trash: %w", err)
+			}
+			s.archiveDeleted(connID, dbName, collName, deleted)
+		}
+
+		// Matching documents that vanished between read and delete would loop forever
+		if res.DeletedCount == 0 {
+			return false, nil
+		}
+	}
+}
+
+// nextDeleteBatch returns up to deleteBatchSize documents matching filter. With idsOnly set,
+// only their _ids are fetched; otherwise whole documents are returned for the trash.
+func (s *Service) nextDeleteBatch(ctx context.Context, coll *mongo.Collection, filter bson.M, idsOnly bool) ([]bson.Raw, error) {
+	findCtx, cancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+	defer cancel()
+
+	findOpts := options.Find().SetLimit(deleteBatchSize)
+	if idsOnly {
+		findOpts.SetProjection(bson.M{"_id": 1})
+	}
+	cursor, err := coll.Find(findCtx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(findCtx)
+
+	var docs []bson.Raw
+	for cursor.Next(findCtx) {
+		docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+	}
+	return docs, cursor.Err()
+}
+
+// batchDeleteFilter restricts a batch delete to the given _ids while re-checking the
+// original filter, so documents modified since they were read are not deleted.
+func batchDeleteFilter(filter bson.M, ids []interface{}) bson.M {
+	idFilter := bson.M{"_id": bson.M{"$in": ids}}
+	if len(filter) == 0 {
+		return idFilter
+	}
+	return bson.M{"$and": bson.A{filter, idFilter}}
+}
+
+// sampleDocuments returns up to n documents matching filter as Extended JSON.
+func (s *Service) sampleDocuments(coll *mongo.Collection, filter bson.M, n int64) ([]string, error) {
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cursor, err := coll.Find(ctx, filter, options.Find().SetLimit(n))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	sample := []string{}
+	for cursor.Next(ctx) {
+		if b, err := bson.MarshalExtJSON(cursor.Current, true, false); err == nil {
+			sample = append(sample, string(b))
+		}
+	}
+	return sample, cursor.Err()
+}
+
+// MaxInsertManyDocuments caps the number of documents accepted by InsertManyDocuments.
+const MaxInsertManyDocuments = 10000
+
+// InsertManyDocuments inserts documents given as an Extended JSON array or as newline-delimited
+// JSON (one document per line). With ordered set, insertion stops at the first failing document;
+// otherwise every document is attempted. The result lists the IDs of inserted documents and an
+// error for each document that failed. A nil writeConcern keeps the connection's write concern.
+func (s *Service) InsertManyDocuments(connID, dbName, collName, documents string, ordered bool, writeConcern *types.WriteConcern) (*types.InsertManyResult, error) {
+	if err := s.state.CheckWritable(connID, "insert"); err != nil {
+		return nil, err
+	}
+
+	docs, err := ParseDocuments(documents)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no documents to insert")
+	}
+	if len(docs) > MaxInsertManyDocuments {
+		return nil, fmt.Errorf("too many documents (%d); the limit is %d", len(docs), MaxInsertManyDocuments)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	debug.LogDocument("Inserting documents", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"count":      len(docs),
+		"ordered":    ordered,
+	})
+
+	// Assign missing _ids up front so inserted documents can be reported by ID
+	ids := make([]interface{}, len(docs))
+	batch := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		id, ok := lookupID(doc)
+		if !ok {
+			id = primitive.NewObjectID()
+			doc = append(bson.D{{Key: "_id", Value: id}}, doc...)
+		}
+		ids[i] = id
+		batch[i] = doc
+	}
+
+	coll, err := writeCollection(client, dbName, collName, writeConcern)
+	if err != nil {
+		return nil, err
+	}
+	_, insertErr := coll.InsertMany(ctx, batch, options.InsertMany().SetOrdered(ordered))
+
+	failed := map[int]string{}
+	if insertErr != nil {
+		bwe, ok := insertErr.(mongo.BulkWriteException)
+		if !ok || len(bwe.WriteErrors) == 0 {
+			return nil, fmt.Errorf("failed to insert documents: %w", insertErr)
+		}
+		for _, we := range bwe.WriteErrors {
+			failed[we.Index] = we.Message
+		}
+	}
+
+	result := &types.InsertManyResult{InsertedIDs: []string{}}
+	for i, id := range ids {
+		if msg, ok := failed[i]; ok {
+			result.Errors = append(result.Errors, types.DocumentError{Index: i, Message: msg})
+			if ordered {
+				// Ordered inserts stop at the first error; later documents were not attempted
+				break
+			}
+			continue
+		}
+		result.InsertedIDs = append(result.InsertedIDs, formatInsertedID(id))
+	}
+
+	debug.LogDocument("Documents inserted", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"inserted":   len(result.InsertedIDs),
+		"failed":     len(result.Errors),
+	})
+
+	return result, nil
+}
+
+// ParseDocuments parses an Extended JSON array of documents or newline-delimited documents.
+// A single document is also accepted.
+func ParseDocuments(input string) ([]bson.D, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "[") {
+		var wrapper struct {
+			Docs []bson.D `bson:"docs"`
+		}
+		if err := bsonutil.UnmarshalExtJSON([]byte(`{"docs": `+input+`}`), true, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid document array: %w", err)
+		}
+		return wrapper.Docs, nil
+	}
+
+	// A single (possibly multi-line) document is one JSON value; anything else is NDJSON
+	if normalized, err := bsonutil.NormalizeExtJSON(input); err == nil {
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON([]byte(normalized), true, &doc); err != nil {
+			return nil, fmt.Errorf("invalid document: %w", err)
+		}
+		return []bson.D{doc}, nil
+	}
+	var docs []bson.D
+	for i, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var doc bson.D
+		if err := bsonutil.UnmarshalExtJSON([]byte(line), true, &doc); err != nil {
+			return nil, fmt.Errorf("invalid document on line %d: %w", i+1, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// lookupID returns a document's _id, if present.
+func lookupID(doc bson.D) (interface{}, bool) {
+	for _, e := range doc {
+		if e.Key == "_id" {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
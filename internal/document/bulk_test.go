@@ -0,0 +1,113 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestBatchDeleteFilter(t *testing.T) {
+	ids := []interface{}{1, 2}
+
+	got := batchDeleteFilter(bson.M{}, ids)
+	want := bson.M{"_id": bson.M{"$in": ids}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("empty filter: got %v, want %v", got, want)
+	}
+
+	filter := bson.M{"status": "archived"}
+	got = batchDeleteFilter(filter, ids)
+	want = bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$in": ids}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("with filter: got %v, want %v", got, want)
+	}
+}
+
+func TestDeleteManyDocuments_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.DeleteManyDocuments("conn-1", "db", "coll", `{"a":`, true, nil); err == nil {
+		t.Error("Expected error for invalid filter")
+	}
+
+	var notConnected *core.NotConnectedError
+	_, err := svc.DeleteManyDocuments("conn-1", "db", "coll", `{"a": 1}`, true, nil)
+	if !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestDeleteInBatches_StopsWhenCancelled(t *testing.T) {
+	state := core.NewAppState()
+	state.DisableEvents = true
+	svc := NewService(state)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	progress := types.DeleteProgress{OperationID: "op-1", Database: "db", Collection: "coll", Total: 5}
+	cancelled, err := svc.deleteInBatches(ctx, "conn-1", nil, bson.M{}, &progress)
+	if err != nil || !cancelled {
+		t.Errorf("Expected a cancelled delete without error, got %v, %v", cancelled, err)
+	}
+	if progress.Deleted != 0 {
+		t.Errorf("Expected nothing deleted, got %d", progress.Deleted)
+	}
+}
+
+func TestParseDocuments(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"array", `[{"a": 1}, {"b": {"$numberLong": "2"}}]`, 2, false},
+		{"empty array", `[]`, 0, false},
+		{"single document", `{"a": 1, "b": [1, 2]}`, 1, false},
+		{"ndjson", "{\"a\": 1}\n\n{\"a\": 2}\n{\"a\": 3}\n", 3, false},
+		{"pretty single document", "{\n  \"a\": 1\n}", 1, false},
+		{"relaxed array", `[{a: ObjectId("507f1f77bcf86cd799439011")}, {b: 'x',},]`, 2, false},
+		{"relaxed ndjson", "{a: 1}\n{a: NumberLong(2)}", 2, false},
+		{"array of non-documents", `[1, 2]`, 0, true},
+		{"bad ndjson line", "{\"a\": 1}\n{\"a\":", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := ParseDocuments(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDocuments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(docs) != tt.want {
+				t.Errorf("ParseDocuments() returned %d documents, want %d", len(docs), tt.want)
+			}
+		})
+	}
+
+	docs, _ := ParseDocuments(`[{"z": 1, "a": 2}]`)
+	if docs[0][0].Key != "z" {
+		t.Errorf("Expected field order to be preserved, got %v", docs[0])
+	}
+}
+
+func TestInsertManyDocuments_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.InsertManyDocuments("conn-1", "db", "coll", `[]`, true, nil); err == nil {
+		t.Error("Expected error for empty input")
+	}
+	if _, err := svc.InsertManyDocuments("conn-1", "db", "coll", `[{"a":`, true, nil); err == nil {
+		t.Error("Expected error for invalid input")
+	}
+
+	var notConnected *core.NotConnectedError
+	_, err := svc.InsertManyDocuments("conn-1", "db", "coll", `[{"a": 1}]`, true, nil)
+	if !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,696 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Duplicate handling modes for CopyDocuments, named after the import modes.
+const (
+	CopyModeSkip     = "skip"     // Keep the existing target document
+	CopyModeOverride = "override" // Replace the target document with the source document
+	CopyModeFail     = "fail"     // Stop at the first duplicate _id
+	CopyModeDrop     = "drop"     // CopyDatabase only: drop existing target collections first
+)
+
+// defaultCopyBatchSize is the number of documents written per round trip when not set.
+const defaultCopyBatchSize = 500
+
+// CopyDocuments streams documents matching filter from sourceNS to targetNS ("db.collection"),
+// which may live on a different connection. Documents whose _id already exists in the target
+// are handled according to opts.Mode. Progress is emitted as "copy:progress" events tagged
+// with an operation ID that can be passed to CancelQuery to stop between batches.
+func (s *Service) CopyDocuments(sourceConnID, sourceNS, targetConnID, targetNS, filter string, opts types.CopyDocumentsOptions) (*types.CopyDocumentsResult, error) {
+	if err := s.state.CheckWritable(targetConnID, "copy documents"); err != nil {
+		return nil, err
+	}
+
+	srcDB, srcColl, err := parseNamespace(sourceNS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source namespace: %w", err)
+	}
+	dstDB, dstColl, err := parseNamespace(targetNS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target namespace: %w", err)
+	}
+	if sourceConnID == targetConnID && sourceNS == targetNS {
+		return nil, fmt.Errorf("source and target namespaces are the same")
+	}
+
+	mode := opts.Mode
+	if mode == "" {
+		mode = CopyModeSkip
+	}
+	if mode != CopyModeSkip && mode != CopyModeOverride && mode != CopyModeFail {
+		return nil, fmt.Errorf("unknown duplicate mode %q", opts.Mode)
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+
+	filterDoc, err := ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	srcClient, err := s.state.GetDatabaseClient(sourceConnID, srcDB)
+	if err != nil {
+		return nil, err
+	}
+	dstClient, err := s.state.GetDatabaseClient(targetConnID, dstDB)
+	if err != nil {
+		return nil, err
+	}
+	src := srcClient.Database(srcDB).Collection(srcColl)
+	dst := dstClient.Database(dstDB).Collection(dstColl)
+
+	countCtx, countCancel := s.state.ContextWithTimeout()
+	total, err := src.CountDocuments(countCtx, filterDoc)
+	countCancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	operationID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.state.SetQueryCancel(operationID, cancel)
+	defer s.state.ClearQueryCancel(operationID)
+	defer cancel()
+
+	debug.LogDocument("Copying documents", map[string]interface{}{
+		"source":      sourceNS,
+		"target":      targetNS,
+		"filter":      filter,
+		"mode":        mode,
+		"total":       total,
+		"operationId": operationID,
+	})
+
+	result := &types.CopyDocumentsResult{OperationID: operationID, Total: total}
+	emitProgress := func() {
+		s.state.EmitConnectionEvent(targetConnID, "copy:progress", types.CopyProgress{
+			OperationID: operationID,
+			Processed:   result.Copied + result.Skipped,
+			Total:       total,
+		})
+	}
+	emitProgress()
+
+	if err := s.copyStream(ctx, src, dst, filterDoc, mode, batchSize, result, emitProgress); err != nil {
+		return result, err
+	}
+
+	debug.LogDocument("Copy finished", map[string]interface{}{
+		"source":      sourceNS,
+		"target":      targetNS,
+		"copied":      result.Copied,
+		"skipped":     result.Skipped,
+		"cancelled":   result.Cancelled,
+		"operationId": operationID,
+	})
+
+	return result, nil
+}
+
+// copyStream copies the documents of src matching filter to dst in batches, adding to the
+// counts in result and calling progress after each batch. A cancelled ctx stops the copy
+// between batches and sets result.Cancelled rather than returning an error.
+func (s *Service) copyStream(ctx context.Context, src, dst *mongo.Collection, filter interface{}, mode string, batchSize int, result *types.CopyDocumentsResult, progress func()) error {
+	cursor, err := src.Find(ctx, filter, options.Find().SetBatchSize(int32(batchSize)))
+	if err != nil {
+		if ctx.Err() != nil {
+			result.Cancelled = true
+			return nil
+		}
+		return fmt.Errorf("failed to read source documents: %w", err)
+	}
+	defer cursor.Close(context.Background())
+
+	batch := make([]bson.Raw, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		copied, skipped, err := s.writeCopyBatch(ctx, dst, batch, mode)
+		result.Copied += copied
+		result.Skipped += skipped
+		batch = batch[:0]
+		if err != nil {
+			return err
+		}
+		progress()
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		batch = append(batch, append(bson.Raw(nil), cursor.Current...))
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				return err
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		result.Cancelled = true
+		return nil
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read source documents: %w", err)
+	}
+	return flush()
+}
+
+// writeCopyBatch writes one batch of documents to the target collection.
+func (s *Service) writeCopyBatch(ctx context.Context, coll *mongo.Collection, batch []bson.Raw, mode string) (copied, skipped int64, err error) {
+	writeCtx, cancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+	defer cancel()
+
+	if mode == CopyModeOverride {
+		models := make([]mongo.WriteModel, len(batch))
+		for i, doc := range batch {
+			models[i] = mongo.NewReplaceOneModel().
+				SetFilter(bson.D{{Key: "_id", Value: doc.Lookup("_id")}}).
+				SetReplacement(doc).
+				SetUpsert(true)
+		}
+		if _, err := coll.BulkWrite(writeCtx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return 0, 0, fmt.Errorf("failed to write documents: %w", err)
+		}
+		return int64(len(batch)), 0, nil
+	}
+
+	docs := make([]interface{}, len(batch))
+	for i, doc := range batch {
+		docs[i] = doc
+	}
+	// Ordered inserts stop at the first duplicate, so fail mode reports exactly what was copied
+	_, err = coll.InsertMany(writeCtx, docs, options.InsertMany().SetOrdered(mode == CopyModeFail))
+	if err == nil {
+		return int64(len(batch)), 0, nil
+	}
+
+	// InsertedIDs lists every attempted document, so derive counts from the write errors
+	bwe, ok := err.(mongo.BulkWriteException)
+	if !ok || len(bwe.WriteErrors) == 0 {
+		return 0, 0, fmt.Errorf("failed to write documents: %w", err)
+	}
+	for _, we := range bwe.WriteErrors {
+		if !mongo.IsDuplicateKeyError(we) {
+			return 0, 0, fmt.Errorf("failed to write documents: %w", err)
+		}
+	}
+	if mode == CopyModeFail {
+		first := bwe.WriteErrors[0].Index
+		return int64(first), 0, fmt.Errorf("duplicate _id %s in target", batch[first].Lookup("_id"))
+	}
+	skipped = int64(len(bwe.WriteErrors))
+	return int64(len(batch)) - skipped, skipped, nil
+}
+
+// parseNamespace splits "db.collection" at the first dot; collection names may contain dots.
+func parseNamespace(ns string) (dbName, collName string, err error) {
+	dbName, collName, ok := strings.Cut(ns, ".")
+	if !ok || dbName == "" || collName == "" {
+		return "", "", fmt.Errorf("expected \"database.collection\", got %q", ns)
+	}
+	return dbName, collName, nil
+}
+
+// CopyCollection copies a collection to a new collection on the same connection, which may
+// be in another database. The target is created with the source's options (capped size,
+// validator, collation, ...), then the documents are streamed across with CopyDocuments,
+// emitting "copy:progress" events. With includeIndexes, the source's secondary indexes are
+// built on the target once the documents are in. The target must not already exist; if the
+// copy fails or is cancelled part way, the partial target is left for inspection.
+func (s *Service) CopyCollection(connID, sourceNS, targetNS string, includeIndexes bool) (*types.CopyCollectionResult, error) {
+	if err := s.state.CheckWritable(connID, "copy collection"); err != nil {
+		return nil, err
+	}
+
+	srcDB, srcColl, err := parseNamespace(sourceNS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source namespace: %w", err)
+	}
+	dstDB, dstColl, err := parseNamespace(targetNS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target namespace: %w", err)
+	}
+	if sourceNS == targetNS {
+		return nil, fmt.Errorf("source and target namespaces are the same")
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, srcDB)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.state.CheckDatabaseAccess(connID, dstDB); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	srcSpecs, err := client.Database(srcDB).ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: srcColl}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source collection: %w", err)
+	}
+	if len(srcSpecs) == 0 {
+		return nil, fmt.Errorf("collection %s does not exist", sourceNS)
+	}
+	if srcSpecs[0].Type != "collection" {
+		return nil, fmt.Errorf("%s is a %s; only collections can be copied", sourceNS, srcSpecs[0].Type)
+	}
+	dstNames, err := client.Database(dstDB).ListCollectionNames(ctx, bson.D{{Key: "name", Value: dstColl}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check target collection: %w", err)
+	}
+	if len(dstNames) > 0 {
+		return nil, fmt.Errorf("collection %s already exists", targetNS)
+	}
+
+	if err := createWithOptions(ctx, client.Database(dstDB), dstColl, srcSpecs[0].Options); err != nil {
+		return nil, fmt.Errorf("failed to create target collection: %w", err)
+	}
+
+	copied, err := s.CopyDocuments(connID, sourceNS, connID, targetNS, "", types.CopyDocumentsOptions{Mode: CopyModeFail})
+	result := &types.CopyCollectionResult{}
+	if copied != nil {
+		result.CopyDocumentsResult = *copied
+	}
+	if err != nil || result.Cancelled || !includeIndexes {
+		return result, err
+	}
+
+	indexCtx, indexCancel := context.WithTimeout(context.Background(), core.IndexBuildTimeout)
+	defer indexCancel()
+	result.IndexesCopied, err = copyIndexes(indexCtx, client.Database(srcDB).Collection(srcColl), client.Database(dstDB).Collection(dstColl))
+	if err != nil {
+		return result, err
+	}
+
+	debug.LogDocument("Collection copied", map[string]interface{}{
+		"source":  sourceNS,
+		"target":  targetNS,
+		"copied":  result.Copied,
+		"indexes": result.IndexesCopied,
+	})
+
+	return result, nil
+}
+
+// CloneCollectionStructure creates an empty copy of a collection on the same connection:
+// same options (validator, collation, capped size, time-series settings, ...) and the same
+// secondary indexes, but no documents. A view is recreated with its pipeline. Returns the
+// number of indexes built. The target must not already exist.
+func (s *Service) CloneCollectionStructure(connID, sourceNS, targetNS string) (int, error) {
+	if err := s.state.CheckWritable(connID, "clone collection"); err != nil {
+		return 0, err
+	}
+
+	srcDB, srcColl, err := parseNamespace(sourceNS)
+	if err != nil {
+		return 0, fmt.Errorf("invalid source namespace: %w", err)
+	}
+	dstDB, dstColl, err := parseNamespace(targetNS)
+	if err != nil {
+		return 0, fmt.Errorf("invalid target namespace: %w", err)
+	}
+	if sourceNS == targetNS {
+		return 0, fmt.Errorf("source and target namespaces are the same")
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, srcDB)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.state.CheckDatabaseAccess(connID, dstDB); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	srcSpecs, err := client.Database(srcDB).ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: srcColl}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read source collection: %w", err)
+	}
+	if len(srcSpecs) == 0 {
+		return 0, fmt.Errorf("collection %s does not exist", sourceNS)
+	}
+	dstNames, err := client.Database(dstDB).ListCollectionNames(ctx, bson.D{{Key: "name", Value: dstColl}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to check target collection: %w", err)
+	}
+	if len(dstNames) > 0 {
+		return 0, fmt.Errorf("collection %s already exists", targetNS)
+	}
+
+	if err := createWithOptions(ctx, client.Database(dstDB), dstColl, srcSpecs[0].Options); err != nil {
+		return 0, fmt.Errorf("failed to create target collection: %w", err)
+	}
+
+	indexes := 0
+	if srcSpecs[0].Type != "view" {
+		// Indexes on an empty collection build immediately
+		indexes, err = copyIndexes(ctx, client.Database(srcDB).Collection(srcColl), client.Database(dstDB).Collection(dstColl))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	debug.LogDocument("Collection structure cloned", map[string]interface{}{
+		"source":  sourceNS,
+		"target":  targetNS,
+		"type":    srcSpecs[0].Type,
+		"indexes": indexes,
+	})
+
+	return indexes, nil
+}
+
+// copyIndexes builds every index of src except _id on dst and returns how many were built.
+func copyIndexes(ctx context.Context, src, dst *mongo.Collection) (int, error) {
+	cursor, err := src.Indexes().List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list source indexes: %w", err)
+	}
+	var specs []bson.D
+	if err := cursor.All(ctx, &specs); err != nil {
+		return 0, fmt.Errorf("failed to list source indexes: %w", err)
+	}
+
+	indexes := bson.A{}
+	for _, spec := range specs {
+		var index bson.D
+		isID := false
+		for _, e := range spec {
+			switch e.Key {
+			case "v", "ns":
+				// Server-assigned; the target gets its own
+			case "name":
+				isID = e.Value == "_id_"
+				index = append(index, e)
+			default:
+				index = append(index, e)
+			}
+		}
+		if !isID {
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) == 0 {
+		return 0, nil
+	}
+
+	cmd := bson.D{{Key: "createIndexes", Value: dst.Name()}, {Key: "indexes", Value: indexes}}
+	if err := dst.Database().RunCommand(ctx, cmd).Err(); err != nil {
+		return 0, fmt.Errorf("failed to copy indexes: %w", err)
+	}
+	return len(indexes), nil
+}
+
+// createWithOptions creates a collection or view with the options listCollections reported
+// for another one.
+func createWithOptions(ctx context.Context, db *mongo.Database, name string, opts bson.Raw) error {
+	createCmd := bson.D{{Key: "create", Value: name}}
+	if elems, err := opts.Elements(); err == nil {
+		for _, e := range elems {
+			createCmd = append(createCmd, bson.E{Key: e.Key(), Value: e.Value()})
+		}
+	}
+	return db.RunCommand(ctx, createCmd).Err()
+}
+
+// CopyDatabase copies the collections of sourceDB, with their options, to targetDB, which may
+// be on another connection. Documents are streamed directly between the servers, collection
+// by collection, emitting "copydb:progress" events tagged with an operation ID that can be
+// passed to CancelQuery. Views are recreated after the collections. Existing target
+// collections are handled by opts.Mode: "skip" and "override" write into them, resolving
+// duplicate _ids like CopyDocuments; "drop" replaces them, and on a target connection with
+// safety settings needs a confirmed "copy database with drop" token for targetDB; "fail"
+// refuses to start.
+func (s *Service) CopyDatabase(sourceConnID, sourceDB, targetConnID, targetDB string, opts types.CopyDatabaseOptions) (*types.CopyDatabaseResult, error) {
+	if err := s.state.CheckWritable(targetConnID, "copy database"); err != nil {
+		return nil, err
+	}
+
+	if sourceDB == "" || targetDB == "" {
+		return nil, fmt.Errorf("source and target databases are required")
+	}
+	if strings.ContainsAny(targetDB, `/\. "$*<>:|?`) {
+		return nil, fmt.Errorf("invalid target database name %q", targetDB)
+	}
+	if sourceConnID == targetConnID && sourceDB == targetDB {
+		return nil, fmt.Errorf("source and target databases are the same")
+	}
+
+	mode := opts.Mode
+	if mode == "" {
+		mode = CopyModeSkip
+	}
+	docMode := mode
+	switch mode {
+	case CopyModeSkip, CopyModeOverride:
+	case CopyModeDrop, CopyModeFail:
+		// Targets start out empty, so any duplicate is within the source copy itself
+		docMode = CopyModeFail
+	default:
+		return nil, fmt.Errorf("unknown conflict mode %q", opts.Mode)
+	}
+	if mode == CopyModeDrop {
+		if err := s.state.RequireConfirmation(targetConnID, "copy database with drop", targetDB); err != nil {
+			return nil, err
+		}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+
+	srcClient, err := s.state.GetDatabaseClient(sourceConnID, sourceDB)
+	if err != nil {
+		return nil, err
+	}
+	dstClient, err := s.state.GetDatabaseClient(targetConnID, targetDB)
+	if err != nil {
+		return nil, err
+	}
+	srcDB := srcClient.Database(sourceDB)
+	dstDB := dstClient.Database(targetDB)
+
+	listCtx, listCancel := s.state.ContextWithTimeout()
+	specs, err := srcDB.ListCollectionSpecifications(listCtx, bson.D{})
+	if err != nil {
+		listCancel()
+		return nil, fmt.Errorf("failed to list source collections: %w", err)
+	}
+	existingNames, err := dstDB.ListCollectionNames(listCtx, bson.D{})
+	listCancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list target collections: %w", err)
+	}
+	collections, views, err := selectCopySpecs(specs, opts.Collections)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(existingNames))
+	for _, name := range existingNames {
+		existing[name] = true
+	}
+	if mode == CopyModeFail {
+		for _, spec := range append(collections, views...) {
+			if existing[spec.Name] {
+				return nil, fmt.Errorf("collection %s.%s already exists", targetDB, spec.Name)
+			}
+		}
+	}
+
+	operationID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.state.SetQueryCancel(operationID, cancel)
+	defer s.state.ClearQueryCancel(operationID)
+	defer cancel()
+
+	debug.LogDocument("Copying database", map[string]interface{}{
+		"source":      sourceDB,
+		"target":      targetDB,
+		"collections": len(collections),
+		"views":       len(views),
+		"mode":        mode,
+		"operationId": operationID,
+	})
+
+	started := time.Now()
+	result := &types.CopyDatabaseResult{OperationID: operationID, Collections: []types.CopyDatabaseCollection{}}
+	finish := func() *types.CopyDatabaseResult {
+		result.DurationMs = time.Since(started).Milliseconds()
+		return result
+	}
+
+	for i, spec := range collections {
+		if ctx.Err() != nil {
+			result.Cancelled = true
+			break
+		}
+		src := srcDB.Collection(spec.Name)
+		dst := dstDB.Collection(spec.Name)
+		if err := s.prepareCopyTarget(ctx, dstDB, spec, existing[spec.Name], mode); err != nil {
+			return finish(), err
+		}
+
+		countCtx, countCancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+		total, _ := src.EstimatedDocumentCount(countCtx)
+		countCancel()
+
+		copied := types.CopyDocumentsResult{}
+		emitProgress := func() {
+			s.state.EmitConnectionEvent(targetConnID, "copydb:progress", types.CopyDatabaseProgress{
+				OperationID:     operationID,
+				Collection:      spec.Name,
+				CollectionIndex: i + 1,
+				CollectionTotal: len(collections),
+				Processed:       copied.Copied + copied.Skipped,
+				Total:           total,
+			})
+		}
+		emitProgress()
+
+		err := s.copyStream(ctx, src, dst, bson.D{}, docMode, batchSize, &copied, emitProgress)
+		collResult := types.CopyDatabaseCollection{Name: spec.Name, Copied: copied.Copied, Skipped: copied.Skipped}
+		result.Copied += copied.Copied
+		result.Skipped += copied.Skipped
+		if err == nil && !copied.Cancelled && opts.IncludeIndexes {
+			indexCtx, indexCancel := context.WithTimeout(ctx, core.IndexBuildTimeout)
+			collResult.IndexesCopied, err = copyIndexes(indexCtx, src, dst)
+			indexCancel()
+		}
+		result.Collections = append(result.Collections, collResult)
+		if err != nil {
+			if ctx.Err() != nil {
+				result.Cancelled = true
+				break
+			}
+			return finish(), fmt.Errorf("failed to copy collection %s: %w", spec.Name, err)
+		}
+		if copied.Cancelled {
+			result.Cancelled = true
+			break
+		}
+	}
+
+	if !result.Cancelled {
+		for _, spec := range views {
+			if existing[spec.Name] && mode != CopyModeDrop {
+				continue
+			}
+			if err := s.prepareCopyTarget(ctx, dstDB, spec, existing[spec.Name], mode); err != nil {
+				return finish(), err
+			}
+			result.Views++
+		}
+	}
+
+	debug.LogDocument("Database copy finished", map[string]interface{}{
+		"source":      sourceDB,
+		"target":      targetDB,
+		"copied":      result.Copied,
+		"skipped":     result.Skipped,
+		"views":       result.Views,
+		"cancelled":   result.Cancelled,
+		"operationId": operationID,
+	})
+
+	return finish(), nil
+}
+
+// prepareCopyTarget makes sure a collection or view exists on the target before its
+// documents are copied: it is created with the source's options unless it already exists,
+// in which case drop mode replaces it.
+func (s *Service) prepareCopyTarget(ctx context.Context, db *mongo.Database, spec *mongo.CollectionSpecification, exists bool, mode string) error {
+	opCtx, cancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+	defer cancel()
+
+	if exists {
+		if mode != CopyModeDrop {
+			return nil
+		}
+		if err := db.Collection(spec.Name).Drop(opCtx); err != nil {
+			return fmt.Errorf("failed to drop target %s: %w", spec.Name, err)
+		}
+	}
+	if err := createWithOptions(opCtx, db, spec.Name, spec.Options); err != nil {
+		return fmt.Errorf("failed to create target %s: %w", spec.Name, err)
+	}
+	return nil
+}
+
+// selectCopySpecs picks the collections and views to copy, leaving out system collections,
+// and orders views so that views defined on other views come after them. names restricts
+// the selection when not empty.
+func selectCopySpecs(specs []*mongo.CollectionSpecification, names []string) (collections, views []*mongo.CollectionSpecification, err error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	found := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		found[spec.Name] = true
+		if strings.HasPrefix(spec.Name, "system.") || (len(names) > 0 && !wanted[spec.Name]) {
+			continue
+		}
+		switch spec.Type {
+		case "collection", "timeseries":
+			collections = append(collections, spec)
+		case "view":
+			views = append(views, spec)
+		}
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, nil, fmt.Errorf("collection %q does not exist in the source database", name)
+		}
+	}
+	sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+
+	// A view can only be created once the view it reads from exists
+	ordered := make([]*mongo.CollectionSpecification, 0, len(views))
+	pending := make(map[string]bool, len(views))
+	for _, v := range views {
+		pending[v.Name] = true
+	}
+	for len(ordered) < len(views) {
+		progressed := false
+		for _, v := range views {
+			if !pending[v.Name] {
+				continue
+			}
+			viewOn, _ := v.Options.Lookup("viewOn").StringValueOK()
+			if pending[viewOn] {
+				continue
+			}
+			ordered = append(ordered, v)
+			delete(pending, v.Name)
+			progressed = true
+		}
+		if !progressed {
+			return nil, nil, fmt.Errorf("views have circular definitions")
+		}
+	}
+	return collections, ordered, nil
+}
@@ -0,0 +1,195 @@
+package document
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseNamespace(t *testing.T) {
+	tests := []struct {
+		ns       string
+		wantDB   string
+		wantColl string
+		wantErr  bool
+	}{
+		{"shop.orders", "shop", "orders", false},
+		{"shop.system.views", "shop", "system.views", false},
+		{"shop", "", "", true},
+		{".orders", "", "", true},
+		{"shop.", "", "", true},
+		{"", "", "", true},
+	}
+
+	for _, tt := range tests {
+		db, coll, err := parseNamespace(tt.ns)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNamespace(%q) error = %v, wantErr %v", tt.ns, err, tt.wantErr)
+			continue
+		}
+		if db != tt.wantDB || coll != tt.wantColl {
+			t.Errorf("parseNamespace(%q) = %q, %q, want %q, %q", tt.ns, db, coll, tt.wantDB, tt.wantColl)
+		}
+	}
+}
+
+func TestCopyDocuments_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name     string
+		sourceNS string
+		targetNS string
+		targetID string
+		filter   string
+		mode     string
+	}{
+		{"invalid source", "shop", "shop.archive", "conn-1", "", ""},
+		{"invalid target", "shop.orders", "archive", "conn-1", "", ""},
+		{"same namespace", "shop.orders", "shop.orders", "conn-1", "", ""},
+		{"unknown mode", "shop.orders", "shop.archive", "conn-1", "", "merge"},
+		{"invalid filter", "shop.orders", "shop.archive", "conn-1", `{"a":`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := types.CopyDocumentsOptions{Mode: tt.mode}
+			if _, err := svc.CopyDocuments("conn-1", tt.sourceNS, tt.targetID, tt.targetNS, tt.filter, opts); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	var notConnected *core.NotConnectedError
+	_, err := svc.CopyDocuments("conn-1", "shop.orders", "conn-2", "shop.orders", "", types.CopyDocumentsOptions{})
+	if !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestCopyCollection_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	for _, tt := range []struct{ name, sourceNS, targetNS string }{
+		{"invalid source", "shop", "shop.archive"},
+		{"invalid target", "shop.orders", "archive"},
+		{"same namespace", "shop.orders", "shop.orders"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CopyCollection("conn-1", tt.sourceNS, tt.targetNS, true); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	var notConnected *core.NotConnectedError
+	_, err := svc.CopyCollection("conn-1", "shop.orders", "backup.orders", true)
+	if !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestCopyDatabase_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name     string
+		sourceDB string
+		targetDB string
+		targetID string
+		mode     string
+	}{
+		{"missing source", "", "archive", "conn-2", ""},
+		{"invalid target", "shop", "arch.ive", "conn-2", ""},
+		{"same database", "shop", "shop", "conn-1", ""},
+		{"unknown mode", "shop", "archive", "conn-2", "merge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := types.CopyDatabaseOptions{Mode: tt.mode}
+			if _, err := svc.CopyDatabase("conn-1", tt.sourceDB, tt.targetID, tt.targetDB, opts); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	_, err := svc.CopyDatabase("conn-1", "shop", "conn-1", "shop_copy", types.CopyDatabaseOptions{Mode: CopyModeDrop})
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+
+	svc.state.SavedConnections = []types.SavedConnection{{ID: "safe", RequireDeleteConfirmation: true}}
+	var required *core.ConfirmationRequiredError
+	_, err = svc.CopyDatabase("conn-1", "shop", "safe", "shop_copy", types.CopyDatabaseOptions{Mode: CopyModeDrop})
+	if !errors.As(err, &required) || required.Target != "shop_copy" {
+		t.Errorf("Expected ConfirmationRequiredError for shop_copy, got %v", err)
+	}
+}
+
+func TestSelectCopySpecs(t *testing.T) {
+	view := func(name, viewOn string) *mongo.CollectionSpecification {
+		opts, _ := bson.Marshal(bson.D{{Key: "viewOn", Value: viewOn}, {Key: "pipeline", Value: bson.A{}}})
+		return &mongo.CollectionSpecification{Name: name, Type: "view", Options: opts}
+	}
+	specs := []*mongo.CollectionSpecification{
+		{Name: "orders", Type: "collection"},
+		view("recent_paid", "paid_orders"),
+		view("paid_orders", "orders"),
+		{Name: "metrics", Type: "timeseries"},
+		{Name: "system.views", Type: "collection"},
+		{Name: "customers", Type: "collection"},
+	}
+
+	collections, views, err := selectCopySpecs(specs, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, c := range collections {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "customers,metrics,orders" {
+		t.Errorf("collections = %v", names)
+	}
+	if len(views) != 2 || views[0].Name != "paid_orders" || views[1].Name != "recent_paid" {
+		t.Errorf("views must be ordered by dependency, got %v", views)
+	}
+
+	collections, views, err = selectCopySpecs(specs, []string{"orders", "paid_orders"})
+	if err != nil || len(collections) != 1 || len(views) != 1 {
+		t.Errorf("selected %d collections and %d views, err %v", len(collections), len(views), err)
+	}
+
+	if _, _, err := selectCopySpecs(specs, []string{"missing"}); err == nil {
+		t.Error("Expected error for a missing collection")
+	}
+
+	cyclic := []*mongo.CollectionSpecification{view("a", "b"), view("b", "a")}
+	if _, _, err := selectCopySpecs(cyclic, nil); err == nil {
+		t.Error("Expected error for circular views")
+	}
+}
+
+func TestCloneCollectionStructure_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	for _, ns := range [][2]string{{"shop", "shop.orders_empty"}, {"shop.orders", "staging"}, {"shop.orders", "shop.orders"}} {
+		if _, err := svc.CloneCollectionStructure("conn-1", ns[0], ns[1]); err == nil {
+			t.Errorf("CloneCollectionStructure(%q, %q) expected error", ns[0], ns[1])
+		}
+	}
+
+	_, err := svc.CloneCollectionStructure("conn-1", "shop.orders", "staging.orders")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -2,30 +2,58 @@
 package document
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/peternagy/mongopal/internal/bsonutil"
 	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/debug"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
+// maxBufferedCounts is how many finished background counts GetQueryCount keeps.
+const maxBufferedCounts = 100
+
 // Service handles document CRUD operations.
 type Service struct {
-	state *core.AppState
+	state    *core.AppState
+	archiver Archiver
+
+	countMu    sync.Mutex
+	counts     map[string]types.QueryCount // Count ID -> finished background count
+	countOrder []string                    // Count IDs, oldest first
 }
 
 // NewService creates a new document service.
 func NewService(state *core.AppState) *Service {
-	return &Service{state: state}
+	return &Service{state: state, counts: make(map[string]types.QueryCount)}
 }
 
+// Count modes for QueryOptions.CountMode.
+const (
+	// CountModeExact counts matching documents before returning (the default).
+	CountModeExact = "exact"
+	// CountModeAsync returns Total = -1 immediately and emits "query:count" once counted.
+	CountModeAsync = "async"
+	// CountModeEstimated uses collection metadata for empty filters and counts
+	// filtered queries asynchronously.
+	CountModeEstimated = "estimated"
+)
+
+// estimatedCountWarning is attached to results whose total comes from collection metadata.
+const estimatedCountWarning = "Total count is approximate (estimated from collection metadata)"
+
+// nearCountWarning is attached to results of $near queries, which cannot be counted.
+const nearCountWarning = "Total count is not available for $near queries"
+
 // FindDocuments executes a query and returns paginated results.
 func (s *Service) FindDocuments(connID, dbName, collName, query string, opts types.QueryOptions) (*types.QueryResult, error) {
 	debug.LogQuery("Executing find query", map[string]interface{}{
@@ -36,7 +64,7 @@ func (s *Service) FindDocuments(connID, dbName, collName, query string, opts typ
 		"limit":      opts.Limit,
 	})
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		debug.LogQuery("Query failed - no connection", map[string]interface{}{
 			"database":   dbName,
@@ -46,25 +74,30 @@ func (s *Service) FindDocuments(connID, dbName, collName, query string, opts typ
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithMaxTime(opts.MaxTimeMS)
 	defer cancel()
+	maxTime := s.state.QueryMaxTime(opts.MaxTimeMS)
 
-	coll := client.Database(dbName).Collection(collName)
+	collOpts, err := ParseReadOptions(opts.ReadPreference, opts.ReadConcern)
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName, collOpts)
 
 	// Parse query filter
-	var filter bson.M
-	if query == "" || query == "{}" {
-		filter = bson.M{}
-	} else {
-		if err := bson.UnmarshalExtJSON([]byte(query), true, &filter); err != nil {
-			debug.LogQuery("Query failed - invalid filter", map[string]interface{}{
-				"database":   dbName,
-				"collection": collName,
-				"query":      query,
-				"error":      err.Error(),
-			})
-			return nil, fmt.Errorf("invalid query: %w", err)
-		}
+	filter, err := ParseFilter(query)
+	if err != nil {
+		debug.LogQuery("Query failed - invalid filter", map[string]interface{}{
+			"database":   dbName,
+			"collection": collName,
+			"query":      query,
+			"error":      err.Error(),
+		})
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	format, err := s.resultFormat(opts.OutputMode)
+	if err != nil {
+		return nil, err
 	}
 
 	// Set defaults
@@ -75,45 +108,73 @@ func (s *Service) FindDocuments(connID, dbName, collName, query string, opts typ
 		opts.Skip = 0
 	}
 
+	keyset := opts.Keyset || opts.AfterID != ""
+	if keyset {
+		if err := validateKeysetSort(opts.Sort); err != nil {
+			return nil, err
+		}
+		opts.Skip = 0
+		opts.Sort = ""
+	}
+
 	startTime := time.Now()
 
 	// Get total count
-	total, err := coll.CountDocuments(ctx, filter)
+	var warnings []string
+	var total int64
+	var countID string
+	if err := validateCountMode(opts.CountMode); err != nil {
+		return nil, err
+	}
+	collation, err := ParseCollation(opts.Collation)
 	if err != nil {
-		return nil, fmt.Errorf("failed to count documents: %w", err)
+		return nil, err
+	}
+	switch {
+	case hasNearOperator(filter):
+		// countDocuments runs the filter in an aggregate $match, which rejects $near
+		total = -1
+		warnings = append(warnings, nearCountWarning)
+	case opts.CountMode == CountModeEstimated && len(filter) == 0:
+		total, err = coll.EstimatedDocumentCount(ctx, options.EstimatedDocumentCount().SetMaxTime(maxTime))
+		if err != nil {
+			return nil, fmt.Errorf("failed to count documents: %w", err)
+		}
+		warnings = append(warnings, estimatedCountWarning)
+	case opts.CountMode == CountModeAsync || opts.CountMode == CountModeEstimated:
+		total = -1
+		countID = uuid.New().String()
+	default:
+		var estimated bool
+		total, estimated, err = s.countDocuments(ctx, coll, filter, collation, maxTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count documents: %w", err)
+		}
+		if estimated {
+			warnings = append(warnings, estimatedCountWarning)
+		}
 	}
 
 	// Build find options
 	findOpts := options.Find().
 		SetSkip(opts.Skip).
-		SetLimit(opts.Limit)
+		SetLimit(opts.Limit).
+		SetMaxTime(maxTime)
 
-	// Parse projection
-	if opts.Projection != "" && opts.Projection != "{}" {
-		var projection bson.M
-		if err := bson.UnmarshalExtJSON([]byte(opts.Projection), true, &projection); err != nil {
-			return nil, fmt.Errorf("invalid projection: %w", err)
-		}
-		findOpts.SetProjection(projection)
+	if err := applyFindOptions(findOpts, opts); err != nil {
+		return nil, err
 	}
 
-	// Parse sort
-	if opts.Sort != "" {
-		sortDoc := bson.D{}
-		// Simple format: "-fieldName" for descending, "fieldName" for ascending
-		for _, field := range strings.Split(opts.Sort, ",") {
-			field = strings.TrimSpace(field)
-			if strings.HasPrefix(field, "-") {
-				sortDoc = append(sortDoc, bson.E{Key: field[1:], Value: -1})
-			} else {
-				sortDoc = append(sortDoc, bson.E{Key: field, Value: 1})
-			}
-		}
-		findOpts.SetSort(sortDoc)
+	// Keyset pagination: walk _id in ascending order, fetching one extra document to detect more pages
+	findFilter := filter
+	if keyset {
+		findFilter = keysetFilter(filter, opts.AfterID)
+		findOpts.SetSort(bson.D{{Key: "_id", Value: 1}})
+		findOpts.SetLimit(opts.Limit + 1)
 	}
 
 	// Execute query
-	cursor, err := coll.Find(ctx, filter, findOpts)
+	cursor, err := coll.Find(ctx, findFilter, findOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find documents: %w", err)
 	}
@@ -122,13 +183,25 @@ func (s *Service) FindDocuments(connID, dbName, collName, query string, opts typ
 	// Collect results as Extended JSON
 	var documents []string
 	var decodeErrors, marshalErrors int
+	var lastID interface{}
+	keysetHasMore := false
+	fetched := int64(0)
 	for cursor.Next(ctx) {
-		var doc bson.M
+		if keyset {
+			if fetched >= opts.Limit {
+				keysetHasMore = true
+				break
+			}
+			fetched++
+		}
+		// bson.D keeps field order, including inside composite _ids
+		var doc bson.D
 		if err := cursor.Decode(&doc); err != nil {
 			decodeErrors++
 			continue
 		}
-		jsonBytes, err := bson.MarshalExtJSON(doc, true, false)
+		lastID, _ = lookupID(doc)
+		jsonBytes, err := marshalResult(doc, format)
 		if err != nil {
 			marshalErrors++
 			continue
@@ -139,7 +212,6 @@ func (s *Service) FindDocuments(connID, dbName, collName, query string, opts typ
 	queryTime := time.Since(startTime).Milliseconds()
 
 	// Build warnings for any decode/marshal errors
-	var warnings []string
 	if decodeErrors > 0 {
 		warnings = append(warnings, fmt.Sprintf("%d document(s) failed to decode", decodeErrors))
 	}
@@ -155,30 +227,197 @@ func (s *Service) FindDocuments(connID, dbName, collName, query string, opts typ
 		"queryTimeMs": queryTime,
 	})
 
-	return &types.QueryResult{
+	result := &types.QueryResult{
 		Documents:   documents,
 		Total:       total,
 		HasMore:     opts.Skip+int64(len(documents)) < total,
 		QueryTimeMs: queryTime,
 		Warnings:    warnings,
-	}, nil
+		CountID:     countID,
+	}
+	if total < 0 {
+		// Count pending or unavailable: a full page suggests there is more
+		result.HasMore = int64(len(documents)) >= opts.Limit
+	}
+	if keyset {
+		result.HasMore = keysetHasMore
+		if keysetHasMore && lastID != nil {
+			if token, err := MarshalValue(lastID); err == nil {
+				result.NextCursor = token
+			}
+		}
+	}
+	if countID != "" {
+		// Started only once the result is built, so a query that fails starts no count
+		go s.countInBackground(connID, countID, coll, filter, collation, opts.MaxTimeMS)
+	}
+	return result, nil
+}
+
+// validateKeysetSort rejects a sort other than ascending _id, the only order keyset
+// pagination can page through.
+func validateKeysetSort(sort string) error {
+	sortDoc := ParseSort(sort)
+	if len(sortDoc) == 0 || (len(sortDoc) == 1 && sortDoc[0].Key == "_id" && sortDoc[0].Value == 1) {
+		return nil
+	}
+	return fmt.Errorf("keyset pagination pages by ascending _id and cannot sort by %q", sort)
+}
+
+// keysetFilter restricts filter to documents whose _id sorts after the cursor token.
+// An empty token returns the filter unchanged (first page).
+func keysetFilter(filter bson.M, afterID string) bson.M {
+	if afterID == "" {
+		return filter
+	}
+	rangeFilter := bson.M{"_id": bson.M{"$gt": ParseCursorToken(afterID)}}
+	if len(filter) == 0 {
+		return rangeFilter
+	}
+	return bson.M{"$and": bson.A{filter, rangeFilter}}
+}
+
+// applyFindOptions parses the projection, sort, and collation from query options onto find options.
+func applyFindOptions(findOpts *options.FindOptions, opts types.QueryOptions) error {
+	// Parse projection
+	if opts.Projection != "" && opts.Projection != "{}" {
+		var projection bson.M
+		if err := bsonutil.UnmarshalExtJSON([]byte(opts.Projection), true, &projection); err != nil {
+			return fmt.Errorf("invalid projection: %w", err)
+		}
+		findOpts.SetProjection(projection)
+	}
+
+	// Parse sort
+	// Simple format: "-fieldName" for descending, "fieldName" for ascending
+	if sortDoc := ParseSort(opts.Sort); len(sortDoc) > 0 {
+		findOpts.SetSort(sortDoc)
+	}
+
+	collation, err := ParseCollation(opts.Collation)
+	if err != nil {
+		return err
+	}
+	if collation != nil {
+		findOpts.SetCollation(collation)
+	}
+	return nil
+}
+
+// countDocuments returns the number of documents matching filter. For an empty filter on a
+// collection larger than the configured EstimatedCountThreshold, the fast metadata-based
+// estimate is returned instead and estimated is true.
+func (s *Service) countDocuments(ctx context.Context, coll *mongo.Collection, filter bson.M, collation *options.Collation, maxTime time.Duration) (total int64, estimated bool, err error) {
+	threshold := s.state.GetSettings().EstimatedCountThreshold
+	if len(filter) == 0 && threshold > 0 {
+		estOpts := options.EstimatedDocumentCount().SetMaxTime(maxTime)
+		if est, err := coll.EstimatedDocumentCount(ctx, estOpts); err == nil && est > threshold {
+			return est, true, nil
+		}
+	}
+
+	countOpts := options.Count().SetMaxTime(maxTime)
+	if collation != nil {
+		countOpts.SetCollation(collation)
+	}
+	total, err = coll.CountDocuments(ctx, filter, countOpts)
+	return total, false, err
+}
+
+// hasNearOperator reports whether a filter uses $near or $nearSphere at any depth.
+func hasNearOperator(v interface{}) bool {
+	switch v := v.(type) {
+	case bson.M:
+		for k, val := range v {
+			if k == "$near" || k == "$nearSphere" || hasNearOperator(val) {
+				return true
+			}
+		}
+	case bson.D:
+		for _, e := range v {
+			if e.Key == "$near" || e.Key == "$nearSphere" || hasNearOperator(e.Value) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, val := range v {
+			if hasNearOperator(val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateCountMode checks that mode is one of the supported count modes or empty.
+func validateCountMode(mode string) error {
+	switch mode {
+	case "", CountModeExact, CountModeAsync, CountModeEstimated:
+		return nil
+	default:
+		return fmt.Errorf("invalid count mode: %s", mode)
+	}
+}
+
+// countInBackground counts documents matching filter and emits the result as a
+// "query:count" event tagged with countID. The result is also kept for GetQueryCount, as the
+// event can arrive before the frontend has the CountID to match it against.
+func (s *Service) countInBackground(connID, countID string, coll *mongo.Collection, filter bson.M, collation *options.Collation, maxTimeMS int64) {
+	ctx, cancel := s.state.ContextWithMaxTime(maxTimeMS)
+	defer cancel()
+
+	event := types.QueryCount{CountID: countID}
+	total, estimated, err := s.countDocuments(ctx, coll, filter, collation, s.state.QueryMaxTime(maxTimeMS))
+	if err != nil {
+		event.Total = -1
+		event.Error = err.Error()
+	} else {
+		event.Total = total
+		event.Estimated = estimated
+	}
+	s.storeCount(event)
+	s.state.EmitConnectionEvent(connID, "query:count", event)
+}
+
+// storeCount keeps a finished background count, dropping the oldest beyond maxBufferedCounts.
+func (s *Service) storeCount(count types.QueryCount) {
+	s.countMu.Lock()
+	defer s.countMu.Unlock()
+	s.counts[count.CountID] = count
+	s.countOrder = append(s.countOrder, count.CountID)
+	if len(s.countOrder) > maxBufferedCounts {
+		delete(s.counts, s.countOrder[0])
+		s.countOrder = s.countOrder[1:]
+	}
+}
+
+// GetQueryCount returns the result of the background count started for a CountID, or nil
+// while it is still counting or once it has been dropped.
+func (s *Service) GetQueryCount(countID string) *types.QueryCount {
+	s.countMu.Lock()
+	defer s.countMu.Unlock()
+	count, ok := s.counts[countID]
+	if !ok {
+		return nil
+	}
+	return &count
 }
 
 // GetDocument returns a single document by ID.
 // docID can be: Extended JSON, ObjectID hex, or plain string.
 func (s *Service) GetDocument(connID, dbName, collName, docID string) (string, error) {
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return "", err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	coll := client.Database(dbName).Collection(collName)
 	filter := bson.M{"_id": ParseDocumentID(docID)}
 
-	var doc bson.M
+	var doc bson.D
 	if err := coll.FindOne(ctx, filter).Decode(&doc); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return "", fmt.Errorf("document not found")
@@ -186,34 +425,35 @@ func (s *Service) GetDocument(connID, dbName, collName, docID string) (string, e
 		return "", fmt.Errorf("failed to get document: %w", err)
 	}
 
-	jsonBytes, err := bson.MarshalExtJSON(doc, true, false)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal document: %w", err)
-	}
-
-	return string(jsonBytes), nil
+	return s.renderDocument(doc)
 }
 
 // UpdateDocument replaces a document.
 // docID can be: Extended JSON, ObjectID hex, or plain string.
-func (s *Service) UpdateDocument(connID, dbName, collName, docID, jsonDoc string) error {
+// With upsert set, a document that doesn't exist is inserted instead of reported as not found.
+// A nil writeConcern keeps the connection's write concern.
+func (s *Service) UpdateDocument(connID, dbName, collName, docID, jsonDoc string, upsert bool, writeConcern *types.WriteConcern) error {
+	if err := s.state.CheckWritable(connID, "update"); err != nil {
+		return err
+	}
+
 	debug.LogDocument("Updating document", map[string]interface{}{
 		"database":   dbName,
 		"collection": collName,
 		"documentId": docID,
 	})
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
-	// Parse the JSON document
-	var doc bson.M
-	if err := bson.UnmarshalExtJSON([]byte(jsonDoc), true, &doc); err != nil {
+	// Parse the JSON document; bson.D keeps field order so a composite _id still matches
+	var doc bson.D
+	if err := bsonutil.UnmarshalExtJSON([]byte(jsonDoc), true, &doc); err != nil {
 		debug.LogDocument("Update failed - invalid JSON", map[string]interface{}{
 			"database":   dbName,
 			"collection": collName,
@@ -223,17 +463,20 @@ func (s *Service) UpdateDocument(connID, dbName, collName, docID, jsonDoc string
 		return fmt.Errorf("invalid JSON: %w", err)
 	}
 
-	coll := client.Database(dbName).Collection(collName)
+	coll, err := writeCollection(client, dbName, collName, writeConcern)
+	if err != nil {
+		return err
+	}
 
 	// Build filter using the _id from the document or the provided docID
 	var filter bson.M
-	if id, ok := doc["_id"]; ok {
+	if id, ok := lookupID(doc); ok {
 		filter = bson.M{"_id": id}
 	} else {
 		filter = bson.M{"_id": ParseDocumentID(docID)}
 	}
 
-	result, err := coll.ReplaceOne(ctx, filter, doc)
+	result, err := coll.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(upsert))
 	if err != nil {
 		debug.LogDocument("Update failed", map[string]interface{}{
 			"database":   dbName,
@@ -244,7 +487,7 @@ func (s *Service) UpdateDocument(connID, dbName, collName, docID, jsonDoc string
 		return fmt.Errorf("failed to update document: %w", err)
 	}
 
-	if result.MatchedCount == 0 {
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
 		debug.LogDocument("Update failed - document not found", map[string]interface{}{
 			"database":   dbName,
 			"collection": collName,
@@ -262,33 +505,90 @@ func (s *Service) UpdateDocument(connID, dbName, collName, docID, jsonDoc string
 	return nil
 }
 
-// InsertDocument creates a new document.
-func (s *Service) InsertDocument(connID, dbName, collName, jsonDoc string) (string, error) {
+// InsertDocument creates a new document. A nil writeConcern keeps the connection's write concern.
+func (s *Service) InsertDocument(connID, dbName, collName, jsonDoc string, writeConcern *types.WriteConcern) (string, error) {
+	if err := s.state.CheckWritable(connID, "insert"); err != nil {
+		return "", err
+	}
+
+	id, err := s.insertDocument(connID, dbName, collName, jsonDoc, writeConcern)
+	if err != nil {
+		return "", err
+	}
+	return formatInsertedID(id), nil
+}
+
+// renderDocument returns doc as the editor shows it: Extended JSON with legacy UUIDs in
+// the representation of the settings.
+func (s *Service) renderDocument(doc bson.D) (string, error) {
+	jsonBytes, err := MarshalDocument(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal document: %w", err)
+	}
+	jsonBytes = bsonutil.RenderLegacyUUIDs(jsonBytes, s.state.GetSettings().LegacyUUIDRepresentation)
+	return string(jsonBytes), nil
+}
+
+// InsertDocumentFull creates a new document and returns the stored document as
+// Extended JSON, including the generated _id and any server-applied values.
+func (s *Service) InsertDocumentFull(connID, dbName, collName, jsonDoc string, writeConcern *types.WriteConcern) (string, error) {
+	if err := s.state.CheckWritable(connID, "insert"); err != nil {
+		return "", err
+	}
+
+	id, err := s.insertDocument(connID, dbName, collName, jsonDoc, writeConcern)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	coll := client.Database(dbName).Collection(collName)
+
+	var doc bson.D
+	if err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return "", fmt.Errorf("document inserted but could not be read back: %w", err)
+	}
+
+	return s.renderDocument(doc)
+}
+
+// insertDocument parses and inserts a single document, returning the raw inserted _id.
+func (s *Service) insertDocument(connID, dbName, collName, jsonDoc string, writeConcern *types.WriteConcern) (interface{}, error) {
 	debug.LogDocument("Inserting document", map[string]interface{}{
 		"database":   dbName,
 		"collection": collName,
 	})
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	// Parse the JSON document
-	var doc bson.M
-	if err := bson.UnmarshalExtJSON([]byte(jsonDoc), true, &doc); err != nil {
+	var doc bson.D
+	if err := bsonutil.UnmarshalExtJSON([]byte(jsonDoc), true, &doc); err != nil {
 		debug.LogDocument("Insert failed - invalid JSON", map[string]interface{}{
 			"database":   dbName,
 			"collection": collName,
 			"error":      err.Error(),
 		})
-		return "", fmt.Errorf("invalid JSON: %w", err)
+		return nil, fmt.Errorf("invalid JSON: %w", err)
 	}
 
-	coll := client.Database(dbName).Collection(collName)
+	coll, err := writeCollection(client, dbName, collName, writeConcern)
+	if err != nil {
+		return nil, err
+	}
 
 	result, err := coll.InsertOne(ctx, doc)
 	if err != nil {
@@ -297,50 +597,81 @@ func (s *Service) InsertDocument(connID, dbName, collName, jsonDoc string) (stri
 			"collection": collName,
 			"error":      err.Error(),
 		})
-		return "", fmt.Errorf("failed to insert document: %w", err)
-	}
-
-	// Return the inserted ID as string
-	var insertedID string
-	switch id := result.InsertedID.(type) {
-	case primitive.ObjectID:
-		insertedID = id.Hex()
-	default:
-		insertedID = fmt.Sprintf("%v", id)
+		return nil, fmt.Errorf("failed to insert document: %w", err)
 	}
 
 	debug.LogDocument("Document inserted", map[string]interface{}{
 		"database":   dbName,
 		"collection": collName,
-		"documentId": insertedID,
+		"documentId": formatInsertedID(result.InsertedID),
 	})
 
-	return insertedID, nil
+	return result.InsertedID, nil
+}
+
+// formatInsertedID returns an inserted _id in a form ParseDocumentID accepts: hex for
+// ObjectIDs, the string itself for string _ids, and canonical Extended JSON otherwise.
+func formatInsertedID(id interface{}) string {
+	switch v := id.(type) {
+	case primitive.ObjectID:
+		return v.Hex()
+	case string:
+		return v
+	default:
+		if s, err := MarshalValue(v); err == nil {
+			return s
+		}
+		return fmt.Sprintf("%v", v)
+	}
 }
 
 // DeleteDocument removes a document.
 // docID can be: Extended JSON (e.g., {"$oid":"..."} or {"$binary":...}), plain ObjectID hex, or string.
-func (s *Service) DeleteDocument(connID, dbName, collName, docID string) error {
+// A nil writeConcern keeps the connection's write concern.
+func (s *Service) DeleteDocument(connID, dbName, collName, docID string, writeConcern *types.WriteConcern) error {
+	if err := s.state.CheckWritable(connID, "delete"); err != nil {
+		return err
+	}
+
 	debug.LogDocument("Deleting document", map[string]interface{}{
 		"database":   dbName,
 		"collection": collName,
 		"documentId": docID,
 	})
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
-	coll := client.Database(dbName).Collection(collName)
+	coll, err := writeCollection(client, dbName, collName, writeConcern)
+	if err != nil {
+		return err
+	}
 
 	// Build filter based on docID format
 	filter := bson.M{"_id": ParseDocumentID(docID)}
 
-	result, err := coll.DeleteOne(ctx, filter)
+	// With trash enabled, FindOneAndDelete returns exactly the document it removed
+	var deletedCount int64
+	var deletedDoc bson.Raw
+	if s.archiver != nil {
+		deletedDoc, err = coll.FindOneAndDelete(ctx, filter).Raw()
+		switch err {
+		case nil:
+			deletedCount = 1
+		case mongo.ErrNoDocuments:
+			err = nil
+		}
+	} else {
+		var result *mongo.DeleteResult
+		if result, err = coll.DeleteOne(ctx, filter); err == nil {
+			deletedCount = result.DeletedCount
+		}
+	}
 	if err != nil {
 		debug.LogDocument("Delete failed", map[string]interface{}{
 			"database":   dbName,
@@ -351,7 +682,7 @@ func (s *Service) DeleteDocument(connID, dbName, collName, docID string) error {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
 
-	if result.DeletedCount == 0 {
+	if deletedCount == 0 {
 		debug.LogDocument("Delete failed - document not found", map[string]interface{}{
 			"database":   dbName,
 			"collection": collName,
@@ -360,6 +691,10 @@ func (s *Service) DeleteDocument(connID, dbName, collName, docID string) error {
 		return fmt.Errorf("document not found")
 	}
 
+	if deletedDoc != nil {
+		s.archiveDeleted(connID, dbName, collName, []bson.Raw{deletedDoc})
+	}
+
 	debug.LogDocument("Document deleted", map[string]interface{}{
 		"database":   dbName,
 		"collection": collName,
@@ -0,0 +1,219 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestKeysetFilter(t *testing.T) {
+	oid := primitive.NewObjectID()
+	token := `{"$oid":"` + oid.Hex() + `"}`
+
+	tests := []struct {
+		name    string
+		filter  bson.M
+		afterID string
+		want    bson.M
+	}{
+		{
+			name:   "first page keeps filter",
+			filter: bson.M{"active": true},
+			want:   bson.M{"active": true},
+		},
+		{
+			name:    "empty filter uses range only",
+			filter:  bson.M{},
+			afterID: token,
+			want:    bson.M{"_id": bson.M{"$gt": oid}},
+		},
+		{
+			name:    "filter combined with range",
+			filter:  bson.M{"active": true},
+			afterID: token,
+			want: bson.M{"$and": bson.A{
+				bson.M{"active": true},
+				bson.M{"_id": bson.M{"$gt": oid}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := keysetFilter(tt.filter, tt.afterID)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keysetFilter() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateKeysetSort(t *testing.T) {
+	for _, sort := range []string{"", " ", "_id"} {
+		if err := validateKeysetSort(sort); err != nil {
+			t.Errorf("validateKeysetSort(%q) unexpected error: %v", sort, err)
+		}
+	}
+	for _, sort := range []string{"-_id", "name", "_id,name"} {
+		if err := validateKeysetSort(sort); err == nil {
+			t.Errorf("validateKeysetSort(%q) expected error", sort)
+		}
+	}
+}
+
+func TestValidateCountMode(t *testing.T) {
+	for _, mode := range []string{"", CountModeExact, CountModeAsync, CountModeEstimated} {
+		if err := validateCountMode(mode); err != nil {
+			t.Errorf("validateCountMode(%q) unexpected error: %v", mode, err)
+		}
+	}
+	if err := validateCountMode("fast"); err == nil {
+		t.Error("Expected error for unknown count mode")
+	}
+}
+
+func TestGetQueryCount_KeepsRecentCounts(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if got := svc.GetQueryCount("pending"); got != nil {
+		t.Errorf("Expected nil for a pending count, got %+v", got)
+	}
+	for i := 0; i <= maxBufferedCounts; i++ {
+		svc.storeCount(types.QueryCount{CountID: fmt.Sprintf("count-%d", i), Total: int64(i)})
+	}
+	if got := svc.GetQueryCount("count-0"); got != nil {
+		t.Errorf("Expected the oldest count to be dropped, got %+v", got)
+	}
+	got := svc.GetQueryCount(fmt.Sprintf("count-%d", maxBufferedCounts))
+	if got == nil || got.Total != maxBufferedCounts {
+		t.Errorf("Expected the newest count to be kept, got %+v", got)
+	}
+}
+
+func TestWritesRejectedOnReadOnlyConnection(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	svc := NewService(state)
+
+	tests := []struct {
+		name     string
+		call     func() error
+		readOnly bool
+	}{
+		{"insert", func() error {
+			_, err := svc.InsertDocument("ro", "db", "coll", `{"a": 1}`, nil)
+			return err
+		}, true},
+		{"update", func() error {
+			return svc.UpdateDocument("ro", "db", "coll", "1", `{"a": 1}`, false, nil)
+		}, true},
+		{"delete", func() error {
+			return svc.DeleteDocument("ro", "db", "coll", "1", nil)
+		}, true},
+		{"insert many", func() error {
+			_, err := svc.InsertManyDocuments("ro", "db", "coll", `[{"a": 1}]`, true, nil)
+			return err
+		}, true},
+		{"delete many", func() error {
+			_, err := svc.DeleteManyDocuments("ro", "db", "coll", `{}`, false, nil)
+			return err
+		}, true},
+		{"delete many dry run", func() error {
+			_, err := svc.DeleteManyDocuments("ro", "db", "coll", `{}`, true, nil)
+			return err
+		}, false},
+		{"copy into read-only target", func() error {
+			_, err := svc.CopyDocuments("rw", "db.coll", "ro", "db.copy", `{}`, types.CopyDocumentsOptions{})
+			return err
+		}, true},
+		{"transaction", func() error {
+			_, err := svc.BeginTransaction("ro")
+			return err
+		}, true},
+		{"aggregation with $out", func() error {
+			_, err := svc.RunAggregation("ro", "db", "coll", `[{"$match": {}}, {"$out": "copy"}]`, types.AggregateOptions{})
+			return err
+		}, true},
+		{"read-only aggregation", func() error {
+			_, err := svc.RunAggregation("ro", "db", "coll", `[{"$match": {}}]`, types.AggregateOptions{})
+			return err
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var readOnly *core.ReadOnlyError
+			if got := errors.As(err, &readOnly); got != tt.readOnly {
+				t.Errorf("error = %v, want read-only error: %v", err, tt.readOnly)
+			}
+		})
+	}
+}
+
+func TestOutputNamespace(t *testing.T) {
+	tests := []struct {
+		name   string
+		stage  bson.D
+		want   string
+		writes bool
+	}{
+		{"match", bson.D{{Key: "$match", Value: bson.D{}}}, ".", false},
+		{"$out collection", bson.D{{Key: "$out", Value: "copy"}}, "shop.copy", true},
+		{"$out other db", bson.D{{Key: "$out", Value: bson.D{{Key: "db", Value: "archive"}, {Key: "coll", Value: "copy"}}}}, "archive.copy", true},
+		{"$merge collection", bson.D{{Key: "$merge", Value: "copy"}}, "shop.copy", true},
+		{"$merge into collection", bson.D{{Key: "$merge", Value: bson.D{{Key: "into", Value: "copy"}}}}, "shop.copy", true},
+		{"$merge into other db", bson.D{{Key: "$merge", Value: bson.D{{Key: "into", Value: bson.D{{Key: "db", Value: "archive"}, {Key: "coll", Value: "copy"}}}}}}, "archive.copy", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, coll, writes := OutputNamespace(tt.stage, "shop")
+			if db+"."+coll != tt.want || writes != tt.writes {
+				t.Errorf("OutputNamespace() = %q, %q, %v, want %q, %v", db, coll, writes, tt.want, tt.writes)
+			}
+		})
+	}
+}
+
+func TestRunAggregation_OutputDatabaseAccess(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "team", AllowedDatabases: []string{"app"}}}
+	svc := NewService(state)
+
+	pipelines := []string{
+		`[{"$out": {"db": "denied", "coll": "copy"}}]`,
+		`[{"$merge": {"into": {"db": "denied", "coll": "copy"}}}]`,
+	}
+	for _, pipeline := range pipelines {
+		_, err := svc.RunAggregation("team", "app", "orders", pipeline, types.AggregateOptions{})
+		var denied *core.DatabaseAccessError
+		if !errors.As(err, &denied) || denied.Database != "denied" {
+			t.Errorf("%s: expected DatabaseAccessError for denied, got %v", pipeline, err)
+		}
+	}
+}
+
+func TestRenderDocument_LegacyUUIDs(t *testing.T) {
+	state := core.NewAppState()
+	state.SetSettings(types.AppSettings{LegacyUUIDRepresentation: bsonutil.UUIDJavaLegacy})
+	svc := NewService(state)
+
+	const id = "00112233-4455-6677-8899-aabbccddeeff"
+	legacy, _ := bsonutil.UUIDToBinary(id, bsonutil.UUIDJavaLegacy)
+	got, err := svc.renderDocument(bson.D{{Key: "_id", Value: legacy}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `"$uuid":"`+id+`","$uuidRepresentation":"javaLegacy"`) {
+		t.Errorf("Expected the legacy UUID rendered with the setting, got %s", got)
+	}
+}
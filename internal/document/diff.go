@@ -0,0 +1,178 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Field diff kinds.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffDocuments compares two Extended JSON documents field by field. Embedded documents and
+// equal-length arrays are compared element-wise; anything else is compared by BSON type and
+// value, so 1 (int) and 1 (long) are reported as a type change.
+func DiffDocuments(docA, docB string) (*types.DocumentDiff, error) {
+	var a, b bson.Raw
+	if err := bsonutil.UnmarshalExtJSON([]byte(docA), true, &a); err != nil {
+		return nil, fmt.Errorf("invalid first document: %w", err)
+	}
+	if err := bsonutil.UnmarshalExtJSON([]byte(docB), true, &b); err != nil {
+		return nil, fmt.Errorf("invalid second document: %w", err)
+	}
+	return diffRawDocuments(a, b)
+}
+
+// DiffDocumentWithServer compares the stored version of a document (as the first document)
+// with a locally edited version, so the user can confirm changes before saving. ns is
+// "database.collection".
+func (s *Service) DiffDocumentWithServer(connID, ns, docID, localJSON string) (*types.DocumentDiff, error) {
+	dbName, collName, err := parseNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	var local bson.Raw
+	if err := bsonutil.UnmarshalExtJSON([]byte(localJSON), true, &local); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	stored, err := client.Database(dbName).Collection(collName).
+		FindOne(ctx, bson.M{"_id": ParseDocumentID(docID)}).Raw()
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("document not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	return diffRawDocuments(stored, local)
+}
+
+func diffRawDocuments(a, b bson.Raw) (*types.DocumentDiff, error) {
+	changes := []types.FieldDiff{}
+	if err := diffDocument("", a, b, &changes); err != nil {
+		return nil, err
+	}
+	return &types.DocumentDiff{Identical: len(changes) == 0, Changes: changes}, nil
+}
+
+// diffDocument appends the differences between two documents, in the field order of a
+// followed by fields only present in b.
+func diffDocument(prefix string, a, b bson.Raw, out *[]types.FieldDiff) error {
+	aElems, err := a.Elements()
+	if err != nil {
+		return err
+	}
+	bElems, err := b.Elements()
+	if err != nil {
+		return err
+	}
+
+	bValues := make(map[string]bson.RawValue, len(bElems))
+	for _, e := range bElems {
+		bValues[e.Key()] = e.Value()
+	}
+	seen := make(map[string]bool, len(aElems))
+	for _, e := range aElems {
+		key := e.Key()
+		seen[key] = true
+		bv, ok := bValues[key]
+		if !ok {
+			av := e.Value()
+			if err := appendDiff(out, DiffRemoved, prefix+key, &av, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := diffValue(prefix+key, e.Value(), bv, out); err != nil {
+			return err
+		}
+	}
+	for _, e := range bElems {
+		if seen[e.Key()] {
+			continue
+		}
+		bv := e.Value()
+		if err := appendDiff(out, DiffAdded, prefix+e.Key(), nil, &bv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffValue(path string, a, b bson.RawValue, out *[]types.FieldDiff) error {
+	switch {
+	case a.Type == bson.TypeEmbeddedDocument && b.Type == bson.TypeEmbeddedDocument:
+		return diffDocument(path+".", a.Document(), b.Document(), out)
+	case a.Type == bson.TypeArray && b.Type == bson.TypeArray:
+		aVals, err := a.Array().Values()
+		if err != nil {
+			return err
+		}
+		bVals, err := b.Array().Values()
+		if err != nil {
+			return err
+		}
+		// Element-wise diffs of arrays that grew or shrank are misleading; report the whole array
+		if len(aVals) == len(bVals) {
+			for i := range aVals {
+				if err := diffValue(path+"."+strconv.Itoa(i), aVals[i], bVals[i], out); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if a.Type == b.Type && bytes.Equal(a.Value, b.Value) {
+		return nil
+	}
+	return appendDiff(out, DiffChanged, path, &a, &b)
+}
+
+// appendDiff records a difference; a is nil for added fields and b for removed ones.
+func appendDiff(out *[]types.FieldDiff, kind, path string, a, b *bson.RawValue) error {
+	d := types.FieldDiff{Path: path, Kind: kind}
+	if a != nil {
+		v, err := MarshalValue(*a)
+		if err != nil {
+			return err
+		}
+		d.OldValue, d.OldType = v, rawTypeName(*a)
+	}
+	if b != nil {
+		v, err := MarshalValue(*b)
+		if err != nil {
+			return err
+		}
+		d.NewValue, d.NewType = v, rawTypeName(*b)
+	}
+	*out = append(*out, d)
+	return nil
+}
+
+// rawTypeName returns the display name of a raw value's type, as schema inference shows it.
+func rawTypeName(v bson.RawValue) string {
+	var decoded interface{}
+	if err := v.Unmarshal(&decoded); err != nil {
+		return v.Type.String()
+	}
+	return bsonutil.TypeName(decoded)
+}
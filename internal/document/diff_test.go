@@ -0,0 +1,71 @@
+package document
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestDiffDocuments(t *testing.T) {
+	a := `{"_id": 1, "name": "Ann", "age": 30, "tags": ["a", "b"], "addr": {"city": "Oslo", "zip": "0150"}, "gone": true, "list": [1]}`
+	b := `{"_id": 1, "name": "Ann", "age": {"$numberLong": "30"}, "tags": ["a", "c"], "addr": {"city": "Bergen", "zip": "0150"}, "list": [1, 2], "extra": null}`
+
+	diff, err := DiffDocuments(a, b)
+	if err != nil {
+		t.Fatalf("DiffDocuments failed: %v", err)
+	}
+	if diff.Identical {
+		t.Error("Expected documents to differ")
+	}
+
+	want := []types.FieldDiff{
+		{Path: "age", Kind: DiffChanged, OldValue: `{"$numberInt":"30"}`, NewValue: `{"$numberLong":"30"}`, OldType: "Int32", NewType: "Int64"},
+		{Path: "tags.1", Kind: DiffChanged, OldValue: `"b"`, NewValue: `"c"`, OldType: "String", NewType: "String"},
+		{Path: "addr.city", Kind: DiffChanged, OldValue: `"Oslo"`, NewValue: `"Bergen"`, OldType: "String", NewType: "String"},
+		{Path: "gone", Kind: DiffRemoved, OldValue: `true`, OldType: "Boolean"},
+		{Path: "list", Kind: DiffChanged, OldValue: `[{"$numberInt":"1"}]`, NewValue: `[{"$numberInt":"1"},{"$numberInt":"2"}]`, OldType: "Array<Int32>", NewType: "Array<Int32>"},
+		{Path: "extra", Kind: DiffAdded, NewValue: `null`, NewType: "Null"},
+	}
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("Unexpected diff:\n got %+v\nwant %+v", diff.Changes, want)
+	}
+}
+
+func TestDiffDocuments_Identical(t *testing.T) {
+	doc := `{"a": 1, "b": {"c": [1, {"d": 2}]}}`
+	diff, err := DiffDocuments(doc, doc)
+	if err != nil {
+		t.Fatalf("DiffDocuments failed: %v", err)
+	}
+	if !diff.Identical || len(diff.Changes) != 0 {
+		t.Errorf("Expected identical documents, got %+v", diff)
+	}
+
+	// Field order alone is not a change
+	diff, _ = DiffDocuments(`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`)
+	if !diff.Identical {
+		t.Errorf("Expected reordered fields to be identical, got %+v", diff.Changes)
+	}
+}
+
+func TestDiffDocuments_Errors(t *testing.T) {
+	if _, err := DiffDocuments(`{"a":`, `{}`); err == nil {
+		t.Error("Expected error for invalid first document")
+	}
+	if _, err := DiffDocuments(`{}`, `[1]`); err == nil {
+		t.Error("Expected error for invalid second document")
+	}
+
+	svc := NewService(core.NewAppState())
+	if _, err := svc.DiffDocumentWithServer("conn-1", "nodot", "1", `{}`); err == nil {
+		t.Error("Expected error for invalid namespace")
+	}
+	var notConnected *core.NotConnectedError
+	_, err := svc.DiffDocumentWithServer("conn-1", "db.coll", "1", `{"a": 1}`)
+	if !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// DefaultDistinctLimit is the number of distinct values returned when no limit is given.
+const DefaultDistinctLimit = 100
+
+// MaxDistinctLimit caps the number of distinct values returned in one call.
+const MaxDistinctLimit = 1000
+
+// DistinctValues returns the distinct values of a field among documents matching the filter,
+// with the number of documents for each value, most frequent first. Array fields are
+// unwound so each element counts as its own value, matching the distinct command. A
+// maxTimeMS of 0 uses the configured query timeout.
+func (s *Service) DistinctValues(connID, dbName, collName, field, filter string, limit int, maxTimeMS int64) (*types.DistinctValuesResult, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil, fmt.Errorf("field name is required")
+	}
+	if strings.HasPrefix(field, "$") {
+		return nil, fmt.Errorf("invalid field name: %s", field)
+	}
+	if limit <= 0 {
+		limit = DefaultDistinctLimit
+	}
+	if limit > MaxDistinctLimit {
+		limit = MaxDistinctLimit
+	}
+
+	filterDoc, err := ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithMaxTime(maxTimeMS)
+	defer cancel()
+
+	debug.LogQuery("Fetching distinct values", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"field":      field,
+		"limit":      limit,
+	})
+
+	coll := client.Database(dbName).Collection(collName)
+	aggOpts := options.Aggregate().SetMaxTime(s.state.QueryMaxTime(maxTimeMS))
+	cursor, err := coll.Aggregate(ctx, buildDistinctPipeline(field, filterDoc, limit), aggOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distinct values: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := &types.DistinctValuesResult{
+		Field:  field,
+		Values: []types.DistinctValue{},
+	}
+	for cursor.Next(ctx) {
+		if len(result.Values) >= limit {
+			result.Truncated = true
+			break
+		}
+		var group struct {
+			ID    bson.RawValue `bson:"_id"`
+			Count int64         `bson:"count"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			continue
+		}
+		value, err := marshalDistinctValue(group.ID)
+		if err != nil {
+			continue
+		}
+		result.Values = append(result.Values, types.DistinctValue{Value: value, Count: group.Count})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read distinct values: %w", err)
+	}
+
+	return result, nil
+}
+
+// buildDistinctPipeline builds the aggregation used by DistinctValues. It fetches one
+// group beyond the limit so the caller can tell whether the result was truncated.
+func buildDistinctPipeline(field string, filter bson.M, limit int) mongo.Pipeline {
+	path := "$" + field
+	return mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$unwind", Value: bson.D{
+			{Key: "path", Value: path},
+			{Key: "preserveNullAndEmptyArrays", Value: true},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: path},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit + 1}},
+	}
+}
+
+// marshalDistinctValue renders a single BSON value as canonical Extended JSON.
+// Values missing from a document group under null.
+func marshalDistinctValue(v bson.RawValue) (string, error) {
+	if v.Type == 0 {
+		return "null", nil
+	}
+	return MarshalValue(v)
+}
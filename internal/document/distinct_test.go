@@ -0,0 +1,85 @@
+package document
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestBuildDistinctPipeline(t *testing.T) {
+	pipeline := buildDistinctPipeline("address.city", bson.M{"active": true}, 10)
+
+	stages := make([]string, len(pipeline))
+	for i, stage := range pipeline {
+		stages[i] = stage[0].Key
+	}
+	want := []string{"$match", "$unwind", "$group", "$sort", "$limit"}
+	if len(stages) != len(want) {
+		t.Fatalf("Expected stages %v, got %v", want, stages)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Errorf("Stage %d: expected %s, got %s", i, want[i], stages[i])
+		}
+	}
+
+	group := pipeline[2][0].Value.(bson.D)
+	if group[0].Value != "$address.city" {
+		t.Errorf("Expected group on $address.city, got %v", group[0].Value)
+	}
+	if pipeline[4][0].Value != 11 {
+		t.Errorf("Expected limit+1 = 11, got %v", pipeline[4][0].Value)
+	}
+}
+
+func TestMarshalDistinctValue(t *testing.T) {
+	oid := primitive.NewObjectID()
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "string", value: "Berlin", want: `"Berlin"`},
+		{name: "int32", value: int32(7), want: `{"$numberInt":"7"}`},
+		{name: "objectId", value: oid, want: `{"$oid":"` + oid.Hex() + `"}`},
+		{name: "null", value: nil, want: "null"},
+		{name: "document", value: bson.D{{Key: "a", Value: "b"}}, want: `{"a":"b"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(bson.D{{Key: "v", Value: tt.value}})
+			if err != nil {
+				t.Fatalf("marshal failed: %v", err)
+			}
+			got, err := marshalDistinctValue(bson.Raw(raw).Lookup("v"))
+			if err != nil {
+				t.Fatalf("marshalDistinctValue failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got, _ := marshalDistinctValue(bson.RawValue{}); got != "null" {
+		t.Errorf("Expected missing value to render as null, got %s", got)
+	}
+}
+
+func TestDistinctValues_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.DistinctValues("conn", "db", "coll", "  ", "", 10, 0); err == nil {
+		t.Error("Expected error for empty field")
+	}
+	if _, err := svc.DistinctValues("conn", "db", "coll", "$name", "", 10, 0); err == nil {
+		t.Error("Expected error for $-prefixed field")
+	}
+	if _, err := svc.DistinctValues("conn", "db", "coll", "name", "{bad", 10, 0); err == nil {
+		t.Error("Expected error for invalid filter")
+	}
+}
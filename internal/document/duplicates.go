@@ -0,0 +1,333 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// DuplicateSampleSize is the number of _ids returned per duplicate group.
+const DuplicateSampleSize = 10
+
+// Which document DeleteDuplicates keeps in each group.
+const (
+	KeepFirst  = "first"  // Lowest _id (the oldest document for ObjectIds)
+	KeepNewest = "newest" // Highest _id
+)
+
+// FindDuplicates groups documents by the given fields and returns groups with more than one
+// document, largest first. limit caps the number of groups (default 100). The sample _ids
+// are collected with $firstN, which needs MongoDB 5.2 or later.
+func (s *Service) FindDuplicates(connID, dbName, collName string, fields []string, limit int) ([]types.DuplicateGroup, error) {
+	if err := validateDuplicateFields(fields); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	debug.LogQuery("Finding duplicates", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"fields":     fields,
+	})
+
+	// $firstN keeps only the sample in memory, however large a group is
+	sample := bson.E{Key: "ids", Value: bson.D{{Key: "$firstN", Value: bson.D{
+		{Key: "input", Value: "$_id"},
+		{Key: "n", Value: DuplicateSampleSize},
+	}}}}
+	pipeline := append(duplicateGroupPipeline(fields, sample),
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	)
+
+	coll := client.Database(dbName).Collection(collName)
+	cursor, err := coll.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	groups := []types.DuplicateGroup{}
+	for cursor.Next(ctx) {
+		var g duplicateGroup
+		if err := cursor.Decode(&g); err != nil {
+			return nil, fmt.Errorf("failed to decode duplicate group: %w", err)
+		}
+		group, err := g.toResult(fields)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	return groups, nil
+}
+
+// DeleteDuplicates deletes all but one document in every group of documents sharing the
+// given fields, keeping the first or newest by _id. The documents of each group are read
+// with their own cursor and deleted in batches, so group size is not bounded by memory.
+// Deleted documents go to the trash when an Archiver is set. On connections with safety
+// settings it needs a confirmed "delete duplicates" token for the namespace.
+func (s *Service) DeleteDuplicates(connID, dbName, collName string, fields []string, keep string) (*types.DeleteDuplicatesResult, error) {
+	if err := s.state.CheckWritable(connID, "delete"); err != nil {
+		return nil, err
+	}
+
+	if err := validateDuplicateFields(fields); err != nil {
+		return nil, err
+	}
+	if keep == "" {
+		keep = KeepFirst
+	}
+	if keep != KeepFirst && keep != KeepNewest {
+		return nil, fmt.Errorf("keep must be %q or %q", KeepFirst, KeepNewest)
+	}
+	if err := s.state.RequireConfirmation(connID, "delete duplicates", dbName+"."+collName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+
+	// Deleting outlasts a single query timeout: the server bounds the grouping with maxTimeMS,
+	// and every group and delete batch gets its own deadline
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	debug.LogDocument("Deleting duplicates", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"fields":     fields,
+		"keep":       keep,
+	})
+
+	accumulator := "$first"
+	if keep == KeepNewest {
+		accumulator = "$last"
+	}
+	pipeline := duplicateGroupPipeline(fields, bson.E{Key: "keep", Value: bson.D{{Key: accumulator, Value: "$_id"}}})
+	aggOpts := options.Aggregate().SetAllowDiskUse(true).SetMaxTime(s.state.QueryTimeout())
+	cursor, err := coll.Aggregate(ctx, pipeline, aggOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := &types.DeleteDuplicatesResult{}
+	for cursor.Next(ctx) {
+		var g duplicateGroup
+		if err := cursor.Decode(&g); err != nil {
+			return result, fmt.Errorf("failed to decode duplicate group: %w", err)
+		}
+		result.Groups++
+		deleted, err := s.deleteGroupDuplicates(connID, dbName, collName, coll, duplicateGroupFilter(fields, g))
+		result.Deleted += deleted
+		if err != nil {
+			return result, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return result, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	debug.LogDocument("Duplicates deleted", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"groups":     result.Groups,
+		"deleted":    result.Deleted,
+	})
+	return result, nil
+}
+
+// deleteGroupDuplicates deletes the documents matching the filter of one duplicate group,
+// reading their _ids with a cursor and deleting them in batches.
+func (s *Service) deleteGroupDuplicates(connID, dbName, collName string, coll *mongo.Collection, filter bson.D) (int64, error) {
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	findOpts := options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}).SetBatchSize(deleteBatchSize)
+	cursor, err := coll.Find(ctx, filter, findOpts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read duplicates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deleted int64
+	batch := make([]interface{}, 0, deleteBatchSize)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return deleted, fmt.Errorf("failed to read duplicates: %w", err)
+		}
+		batch = append(batch, doc.ID)
+		if len(batch) >= deleteBatchSize {
+			n, err := s.deleteByIDs(connID, dbName, collName, coll, batch)
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return deleted, fmt.Errorf("failed to read duplicates: %w", err)
+	}
+	n, err := s.deleteByIDs(connID, dbName, collName, coll, batch)
+	return deleted + n, err
+}
+
+// deleteByIDs deletes documents by _id, archiving the deleted ones when an Archiver is set.
+func (s *Service) deleteByIDs(connID, dbName, collName string, coll *mongo.Collection, ids []interface{}) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	var docs []bson.Raw
+	if s.archiver != nil {
+		cursor, err := coll.Find(ctx, filter)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read documents to delete: %w", err)
+		}
+		for cursor.Next(ctx) {
+			docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read documents to delete: %w", err)
+		}
+	}
+
+	res, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete documents: %w", err)
+	}
+	if s.archiver != nil && res.DeletedCount > 0 {
+		deleted, err := deletedDocuments(ctx, coll, docs, res.DeletedCount)
+		if err != nil {
+			return res.DeletedCount, fmt.Errorf("documents deleted, but failed to check which to move to trash: %w", err)
+		}
+		s.archiveDeleted(connID, dbName, collName, deleted)
+	}
+	return res.DeletedCount, nil
+}
+
+// duplicateGroup is one $group output row of duplicateGroupPipeline.
+type duplicateGroup struct {
+	Key   bson.D        `bson:"_id"`
+	Count int64         `bson:"count"`
+	IDs   []interface{} `bson:"ids"`  // Sample of _ids, for FindDuplicates
+	Keep  interface{}   `bson:"keep"` // _id of the document to keep, for DeleteDuplicates
+}
+
+// toResult renders a group with its key labelled by the original field names.
+func (g duplicateGroup) toResult(fields []string) (types.DuplicateGroup, error) {
+	values := make(map[string]interface{}, len(g.Key))
+	for _, e := range g.Key {
+		values[e.Key] = e.Value
+	}
+	// Missing fields are left out of the group key, so they come back as null
+	key := make(bson.D, 0, len(fields))
+	for i, f := range fields {
+		key = append(key, bson.E{Key: f, Value: values[fmt.Sprintf("k%d", i)]})
+	}
+	keyJSON, err := bson.MarshalExtJSON(key, true, false)
+	if err != nil {
+		return types.DuplicateGroup{}, fmt.Errorf("failed to marshal duplicate key: %w", err)
+	}
+	ids := make([]string, 0, len(g.IDs))
+	for _, id := range g.IDs {
+		s, err := MarshalValue(id)
+		if err != nil {
+			return types.DuplicateGroup{}, err
+		}
+		ids = append(ids, s)
+	}
+	return types.DuplicateGroup{Key: string(keyJSON), Count: g.Count, SampleIDs: ids}, nil
+}
+
+// duplicateGroupPipeline groups documents by fields (in _id order) and keeps groups with
+// more than one document, computing accumulator for each group. Group keys are positional
+// since dotted paths can't be field names.
+func duplicateGroupPipeline(fields []string, accumulator bson.E) mongo.Pipeline {
+	groupKey := make(bson.D, 0, len(fields))
+	for i, f := range fields {
+		groupKey = append(groupKey, bson.E{Key: fmt.Sprintf("k%d", i), Value: "$" + f})
+	}
+	return mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: groupKey},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			accumulator,
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}
+}
+
+// duplicateGroupFilter matches the documents of a group other than the one to keep. It
+// compares with aggregation expressions so it matches exactly what $group grouped together;
+// fields missing from the group key were missing from the documents.
+func duplicateGroupFilter(fields []string, g duplicateGroup) bson.D {
+	values := make(map[string]interface{}, len(g.Key))
+	for _, e := range g.Key {
+		values[e.Key] = e.Value
+	}
+	conds := make(bson.A, 0, len(fields))
+	for i, f := range fields {
+		value, ok := values[fmt.Sprintf("k%d", i)]
+		if !ok {
+			conds = append(conds, bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: "$" + f}}, "missing"}}})
+			continue
+		}
+		conds = append(conds, bson.D{{Key: "$eq", Value: bson.A{"$" + f, bson.D{{Key: "$literal", Value: value}}}}})
+	}
+	return bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: g.Keep}}},
+		{Key: "$expr", Value: bson.D{{Key: "$and", Value: conds}}},
+	}
+}
+
+func validateDuplicateFields(fields []string) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("at least one field is required")
+	}
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if strings.TrimSpace(f) == "" || strings.HasPrefix(f, "$") {
+			return fmt.Errorf("invalid field %q", f)
+		}
+		if seen[f] {
+			return fmt.Errorf("duplicate field %q", f)
+		}
+		seen[f] = true
+	}
+	return nil
+}
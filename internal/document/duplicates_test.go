@@ -0,0 +1,97 @@
+package document
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestDuplicateGroupPipeline(t *testing.T) {
+	keep := bson.E{Key: "keep", Value: bson.D{{Key: "$first", Value: "$_id"}}}
+	pipeline := duplicateGroupPipeline([]string{"email", "address.city"}, keep)
+	if len(pipeline) != 3 {
+		t.Fatalf("Expected 3 stages, got %d", len(pipeline))
+	}
+	group := pipeline[1][0].Value.(bson.D)
+	key := group[0].Value.(bson.D)
+	want := bson.D{{Key: "k0", Value: "$email"}, {Key: "k1", Value: "$address.city"}}
+	if len(key) != 2 || key[0] != want[0] || key[1] != want[1] {
+		t.Errorf("Unexpected group key %v", key)
+	}
+	// Only the count and the given accumulator: no per-group array of every _id
+	if len(group) != 3 || group[2].Key != "keep" {
+		t.Errorf("Unexpected group accumulators %v", group)
+	}
+}
+
+func TestDuplicateGroupFilter(t *testing.T) {
+	g := duplicateGroup{Key: bson.D{{Key: "k1", Value: "$5"}}, Keep: int32(7)}
+	got := duplicateGroupFilter([]string{"email", "price"}, g)
+	want := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: int32(7)}}},
+		{Key: "$expr", Value: bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: "$email"}}, "missing"}}},
+			bson.D{{Key: "$eq", Value: bson.A{"$price", bson.D{{Key: "$literal", Value: "$5"}}}}},
+		}}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("duplicateGroupFilter =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestDuplicateGroup_ToResult(t *testing.T) {
+	g := duplicateGroup{
+		Key:   bson.D{{Key: "k0", Value: "a@example.com"}, {Key: "k1", Value: int32(3)}},
+		Count: 2,
+		IDs:   []interface{}{int32(1), "two"},
+	}
+	got, err := g.toResult([]string{"email", "n"})
+	if err != nil {
+		t.Fatalf("toResult failed: %v", err)
+	}
+	if got.Key != `{"email":"a@example.com","n":{"$numberInt":"3"}}` {
+		t.Errorf("Unexpected key %s", got.Key)
+	}
+	if len(got.SampleIDs) != 2 || got.SampleIDs[0] != `{"$numberInt":"1"}` || got.SampleIDs[1] != `"two"` {
+		t.Errorf("Unexpected sample ids %v", got.SampleIDs)
+	}
+
+	// Missing fields group under null
+	g = duplicateGroup{Key: bson.D{{Key: "k1", Value: "x"}}, Count: 3}
+	got, _ = g.toResult([]string{"email", "name"})
+	if got.Key != `{"email":null,"name":"x"}` {
+		t.Errorf("Expected null key for missing field, got %s", got.Key)
+	}
+}
+
+func TestDuplicates_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	for _, fields := range [][]string{nil, {""}, {"$a"}, {"a", "a"}} {
+		if _, err := svc.FindDuplicates("conn-1", "db", "coll", fields, 0); err == nil {
+			t.Errorf("Expected error for fields %q", fields)
+		}
+	}
+	if _, err := svc.DeleteDuplicates("conn-1", "db", "coll", []string{"a"}, "oldest"); err == nil {
+		t.Error("Expected error for unknown keep mode")
+	}
+
+	var notConnected *core.NotConnectedError
+	if _, err := svc.FindDuplicates("conn-1", "db", "coll", []string{"a"}, 0); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+	if _, err := svc.DeleteDuplicates("conn-1", "db", "coll", []string{"a"}, ""); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+
+	svc.state.SavedConnections = []types.SavedConnection{{ID: "safe", RequireDeleteConfirmation: true}}
+	var required *core.ConfirmationRequiredError
+	if _, err := svc.DeleteDuplicates("safe", "db", "coll", []string{"a"}, ""); !errors.As(err, &required) {
+		t.Errorf("Expected ConfirmationRequiredError, got %v", err)
+	}
+}
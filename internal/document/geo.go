@@ -0,0 +1,299 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Geo operators for GeoQuery.Operator.
+const (
+	GeoOperatorNear       = "near"
+	GeoOperatorWithin     = "within"
+	GeoOperatorIntersects = "intersects"
+)
+
+// DefaultGeoPointsLimit is the number of points returned when no limit is given.
+const DefaultGeoPointsLimit = 1000
+
+// MaxGeoPointsLimit caps the number of points returned in one call.
+const MaxGeoPointsLimit = 10000
+
+// earthRadiusMeters converts meter distances to radians for $centerSphere.
+const earthRadiusMeters = 6378100.0
+
+// BuildGeoQuery builds a filter for a $near, $geoWithin, or $geoIntersects query and
+// returns it as Extended JSON suitable for FindDocuments. $near sorts by distance, and
+// FindDocuments returns its results without a total count.
+func BuildGeoQuery(q types.GeoQuery) (string, error) {
+	filter, err := buildGeoFilter(q)
+	if err != nil {
+		return "", err
+	}
+	b, err := bson.MarshalExtJSON(filter, false, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal geo query: %w", err)
+	}
+	return string(b), nil
+}
+
+// buildGeoFilter builds the filter document for a geo query.
+func buildGeoFilter(q types.GeoQuery) (bson.D, error) {
+	field := strings.TrimSpace(q.Field)
+	if field == "" {
+		return nil, fmt.Errorf("geo field is required")
+	}
+	if q.MaxDistanceMeters < 0 || q.MinDistanceMeters < 0 {
+		return nil, fmt.Errorf("distances cannot be negative")
+	}
+
+	switch q.Operator {
+	case GeoOperatorNear:
+		if err := validateLngLat(q.Longitude, q.Latitude); err != nil {
+			return nil, err
+		}
+		near := bson.D{{Key: "$geometry", Value: pointGeometry(q.Longitude, q.Latitude)}}
+		if q.MaxDistanceMeters > 0 {
+			near = append(near, bson.E{Key: "$maxDistance", Value: q.MaxDistanceMeters})
+		}
+		if q.MinDistanceMeters > 0 {
+			near = append(near, bson.E{Key: "$minDistance", Value: q.MinDistanceMeters})
+		}
+		return bson.D{{Key: field, Value: bson.D{{Key: "$near", Value: near}}}}, nil
+
+	case GeoOperatorWithin:
+		if q.Geometry != "" {
+			geometry, err := parseGeometry(q.Geometry)
+			if err != nil {
+				return nil, err
+			}
+			return bson.D{{Key: field, Value: bson.D{{Key: "$geoWithin", Value: bson.D{{Key: "$geometry", Value: geometry}}}}}}, nil
+		}
+		// Without a geometry, search a circle around the center point
+		if err := validateLngLat(q.Longitude, q.Latitude); err != nil {
+			return nil, err
+		}
+		if q.MaxDistanceMeters <= 0 {
+			return nil, fmt.Errorf("geometry or radius (maxDistanceMeters) is required for within")
+		}
+		circle := bson.A{bson.A{q.Longitude, q.Latitude}, q.MaxDistanceMeters / earthRadiusMeters}
+		return bson.D{{Key: field, Value: bson.D{{Key: "$geoWithin", Value: bson.D{{Key: "$centerSphere", Value: circle}}}}}}, nil
+
+	case GeoOperatorIntersects:
+		if q.Geometry == "" {
+			return nil, fmt.Errorf("geometry is required for intersects")
+		}
+		geometry, err := parseGeometry(q.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: field, Value: bson.D{{Key: "$geoIntersects", Value: bson.D{{Key: "$geometry", Value: geometry}}}}}}, nil
+
+	default:
+		return nil, fmt.Errorf("invalid geo operator: %s", q.Operator)
+	}
+}
+
+// GetGeoPoints returns the locations stored in geoField for documents matching filter as
+// a GeoJSON FeatureCollection. Both GeoJSON geometries and legacy [lng, lat] pairs are
+// supported; documents without a usable location are counted in Skipped. A maxTimeMS of 0
+// uses the configured query timeout.
+func (s *Service) GetGeoPoints(connID, dbName, collName, filter, geoField string, limit int, maxTimeMS int64) (*types.GeoPointsResult, error) {
+	geoField = strings.TrimSpace(geoField)
+	if geoField == "" {
+		return nil, fmt.Errorf("geo field is required")
+	}
+	if limit <= 0 {
+		limit = DefaultGeoPointsLimit
+	}
+	if limit > MaxGeoPointsLimit {
+		limit = MaxGeoPointsLimit
+	}
+
+	filterDoc, err := ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithMaxTime(maxTimeMS)
+	defer cancel()
+
+	// Only fetch documents that have the field, and only the field itself
+	query := bson.M{"$and": bson.A{filterDoc, bson.M{geoField: bson.M{"$exists": true}}}}
+	findOpts := options.Find().
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{geoField: 1}).
+		SetMaxTime(s.state.QueryMaxTime(maxTimeMS))
+
+	debug.LogQuery("Fetching geo points", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"field":      geoField,
+		"limit":      limit,
+	})
+
+	coll := client.Database(dbName).Collection(collName)
+	cursor, err := coll.Find(ctx, query, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := &types.GeoPointsResult{
+		Type:     "FeatureCollection",
+		Features: []types.GeoFeature{},
+	}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			result.Skipped++
+			continue
+		}
+		geometry, ok := extractGeometry(lookupPath(doc, geoField))
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		id, _ := MarshalValue(doc["_id"])
+		result.Features = append(result.Features, types.GeoFeature{
+			Type:       "Feature",
+			Geometry:   geometry,
+			Properties: map[string]interface{}{"id": id},
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read documents: %w", err)
+	}
+
+	return result, nil
+}
+
+// pointGeometry returns a GeoJSON Point.
+func pointGeometry(lng, lat float64) bson.D {
+	return bson.D{
+		{Key: "type", Value: "Point"},
+		{Key: "coordinates", Value: bson.A{lng, lat}},
+	}
+}
+
+// validateLngLat checks that a coordinate pair is within valid WGS84 bounds.
+func validateLngLat(lng, lat float64) error {
+	if lng < -180 || lng > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	return nil
+}
+
+// parseGeometry parses and minimally validates a GeoJSON geometry.
+func parseGeometry(geometryJSON string) (bson.M, error) {
+	var geometry bson.M
+	if err := bson.UnmarshalExtJSON([]byte(geometryJSON), false, &geometry); err != nil {
+		return nil, fmt.Errorf("invalid geometry: %w", err)
+	}
+	if t, _ := geometry["type"].(string); t == "" {
+		return nil, fmt.Errorf("invalid geometry: missing type")
+	}
+	if _, ok := geometry["coordinates"]; !ok {
+		return nil, fmt.Errorf("invalid geometry: missing coordinates")
+	}
+	return geometry, nil
+}
+
+// lookupPath returns the value at a dotted field path, or nil if absent.
+func lookupPath(doc bson.M, path string) interface{} {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(bson.M)
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+// extractGeometry converts a stored location into a GeoJSON geometry. Accepts GeoJSON
+// objects, legacy [lng, lat] arrays, and legacy {lng, lat} embedded documents.
+func extractGeometry(v interface{}) (types.GeoGeometry, bool) {
+	switch val := v.(type) {
+	case bson.M:
+		if t, ok := val["type"].(string); ok {
+			coords, ok := normalizeCoordinates(val["coordinates"])
+			if !ok {
+				return types.GeoGeometry{}, false
+			}
+			return types.GeoGeometry{Type: t, Coordinates: coords}, true
+		}
+		// Legacy embedded document: the first two numeric values are lng, lat
+		var nums []float64
+		for _, key := range []string{"lng", "lon", "longitude", "x"} {
+			if n, ok := geoNumber(val[key]); ok {
+				nums = append(nums, n)
+				break
+			}
+		}
+		for _, key := range []string{"lat", "latitude", "y"} {
+			if n, ok := geoNumber(val[key]); ok {
+				nums = append(nums, n)
+				break
+			}
+		}
+		if len(nums) == 2 {
+			return types.GeoGeometry{Type: "Point", Coordinates: nums}, true
+		}
+	case bson.A:
+		if len(val) == 2 {
+			lng, ok1 := geoNumber(val[0])
+			lat, ok2 := geoNumber(val[1])
+			if ok1 && ok2 {
+				return types.GeoGeometry{Type: "Point", Coordinates: []float64{lng, lat}}, true
+			}
+		}
+	}
+	return types.GeoGeometry{}, false
+}
+
+// normalizeCoordinates converts nested BSON coordinate arrays to plain JSON-friendly values.
+func normalizeCoordinates(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case bson.A:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			n, ok := normalizeCoordinates(item)
+			if !ok {
+				return nil, false
+			}
+			out[i] = n
+		}
+		return out, true
+	default:
+		return geoNumber(val)
+	}
+}
+
+// geoNumber converts a numeric BSON value to float64.
+func geoNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,168 @@
+package document
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestBuildGeoQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    types.GeoQuery
+		contains []string
+		wantErr  bool
+	}{
+		{
+			name:     "near with distances",
+			query:    types.GeoQuery{Operator: "near", Field: "loc", Longitude: 13.4, Latitude: 52.5, MaxDistanceMeters: 1000, MinDistanceMeters: 10},
+			contains: []string{`"$near"`, `"$maxDistance":1000`, `"$minDistance":10`, `"coordinates":[13.4,52.5]`},
+		},
+		{
+			name:     "within polygon",
+			query:    types.GeoQuery{Operator: "within", Field: "loc", Geometry: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`},
+			contains: []string{`"$geoWithin"`, `"$geometry"`, `"Polygon"`},
+		},
+		{
+			name:     "within circle",
+			query:    types.GeoQuery{Operator: "within", Field: "loc", Longitude: 0, Latitude: 0, MaxDistanceMeters: 6378100},
+			contains: []string{`"$centerSphere":[[0.0,0.0],1.0]`},
+		},
+		{
+			name:     "intersects",
+			query:    types.GeoQuery{Operator: "intersects", Field: "area", Geometry: `{"type":"Point","coordinates":[1,2]}`},
+			contains: []string{`"area"`, `"$geoIntersects"`},
+		},
+		{name: "missing field", query: types.GeoQuery{Operator: "near"}, wantErr: true},
+		{name: "bad operator", query: types.GeoQuery{Operator: "box", Field: "loc"}, wantErr: true},
+		{name: "latitude out of range", query: types.GeoQuery{Operator: "near", Field: "loc", Latitude: 95}, wantErr: true},
+		{name: "within without geometry or radius", query: types.GeoQuery{Operator: "within", Field: "loc"}, wantErr: true},
+		{name: "intersects without geometry", query: types.GeoQuery{Operator: "intersects", Field: "loc"}, wantErr: true},
+		{name: "geometry without coordinates", query: types.GeoQuery{Operator: "intersects", Field: "loc", Geometry: `{"type":"Point"}`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildGeoQuery(tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected %s to contain %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractGeometry(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   types.GeoGeometry
+		wantOK bool
+	}{
+		{
+			name:   "GeoJSON point",
+			value:  bson.M{"type": "Point", "coordinates": bson.A{1.5, int32(2)}},
+			want:   types.GeoGeometry{Type: "Point", Coordinates: []interface{}{1.5, 2.0}},
+			wantOK: true,
+		},
+		{
+			name:   "legacy pair",
+			value:  bson.A{int64(10), 20.5},
+			want:   types.GeoGeometry{Type: "Point", Coordinates: []float64{10, 20.5}},
+			wantOK: true,
+		},
+		{
+			name:   "legacy embedded document",
+			value:  bson.M{"lng": 3.0, "lat": 4.0},
+			want:   types.GeoGeometry{Type: "Point", Coordinates: []float64{3, 4}},
+			wantOK: true,
+		},
+		{name: "string", value: "here"},
+		{name: "bad coordinates", value: bson.M{"type": "Point", "coordinates": bson.A{"a", "b"}}},
+		{name: "missing", value: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := extractGeometry(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	doc := bson.M{"address": bson.M{"geo": bson.A{1.0, 2.0}}}
+	if got := lookupPath(doc, "address.geo"); !reflect.DeepEqual(got, bson.A{1.0, 2.0}) {
+		t.Errorf("Unexpected nested value: %#v", got)
+	}
+	if got := lookupPath(doc, "address.missing.deep"); got != nil {
+		t.Errorf("Expected nil for missing path, got %#v", got)
+	}
+}
+
+func TestBuildGeoQuery_FindDocuments(t *testing.T) {
+	state := core.NewAppState()
+	state.DisableEvents = true
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1/?serverSelectionTimeoutMS=100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+	state.SetClient("conn-1", client)
+	svc := NewService(state)
+
+	tests := []struct {
+		name    string
+		query   types.GeoQuery
+		counted bool
+	}{
+		{"near", types.GeoQuery{Field: "loc", Operator: GeoOperatorNear, Longitude: 13.4, Latitude: 52.5, MaxDistanceMeters: 500}, false},
+		{"within radius", types.GeoQuery{Field: "loc", Operator: GeoOperatorWithin, Longitude: 13.4, Latitude: 52.5, MaxDistanceMeters: 500}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := BuildGeoQuery(tt.query)
+			if err != nil {
+				t.Fatalf("BuildGeoQuery: %v", err)
+			}
+			for _, mode := range []string{CountModeExact, CountModeAsync} {
+				// The server is unreachable, so the first server call fails: $near queries
+				// must get past the count to the find
+				_, err := svc.FindDocuments("conn-1", "db", "places", query, types.QueryOptions{CountMode: mode})
+				if err == nil {
+					t.Fatalf("%s: expected an error from the unreachable server", mode)
+				}
+				wantStep := "failed to find documents"
+				if tt.counted && mode == CountModeExact {
+					wantStep = "failed to count documents"
+				}
+				if !strings.Contains(err.Error(), wantStep) {
+					t.Errorf("%s: error = %v, want %q", mode, err, wantStep)
+				}
+			}
+		})
+	}
+}
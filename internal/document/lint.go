@@ -0,0 +1,584 @@
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Lint severities.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+	LintInfo    = "info"
+)
+
+// topLevelOperators are query operators valid in place of a field name.
+var topLevelOperators = map[string]bool{
+	"$and": true, "$or": true, "$nor": true, "$expr": true, "$text": true,
+	"$where": true, "$comment": true, "$jsonSchema": true,
+}
+
+// fieldOperators are query operators valid inside a field condition.
+var fieldOperators = map[string]bool{
+	"$eq": true, "$ne": true, "$gt": true, "$gte": true, "$lt": true, "$lte": true,
+	"$in": true, "$nin": true, "$exists": true, "$type": true, "$regex": true, "$options": true,
+	"$not": true, "$elemMatch": true, "$size": true, "$all": true, "$mod": true,
+	"$near": true, "$nearSphere": true, "$geoWithin": true, "$geoIntersects": true,
+	"$geometry": true, "$maxDistance": true, "$minDistance": true, "$box": true,
+	"$center": true, "$centerSphere": true, "$polygon": true,
+	"$bitsAllSet": true, "$bitsAnySet": true, "$bitsAllClear": true, "$bitsAnyClear": true,
+	"$search": true, "$language": true, "$caseSensitive": true, "$diacriticSensitive": true,
+}
+
+// extJSONWrappers are Extended JSON type wrappers that may appear as values.
+var extJSONWrappers = map[string]bool{
+	"$oid": true, "$date": true, "$numberLong": true, "$numberInt": true, "$numberDouble": true,
+	"$numberDecimal": true, "$binary": true, "$uuid": true, "$regularExpression": true,
+	"$timestamp": true, "$minKey": true, "$maxKey": true, "$symbol": true, "$code": true,
+	"$undefined": true, "$dbPointer": true, "$regex": true,
+}
+
+// projectionOperators are operators valid as projection values.
+var projectionOperators = map[string]bool{
+	"$slice": true, "$elemMatch": true, "$meta": true,
+}
+
+var (
+	hexObjectIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	integerPattern     = regexp.MustCompile(`^-?[0-9]+$`)
+	uuidPattern        = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// LintQuery statically checks a filter, projection, and sort (FindDocuments format) for
+// mistakes that ValidateJSON doesn't catch: unknown operators, malformed Extended JSON
+// literals, projections mixing inclusion and exclusion, and malformed sort specs.
+func LintQuery(filter, projection, sortSpec string) *types.LintResult {
+	l := &linter{}
+	l.lintQuery(filter, projection, sortSpec)
+	return l.result()
+}
+
+// LintQueryWithIndexes runs LintQuery and additionally reports filters and sorts that
+// cannot use any of the given index key documents (as returned by listIndexes, in key order).
+func LintQueryWithIndexes(filter, projection, sortSpec string, indexKeys []bson.D) *types.LintResult {
+	l := &linter{}
+	if doc, ok := l.lintQuery(filter, projection, sortSpec); ok {
+		l.lintIndexUsage(doc, sortSpec, indexKeys)
+	}
+	return l.result()
+}
+
+// linter accumulates lint issues.
+type linter struct {
+	issues []types.LintIssue
+}
+
+func (l *linter) add(severity, code, path, format string, args ...interface{}) {
+	l.issues = append(l.issues, types.LintIssue{
+		Severity: severity,
+		Code:     code,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// lintQuery runs the static checks and returns the decoded filter if it parsed.
+func (l *linter) lintQuery(filter, projection, sortSpec string) (map[string]interface{}, bool) {
+	doc, ok := l.parseObject("filter", filter)
+	if ok {
+		l.lintFilter("filter", doc)
+	}
+	if proj, ok := l.parseObject("projection", projection); ok {
+		l.lintProjection(proj)
+	}
+	l.lintSort(sortSpec)
+	return doc, ok
+}
+
+func (l *linter) result() *types.LintResult {
+	res := &types.LintResult{Valid: true, Issues: l.issues}
+	if res.Issues == nil {
+		res.Issues = []types.LintIssue{}
+	}
+	for _, issue := range res.Issues {
+		if issue.Severity == LintError {
+			res.Valid = false
+		}
+	}
+	return res
+}
+
+// parseObject decodes a JSON object, accepting the relaxed shell syntax FindDocuments
+// accepts. Empty input is treated as an empty object.
+func (l *linter) parseObject(path, input string) (map[string]interface{}, bool) {
+	if strings.TrimSpace(input) == "" {
+		return map[string]interface{}{}, true
+	}
+	normalized, err := bsonutil.NormalizeExtJSON(input)
+	if err != nil {
+		l.add(LintError, "invalid-json", path, "invalid JSON: %v", err)
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(normalized)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		l.add(LintError, "invalid-json", path, "invalid JSON: %v", err)
+		return nil, false
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		l.add(LintError, "not-object", path, "%s must be a JSON object", path)
+		return nil, false
+	}
+	return obj, true
+}
+
+// lintFilter checks a query document where keys are field names or top-level operators.
+func (l *linter) lintFilter(path string, doc map[string]interface{}) {
+	for _, key := range sortedKeys(doc) {
+		value := doc[key]
+		keyPath := path + "." + key
+		if !strings.HasPrefix(key, "$") {
+			l.lintCondition(keyPath, value)
+			continue
+		}
+		switch {
+		case key == "$and" || key == "$or" || key == "$nor":
+			arr, ok := value.([]interface{})
+			if !ok || len(arr) == 0 {
+				l.add(LintError, "invalid-logical", keyPath, "%s requires a non-empty array", key)
+				continue
+			}
+			for i, item := range arr {
+				sub, ok := item.(map[string]interface{})
+				if !ok {
+					l.add(LintError, "invalid-logical", fmt.Sprintf("%s[%d]", keyPath, i), "%s entries must be objects", key)
+					continue
+				}
+				l.lintFilter(fmt.Sprintf("%s[%d]", keyPath, i), sub)
+			}
+		case key == "$where":
+			l.add(LintWarning, "where-operator", keyPath, "$where runs JavaScript for every document and cannot use indexes")
+		case topLevelOperators[key]:
+			// $expr, $text, $jsonSchema, $comment: contents not checked
+		case fieldOperators[key]:
+			l.add(LintError, "misplaced-operator", keyPath, "%s must be used inside a field condition, e.g. {\"field\": {\"%s\": ...}}", key, key)
+		default:
+			l.unknownOperator(keyPath, key)
+		}
+	}
+}
+
+// lintCondition checks the value of a field in a filter.
+func (l *linter) lintCondition(path string, value interface{}) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		l.lintValue(path, value)
+		return
+	}
+	if isExtJSONWrapper(obj) {
+		l.lintExtJSON(path, obj)
+		return
+	}
+
+	hasOperator, hasPlain := false, false
+	for _, key := range sortedKeys(obj) {
+		if strings.HasPrefix(key, "$") {
+			hasOperator = true
+		} else {
+			hasPlain = true
+		}
+	}
+	if !hasOperator {
+		// Exact embedded document match
+		l.lintValue(path, value)
+		return
+	}
+	if hasPlain {
+		l.add(LintError, "mixed-condition", path, "condition mixes operators with field names")
+	}
+
+	for _, key := range sortedKeys(obj) {
+		if !strings.HasPrefix(key, "$") {
+			continue
+		}
+		opPath := path + "." + key
+		v := obj[key]
+		switch {
+		case key == "$not":
+			if sub, ok := v.(map[string]interface{}); ok {
+				l.lintCondition(opPath, sub)
+			}
+		case key == "$elemMatch":
+			if sub, ok := v.(map[string]interface{}); ok {
+				l.lintElemMatch(opPath, sub)
+			}
+		case key == "$in" || key == "$nin" || key == "$all":
+			arr, ok := v.([]interface{})
+			if !ok {
+				l.add(LintError, "expected-array", opPath, "%s requires an array", key)
+				continue
+			}
+			for i, item := range arr {
+				l.lintValue(fmt.Sprintf("%s[%d]", opPath, i), item)
+			}
+		case key == "$exists":
+			if _, ok := v.(bool); !ok {
+				l.add(LintWarning, "exists-not-bool", opPath, "$exists expects true or false")
+			}
+		case fieldOperators[key]:
+			l.lintValue(opPath, v)
+		case topLevelOperators[key]:
+			l.add(LintError, "misplaced-operator", opPath, "%s is only valid at the top level of a filter", key)
+		default:
+			l.unknownOperator(opPath, key)
+		}
+	}
+}
+
+// lintElemMatch handles $elemMatch, which accepts either a sub-filter or operator conditions.
+func (l *linter) lintElemMatch(path string, doc map[string]interface{}) {
+	for _, key := range sortedKeys(doc) {
+		if strings.HasPrefix(key, "$") && fieldOperators[key] {
+			l.lintCondition(path, doc)
+			return
+		}
+	}
+	l.lintFilter(path, doc)
+}
+
+// lintValue checks literal values for malformed Extended JSON wrappers.
+func (l *linter) lintValue(path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isExtJSONWrapper(v) {
+			l.lintExtJSON(path, v)
+			return
+		}
+		for _, key := range sortedKeys(v) {
+			l.lintValue(path+"."+key, v[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			l.lintValue(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	}
+}
+
+// lintExtJSON validates the payload of an Extended JSON type wrapper.
+func (l *linter) lintExtJSON(path string, obj map[string]interface{}) {
+	for key, v := range obj {
+		keyPath := path + "." + key
+		switch key {
+		case "$oid":
+			s, ok := v.(string)
+			if !ok || !hexObjectIDPattern.MatchString(s) {
+				l.add(LintError, "invalid-oid", keyPath, "$oid must be a 24-character hex string")
+			}
+		case "$date":
+			if !validExtJSONDate(v) {
+				l.add(LintError, "invalid-date", keyPath, "$date must be an ISO-8601 string, a millisecond number, or {\"$numberLong\": \"...\"}")
+			}
+		case "$numberLong", "$numberInt":
+			s, ok := v.(string)
+			if !ok || !integerPattern.MatchString(s) {
+				l.add(LintError, "invalid-number", keyPath, "%s must be a string of digits, e.g. \"42\"", key)
+			}
+		case "$numberDouble", "$numberDecimal":
+			if _, ok := v.(string); !ok {
+				l.add(LintError, "invalid-number", keyPath, "%s must be a string, e.g. \"1.5\"", key)
+			}
+		case "$uuid":
+			s, ok := v.(string)
+			if !ok || !uuidPattern.MatchString(s) {
+				l.add(LintError, "invalid-uuid", keyPath, "$uuid must be a hyphenated UUID string")
+			}
+		}
+	}
+}
+
+// lintProjection checks for mixed inclusion/exclusion and unknown projection operators.
+func (l *linter) lintProjection(doc map[string]interface{}) {
+	var included, excluded []string
+	for _, key := range sortedKeys(doc) {
+		path := "projection." + key
+		if strings.HasPrefix(key, "$") {
+			l.add(LintError, "invalid-projection-field", path, "projection field names cannot start with $")
+			continue
+		}
+		switch v := doc[key].(type) {
+		case json.Number:
+			if v.String() == "0" {
+				excluded = append(excluded, key)
+			} else {
+				included = append(included, key)
+			}
+		case bool:
+			if v {
+				included = append(included, key)
+			} else {
+				excluded = append(excluded, key)
+			}
+		case map[string]interface{}:
+			for op := range v {
+				if strings.HasPrefix(op, "$") && !projectionOperators[op] && !extJSONWrappers[op] {
+					// Aggregation expressions are allowed (4.4+), so only warn
+					l.add(LintWarning, "projection-expression", path+"."+op, "%s is not a projection operator; it will be evaluated as an aggregation expression", op)
+				}
+			}
+			included = append(included, key)
+		default:
+			included = append(included, key)
+		}
+	}
+
+	// _id may be excluded alongside inclusions
+	var otherExcluded []string
+	for _, key := range excluded {
+		if key != "_id" {
+			otherExcluded = append(otherExcluded, key)
+		}
+	}
+	if len(included) > 0 && len(otherExcluded) > 0 {
+		l.add(LintError, "mixed-projection", "projection",
+			"projection cannot mix inclusion (%s) and exclusion (%s); only _id may be excluded in an inclusion projection",
+			strings.Join(included, ", "), strings.Join(otherExcluded, ", "))
+	}
+}
+
+// lintSort checks the FindDocuments sort format ("field,-field").
+func (l *linter) lintSort(sortSpec string) {
+	trimmed := strings.TrimSpace(sortSpec)
+	if trimmed == "" {
+		return
+	}
+	if strings.HasPrefix(trimmed, "{") {
+		l.add(LintError, "sort-format", "sort", "sort uses the \"field,-field\" format, not a JSON document")
+		return
+	}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(trimmed, ",") {
+		field := strings.TrimSpace(part)
+		if field == "" {
+			l.add(LintWarning, "sort-empty-field", "sort", "sort contains an empty field")
+			continue
+		}
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "-"), "+")
+		if field == "" || strings.HasPrefix(field, "$") || strings.ContainsAny(field, " \t") {
+			l.add(LintError, "sort-invalid-field", "sort."+field, "invalid sort field %q", part)
+			continue
+		}
+		if seen[field] {
+			l.add(LintWarning, "sort-duplicate-field", "sort."+field, "sort field %s appears more than once", field)
+		}
+		seen[field] = true
+	}
+}
+
+// lintIndexUsage warns when a filter has no field usable as an index prefix, meaning the
+// server must scan every document, and notes when a sort will happen in memory.
+func (l *linter) lintIndexUsage(filter map[string]interface{}, sortSpec string, indexKeys []bson.D) {
+	leading := map[string]bool{}
+	hasText := false
+	for _, keys := range indexKeys {
+		if len(keys) == 0 {
+			continue
+		}
+		leading[keys[0].Key] = true
+		for _, k := range keys {
+			if v, ok := k.Value.(string); ok && v == "text" {
+				hasText = true
+			}
+		}
+	}
+
+	if _, ok := filter["$text"]; ok {
+		if !hasText {
+			l.add(LintError, "text-index-missing", "filter.$text", "$text requires a text index on the collection")
+		}
+		return
+	}
+
+	if len(filter) == 0 {
+		if field := firstSortField(sortSpec); field != "" && leading[field] {
+			return
+		}
+		l.add(LintInfo, "full-scan", "filter", "empty filter reads every document in the collection")
+		return
+	}
+
+	if !filterUsesIndex(filter, leading) {
+		fields := indexableFields(filter)
+		if len(fields) == 0 {
+			l.add(LintWarning, "collection-scan", "filter", "filter has no index-eligible conditions and will scan the whole collection")
+		} else {
+			l.add(LintWarning, "collection-scan", "filter",
+				"no index starts with %s; the query will scan the whole collection", strings.Join(fields, ", "))
+		}
+		return
+	}
+
+	if field := firstSortField(sortSpec); field != "" && !indexContains(indexKeys, field) {
+		l.add(LintInfo, "in-memory-sort", "sort."+field, "no index contains %s; results will be sorted in memory", field)
+	}
+}
+
+// filterUsesIndex reports whether some condition of the filter can use an index whose
+// first key is in leading. A top-level $or can only use indexes if every branch can.
+func filterUsesIndex(filter map[string]interface{}, leading map[string]bool) bool {
+	for _, field := range indexableFields(filter) {
+		if leading[field] {
+			return true
+		}
+	}
+	if branches, ok := filter["$or"].([]interface{}); ok && len(branches) > 0 {
+		for _, branch := range branches {
+			sub, ok := branch.(map[string]interface{})
+			if !ok || !filterUsesIndex(sub, leading) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// indexableFields returns the filter fields (including those under a top-level $and) whose
+// conditions can bound an index scan. Negations and unanchored regexes cannot.
+func indexableFields(filter map[string]interface{}) []string {
+	var fields []string
+	for _, key := range sortedKeys(filter) {
+		value := filter[key]
+		if key == "$and" {
+			if arr, ok := value.([]interface{}); ok {
+				for _, item := range arr {
+					if sub, ok := item.(map[string]interface{}); ok {
+						fields = append(fields, indexableFields(sub)...)
+					}
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(key, "$") || !conditionIsIndexable(value) {
+			continue
+		}
+		fields = append(fields, key)
+	}
+	return fields
+}
+
+// conditionIsIndexable reports whether a field condition can bound an index scan.
+func conditionIsIndexable(value interface{}) bool {
+	obj, ok := value.(map[string]interface{})
+	if !ok || isExtJSONWrapper(obj) {
+		return true
+	}
+	if len(obj) == 0 {
+		return true
+	}
+	for key, v := range obj {
+		switch key {
+		case "$ne", "$nin", "$not", "$where":
+			continue
+		case "$exists":
+			if b, ok := v.(bool); ok && !b {
+				continue
+			}
+			return true
+		case "$regex":
+			if s, ok := v.(string); ok && !strings.HasPrefix(s, "^") {
+				continue
+			}
+			return true
+		case "$options":
+			continue
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// indexContains reports whether any index includes field.
+func indexContains(indexKeys []bson.D, field string) bool {
+	for _, keys := range indexKeys {
+		for _, k := range keys {
+			if k.Key == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstSortField returns the first field of a FindDocuments sort spec, without direction.
+func firstSortField(sortSpec string) string {
+	first := strings.TrimSpace(strings.Split(sortSpec, ",")[0])
+	return strings.TrimPrefix(strings.TrimPrefix(first, "-"), "+")
+}
+
+// unknownOperator reports an unrecognized $-prefixed key, suggesting a likely fix.
+func (l *linter) unknownOperator(path, key string) {
+	lower := strings.ToLower(key)
+	for _, known := range []map[string]bool{fieldOperators, topLevelOperators} {
+		for op := range known {
+			if strings.ToLower(op) == lower {
+				l.add(LintError, "unknown-operator", path, "unknown operator %s (did you mean %s?)", key, op)
+				return
+			}
+		}
+	}
+	l.add(LintError, "unknown-operator", path, "unknown operator %s", key)
+}
+
+// isExtJSONWrapper reports whether an object is a single-key Extended JSON type wrapper.
+// $regex is excluded since it is also a query operator.
+func isExtJSONWrapper(obj map[string]interface{}) bool {
+	if len(obj) != 1 {
+		return false
+	}
+	for key := range obj {
+		return extJSONWrappers[key] && key != "$regex"
+	}
+	return false
+}
+
+// validExtJSONDate reports whether v is a valid $date payload.
+func validExtJSONDate(v interface{}) bool {
+	switch d := v.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05Z07:00", "2006-01-02"} {
+			if _, err := time.Parse(layout, d); err == nil {
+				return true
+			}
+		}
+		return false
+	case json.Number:
+		_, err := d.Int64()
+		return err == nil
+	case map[string]interface{}:
+		s, ok := d["$numberLong"].(string)
+		return ok && len(d) == 1 && integerPattern.MatchString(s)
+	}
+	return false
+}
+
+// sortedKeys returns map keys in a stable order so issues are reported deterministically.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+package document
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLintQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     string
+		projection string
+		sort       string
+		codes      []string
+		valid      bool
+	}{
+		{name: "empty", valid: true},
+		{name: "valid query", filter: `{"age":{"$gte":18},"$or":[{"a":1},{"b":{"$in":[1,2]}}]}`, projection: `{"name":1,"_id":0}`, sort: "-age,name", valid: true},
+		{name: "invalid json", filter: `{"a":`, codes: []string{"invalid-json"}},
+		{name: "unknown operator", filter: `{"age":{"$gtt":1}}`, codes: []string{"unknown-operator"}},
+		{name: "wrong case operator", filter: `{"age":{"$GTE":1}}`, codes: []string{"unknown-operator"}},
+		{name: "unknown top-level operator", filter: `{"$foo":1}`, codes: []string{"unknown-operator"}},
+		{name: "field operator at top level", filter: `{"$gt":1}`, codes: []string{"misplaced-operator"}},
+		{name: "mixed condition", filter: `{"a":{"$gt":1,"b":2}}`, codes: []string{"mixed-condition"}},
+		{name: "bad oid", filter: `{"_id":{"$oid":"1234"}}`, codes: []string{"invalid-oid"}},
+		{name: "bad oid in $in", filter: `{"_id":{"$in":[{"$oid":"zz"}]}}`, codes: []string{"invalid-oid"}},
+		{name: "valid oid and date", filter: `{"_id":{"$oid":"507f1f77bcf86cd799439011"},"at":{"$lt":{"$date":"2024-01-01T00:00:00Z"}}}`, valid: true},
+		{name: "bad date", filter: `{"at":{"$date":"yesterday"}}`, codes: []string{"invalid-date"}},
+		{name: "numeric date", filter: `{"at":{"$date":1700000000000}}`, valid: true},
+		{name: "bad numberLong", filter: `{"n":{"$numberLong":42}}`, codes: []string{"invalid-number"}},
+		{name: "$where warns", filter: `{"$where":"this.a > 1"}`, codes: []string{"where-operator"}, valid: true},
+		{name: "empty $or", filter: `{"$or":[]}`, codes: []string{"invalid-logical"}},
+		{name: "mixed projection", projection: `{"a":1,"b":0}`, codes: []string{"mixed-projection"}},
+		{name: "exclude _id in inclusion", projection: `{"a":true,"_id":false}`, valid: true},
+		{name: "projection operator", projection: `{"tags":{"$slice":5}}`, valid: true},
+		{name: "json sort", sort: `{"a":1}`, codes: []string{"sort-format"}},
+		{name: "duplicate sort field", sort: "a,-a", codes: []string{"sort-duplicate-field"}, valid: true},
+		{name: "shell syntax", filter: `{_id: ObjectId("507f1f77bcf86cd799439011"), at: {$lt: ISODate("2024-01-01T00:00:00Z")}, n: NumberLong(5), name: /^a/i,}`, projection: `{name: 1, _id: 0}`, valid: true},
+		{name: "shell syntax with unknown operator", filter: `{age: {$gtt: 1}}`, codes: []string{"unknown-operator"}},
+		{name: "shell syntax bad oid", filter: `{'_id': {'$oid': '1234'}}`, codes: []string{"invalid-oid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := LintQuery(tt.filter, tt.projection, tt.sort)
+			if res.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v (issues: %+v)", res.Valid, tt.valid, res.Issues)
+			}
+			var got []string
+			for _, issue := range res.Issues {
+				got = append(got, issue.Code)
+			}
+			if len(got) != len(tt.codes) {
+				t.Fatalf("codes = %v, want %v", got, tt.codes)
+			}
+			for i := range got {
+				if got[i] != tt.codes[i] {
+					t.Errorf("codes = %v, want %v", got, tt.codes)
+				}
+			}
+		})
+	}
+}
+
+func TestLintQueryWithIndexes(t *testing.T) {
+	indexes := []bson.D{
+		{{Key: "_id", Value: 1}},
+		{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+	}
+
+	tests := []struct {
+		name   string
+		filter string
+		sort   string
+		code   string // Expected index-related issue, empty for none
+	}{
+		{name: "indexed equality", filter: `{"status":"paid"}`},
+		{name: "second key only", filter: `{"createdAt":{"$gt":1}}`, code: "collection-scan"},
+		{name: "unindexed field", filter: `{"email":"a@b.c"}`, code: "collection-scan"},
+		{name: "negation cannot use index", filter: `{"status":{"$ne":"paid"}}`, code: "collection-scan"},
+		{name: "unanchored regex", filter: `{"status":{"$regex":"ai"}}`, code: "collection-scan"},
+		{name: "anchored regex", filter: `{"status":{"$regex":"^pa"}}`},
+		{name: "indexed field in $and", filter: `{"$and":[{"email":"x"},{"status":"paid"}]}`},
+		{name: "$or all branches indexed", filter: `{"$or":[{"_id":1},{"status":"paid"}]}`},
+		{name: "$or with unindexed branch", filter: `{"$or":[{"_id":1},{"email":"x"}]}`, code: "collection-scan"},
+		{name: "empty filter", code: "full-scan"},
+		{name: "empty filter sorted by index", sort: "-status"},
+		{name: "in-memory sort", filter: `{"status":"paid"}`, sort: "name", code: "in-memory-sort"},
+		{name: "text without text index", filter: `{"$text":{"$search":"x"}}`, code: "text-index-missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := LintQueryWithIndexes(tt.filter, "", tt.sort, indexes)
+			var got string
+			for _, issue := range res.Issues {
+				got = issue.Code
+			}
+			if len(res.Issues) > 1 {
+				t.Fatalf("expected at most one issue, got %+v", res.Issues)
+			}
+			if got != tt.code {
+				t.Errorf("code = %q, want %q (issues: %+v)", got, tt.code, res.Issues)
+			}
+		})
+	}
+
+	textIndexes := []bson.D{{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}}
+	if res := LintQueryWithIndexes(`{"$text":{"$search":"x"}}`, "", "", textIndexes); len(res.Issues) != 0 {
+		t.Errorf("expected no issues with a text index, got %+v", res.Issues)
+	}
+}
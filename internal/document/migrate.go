@@ -0,0 +1,373 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// migrationBatchSize is the number of documents updated per round trip by field migrations.
+const migrationBatchSize = 1000
+
+// RenameField renames oldPath to newPath with $rename in every document matching filter that
+// has the field. With dryRun set, only the number of affected documents is returned. Otherwise
+// documents are updated in batches, emitting "migration:progress" events tagged with an
+// operation ID that can be passed to CancelQuery to stop between batches.
+func (s *Service) RenameField(connID, dbName, collName, oldPath, newPath, filter string, dryRun bool) (*types.MigrationResult, error) {
+	if !dryRun {
+		if err := s.state.CheckWritable(connID, "rename field"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateMigrationPath(oldPath); err != nil {
+		return nil, err
+	}
+	if err := validateMigrationPath(newPath); err != nil {
+		return nil, err
+	}
+	if oldPath == newPath || strings.HasPrefix(newPath, oldPath+".") || strings.HasPrefix(oldPath, newPath+".") {
+		return nil, fmt.Errorf("cannot rename %q to %q", oldPath, newPath)
+	}
+
+	filterDoc, err := ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	matchDoc := andFilter(filterDoc, bson.M{oldPath: bson.M{"$exists": true}})
+	update := bson.M{"$rename": bson.M{oldPath: newPath}}
+
+	return s.runMigration(connID, dbName, collName, "Renaming field", matchDoc, update, dryRun, map[string]interface{}{
+		"from": oldPath,
+		"to":   newPath,
+	})
+}
+
+// runMigration counts the documents matching filter and, unless dryRun is set, applies update
+// to them in batches with progress events and cancellation.
+func (s *Service) runMigration(connID, dbName, collName, action string, filter bson.M, update interface{}, dryRun bool, logFields map[string]interface{}) (*types.MigrationResult, error) {
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+
+	countCtx, countCancel := s.state.ContextWithTimeout()
+	matched, err := coll.CountDocuments(countCtx, filter)
+	countCancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+	if dryRun {
+		return &types.MigrationResult{DryRun: true, Matched: matched}, nil
+	}
+
+	operationID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.state.SetQueryCancel(operationID, cancel)
+	defer s.state.ClearQueryCancel(operationID)
+	defer cancel()
+
+	logFields["database"] = dbName
+	logFields["collection"] = collName
+	logFields["matched"] = matched
+	logFields["operationId"] = operationID
+	debug.LogDocument(action, logFields)
+
+	result := &types.MigrationResult{OperationID: operationID, Matched: matched}
+	emitProgress := func() {
+		s.state.EmitConnectionEvent(connID, "migration:progress", types.MigrationProgress{
+			OperationID: operationID,
+			Database:    dbName,
+			Collection:  collName,
+			Processed:   result.Processed,
+			Modified:    result.Modified,
+			Total:       matched,
+		})
+	}
+	emitProgress()
+
+	err = s.batchedUpdate(ctx, coll, filter, update, func(processed, modified int64) {
+		result.Processed += processed
+		result.Modified += modified
+		emitProgress()
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			return result, err
+		}
+		result.Cancelled = true
+	}
+
+	debug.LogDocument(action+" finished", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"modified":    result.Modified,
+		"cancelled":   result.Cancelled,
+		"operationId": operationID,
+	})
+	return result, nil
+}
+
+// batchedUpdate applies update to the documents matching filter in _id order, one batch at a
+// time. Paging by _id rather than re-running the filter means documents the update leaves
+// matching (e.g. failed conversions) are visited only once.
+func (s *Service) batchedUpdate(ctx context.Context, coll *mongo.Collection, filter bson.M, update interface{}, onBatch func(processed, modified int64)) error {
+	var lastID interface{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pageFilter := filter
+		if lastID != nil {
+			pageFilter = andFilter(filter, bson.M{"_id": bson.M{"$gt": lastID}})
+		}
+		ids, err := s.nextIDBatch(ctx, coll, pageFilter)
+		if err != nil {
+			return fmt.Errorf("failed to read documents: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		lastID = ids[len(ids)-1]
+
+		batchCtx, batchCancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+		res, err := coll.UpdateMany(batchCtx, andFilter(filter, bson.M{"_id": bson.M{"$in": ids}}), update)
+		batchCancel()
+		if err != nil {
+			return fmt.Errorf("failed to update documents: %w", err)
+		}
+		onBatch(int64(len(ids)), res.ModifiedCount)
+	}
+}
+
+// nextIDBatch returns the _ids of up to migrationBatchSize matching documents in _id order.
+func (s *Service) nextIDBatch(ctx context.Context, coll *mongo.Collection, filter bson.M) ([]interface{}, error) {
+	findCtx, cancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+	defer cancel()
+
+	findOpts := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(migrationBatchSize)
+	cursor, err := coll.Find(findCtx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(findCtx)
+
+	var ids []interface{}
+	for cursor.Next(findCtx) {
+		ids = append(ids, cursor.Current.Lookup("_id"))
+	}
+	return ids, cursor.Err()
+}
+
+// andFilter combines a user filter with an extra condition.
+func andFilter(filter, extra bson.M) bson.M {
+	if len(filter) == 0 {
+		return extra
+	}
+	return bson.M{"$and": bson.A{filter, extra}}
+}
+
+// validateMigrationPath checks a dotted field path for a collection-wide migration.
+func validateMigrationPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("field path cannot be empty")
+	}
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" || strings.HasPrefix(segment, "$") {
+			return fmt.Errorf("invalid field path %q", path)
+		}
+	}
+	if path == "_id" || strings.HasPrefix(path, "_id.") {
+		return fmt.Errorf("cannot modify _id")
+	}
+	return nil
+}
+
+// MaxConversionFailures caps the failing documents listed by field type conversions.
+const MaxConversionFailures = 100
+
+// convertTargetTypes are the $convert target types supported by ConvertFieldType.
+var convertTargetTypes = map[string]bool{
+	"double": true, "string": true, "objectId": true, "bool": true,
+	"date": true, "int": true, "long": true, "decimal": true,
+}
+
+// convertSourceTypes are the $type aliases accepted as the type to convert from.
+var convertSourceTypes = map[string]bool{
+	"double": true, "string": true, "objectId": true, "bool": true, "date": true,
+	"int": true, "long": true, "decimal": true, "timestamp": true, "null": true,
+}
+
+// PreviewFieldConversion reports how many documents whose fieldPath holds a fromType value
+// can be converted to toType, with a sample of the values that can't.
+func (s *Service) PreviewFieldConversion(connID, dbName, collName, fieldPath, fromType, toType string) (*types.FieldConversionPreview, error) {
+	if err := validateConversion(fieldPath, fromType, toType); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+	return previewConversion(ctx, coll, fieldPath, fromType, toType)
+}
+
+// ConvertFieldType converts fieldPath from fromType to toType with $convert in every document
+// where it holds a fromType value. Values that can't be converted are left unchanged and
+// listed in the result. Progress is emitted as "migration:progress" events.
+func (s *Service) ConvertFieldType(connID, dbName, collName, fieldPath, fromType, toType string) (*types.FieldConversionResult, error) {
+	if err := s.state.CheckWritable(connID, "convert field type"); err != nil {
+		return nil, err
+	}
+
+	if err := validateConversion(fieldPath, fromType, toType); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	preview, err := previewConversion(ctx, coll, fieldPath, fromType, toType)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	// Failed conversions fall back to the original value, so those documents are untouched
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: fieldPath, Value: convertExpression(fieldPath, toType, "$"+fieldPath)},
+	}}}}
+	migration, err := s.runMigration(connID, dbName, collName, "Converting field type", conversionFilter(fieldPath, fromType), update, false, map[string]interface{}{
+		"field": fieldPath,
+		"from":  fromType,
+		"to":    toType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.FieldConversionResult{
+		MigrationResult: *migration,
+		Failed:          preview.NotConvertible,
+		Failures:        preview.Failures,
+	}, nil
+}
+
+// previewConversion counts convertible and non-convertible values with a single aggregation.
+func previewConversion(ctx context.Context, coll *mongo.Collection, fieldPath, fromType, toType string) (*types.FieldConversionPreview, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: conversionFilter(fieldPath, fromType)}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "value", Value: "$" + fieldPath},
+			{Key: "ok", Value: bson.D{{Key: "$ne", Value: bson.A{
+				bson.D{{Key: "$type", Value: convertExpression(fieldPath, toType, "$$REMOVE")}},
+				"missing",
+			}}}},
+		}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "counts", Value: bson.A{
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: "$ok"},
+					{Key: "n", Value: bson.D{{Key: "$sum", Value: 1}}},
+				}}},
+			}},
+			{Key: "failures", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "ok", Value: false}}}},
+				bson.D{{Key: "$limit", Value: MaxConversionFailures}},
+			}},
+		}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview conversion: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Counts []struct {
+			OK bool  `bson:"_id"`
+			N  int64 `bson:"n"`
+		} `bson:"counts"`
+		Failures []bson.Raw `bson:"failures"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, fmt.Errorf("failed to preview conversion: %w", err)
+	}
+
+	preview := &types.FieldConversionPreview{Failures: []types.ConversionFailure{}}
+	if len(facets) == 0 {
+		return preview, nil
+	}
+	for _, c := range facets[0].Counts {
+		if c.OK {
+			preview.Convertible = c.N
+		} else {
+			preview.NotConvertible = c.N
+		}
+	}
+	preview.Matched = preview.Convertible + preview.NotConvertible
+	for _, doc := range facets[0].Failures {
+		id, err := MarshalValue(doc.Lookup("_id"))
+		if err != nil {
+			return nil, err
+		}
+		value, err := MarshalValue(doc.Lookup("value"))
+		if err != nil {
+			return nil, err
+		}
+		preview.Failures = append(preview.Failures, types.ConversionFailure{ID: id, Value: value})
+	}
+	return preview, nil
+}
+
+// convertExpression builds a $convert of fieldPath to toType, yielding onError on failure.
+func convertExpression(fieldPath, toType string, onError interface{}) bson.D {
+	return bson.D{{Key: "$convert", Value: bson.D{
+		{Key: "input", Value: "$" + fieldPath},
+		{Key: "to", Value: toType},
+		{Key: "onError", Value: onError},
+	}}}
+}
+
+// conversionFilter matches documents where fieldPath holds a fromType value.
+func conversionFilter(fieldPath, fromType string) bson.M {
+	return bson.M{fieldPath: bson.M{"$type": fromType}}
+}
+
+func validateConversion(fieldPath, fromType, toType string) error {
+	if err := validateMigrationPath(fieldPath); err != nil {
+		return err
+	}
+	if !convertSourceTypes[fromType] {
+		return fmt.Errorf("unsupported source type %q", fromType)
+	}
+	if !convertTargetTypes[toType] {
+		return fmt.Errorf("unsupported target type %q", toType)
+	}
+	if fromType == toType {
+		return fmt.Errorf("field is already of type %q", toType)
+	}
+	return nil
+}
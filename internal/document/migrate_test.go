@@ -0,0 +1,102 @@
+package document
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestAndFilter(t *testing.T) {
+	extra := bson.M{"a": bson.M{"$exists": true}}
+	if got := andFilter(bson.M{}, extra); !reflect.DeepEqual(got, extra) {
+		t.Errorf("empty filter: got %v", got)
+	}
+	filter := bson.M{"status": "new"}
+	want := bson.M{"$and": bson.A{filter, extra}}
+	if got := andFilter(filter, extra); !reflect.DeepEqual(got, want) {
+		t.Errorf("with filter: got %v, want %v", got, want)
+	}
+}
+
+func TestRenameField_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name, oldPath, newPath, filter string
+	}{
+		{"empty old path", "", "b", ""},
+		{"empty new path", "a", "", ""},
+		{"operator segment", "a.$b", "c", ""},
+		{"empty segment", "a..b", "c", ""},
+		{"rename _id", "_id", "id", ""},
+		{"rename to _id", "id", "_id", ""},
+		{"same path", "a", "a", ""},
+		{"into itself", "a", "a.b", ""},
+		{"onto parent", "a.b", "a", ""},
+		{"invalid filter", "a", "b", `{"x":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.RenameField("conn-1", "db", "coll", tt.oldPath, tt.newPath, tt.filter, true); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	var notConnected *core.NotConnectedError
+	_, err := svc.RenameField("conn-1", "db", "coll", "a", "b", "", true)
+	if !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestValidateConversion(t *testing.T) {
+	tests := []struct {
+		field, from, to string
+		wantErr         bool
+	}{
+		{"price", "string", "double", false},
+		{"createdAt", "string", "date", false},
+		{"count", "double", "int", false},
+		{"a.b", "int", "string", false},
+		{"price", "string", "string", true},
+		{"price", "text", "double", true},
+		{"price", "string", "money", true},
+		{"_id", "string", "objectId", true},
+		{"", "string", "int", true},
+	}
+	for _, tt := range tests {
+		err := validateConversion(tt.field, tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateConversion(%q, %q, %q) error = %v, wantErr %v", tt.field, tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConvertExpression(t *testing.T) {
+	got := convertExpression("a.b", "int", "$$REMOVE")
+	want := bson.D{{Key: "$convert", Value: bson.D{
+		{Key: "input", Value: "$a.b"},
+		{Key: "to", Value: "int"},
+		{Key: "onError", Value: "$$REMOVE"},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("convertExpression() = %v, want %v", got, want)
+	}
+}
+
+func TestConvertFieldType_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	var notConnected *core.NotConnectedError
+	if _, err := svc.PreviewFieldConversion("conn-1", "db", "coll", "a", "string", "int"); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+	if _, err := svc.ConvertFieldType("conn-1", "db", "coll", "a", "string", "int"); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MissingFieldSampleSize is the number of _ids returned per kind of gap.
+const MissingFieldSampleSize = 10
+
+// Kinds of gaps FindMissingFields reports.
+const (
+	FieldMissing = "missing" // The field is absent
+	FieldNull    = "null"    // The field is present but null
+	FieldEmpty   = "empty"   // The field is an empty string
+)
+
+// missingFieldKinds lists the gap kinds in report order.
+var missingFieldKinds = []string{FieldMissing, FieldNull, FieldEmpty}
+
+// FindMissingFields counts the documents in which fieldPath is missing, null, or an empty
+// string, with a sample of _ids for each, in a single aggregation.
+func (s *Service) FindMissingFields(connID, dbName, collName, fieldPath string) (*types.MissingFieldsReport, error) {
+	fieldPath = strings.TrimSpace(fieldPath)
+	if fieldPath == "" {
+		return nil, fmt.Errorf("field name is required")
+	}
+	if strings.HasPrefix(fieldPath, "$") {
+		return nil, fmt.Errorf("invalid field name: %s", fieldPath)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	debug.LogQuery("Finding missing field values", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"field":      fieldPath,
+	})
+
+	coll := client.Database(dbName).Collection(collName)
+	cursor, err := coll.Aggregate(ctx, missingFieldPipeline(fieldPath), options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find missing fields: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []bson.Raw
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to find missing fields: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("failed to find missing fields: empty aggregation result")
+	}
+	return missingFieldReport(fieldPath, rows[0])
+}
+
+// missingFieldPipeline classifies every document by the state of the field, then counts
+// the states and samples _ids for each gap kind.
+func missingFieldPipeline(fieldPath string) mongo.Pipeline {
+	ref := "$" + fieldPath
+	state := bson.D{{Key: "$switch", Value: bson.D{
+		{Key: "branches", Value: bson.A{
+			bson.D{
+				{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: ref}}, "missing"}}}},
+				{Key: "then", Value: FieldMissing},
+			},
+			bson.D{
+				{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: ref}}, "null"}}}},
+				{Key: "then", Value: FieldNull},
+			},
+			bson.D{
+				{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{ref, ""}}}},
+				{Key: "then", Value: FieldEmpty},
+			},
+		}},
+		{Key: "default", Value: "present"},
+	}}}
+
+	facets := bson.D{{Key: "counts", Value: bson.A{
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$state"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}}}
+	for _, kind := range missingFieldKinds {
+		facets = append(facets, bson.E{Key: kind, Value: bson.A{
+			bson.D{{Key: "$match", Value: bson.D{{Key: "state", Value: kind}}}},
+			bson.D{{Key: "$limit", Value: MissingFieldSampleSize}},
+			bson.D{{Key: "$project", Value: bson.D{{Key: "_id", Value: 1}}}},
+		}})
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$project", Value: bson.D{{Key: "state", Value: state}}}},
+		{{Key: "$facet", Value: facets}},
+	}
+}
+
+// missingFieldReport converts the output document of missingFieldPipeline.
+func missingFieldReport(fieldPath string, row bson.Raw) (*types.MissingFieldsReport, error) {
+	var counts struct {
+		Counts []struct {
+			State string `bson:"_id"`
+			Count int64  `bson:"count"`
+		} `bson:"counts"`
+	}
+	if err := bson.Unmarshal(row, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode field counts: %w", err)
+	}
+	byState := map[string]int64{}
+	report := &types.MissingFieldsReport{Field: fieldPath, Gaps: make([]types.FieldGap, 0, len(missingFieldKinds))}
+	for _, c := range counts.Counts {
+		byState[c.State] = c.Count
+		report.Total += c.Count
+	}
+	report.Present = byState["present"]
+
+	for _, kind := range missingFieldKinds {
+		gap := types.FieldGap{Kind: kind, Count: byState[kind], SampleIDs: []string{}}
+		if report.Total > 0 {
+			gap.Percent = float64(gap.Count) * 100 / float64(report.Total)
+		}
+		samples, _ := row.Lookup(kind).ArrayOK()
+		values, _ := samples.Values()
+		for _, v := range values {
+			doc, ok := v.DocumentOK()
+			if !ok {
+				continue
+			}
+			id, err := MarshalValue(doc.Lookup("_id"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal document id: %w", err)
+			}
+			gap.SampleIDs = append(gap.SampleIDs, id)
+		}
+		report.Gaps = append(report.Gaps, gap)
+	}
+	return report, nil
+}
@@ -0,0 +1,84 @@
+package document
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestMissingFieldPipeline(t *testing.T) {
+	pipeline := missingFieldPipeline("address.city")
+	if len(pipeline) != 2 {
+		t.Fatalf("Expected 2 stages, got %d", len(pipeline))
+	}
+	facets := pipeline[1][0].Value.(bson.D)
+	if len(facets) != 1+len(missingFieldKinds) {
+		t.Fatalf("Expected %d facets, got %d", 1+len(missingFieldKinds), len(facets))
+	}
+	for i, kind := range missingFieldKinds {
+		if facets[i+1].Key != kind {
+			t.Errorf("Expected facet %q, got %q", kind, facets[i+1].Key)
+		}
+	}
+}
+
+func TestMissingFieldReport(t *testing.T) {
+	row, err := bson.Marshal(bson.D{
+		{Key: "counts", Value: bson.A{
+			bson.D{{Key: "_id", Value: "present"}, {Key: "count", Value: int32(6)}},
+			bson.D{{Key: "_id", Value: FieldMissing}, {Key: "count", Value: int32(3)}},
+			bson.D{{Key: "_id", Value: FieldEmpty}, {Key: "count", Value: int32(1)}},
+		}},
+		{Key: FieldMissing, Value: bson.A{
+			bson.D{{Key: "_id", Value: int32(1)}},
+			bson.D{{Key: "_id", Value: "two"}},
+		}},
+		{Key: FieldNull, Value: bson.A{}},
+		{Key: FieldEmpty, Value: bson.A{bson.D{{Key: "_id", Value: int32(9)}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := missingFieldReport("name", row)
+	if err != nil {
+		t.Fatalf("missingFieldReport failed: %v", err)
+	}
+	if report.Field != "name" || report.Total != 10 || report.Present != 6 {
+		t.Errorf("Unexpected totals %+v", report)
+	}
+	if len(report.Gaps) != 3 {
+		t.Fatalf("Expected 3 gaps, got %d", len(report.Gaps))
+	}
+	missing, null, empty := report.Gaps[0], report.Gaps[1], report.Gaps[2]
+	if missing.Kind != FieldMissing || missing.Count != 3 || missing.Percent != 30 {
+		t.Errorf("Unexpected missing gap %+v", missing)
+	}
+	if len(missing.SampleIDs) != 2 || missing.SampleIDs[0] != `{"$numberInt":"1"}` || missing.SampleIDs[1] != `"two"` {
+		t.Errorf("Unexpected missing samples %v", missing.SampleIDs)
+	}
+	if null.Count != 0 || len(null.SampleIDs) != 0 {
+		t.Errorf("Unexpected null gap %+v", null)
+	}
+	if empty.Count != 1 || len(empty.SampleIDs) != 1 {
+		t.Errorf("Unexpected empty gap %+v", empty)
+	}
+}
+
+func TestFindMissingFields_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	for _, field := range []string{"", "  ", "$name"} {
+		if _, err := svc.FindMissingFields("conn-1", "db", "coll", field); err == nil {
+			t.Errorf("Expected error for field %q", field)
+		}
+	}
+
+	var notConnected *core.NotConnectedError
+	if _, err := svc.FindMissingFields("conn-1", "db", "coll", "name"); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package document
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+)
+
+// Extended JSON output modes for query results.
+const (
+	OutputCanonical = "canonical" // Type-preserving: {"$numberLong": "5"}, {"$date": {"$numberLong": ...}}
+	OutputRelaxed   = "relaxed"   // Readable: plain numbers and ISO 8601 dates
+)
+
+// localDateLayout renders dates as ISO 8601 with millisecond precision and a UTC offset.
+const localDateLayout = "2006-01-02T15:04:05.000Z07:00"
+
+var (
+	canonicalDatePattern = regexp.MustCompile(`\{"\$date":\{"\$numberLong":"(-?\d+)"\}\}`)
+	relaxedDatePattern   = regexp.MustCompile(`\{"\$date":"([^"]+)"\}`)
+)
+
+// outputFormat controls how result documents are rendered.
+type outputFormat struct {
+	canonical  bool
+	localDates bool   // Render dates as ISO 8601 in the local timezone
+	legacyUUID string // Byte order to render subtype 3 UUIDs in; empty leaves them as binary
+}
+
+// resultFormat resolves the output format for a request. mode overrides the app setting
+// when set.
+func (s *Service) resultFormat(mode string) (outputFormat, error) {
+	settings := s.state.GetSettings()
+	if mode == "" {
+		mode = settings.OutputMode
+	}
+	switch mode {
+	case "", OutputCanonical:
+		return outputFormat{canonical: true, localDates: settings.LocalDates, legacyUUID: settings.LegacyUUIDRepresentation}, nil
+	case OutputRelaxed:
+		return outputFormat{canonical: false, localDates: settings.LocalDates, legacyUUID: settings.LegacyUUIDRepresentation}, nil
+	}
+	return outputFormat{}, fmt.Errorf("invalid output mode %q: expected %q or %q", mode, OutputCanonical, OutputRelaxed)
+}
+
+// marshalResult renders a result document in the given format. Relaxed output can lose
+// type information, so documents opened for editing always use MarshalDocument instead.
+func marshalResult(doc interface{}, f outputFormat) ([]byte, error) {
+	if f.canonical && !f.localDates && f.legacyUUID == "" {
+		return MarshalDocument(doc)
+	}
+	b, err := bson.MarshalExtJSON(doc, f.canonical, false)
+	if err != nil {
+		return nil, err
+	}
+	b = bsonutil.RenderUUIDs(b)
+	b = bsonutil.RenderLegacyUUIDs(b, f.legacyUUID)
+	if f.localDates {
+		b = localizeDates(b)
+	}
+	return b, nil
+}
+
+// localizeDates rewrites Extended JSON dates as {"$date": "<ISO 8601 local time>"}, the
+// relaxed form, which still parses back to the same instant.
+func localizeDates(extJSON []byte) []byte {
+	render := func(t time.Time) []byte {
+		return []byte(`{"$date":"` + t.Local().Format(localDateLayout) + `"}`)
+	}
+	extJSON = canonicalDatePattern.ReplaceAllFunc(extJSON, func(m []byte) []byte {
+		ms, err := strconv.ParseInt(string(canonicalDatePattern.FindSubmatch(m)[1]), 10, 64)
+		if err != nil {
+			return m
+		}
+		return render(time.UnixMilli(ms))
+	})
+	return relaxedDatePattern.ReplaceAllFunc(extJSON, func(m []byte) []byte {
+		t, err := time.Parse(time.RFC3339Nano, string(relaxedDatePattern.FindSubmatch(m)[1]))
+		if err != nil {
+			return m
+		}
+		return render(t)
+	})
+}
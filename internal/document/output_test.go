@@ -0,0 +1,93 @@
+package document
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestResultFormat(t *testing.T) {
+	state := core.NewAppState()
+	svc := NewService(state)
+
+	f, err := svc.resultFormat("")
+	if err != nil || !f.canonical || f.localDates {
+		t.Errorf("Expected canonical default, got %+v, %v", f, err)
+	}
+
+	state.SetSettings(types.AppSettings{OutputMode: OutputRelaxed, LocalDates: true})
+	f, _ = svc.resultFormat("")
+	if f.canonical || !f.localDates {
+		t.Errorf("Expected relaxed with local dates from settings, got %+v", f)
+	}
+	f, _ = svc.resultFormat(OutputCanonical)
+	if !f.canonical {
+		t.Error("Expected the request mode to override the setting")
+	}
+	if _, err := svc.resultFormat("pretty"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
+
+func TestMarshalResult(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	doc := bson.D{
+		{Key: "n", Value: int64(5)},
+		{Key: "at", Value: primitive.NewDateTimeFromTime(at)},
+	}
+
+	got, err := marshalResult(doc, outputFormat{canonical: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"n":{"$numberLong":"5"},"at":{"$date":{"$numberLong":"1704164645000"}}}` {
+		t.Errorf("Unexpected canonical output %s", got)
+	}
+
+	got, _ = marshalResult(doc, outputFormat{canonical: false})
+	if string(got) != `{"n":5,"at":{"$date":"2024-01-02T03:04:05Z"}}` {
+		t.Errorf("Unexpected relaxed output %s", got)
+	}
+
+	local := at.Local().Format(localDateLayout)
+	for _, canonical := range []bool{true, false} {
+		got, _ = marshalResult(doc, outputFormat{canonical: canonical, localDates: true})
+		var back struct {
+			At primitive.DateTime `bson:"at"`
+		}
+		if err := bson.UnmarshalExtJSON(got, false, &back); err != nil {
+			t.Fatalf("Local date output does not parse: %v (%s)", err, got)
+		}
+		if !back.At.Time().Equal(at) {
+			t.Errorf("Expected %v after round trip, got %v", at, back.At.Time())
+		}
+		if want := `"at":{"$date":"` + local + `"}`; !strings.Contains(string(got), want) {
+			t.Errorf("Expected %s in %s", want, got)
+		}
+	}
+}
+
+func TestMarshalResult_LegacyUUIDs(t *testing.T) {
+	const id = "00112233-4455-6677-8899-aabbccddeeff"
+	legacy, _ := bsonutil.UUIDToBinary(id, bsonutil.UUIDJavaLegacy)
+	doc := bson.D{{Key: "_id", Value: legacy}}
+
+	got, err := marshalResult(doc, outputFormat{canonical: true, legacyUUID: bsonutil.UUIDJavaLegacy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"_id":{"$uuid":"`+id+`","$uuidRepresentation":"javaLegacy"}}` {
+		t.Errorf("Unexpected legacy UUID output %s", got)
+	}
+	token := strings.TrimSuffix(strings.TrimPrefix(string(got), `{"_id":`), "}")
+	if back, ok := ParseDocumentID(token).(primitive.Binary); !ok || back.Subtype != 0x03 || string(back.Data) != string(legacy.Data) {
+		t.Errorf("Expected the rendered _id to parse back to the same binary, got %#v", ParseDocumentID(token))
+	}
+}
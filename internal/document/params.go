@@ -0,0 +1,244 @@
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Saved query parameter types.
+const (
+	ParamTypeString   = "string"
+	ParamTypeInt      = "int"
+	ParamTypeLong     = "long"
+	ParamTypeDouble   = "double"
+	ParamTypeBool     = "bool"
+	ParamTypeDate     = "date"
+	ParamTypeObjectID = "objectId"
+)
+
+// placeholderPattern matches {{name}} placeholders, allowing surrounding spaces.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// SubstituteParameters replaces {{name}} placeholders in a saved query with values from
+// valuesJSON (a JSON object), coerced to each parameter's declared BSON type. A placeholder
+// that is the whole of a JSON string ("{{name}}") or stands outside any string is replaced
+// by a typed Extended JSON literal; one embedded in a longer string is replaced by the
+// escaped text of the value. Values never get spliced in as raw JSON.
+func SubstituteParameters(query string, params []types.QueryParameter, valuesJSON string) (string, error) {
+	values := map[string]interface{}{}
+	if strings.TrimSpace(valuesJSON) != "" {
+		dec := json.NewDecoder(strings.NewReader(valuesJSON))
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return "", fmt.Errorf("invalid parameter values: %w", err)
+		}
+	}
+
+	defs := make(map[string]types.QueryParameter, len(params))
+	for _, p := range params {
+		if _, dup := defs[p.Name]; dup {
+			return "", fmt.Errorf("duplicate parameter %q", p.Name)
+		}
+		defs[p.Name] = p
+	}
+
+	// Resolve each placeholder once
+	resolved := map[string]interface{}{}
+	resolve := func(name string) (interface{}, error) {
+		if v, ok := resolved[name]; ok {
+			return v, nil
+		}
+		def, hasDef := defs[name]
+		raw, supplied := values[name]
+		if !supplied {
+			if !hasDef || def.Default == "" {
+				return nil, fmt.Errorf("missing value for parameter %q", name)
+			}
+			raw = def.Default
+		}
+		v, err := coerceParameter(raw, def.Type)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %q: %w", name, err)
+		}
+		resolved[name] = v
+		return v, nil
+	}
+
+	var out bytes.Buffer
+	inString := false
+	stringStart := -1 // Output length just after the current string's opening quote
+	for i := 0; i < len(query); {
+		c := query[i]
+		if inString && c == '\\' && i+1 < len(query) {
+			out.WriteString(query[i : i+2])
+			i += 2
+			continue
+		}
+		var loc []int
+		if strings.HasPrefix(query[i:], "{{") {
+			loc = placeholderPattern.FindStringSubmatchIndex(query[i:])
+		}
+		if loc == nil || loc[0] != 0 {
+			if c == '"' {
+				inString = !inString
+				stringStart = out.Len() + 1
+			}
+			out.WriteByte(c)
+			i++
+			continue
+		}
+
+		name := query[i+loc[2] : i+loc[3]]
+		end := i + loc[1]
+		v, err := resolve(name)
+		if err != nil {
+			return "", err
+		}
+
+		// "{{name}}" as a whole string: drop the quotes and emit a typed literal
+		wholeString := inString && out.Len() == stringStart && end < len(query) && query[end] == '"'
+		switch {
+		case wholeString:
+			out.Truncate(out.Len() - 1)
+			lit, err := MarshalValue(v)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(lit)
+			inString = false
+			end++
+		case inString:
+			text, err := json.Marshal(parameterText(v))
+			if err != nil {
+				return "", err
+			}
+			out.Write(text[1 : len(text)-1])
+		default:
+			lit, err := MarshalValue(v)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(lit)
+		}
+		i = end
+	}
+
+	result := out.String()
+	var check bson.D
+	if err := bson.UnmarshalExtJSON([]byte(result), true, &check); err != nil {
+		return "", fmt.Errorf("query is invalid after substituting parameters: %w", err)
+	}
+	return result, nil
+}
+
+// coerceParameter converts a JSON value (or a default string) to the Go value for a BSON type.
+// An empty type infers string, number, bool, or null from the JSON value.
+func coerceParameter(raw interface{}, paramType string) (interface{}, error) {
+	text := parameterText(raw)
+
+	switch paramType {
+	case "":
+		switch v := raw.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				return n, nil
+			}
+			return v.Float64()
+		case string, bool, nil:
+			return v, nil
+		}
+		return nil, fmt.Errorf("unsupported value %v", raw)
+
+	case ParamTypeString:
+		if raw == nil {
+			return nil, fmt.Errorf("expected a string, got null")
+		}
+		return text, nil
+
+	case ParamTypeInt, ParamTypeLong:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", text)
+		}
+		if paramType == ParamTypeInt {
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				return nil, fmt.Errorf("%d is out of range for int", n)
+			}
+			return int32(n), nil
+		}
+		return n, nil
+
+	case ParamTypeDouble:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", text)
+		}
+		return f, nil
+
+	case ParamTypeBool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", text)
+		}
+		return b, nil
+
+	case ParamTypeDate:
+		if n, ok := raw.(json.Number); ok {
+			ms, err := n.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("expected milliseconds since epoch, got %s", n)
+			}
+			return primitive.NewDateTimeFromTime(time.UnixMilli(ms)), nil
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, text); err == nil {
+				return primitive.NewDateTimeFromTime(t), nil
+			}
+		}
+		return nil, fmt.Errorf("expected an ISO-8601 date, got %q", text)
+
+	case ParamTypeObjectID:
+		oid, err := primitive.ObjectIDFromHex(text)
+		if err != nil {
+			return nil, fmt.Errorf("expected a 24-character hex ObjectId, got %q", text)
+		}
+		return oid, nil
+	}
+
+	return nil, fmt.Errorf("unknown parameter type %q", paramType)
+}
+
+// parameterText returns the textual form of a parameter value for string contexts.
+func parameterText(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	case int32:
+		return strconv.FormatInt(int64(val), 10)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case primitive.DateTime:
+		return val.Time().UTC().Format(time.RFC3339Nano)
+	case primitive.ObjectID:
+		return val.Hex()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,101 @@
+package document
+
+import (
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestSubstituteParameters(t *testing.T) {
+	params := []types.QueryParameter{
+		{Name: "status", Type: ParamTypeString},
+		{Name: "minAge", Type: ParamTypeInt, Default: "18"},
+		{Name: "total", Type: ParamTypeLong},
+		{Name: "since", Type: ParamTypeDate},
+		{Name: "id", Type: ParamTypeObjectID},
+		{Name: "active", Type: ParamTypeBool},
+		{Name: "ratio", Type: ParamTypeDouble},
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		values string
+		want   string
+	}{
+		{
+			name:   "quoted placeholder becomes typed literal",
+			query:  `{"age": {"$gte": "{{minAge}}"}, "status": "{{ status }}"}`,
+			values: `{"status": "paid"}`,
+			want:   `{"age": {"$gte": {"$numberInt":"18"}}, "status": "paid"}`,
+		},
+		{
+			name:   "bare placeholder",
+			query:  `{"total": {{total}}, "active": {{active}}, "ratio": {{ratio}}}`,
+			values: `{"total": "9007199254740993", "active": "true", "ratio": 0.5}`,
+			want:   `{"total": {"$numberLong":"9007199254740993"}, "active": true, "ratio": {"$numberDouble":"0.5"}}`,
+		},
+		{
+			name:   "date and objectId",
+			query:  `{"_id": "{{id}}", "at": {"$gte": "{{since}}"}}`,
+			values: `{"id": "507f1f77bcf86cd799439011", "since": "2024-01-02T00:00:00Z"}`,
+			want:   `{"_id": {"$oid":"507f1f77bcf86cd799439011"}, "at": {"$gte": {"$date":{"$numberLong":"1704153600000"}}}}`,
+		},
+		{
+			name:   "embedded in string is escaped",
+			query:  `{"note": "status is {{status}}!"}`,
+			values: `{"status": "\"}, \"$where\": \"1"}`,
+			want:   `{"note": "status is \"}, \"$where\": \"1!"}`,
+		},
+		{
+			name:   "undeclared parameter infers type",
+			query:  `{"n": "{{n}}", "s": "{{s}}"}`,
+			values: `{"n": 42, "s": "x"}`,
+			want:   `{"n": {"$numberLong":"42"}, "s": "x"}`,
+		},
+		{
+			name:   "escaped quote before placeholder",
+			query:  `{"q": "\"{{status}}\""}`,
+			values: `{"status": "a"}`,
+			want:   `{"q": "\"a\""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SubstituteParameters(tt.query, params, tt.values)
+			if err != nil {
+				t.Fatalf("SubstituteParameters failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstituteParameters_Errors(t *testing.T) {
+	params := []types.QueryParameter{
+		{Name: "age", Type: ParamTypeInt},
+		{Name: "id", Type: ParamTypeObjectID},
+		{Name: "kind", Type: "uuid"},
+	}
+
+	tests := map[string]struct {
+		query  string
+		values string
+	}{
+		"missing value":       {`{"age": "{{age}}"}`, `{}`},
+		"not an integer":      {`{"age": "{{age}}"}`, `{"age": "ten"}`},
+		"int out of range":    {`{"age": "{{age}}"}`, `{"age": 3000000000}`},
+		"bad objectId":        {`{"_id": "{{id}}"}`, `{"id": "nope"}`},
+		"unknown type":        {`{"k": "{{kind}}"}`, `{"kind": "x"}`},
+		"invalid values json": {`{"age": "{{age}}"}`, `{"age":`},
+		"invalid result":      {`{"age": {{age}`, `{"age": 1}`},
+	}
+	for name, tt := range tests {
+		if got, err := SubstituteParameters(tt.query, params, tt.values); err == nil {
+			t.Errorf("%s: expected error, got %s", name, got)
+		}
+	}
+}
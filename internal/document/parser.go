@@ -3,23 +3,40 @@ package document
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/types"
 )
 
 // ParseDocumentID converts a document ID string to the appropriate BSON type.
-// Accepts: Extended JSON, ObjectID hex string, or plain string.
+// Accepts: the _id as canonical Extended JSON (as rendered by MarshalValue, so every BSON
+// type round-trips, including composite _ids, whose field order is kept), a quoted JSON
+// string, shell helpers such as UUID("..."), ObjectID hex string, or plain string.
 func ParseDocumentID(docID string) interface{} {
 	// Try to parse as Extended JSON first (handles Binary, UUID, ObjectId, $numberLong, etc.)
-	if strings.HasPrefix(docID, "{") {
-		// Wrap in a document to properly parse Extended JSON types like $numberLong
-		// bson.UnmarshalExtJSON into interface{} doesn't convert EJSON types, but bson.M does
-		wrapped := fmt.Sprintf(`{"_id": %s}`, docID)
-		var doc bson.M
-		if err := bson.UnmarshalExtJSON([]byte(wrapped), true, &doc); err == nil {
-			return doc["_id"]
+	if strings.HasPrefix(docID, "{") || strings.HasPrefix(docID, `"`) {
+		if id, ok := parseExtJSONID(docID); ok {
+			return id
+		}
+	}
+
+	// Shell helpers: UUID("..."), CSUUID("..."), ObjectId("..."), NumberLong(...), ...
+	if strings.HasSuffix(docID, ")") {
+		if normalized, err := bsonutil.NormalizeExtJSON(docID); err == nil {
+			if id, ok := parseExtJSONID(normalized); ok {
+				return id
+			}
 		}
 	}
 
@@ -32,10 +49,209 @@ func ParseDocumentID(docID string) interface{} {
 	return docID
 }
 
+// ParseCursorToken parses a keyset pagination token (an _id rendered as Extended JSON,
+// as returned in QueryResult.NextCursor). Falls back to ParseDocumentID for hand-typed IDs.
+func ParseCursorToken(token string) interface{} {
+	if id, ok := parseExtJSONID(token); ok {
+		return id
+	}
+	return ParseDocumentID(token)
+}
+
+// parseExtJSONID parses a single Extended JSON value. Top-level values can't be unmarshaled
+// directly, so the value is wrapped in a document; decoding into bson.D keeps the field
+// order of embedded documents, which a composite _id must match exactly. Legacy UUIDs in the
+// form RenderLegacyUUIDs writes are accepted.
+func parseExtJSONID(value string) (interface{}, bool) {
+	var doc bson.D
+	data := bsonutil.RestoreLegacyUUIDs([]byte(`{"_id": ` + value + `}`))
+	if err := bson.UnmarshalExtJSON(data, true, &doc); err != nil || len(doc) != 1 {
+		return nil, false
+	}
+	return doc[0].Value, true
+}
+
+// MarshalDocument renders a result document as canonical Extended JSON, with UUIDs shown
+// as {"$uuid": "..."}.
+func MarshalDocument(doc interface{}) ([]byte, error) {
+	b, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil {
+		return nil, err
+	}
+	return bsonutil.RenderUUIDs(b), nil
+}
+
+// MarshalValue renders a single BSON value as canonical Extended JSON.
+func MarshalValue(v interface{}) (string, error) {
+	// Extended JSON marshaling needs a top-level document, so wrap and strip
+	b, err := MarshalDocument(bson.D{{Key: "v", Value: v}})
+	if err != nil {
+		return "", err
+	}
+	out := strings.TrimSpace(string(b))
+	out = strings.TrimPrefix(out, `{"v":`)
+	out = strings.TrimSuffix(out, "}")
+	return strings.TrimSpace(out), nil
+}
+
+// ParseCollation validates a query collation and converts it to driver options.
+// Returns nil for a nil collation.
+func ParseCollation(c *types.Collation) (*options.Collation, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if strings.TrimSpace(c.Locale) == "" {
+		return nil, fmt.Errorf("collation locale is required")
+	}
+	if c.Strength < 0 || c.Strength > 5 {
+		return nil, fmt.Errorf("collation strength must be between 1 and 5")
+	}
+	return &options.Collation{
+		Locale:    c.Locale,
+		Strength:  c.Strength,
+		CaseLevel: c.CaseLevel,
+	}, nil
+}
+
+// ParseReadOptions converts a read preference mode (e.g. "secondaryPreferred") and read concern
+// level (e.g. "majority") into collection options. Empty values keep the connection's defaults;
+// returns nil if both are empty.
+func ParseReadOptions(readPreference, readConcern string) (*options.CollectionOptions, error) {
+	if readPreference == "" && readConcern == "" {
+		return nil, nil
+	}
+	collOpts := options.Collection()
+	if readPreference != "" {
+		mode, err := readpref.ModeFromString(readPreference)
+		if err != nil {
+			return nil, err
+		}
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read preference: %w", err)
+		}
+		collOpts.SetReadPreference(rp)
+	}
+	switch readConcern {
+	case "":
+	case "local", "available", "majority", "linearizable", "snapshot":
+		collOpts.SetReadConcern(&readconcern.ReadConcern{Level: readConcern})
+	default:
+		return nil, fmt.Errorf("unknown read concern %q", readConcern)
+	}
+	return collOpts, nil
+}
+
+// ParseWriteConcern converts a requested write concern into driver form. Returns nil, which
+// keeps the connection's write concern, for a nil wc. Unacknowledged writes (w: 0) are
+// rejected since the result of the write could not be reported.
+func ParseWriteConcern(wc *types.WriteConcern) (*writeconcern.WriteConcern, error) {
+	if wc == nil {
+		return nil, nil
+	}
+	if wc.WTimeoutMS < 0 {
+		return nil, fmt.Errorf("wtimeout cannot be negative")
+	}
+	out := &writeconcern.WriteConcern{WTimeout: time.Duration(wc.WTimeoutMS) * time.Millisecond}
+	if wc.J {
+		j := true
+		out.Journal = &j
+	}
+
+	// JSON numbers arrive as float64
+	switch w := wc.W.(type) {
+	case nil:
+	case float64:
+		if w != float64(int(w)) || w < 1 {
+			return nil, fmt.Errorf("w must be a positive whole number, \"majority\", or a tag set name")
+		}
+		out.W = int(w)
+	case int:
+		if w < 1 {
+			return nil, fmt.Errorf("w must be a positive whole number, \"majority\", or a tag set name")
+		}
+		out.W = w
+	case string:
+		if n, err := strconv.Atoi(w); err == nil {
+			if n < 1 {
+				return nil, fmt.Errorf("w must be a positive whole number, \"majority\", or a tag set name")
+			}
+			out.W = n
+		} else if w != "" {
+			out.W = w
+		}
+	default:
+		return nil, fmt.Errorf("invalid w: %v", wc.W)
+	}
+	return out, nil
+}
+
+// writeCollection returns a collection handle whose writes use the requested write concern.
+func writeCollection(client *mongo.Client, dbName, collName string, wc *types.WriteConcern) (*mongo.Collection, error) {
+	concern, err := ParseWriteConcern(wc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write concern: %w", err)
+	}
+	return client.Database(dbName).Collection(collName, options.Collection().SetWriteConcern(concern)), nil
+}
+
+// ParseFilter parses a query filter in Extended JSON. An empty string or "{}" matches all documents.
+func ParseFilter(query string) (bson.M, error) {
+	if query == "" || query == "{}" {
+		return bson.M{}, nil
+	}
+	var filter bson.M
+	if err := bsonutil.UnmarshalExtJSON([]byte(query), true, &filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// ParseSort converts the simple sort format ("field" ascending, "-field" descending,
+// comma-separated) into an ordered sort document. Returns nil for an empty spec.
+func ParseSort(sort string) bson.D {
+	if strings.TrimSpace(sort) == "" {
+		return nil
+	}
+	sortDoc := bson.D{}
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if strings.HasPrefix(field, "-") {
+			sortDoc = append(sortDoc, bson.E{Key: field[1:], Value: -1})
+		} else {
+			sortDoc = append(sortDoc, bson.E{Key: field, Value: 1})
+		}
+	}
+	return sortDoc
+}
+
+// ParsePipeline parses an aggregation pipeline given as an Extended JSON array of stages.
+// An empty string yields an empty pipeline.
+func ParsePipeline(pipelineJSON string) (mongo.Pipeline, error) {
+	if strings.TrimSpace(pipelineJSON) == "" {
+		return mongo.Pipeline{}, nil
+	}
+	// Top-level arrays can't be unmarshaled directly, so wrap in a document
+	var wrapper struct {
+		Pipeline mongo.Pipeline `bson:"pipeline"`
+	}
+	wrapped := fmt.Sprintf(`{"pipeline": %s}`, pipelineJSON)
+	if err := bsonutil.UnmarshalExtJSON([]byte(wrapped), true, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid pipeline: %w", err)
+	}
+	if wrapper.Pipeline == nil {
+		return mongo.Pipeline{}, nil
+	}
+	return wrapper.Pipeline, nil
+}
+
 // ValidateJSON validates JSON/Extended JSON syntax.
 func ValidateJSON(jsonStr string) error {
 	var doc bson.M
-	if err := bson.UnmarshalExtJSON([]byte(jsonStr), true, &doc); err != nil {
+	if err := bsonutil.UnmarshalExtJSON([]byte(jsonStr), true, &doc); err != nil {
 		// Try standard JSON
 		if err2 := json.Unmarshal([]byte(jsonStr), &doc); err2 != nil {
 			return fmt.Errorf("invalid JSON: %w", err)
@@ -0,0 +1,319 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name string
+		sort string
+		want bson.D
+	}{
+		{name: "empty", sort: "", want: nil},
+		{name: "whitespace only", sort: "   ", want: nil},
+		{name: "single ascending", sort: "name", want: bson.D{{Key: "name", Value: 1}}},
+		{name: "single descending", sort: "-createdAt", want: bson.D{{Key: "createdAt", Value: -1}}},
+		{
+			name: "compound keeps order",
+			sort: "-age, name",
+			want: bson.D{{Key: "age", Value: -1}, {Key: "name", Value: 1}},
+		},
+		{
+			name: "skips empty segments",
+			sort: "a,,b,",
+			want: bson.D{{Key: "a", Value: 1}, {Key: "b", Value: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseSort(tt.sort)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSort(%q) = %v, want %v", tt.sort, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePipeline(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		got, err := ParsePipeline("  ")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("Expected empty non-nil pipeline, got %v", got)
+		}
+	})
+
+	t.Run("stages keep order", func(t *testing.T) {
+		got, err := ParsePipeline(`[{"$match": {"n": {"$numberLong": "5"}}}, {"$limit": 10}]`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("Expected 2 stages, got %d", len(got))
+		}
+		if got[0][0].Key != "$match" || got[1][0].Key != "$limit" {
+			t.Errorf("Unexpected stage order: %v", got)
+		}
+		match := got[0][0].Value.(bson.D)
+		if v, ok := match[0].Value.(int64); !ok || v != 5 {
+			t.Errorf("Expected Extended JSON $numberLong to decode to int64 5, got %#v", match[0].Value)
+		}
+	})
+
+	t.Run("shell syntax", func(t *testing.T) {
+		got, err := ParsePipeline(`[{$match: {at: {$gte: ISODate("2024-01-01")}}}, {$limit: 10},]`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("Expected 2 stages, got %d", len(got))
+		}
+		at := got[0][0].Value.(bson.D)[0].Value.(bson.D)
+		if _, ok := at[0].Value.(primitive.DateTime); !ok {
+			t.Errorf("Expected ISODate to decode to a date, got %#v", at[0].Value)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, input := range []string{`{"$match": {}}`, `[{"$match": }]`, `not json`} {
+			if _, err := ParsePipeline(input); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
+
+func TestParseDocumentID_ShellHelpers(t *testing.T) {
+	id, ok := ParseDocumentID(`UUID("00112233-4455-6677-8899-aabbccddeeff")`).(primitive.Binary)
+	if !ok || id.Subtype != 0x04 || len(id.Data) != 16 || id.Data[0] != 0x00 {
+		t.Errorf("Expected subtype 4 UUID, got %#v", id)
+	}
+	id, ok = ParseDocumentID(`CSUUID("00112233-4455-6677-8899-aabbccddeeff")`).(primitive.Binary)
+	if !ok || id.Subtype != 0x03 || id.Data[0] != 0x33 {
+		t.Errorf("Expected subtype 3 C# UUID, got %#v", id)
+	}
+	if s, ok := ParseDocumentID(`(not a helper)`).(string); !ok || s != "(not a helper)" {
+		t.Errorf("Expected string fallback, got %#v", s)
+	}
+
+	// Rendered UUIDs round-trip as document IDs
+	rendered, err := MarshalValue(primitive.Binary{Subtype: 0x04, Data: id.Data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered != `{"$uuid":"33221100-5544-7766-8899-aabbccddeeff"}` {
+		t.Errorf("Expected $uuid rendering, got %s", rendered)
+	}
+	if back, ok := ParseDocumentID(rendered).(primitive.Binary); !ok || back.Subtype != 0x04 || string(back.Data) != string(id.Data) {
+		t.Errorf("Expected rendered UUID to parse back, got %#v", back)
+	}
+}
+
+func TestFormatUUID(t *testing.T) {
+	got, err := FormatUUID(`{"$binary": {"base64": "MyIRAFVEd2aImaq7zN3u/w==", "subType": "03"}}`, "csharpLegacy")
+	if err != nil || got != "00112233-4455-6677-8899-aabbccddeeff" {
+		t.Errorf("FormatUUID = %q, %v", got, err)
+	}
+	ext, err := UUIDToExtJSON("00112233-4455-6677-8899-aabbccddeeff", "csharpLegacy")
+	if err != nil || ext != `{"$binary":{"base64":"MyIRAFVEd2aImaq7zN3u/w==","subType":"03"}}` {
+		t.Errorf("UUIDToExtJSON = %q, %v", ext, err)
+	}
+	if _, err := FormatUUID(`"text"`, ""); err == nil {
+		t.Error("Expected error for non-binary value")
+	}
+}
+
+func TestParseDocumentID_RoundTrip(t *testing.T) {
+	at := primitive.NewDateTimeFromTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	dec, _ := primitive.ParseDecimal128("12.50")
+	ids := []interface{}{
+		primitive.NewObjectID(),
+		"plain",
+		"507f1f77bcf86cd799439011", // A string that looks like an ObjectId
+		`{"not": "json"}`,
+		int32(7),
+		int64(1 << 40),
+		3.5,
+		dec,
+		at,
+		primitive.Binary{Subtype: 0x04, Data: make([]byte, 16)},
+		primitive.Binary{Subtype: 0x00, Data: []byte{1, 2, 3}},
+		primitive.Timestamp{T: 1700000000, I: 1},
+		bson.D{{Key: "tenant", Value: "a"}, {Key: "seq", Value: int32(1)}},
+		bson.D{{Key: "z", Value: int32(1)}, {Key: "a", Value: bson.D{{Key: "y", Value: "x"}, {Key: "b", Value: at}}}},
+		bson.D{{Key: "order", Value: int64(5)}, {Key: "line", Value: bson.A{int32(1), "x"}}},
+	}
+
+	for _, id := range ids {
+		encoded, err := MarshalValue(id)
+		if err != nil {
+			t.Fatalf("MarshalValue(%v) failed: %v", id, err)
+		}
+		if got := ParseDocumentID(encoded); !reflect.DeepEqual(got, id) {
+			t.Errorf("ParseDocumentID(%s) = %#v, want %#v", encoded, got, id)
+		}
+	}
+}
+
+func TestParseDocumentID_CompositeKeepsOrder(t *testing.T) {
+	got, ok := ParseDocumentID(`{"tenant": "acme", "region": "eu", "seq": {"$numberLong": "9"}}`).(bson.D)
+	if !ok {
+		t.Fatalf("Expected bson.D, got %T", got)
+	}
+	want := bson.D{{Key: "tenant", Value: "acme"}, {Key: "region", Value: "eu"}, {Key: "seq", Value: int64(9)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v, want %#v", got, want)
+	}
+}
+
+func TestFormatInsertedID(t *testing.T) {
+	oid := primitive.NewObjectID()
+	composite := bson.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: "x"}}
+	for _, id := range []interface{}{oid, "user-1", int64(42), composite} {
+		formatted := formatInsertedID(id)
+		if got := ParseDocumentID(formatted); !reflect.DeepEqual(got, id) {
+			t.Errorf("formatInsertedID(%#v) = %s, parses back as %#v", id, formatted, got)
+		}
+	}
+	if got := formatInsertedID(oid); got != oid.Hex() {
+		t.Errorf("Expected hex for ObjectId, got %s", got)
+	}
+}
+
+func TestCursorTokenRoundTrip(t *testing.T) {
+	oid := primitive.NewObjectID()
+	ids := []interface{}{
+		oid,
+		"user-42",
+		int32(7),
+		int64(1 << 40),
+		bson.D{{Key: "tenant", Value: "a"}, {Key: "seq", Value: int32(1)}},
+	}
+
+	for _, id := range ids {
+		token, err := MarshalValue(id)
+		if err != nil {
+			t.Fatalf("MarshalValue(%v) failed: %v", id, err)
+		}
+		got := ParseCursorToken(token)
+		// Embedded documents keep their field order
+		if !reflect.DeepEqual(got, id) {
+			t.Errorf("Token %s: got %#v, want %#v", token, got, id)
+		}
+	}
+}
+
+func TestParseCursorToken_HexFallback(t *testing.T) {
+	oid := primitive.NewObjectID()
+	if got := ParseCursorToken(oid.Hex()); got != oid {
+		t.Errorf("Expected bare hex to parse as ObjectID, got %#v", got)
+	}
+}
+
+func TestParseCollation(t *testing.T) {
+	if c, err := ParseCollation(nil); c != nil || err != nil {
+		t.Errorf("ParseCollation(nil) = %v, %v; want nil, nil", c, err)
+	}
+
+	c, err := ParseCollation(&types.Collation{Locale: "de", Strength: 2, CaseLevel: true})
+	if err != nil {
+		t.Fatalf("ParseCollation failed: %v", err)
+	}
+	if c.Locale != "de" || c.Strength != 2 || !c.CaseLevel {
+		t.Errorf("unexpected collation %+v", c)
+	}
+
+	for _, bad := range []types.Collation{{Locale: ""}, {Locale: "en", Strength: 6}, {Locale: "en", Strength: -1}} {
+		if _, err := ParseCollation(&bad); err == nil {
+			t.Errorf("ParseCollation(%+v) expected error", bad)
+		}
+	}
+}
+
+func TestParseReadOptions(t *testing.T) {
+	if opts, err := ParseReadOptions("", ""); opts != nil || err != nil {
+		t.Errorf("ParseReadOptions(\"\", \"\") = %v, %v; want nil, nil", opts, err)
+	}
+
+	opts, err := ParseReadOptions("secondaryPreferred", "majority")
+	if err != nil {
+		t.Fatalf("ParseReadOptions failed: %v", err)
+	}
+	if opts.ReadPreference == nil || opts.ReadPreference.Mode().String() != "secondaryPreferred" {
+		t.Errorf("unexpected read preference %v", opts.ReadPreference)
+	}
+	if opts.ReadConcern == nil || opts.ReadConcern.Level != "majority" {
+		t.Errorf("unexpected read concern %v", opts.ReadConcern)
+	}
+
+	if _, err := ParseReadOptions("secondaryish", ""); err == nil {
+		t.Error("expected error for unknown read preference")
+	}
+	if _, err := ParseReadOptions("", "strong"); err == nil {
+		t.Error("expected error for unknown read concern")
+	}
+}
+
+func TestParseWriteConcern(t *testing.T) {
+	if wc, err := ParseWriteConcern(nil); wc != nil || err != nil {
+		t.Errorf("ParseWriteConcern(nil) = %v, %v; want nil, nil", wc, err)
+	}
+
+	tests := []struct {
+		name    string
+		in      types.WriteConcern
+		wantW   interface{}
+		wantErr bool
+	}{
+		{name: "majority", in: types.WriteConcern{W: "majority"}, wantW: "majority"},
+		{name: "json number", in: types.WriteConcern{W: float64(2)}, wantW: 2},
+		{name: "numeric string", in: types.WriteConcern{W: "3"}, wantW: 3},
+		{name: "tag set", in: types.WriteConcern{W: "dc-east"}, wantW: "dc-east"},
+		{name: "journal only", in: types.WriteConcern{J: true}, wantW: nil},
+		{name: "unacknowledged", in: types.WriteConcern{W: float64(0)}, wantErr: true},
+		{name: "unacknowledged string", in: types.WriteConcern{W: "0"}, wantErr: true},
+		{name: "fractional", in: types.WriteConcern{W: 1.5}, wantErr: true},
+		{name: "negative timeout", in: types.WriteConcern{W: "majority", WTimeoutMS: -1}, wantErr: true},
+		{name: "wrong type", in: types.WriteConcern{W: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wc, err := ParseWriteConcern(&tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", wc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWriteConcern failed: %v", err)
+			}
+			if wc.W != tt.wantW {
+				t.Errorf("W = %v, want %v", wc.W, tt.wantW)
+			}
+			if tt.in.J && (wc.Journal == nil || !*wc.Journal) {
+				t.Error("expected journal to be required")
+			}
+		})
+	}
+
+	wc, err := ParseWriteConcern(&types.WriteConcern{W: "majority", J: true, WTimeoutMS: 5000})
+	if err != nil {
+		t.Fatalf("ParseWriteConcern failed: %v", err)
+	}
+	if wc.WTimeout != 5*time.Second {
+		t.Errorf("WTimeout = %v, want 5s", wc.WTimeout)
+	}
+}
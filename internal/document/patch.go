@@ -0,0 +1,170 @@
+package document
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// PatchDocument updates only the given paths of a document instead of replacing it, so
+// concurrent edits to other fields are preserved. patchJSON is an Extended JSON object with
+// "$set" (dotted path to new value) and/or "$unset" (an object or array of dotted paths).
+// With upsert set, a missing document is created from docID and the $set fields.
+// A nil writeConcern keeps the connection's write concern.
+func (s *Service) PatchDocument(connID, dbName, collName, docID, patchJSON string, upsert bool, writeConcern *types.WriteConcern) error {
+	if err := s.state.CheckWritable(connID, "update"); err != nil {
+		return err
+	}
+
+	update, err := buildPatchUpdate(patchJSON)
+	if err != nil {
+		return err
+	}
+
+	debug.LogDocument("Patching document", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"documentId": docID,
+	})
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	coll, err := writeCollection(client, dbName, collName, writeConcern)
+	if err != nil {
+		return err
+	}
+	result, err := coll.UpdateOne(ctx, bson.M{"_id": ParseDocumentID(docID)}, update, options.Update().SetUpsert(upsert))
+	if err != nil {
+		debug.LogDocument("Patch failed", map[string]interface{}{
+			"database":   dbName,
+			"collection": collName,
+			"documentId": docID,
+			"error":      err.Error(),
+		})
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return fmt.Errorf("document not found")
+	}
+
+	debug.LogDocument("Document patched", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"documentId": docID,
+		"modified":   result.ModifiedCount,
+		"upserted":   result.UpsertedCount,
+	})
+	return nil
+}
+
+// buildPatchUpdate parses and validates a patch into an update document.
+func buildPatchUpdate(patchJSON string) (bson.D, error) {
+	var patch bson.M
+	if err := bsonutil.UnmarshalExtJSON([]byte(patchJSON), false, &patch); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	set := bson.M{}
+	var unset []string
+	for key, value := range patch {
+		switch key {
+		case "$set":
+			doc, ok := value.(bson.M)
+			if !ok {
+				return nil, fmt.Errorf("$set must be an object")
+			}
+			set = doc
+		case "$unset":
+			switch v := value.(type) {
+			case bson.M:
+				for path := range v {
+					unset = append(unset, path)
+				}
+			case bson.A:
+				for _, item := range v {
+					path, ok := item.(string)
+					if !ok {
+						return nil, fmt.Errorf("$unset paths must be strings")
+					}
+					unset = append(unset, path)
+				}
+			default:
+				return nil, fmt.Errorf("$unset must be an object or array of paths")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported patch key %q (only $set and $unset are allowed)", key)
+		}
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return nil, fmt.Errorf("patch has no changes")
+	}
+
+	paths := make([]string, 0, len(set)+len(unset))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	paths = append(paths, unset...)
+	if err := validatePatchPaths(paths); err != nil {
+		return nil, err
+	}
+
+	update := bson.D{}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(unset) > 0 {
+		unsetDoc := bson.D{}
+		sort.Strings(unset)
+		for _, path := range unset {
+			unsetDoc = append(unsetDoc, bson.E{Key: path, Value: ""})
+		}
+		update = append(update, bson.E{Key: "$unset", Value: unsetDoc})
+	}
+	return update, nil
+}
+
+// validatePatchPaths rejects empty or operator paths, changes to _id, and paths that
+// overlap (one is a prefix of another), which the server would reject as conflicting.
+func validatePatchPaths(paths []string) error {
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			return fmt.Errorf("patch path cannot be empty")
+		}
+		for _, segment := range strings.Split(path, ".") {
+			if segment == "" || strings.HasPrefix(segment, "$") {
+				return fmt.Errorf("invalid patch path %q", path)
+			}
+		}
+		if path == "_id" || strings.HasPrefix(path, "_id.") {
+			return fmt.Errorf("cannot modify _id")
+		}
+		if seen[path] {
+			return fmt.Errorf("conflicting patch paths %q and %q", path, path)
+		}
+		seen[path] = true
+	}
+	// Checking every parent path against the set catches prefixes that sorting would
+	// separate, such as "a" from "a.c" by "a-b"
+	for _, path := range paths {
+		for i := 0; i < len(path); i++ {
+			if path[i] == '.' && seen[path[:i]] {
+				return fmt.Errorf("conflicting patch paths %q and %q", path[:i], path)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package document
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBuildPatchUpdate(t *testing.T) {
+	update, err := buildPatchUpdate(`{"$set": {"name": "Ada", "address.city": "London"}, "$unset": ["legacy", "tmp"]}`)
+	if err != nil {
+		t.Fatalf("buildPatchUpdate failed: %v", err)
+	}
+	if len(update) != 2 || update[0].Key != "$set" || update[1].Key != "$unset" {
+		t.Fatalf("unexpected update %v", update)
+	}
+	set := update[0].Value.(bson.M)
+	if set["name"] != "Ada" || set["address.city"] != "London" {
+		t.Errorf("unexpected $set %v", set)
+	}
+	unset := update[1].Value.(bson.D)
+	if len(unset) != 2 || unset[0].Key != "legacy" || unset[1].Key != "tmp" {
+		t.Errorf("unexpected $unset %v", unset)
+	}
+
+	// $unset may also be an object, and either operator alone is fine
+	update, err = buildPatchUpdate(`{"$unset": {"a": ""}}`)
+	if err != nil || len(update) != 1 || update[0].Key != "$unset" {
+		t.Errorf("object $unset: got %v, %v", update, err)
+	}
+
+	// Extended JSON values keep their types
+	update, err = buildPatchUpdate(`{"$set": {"n": {"$numberLong": "5"}}}`)
+	if err != nil {
+		t.Fatalf("buildPatchUpdate failed: %v", err)
+	}
+	if _, ok := update[0].Value.(bson.M)["n"].(int64); !ok {
+		t.Errorf("expected int64 value, got %T", update[0].Value.(bson.M)["n"])
+	}
+}
+
+func TestBuildPatchUpdate_Errors(t *testing.T) {
+	tests := map[string]string{
+		"invalid json":        `{"$set":`,
+		"empty":               `{}`,
+		"empty set":           `{"$set": {}}`,
+		"replace style":       `{"name": "Ada"}`,
+		"other operator":      `{"$inc": {"n": 1}}`,
+		"set not object":      `{"$set": 1}`,
+		"unset non-string":    `{"$unset": [1]}`,
+		"modifies _id":        `{"$set": {"_id": 2}}`,
+		"modifies _id subkey": `{"$set": {"_id.a": 2}}`,
+		"operator segment":    `{"$set": {"a.$b": 1}}`,
+		"empty segment":       `{"$set": {"a..b": 1}}`,
+		"set and unset same":  `{"$set": {"a": 1}, "$unset": ["a"]}`,
+		"overlapping paths":   `{"$set": {"a": {}, "a.b": 1}}`,
+		"nonadjacent overlap": `{"$set": {"a": {}, "a-b": 1, "a.c": 2}}`,
+	}
+	for name, patch := range tests {
+		if _, err := buildPatchUpdate(patch); err == nil {
+			t.Errorf("%s: expected error for %s", name, patch)
+		}
+	}
+}
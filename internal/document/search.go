@@ -0,0 +1,267 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Search modes for SearchOptions.Mode.
+const (
+	SearchModeText  = "text"
+	SearchModeAtlas = "atlas"
+)
+
+// DefaultSearchLimit is the number of hits returned when no limit is given.
+const DefaultSearchLimit = 50
+
+// MaxSearchLimit caps the number of hits returned in one search.
+const MaxSearchLimit = 1000
+
+// searchScoreField temporarily holds the relevance score on each result document.
+const searchScoreField = "__mongopalScore"
+
+// SearchDocuments runs a full-text search using either a $text query (standard text index)
+// or a $search stage (Atlas Search) and returns hits ordered by score. With an empty mode,
+// Atlas Search is preferred when available, falling back to the text index.
+func (s *Service) SearchDocuments(connID, dbName, collName, text string, opts types.SearchOptions) (*types.SearchResult, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("search text is required")
+	}
+	switch opts.Mode {
+	case "", SearchModeText, SearchModeAtlas:
+	default:
+		return nil, fmt.Errorf("invalid search mode: %s", opts.Mode)
+	}
+
+	filter, err := ParseFilter(opts.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultSearchLimit
+	}
+	if opts.Limit > MaxSearchLimit {
+		opts.Limit = MaxSearchLimit
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithMaxTime(opts.MaxTimeMS)
+	defer cancel()
+
+	coll := client.Database(dbName).Collection(collName)
+
+	mode := opts.Mode
+	if mode == "" {
+		caps, err := DetectSearchCapabilities(ctx, coll)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case caps.HasAtlasSearch:
+			mode = SearchModeAtlas
+			if opts.Index == "" && len(caps.AtlasSearchIndexes) > 0 {
+				opts.Index = caps.AtlasSearchIndexes[0]
+			}
+		case caps.HasTextIndex:
+			mode = SearchModeText
+		default:
+			return nil, fmt.Errorf("collection %s has no text or Atlas Search index", collName)
+		}
+	}
+
+	var pipeline mongo.Pipeline
+	if mode == SearchModeAtlas {
+		pipeline = buildAtlasSearchPipeline(text, filter, opts.Index, opts.Paths, opts.Limit)
+	} else {
+		pipeline = buildTextSearchPipeline(text, filter, opts.Language, opts.Limit)
+	}
+
+	debug.LogQuery("Executing search", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"mode":       mode,
+		"text":       text,
+	})
+
+	startTime := time.Now()
+	cursor, err := coll.Aggregate(ctx, pipeline, options.Aggregate().SetMaxTime(s.state.QueryMaxTime(opts.MaxTimeMS)))
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	format, _ := s.resultFormat("")
+	result := &types.SearchResult{
+		Mode:    mode,
+		Results: []types.ScoredDocument{},
+	}
+	for cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		doc, score := extractSearchScore(doc)
+		jsonBytes, err := marshalResult(doc, format)
+		if err != nil {
+			continue
+		}
+		result.Results = append(result.Results, types.ScoredDocument{Document: string(jsonBytes), Score: score})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	result.QueryTimeMs = time.Since(startTime).Milliseconds()
+
+	return result, nil
+}
+
+// DetectSearchCapabilities reports whether a collection has a text index and/or Atlas Search
+// indexes. Failure to list Atlas Search indexes (e.g. on non-Atlas deployments) is not an error.
+func DetectSearchCapabilities(ctx context.Context, coll *mongo.Collection) (types.SearchCapabilities, error) {
+	var caps types.SearchCapabilities
+
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return caps, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var idx bson.M
+		if err := cursor.Decode(&idx); err != nil {
+			continue
+		}
+		if !isTextIndex(idx) {
+			continue
+		}
+		caps.HasTextIndex = true
+		caps.TextIndexName, _ = idx["name"].(string)
+		if weights, ok := idx["weights"].(bson.M); ok {
+			for field := range weights {
+				caps.TextFields = append(caps.TextFields, field)
+			}
+			sort.Strings(caps.TextFields)
+		}
+	}
+
+	if searchCursor, err := coll.SearchIndexes().List(ctx, nil); err == nil {
+		defer searchCursor.Close(ctx)
+		for searchCursor.Next(ctx) {
+			var idx bson.M
+			if err := searchCursor.Decode(&idx); err != nil {
+				continue
+			}
+			// Vector search indexes can't serve $search text queries
+			if t, _ := idx["type"].(string); t != "" && t != "search" {
+				continue
+			}
+			if name, ok := idx["name"].(string); ok {
+				caps.AtlasSearchIndexes = append(caps.AtlasSearchIndexes, name)
+			}
+		}
+		caps.HasAtlasSearch = len(caps.AtlasSearchIndexes) > 0
+	}
+
+	return caps, nil
+}
+
+// isTextIndex reports whether an index spec from listIndexes is a text index.
+func isTextIndex(idx bson.M) bool {
+	key, ok := idx["key"].(bson.M)
+	if !ok {
+		return false
+	}
+	for _, v := range key {
+		if v == "text" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildTextSearchPipeline builds a $text aggregation ordered by text score.
+func buildTextSearchPipeline(text string, filter bson.M, language string, limit int64) mongo.Pipeline {
+	textQuery := bson.D{{Key: "$search", Value: text}}
+	if language != "" {
+		textQuery = append(textQuery, bson.E{Key: "$language", Value: language})
+	}
+
+	// $text must be in the first $match stage
+	match := bson.D{{Key: "$text", Value: textQuery}}
+	if len(filter) > 0 {
+		match = bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "$text", Value: textQuery}}, filter}}}
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.D{{Key: searchScoreField, Value: bson.D{{Key: "$meta", Value: "textScore"}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: searchScoreField, Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+}
+
+// buildAtlasSearchPipeline builds a $search aggregation. Results from $search are already
+// ordered by relevance.
+func buildAtlasSearchPipeline(text string, filter bson.M, index string, paths []string, limit int64) mongo.Pipeline {
+	if index == "" {
+		index = "default"
+	}
+	var path interface{} = bson.D{{Key: "wildcard", Value: "*"}}
+	if len(paths) == 1 {
+		path = paths[0]
+	} else if len(paths) > 1 {
+		path = paths
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$search", Value: bson.D{
+			{Key: "index", Value: index},
+			{Key: "text", Value: bson.D{
+				{Key: "query", Value: text},
+				{Key: "path", Value: path},
+			}},
+		}}},
+	}
+	if len(filter) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: filter}})
+	}
+	return append(pipeline,
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$addFields", Value: bson.D{{Key: searchScoreField, Value: bson.D{{Key: "$meta", Value: "searchScore"}}}}}},
+	)
+}
+
+// extractSearchScore removes the score field added by the search pipeline and returns it.
+func extractSearchScore(doc bson.D) (bson.D, float64) {
+	for i, e := range doc {
+		if e.Key != searchScoreField {
+			continue
+		}
+		var score float64
+		switch v := e.Value.(type) {
+		case float64:
+			score = v
+		case int32:
+			score = float64(v)
+		case int64:
+			score = float64(v)
+		}
+		return append(doc[:i:i], doc[i+1:]...), score
+	}
+	return doc, 0
+}
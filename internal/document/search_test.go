@@ -0,0 +1,107 @@
+package document
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func stageNames(p []bson.D) []string {
+	names := make([]string, len(p))
+	for i, stage := range p {
+		names[i] = stage[0].Key
+	}
+	return names
+}
+
+func TestBuildTextSearchPipeline(t *testing.T) {
+	p := buildTextSearchPipeline("coffee", bson.M{}, "", 20)
+	got := stageNames(p)
+	want := []string{"$match", "$addFields", "$sort", "$limit"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected stages %v, got %v", want, got)
+	}
+	match := p[0][0].Value.(bson.D)
+	if match[0].Key != "$text" {
+		t.Errorf("Expected $text as the first match key, got %s", match[0].Key)
+	}
+
+	// Extra filter and language are combined with $and
+	p = buildTextSearchPipeline("café", bson.M{"active": true}, "french", 20)
+	match = p[0][0].Value.(bson.D)
+	if match[0].Key != "$and" {
+		t.Fatalf("Expected $and when filter is set, got %s", match[0].Key)
+	}
+	textQuery := match[0].Value.(bson.A)[0].(bson.D)[0].Value.(bson.D)
+	if len(textQuery) != 2 || textQuery[1].Key != "$language" || textQuery[1].Value != "french" {
+		t.Errorf("Expected $language override, got %v", textQuery)
+	}
+}
+
+func TestBuildAtlasSearchPipeline(t *testing.T) {
+	p := buildAtlasSearchPipeline("coffee", bson.M{}, "", nil, 10)
+	got := stageNames(p)
+	want := []string{"$search", "$limit", "$addFields"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected stages %v, got %v", want, got)
+	}
+	search := p[0][0].Value.(bson.D)
+	if search[0].Value != "default" {
+		t.Errorf("Expected default index name, got %v", search[0].Value)
+	}
+	path := search[1].Value.(bson.D)[1].Value
+	if _, ok := path.(bson.D); !ok {
+		t.Errorf("Expected wildcard path when no paths given, got %#v", path)
+	}
+
+	p = buildAtlasSearchPipeline("coffee", bson.M{"active": true}, "products", []string{"name", "desc"}, 10)
+	if got := stageNames(p); got[1] != "$match" {
+		t.Errorf("Expected $match after $search, got %v", got)
+	}
+	search = p[0][0].Value.(bson.D)
+	if paths, ok := search[1].Value.(bson.D)[1].Value.([]string); !ok || len(paths) != 2 {
+		t.Errorf("Expected explicit path list, got %#v", search[1].Value)
+	}
+}
+
+func TestExtractSearchScore(t *testing.T) {
+	doc := bson.D{
+		{Key: "_id", Value: 1},
+		{Key: searchScoreField, Value: 2.5},
+		{Key: "name", Value: "x"},
+	}
+	rest, score := extractSearchScore(doc)
+	if score != 2.5 {
+		t.Errorf("Expected score 2.5, got %v", score)
+	}
+	if len(rest) != 2 || rest[0].Key != "_id" || rest[1].Key != "name" {
+		t.Errorf("Expected score field removed, got %v", rest)
+	}
+
+	if _, score := extractSearchScore(bson.D{{Key: "a", Value: 1}}); score != 0 {
+		t.Errorf("Expected 0 score without score field, got %v", score)
+	}
+}
+
+func TestIsTextIndex(t *testing.T) {
+	if !isTextIndex(bson.M{"key": bson.M{"_fts": "text", "_ftsx": int32(1)}}) {
+		t.Error("Expected text index to be detected")
+	}
+	if isTextIndex(bson.M{"key": bson.M{"name": int32(1)}}) {
+		t.Error("Expected regular index not to be detected as text")
+	}
+}
+
+func TestSearchDocuments_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.SearchDocuments("conn", "db", "coll", "  ", types.SearchOptions{}); err == nil {
+		t.Error("Expected error for empty search text")
+	}
+	if _, err := svc.SearchDocuments("conn", "db", "coll", "x", types.SearchOptions{Mode: "fuzzy"}); err == nil {
+		t.Error("Expected error for invalid mode")
+	}
+}
@@ -0,0 +1,164 @@
+package document
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MaxBSONDocumentSize is the server's document size limit (16MB).
+const MaxBSONDocumentSize = 16 * 1024 * 1024
+
+// MaxSizeReportDocuments caps the number of largest documents AnalyzeDocumentSizes returns.
+const MaxSizeReportDocuments = 1000
+
+// sizeBucketBoundaries are the lower bounds of the size histogram buckets. The last bucket
+// is open-ended so documents at or over the 16MB limit get a bucket of their own.
+var sizeBucketBoundaries = []int64{0, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, MaxBSONDocumentSize}
+
+// AnalyzeDocumentSizes measures every document with $bsonSize (MongoDB 4.4+) and returns the
+// topN largest, overall size statistics, and a size distribution histogram.
+func (s *Service) AnalyzeDocumentSizes(connID, dbName, collName string, topN int) (*types.DocumentSizeReport, error) {
+	if topN <= 0 {
+		topN = 20
+	}
+	if topN > MaxSizeReportDocuments {
+		topN = MaxSizeReportDocuments
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	debug.LogQuery("Analyzing document sizes", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"topN":       topN,
+	})
+
+	coll := client.Database(dbName).Collection(collName)
+	cursor, err := coll.Aggregate(ctx, documentSizePipeline(topN), options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze document sizes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []sizeFacets
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to analyze document sizes: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("failed to analyze document sizes: empty aggregation result")
+	}
+	return rows[0].toReport()
+}
+
+// documentSizePipeline computes every facet of the size report in a single collection scan.
+func documentSizePipeline(topN int) mongo.Pipeline {
+	boundaries := make(bson.A, 0, len(sizeBucketBoundaries)+1)
+	for _, b := range sizeBucketBoundaries {
+		boundaries = append(boundaries, b)
+	}
+	// $bucket needs an upper bound; no document can reach twice the limit
+	boundaries = append(boundaries, int64(2*MaxBSONDocumentSize))
+
+	return mongo.Pipeline{
+		{{Key: "$project", Value: bson.D{{Key: "size", Value: bson.D{{Key: "$bsonSize", Value: "$$ROOT"}}}}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "stats", Value: bson.A{
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: nil},
+					{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+					{Key: "total", Value: bson.D{{Key: "$sum", Value: "$size"}}},
+					{Key: "avg", Value: bson.D{{Key: "$avg", Value: "$size"}}},
+					{Key: "min", Value: bson.D{{Key: "$min", Value: "$size"}}},
+					{Key: "max", Value: bson.D{{Key: "$max", Value: "$size"}}},
+				}}},
+			}},
+			{Key: "largest", Value: bson.A{
+				bson.D{{Key: "$sort", Value: bson.D{{Key: "size", Value: -1}}}},
+				bson.D{{Key: "$limit", Value: topN}},
+			}},
+			{Key: "histogram", Value: bson.A{
+				bson.D{{Key: "$bucket", Value: bson.D{
+					{Key: "groupBy", Value: "$size"},
+					{Key: "boundaries", Value: boundaries},
+					{Key: "output", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}},
+				}}},
+			}},
+		}}},
+	}
+}
+
+// sizeFacets is the single output document of documentSizePipeline.
+type sizeFacets struct {
+	Stats []struct {
+		Count int64   `bson:"count"`
+		Total int64   `bson:"total"`
+		Avg   float64 `bson:"avg"`
+		Min   int64   `bson:"min"`
+		Max   int64   `bson:"max"`
+	} `bson:"stats"`
+	Largest []struct {
+		ID   interface{} `bson:"_id"`
+		Size int64       `bson:"size"`
+	} `bson:"largest"`
+	Histogram []struct {
+		Lower int64 `bson:"_id"`
+		Count int64 `bson:"count"`
+	} `bson:"histogram"`
+}
+
+func (f sizeFacets) toReport() (*types.DocumentSizeReport, error) {
+	report := &types.DocumentSizeReport{
+		Largest:    make([]types.DocumentSize, 0, len(f.Largest)),
+		Histogram:  make([]types.SizeBucket, len(sizeBucketBoundaries)),
+		LimitBytes: MaxBSONDocumentSize,
+	}
+	if len(f.Stats) > 0 {
+		st := f.Stats[0]
+		report.Count = st.Count
+		report.TotalBytes = st.Total
+		report.AvgBytes = int64(st.Avg)
+		report.MinBytes = st.Min
+		report.MaxBytes = st.Max
+	}
+
+	for _, d := range f.Largest {
+		id, err := MarshalValue(d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal document id: %w", err)
+		}
+		report.Largest = append(report.Largest, types.DocumentSize{
+			ID:             id,
+			Bytes:          d.Size,
+			PercentOfLimit: float64(d.Size) * 100 / MaxBSONDocumentSize,
+		})
+	}
+
+	// $bucket omits empty buckets, so lay out every bucket and fill in the counts
+	for i, lower := range sizeBucketBoundaries {
+		report.Histogram[i].MinBytes = lower
+		if i+1 < len(sizeBucketBoundaries) {
+			report.Histogram[i].MaxBytes = sizeBucketBoundaries[i+1]
+		}
+	}
+	for _, b := range f.Histogram {
+		for i := range report.Histogram {
+			if report.Histogram[i].MinBytes == b.Lower {
+				report.Histogram[i].Count = b.Count
+				break
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,91 @@
+package document
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestDocumentSizePipeline(t *testing.T) {
+	pipeline := documentSizePipeline(5)
+	if len(pipeline) != 2 {
+		t.Fatalf("Expected 2 stages, got %d", len(pipeline))
+	}
+	facets := pipeline[1][0].Value.(bson.D)
+	largest := facets[1].Value.(bson.A)
+	if limit := largest[1].(bson.D)[0].Value; limit != 5 {
+		t.Errorf("Expected $limit 5, got %v", limit)
+	}
+	bucket := facets[2].Value.(bson.A)[0].(bson.D)[0].Value.(bson.D)
+	boundaries := bucket[1].Value.(bson.A)
+	if len(boundaries) != len(sizeBucketBoundaries)+1 {
+		t.Errorf("Expected %d boundaries, got %d", len(sizeBucketBoundaries)+1, len(boundaries))
+	}
+}
+
+func TestSizeFacets_ToReport(t *testing.T) {
+	raw, err := bson.Marshal(bson.D{
+		{Key: "stats", Value: bson.A{bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "count", Value: int32(3)},
+			{Key: "total", Value: int64(MaxBSONDocumentSize + 2100)},
+			{Key: "avg", Value: float64(MaxBSONDocumentSize+2100) / 3},
+			{Key: "min", Value: int32(100)},
+			{Key: "max", Value: int32(MaxBSONDocumentSize)},
+		}}},
+		{Key: "largest", Value: bson.A{
+			bson.D{{Key: "_id", Value: "big"}, {Key: "size", Value: int32(MaxBSONDocumentSize)}},
+			bson.D{{Key: "_id", Value: int32(2)}, {Key: "size", Value: int32(2000)}},
+		}},
+		{Key: "histogram", Value: bson.A{
+			bson.D{{Key: "_id", Value: int64(0)}, {Key: "count", Value: int32(1)}},
+			bson.D{{Key: "_id", Value: int64(1 << 10)}, {Key: "count", Value: int32(1)}},
+			bson.D{{Key: "_id", Value: int64(MaxBSONDocumentSize)}, {Key: "count", Value: int32(1)}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f sizeFacets
+	if err := bson.Unmarshal(raw, &f); err != nil {
+		t.Fatalf("Failed to decode facets: %v", err)
+	}
+
+	report, err := f.toReport()
+	if err != nil {
+		t.Fatalf("toReport failed: %v", err)
+	}
+	if report.Count != 3 || report.MinBytes != 100 || report.MaxBytes != MaxBSONDocumentSize {
+		t.Errorf("Unexpected stats %+v", report)
+	}
+	if len(report.Largest) != 2 || report.Largest[0].ID != `"big"` || report.Largest[0].PercentOfLimit != 100 {
+		t.Errorf("Unexpected largest %+v", report.Largest)
+	}
+	if report.Largest[1].ID != `{"$numberInt":"2"}` {
+		t.Errorf("Unexpected id %s", report.Largest[1].ID)
+	}
+	if len(report.Histogram) != len(sizeBucketBoundaries) {
+		t.Fatalf("Expected every bucket, got %d", len(report.Histogram))
+	}
+	counts := []int64{1, 1, 0, 0, 0, 0, 0, 0, 1}
+	for i, b := range report.Histogram {
+		if b.Count != counts[i] {
+			t.Errorf("Bucket %d: expected count %d, got %d", i, counts[i], b.Count)
+		}
+	}
+	if last := report.Histogram[len(report.Histogram)-1]; last.MinBytes != MaxBSONDocumentSize || last.MaxBytes != 0 {
+		t.Errorf("Expected open-ended last bucket, got %+v", last)
+	}
+}
+
+func TestAnalyzeDocumentSizes_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	var notConnected *core.NotConnectedError
+	if _, err := svc.AnalyzeDocumentSizes("conn-1", "db", "coll", 10); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// DefaultStreamBatchSize is the number of documents per "query:batch" event when none is given.
+const DefaultStreamBatchSize = 500
+
+// MaxStreamBatchSize caps the number of documents per "query:batch" event.
+const MaxStreamBatchSize = 10000
+
+// StreamDocuments runs a query in the background and streams the results as
+// "query:batch" events of up to batchSize documents, followed by a single "query:done"
+// event. Unlike FindDocuments, a zero limit streams every matching document.
+// Returns the query ID, which can be passed to CancelQuery.
+func (s *Service) StreamDocuments(connID, dbName, collName, query string, opts types.QueryOptions, batchSize int) (string, error) {
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return "", err
+	}
+
+	filter, err := ParseFilter(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid query: %w", err)
+	}
+	format, err := s.resultFormat(opts.OutputMode)
+	if err != nil {
+		return "", err
+	}
+
+	if batchSize <= 0 {
+		batchSize = DefaultStreamBatchSize
+	}
+	if batchSize > MaxStreamBatchSize {
+		batchSize = MaxStreamBatchSize
+	}
+	if opts.Skip < 0 {
+		opts.Skip = 0
+	}
+	if opts.Limit < 0 {
+		opts.Limit = 0
+	}
+
+	findOpts := options.Find().
+		SetSkip(opts.Skip).
+		SetLimit(opts.Limit).
+		SetBatchSize(int32(batchSize))
+	// Streams are open-ended, so only an explicit MaxTimeMS limits them
+	if opts.MaxTimeMS > 0 {
+		findOpts.SetMaxTime(s.state.QueryMaxTime(opts.MaxTimeMS))
+	}
+	if err := applyFindOptions(findOpts, opts); err != nil {
+		return "", err
+	}
+	collOpts, err := ParseReadOptions(opts.ReadPreference, opts.ReadConcern)
+	if err != nil {
+		return "", err
+	}
+
+	queryID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.state.SetQueryCancel(queryID, cancel)
+
+	debug.LogQuery("Starting streaming query", map[string]interface{}{
+		"queryId":    queryID,
+		"database":   dbName,
+		"collection": collName,
+		"query":      query,
+		"batchSize":  batchSize,
+	})
+
+	coll := client.Database(dbName).Collection(collName, collOpts)
+	go func() {
+		defer cancel()
+		defer s.state.ClearQueryCancel(queryID)
+		done := s.streamCursor(ctx, connID, queryID, coll, filter, findOpts, batchSize, format)
+		s.state.EmitConnectionEvent(connID, "query:done", done)
+	}()
+
+	return queryID, nil
+}
+
+// CancelQuery stops a streaming query started by StreamDocuments, or a bulk delete
+// started by DeleteManyDocuments.
+func (s *Service) CancelQuery(queryID string) error {
+	if !s.state.CancelQuery(queryID) {
+		return fmt.Errorf("query not running: %s", queryID)
+	}
+	return nil
+}
+
+// streamCursor iterates the query cursor, emitting batches until it is exhausted,
+// fails, or the context is cancelled.
+func (s *Service) streamCursor(ctx context.Context, connID, queryID string, coll *mongo.Collection, filter bson.M, findOpts *options.FindOptions, batchSize int, format outputFormat) types.QueryDone {
+	startTime := time.Now()
+	done := types.QueryDone{QueryID: queryID}
+
+	finish := func(err error) types.QueryDone {
+		done.QueryTimeMs = time.Since(startTime).Milliseconds()
+		if ctx.Err() == context.Canceled {
+			done.Cancelled = true
+		} else if err != nil {
+			done.Error = err.Error()
+		}
+		debug.LogQuery("Streaming query finished", map[string]interface{}{
+			"queryId":     queryID,
+			"docCount":    done.Count,
+			"cancelled":   done.Cancelled,
+			"queryTimeMs": done.QueryTimeMs,
+		})
+		return done
+	}
+
+	cursor, err := coll.Find(ctx, filter, findOpts)
+	if err != nil {
+		return finish(fmt.Errorf("failed to find documents: %w", err))
+	}
+	defer cursor.Close(context.Background())
+
+	batch := make([]string, 0, batchSize)
+	batchIndex := 0
+	var decodeErrors, marshalErrors int
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.state.EmitConnectionEvent(connID, "query:batch", types.QueryBatch{
+			QueryID:    queryID,
+			BatchIndex: batchIndex,
+			Documents:  batch,
+		})
+		batchIndex++
+		batch = make([]string, 0, batchSize)
+	}
+
+	for cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			decodeErrors++
+			continue
+		}
+		jsonBytes, err := marshalResult(doc, format)
+		if err != nil {
+			marshalErrors++
+			continue
+		}
+		batch = append(batch, string(jsonBytes))
+		done.Count++
+		if len(batch) >= batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	if decodeErrors > 0 {
+		done.Warnings = append(done.Warnings, fmt.Sprintf("%d document(s) failed to decode", decodeErrors))
+	}
+	if marshalErrors > 0 {
+		done.Warnings = append(done.Warnings, fmt.Sprintf("%d document(s) failed to marshal to JSON", marshalErrors))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return finish(fmt.Errorf("failed to read documents: %w", err))
+	}
+	return finish(nil)
+}
@@ -0,0 +1,243 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// BeginTransaction starts a multi-document transaction and returns a session token for
+// the *InTransaction methods, CommitTransaction, and AbortTransaction. Transactions need a
+// replica set or sharded cluster; the server aborts one that stays open longer than its
+// transactionLifetimeLimitSeconds (60s by default). Disconnecting aborts open transactions.
+func (s *Service) BeginTransaction(connID string) (string, error) {
+	if err := s.state.CheckWritable(connID, "transaction"); err != nil {
+		return "", err
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	var hello bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return "", fmt.Errorf("failed to check server topology: %w", err)
+	}
+	if bsonutil.ToString(hello["setName"]) == "" && bsonutil.ToString(hello["msg"]) != "isdbgrid" {
+		return "", fmt.Errorf("transactions require a replica set or sharded cluster")
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return "", fmt.Errorf("failed to start session: %w", err)
+	}
+	if err := session.StartTransaction(); err != nil {
+		session.EndSession(ctx)
+		return "", fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	txn := &core.Transaction{
+		Token:   uuid.New().String(),
+		ConnID:  connID,
+		Session: session,
+		Started: time.Now(),
+	}
+	s.state.AddTransaction(txn)
+
+	debug.LogDocument("Transaction started", map[string]interface{}{
+		"connectionId": connID,
+		"token":        txn.Token,
+	})
+
+	return txn.Token, nil
+}
+
+// InsertInTransaction inserts a document as part of an open transaction and returns its
+// _id in the same form as InsertDocument.
+func (s *Service) InsertInTransaction(token, dbName, collName, jsonDoc string) (string, error) {
+	var doc bson.D
+	if err := bsonutil.UnmarshalExtJSON([]byte(jsonDoc), true, &doc); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var insertedID interface{}
+	err := s.inTransaction(token, dbName, func(ctx context.Context, client *mongo.Client, txn *core.Transaction) error {
+		result, err := client.Database(dbName).Collection(collName).InsertOne(ctx, doc)
+		if err != nil {
+			return fmt.Errorf("failed to insert document: %w", err)
+		}
+		insertedID = result.InsertedID
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return formatInsertedID(insertedID), nil
+}
+
+// UpdateInTransaction replaces a document as part of an open transaction.
+// docID is used when the document itself has no _id.
+func (s *Service) UpdateInTransaction(token, dbName, collName, docID, jsonDoc string) error {
+	var doc bson.D
+	if err := bsonutil.UnmarshalExtJSON([]byte(jsonDoc), true, &doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	filter := bson.M{"_id": ParseDocumentID(docID)}
+	if id, ok := lookupID(doc); ok {
+		filter = bson.M{"_id": id}
+	}
+
+	return s.inTransaction(token, dbName, func(ctx context.Context, client *mongo.Client, txn *core.Transaction) error {
+		result, err := client.Database(dbName).Collection(collName).ReplaceOne(ctx, filter, doc)
+		if err != nil {
+			return fmt.Errorf("failed to update document: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			return fmt.Errorf("document not found")
+		}
+		return nil
+	})
+}
+
+// DeleteInTransaction deletes a document as part of an open transaction. With trash
+// enabled, the document is archived only once the transaction commits.
+func (s *Service) DeleteInTransaction(token, dbName, collName, docID string) error {
+	filter := bson.M{"_id": ParseDocumentID(docID)}
+
+	return s.inTransaction(token, dbName, func(ctx context.Context, client *mongo.Client, txn *core.Transaction) error {
+		coll := client.Database(dbName).Collection(collName)
+		var archived bson.Raw
+		if s.archiver != nil {
+			doc, err := coll.FindOne(ctx, filter).Raw()
+			if err == mongo.ErrNoDocuments {
+				return fmt.Errorf("document not found")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read document: %w", err)
+			}
+			archived = doc
+		}
+
+		result, err := coll.DeleteOne(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to delete document: %w", err)
+		}
+		if result.DeletedCount == 0 {
+			return fmt.Errorf("document not found")
+		}
+
+		if archived != nil {
+			connID := txn.ConnID
+			txn.AfterCommit = append(txn.AfterCommit, func() {
+				s.archiveDeleted(connID, dbName, collName, []bson.Raw{archived})
+			})
+		}
+		return nil
+	})
+}
+
+// CommitTransaction commits an open transaction and ends its session.
+func (s *Service) CommitTransaction(token string) (*types.TransactionResult, error) {
+	txn, err := s.state.TakeTransaction(token)
+	if err != nil {
+		return nil, err
+	}
+	txn.Mu.Lock()
+	defer txn.Mu.Unlock()
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+	defer txn.Session.EndSession(ctx)
+
+	if err := txn.Session.CommitTransaction(ctx); err != nil {
+		debug.LogDocument("Transaction commit failed", map[string]interface{}{
+			"connectionId": txn.ConnID,
+			"token":        token,
+			"error":        err.Error(),
+		})
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, fn := range txn.AfterCommit {
+		fn()
+	}
+
+	result := &types.TransactionResult{
+		Token:      token,
+		Operations: txn.Operations,
+		DurationMs: time.Since(txn.Started).Milliseconds(),
+	}
+
+	debug.LogDocument("Transaction committed", map[string]interface{}{
+		"connectionId": txn.ConnID,
+		"token":        token,
+		"operations":   txn.Operations,
+	})
+
+	return result, nil
+}
+
+// AbortTransaction discards an open transaction and ends its session.
+func (s *Service) AbortTransaction(token string) error {
+	txn, err := s.state.TakeTransaction(token)
+	if err != nil {
+		return err
+	}
+	txn.Mu.Lock()
+	defer txn.Mu.Unlock()
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+	defer txn.Session.EndSession(ctx)
+
+	if err := txn.Session.AbortTransaction(ctx); err != nil {
+		return fmt.Errorf("failed to abort transaction: %w", err)
+	}
+
+	debug.LogDocument("Transaction aborted", map[string]interface{}{
+		"connectionId": txn.ConnID,
+		"token":        token,
+	})
+
+	return nil
+}
+
+// inTransaction runs one write on dbName inside an open transaction, counting it on success.
+func (s *Service) inTransaction(token, dbName string, fn func(ctx context.Context, client *mongo.Client, txn *core.Transaction) error) error {
+	txn, err := s.state.GetTransaction(token)
+	if err != nil {
+		return err
+	}
+	txn.Mu.Lock()
+	defer txn.Mu.Unlock()
+	// It may have been committed or aborted while this write waited for the lock
+	if _, err := s.state.GetTransaction(token); err != nil {
+		return err
+	}
+
+	client, err := s.state.GetDatabaseClient(txn.ConnID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := fn(txn.Context(ctx), client, txn); err != nil {
+		return err
+	}
+	txn.Operations++
+	return nil
+}
@@ -0,0 +1,55 @@
+package document
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestBeginTransaction_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	var notConnected *core.NotConnectedError
+	if _, err := svc.BeginTransaction("conn-1"); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestTransaction_UnknownToken(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"insert", func() error {
+			_, err := svc.InsertInTransaction("missing", "db", "coll", `{"a": 1}`)
+			return err
+		}},
+		{"update", func() error { return svc.UpdateInTransaction("missing", "db", "coll", "1", `{"_id": 1}`) }},
+		{"delete", func() error { return svc.DeleteInTransaction("missing", "db", "coll", "1") }},
+		{"commit", func() error {
+			_, err := svc.CommitTransaction("missing")
+			return err
+		}},
+		{"abort", func() error { return svc.AbortTransaction("missing") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notFound *core.TransactionNotFoundError
+			if err := tt.call(); !errors.As(err, &notFound) {
+				t.Errorf("Expected TransactionNotFoundError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInsertInTransaction_InvalidJSON(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.InsertInTransaction("missing", "db", "coll", `{"a": `); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
@@ -0,0 +1,259 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/script"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// transformBatchSize is the number of documents sent to the script and written per round trip.
+const transformBatchSize = 500
+
+// TransformPreviewSize is the number of documents a dry-run transform processes.
+const TransformPreviewSize = 20
+
+// TransformDocuments runs transformScript over every document matching filter and writes the
+// documents it changes back. The script is the body of a JavaScript function of doc, run by
+// mongosh (see script.RunTransform). A document the script throws on, or whose _id it changes,
+// is left unchanged and reported in Errors. With dryRun set, only the first
+// TransformPreviewSize documents are transformed and returned as a before/after preview.
+// Otherwise documents are processed in _id order in batches, emitting "migration:progress"
+// events tagged with an operation ID that can be passed to CancelQuery to stop between batches.
+func (s *Service) TransformDocuments(connID, dbName, collName, filter, transformScript string, dryRun bool) (*types.TransformResult, error) {
+	if !dryRun {
+		if err := s.state.CheckWritable(connID, "transform documents"); err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(transformScript) == "" {
+		return nil, fmt.Errorf("transform script cannot be empty")
+	}
+	filterDoc, err := ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	if available, _ := script.CheckMongoshAvailable(); !available {
+		return nil, fmt.Errorf("transforms run in mongosh, which was not found. Please install MongoDB Shell: https://www.mongodb.com/try/download/shell")
+	}
+	coll := client.Database(dbName).Collection(collName)
+
+	countCtx, countCancel := s.state.ContextWithTimeout()
+	matched, err := coll.CountDocuments(countCtx, filterDoc)
+	countCancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	operationID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.state.SetQueryCancel(operationID, cancel)
+	defer s.state.ClearQueryCancel(operationID)
+	defer cancel()
+
+	debug.LogDocument("Transforming documents", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"matched":     matched,
+		"dryRun":      dryRun,
+		"operationId": operationID,
+	})
+
+	result := &types.TransformResult{
+		MigrationResult: types.MigrationResult{OperationID: operationID, DryRun: dryRun, Matched: matched},
+		Errors:          []types.TransformError{},
+	}
+	emitProgress := func() {
+		s.state.EmitConnectionEvent(connID, "migration:progress", types.MigrationProgress{
+			OperationID: operationID,
+			Database:    dbName,
+			Collection:  collName,
+			Processed:   result.Processed,
+			Modified:    result.Modified,
+			Total:       matched,
+		})
+	}
+	addError := func(id, message string) {
+		result.Failed++
+		if len(result.Errors) < MaxConversionFailures {
+			result.Errors = append(result.Errors, types.TransformError{ID: id, Message: message})
+		}
+	}
+
+	limit := int64(transformBatchSize)
+	if dryRun {
+		limit = TransformPreviewSize
+	}
+
+	var lastID interface{}
+	for {
+		if ctx.Err() != nil {
+			result.Cancelled = true
+			break
+		}
+		pageFilter := filterDoc
+		if lastID != nil {
+			pageFilter = andFilter(filterDoc, bson.M{"_id": bson.M{"$gt": lastID}})
+		}
+		docs, err := s.nextDocumentBatch(ctx, coll, pageFilter, limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				result.Cancelled = true
+				break
+			}
+			return result, fmt.Errorf("failed to read documents: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+		lastID = docs[len(docs)-1].Lookup("_id")
+
+		inputs := make([]string, len(docs))
+		for i, doc := range docs {
+			b, err := bson.MarshalExtJSON(doc, true, false)
+			if err != nil {
+				return result, fmt.Errorf("failed to marshal document: %w", err)
+			}
+			inputs[i] = string(b)
+		}
+		outputs, err := script.RunTransform(ctx, transformScript, inputs)
+		if err != nil {
+			if ctx.Err() != nil {
+				result.Cancelled = true
+				break
+			}
+			return result, err
+		}
+
+		var models []mongo.WriteModel
+		var modelIDs []string
+		for i, out := range outputs {
+			id, _ := MarshalValue(docs[i].Lookup("_id"))
+			if out.Error != "" {
+				addError(id, out.Error)
+				continue
+			}
+			newDoc, changed, err := transformedDocument(docs[i], out.Doc)
+			if err != nil {
+				addError(id, err.Error())
+				continue
+			}
+			if !changed {
+				result.Unchanged++
+			}
+			if dryRun {
+				preview := types.TransformPreview{ID: id, Before: inputs[i]}
+				if newDoc != nil {
+					after, _ := MarshalDocument(newDoc)
+					preview.After = string(after)
+				}
+				result.Preview = append(result.Preview, preview)
+				continue
+			}
+			if changed {
+				models = append(models, mongo.NewReplaceOneModel().
+					SetFilter(bson.D{{Key: "_id", Value: docs[i].Lookup("_id")}}).
+					SetReplacement(newDoc))
+				modelIDs = append(modelIDs, id)
+			}
+		}
+		result.Processed += int64(len(docs))
+
+		if len(models) > 0 {
+			writeCtx, writeCancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+			res, err := coll.BulkWrite(writeCtx, models, options.BulkWrite().SetOrdered(false))
+			writeCancel()
+			if res != nil {
+				result.Modified += res.ModifiedCount
+			}
+			if err != nil {
+				var bulkErr mongo.BulkWriteException
+				if !errors.As(err, &bulkErr) {
+					if ctx.Err() != nil {
+						result.Cancelled = true
+						break
+					}
+					return result, fmt.Errorf("failed to write documents: %w", err)
+				}
+				for _, we := range bulkErr.WriteErrors {
+					addError(modelIDs[we.Index], we.Message)
+				}
+			}
+		}
+		emitProgress()
+
+		if dryRun || int64(len(docs)) < limit {
+			break
+		}
+	}
+
+	debug.LogDocument("Transform finished", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"modified":    result.Modified,
+		"failed":      result.Failed,
+		"cancelled":   result.Cancelled,
+		"operationId": operationID,
+	})
+	return result, nil
+}
+
+// nextDocumentBatch returns up to limit matching documents in _id order.
+func (s *Service) nextDocumentBatch(ctx context.Context, coll *mongo.Collection, filter bson.M, limit int64) ([]bson.Raw, error) {
+	findCtx, cancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+	defer cancel()
+
+	findOpts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := coll.Find(findCtx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(findCtx)
+
+	var docs []bson.Raw
+	for cursor.Next(findCtx) {
+		docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+	}
+	return docs, cursor.Err()
+}
+
+// transformedDocument parses the script's output for original. A nil document means the
+// script skipped it. The original _id is kept when the output has none; changing it is an error.
+func transformedDocument(original bson.Raw, output string) (bson.D, bool, error) {
+	if output == "" {
+		return nil, false, nil
+	}
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON([]byte(output), true, &doc); err != nil {
+		return nil, false, fmt.Errorf("script returned an invalid document: %w", err)
+	}
+
+	origID := original.Lookup("_id")
+	if _, ok := lookupID(doc); !ok {
+		doc = append(bson.D{{Key: "_id", Value: origID}}, doc...)
+	}
+	b, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, false, fmt.Errorf("script returned an invalid document: %w", err)
+	}
+	if !bson.Raw(b).Lookup("_id").Equal(origID) {
+		return nil, false, fmt.Errorf("script changed the document's _id")
+	}
+	return doc, !bytes.Equal(b, original), nil
+}
@@ -0,0 +1,70 @@
+package document
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestTransformedDocument(t *testing.T) {
+	original, _ := bson.Marshal(bson.D{{Key: "_id", Value: int32(1)}, {Key: "name", Value: "a"}})
+
+	tests := []struct {
+		name        string
+		output      string
+		wantNil     bool
+		wantChanged bool
+		wantErr     string
+	}{
+		{"unchanged", `{"_id":{"$numberInt":"1"},"name":"a"}`, false, false, ""},
+		{"changed", `{"_id":{"$numberInt":"1"},"name":"b"}`, false, true, ""},
+		{"skipped", ``, true, false, ""},
+		{"missing _id keeps original", `{"name":"a"}`, false, false, ""},
+		{"changed _id", `{"_id":{"$numberInt":"2"},"name":"a"}`, false, false, "_id"},
+		{"id type change", `{"_id":{"$numberLong":"1"},"name":"a"}`, false, false, "_id"},
+		{"invalid", `{"name":`, false, false, "invalid document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, changed, err := transformedDocument(original, tt.output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (doc == nil) != tt.wantNil {
+				t.Errorf("Expected nil document %v, got %v", tt.wantNil, doc)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("Expected changed %v, got %v", tt.wantChanged, changed)
+			}
+			if doc != nil && doc[0].Key != "_id" {
+				t.Errorf("Expected _id first, got %v", doc)
+			}
+		})
+	}
+}
+
+func TestTransformDocuments_Validation(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.TransformDocuments("conn-1", "db", "coll", "{}", "  ", false); err == nil {
+		t.Error("Expected error for empty script")
+	}
+	if _, err := svc.TransformDocuments("conn-1", "db", "coll", "{bad", "return doc;", false); err == nil {
+		t.Error("Expected error for invalid filter")
+	}
+	var notConnected *core.NotConnectedError
+	if _, err := svc.TransformDocuments("conn-1", "db", "coll", "{}", "return doc;", true); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
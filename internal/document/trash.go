@@ -0,0 +1,101 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+)
+
+// Archiver keeps copies of documents that were deleted.
+type Archiver interface {
+	ArchiveDocuments(connID, dbName, collName string, docs []bson.Raw) error
+}
+
+// SetArchiver makes DeleteDocument and DeleteManyDocuments archive the documents they delete,
+// once the delete has succeeded.
+func (s *Service) SetArchiver(archiver Archiver) {
+	s.archiver = archiver
+}
+
+// archiveDeleted moves documents that were just deleted to the trash. The delete cannot be
+// taken back, so a failure is reported with an app:warning event rather than an error.
+func (s *Service) archiveDeleted(connID, dbName, collName string, docs []bson.Raw) {
+	if s.archiver == nil || len(docs) == 0 {
+		return
+	}
+	if err := s.archiver.ArchiveDocuments(connID, dbName, collName, docs); err != nil {
+		s.state.EmitConnectionEvent(connID, "app:warning", map[string]string{
+			"message": fmt.Sprintf("%d deleted document(s) could not be moved to the trash", len(docs)),
+			"detail":  err.Error(),
+		})
+	}
+}
+
+// deletedDocuments returns the documents of docs that a delete by their _ids removed. When the
+// delete count falls short, the documents still in the collection are looked up and left out.
+func deletedDocuments(ctx context.Context, coll *mongo.Collection, docs []bson.Raw, deleted int64) ([]bson.Raw, error) {
+	if deleted >= int64(len(docs)) {
+		return docs, nil
+	}
+	ids := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.Lookup("_id")
+	}
+	cursor, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var remaining []bson.RawValue
+	for cursor.Next(ctx) {
+		remaining = append(remaining, cursor.Current.Lookup("_id"))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	gone := make([]bson.Raw, 0, deleted)
+	for _, doc := range docs {
+		id := doc.Lookup("_id")
+		if !slices.ContainsFunc(remaining, id.Equal) {
+			gone = append(gone, doc)
+		}
+	}
+	return gone, nil
+}
+
+// RestoreDocument re-inserts a document previously archived as canonical Extended JSON,
+// keeping its _id and field order. Fails if a document with the same _id exists.
+func (s *Service) RestoreDocument(connID, dbName, collName, jsonDoc string) error {
+	if err := s.state.CheckWritable(connID, "restore document"); err != nil {
+		return err
+	}
+
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON([]byte(jsonDoc), true, &doc); err != nil {
+		return fmt.Errorf("invalid archived document: %w", err)
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if _, err := client.Database(dbName).Collection(collName).InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to restore document: %w", err)
+	}
+
+	debug.LogDocument("Document restored", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+	})
+	return nil
+}
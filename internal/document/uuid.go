@@ -0,0 +1,38 @@
+package document
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+)
+
+// FormatUUID renders a binary value given as Extended JSON ({"$binary": ...} or {"$uuid": ...})
+// as a UUID string. representation selects the byte order of legacy subtype 3 values:
+// "pythonLegacy" (the default), "javaLegacy", or "csharpLegacy".
+func FormatUUID(binaryJSON, representation string) (string, error) {
+	var doc struct {
+		V primitive.Binary `bson:"v"`
+	}
+	if err := bson.UnmarshalExtJSON([]byte(`{"v": `+binaryJSON+`}`), true, &doc); err != nil {
+		return "", fmt.Errorf("invalid binary value: %w", err)
+	}
+	return bsonutil.BinaryToUUID(doc.V, representation)
+}
+
+// UUIDToExtJSON converts a UUID string to binary Extended JSON for use in queries, using
+// the given representation ("standard" subtype 4 by default, or a legacy subtype 3 order).
+func UUIDToExtJSON(uuid, representation string) (string, error) {
+	b, err := bsonutil.UUIDToBinary(uuid, representation)
+	if err != nil {
+		return "", err
+	}
+	out, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: b}}, true, false)
+	if err != nil {
+		return "", err
+	}
+	// Strip the {"v": ...} wrapper; unlike MarshalValue this keeps the $binary form
+	return string(out[len(`{"v":`) : len(out)-1]), nil
+}
@@ -168,6 +168,21 @@ func (s *Service) getExternalToolURI(connID string) (string, error) {
 	return uri, nil
 }
 
+// checkToolDatabases checks the databases a mongodump or mongorestore run touches against
+// the connection's allow/deny lists. The tools work on every database when none are named,
+// which connections with lists do not allow.
+func (s *Service) checkToolDatabases(connID string, dbNames []string) error {
+	if len(dbNames) == 0 && s.state.HasDatabaseRestrictions(connID) {
+		return fmt.Errorf("this connection restricts database access: select the databases explicitly")
+	}
+	for _, dbName := range dbNames {
+		if err := s.state.CheckDatabaseAccess(connID, dbName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // detectAuthMechanism queries the server for the user's supported SASL auth
 // mechanisms using the active Go driver connection. Returns the preferred
 // mechanism (SCRAM-SHA-256 > SCRAM-SHA-1) or empty string on failure.
@@ -458,7 +473,7 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 			return fmt.Errorf("failed to open save dialog: %w", err)
 		}
 		if selected == "" {
-			s.state.EmitEvent("export:cancelled", nil)
+			s.state.EmitConnectionEvent(connID, "export:cancelled", nil)
 			return nil
 		}
 		filePath = selected
@@ -494,6 +509,15 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 	} else {
 		jobs = append(jobs, dumpJob{})
 	}
+	jobDBs := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		if job.db != "" {
+			jobDBs = append(jobDBs, job.db)
+		}
+	}
+	if err := s.checkToolDatabases(connID, jobDBs); err != nil {
+		return err
+	}
 
 	// Create cancellable context
 	exportID := fmt.Sprintf("bson-%s-%d", connID, time.Now().UnixNano())
@@ -542,7 +566,7 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 		select {
 		case <-exportCtx.Done():
 			cleanupExport()
-			s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID})
+			s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID})
 			return fmt.Errorf("export cancelled")
 		default:
 		}
@@ -581,7 +605,7 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 		}
 
 		// Emit progress
-		s.state.EmitEvent("export:progress", types.ExportProgress{
+		s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 			ExportID:      exportID,
 			Phase:         "exporting",
 			Database:      job.db,
@@ -616,7 +640,7 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 					stderrLines = stderrLines[1:]
 				}
 				if matches := reDumpDone.FindStringSubmatch(line); len(matches) >= 4 {
-					s.state.EmitEvent("export:progress", types.ExportProgress{
+					s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 						ExportID:      exportID,
 						Phase:         "exporting",
 						Database:      matches[1],
@@ -637,7 +661,7 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 			select {
 			case <-exportCtx.Done():
 				cleanupExport()
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID})
+				s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID})
 				return fmt.Errorf("export cancelled")
 			default:
 			}
@@ -648,7 +672,7 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 		}
 	}
 
-	s.state.EmitEvent("export:complete", map[string]interface{}{
+	s.state.EmitConnectionEvent(connID, "export:complete", map[string]interface{}{
 		"exportId": exportID,
 		"filePath": filePath,
 	})
@@ -661,11 +685,32 @@ func (s *Service) ExportWithMongodump(connID string, opts types.MongodumpOptions
 //   - Directory of BSON files: raw mongodump output (uses --dir)
 //   - .archive: single mongodump archive file (uses --archive=<file> --gzip)
 func (s *Service) ImportWithMongorestore(connID string, opts types.MongorestoreOptions) (*types.ImportResult, error) {
+	if err := s.state.CheckWritable(connID, "import"); err != nil {
+		return nil, err
+	}
+	if opts.Drop && !opts.DryRun {
+		if err := s.state.RequireConfirmation(connID, "restore with drop", opts.InputPath); err != nil {
+			return nil, err
+		}
+	}
+
 	available, toolPath := CheckMongorestoreAvailable()
 	if !available {
 		return nil, fmt.Errorf("mongorestore not found. Install MongoDB Database Tools: %s", toolDownloadURL)
 	}
 
+	var restoreDBs []string
+	if opts.Database != "" {
+		restoreDBs = append(restoreDBs, opts.Database)
+	} else {
+		for _, ns := range opts.NsInclude {
+			restoreDBs = append(restoreDBs, strings.SplitN(ns, ".", 2)[0])
+		}
+	}
+	if err := s.checkToolDatabases(connID, restoreDBs); err != nil {
+		return nil, err
+	}
+
 	// Get connection URI
 	uri, err := s.getExternalToolURI(connID)
 	if err != nil {
@@ -692,10 +737,10 @@ func (s *Service) ImportWithMongorestore(connID string, opts types.MongorestoreO
 	}
 
 	if info.IsDir() {
-		return s.restoreFromDir(importCtx, toolPath, uri, inputPath, opts)
+		return s.restoreFromDir(importCtx, connID, toolPath, uri, inputPath, opts)
 	}
 	// Single .archive file
-	return s.restoreFromArchive(importCtx, toolPath, uri, inputPath, opts)
+	return s.restoreFromArchive(importCtx, connID, toolPath, uri, inputPath, opts)
 }
 
 // dirContainsArchiveFiles checks if a directory contains .archive files.
@@ -747,9 +792,9 @@ func dirContainsGzipFilesDepth(dirPath string, maxDepth int) bool {
 
 // restoreFromDir restores from a directory. Detects whether it contains
 // .archive files (MongoPal multi-DB export) or BSON files (raw mongodump).
-func (s *Service) restoreFromDir(ctx context.Context, toolPath, uri, inputPath string, opts types.MongorestoreOptions) (*types.ImportResult, error) {
+func (s *Service) restoreFromDir(ctx context.Context, connID, toolPath, uri, inputPath string, opts types.MongorestoreOptions) (*types.ImportResult, error) {
 	if dirContainsArchiveFiles(inputPath) {
-		return s.restoreFromArchiveDir(ctx, toolPath, uri, inputPath, opts)
+		return s.restoreFromArchiveDir(ctx, connID, toolPath, uri, inputPath, opts)
 	}
 
 	// Raw mongodump directory — use --dir
@@ -778,11 +823,11 @@ func (s *Service) restoreFromDir(ctx context.Context, toolPath, uri, inputPath s
 		args = append(args, "--dryRun")
 	}
 
-	return s.runMongorestore(ctx, toolPath, args)
+	return s.runMongorestore(ctx, connID, toolPath, args)
 }
 
 // restoreFromArchiveDir restores from a directory of .archive files (MongoPal multi-DB export).
-func (s *Service) restoreFromArchiveDir(ctx context.Context, toolPath, uri, dirPath string, opts types.MongorestoreOptions) (*types.ImportResult, error) {
+func (s *Service) restoreFromArchiveDir(ctx context.Context, connID, toolPath, uri, dirPath string, opts types.MongorestoreOptions) (*types.ImportResult, error) {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
@@ -813,13 +858,13 @@ func (s *Service) restoreFromArchiveDir(ctx context.Context, toolPath, uri, dirP
 		// Check cancellation between archives
 		select {
 		case <-ctx.Done():
-			s.state.EmitEvent("import:cancelled", nil)
+			s.state.EmitConnectionEvent(connID, "import:cancelled", nil)
 			return combined, fmt.Errorf("import cancelled")
 		default:
 		}
 
 		archivePath := filepath.Join(dirPath, entry.Name())
-		result, err := s.restoreFromArchive(ctx, toolPath, uri, archivePath, opts)
+		result, err := s.restoreFromArchive(ctx, connID, toolPath, uri, archivePath, opts)
 		if err != nil {
 			combined.Errors = append(combined.Errors, fmt.Sprintf("%s: %v", entry.Name(), err))
 		}
@@ -831,7 +876,7 @@ func (s *Service) restoreFromArchiveDir(ctx context.Context, toolPath, uri, dirP
 		}
 	}
 
-	s.state.EmitEvent("import:complete", map[string]interface{}{
+	s.state.EmitConnectionEvent(connID, "import:complete", map[string]interface{}{
 		"documentsInserted": combined.DocumentsInserted,
 		"documentsFailed":   combined.DocumentsFailed,
 	})
@@ -839,7 +884,7 @@ func (s *Service) restoreFromArchiveDir(ctx context.Context, toolPath, uri, dirP
 }
 
 // restoreFromArchive restores from a single .archive file using --archive=<file> --gzip.
-func (s *Service) restoreFromArchive(ctx context.Context, toolPath, uri, archivePath string, opts types.MongorestoreOptions) (*types.ImportResult, error) {
+func (s *Service) restoreFromArchive(ctx context.Context, connID, toolPath, uri, archivePath string, opts types.MongorestoreOptions) (*types.ImportResult, error) {
 	connURI := uri
 	if opts.Database != "" {
 		connURI = stripURIDatabase(uri)
@@ -865,12 +910,12 @@ func (s *Service) restoreFromArchive(ctx context.Context, toolPath, uri, archive
 		args = append(args, "--nsInclude="+ns)
 	}
 
-	return s.runMongorestore(ctx, toolPath, args)
+	return s.runMongorestore(ctx, connID, toolPath, args)
 }
 
 // runMongorestore executes a single mongorestore command, parsing stderr for progress.
-func (s *Service) runMongorestore(ctx context.Context, toolPath string, args []string) (*types.ImportResult, error) {
-	s.state.EmitEvent("import:progress", types.ExportProgress{
+func (s *Service) runMongorestore(ctx context.Context, connID, toolPath string, args []string) (*types.ImportResult, error) {
+	s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 		Phase:   "importing",
 		Current: 0,
 		Total:   -1,
@@ -932,7 +977,7 @@ func (s *Service) runMongorestore(ctx context.Context, toolPath string, args []s
 				result.DocumentsInserted += docCount
 				result.DocumentsFailed += failCount
 
-				s.state.EmitEvent("import:progress", types.ExportProgress{
+				s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 					Phase:      "importing",
 					Database:   dbName,
 					Collection: collName,
@@ -974,7 +1019,7 @@ func (s *Service) runMongorestore(ctx context.Context, toolPath string, args []s
 		// Check if it was cancelled
 		select {
 		case <-ctx.Done():
-			s.state.EmitEvent("import:cancelled", nil)
+			s.state.EmitConnectionEvent(connID, "import:cancelled", nil)
 			return result, fmt.Errorf("import cancelled")
 		default:
 		}
@@ -989,7 +1034,7 @@ func (s *Service) runMongorestore(ctx context.Context, toolPath string, args []s
 		return result, fmt.Errorf("mongorestore stderr read error: %w", scanErr)
 	}
 
-	s.state.EmitEvent("import:complete", map[string]interface{}{
+	s.state.EmitConnectionEvent(connID, "import:complete", map[string]interface{}{
 		"documentsInserted": result.DocumentsInserted,
 	})
 	return result, nil
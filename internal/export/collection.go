@@ -11,6 +11,7 @@ import (
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/peternagy/mongopal/internal/types"
 )
@@ -28,13 +29,34 @@ func sanitizeFilename(name string) string {
 	return sanitized.String()
 }
 
+// collectionOptionsJSON returns a collection's create options as canonical Extended JSON so
+// an import can recreate it the same way (time-series, capped, validator, ...). Returns ""
+// for collections created with default options or when the options cannot be read.
+func (s *Service) collectionOptionsJSON(db *mongo.Database, collName string) string {
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: collName}})
+	if err != nil || len(specs) == 0 {
+		return ""
+	}
+	if elems, err := specs[0].Options.Elements(); err != nil || len(elems) == 0 {
+		return ""
+	}
+	optionsJSON, err := bson.MarshalExtJSON(specs[0].Options, true, false)
+	if err != nil {
+		return ""
+	}
+	return string(optionsJSON)
+}
+
 // ExportCollections exports selected collections from a single database to a zip file.
 func (s *Service) ExportCollections(connID, dbName string, collNames []string) error {
 	if len(collNames) == 0 {
 		return fmt.Errorf("no collections selected for export")
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
@@ -69,7 +91,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 	}
 	if filePath == "" {
 		// User cancelled the save dialog - notify frontend
-		runtime.EventsEmit(s.state.Ctx, "export:cancelled")
+		s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"database": dbName})
 		return nil
 	}
 
@@ -129,7 +151,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 		// Check for cancellation
 		select {
 		case <-exportCtx.Done():
-			s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID})
+			s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID})
 			zipWriter.Close()
 			zipFile.Close()
 			os.Remove(filePath)
@@ -141,7 +163,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 		estimatedCount := collEstimates[collName]
 
 		// Emit progress
-		s.state.EmitEvent("export:progress", types.ExportProgress{
+		s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 			ExportID:        exportID,
 			Phase:           "exporting",
 			Database:        dbName,
@@ -191,7 +213,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 				}
 
 				// Emit progress update
-				s.state.EmitEvent("export:progress", types.ExportProgress{
+				s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 					ExportID:        exportID,
 					Phase:           "exporting",
 					Database:        dbName,
@@ -226,7 +248,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 		processedDocs += docCount
 
 		// Emit final progress for this collection (ensures no jumps between collections)
-		s.state.EmitEvent("export:progress", types.ExportProgress{
+		s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 			ExportID:        exportID,
 			Phase:           "exporting",
 			Database:        dbName,
@@ -240,7 +262,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 		})
 
 		if cancelled {
-			s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID})
+			s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID})
 			zipWriter.Close()
 			zipFile.Close()
 			os.Remove(filePath)
@@ -276,6 +298,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 		manifest.Databases[0].Collections = append(manifest.Databases[0].Collections, types.ExportManifestCollection{
 			Name:     collName,
 			DocCount: docCount,
+			Options:  s.collectionOptionsJSON(db, collName),
 		})
 	}
 
@@ -287,7 +310,7 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 	}
 
 	// Emit 100% progress before complete
-	s.state.EmitEvent("export:progress", types.ExportProgress{
+	s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 		ExportID:        exportID,
 		Phase:           "finalizing",
 		Database:        dbName,
@@ -300,6 +323,6 @@ func (s *Service) ExportCollections(connID, dbName string, collNames []string) e
 		TotalDocs:       totalDocs,
 	})
 
-	s.state.EmitEvent("export:complete", map[string]interface{}{"exportId": exportID, "filePath": filePath})
+	s.state.EmitConnectionEvent(connID, "export:complete", map[string]interface{}{"exportId": exportID, "filePath": filePath})
 	return nil
 }
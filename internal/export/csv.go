@@ -73,7 +73,7 @@ func (s *Service) RevealInFinder(filePath string) error {
 
 // ExportCollectionAsCSV exports a collection to a CSV file.
 func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilename string, opts types.CSVExportOptions) error {
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
@@ -113,7 +113,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 			return fmt.Errorf("failed to open save dialog: %w", err)
 		}
 		if filePath == "" {
-			runtime.EventsEmit(s.state.Ctx, "export:cancelled", map[string]interface{}{"database": dbName, "collection": collName})
+			s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"database": dbName, "collection": collName})
 			return nil
 		}
 	}
@@ -136,14 +136,10 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 	}
 	defer file.Close()
 
-	// Parse filter
-	var filter bson.M
-	if opts.Filter == "" || opts.Filter == "{}" {
-		filter = bson.M{}
-	} else {
-		if err := bson.UnmarshalExtJSON([]byte(opts.Filter), true, &filter); err != nil {
-			return fmt.Errorf("invalid filter: %w", err)
-		}
+	// Parse data source (pipeline or filter)
+	source, err := parseExportSource(opts.Filter, opts.Pipeline)
+	if err != nil {
+		return err
 	}
 
 	db := client.Database(dbName)
@@ -162,7 +158,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 	const totalProgress int64 = 10000 // 100.00%
 
 	// Emit initial progress
-	s.state.EmitEvent("export:progress", types.ExportProgress{
+	s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 		ExportID:      exportID,
 		Phase:         "downloading",
 		Database:      dbName,
@@ -185,7 +181,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	cursor, err := coll.Find(ctx, filter)
+	cursor, err := source.open(ctx, coll)
 	if err != nil {
 		tempFile.Close()
 		return fmt.Errorf("failed to query collection: %w", err)
@@ -204,7 +200,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 				tempWriter.Flush()
 				tempFile.Close()
 				os.Remove(filePath)
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
+				s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
 				return fmt.Errorf("export cancelled")
 			}
 			// Also check context directly
@@ -214,7 +210,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 				tempWriter.Flush()
 				tempFile.Close()
 				os.Remove(filePath)
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
+				s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
 				return fmt.Errorf("export cancelled")
 			default:
 			}
@@ -225,7 +221,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 			if downloadPct > 8000 {
 				downloadPct = 8000
 			}
-			s.state.EmitEvent("export:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 				ExportID:      exportID,
 				Phase:         "downloading",
 				Database:      dbName,
@@ -266,7 +262,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 	}
 
 	// Emit 80% progress - download complete, starting CSV write
-	s.state.EmitEvent("export:progress", types.ExportProgress{
+	s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 		ExportID:      exportID,
 		Phase:         "writing",
 		Database:      dbName,
@@ -325,7 +321,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 				writer.Flush()
 				file.Close()
 				os.Remove(filePath)
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
+				s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
 				return fmt.Errorf("export cancelled")
 			}
 			// Also check context directly
@@ -334,7 +330,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 				writer.Flush()
 				file.Close()
 				os.Remove(filePath)
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
+				s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
 				return fmt.Errorf("export cancelled")
 			default:
 			}
@@ -342,7 +338,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 			// Progress: writing is 80-100% of total (20% of work)
 			// Calculate: 80% + (exportedCount / totalDocs) * 20%
 			writePct := 8000 + (exportedCount*2000)/totalDocs // 8000-10000 (80-100%)
-			s.state.EmitEvent("export:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 				ExportID:      exportID,
 				Phase:         "writing",
 				Database:      dbName,
@@ -379,7 +375,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 	}
 
 	// Emit 100% progress before complete
-	s.state.EmitEvent("export:progress", types.ExportProgress{
+	s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 		ExportID:      exportID,
 		Phase:         "writing",
 		Database:      dbName,
@@ -389,7 +385,7 @@ func (s *Service) ExportCollectionAsCSV(connID, dbName, collName, defaultFilenam
 		ProcessedDocs: totalDocs,
 	})
 
-	s.state.EmitEvent("export:complete", map[string]interface{}{
+	s.state.EmitConnectionEvent(connID, "export:complete", map[string]interface{}{
 		"exportId":   exportID,
 		"filePath":   filePath,
 		"database":   dbName,
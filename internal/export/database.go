@@ -102,6 +102,11 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 	if err != nil {
 		return err
 	}
+	for _, dbName := range dbNames {
+		if err := s.state.CheckDatabaseAccess(opts.ConnID, dbName); err != nil {
+			return err
+		}
+	}
 
 	// Get connection name for filename
 	connName := "export"
@@ -124,7 +129,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 			return fmt.Errorf("failed to open save dialog: %w", dlgErr)
 		}
 		if filePath == "" {
-			runtime.EventsEmit(s.state.Ctx, "export:cancelled")
+			s.state.EmitConnectionEvent(opts.ConnID, "export:cancelled", map[string]interface{}{"databases": dbNames})
 			return nil
 		}
 	}
@@ -175,7 +180,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 			}
 		} else {
 			// Full: discover all non-view collections
-			ctx, cancel := core.ContextWithTimeout()
+			ctx, cancel := s.state.ContextWithTimeout()
 			db := client.Database(dbName)
 			cursor, err := db.ListCollections(ctx, bson.D{})
 			if err != nil {
@@ -214,7 +219,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 		// Check for cancellation
 		select {
 		case <-exportCtx.Done():
-			s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID})
+			s.state.EmitConnectionEvent(opts.ConnID, "export:cancelled", map[string]interface{}{"exportId": exportID})
 			zipWriter.Close()
 			zipFile.Close()
 			os.Remove(filePath)
@@ -233,12 +238,12 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 		for _, collName := range collNames {
 			coll := db.Collection(collName)
 
-			ctx, cancel := core.ContextWithTimeout()
+			ctx, cancel := s.state.ContextWithTimeout()
 			estimatedCount, _ := coll.EstimatedDocumentCount(ctx)
 			cancel()
 
 			// Emit progress
-			s.state.EmitEvent("export:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(opts.ConnID, "export:progress", types.ExportProgress{
 				ExportID:      exportID,
 				Phase:         "exporting",
 				Database:      dbName,
@@ -256,7 +261,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 			docCursor, err := coll.Find(ctx, bson.D{})
 			if err != nil {
 				cancel()
-				s.state.EmitEvent("export:warning", map[string]interface{}{
+				s.state.EmitConnectionEvent(opts.ConnID, "export:warning", map[string]interface{}{
 					"database":   dbName,
 					"collection": collName,
 					"error":      fmt.Sprintf("failed to query documents: %v", err),
@@ -308,7 +313,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 
 				// Emit progress periodically
 				if docCount%1000 == 0 {
-					s.state.EmitEvent("export:progress", types.ExportProgress{
+					s.state.EmitConnectionEvent(opts.ConnID, "export:progress", types.ExportProgress{
 						ExportID:      exportID,
 						Phase:         "exporting",
 						Database:      dbName,
@@ -327,7 +332,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 			processedDocs += docCount
 
 			// Emit final progress for this collection
-			s.state.EmitEvent("export:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(opts.ConnID, "export:progress", types.ExportProgress{
 				ExportID:      exportID,
 				Phase:         "exporting",
 				Database:      dbName,
@@ -341,7 +346,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 			})
 
 			if skippedDocs > 0 {
-				s.state.EmitEvent("export:warning", map[string]interface{}{
+				s.state.EmitConnectionEvent(opts.ConnID, "export:warning", map[string]interface{}{
 					"database":   dbName,
 					"collection": collName,
 					"skipped":    skippedDocs,
@@ -351,7 +356,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 			if cancelled {
 				docCursor.Close(ctx)
 				cancel()
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID})
+				s.state.EmitConnectionEvent(opts.ConnID, "export:cancelled", map[string]interface{}{"exportId": exportID})
 				zipWriter.Close()
 				zipFile.Close()
 				os.Remove(filePath)
@@ -362,10 +367,10 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 
 			// Export indexes
 			var indexes []bson.M
-			ctx2, cancel2 := core.ContextWithTimeout()
+			ctx2, cancel2 := s.state.ContextWithTimeout()
 			indexCursor, err := coll.Indexes().List(ctx2)
 			if err != nil {
-				s.state.EmitEvent("export:warning", map[string]interface{}{
+				s.state.EmitConnectionEvent(opts.ConnID, "export:warning", map[string]interface{}{
 					"database":   dbName,
 					"collection": collName,
 					"error":      fmt.Sprintf("failed to list indexes: %v", err),
@@ -398,6 +403,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 				Name:       collName,
 				DocCount:   docCount,
 				IndexCount: len(indexes),
+				Options:    s.collectionOptionsJSON(db, collName),
 			})
 		}
 
@@ -413,7 +419,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 	manifestWriter.Write(manifestData)
 
 	// Emit 100% progress before complete
-	s.state.EmitEvent("export:progress", types.ExportProgress{
+	s.state.EmitConnectionEvent(opts.ConnID, "export:progress", types.ExportProgress{
 		ExportID:      exportID,
 		Phase:         "finalizing",
 		Database:      "",
@@ -426,7 +432,7 @@ func (s *Service) exportDatabases(opts DatabaseExportOptions, dbNames []string)
 		TotalDocs:     totalDocs,
 	})
 
-	s.state.EmitEvent("export:complete", map[string]interface{}{"exportId": exportID, "filePath": filePath})
+	s.state.EmitConnectionEvent(opts.ConnID, "export:complete", map[string]interface{}{"exportId": exportID, "filePath": filePath})
 	return nil
 }
 
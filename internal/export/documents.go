@@ -14,7 +14,7 @@ import (
 )
 
 // ExportDocumentsAsZip exports multiple documents as a ZIP file.
-func (s *Service) ExportDocumentsAsZip(entries []types.DocumentExportEntry, defaultFilename string) error {
+func (s *Service) ExportDocumentsAsZip(connID string, entries []types.DocumentExportEntry, defaultFilename string) error {
 	if len(entries) == 0 {
 		return fmt.Errorf("no documents to export")
 	}
@@ -32,7 +32,7 @@ func (s *Service) ExportDocumentsAsZip(entries []types.DocumentExportEntry, defa
 	}
 	if filePath == "" {
 		// User cancelled the save dialog - notify frontend
-		runtime.EventsEmit(s.state.Ctx, "export:cancelled")
+		s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"database": entries[0].Database, "collection": entries[0].Collection})
 		return nil
 	}
 
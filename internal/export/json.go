@@ -37,7 +37,7 @@ func (s *Service) GetJSONSavePath(defaultFilename string) (string, error) {
 
 // ExportCollectionAsJSON exports a collection to a JSON file (NDJSON or JSON array).
 func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilename string, opts types.JSONExportOptions) error {
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return err
 	}
@@ -66,7 +66,7 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 			return fmt.Errorf("failed to open save dialog: %w", err)
 		}
 		if filePath == "" {
-			runtime.EventsEmit(s.state.Ctx, "export:cancelled", map[string]interface{}{"database": dbName, "collection": collName})
+			s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"database": dbName, "collection": collName})
 			return nil
 		}
 	}
@@ -90,14 +90,10 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 	}
 	defer file.Close()
 
-	// Parse filter
-	var filter bson.M
-	if opts.Filter == "" || opts.Filter == "{}" {
-		filter = bson.M{}
-	} else {
-		if err := bson.UnmarshalExtJSON([]byte(opts.Filter), true, &filter); err != nil {
-			return fmt.Errorf("invalid filter: %w", err)
-		}
+	// Parse data source (pipeline or filter)
+	source, err := parseExportSource(opts.Filter, opts.Pipeline)
+	if err != nil {
+		return err
 	}
 
 	db := client.Database(dbName)
@@ -113,7 +109,7 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 
 	const totalProgress int64 = 10000 // 100.00%
 
-	s.state.EmitEvent("export:progress", types.ExportProgress{
+	s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 		ExportID:      exportID,
 		Phase:         "downloading",
 		Database:      dbName,
@@ -127,7 +123,7 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	cursor, err := coll.Find(ctx, filter)
+	cursor, err := source.open(ctx, coll)
 	if err != nil {
 		return fmt.Errorf("failed to query collection: %w", err)
 	}
@@ -152,13 +148,13 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 		if docCount%100 == 0 {
 			if !s.state.WaitIfExportPaused(exportCtx) {
 				os.Remove(filePath)
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
+				s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
 				return fmt.Errorf("export cancelled")
 			}
 			select {
 			case <-exportCtx.Done():
 				os.Remove(filePath)
-				s.state.EmitEvent("export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
+				s.state.EmitConnectionEvent(connID, "export:cancelled", map[string]interface{}{"exportId": exportID, "database": dbName, "collection": collName})
 				return fmt.Errorf("export cancelled")
 			default:
 			}
@@ -167,7 +163,7 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 			if pct > 9500 {
 				pct = 9500
 			}
-			s.state.EmitEvent("export:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 				ExportID:      exportID,
 				Phase:         "downloading",
 				Database:      dbName,
@@ -230,14 +226,14 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 	}
 
 	if skipCount > 0 {
-		s.state.EmitEvent("export:warning", map[string]interface{}{
+		s.state.EmitConnectionEvent(connID, "export:warning", map[string]interface{}{
 			"exportId": exportID,
 			"message":  fmt.Sprintf("%d documents skipped due to decode errors", skipCount),
 		})
 	}
 
 	// Emit 100% progress
-	s.state.EmitEvent("export:progress", types.ExportProgress{
+	s.state.EmitConnectionEvent(connID, "export:progress", types.ExportProgress{
 		ExportID:      exportID,
 		Phase:         "writing",
 		Database:      dbName,
@@ -247,7 +243,7 @@ func (s *Service) ExportCollectionAsJSON(connID, dbName, collName, defaultFilena
 		ProcessedDocs: docCount,
 	})
 
-	s.state.EmitEvent("export:complete", map[string]interface{}{
+	s.state.EmitConnectionEvent(connID, "export:complete", map[string]interface{}{
 		"exportId":   exportID,
 		"filePath":   filePath,
 		"database":   dbName,
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/document"
+)
+
+// exportSource is the data an export reads: either an aggregation pipeline or a find filter.
+type exportSource struct {
+	filter   bson.M
+	pipeline mongo.Pipeline
+}
+
+// parseExportSource parses export options into a data source. A non-empty pipeline
+// (Extended JSON array of stages) takes precedence over the filter.
+func parseExportSource(filter, pipeline string) (*exportSource, error) {
+	if pipeline != "" {
+		stages, err := document.ParsePipeline(pipeline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pipeline: %w", err)
+		}
+		if len(stages) > 0 {
+			for _, stage := range stages {
+				if len(stage) > 0 && (stage[0].Key == "$out" || stage[0].Key == "$merge") {
+					return nil, fmt.Errorf("export pipelines cannot contain %s", stage[0].Key)
+				}
+			}
+			return &exportSource{pipeline: stages}, nil
+		}
+	}
+
+	filterDoc, err := document.ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return &exportSource{filter: filterDoc}, nil
+}
+
+// open returns a cursor over the source documents.
+func (src *exportSource) open(ctx context.Context, coll *mongo.Collection) (*mongo.Cursor, error) {
+	if src.pipeline != nil {
+		// Grouping and sorting large results may exceed the in-memory limit
+		return coll.Aggregate(ctx, src.pipeline, options.Aggregate().SetAllowDiskUse(true))
+	}
+	return coll.Find(ctx, src.filter)
+}
@@ -0,0 +1,41 @@
+package export
+
+import "testing"
+
+func TestParseExportSource(t *testing.T) {
+	src, err := parseExportSource(`{"status": "paid"}`, "")
+	if err != nil {
+		t.Fatalf("parseExportSource failed: %v", err)
+	}
+	if src.pipeline != nil || src.filter["status"] != "paid" {
+		t.Errorf("expected filter source, got %+v", src)
+	}
+
+	src, err = parseExportSource(`{"ignored": true}`, `[{"$group": {"_id": "$status", "n": {"$sum": 1}}}]`)
+	if err != nil {
+		t.Fatalf("parseExportSource failed: %v", err)
+	}
+	if len(src.pipeline) != 1 || src.filter != nil {
+		t.Errorf("expected pipeline source, got %+v", src)
+	}
+
+	// An empty pipeline falls back to the filter
+	src, err = parseExportSource("", "[]")
+	if err != nil {
+		t.Fatalf("parseExportSource failed: %v", err)
+	}
+	if src.pipeline != nil || src.filter == nil {
+		t.Errorf("expected empty filter source, got %+v", src)
+	}
+
+	for _, tc := range []struct{ filter, pipeline string }{
+		{`{"a":`, ""},
+		{"", `{"$match": {}}`},
+		{"", `[{"$match": {}}, {"$out": "copy"}]`},
+		{"", `[{"$merge": {"into": "copy"}}]`},
+	} {
+		if _, err := parseExportSource(tc.filter, tc.pipeline); err == nil {
+			t.Errorf("parseExportSource(%q, %q) expected error", tc.filter, tc.pipeline)
+		}
+	}
+}
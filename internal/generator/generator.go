@@ -0,0 +1,154 @@
+// Package generator seeds collections with fake documents built from templates.
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MaxGeneratedDocuments caps a single GenerateDocuments call.
+const MaxGeneratedDocuments = 1000000
+
+// generateBatchSize is the number of documents inserted per round trip.
+const generateBatchSize = 1000
+
+// Service handles fake data generation.
+type Service struct {
+	state *core.AppState
+}
+
+// NewService creates a new generator service.
+func NewService(state *core.AppState) *Service {
+	return &Service{state: state}
+}
+
+// GenerateDocuments inserts count documents built from templateJSON (see Template) into a
+// collection. Documents are inserted in batches, emitting "generate:progress" events tagged
+// with an operation ID that can be passed to CancelQuery to stop between batches.
+func (s *Service) GenerateDocuments(connID, dbName, collName, templateJSON string, count int) (*types.GenerateResult, error) {
+	if err := s.state.CheckWritable(connID, "generate documents"); err != nil {
+		return nil, err
+	}
+
+	if count <= 0 || count > MaxGeneratedDocuments {
+		return nil, fmt.Errorf("count must be between 1 and %d", MaxGeneratedDocuments)
+	}
+	tmpl, err := ParseTemplate(templateJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+
+	operationID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.state.SetQueryCancel(operationID, cancel)
+	defer s.state.ClearQueryCancel(operationID)
+	defer cancel()
+
+	debug.LogDocument("Generating documents", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"count":       count,
+		"operationId": operationID,
+	})
+
+	start := time.Now()
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	result := &types.GenerateResult{OperationID: operationID}
+	batch := make([]interface{}, 0, generateBatchSize)
+
+	for i := 0; i < count; i++ {
+		doc, err := tmpl.Generate(rng, i)
+		if err != nil {
+			return result, err
+		}
+		batch = append(batch, doc)
+		if len(batch) < generateBatchSize && i < count-1 {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			result.Cancelled = true
+			break
+		}
+		insertCtx, insertCancel := context.WithTimeout(ctx, s.state.QueryTimeout())
+		res, err := coll.InsertMany(insertCtx, batch)
+		insertCancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				result.Cancelled = true
+				break
+			}
+			return result, fmt.Errorf("failed to insert documents: %w", err)
+		}
+		result.Inserted += int64(len(res.InsertedIDs))
+		batch = batch[:0]
+
+		s.state.EmitConnectionEvent(connID, "generate:progress", types.GenerateProgress{
+			OperationID: operationID,
+			Inserted:    result.Inserted,
+			Total:       int64(count),
+		})
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	debug.LogDocument("Document generation finished", map[string]interface{}{
+		"database":    dbName,
+		"collection":  collName,
+		"inserted":    result.Inserted,
+		"cancelled":   result.Cancelled,
+		"operationId": operationID,
+	})
+
+	return result, nil
+}
+
+// PreviewDocuments expands a template n times without touching the database, so a template
+// can be checked before seeding. Documents are returned as Extended JSON.
+func PreviewDocuments(templateJSON string, n int) ([]string, error) {
+	if n <= 0 || n > 100 {
+		return nil, fmt.Errorf("preview size must be between 1 and 100")
+	}
+	tmpl, err := ParseTemplate(templateJSON)
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	docs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		doc, err := tmpl.Generate(rng, i)
+		if err != nil {
+			return nil, err
+		}
+		b, err := bson.MarshalExtJSON(doc, true, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal document: %w", err)
+		}
+		docs = append(docs, string(b))
+	}
+	return docs, nil
+}
+
+// RenderDocument expands a template once and returns the document as Extended JSON, for
+// filling in a new document from an insert template.
+func RenderDocument(templateJSON string) (string, error) {
+	docs, err := PreviewDocuments(templateJSON, 1)
+	if err != nil {
+		return "", err
+	}
+	return docs[0], nil
+}
@@ -0,0 +1,346 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// tokenPattern matches {{token arg ...}} placeholders in template strings.
+var tokenPattern = regexp.MustCompile(`\{\{\s*([A-Za-z]+)((?:\s+[^\s}]+)*)\s*\}\}`)
+
+// repeatPattern matches the {{repeat min max}} directive that opens a generated array.
+var repeatPattern = regexp.MustCompile(`^\{\{\s*repeat\s+(\d+)(?:\s+(\d+))?\s*\}\}$`)
+
+// MaxRepeat caps the number of elements a {{repeat}} directive may generate.
+const MaxRepeat = 1000
+
+// Template is a parsed document template. Any string value may contain tokens such as
+// {{name}}, {{email}}, {{int 1 100}}, or {{date 2020-01-01 2024-12-31}}. A string that is
+// exactly one token yields a typed value (int, date, ObjectId, ...); tokens embedded in longer
+// strings are replaced with their text. An array whose first element is "{{repeat min max}}"
+// becomes between min and max (at most MaxRepeat) copies of its second element. The type
+// placeholders {{string}}, {{number}}, {{array}}, {{object}}, and {{null}} yield empty values
+// of that type, for skeleton documents that are filled in by hand.
+type Template struct {
+	root bson.D
+}
+
+// ParseTemplate parses an Extended JSON document template and checks every token in it.
+func ParseTemplate(templateJSON string) (*Template, error) {
+	var root bson.D
+	if err := bson.UnmarshalExtJSON([]byte(templateJSON), true, &root); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	// Expanding once surfaces unknown tokens and bad arguments before anything is inserted
+	g := &expander{rng: rand.New(rand.NewSource(1)), validating: true}
+	if _, err := g.expand(root); err != nil {
+		return nil, err
+	}
+	return &Template{root: root}, nil
+}
+
+// Generate produces one document. index is exposed to the template as {{index}}.
+func (t *Template) Generate(rng *rand.Rand, index int) (bson.D, error) {
+	g := &expander{rng: rng, index: index}
+	v, err := g.expand(t.root)
+	if err != nil {
+		return nil, err
+	}
+	return v.(bson.D), nil
+}
+
+// expander holds the state for expanding one document.
+type expander struct {
+	rng        *rand.Rand
+	index      int
+	validating bool // Expand every repeat at least once so nothing goes unchecked
+}
+
+func (g *expander) expand(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case bson.D:
+		out := make(bson.D, 0, len(val))
+		for _, e := range val {
+			ev, err := g.expand(e.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Key, err)
+			}
+			out = append(out, bson.E{Key: e.Key, Value: ev})
+		}
+		return out, nil
+	case bson.A:
+		return g.expandArray(val)
+	case string:
+		return g.expandString(val)
+	}
+	return v, nil
+}
+
+func (g *expander) expandArray(arr bson.A) (interface{}, error) {
+	if len(arr) > 0 {
+		if s, ok := arr[0].(string); ok {
+			if m := repeatPattern.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+				if len(arr) != 2 {
+					return nil, fmt.Errorf("a repeat array needs exactly one element template after the directive")
+				}
+				lo, err := strconv.Atoi(m[1])
+				hi := lo
+				if err == nil && m[2] != "" {
+					hi, err = strconv.Atoi(m[2])
+				}
+				if err != nil || hi > MaxRepeat {
+					return nil, fmt.Errorf("repeat count cannot exceed %d", MaxRepeat)
+				}
+				if hi < lo {
+					return nil, fmt.Errorf("repeat max %d is less than min %d", hi, lo)
+				}
+				n := lo + g.rng.Intn(hi-lo+1)
+				if g.validating {
+					n = 1
+				}
+				out := make(bson.A, 0, n)
+				for i := 0; i < n; i++ {
+					ev, err := g.expand(arr[1])
+					if err != nil {
+						return nil, err
+					}
+					out = append(out, ev)
+				}
+				return out, nil
+			}
+		}
+	}
+	out := make(bson.A, 0, len(arr))
+	for _, item := range arr {
+		ev, err := g.expand(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+func (g *expander) expandString(s string) (interface{}, error) {
+	locs := tokenPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return s, nil
+	}
+	// A string that is exactly one token keeps the token's type
+	if len(locs) == 1 && locs[0][0] == 0 && locs[0][1] == len(s) {
+		return g.token(s[locs[0][2]:locs[0][3]], strings.Fields(s[locs[0][4]:locs[0][5]]))
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		b.WriteString(s[last:loc[0]])
+		v, err := g.token(s[loc[2]:loc[3]], strings.Fields(s[loc[4]:loc[5]]))
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(tokenText(v))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// token evaluates a single generator token.
+func (g *expander) token(name string, args []string) (interface{}, error) {
+	switch name {
+	case "index":
+		return int32(g.index), nil
+	case "string":
+		return "", nil
+	case "number":
+		return int32(0), nil
+	case "array":
+		return bson.A{}, nil
+	case "object":
+		return bson.D{}, nil
+	case "null":
+		return nil, nil
+	case "objectId":
+		return primitive.NewObjectID(), nil
+	case "uuid":
+		return uuid.New().String(), nil
+	case "bool":
+		return g.rng.Intn(2) == 1, nil
+	case "now":
+		return primitive.NewDateTimeFromTime(time.Now()), nil
+	case "int":
+		lo, hi, err := intRange(args, 0, 1000)
+		if err != nil {
+			return nil, fmt.Errorf("{{int}}: %w", err)
+		}
+		var n int64
+		if span := hi - lo + 1; span > 0 {
+			n = lo + g.rng.Int63n(span)
+		} else {
+			// The range has more values than int64 can count: draw from all 64 bits. The width
+			// wraps to 0 for the full int64 range, where every draw is in range.
+			r := g.rng.Uint64()
+			if width := uint64(hi) - uint64(lo) + 1; width != 0 {
+				r %= width
+			}
+			n = lo + int64(r)
+		}
+		if n >= -1<<31 && n < 1<<31 {
+			return int32(n), nil
+		}
+		return n, nil
+	case "double":
+		lo, hi, err := floatRange(args)
+		if err != nil {
+			return nil, fmt.Errorf("{{double}}: %w", err)
+		}
+		// Two decimal places reads like real data (prices, scores)
+		f := lo + g.rng.Float64()*(hi-lo)
+		return float64(int64(f*100)) / 100, nil
+	case "date":
+		from, to, err := dateRange(args)
+		if err != nil {
+			return nil, fmt.Errorf("{{date}}: %w", err)
+		}
+		span := to.Sub(from)
+		offset := time.Duration(0)
+		if span > 0 {
+			offset = time.Duration(g.rng.Int63n(int64(span)))
+		}
+		return primitive.NewDateTimeFromTime(from.Add(offset)), nil
+	case "pick":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("{{pick}} needs options, e.g. {{pick red|green|blue}}")
+		}
+		options := strings.Split(strings.Join(args, " "), "|")
+		return options[g.rng.Intn(len(options))], nil
+	case "firstName":
+		return g.pick(firstNames), nil
+	case "lastName":
+		return g.pick(lastNames), nil
+	case "name":
+		return g.pick(firstNames) + " " + g.pick(lastNames), nil
+	case "username":
+		return strings.ToLower(g.pick(firstNames)) + strconv.Itoa(g.rng.Intn(1000)), nil
+	case "email":
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(g.pick(firstNames)), strings.ToLower(g.pick(lastNames)),
+			g.rng.Intn(100), g.pick(emailDomains)), nil
+	case "phone":
+		return fmt.Sprintf("+1-%03d-%03d-%04d", 200+g.rng.Intn(800), g.rng.Intn(1000), g.rng.Intn(10000)), nil
+	case "company":
+		return g.pick(lastNames) + " " + g.pick(companySuffixes), nil
+	case "street":
+		return fmt.Sprintf("%d %s %s", 1+g.rng.Intn(9999), g.pick(lastNames), g.pick(streetSuffixes)), nil
+	case "city":
+		return g.pick(cities), nil
+	case "country":
+		return g.pick(countries), nil
+	case "word":
+		return g.pick(words), nil
+	case "sentence":
+		n := 4 + g.rng.Intn(8)
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = g.pick(words)
+		}
+		s := strings.Join(parts, " ")
+		return strings.ToUpper(s[:1]) + s[1:] + ".", nil
+	}
+	return nil, fmt.Errorf("unknown token {{%s}}", name)
+}
+
+func (g *expander) pick(list []string) string {
+	return list[g.rng.Intn(len(list))]
+}
+
+// tokenText renders a token value for interpolation into a longer string.
+func tokenText(v interface{}) string {
+	switch val := v.(type) {
+	case primitive.ObjectID:
+		return val.Hex()
+	case primitive.DateTime:
+		return val.Time().UTC().Format(time.RFC3339)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func intRange(args []string, defLo, defHi int64) (int64, int64, error) {
+	switch len(args) {
+	case 0:
+		return defLo, defHi, nil
+	case 2:
+		lo, err1 := strconv.ParseInt(args[0], 10, 64)
+		hi, err2 := strconv.ParseInt(args[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			return 0, 0, fmt.Errorf("expected integer bounds, got %q", strings.Join(args, " "))
+		}
+		if hi < lo {
+			return 0, 0, fmt.Errorf("max %d is less than min %d", hi, lo)
+		}
+		return lo, hi, nil
+	}
+	return 0, 0, fmt.Errorf("expected min and max")
+}
+
+func floatRange(args []string) (float64, float64, error) {
+	switch len(args) {
+	case 0:
+		return 0, 1000, nil
+	case 2:
+		lo, err1 := strconv.ParseFloat(args[0], 64)
+		hi, err2 := strconv.ParseFloat(args[1], 64)
+		if err1 != nil || err2 != nil {
+			return 0, 0, fmt.Errorf("expected numeric bounds, got %q", strings.Join(args, " "))
+		}
+		if hi < lo {
+			return 0, 0, fmt.Errorf("max %v is less than min %v", hi, lo)
+		}
+		return lo, hi, nil
+	}
+	return 0, 0, fmt.Errorf("expected min and max")
+}
+
+// dateRange parses optional from/to dates (YYYY-MM-DD or RFC 3339). Without arguments the
+// range is the past year; with one, it runs from that date to now.
+func dateRange(args []string) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	parse := func(s string) (time.Time, error) {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("expected a date like 2024-01-31, got %q", s)
+	}
+	switch len(args) {
+	case 0:
+		return now.AddDate(-1, 0, 0), now, nil
+	case 1, 2:
+		from, err := parse(args[0])
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to := now
+		if len(args) == 2 {
+			if to, err = parse(args[1]); err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+		}
+		if to.Before(from) {
+			return time.Time{}, time.Time{}, fmt.Errorf("end date is before start date")
+		}
+		return from, to, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("expected at most two dates")
+}
@@ -0,0 +1,225 @@
+package generator
+
+import (
+	"errors"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func generateOne(t *testing.T, templateJSON string) bson.M {
+	t.Helper()
+	tmpl, err := ParseTemplate(templateJSON)
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+	doc, err := tmpl.Generate(rand.New(rand.NewSource(42)), 7)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	raw, _ := bson.Marshal(doc)
+	var m bson.M
+	_ = bson.Unmarshal(raw, &m)
+	return m
+}
+
+func TestTemplate_TypedTokens(t *testing.T) {
+	doc := generateOne(t, `{
+		"_id": "{{objectId}}",
+		"n": "{{index}}",
+		"age": "{{int 18 65}}",
+		"score": "{{double 0 5}}",
+		"active": "{{bool}}",
+		"joined": "{{date 2020-01-01 2020-12-31}}",
+		"color": "{{pick red|green|blue}}",
+		"fixed": {"$numberLong": "5"}
+	}`)
+
+	if _, ok := doc["_id"].(primitive.ObjectID); !ok {
+		t.Errorf("_id: expected ObjectID, got %T", doc["_id"])
+	}
+	if doc["n"] != int32(7) {
+		t.Errorf("n: expected index 7, got %v", doc["n"])
+	}
+	if age, ok := doc["age"].(int32); !ok || age < 18 || age > 65 {
+		t.Errorf("age: expected int32 in [18, 65], got %T %v", doc["age"], doc["age"])
+	}
+	if score, ok := doc["score"].(float64); !ok || score < 0 || score > 5 {
+		t.Errorf("score: expected double in [0, 5], got %T %v", doc["score"], doc["score"])
+	}
+	if _, ok := doc["active"].(bool); !ok {
+		t.Errorf("active: expected bool, got %T", doc["active"])
+	}
+	joined, ok := doc["joined"].(primitive.DateTime)
+	if !ok || joined.Time().Year() != 2020 {
+		t.Errorf("joined: expected a 2020 date, got %T %v", doc["joined"], doc["joined"])
+	}
+	if c := doc["color"]; c != "red" && c != "green" && c != "blue" {
+		t.Errorf("color: unexpected %v", c)
+	}
+	if doc["fixed"] != int64(5) {
+		t.Errorf("fixed: expected Extended JSON literal to be kept, got %T %v", doc["fixed"], doc["fixed"])
+	}
+}
+
+func TestTemplate_WideIntRange(t *testing.T) {
+	tmpl, err := ParseTemplate(`{
+		"full": "{{int -9223372036854775808 9223372036854775807}}",
+		"wide": "{{int -9000000000000000000 9000000000000000000}}"
+	}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		doc, err := tmpl.Generate(rng, i)
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		m := doc.Map()
+		if n := toInt64(m["wide"]); n < -9000000000000000000 || n > 9000000000000000000 {
+			t.Fatalf("wide: %d out of range", n)
+		}
+	}
+}
+
+// toInt64 returns a generated int32 or int64 as int64.
+func toInt64(v interface{}) int64 {
+	if n, ok := v.(int32); ok {
+		return int64(n)
+	}
+	return v.(int64)
+}
+
+func TestTemplate_InterpolatesAndNests(t *testing.T) {
+	doc := generateOne(t, `{
+		"email": "{{email}}",
+		"label": "user-{{index}} ({{city}})",
+		"address": {"street": "{{street}}", "country": "{{country}}"},
+		"tags": ["{{repeat 2 4}}", "{{word}}"],
+		"orders": ["{{repeat 3}}", {"total": "{{int 1 10}}"}],
+		"plain": ["a", "b"]
+	}`)
+
+	if ok, _ := regexp.MatchString(`^[a-z]+\.[a-z]+\d+@[a-z.]+$`, doc["email"].(string)); !ok {
+		t.Errorf("email: unexpected %q", doc["email"])
+	}
+	if label := doc["label"].(string); !strings.HasPrefix(label, "user-7 (") {
+		t.Errorf("label: unexpected %q", label)
+	}
+	if addr, ok := doc["address"].(bson.M); !ok || addr["street"] == "" {
+		t.Errorf("address: unexpected %v", doc["address"])
+	}
+	if tags := doc["tags"].(bson.A); len(tags) < 2 || len(tags) > 4 {
+		t.Errorf("tags: expected 2-4 items, got %d", len(tags))
+	}
+	orders := doc["orders"].(bson.A)
+	if len(orders) != 3 {
+		t.Fatalf("orders: expected 3 items, got %d", len(orders))
+	}
+	if _, ok := orders[0].(bson.M)["total"].(int32); !ok {
+		t.Errorf("orders: expected generated subdocuments, got %v", orders[0])
+	}
+	if plain := doc["plain"].(bson.A); len(plain) != 2 || plain[0] != "a" {
+		t.Errorf("plain: expected array to be kept, got %v", plain)
+	}
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"invalid json", `{"a":`},
+		{"unknown token", `{"a": "{{nope}}"}`},
+		{"bad int range", `{"a": "{{int 10 1}}"}`},
+		{"bad int args", `{"a": "{{int x y}}"}`},
+		{"bad date", `{"a": "{{date yesterday}}"}`},
+		{"empty pick", `{"a": "{{pick}}"}`},
+		{"repeat without template", `{"a": ["{{repeat 2}}"]}`},
+		{"nested unknown token", `{"a": {"b": ["{{repeat 0 1}}", "x {{nope}}"]}}`},
+		{"repeat over limit", `{"a": ["{{repeat 1 5000}}", "x"]}`},
+		{"repeat count overflows", `{"a": ["{{repeat 99999999999999999999}}", "x"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTemplate(tt.template); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestPreviewDocuments(t *testing.T) {
+	docs, err := PreviewDocuments(`{"name": "{{name}}", "at": "{{now}}"}`, 3)
+	if err != nil {
+		t.Fatalf("PreviewDocuments failed: %v", err)
+	}
+	if len(docs) != 3 || !strings.Contains(docs[0], `"$date"`) {
+		t.Errorf("Unexpected preview: %v", docs)
+	}
+	if _, err := PreviewDocuments(`{}`, 0); err == nil {
+		t.Error("Expected error for zero preview size")
+	}
+}
+
+func TestRenderDocument_TypePlaceholders(t *testing.T) {
+	out, err := RenderDocument(`{"_id": "{{objectId}}", "name": "{{string}}", "qty": "{{number}}",
+		"tags": "{{array}}", "meta": "{{object}}", "deletedAt": "{{null}}", "label": "x{{null}}y"}`)
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+	var doc bson.M
+	if err := bson.UnmarshalExtJSON([]byte(out), true, &doc); err != nil {
+		t.Fatalf("Rendered document is not valid Extended JSON: %v", err)
+	}
+	if _, ok := doc["_id"].(primitive.ObjectID); !ok {
+		t.Errorf("_id: expected ObjectID, got %T", doc["_id"])
+	}
+	if doc["name"] != "" || doc["qty"] != int32(0) || doc["label"] != "xy" {
+		t.Errorf("Unexpected scalar placeholders: %v", doc)
+	}
+	if tags, ok := doc["tags"].(bson.A); !ok || len(tags) != 0 {
+		t.Errorf("tags: expected empty array, got %v", doc["tags"])
+	}
+	if meta, ok := doc["meta"].(bson.M); !ok || len(meta) != 0 {
+		t.Errorf("meta: expected empty object, got %v", doc["meta"])
+	}
+	if v, ok := doc["deletedAt"]; !ok || v != nil {
+		t.Errorf("deletedAt: expected null, got %v", v)
+	}
+}
+
+func TestDateRange_Defaults(t *testing.T) {
+	from, to, err := dateRange(nil)
+	if err != nil {
+		t.Fatalf("dateRange failed: %v", err)
+	}
+	if d := to.Sub(from); d < 364*24*time.Hour || d > 367*24*time.Hour {
+		t.Errorf("Expected a one-year default range, got %v", d)
+	}
+}
+
+func TestGenerateDocuments_Errors(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	if _, err := svc.GenerateDocuments("conn-1", "db", "coll", `{}`, 0); err == nil {
+		t.Error("Expected error for zero count")
+	}
+	if _, err := svc.GenerateDocuments("conn-1", "db", "coll", `{"a": "{{nope}}"}`, 10); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+
+	var notConnected *core.NotConnectedError
+	_, err := svc.GenerateDocuments("conn-1", "db", "coll", `{"a": "{{name}}"}`, 10)
+	if !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package generator
+
+// Word lists used by the faker-style template tokens.
+var (
+	firstNames = []string{
+		"Alice", "Bob", "Carlos", "Dana", "Elena", "Farid", "Grace", "Hiro", "Ines", "Jamal",
+		"Kara", "Liam", "Maya", "Noah", "Olga", "Priya", "Quinn", "Rosa", "Sven", "Tara",
+		"Umar", "Vera", "Wei", "Ximena", "Yusuf", "Zoe",
+	}
+	lastNames = []string{
+		"Anderson", "Brown", "Chen", "Diaz", "Evans", "Fischer", "Garcia", "Hansen", "Ito", "Johnson",
+		"Kowalski", "Lopez", "Martin", "Nakamura", "Okafor", "Patel", "Quigley", "Rossi", "Schmidt", "Taylor",
+		"Uddin", "Varga", "Williams", "Xu", "Young", "Zimmermann",
+	}
+	emailDomains    = []string{"example.com", "example.org", "example.net", "mail.test", "corp.test"}
+	companySuffixes = []string{"Inc", "LLC", "Group", "Labs", "Systems", "Partners", "Holdings"}
+	streetSuffixes  = []string{"Street", "Avenue", "Road", "Lane", "Boulevard", "Way", "Court"}
+	cities          = []string{
+		"Amsterdam", "Berlin", "Budapest", "Chicago", "Dublin", "Lisbon", "London", "Madrid",
+		"Nairobi", "Oslo", "Paris", "Seoul", "Singapore", "Sydney", "Tokyo", "Toronto",
+	}
+	countries = []string{
+		"Australia", "Brazil", "Canada", "France", "Germany", "Hungary", "India", "Ireland",
+		"Japan", "Kenya", "Netherlands", "Norway", "Portugal", "Spain", "United Kingdom", "United States",
+	}
+	words = []string{
+		"alpha", "bright", "cloud", "delta", "echo", "forest", "garden", "harbor", "island", "jade",
+		"kernel", "lunar", "meadow", "nova", "orbit", "pixel", "quartz", "river", "summit", "timber",
+		"urban", "valley", "willow", "xenon", "yield", "zephyr",
+	}
+)
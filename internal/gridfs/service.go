@@ -0,0 +1,209 @@
+// Package gridfs handles browsing and transferring files stored in GridFS buckets.
+package gridfs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// DefaultFileLimit is the number of files ListFiles returns when no limit is given.
+const DefaultFileLimit = 100
+
+// Service handles GridFS operations.
+type Service struct {
+	state *core.AppState
+}
+
+// NewService creates a new GridFS service.
+func NewService(state *core.AppState) *Service {
+	return &Service{state: state}
+}
+
+// ListBuckets returns the names of the GridFS buckets in a database, i.e. the prefixes
+// that have both a "<name>.files" and a "<name>.chunks" collection.
+func (s *Service) ListBuckets(connID, dbName string) ([]string, error) {
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	names, err := client.Database(dbName).ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return bucketNames(names), nil
+}
+
+// ListFiles returns files in a bucket, newest first. filter is an Extended JSON query on the
+// files collection (e.g. {"filename": {"$regex": "^report"}}); empty matches all files.
+func (s *Service) ListFiles(connID, dbName, bucketName, filter string, limit int) ([]types.GridFSFile, error) {
+	filterDoc, err := document.ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if limit <= 0 {
+		limit = DefaultFileLimit
+	}
+
+	bucket, err := s.bucket(connID, dbName, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	findOpts := options.GridFSFind().
+		SetSort(bson.D{{Key: "uploadDate", Value: -1}}).
+		SetLimit(int32(limit))
+	cursor, err := bucket.FindContext(ctx, filterDoc, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	files := []types.GridFSFile{}
+	for cursor.Next(ctx) {
+		var f gridfs.File
+		if err := cursor.Decode(&f); err != nil {
+			return nil, fmt.Errorf("failed to decode file: %w", err)
+		}
+		info, err := fileInfo(&f)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, info)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return files, nil
+}
+
+// DeleteFile removes a file and its chunks from a bucket.
+func (s *Service) DeleteFile(connID, dbName, bucketName, fileID string) error {
+	if err := s.state.CheckWritable(connID, "delete file"); err != nil {
+		return err
+	}
+
+	bucket, err := s.bucket(connID, dbName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	if err := bucket.DeleteContext(ctx, document.ParseDocumentID(fileID)); err != nil {
+		if err == gridfs.ErrFileNotFound {
+			return fmt.Errorf("file not found")
+		}
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	debug.LogDocument("GridFS file deleted", map[string]interface{}{
+		"database": dbName,
+		"bucket":   bucketName,
+		"fileId":   fileID,
+	})
+	return nil
+}
+
+// bucket opens a GridFS bucket on a connected client.
+func (s *Service) bucket(connID, dbName, bucketName string) (*gridfs.Bucket, error) {
+	if bucketName == "" {
+		bucketName = options.DefaultName
+	}
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := gridfs.NewBucket(client.Database(dbName), options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bucket: %w", err)
+	}
+	return bucket, nil
+}
+
+// findFile looks up a file's metadata by ID.
+func (s *Service) findFile(bucket *gridfs.Bucket, fileID interface{}) (*gridfs.File, error) {
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cursor, err := bucket.FindContext(ctx, bson.M{"_id": fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return nil, fmt.Errorf("failed to find file: %w", err)
+		}
+		return nil, fmt.Errorf("file not found")
+	}
+	var f gridfs.File
+	if err := cursor.Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to decode file: %w", err)
+	}
+	return &f, nil
+}
+
+// fileInfo converts driver file metadata for the frontend.
+func fileInfo(f *gridfs.File) (types.GridFSFile, error) {
+	id, err := document.MarshalValue(f.ID)
+	if err != nil {
+		return types.GridFSFile{}, fmt.Errorf("failed to marshal file id: %w", err)
+	}
+	info := types.GridFSFile{
+		ID:         id,
+		Filename:   f.Name,
+		Length:     f.Length,
+		ChunkSize:  f.ChunkSize,
+		UploadDate: f.UploadDate,
+	}
+	if len(f.Metadata) > 0 {
+		metadata, err := bson.MarshalExtJSON(f.Metadata, true, false)
+		if err != nil {
+			return types.GridFSFile{}, fmt.Errorf("failed to marshal file metadata: %w", err)
+		}
+		info.Metadata = string(metadata)
+	}
+	return info, nil
+}
+
+// bucketNames returns the sorted bucket prefixes with both a files and a chunks collection.
+func bucketNames(collections []string) []string {
+	existing := make(map[string]bool, len(collections))
+	for _, name := range collections {
+		existing[name] = true
+	}
+	buckets := []string{}
+	for _, name := range collections {
+		prefix, ok := strings.CutSuffix(name, ".files")
+		if ok && prefix != "" && existing[prefix+".chunks"] {
+			buckets = append(buckets, prefix)
+		}
+	}
+	sort.Strings(buckets)
+	return buckets
+}
+
+// isNotFound reports whether err means the requested file doesn't exist.
+func isNotFound(err error) bool {
+	return err == gridfs.ErrFileNotFound || err == mongo.ErrNoDocuments
+}
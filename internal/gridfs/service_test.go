@@ -0,0 +1,101 @@
+package gridfs
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestBucketNames(t *testing.T) {
+	got := bucketNames([]string{
+		"users", "fs.files", "fs.chunks", "images.chunks", "images.files",
+		"orphan.files", "logs.chunks", ".files", ".chunks",
+	})
+	want := []string{"fs", "images"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bucketNames() = %v, want %v", got, want)
+	}
+	if got := bucketNames(nil); len(got) != 0 {
+		t.Errorf("Expected no buckets, got %v", got)
+	}
+}
+
+func TestFileInfo(t *testing.T) {
+	oid := primitive.NewObjectID()
+	metadata, _ := bson.Marshal(bson.D{{Key: "owner", Value: "ann"}})
+	uploaded := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	info, err := fileInfo(&gridfs.File{
+		ID: oid, Length: 42, ChunkSize: 261120, UploadDate: uploaded, Name: "a.txt", Metadata: metadata,
+	})
+	if err != nil {
+		t.Fatalf("fileInfo failed: %v", err)
+	}
+	want := types.GridFSFile{
+		ID:         `{"$oid":"` + oid.Hex() + `"}`,
+		Filename:   "a.txt",
+		Length:     42,
+		ChunkSize:  261120,
+		UploadDate: uploaded,
+		Metadata:   `{"owner":"ann"}`,
+	}
+	if info != want {
+		t.Errorf("fileInfo() = %+v, want %+v", info, want)
+	}
+}
+
+func TestBuildPreview(t *testing.T) {
+	text := buildPreview(types.GridFSFile{Length: 5}, []byte("hello"))
+	if !text.IsText || text.Content != "hello" || text.Truncated {
+		t.Errorf("Unexpected text preview: %+v", text)
+	}
+	if text.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("Unexpected content type %q", text.ContentType)
+	}
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00")
+	bin := buildPreview(types.GridFSFile{Length: 100}, png)
+	if bin.IsText || bin.Content != base64.StdEncoding.EncodeToString(png) || !bin.Truncated {
+		t.Errorf("Unexpected binary preview: %+v", bin)
+	}
+	if bin.ContentType != "image/png" {
+		t.Errorf("Unexpected content type %q", bin.ContentType)
+	}
+
+	// A multi-byte character cut at the preview boundary still counts as text
+	cut := []byte("héllo")[:2]
+	partial := buildPreview(types.GridFSFile{Length: 6}, cut)
+	if !partial.IsText || partial.Content != "h" {
+		t.Errorf("Unexpected truncated text preview: %+v", partial)
+	}
+}
+
+func TestService_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+	var notConnected *core.NotConnectedError
+
+	if _, err := svc.ListBuckets("conn-1", "db"); !errors.As(err, &notConnected) {
+		t.Errorf("ListBuckets: expected NotConnectedError, got %v", err)
+	}
+	if _, err := svc.ListFiles("conn-1", "db", "fs", "", 0); !errors.As(err, &notConnected) {
+		t.Errorf("ListFiles: expected NotConnectedError, got %v", err)
+	}
+	if _, err := svc.ListFiles("conn-1", "db", "fs", `{"a":`, 0); err == nil {
+		t.Error("ListFiles: expected error for invalid filter")
+	}
+	if err := svc.DeleteFile("conn-1", "db", "fs", "x"); !errors.As(err, &notConnected) {
+		t.Errorf("DeleteFile: expected NotConnectedError, got %v", err)
+	}
+	if _, err := svc.UploadFile("conn-1", "db", "fs", `{"a":`); err == nil {
+		t.Error("UploadFile: expected error for invalid metadata")
+	}
+}
@@ -0,0 +1,193 @@
+package gridfs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MaxPreviewBytes is the number of bytes of a file PreviewFile returns.
+const MaxPreviewBytes = 512 * 1024
+
+// UploadFile asks for a local file with the native open dialog and stores it in a bucket.
+// metadataJSON is optional Extended JSON saved as the file's metadata. Returns nil if the user
+// cancels the dialog.
+func (s *Service) UploadFile(connID, dbName, bucketName, metadataJSON string) (*types.GridFSFile, error) {
+	if err := s.state.CheckWritable(connID, "upload file"); err != nil {
+		return nil, err
+	}
+
+	var uploadOpts *options.UploadOptions
+	if metadataJSON != "" {
+		var metadata bson.D
+		if err := bson.UnmarshalExtJSON([]byte(metadataJSON), true, &metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %w", err)
+		}
+		uploadOpts = options.GridFSUpload().SetMetadata(metadata)
+	}
+
+	bucket, err := s.bucket(connID, dbName, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath, err := runtime.OpenFileDialog(s.state.Ctx, runtime.OpenDialogOptions{
+		Title: "Select file to upload",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file dialog: %w", err)
+	}
+	if filePath == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	filename := filepath.Base(filePath)
+	var opts []*options.UploadOptions
+	if uploadOpts != nil {
+		opts = append(opts, uploadOpts)
+	}
+	id, err := bucket.UploadFromStream(filename, f, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	debug.LogDocument("GridFS file uploaded", map[string]interface{}{
+		"database": dbName,
+		"bucket":   bucketName,
+		"filename": filename,
+		"fileId":   id.Hex(),
+	})
+
+	stored, err := s.findFile(bucket, id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fileInfo(stored)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// DownloadFile asks for a destination with the native save dialog and writes a file from a
+// bucket to it. Returns an empty path if the user cancels the dialog.
+func (s *Service) DownloadFile(connID, dbName, bucketName, fileID string) (string, error) {
+	bucket, err := s.bucket(connID, dbName, bucketName)
+	if err != nil {
+		return "", err
+	}
+	file, err := s.findFile(bucket, document.ParseDocumentID(fileID))
+	if err != nil {
+		return "", err
+	}
+
+	filePath, err := runtime.SaveFileDialog(s.state.Ctx, runtime.SaveDialogOptions{
+		DefaultFilename: filepath.Base(file.Name),
+		Title:           "Download File",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if filePath == "" {
+		return "", nil
+	}
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := bucket.DownloadToStream(file.ID, out); err != nil {
+		out.Close()
+		os.Remove(filePath)
+		return "", fmt.Errorf("failed to download file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	debug.LogDocument("GridFS file downloaded", map[string]interface{}{
+		"database": dbName,
+		"bucket":   bucketName,
+		"fileId":   fileID,
+		"path":     filePath,
+	})
+	return filePath, nil
+}
+
+// PreviewFile returns up to MaxPreviewBytes of a file. Text is returned as-is; anything else
+// is base64 encoded so the frontend can render images and hex dumps.
+func (s *Service) PreviewFile(connID, dbName, bucketName, fileID string) (*types.GridFSPreview, error) {
+	bucket, err := s.bucket(connID, dbName, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := bucket.OpenDownloadStream(document.ParseDocumentID(fileID))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("file not found")
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(io.LimitReader(stream, MaxPreviewBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	info, err := fileInfo(stream.GetFile())
+	if err != nil {
+		return nil, err
+	}
+	return buildPreview(info, data), nil
+}
+
+// buildPreview detects the content type of a file's leading bytes and encodes them.
+func buildPreview(info types.GridFSFile, data []byte) *types.GridFSPreview {
+	preview := &types.GridFSPreview{
+		File:        info,
+		ContentType: http.DetectContentType(data),
+		Truncated:   int64(len(data)) < info.Length,
+	}
+	// A multi-byte rune may be cut at the preview boundary; ignore a partial trailing rune
+	text := data
+	if preview.Truncated {
+		for i := 0; i < utf8.UTFMax && len(text) > 0 && !utf8.Valid(text); i++ {
+			text = text[:len(text)-1]
+		}
+	}
+	if utf8.Valid(text) && !containsNUL(text) {
+		preview.IsText = true
+		preview.Content = string(text)
+	} else {
+		preview.Content = base64.StdEncoding.EncodeToString(data)
+	}
+	return preview
+}
+
+func containsNUL(b []byte) bool {
+	for _, c := range b {
+		if c == 0 {
+			return true
+		}
+	}
+	return false
+}
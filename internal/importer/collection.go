@@ -13,7 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -99,7 +99,7 @@ func (s *Service) DryRunImportCollections(connID, dbName string, opts types.Impo
 		return nil, fmt.Errorf("no source database specified")
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +154,7 @@ func (s *Service) DryRunImportCollections(connID, dbName string, opts types.Impo
 
 	for collName, file := range collectionFiles {
 		collIdx++
-		s.state.EmitEvent("import:progress", types.ImportProgress{
+		s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 			Phase:           "previewing",
 			Database:        dbName,
 			Collection:      collName,
@@ -170,7 +170,7 @@ func (s *Service) DryRunImportCollections(connID, dbName string, opts types.Impo
 
 		// Get current document count for override mode
 		if opts.Mode == "override" {
-			ctx, cancel := core.ContextWithTimeout()
+			ctx, cancel := s.state.ContextWithTimeout()
 			currentCount, _ := coll.EstimatedDocumentCount(ctx)
 			cancel()
 			collResult.CurrentCount = currentCount
@@ -205,7 +205,7 @@ func (s *Service) DryRunImportCollections(connID, dbName string, opts types.Impo
 
 		// For skip mode, check how many already exist
 		if opts.Mode == "skip" {
-			existingCount := countExistingIds(coll, allIDs)
+			existingCount := s.countExistingIds(coll, allIDs)
 			collResult.DocumentsSkipped = existingCount
 			collResult.DocumentsInserted = int64(len(allIDs)) - existingCount
 		} else {
@@ -230,6 +230,13 @@ func (s *Service) DryRunImportCollections(connID, dbName string, opts types.Impo
 
 // ImportCollections imports collections from a zip file into a single database.
 func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOptions) (*types.ImportResult, error) {
+	if err := s.state.CheckWritable(connID, "import"); err != nil {
+		return nil, err
+	}
+	if err := s.requireOverrideConfirmation(connID, opts.Mode, opts.FilePath); err != nil {
+		return nil, err
+	}
+
 	if opts.FilePath == "" {
 		return nil, fmt.Errorf("no file path specified")
 	}
@@ -237,10 +244,14 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 		return nil, fmt.Errorf("no source database specified")
 	}
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
+	writeConcern, err := document.ParseWriteConcern(opts.WriteConcern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write concern: %w", err)
+	}
 
 	filePath := opts.FilePath
 
@@ -275,7 +286,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 		Databases: []types.DatabaseImportResult{},
 	}
 
-	db := client.Database(dbName)
+	db := client.Database(dbName, options.Database().SetWriteConcern(writeConcern))
 	dbResult := types.DatabaseImportResult{
 		Name:        dbName,
 		Collections: []types.CollectionImportResult{},
@@ -320,12 +331,14 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 	// Calculate total docs for ETA from manifest
 	var totalDocs int64
 	collDocCounts := make(map[string]int64)
+	collOptions := make(map[string]string)
 	for _, db := range manifest.Databases {
 		if db.Name == opts.SourceDatabase {
 			for _, coll := range db.Collections {
 				if len(selectedColls) == 0 || selectedColls[coll.Name] {
 					totalDocs += coll.DocCount
 					collDocCounts[coll.Name] = coll.DocCount
+					collOptions[coll.Name] = coll.Options
 				}
 			}
 			break
@@ -346,7 +359,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 			remaining = append(remaining, collectionNames[i])
 		}
 		// For collection imports, remaining databases is just the current database with remaining collections
-		s.state.EmitEvent("import:error", types.ImportErrorResult{
+		s.state.EmitConnectionEvent(connID, "import:error", types.ImportErrorResult{
 			Error:            errMsg,
 			PartialResult:    *result,
 			FailedDatabase:   dbName,
@@ -374,14 +387,18 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 
 		// For override mode, drop the collection first
 		if opts.Mode == "override" {
-			ctx, cancel := core.ContextWithTimeout()
+			ctx, cancel := s.state.ContextWithTimeout()
 			coll.Drop(ctx)
 			cancel()
 		}
 
+		if err := s.createCollectionWithOptions(db, collName, collOptions[collName]); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to create %s.%s with its original options: %v", dbName, collName, err))
+		}
+
 		// Import documents
 		if files.docs != nil {
-			s.state.EmitEvent("import:progress", types.ImportProgress{
+			s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 				Phase:           "importing",
 				Database:        dbName,
 				Collection:      collName,
@@ -438,7 +455,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 
 				if len(batch) >= batchSize {
 					if opts.Mode == "skip" {
-						inserted, skipped, insertErr := insertBatchSkipDuplicates(coll, batch)
+						inserted, skipped, insertErr := s.insertBatchSkipDuplicates(coll, batch)
 						if insertErr != nil {
 							// Fatal error - save partial results and emit error event
 							collResult.DocumentsInserted += inserted
@@ -453,7 +470,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 						collResult.DocumentsInserted += inserted
 						collResult.DocumentsSkipped += skipped
 					} else {
-						ctx, cancel := core.ContextWithTimeout()
+						ctx, cancel := s.state.ContextWithTimeout()
 						res, insertErr := coll.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
 						cancel()
 						if insertErr != nil {
@@ -473,7 +490,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 					}
 					batch = batch[:0]
 
-					s.state.EmitEvent("import:progress", types.ImportProgress{
+					s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 						Phase:           "importing",
 						Database:        dbName,
 						Collection:      collName,
@@ -494,7 +511,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 			// Insert remaining batch
 			if len(batch) > 0 && !cancelled {
 				if opts.Mode == "skip" {
-					inserted, skipped, insertErr := insertBatchSkipDuplicates(coll, batch)
+					inserted, skipped, insertErr := s.insertBatchSkipDuplicates(coll, batch)
 					if insertErr != nil {
 						// Fatal error - save partial results and emit error event
 						collResult.DocumentsInserted += inserted
@@ -509,7 +526,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 					collResult.DocumentsInserted += inserted
 					collResult.DocumentsSkipped += skipped
 				} else {
-					ctx, cancel := core.ContextWithTimeout()
+					ctx, cancel := s.state.ContextWithTimeout()
 					res, insertErr := coll.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
 					cancel()
 					if insertErr != nil {
@@ -566,7 +583,7 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 					if sparse, ok := idx["sparse"].(bool); ok && sparse {
 						indexOpts.SetSparse(true)
 					}
-					ctx, cancel := core.ContextWithTimeout()
+					ctx, cancel := s.state.ContextWithTimeout()
 					_, indexErr := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
 						Keys:    keyDoc,
 						Options: indexOpts,
@@ -596,10 +613,10 @@ func (s *Service) ImportCollections(connID, dbName string, opts types.ImportOpti
 	}
 
 	if cancelled {
-		s.state.EmitEvent("import:cancelled", result)
+		s.state.EmitConnectionEvent(connID, "import:cancelled", result)
 		return result, fmt.Errorf("import cancelled")
 	}
 
-	s.state.EmitEvent("import:complete", result)
+	s.state.EmitConnectionEvent(connID, "import:complete", result)
 	return result, nil
 }
@@ -14,7 +14,9 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -271,6 +273,12 @@ func (s *Service) PreviewCSVFile(opts types.CSVImportPreviewOptions) (*types.CSV
 
 // ImportCSV imports a CSV file into a collection.
 func (s *Service) ImportCSV(connID, dbName, collName string, opts types.CSVImportOptions) (*types.ImportResult, error) {
+	if err := s.state.CheckWritable(connID, "import"); err != nil {
+		return nil, err
+	}
+	if err := s.requireOverrideConfirmation(connID, opts.Mode, dbName+"."+collName); err != nil {
+		return nil, err
+	}
 	return s.importCSVInternal(connID, dbName, collName, opts, false)
 }
 
@@ -280,16 +288,20 @@ func (s *Service) DryRunImportCSV(connID, dbName, collName string, opts types.CS
 }
 
 func (s *Service) importCSVInternal(connID, dbName, collName string, opts types.CSVImportOptions, dryRun bool) (*types.ImportResult, error) {
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
+	writeConcern, err := document.ParseWriteConcern(opts.WriteConcern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write concern: %w", err)
+	}
 
 	if opts.FilePath == "" {
 		return nil, fmt.Errorf("no file path specified")
 	}
 
-	db := client.Database(dbName)
+	db := client.Database(dbName, options.Database().SetWriteConcern(writeConcern))
 	coll := db.Collection(collName)
 
 	// Set up cancellation
@@ -387,7 +399,7 @@ func (s *Service) importCSVInternal(connID, dbName, collName string, opts types.
 		phase = "previewing"
 	}
 
-	s.state.EmitEvent("import:progress", types.ImportProgress{
+	s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 		Phase:           phase,
 		Database:        dbName,
 		Collection:      collName,
@@ -411,11 +423,11 @@ func (s *Service) importCSVInternal(connID, dbName, collName string, opts types.
 					ids[i] = m["_id"]
 				}
 			}
-			existing := countExistingIds(coll, ids)
+			existing := s.countExistingIds(coll, ids)
 			collResult.DocumentsSkipped += existing
 			collResult.DocumentsInserted += int64(len(batch)) - existing
 		} else {
-			inserted, skipped, err := insertBatchSkipDuplicates(coll, batch)
+			inserted, skipped, err := s.insertBatchSkipDuplicates(coll, batch)
 			if err != nil {
 				return err
 			}
@@ -504,7 +516,7 @@ func (s *Service) importCSVInternal(connID, dbName, collName string, opts types.
 
 		// Emit progress
 		if processedDocs%100 == 0 {
-			s.state.EmitEvent("import:progress", types.ImportProgress{
+			s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 				Phase:           phase,
 				Database:        dbName,
 				Collection:      collName,
@@ -527,7 +539,7 @@ func (s *Service) importCSVInternal(connID, dbName, collName string, opts types.
 	result.DocumentsParseError = collResult.DocumentsParseError
 
 	// Emit completion
-	s.state.EmitEvent("import:progress", types.ImportProgress{
+	s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 		Phase:           phase,
 		Database:        dbName,
 		Collection:      collName,
@@ -538,7 +550,7 @@ func (s *Service) importCSVInternal(connID, dbName, collName string, opts types.
 	})
 
 	if !dryRun {
-		s.state.EmitEvent("import:complete", result)
+		s.state.EmitConnectionEvent(connID, "import:complete", result)
 	}
 
 	return result, nil
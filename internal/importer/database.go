@@ -13,7 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -140,6 +140,9 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 	if len(databasesToCheck) == 0 {
 		return nil, fmt.Errorf("no databases selected for import")
 	}
+	if err := s.checkManifestDatabases(connID, databasesToCheck); err != nil {
+		return nil, err
+	}
 
 	result := &types.ImportResult{
 		Databases: []types.DatabaseImportResult{},
@@ -165,7 +168,7 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 		}
 
 		// Emit progress
-		s.state.EmitEvent("dryrun:progress", types.ExportProgress{
+		s.state.EmitConnectionEvent(connID, "dryrun:progress", types.ExportProgress{
 			Phase:         "analyzing",
 			Database:      dbName,
 			DatabaseIndex: dbIdx + 1,
@@ -175,7 +178,7 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 		// Override mode: count what currently exists (will be dropped)
 		if opts.Mode == "override" {
 			// Get list of collections currently in this database
-			ctx, cancel := core.ContextWithTimeout()
+			ctx, cancel := s.state.ContextWithTimeout()
 			collNames, err := db.ListCollectionNames(ctx, bson.M{})
 			cancel()
 			if err != nil {
@@ -189,7 +192,7 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 				if strings.HasPrefix(collName, "system.") {
 					continue
 				}
-				ctx, cancel := core.ContextWithTimeout()
+				ctx, cancel := s.state.ContextWithTimeout()
 				count, err := db.Collection(collName).CountDocuments(ctx, bson.M{})
 				cancel()
 				if err == nil {
@@ -205,7 +208,7 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 				var currentCount int64
 				for _, existingColl := range collNames {
 					if existingColl == collManifest.Name {
-						ctx, cancel := core.ContextWithTimeout()
+						ctx, cancel := s.state.ContextWithTimeout()
 						count, err := db.Collection(collManifest.Name).CountDocuments(ctx, bson.M{})
 						cancel()
 						if err == nil {
@@ -237,7 +240,7 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 				Name: collName,
 			}
 
-			s.state.EmitEvent("dryrun:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "dryrun:progress", types.ExportProgress{
 				Phase:         "analyzing",
 				Database:      dbName,
 				Collection:    collName,
@@ -293,14 +296,14 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 
 				// Check batch
 				if len(ids) >= batchSize {
-					existing := countExistingIds(coll, ids)
+					existing := s.countExistingIds(coll, ids)
 					collResult.DocumentsSkipped += existing
 					collResult.DocumentsInserted += int64(len(ids)) - existing
 					ids = ids[:0]
 				}
 
 				if current%1000 == 0 {
-					s.state.EmitEvent("dryrun:progress", types.ExportProgress{
+					s.state.EmitConnectionEvent(connID, "dryrun:progress", types.ExportProgress{
 						Phase:         "analyzing",
 						Database:      dbName,
 						Collection:    collName,
@@ -315,7 +318,7 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 
 			// Check remaining IDs
 			if len(ids) > 0 {
-				existing := countExistingIds(coll, ids)
+				existing := s.countExistingIds(coll, ids)
 				collResult.DocumentsSkipped += existing
 				collResult.DocumentsInserted += int64(len(ids)) - existing
 			}
@@ -328,12 +331,19 @@ func (s *Service) DryRunImport(connID string, opts types.ImportOptions) (*types.
 		result.Databases = append(result.Databases, dbResult)
 	}
 
-	s.state.EmitEvent("dryrun:complete", result)
+	s.state.EmitConnectionEvent(connID, "dryrun:complete", result)
 	return result, nil
 }
 
 // ImportDatabases imports selected databases from a zip file.
 func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*types.ImportResult, error) {
+	if err := s.state.CheckWritable(connID, "import"); err != nil {
+		return nil, err
+	}
+	if err := s.requireOverrideConfirmation(connID, opts.Mode, opts.FilePath); err != nil {
+		return nil, err
+	}
+
 	if opts.FilePath == "" {
 		return nil, fmt.Errorf("no file path specified")
 	}
@@ -342,6 +352,10 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 	if err != nil {
 		return nil, err
 	}
+	writeConcern, err := document.ParseWriteConcern(opts.WriteConcern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write concern: %w", err)
+	}
 
 	// Open zip file
 	zipReader, err := zip.OpenReader(opts.FilePath)
@@ -393,6 +407,9 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 	if len(databasesToImport) == 0 {
 		return nil, fmt.Errorf("no databases selected for import")
 	}
+	if err := s.checkManifestDatabases(connID, databasesToImport); err != nil {
+		return nil, err
+	}
 
 	// Calculate total docs for ETA
 	var totalDocs int64
@@ -422,7 +439,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 		for i := dbIdx; i < len(databasesToImport); i++ {
 			remaining = append(remaining, databasesToImport[i].Name)
 		}
-		s.state.EmitEvent("import:error", types.ImportErrorResult{
+		s.state.EmitConnectionEvent(connID, "import:error", types.ImportErrorResult{
 			Error:              errMsg,
 			PartialResult:      *result,
 			FailedDatabase:     failedDb,
@@ -436,13 +453,13 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 		// Check for cancellation
 		select {
 		case <-importCtx.Done():
-			s.state.EmitEvent("import:cancelled", result)
+			s.state.EmitConnectionEvent(connID, "import:cancelled", result)
 			return result, nil
 		default:
 		}
 
 		dbName := dbManifest.Name
-		db := client.Database(dbName)
+		db := client.Database(dbName, options.Database().SetWriteConcern(writeConcern))
 
 		// Track per-database results
 		dbResult := types.DatabaseImportResult{
@@ -452,7 +469,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 
 		// Override mode: drop the database first
 		if opts.Mode == "override" {
-			s.state.EmitEvent("import:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 				Phase:         "dropping",
 				Database:      dbName,
 				Collection:    "",
@@ -463,7 +480,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 				ProcessedDocs: processedDocs,
 				TotalDocs:     totalDocs,
 			})
-			ctx, cancel := core.ContextWithTimeout()
+			ctx, cancel := s.state.ContextWithTimeout()
 			if err := db.Drop(ctx); err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("failed to drop database %s: %v", dbName, err))
 			}
@@ -480,7 +497,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 			}
 
 			// Emit progress
-			s.state.EmitEvent("import:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 				Phase:         "importing",
 				Database:      dbName,
 				Collection:    collName,
@@ -492,6 +509,10 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 				TotalDocs:     totalDocs,
 			})
 
+			if err := s.createCollectionWithOptions(db, collName, collManifest.Options); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to create %s.%s with its original options: %v", dbName, collName, err))
+			}
+
 			// Import documents
 			ndjsonPath := fmt.Sprintf("%s/%s/documents.ndjson", dbName, collName)
 			ndjsonFile := fileMap[ndjsonPath]
@@ -554,7 +575,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 				// Both modes now just batch insert (override already dropped db, skip uses unordered insert)
 				batch = append(batch, doc)
 				if len(batch) >= batchSize {
-					inserted, skipped, insertErr := insertBatchSkipDuplicates(coll, batch)
+					inserted, skipped, insertErr := s.insertBatchSkipDuplicates(coll, batch)
 					if insertErr != nil {
 						// Fatal error - save partial results and emit error event
 						collResult.DocumentsInserted += inserted
@@ -575,7 +596,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 
 				current++
 				if current%1000 == 0 {
-					s.state.EmitEvent("import:progress", types.ExportProgress{
+					s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 						Phase:         "importing",
 						Database:      dbName,
 						Collection:    collName,
@@ -595,13 +616,13 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 				// Save partial collection result
 				dbResult.Collections = append(dbResult.Collections, collResult)
 				result.Databases = append(result.Databases, dbResult)
-				s.state.EmitEvent("import:cancelled", result)
+				s.state.EmitConnectionEvent(connID, "import:cancelled", result)
 				return result, nil
 			}
 
 			// Insert remaining batch
 			if len(batch) > 0 {
-				inserted, skipped, insertErr := insertBatchSkipDuplicates(coll, batch)
+				inserted, skipped, insertErr := s.insertBatchSkipDuplicates(coll, batch)
 				if insertErr != nil {
 					// Fatal error - save partial results and emit error event
 					collResult.DocumentsInserted += inserted
@@ -662,7 +683,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 								indexOpts.SetSparse(true)
 							}
 
-							ctx, cancel := core.ContextWithTimeout()
+							ctx, cancel := s.state.ContextWithTimeout()
 							_, indexErr := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
 								Keys:    keyDoc,
 								Options: indexOpts,
@@ -689,7 +710,7 @@ func (s *Service) ImportDatabases(connID string, opts types.ImportOptions) (*typ
 		result.Errors = append(result.Errors, fmt.Sprintf("%d document(s) failed to parse and were skipped", result.DocumentsParseError))
 	}
 
-	s.state.EmitEvent("import:complete", result)
+	s.state.EmitConnectionEvent(connID, "import:complete", result)
 	return result, nil
 }
 
@@ -751,6 +772,9 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 	if len(databasesToCheck) == 0 {
 		return nil, fmt.Errorf("no databases selected for import")
 	}
+	if err := s.checkManifestDatabases(connID, databasesToCheck); err != nil {
+		return nil, err
+	}
 
 	result := &types.ImportResult{
 		Databases: []types.DatabaseImportResult{},
@@ -775,7 +799,7 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 			Collections: []types.CollectionImportResult{},
 		}
 
-		s.state.EmitEvent("dryrun:progress", types.ExportProgress{
+		s.state.EmitConnectionEvent(connID, "dryrun:progress", types.ExportProgress{
 			Phase:         "analyzing",
 			Database:      dbName,
 			DatabaseIndex: dbIdx + 1,
@@ -794,7 +818,7 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 		if opts.Mode == "override" {
 			for _, collManifest := range collectionsToCheck {
 				var currentCount int64
-				ctx, cancel := core.ContextWithTimeout()
+				ctx, cancel := s.state.ContextWithTimeout()
 				count, err := db.Collection(collManifest.Name).CountDocuments(ctx, bson.M{})
 				cancel()
 				if err == nil {
@@ -825,7 +849,7 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 				Name: collName,
 			}
 
-			s.state.EmitEvent("dryrun:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "dryrun:progress", types.ExportProgress{
 				Phase:         "analyzing",
 				Database:      dbName,
 				Collection:    collName,
@@ -878,14 +902,14 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 				current++
 
 				if len(ids) >= batchSize {
-					existing := countExistingIds(coll, ids)
+					existing := s.countExistingIds(coll, ids)
 					collResult.DocumentsSkipped += existing
 					collResult.DocumentsInserted += int64(len(ids)) - existing
 					ids = ids[:0]
 				}
 
 				if current%1000 == 0 {
-					s.state.EmitEvent("dryrun:progress", types.ExportProgress{
+					s.state.EmitConnectionEvent(connID, "dryrun:progress", types.ExportProgress{
 						Phase:         "analyzing",
 						Database:      dbName,
 						Collection:    collName,
@@ -899,7 +923,7 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 			rc.Close()
 
 			if len(ids) > 0 {
-				existing := countExistingIds(coll, ids)
+				existing := s.countExistingIds(coll, ids)
 				collResult.DocumentsSkipped += existing
 				collResult.DocumentsInserted += int64(len(ids)) - existing
 			}
@@ -912,7 +936,7 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 		result.Databases = append(result.Databases, dbResult)
 	}
 
-	s.state.EmitEvent("dryrun:complete", result)
+	s.state.EmitConnectionEvent(connID, "dryrun:complete", result)
 	return result, nil
 }
 
@@ -920,6 +944,13 @@ func (s *Service) DryRunSelectiveImport(connID string, dbCollections map[string]
 // Unlike ImportDatabases which takes a list of database names, this takes a map of dbName→collectionNames
 // so users can pick individual collections within each database.
 func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[string][]string, opts types.ImportOptions) (*types.ImportResult, error) {
+	if err := s.state.CheckWritable(connID, "import"); err != nil {
+		return nil, err
+	}
+	if err := s.requireOverrideConfirmation(connID, opts.Mode, opts.FilePath); err != nil {
+		return nil, err
+	}
+
 	if opts.FilePath == "" {
 		return nil, fmt.Errorf("no file path specified")
 	}
@@ -928,6 +959,10 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 	if err != nil {
 		return nil, err
 	}
+	writeConcern, err := document.ParseWriteConcern(opts.WriteConcern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write concern: %w", err)
+	}
 
 	// Open zip file
 	zipReader, err := zip.OpenReader(opts.FilePath)
@@ -981,6 +1016,9 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 	if len(databasesToImport) == 0 {
 		return nil, fmt.Errorf("no databases selected for import")
 	}
+	if err := s.checkManifestDatabases(connID, databasesToImport); err != nil {
+		return nil, err
+	}
 
 	// Calculate total docs for ETA (only selected collections)
 	var totalDocs int64
@@ -1013,7 +1051,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 		for i := dbIdx; i < len(databasesToImport); i++ {
 			remaining = append(remaining, databasesToImport[i].Name)
 		}
-		s.state.EmitEvent("import:error", types.ImportErrorResult{
+		s.state.EmitConnectionEvent(connID, "import:error", types.ImportErrorResult{
 			Error:              errMsg,
 			PartialResult:      *result,
 			FailedDatabase:     failedDb,
@@ -1027,13 +1065,13 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 		// Check for cancellation
 		select {
 		case <-importCtx.Done():
-			s.state.EmitEvent("import:cancelled", result)
+			s.state.EmitConnectionEvent(connID, "import:cancelled", result)
 			return result, nil
 		default:
 		}
 
 		dbName := dbManifest.Name
-		db := client.Database(dbName)
+		db := client.Database(dbName, options.Database().SetWriteConcern(writeConcern))
 		collSet := selectedColls[dbName]
 
 		dbResult := types.DatabaseImportResult{
@@ -1052,7 +1090,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 		// Override mode: drop individual collections (not the whole database)
 		if opts.Mode == "override" {
 			for _, collManifest := range collectionsToImport {
-				s.state.EmitEvent("import:progress", types.ExportProgress{
+				s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 					Phase:         "dropping",
 					Database:      dbName,
 					Collection:    collManifest.Name,
@@ -1061,7 +1099,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 					ProcessedDocs: processedDocs,
 					TotalDocs:     totalDocs,
 				})
-				ctx, cancel := core.ContextWithTimeout()
+				ctx, cancel := s.state.ContextWithTimeout()
 				if err := db.Collection(collManifest.Name).Drop(ctx); err != nil {
 					result.Errors = append(result.Errors, fmt.Sprintf("failed to drop collection %s.%s: %v", dbName, collManifest.Name, err))
 				}
@@ -1077,7 +1115,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 				Name: collName,
 			}
 
-			s.state.EmitEvent("import:progress", types.ExportProgress{
+			s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 				Phase:         "importing",
 				Database:      dbName,
 				Collection:    collName,
@@ -1089,6 +1127,10 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 				TotalDocs:     totalDocs,
 			})
 
+			if err := s.createCollectionWithOptions(db, collName, collManifest.Options); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to create %s.%s with its original options: %v", dbName, collName, err))
+			}
+
 			ndjsonPath := fmt.Sprintf("%s/%s/documents.ndjson", dbName, collName)
 			ndjsonFile := fileMap[ndjsonPath]
 			if ndjsonFile == nil {
@@ -1144,7 +1186,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 
 				batch = append(batch, doc)
 				if len(batch) >= batchSize {
-					inserted, skipped, insertErr := insertBatchSkipDuplicates(coll, batch)
+					inserted, skipped, insertErr := s.insertBatchSkipDuplicates(coll, batch)
 					if insertErr != nil {
 						collResult.DocumentsInserted += inserted
 						collResult.DocumentsSkipped += skipped
@@ -1164,7 +1206,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 
 				current++
 				if current%1000 == 0 {
-					s.state.EmitEvent("import:progress", types.ExportProgress{
+					s.state.EmitConnectionEvent(connID, "import:progress", types.ExportProgress{
 						Phase:         "importing",
 						Database:      dbName,
 						Collection:    collName,
@@ -1182,12 +1224,12 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 			if cancelled {
 				dbResult.Collections = append(dbResult.Collections, collResult)
 				result.Databases = append(result.Databases, dbResult)
-				s.state.EmitEvent("import:cancelled", result)
+				s.state.EmitConnectionEvent(connID, "import:cancelled", result)
 				return result, nil
 			}
 
 			if len(batch) > 0 {
-				inserted, skipped, insertErr := insertBatchSkipDuplicates(coll, batch)
+				inserted, skipped, insertErr := s.insertBatchSkipDuplicates(coll, batch)
 				if insertErr != nil {
 					collResult.DocumentsInserted += inserted
 					collResult.DocumentsSkipped += skipped
@@ -1244,7 +1286,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 								indexOpts.SetSparse(true)
 							}
 
-							ctx, cancel := core.ContextWithTimeout()
+							ctx, cancel := s.state.ContextWithTimeout()
 							_, indexErr := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
 								Keys:    keyDoc,
 								Options: indexOpts,
@@ -1269,7 +1311,7 @@ func (s *Service) ImportSelectiveDatabases(connID string, dbCollections map[stri
 		result.Errors = append(result.Errors, fmt.Sprintf("%d document(s) failed to parse and were skipped", result.DocumentsParseError))
 	}
 
-	s.state.EmitEvent("import:complete", result)
+	s.state.EmitConnectionEvent(connID, "import:complete", result)
 	return result, nil
 }
 
@@ -12,6 +12,7 @@ import (
 
 	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/storage"
+	"github.com/peternagy/mongopal/internal/types"
 )
 
 // Service handles import operations.
@@ -28,6 +29,15 @@ func NewService(state *core.AppState, connStore *storage.ConnectionService) *Ser
 	}
 }
 
+// requireOverrideConfirmation consumes a confirmation token for an import that overwrites
+// existing documents. The target is the namespace of a file import or the archive path.
+func (s *Service) requireOverrideConfirmation(connID, mode, target string) error {
+	if mode != "override" {
+		return nil
+	}
+	return s.state.RequireConfirmation(connID, "override import", target)
+}
+
 // GetImportFilePath opens a file dialog for selecting import files and returns the chosen path.
 func (s *Service) GetImportFilePath() (string, error) {
 	filePath, err := runtime.OpenFileDialog(s.state.Ctx, runtime.OpenDialogOptions{
@@ -48,8 +58,8 @@ func (s *Service) GetImportFilePath() (string, error) {
 }
 
 // countExistingIds counts how many of the given IDs exist in the collection.
-func countExistingIds(coll *mongo.Collection, ids []interface{}) int64 {
-	ctx, cancel := core.ContextWithTimeout()
+func (s *Service) countExistingIds(coll *mongo.Collection, ids []interface{}) int64 {
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	count, err := coll.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}})
@@ -61,12 +71,12 @@ func countExistingIds(coll *mongo.Collection, ids []interface{}) int64 {
 
 // insertBatchSkipDuplicates inserts documents, skipping duplicates.
 // Returns inserted count, skipped count, and any fatal error (e.g., connection failure).
-func insertBatchSkipDuplicates(coll *mongo.Collection, batch []interface{}) (inserted, skipped int64, err error) {
+func (s *Service) insertBatchSkipDuplicates(coll *mongo.Collection, batch []interface{}) (inserted, skipped int64, err error) {
 	if len(batch) == 0 {
 		return 0, 0, nil
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	opts := options.InsertMany().SetOrdered(false)
@@ -84,3 +94,39 @@ func insertBatchSkipDuplicates(coll *mongo.Collection, batch []interface{}) (ins
 
 	return int64(len(result.InsertedIDs)), 0, nil
 }
+
+// createCollectionWithOptions creates a collection from the Options recorded in an export
+// manifest, so time-series, capped, and validated collections are not recreated as regular
+// collections by the first insert. Existing collections and empty options are left alone.
+func (s *Service) createCollectionWithOptions(db *mongo.Database, collName, optionsJSON string) error {
+	if optionsJSON == "" {
+		return nil
+	}
+	var collOpts bson.D
+	if err := bson.UnmarshalExtJSON([]byte(optionsJSON), true, &collOpts); err != nil {
+		return fmt.Errorf("invalid collection options: %w", err)
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	existing, err := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: collName}})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+	return db.RunCommand(ctx, append(bson.D{{Key: "create", Value: collName}}, collOpts...)).Err()
+}
+
+// checkManifestDatabases checks the databases an archive import writes to against the
+// connection's allow/deny lists.
+func (s *Service) checkManifestDatabases(connID string, dbs []types.ExportManifestDatabase) error {
+	for _, db := range dbs {
+		if err := s.state.CheckDatabaseAccess(connID, db.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -80,6 +82,12 @@ func (s *Service) PreviewJSONFile(filePath string) (*types.JSONImportPreview, er
 
 // ImportJSON imports a JSON/NDJSON file into a collection.
 func (s *Service) ImportJSON(connID, dbName, collName string, opts types.JSONImportOptions) (*types.ImportResult, error) {
+	if err := s.state.CheckWritable(connID, "import"); err != nil {
+		return nil, err
+	}
+	if err := s.requireOverrideConfirmation(connID, opts.Mode, dbName+"."+collName); err != nil {
+		return nil, err
+	}
 	return s.importJSONInternal(connID, dbName, collName, opts, false)
 }
 
@@ -89,10 +97,14 @@ func (s *Service) DryRunImportJSON(connID, dbName, collName string, opts types.J
 }
 
 func (s *Service) importJSONInternal(connID, dbName, collName string, opts types.JSONImportOptions, dryRun bool) (*types.ImportResult, error) {
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		return nil, err
 	}
+	writeConcern, err := document.ParseWriteConcern(opts.WriteConcern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write concern: %w", err)
+	}
 
 	if opts.FilePath == "" {
 		return nil, fmt.Errorf("no file path specified")
@@ -106,7 +118,7 @@ func (s *Service) importJSONInternal(connID, dbName, collName string, opts types
 		return nil, fmt.Errorf("unsupported format: %s (expected JSON or NDJSON)", format)
 	}
 
-	db := client.Database(dbName)
+	db := client.Database(dbName, options.Database().SetWriteConcern(writeConcern))
 	coll := db.Collection(collName)
 
 	// Set up cancellation
@@ -189,7 +201,7 @@ func (s *Service) importJSONInternal(connID, dbName, collName string, opts types
 		phase = "previewing"
 	}
 
-	s.state.EmitEvent("import:progress", types.ImportProgress{
+	s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 		Phase:           phase,
 		Database:        dbName,
 		Collection:      collName,
@@ -213,11 +225,11 @@ func (s *Service) importJSONInternal(connID, dbName, collName string, opts types
 					ids[i] = m["_id"]
 				}
 			}
-			existing := countExistingIds(coll, ids)
+			existing := s.countExistingIds(coll, ids)
 			collResult.DocumentsSkipped += existing
 			collResult.DocumentsInserted += int64(len(batch)) - existing
 		} else {
-			inserted, skipped, err := insertBatchSkipDuplicates(coll, batch)
+			inserted, skipped, err := s.insertBatchSkipDuplicates(coll, batch)
 			if err != nil {
 				return err
 			}
@@ -263,7 +275,7 @@ func (s *Service) importJSONInternal(connID, dbName, collName string, opts types
 
 		// Emit progress
 		if processedDocs%100 == 0 {
-			s.state.EmitEvent("import:progress", types.ImportProgress{
+			s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 				Phase:           phase,
 				Database:        dbName,
 				Collection:      collName,
@@ -310,7 +322,7 @@ func (s *Service) importJSONInternal(connID, dbName, collName string, opts types
 	result.DocumentsParseError = collResult.DocumentsParseError
 
 	// Emit completion
-	s.state.EmitEvent("import:progress", types.ImportProgress{
+	s.state.EmitConnectionEvent(connID, "import:progress", types.ImportProgress{
 		Phase:           phase,
 		Database:        dbName,
 		Collection:      collName,
@@ -321,7 +333,7 @@ func (s *Service) importJSONInternal(connID, dbName, collName string, opts types
 	})
 
 	if !dryRun {
-		s.state.EmitEvent("import:complete", result)
+		s.state.EmitConnectionEvent(connID, "import:complete", result)
 	}
 
 	return result, nil
@@ -2,10 +2,14 @@ package importer
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
 )
 
 func TestProcessNDJSON(t *testing.T) {
@@ -260,3 +264,34 @@ func TestProcessNDJSON_ExtendedJSON(t *testing.T) {
 		t.Error("expected Extended JSON $oid preserved")
 	}
 }
+
+func TestImportJSON_ReadOnly(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	svc := NewService(state, nil)
+
+	_, err := svc.ImportJSON("ro", "db", "coll", types.JSONImportOptions{FilePath: "data.json"})
+	var readOnly *core.ReadOnlyError
+	if !errors.As(err, &readOnly) {
+		t.Fatalf("Expected ReadOnlyError, got %v", err)
+	}
+}
+
+func TestImportJSON_OverrideNeedsConfirmation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "prod", RequireDeleteConfirmation: true}}
+	svc := NewService(state, nil)
+
+	_, err := svc.ImportJSON("prod", "db", "coll", types.JSONImportOptions{FilePath: "data.json", Mode: "override"})
+	var required *core.ConfirmationRequiredError
+	if !errors.As(err, &required) || required.Target != "db.coll" {
+		t.Fatalf("Expected ConfirmationRequiredError for db.coll, got %v", err)
+	}
+
+	// Skip mode doesn't overwrite anything, so it needs no confirmation
+	_, err = svc.ImportJSON("prod", "db", "coll", types.JSONImportOptions{FilePath: "data.json", Mode: "skip"})
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Expected NotConnectedError, got %v", err)
+	}
+}
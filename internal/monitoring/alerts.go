@@ -0,0 +1,169 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/connection"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// GetActiveAlerts returns the triggered, unresolved alerts of a connection, oldest first.
+func (s *Service) GetActiveAlerts(connID string) []types.Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alerts := make([]types.Alert, 0, len(s.alerts[connID]))
+	for _, a := range s.alerts[connID] {
+		alerts = append(alerts, *a)
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].TriggeredAt.Before(alerts[j].TriggeredAt) })
+	return alerts
+}
+
+// checkAlerts reads the metrics the connection's enabled alert rules watch and applies them.
+// Replication lag and disk usage are only read when a rule needs them.
+func (s *Service) checkAlerts(ctx context.Context, connID string, client *mongo.Client, sample types.MetricsSample) {
+	if s.alertRules == nil {
+		return
+	}
+	rules := s.alertRules.GetAlertRules(connID)
+	watched := make(map[string]bool)
+	for _, r := range rules {
+		if r.Enabled {
+			watched[r.Metric] = true
+		}
+	}
+
+	values := map[string]float64{}
+	if watched[types.AlertMetricConnections] {
+		values[types.AlertMetricConnections] = float64(sample.Connections.Current)
+	}
+	readCtx, cancel := context.WithTimeout(ctx, metricsTimeout)
+	defer cancel()
+	if watched[types.AlertMetricReplicationLag] {
+		if lag, err := readReplicationLag(readCtx, client); err == nil {
+			values[types.AlertMetricReplicationLag] = lag
+		}
+	}
+	if watched[types.AlertMetricDiskUsage] {
+		if usage, err := readDiskUsage(readCtx, client); err == nil {
+			values[types.AlertMetricDiskUsage] = usage
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	s.applyAlerts(connID, rules, values, sample.Time)
+}
+
+// applyAlerts triggers the alerts of rules whose metric is above the threshold and resolves
+// active alerts whose metric is back at or below it, or whose rule was removed or disabled.
+// Rules whose metric could not be read are left as they are.
+func (s *Service) applyAlerts(connID string, rules []types.AlertRule, values map[string]float64, now time.Time) {
+	var triggered, resolved []types.Alert
+
+	s.mu.Lock()
+	active := s.alerts[connID]
+	if active == nil {
+		active = make(map[string]*types.Alert)
+		s.alerts[connID] = active
+	}
+	enabled := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if !r.Enabled {
+			continue
+		}
+		enabled[r.ID] = true
+		value, ok := values[r.Metric]
+		if !ok {
+			continue
+		}
+		alert, isActive := active[r.ID]
+		switch {
+		case value > r.Threshold && !isActive:
+			alert = &types.Alert{
+				ConnectionID: connID,
+				RuleID:       r.ID,
+				Metric:       r.Metric,
+				Threshold:    r.Threshold,
+				Value:        value,
+				Message:      alertMessage(r.Metric, value, r.Threshold),
+				TriggeredAt:  now,
+			}
+			active[r.ID] = alert
+			triggered = append(triggered, *alert)
+		case value > r.Threshold:
+			alert.Value = value
+		case isActive:
+			alert.Value = value
+			alert.ResolvedAt = &now
+			delete(active, r.ID)
+			resolved = append(resolved, *alert)
+		}
+	}
+	for id, alert := range active {
+		if !enabled[id] {
+			alert.ResolvedAt = &now
+			delete(active, id)
+			resolved = append(resolved, *alert)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, a := range triggered {
+		debug.LogConnection("Alert triggered", map[string]interface{}{
+			"connectionId": connID,
+			"metric":       a.Metric,
+			"value":        a.Value,
+			"threshold":    a.Threshold,
+		})
+		s.state.EmitConnectionEvent(connID, "alert:triggered", a)
+	}
+	for _, a := range resolved {
+		s.state.EmitConnectionEvent(connID, "alert:resolved", a)
+	}
+}
+
+// alertMessage describes a metric that crossed its threshold.
+func alertMessage(metric string, value, threshold float64) string {
+	switch metric {
+	case types.AlertMetricConnections:
+		return fmt.Sprintf("%.0f connections open (threshold %.0f)", value, threshold)
+	case types.AlertMetricReplicationLag:
+		return fmt.Sprintf("Replication lag of %.0fs (threshold %.0fs)", value, threshold)
+	case types.AlertMetricDiskUsage:
+		return fmt.Sprintf("Disk %.1f%% full (threshold %.0f%%)", value, threshold)
+	}
+	return fmt.Sprintf("%s is %g (threshold %g)", metric, value, threshold)
+}
+
+// readReplicationLag returns the seconds the furthest member is behind the primary, or behind
+// the most recent optime while there is no primary.
+func readReplicationLag(ctx context.Context, client *mongo.Client) (float64, error) {
+	var status bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status); err != nil {
+		return 0, err
+	}
+	return connection.BuildReplicationLag("", status, time.Now()).MaxLagSeconds, nil
+}
+
+// readDiskUsage returns the percent of the data volume in use, from dbStats (4.4+).
+func readDiskUsage(ctx context.Context, client *mongo.Client) (float64, error) {
+	var stats struct {
+		FsUsedSize  float64 `bson:"fsUsedSize"`
+		FsTotalSize float64 `bson:"fsTotalSize"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&stats); err != nil {
+		return 0, err
+	}
+	if stats.FsTotalSize <= 0 {
+		return 0, fmt.Errorf("server does not report disk usage")
+	}
+	return stats.FsUsedSize / stats.FsTotalSize * 100, nil
+}
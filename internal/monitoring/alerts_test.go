@@ -0,0 +1,102 @@
+package monitoring
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// eventRecorder records the names of emitted events.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) Emit(eventName string, data interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, eventName)
+}
+
+func TestApplyAlerts(t *testing.T) {
+	state := core.NewAppState()
+	recorder := &eventRecorder{}
+	state.Emitter = recorder
+	s := NewService(state, nil)
+
+	rules := []types.AlertRule{
+		{ID: "conns", Metric: types.AlertMetricConnections, Threshold: 100, Enabled: true},
+		{ID: "lag", Metric: types.AlertMetricReplicationLag, Threshold: 10, Enabled: true},
+		{ID: "disk", Metric: types.AlertMetricDiskUsage, Threshold: 90, Enabled: false},
+	}
+	now := time.Now()
+
+	s.applyAlerts("conn-1", rules, map[string]float64{
+		types.AlertMetricConnections: 150,
+		types.AlertMetricDiskUsage:   99,
+	}, now)
+	active := s.GetActiveAlerts("conn-1")
+	if len(active) != 1 || active[0].RuleID != "conns" || active[0].Value != 150 {
+		t.Fatalf("expected the connections alert only, got %+v", active)
+	}
+	if !strings.Contains(active[0].Message, "150 connections") {
+		t.Errorf("message = %q", active[0].Message)
+	}
+
+	// Still above: no new event, value updated; unread lag leaves nothing changed
+	s.applyAlerts("conn-1", rules, map[string]float64{types.AlertMetricConnections: 180}, now.Add(time.Second))
+	if active := s.GetActiveAlerts("conn-1"); len(active) != 1 || active[0].Value != 180 || !active[0].TriggeredAt.Equal(now) {
+		t.Errorf("unexpected active alerts: %+v", active)
+	}
+
+	s.applyAlerts("conn-1", rules, map[string]float64{
+		types.AlertMetricConnections:    100,
+		types.AlertMetricReplicationLag: 30,
+	}, now.Add(2*time.Second))
+	if active := s.GetActiveAlerts("conn-1"); len(active) != 1 || active[0].RuleID != "lag" {
+		t.Errorf("expected the lag alert only, got %+v", active)
+	}
+
+	// Removing the rule resolves its alert
+	s.applyAlerts("conn-1", rules[:1], map[string]float64{types.AlertMetricConnections: 50}, now.Add(3*time.Second))
+	if active := s.GetActiveAlerts("conn-1"); len(active) != 0 {
+		t.Errorf("expected no active alerts, got %+v", active)
+	}
+
+	want := []string{"alert:triggered", "alert:triggered", "alert:resolved", "alert:resolved"}
+	if strings.Join(recorder.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", recorder.events, want)
+	}
+}
+
+func TestStopMetricsDropsAlerts(t *testing.T) {
+	state := core.NewAppState()
+	state.DisableEvents = true
+	s := NewService(state, nil)
+
+	rules := []types.AlertRule{{ID: "conns", Metric: types.AlertMetricConnections, Threshold: 1, Enabled: true}}
+	s.applyAlerts("conn-1", rules, map[string]float64{types.AlertMetricConnections: 5}, time.Now())
+	if len(s.GetActiveAlerts("conn-1")) != 1 {
+		t.Fatal("expected an active alert")
+	}
+	s.StopMetrics("conn-1")
+	if active := s.GetActiveAlerts("conn-1"); len(active) != 0 {
+		t.Errorf("expected alerts to be dropped, got %+v", active)
+	}
+}
+
+func TestAlertMessage(t *testing.T) {
+	tests := map[string]string{
+		alertMessage(types.AlertMetricReplicationLag, 42.4, 10): "Replication lag of 42s (threshold 10s)",
+		alertMessage(types.AlertMetricDiskUsage, 91.25, 90):     "Disk 91.2% full (threshold 90%)",
+	}
+	for got, want := range tests {
+		if got != want {
+			t.Errorf("message = %q, want %q", got, want)
+		}
+	}
+}
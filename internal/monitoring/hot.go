@@ -0,0 +1,233 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Sources of a hot collection report.
+const (
+	HotSourceTop       = "top"
+	HotSourceCollStats = "collStats"
+)
+
+// maxLatencyStatsCollections bounds how many collections the $collStats fallback reads.
+const maxLatencyStatsCollections = 500
+
+// commandNotFound is the server error code for an unknown command, as top is on mongos.
+const commandNotFound = 59
+
+// nsTimes are the cumulative time, in microseconds, and operation counts of a namespace.
+type nsTimes struct {
+	readUs, writeUs, totalUs int64
+	reads, writes, total     int64
+}
+
+// hotSnapshot is the reading the next hot collection report of a connection is compared to.
+type hotSnapshot struct {
+	at     time.Time
+	source string
+	times  map[string]nsTimes
+}
+
+// GetHotCollections returns the time a connection's server spent per collection, busiest
+// first. The figures cover the time since the previous call for the connection, or since the
+// server started on the first call. Servers report them with the top command; mongos, which
+// has no top, is read with $collStats latencyStats instead.
+func (s *Service) GetHotCollections(connID string) (*types.HotCollectionReport, error) {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	source := HotSourceTop
+	times, err := readTop(ctx, client)
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(commandNotFound) {
+		source = HotSourceCollStats
+		times, err = s.readLatencyStats(ctx, connID, client)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection statistics: %w", err)
+	}
+	for ns := range times {
+		db, _, _ := strings.Cut(ns, ".")
+		if !s.state.DatabaseAllowed(connID, db) {
+			delete(times, ns)
+		}
+	}
+
+	cur := &hotSnapshot{at: time.Now(), source: source, times: times}
+	s.mu.Lock()
+	prev := s.hot[connID]
+	s.hot[connID] = cur
+	s.mu.Unlock()
+	if prev != nil && prev.source != source {
+		prev = nil
+	}
+	return buildHotReport(connID, prev, cur), nil
+}
+
+// readTop reads the per-namespace totals of the top command.
+func readTop(ctx context.Context, client *mongo.Client) (map[string]nsTimes, error) {
+	var result struct {
+		Totals bson.M `bson:"totals"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "top", Value: 1}}).Decode(&result); err != nil {
+		return nil, err
+	}
+	return parseTop(result.Totals), nil
+}
+
+// parseTop converts the totals of a top reply, skipping its note and entries without a
+// collection.
+func parseTop(totals bson.M) map[string]nsTimes {
+	times := make(map[string]nsTimes, len(totals))
+	for ns, v := range totals {
+		doc, ok := v.(bson.M)
+		if !ok || !strings.Contains(ns, ".") {
+			continue
+		}
+		stat := func(name string) (int64, int64) {
+			m, _ := doc[name].(bson.M)
+			return bsonutil.ToInt64(m["time"]), bsonutil.ToInt64(m["count"])
+		}
+		var t nsTimes
+		t.readUs, t.reads = stat("readLock")
+		t.writeUs, t.writes = stat("writeLock")
+		t.totalUs, t.total = stat("total")
+		times[ns] = t
+	}
+	return times
+}
+
+// readLatencyStats reads the latencyStats of every collection the connection may access,
+// summed over shards.
+func (s *Service) readLatencyStats(ctx context.Context, connID string, client *mongo.Client) (map[string]nsTimes, error) {
+	dbNames, err := client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[string]nsTimes)
+	for _, db := range s.state.FilterDatabases(connID, dbNames) {
+		if db == "config" || db == "local" {
+			continue
+		}
+		names, err := client.Database(db).ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+		if err != nil {
+			continue
+		}
+		for _, coll := range names {
+			if len(times) >= maxLatencyStatsCollections {
+				return times, nil
+			}
+			pipeline := bson.A{bson.D{{Key: "$collStats", Value: bson.D{{Key: "latencyStats", Value: bson.D{}}}}}}
+			cursor, err := client.Database(db).Collection(coll).Aggregate(ctx, pipeline)
+			if err != nil {
+				continue
+			}
+			var docs []bson.M
+			if err := cursor.All(ctx, &docs); err != nil {
+				continue
+			}
+			var t nsTimes
+			for _, doc := range docs {
+				t = t.add(parseLatencyStats(doc))
+			}
+			times[db+"."+coll] = t
+		}
+	}
+	return times, nil
+}
+
+// parseLatencyStats converts the latencyStats of one $collStats document. Commands count
+// toward the total only.
+func parseLatencyStats(doc bson.M) nsTimes {
+	stats, _ := doc["latencyStats"].(bson.M)
+	stat := func(name string) (int64, int64) {
+		m, _ := stats[name].(bson.M)
+		return bsonutil.ToInt64(m["latency"]), bsonutil.ToInt64(m["ops"])
+	}
+	var t nsTimes
+	t.readUs, t.reads = stat("reads")
+	t.writeUs, t.writes = stat("writes")
+	commandUs, commands := stat("commands")
+	t.totalUs = t.readUs + t.writeUs + commandUs
+	t.total = t.reads + t.writes + commands
+	return t
+}
+
+// add returns the sum of two readings.
+func (t nsTimes) add(o nsTimes) nsTimes {
+	return nsTimes{
+		readUs: t.readUs + o.readUs, writeUs: t.writeUs + o.writeUs, totalUs: t.totalUs + o.totalUs,
+		reads: t.reads + o.reads, writes: t.writes + o.writes, total: t.total + o.total,
+	}
+}
+
+// sub returns the growth from an earlier reading, and false if a counter went backwards
+// (a server restart or a dropped collection).
+func (t nsTimes) sub(o nsTimes) (nsTimes, bool) {
+	d := nsTimes{
+		readUs: t.readUs - o.readUs, writeUs: t.writeUs - o.writeUs, totalUs: t.totalUs - o.totalUs,
+		reads: t.reads - o.reads, writes: t.writes - o.writes, total: t.total - o.total,
+	}
+	ok := d.readUs >= 0 && d.writeUs >= 0 && d.totalUs >= 0 && d.reads >= 0 && d.writes >= 0 && d.total >= 0
+	return d, ok
+}
+
+// buildHotReport compares a reading to the previous one, or reports it as is without one.
+// Namespaces without operations in the interval are left out.
+func buildHotReport(connID string, prev, cur *hotSnapshot) *types.HotCollectionReport {
+	report := &types.HotCollectionReport{
+		ConnectionID: connID,
+		Time:         cur.at,
+		Source:       cur.source,
+		Collections:  []types.HotCollection{},
+	}
+	if prev != nil {
+		report.IntervalSeconds = cur.at.Sub(prev.at).Seconds()
+	}
+	for ns, t := range cur.times {
+		if prev != nil {
+			if p, ok := prev.times[ns]; ok {
+				if d, ok := t.sub(p); ok {
+					t = d
+				}
+			}
+		}
+		if t.total == 0 {
+			continue
+		}
+		report.Collections = append(report.Collections, types.HotCollection{
+			Namespace:   ns,
+			ReadTimeMs:  float64(t.readUs) / 1000,
+			WriteTimeMs: float64(t.writeUs) / 1000,
+			TotalTimeMs: float64(t.totalUs) / 1000,
+			ReadCount:   t.reads,
+			WriteCount:  t.writes,
+			TotalCount:  t.total,
+		})
+	}
+	sort.Slice(report.Collections, func(i, j int) bool {
+		a, b := report.Collections[i], report.Collections[j]
+		if a.TotalTimeMs != b.TotalTimeMs {
+			return a.TotalTimeMs > b.TotalTimeMs
+		}
+		return a.Namespace < b.Namespace
+	})
+	return report
+}
@@ -0,0 +1,93 @@
+package monitoring
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+)
+
+func TestParseTop(t *testing.T) {
+	times := parseTop(bson.M{
+		"note": "all times in microseconds",
+		"shop.orders": bson.M{
+			"total":     bson.M{"time": int64(9000), "count": int64(30)},
+			"readLock":  bson.M{"time": int64(6000), "count": int64(20)},
+			"writeLock": bson.M{"time": int64(3000), "count": int64(10)},
+		},
+		"shop": bson.M{"total": bson.M{"time": int64(1), "count": int64(1)}},
+	})
+	if len(times) != 1 {
+		t.Fatalf("expected only shop.orders, got %v", times)
+	}
+	want := nsTimes{readUs: 6000, writeUs: 3000, totalUs: 9000, reads: 20, writes: 10, total: 30}
+	if times["shop.orders"] != want {
+		t.Errorf("times = %+v, want %+v", times["shop.orders"], want)
+	}
+}
+
+func TestParseLatencyStats(t *testing.T) {
+	got := parseLatencyStats(bson.M{"latencyStats": bson.M{
+		"reads":    bson.M{"latency": int64(500), "ops": int64(5)},
+		"writes":   bson.M{"latency": int64(300), "ops": int64(3)},
+		"commands": bson.M{"latency": int64(200), "ops": int64(2)},
+	}})
+	want := nsTimes{readUs: 500, writeUs: 300, totalUs: 1000, reads: 5, writes: 3, total: 10}
+	if got != want {
+		t.Errorf("times = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuildHotReport(t *testing.T) {
+	start := time.Now()
+	first := &hotSnapshot{at: start, source: HotSourceTop, times: map[string]nsTimes{
+		"shop.orders": {readUs: 1000, writeUs: 1000, totalUs: 2000, reads: 10, writes: 10, total: 20},
+		"shop.users":  {readUs: 5000, totalUs: 5000, reads: 5, total: 5},
+	}}
+
+	report := buildHotReport("conn-1", nil, first)
+	if report.IntervalSeconds != 0 || len(report.Collections) != 2 || report.Collections[0].Namespace != "shop.users" {
+		t.Fatalf("unexpected first report: %+v", report)
+	}
+
+	second := &hotSnapshot{at: start.Add(10 * time.Second), source: HotSourceTop, times: map[string]nsTimes{
+		"shop.orders": {readUs: 1500, writeUs: 9000, totalUs: 10500, reads: 12, writes: 20, total: 32},
+		"shop.users":  {readUs: 5000, totalUs: 5000, reads: 5, total: 5},
+		"shop.carts":  {readUs: 100, totalUs: 100, reads: 1, total: 1},
+	}}
+	report = buildHotReport("conn-1", first, second)
+	if report.IntervalSeconds != 10 {
+		t.Errorf("interval = %v, want 10", report.IntervalSeconds)
+	}
+	if len(report.Collections) != 2 {
+		t.Fatalf("idle shop.users should be left out: %+v", report.Collections)
+	}
+	orders := report.Collections[0]
+	if orders.Namespace != "shop.orders" || orders.TotalTimeMs != 8.5 || orders.WriteTimeMs != 8 || orders.TotalCount != 12 {
+		t.Errorf("unexpected orders delta: %+v", orders)
+	}
+	if carts := report.Collections[1]; carts.Namespace != "shop.carts" || carts.TotalTimeMs != 0.1 {
+		t.Errorf("new namespace should be reported as is: %+v", carts)
+	}
+
+	// A restart resets the counters; the new reading is reported as is
+	restarted := &hotSnapshot{at: start.Add(20 * time.Second), source: HotSourceTop, times: map[string]nsTimes{
+		"shop.orders": {readUs: 200, totalUs: 200, reads: 2, total: 2},
+	}}
+	report = buildHotReport("conn-1", second, restarted)
+	if len(report.Collections) != 1 || report.Collections[0].TotalCount != 2 {
+		t.Errorf("unexpected report after restart: %+v", report.Collections)
+	}
+}
+
+func TestGetHotCollections_NotConnected(t *testing.T) {
+	s := NewService(core.NewAppState(), nil)
+	_, err := s.GetHotCollections("missing")
+	var notConnected *core.NotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
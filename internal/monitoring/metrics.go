@@ -0,0 +1,279 @@
+// Package monitoring samples server statistics of connected servers for live dashboards.
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/storage"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+const (
+	// DefaultMetricsInterval is how often a metrics poller samples serverStatus by default.
+	DefaultMetricsInterval = 5 * time.Second
+	// MetricsHistorySize is how many samples are kept per connection.
+	MetricsHistorySize = 120
+	// minMetricsInterval keeps pollers from loading the server.
+	minMetricsInterval = time.Second
+	// metricsTimeout bounds a single serverStatus call.
+	metricsTimeout = 5 * time.Second
+)
+
+// serverStatus holds the serverStatus counters the metrics poller samples.
+type serverStatus struct {
+	Opcounters struct {
+		Insert  int64 `bson:"insert"`
+		Query   int64 `bson:"query"`
+		Update  int64 `bson:"update"`
+		Delete  int64 `bson:"delete"`
+		GetMore int64 `bson:"getmore"`
+		Command int64 `bson:"command"`
+	} `bson:"opcounters"`
+	Connections struct {
+		Current      int64 `bson:"current"`
+		Available    int64 `bson:"available"`
+		TotalCreated int64 `bson:"totalCreated"`
+	} `bson:"connections"`
+	Mem struct {
+		Resident int64 `bson:"resident"`
+		Virtual  int64 `bson:"virtual"`
+	} `bson:"mem"`
+	Network struct {
+		BytesIn     int64 `bson:"bytesIn"`
+		BytesOut    int64 `bson:"bytesOut"`
+		NumRequests int64 `bson:"numRequests"`
+	} `bson:"network"`
+
+	sampledAt time.Time
+}
+
+// metricsPoller is the running metrics poller of one connection.
+type metricsPoller struct {
+	cancel context.CancelFunc
+	prev   *serverStatus
+}
+
+// Service samples serverStatus of connected servers in the background and emits each sample
+// as a "metrics:sample" event. The latest samples are kept for GetMetricsHistory, also after
+// the poller stops. Each sample is checked against the connection's alert rules.
+type Service struct {
+	state      *core.AppState
+	alertRules *storage.AlertRuleService
+	status     func(ctx context.Context, client *mongo.Client) (*serverStatus, error)
+
+	mu      sync.Mutex
+	pollers map[string]*metricsPoller
+	history map[string][]types.MetricsSample
+	hot     map[string]*hotSnapshot
+	alerts  map[string]map[string]*types.Alert // Active alerts by connection and rule ID
+}
+
+// NewService creates a new monitoring service. Without alert rules, no alerts are raised.
+func NewService(state *core.AppState, alertRules *storage.AlertRuleService) *Service {
+	return &Service{
+		state:      state,
+		alertRules: alertRules,
+		status:     readServerStatus,
+		pollers:    make(map[string]*metricsPoller),
+		history:    make(map[string][]types.MetricsSample),
+		hot:        make(map[string]*hotSnapshot),
+		alerts:     make(map[string]map[string]*types.Alert),
+	}
+}
+
+// StartMetrics starts sampling a connection every intervalSeconds (the default if 0 or less,
+// at least one second). A running poller of the connection is replaced.
+func (s *Service) StartMetrics(connID string, intervalSeconds int) error {
+	if _, err := s.state.GetClient(connID); err != nil {
+		return err
+	}
+	interval := DefaultMetricsInterval
+	if intervalSeconds > 0 {
+		interval = max(time.Duration(intervalSeconds)*time.Second, minMetricsInterval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &metricsPoller{cancel: cancel}
+	s.mu.Lock()
+	if old, ok := s.pollers[connID]; ok {
+		old.cancel()
+	}
+	s.pollers[connID] = p
+	s.mu.Unlock()
+
+	debug.LogConnection("Metrics polling started", map[string]interface{}{
+		"connectionId": connID,
+		"interval":     interval.String(),
+	})
+	go s.run(ctx, connID, p, interval)
+	return nil
+}
+
+// StopMetrics stops sampling a connection and drops its active alerts, which can no longer
+// resolve. Stopping a connection that is not sampled does nothing.
+func (s *Service) StopMetrics(connID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.pollers[connID]; ok {
+		p.cancel()
+		delete(s.pollers, connID)
+	}
+	delete(s.alerts, connID)
+}
+
+// StopAll stops every metrics poller.
+func (s *Service) StopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, p := range s.pollers {
+		p.cancel()
+		delete(s.pollers, id)
+	}
+	clear(s.alerts)
+}
+
+// IsPolling reports whether a connection is being sampled.
+func (s *Service) IsPolling(connID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.pollers[connID]
+	return ok
+}
+
+// GetMetricsHistory returns the kept samples of a connection, oldest first.
+func (s *Service) GetMetricsHistory(connID string) []types.MetricsSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.MetricsSample{}, s.history[connID]...)
+}
+
+// run samples a connection every interval until ctx is cancelled or it disconnects.
+func (s *Service) run(ctx context.Context, connID string, p *metricsPoller, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if !s.poll(ctx, connID, p) {
+			s.mu.Lock()
+			if s.pollers[connID] == p {
+				delete(s.pollers, connID)
+				delete(s.alerts, connID)
+			}
+			s.mu.Unlock()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll takes one sample. It returns false when the connection is gone and polling should end.
+func (s *Service) poll(ctx context.Context, connID string, p *metricsPoller) bool {
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return false
+	}
+	statusCtx, cancel := context.WithTimeout(ctx, metricsTimeout)
+	defer cancel()
+	status, err := s.status(statusCtx, client)
+	if err != nil {
+		if ctx.Err() == nil {
+			debug.LogConnection("Metrics sample failed", map[string]interface{}{
+				"connectionId": connID,
+				"error":        err.Error(),
+			})
+		}
+		return ctx.Err() == nil
+	}
+
+	sample := buildSample(connID, p.prev, status)
+	p.prev = status
+	if ctx.Err() != nil {
+		return false
+	}
+	s.record(sample)
+	s.state.EmitConnectionEvent(connID, "metrics:sample", sample)
+	s.checkAlerts(ctx, connID, client, sample)
+	return true
+}
+
+// record adds a sample to its connection's history, dropping the oldest beyond
+// MetricsHistorySize.
+func (s *Service) record(sample types.MetricsSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := append(s.history[sample.ConnectionID], sample)
+	if len(h) > MetricsHistorySize {
+		h = h[len(h)-MetricsHistorySize:]
+	}
+	s.history[sample.ConnectionID] = h
+}
+
+// readServerStatus runs serverStatus without its larger sections.
+func readServerStatus(ctx context.Context, client *mongo.Client) (*serverStatus, error) {
+	cmd := bson.D{
+		{Key: "serverStatus", Value: 1},
+		{Key: "repl", Value: 0},
+		{Key: "metrics", Value: 0},
+		{Key: "locks", Value: 0},
+		{Key: "wiredTiger", Value: 0},
+	}
+	var status serverStatus
+	if err := client.Database("admin").RunCommand(ctx, cmd).Decode(&status); err != nil {
+		return nil, err
+	}
+	status.sampledAt = time.Now()
+	return &status, nil
+}
+
+// buildSample converts a serverStatus reading to a sample, with rates computed against the
+// previous reading. Counters that went backwards (a server restart) give no rate.
+func buildSample(connID string, prev, cur *serverStatus) types.MetricsSample {
+	sample := types.MetricsSample{
+		ConnectionID: connID,
+		Time:         cur.sampledAt,
+		Connections: types.ServerConnections{
+			Current:      cur.Connections.Current,
+			Available:    cur.Connections.Available,
+			TotalCreated: cur.Connections.TotalCreated,
+		},
+		Memory: types.ServerMemory{ResidentMB: cur.Mem.Resident, VirtualMB: cur.Mem.Virtual},
+	}
+	if prev == nil {
+		return sample
+	}
+	elapsed := cur.sampledAt.Sub(prev.sampledAt).Seconds()
+	if elapsed <= 0 {
+		return sample
+	}
+	rate := func(before, after int64) float64 {
+		if after < before {
+			return 0
+		}
+		return float64(after-before) / elapsed
+	}
+	po, co := prev.Opcounters, cur.Opcounters
+	sample.Opcounters = types.OpcounterRates{
+		Insert:  rate(po.Insert, co.Insert),
+		Query:   rate(po.Query, co.Query),
+		Update:  rate(po.Update, co.Update),
+		Delete:  rate(po.Delete, co.Delete),
+		GetMore: rate(po.GetMore, co.GetMore),
+		Command: rate(po.Command, co.Command),
+	}
+	sample.Network = types.NetworkRates{
+		BytesInPerSec:  rate(prev.Network.BytesIn, cur.Network.BytesIn),
+		BytesOutPerSec: rate(prev.Network.BytesOut, cur.Network.BytesOut),
+		RequestsPerSec: rate(prev.Network.NumRequests, cur.Network.NumRequests),
+	}
+	return sample
+}
@@ -0,0 +1,120 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestBuildSample(t *testing.T) {
+	start := time.Now()
+	prev := &serverStatus{sampledAt: start}
+	prev.Opcounters.Insert = 100
+	prev.Opcounters.Query = 50
+	prev.Network.BytesIn = 1000
+	prev.Network.NumRequests = 10
+
+	cur := &serverStatus{sampledAt: start.Add(2 * time.Second)}
+	cur.Opcounters.Insert = 120
+	cur.Opcounters.Query = 40 // Reset by a restart
+	cur.Network.BytesIn = 5000
+	cur.Network.NumRequests = 30
+	cur.Connections.Current = 7
+	cur.Mem.Resident = 512
+
+	first := buildSample("conn-1", nil, prev)
+	if first.Opcounters != (types.OpcounterRates{}) || first.Time != start {
+		t.Errorf("first sample should have no rates: %+v", first)
+	}
+
+	sample := buildSample("conn-1", prev, cur)
+	if sample.Opcounters.Insert != 10 || sample.Opcounters.Query != 0 {
+		t.Errorf("opcounter rates = %+v", sample.Opcounters)
+	}
+	if sample.Network.BytesInPerSec != 2000 || sample.Network.RequestsPerSec != 10 {
+		t.Errorf("network rates = %+v", sample.Network)
+	}
+	if sample.Connections.Current != 7 || sample.Memory.ResidentMB != 512 {
+		t.Errorf("gauges not copied: %+v", sample)
+	}
+}
+
+func TestMetricsPolling(t *testing.T) {
+	state := core.NewAppState()
+	state.DisableEvents = true
+	s := NewService(state, nil)
+
+	var mu sync.Mutex
+	calls := 0
+	s.status = func(ctx context.Context, client *mongo.Client) (*serverStatus, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		status := &serverStatus{sampledAt: time.Now()}
+		status.Opcounters.Insert = int64(calls * 10)
+		return status, nil
+	}
+
+	var notConnected *core.NotConnectedError
+	if err := s.StartMetrics("conn-1", 1); !errors.As(err, &notConnected) {
+		t.Fatalf("expected NotConnectedError, got %v", err)
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	state.SetClient("conn-1", client)
+	if err := s.StartMetrics("conn-1", 1); err != nil {
+		t.Fatalf("StartMetrics: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(s.GetMetricsHistory("conn-1")) < 2 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	history := s.GetMetricsHistory("conn-1")
+	if len(history) < 2 || history[1].Opcounters.Insert <= 0 {
+		t.Fatalf("expected two samples with rates, got %+v", history)
+	}
+
+	s.StopMetrics("conn-1")
+	if s.IsPolling("conn-1") {
+		t.Error("poller still running after StopMetrics")
+	}
+	if len(s.GetMetricsHistory("conn-1")) == 0 {
+		t.Error("history should be kept after stopping")
+	}
+
+	// A poller ends by itself once its connection is gone
+	if err := s.StartMetrics("conn-1", 1); err != nil {
+		t.Fatalf("StartMetrics: %v", err)
+	}
+	state.RemoveClient("conn-1")
+	deadline = time.Now().Add(3 * time.Second)
+	for s.IsPolling("conn-1") && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if s.IsPolling("conn-1") {
+		t.Error("poller should stop after disconnect")
+	}
+}
+
+func TestMetricsHistoryLimit(t *testing.T) {
+	s := NewService(core.NewAppState(), nil)
+	for i := 0; i < MetricsHistorySize+5; i++ {
+		s.record(types.MetricsSample{ConnectionID: "conn-1", Connections: types.ServerConnections{Current: int64(i)}})
+	}
+	history := s.GetMetricsHistory("conn-1")
+	if len(history) != MetricsHistorySize || history[0].Connections.Current != 5 {
+		t.Errorf("history has %d samples starting at %d", len(history), history[0].Connections.Current)
+	}
+}
@@ -0,0 +1,177 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// maxOpCommandLength caps the command shown for an operation; inserts and large pipelines
+// can carry megabytes of documents.
+const maxOpCommandLength = 4096
+
+// ListCurrentOperations returns the operations in progress on a connection's server from the
+// $currentOp aggregation stage, longest running first. Operations on databases the connection
+// may not access are left out.
+func (s *Service) ListCurrentOperations(connID string, filter types.CurrentOpFilter) ([]types.CurrentOperation, error) {
+	var match bson.M
+	if m := strings.TrimSpace(filter.Match); m != "" && m != "{}" {
+		if err := bsonutil.UnmarshalExtJSON([]byte(m), true, &match); err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.D{{Key: "$currentOp", Value: bson.D{
+			{Key: "allUsers", Value: filter.AllUsers},
+			{Key: "idleConnections", Value: filter.IdleConnections},
+			{Key: "idleSessions", Value: filter.IdleConnections},
+		}}},
+	}
+	if len(match) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: match}})
+	}
+	cursor, err := client.Database("admin").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list current operations: %w", err)
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to list current operations: %w", err)
+	}
+
+	ops := make([]types.CurrentOperation, 0, len(docs))
+	for _, doc := range docs {
+		op := parseCurrentOp(doc)
+		if s.operationAllowed(connID, op.Namespace) {
+			ops = append(ops, op)
+		}
+	}
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].DurationMs > ops[j].DurationMs })
+	return ops, nil
+}
+
+// KillOperation kills an operation by the opId ListCurrentOperations returned. On a connection
+// with database restrictions, only operations ListCurrentOperations would show can be killed.
+func (s *Service) KillOperation(connID, opID string) error {
+	if err := s.state.CheckWritable(connID, "kill operation"); err != nil {
+		return err
+	}
+	opID = strings.TrimSpace(opID)
+	if opID == "" {
+		return fmt.Errorf("operation ID is required")
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	// mongod opids are numbers; mongos prefixes them with the shard name
+	var op interface{} = opID
+	if n, err := strconv.ParseInt(opID, 10, 64); err == nil {
+		op = n
+	}
+
+	if s.state.HasDatabaseRestrictions(connID) {
+		pipeline := bson.A{
+			bson.D{{Key: "$currentOp", Value: bson.D{{Key: "allUsers", Value: true}, {Key: "idleConnections", Value: true}}}},
+			bson.D{{Key: "$match", Value: bson.M{"opid": op}}},
+		}
+		cursor, err := client.Database("admin").Aggregate(ctx, pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to look up operation %s: %w", opID, err)
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return fmt.Errorf("failed to look up operation %s: %w", opID, err)
+		}
+		if len(docs) == 0 {
+			return fmt.Errorf("operation %s not found", opID)
+		}
+		if ns := bsonutil.ToString(docs[0]["ns"]); !s.operationAllowed(connID, ns) {
+			return fmt.Errorf("operation %s is not on a database this connection may access", opID)
+		}
+	}
+
+	cmd := bson.D{{Key: "killOp", Value: 1}, {Key: "op", Value: op}}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("failed to kill operation %s: %w", opID, err)
+	}
+
+	debug.LogConnection("Killed operation", map[string]interface{}{
+		"connectionId": connID,
+		"opId":         opID,
+	})
+	return nil
+}
+
+// operationAllowed reports whether an operation's namespace is in a database the connection
+// may access. Operations without a namespace are hidden on connections with restrictions.
+func (s *Service) operationAllowed(connID, ns string) bool {
+	db, _, _ := strings.Cut(ns, ".")
+	if db == "" {
+		return !s.state.HasDatabaseRestrictions(connID)
+	}
+	return s.state.DatabaseAllowed(connID, db)
+}
+
+// parseCurrentOp converts a $currentOp document to an operation.
+func parseCurrentOp(doc bson.M) types.CurrentOperation {
+	op := types.CurrentOperation{
+		Type:        bsonutil.ToString(doc["type"]),
+		Op:          bsonutil.ToString(doc["op"]),
+		Namespace:   bsonutil.ToString(doc["ns"]),
+		Active:      bsonutil.ToBool(doc["active"]),
+		DurationMs:  bsonutil.ToInt64(doc["microsecs_running"]) / 1000,
+		PlanSummary: bsonutil.ToString(doc["planSummary"]),
+		Client:      bsonutil.ToString(doc["client"]),
+		AppName:     bsonutil.ToString(doc["appName"]),
+		Host:        bsonutil.ToString(doc["host"]),
+		Shard:       bsonutil.ToString(doc["shard"]),
+		Description: bsonutil.ToString(doc["desc"]),
+		WaitingLock: bsonutil.ToBool(doc["waitingForLock"]),
+	}
+	switch id := doc["opid"].(type) {
+	case string:
+		op.OpID = id
+	case nil:
+	default:
+		op.OpID = strconv.FormatInt(bsonutil.ToInt64(id), 10)
+	}
+	if op.Client == "" {
+		op.Client = bsonutil.ToString(doc["client_s"]) // mongos
+	}
+	if users, ok := doc["effectiveUsers"].(bson.A); ok && len(users) > 0 {
+		if u, ok := users[0].(bson.M); ok {
+			op.User = bsonutil.ToString(u["user"]) + "@" + bsonutil.ToString(u["db"])
+		}
+	}
+	if cmd, ok := doc["command"].(bson.M); ok {
+		if data, err := bson.MarshalExtJSON(cmd, false, false); err == nil {
+			if len(data) > maxOpCommandLength {
+				data = append(data[:maxOpCommandLength], "…"...)
+			}
+			op.Command = string(data)
+		}
+	}
+	return op
+}
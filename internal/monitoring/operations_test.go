@@ -0,0 +1,103 @@
+package monitoring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseCurrentOp(t *testing.T) {
+	op := parseCurrentOp(bson.M{
+		"opid":              int32(4242),
+		"type":              "op",
+		"op":                "query",
+		"ns":                "shop.orders",
+		"active":            true,
+		"microsecs_running": int64(2_500_000),
+		"planSummary":       "COLLSCAN",
+		"client":            "10.0.0.5:51234",
+		"appName":           "reporting",
+		"effectiveUsers":    bson.A{bson.M{"user": "alice", "db": "admin"}},
+		"command":           bson.M{"find": "orders", "filter": bson.M{"total": bson.M{"$gt": 100}}},
+	})
+	if op.OpID != "4242" || op.DurationMs != 2500 || op.PlanSummary != "COLLSCAN" {
+		t.Errorf("unexpected operation: %+v", op)
+	}
+	if op.User != "alice@admin" || op.Client != "10.0.0.5:51234" || !op.Active {
+		t.Errorf("unexpected client fields: %+v", op)
+	}
+	if !strings.Contains(op.Command, `"find":"orders"`) {
+		t.Errorf("command = %s", op.Command)
+	}
+
+	mongos := parseCurrentOp(bson.M{
+		"opid":     "shard01:1234",
+		"client_s": "10.0.0.6:40000",
+		"command":  bson.M{"insert": "logs", "documents": strings.Repeat("x", 2*maxOpCommandLength)},
+	})
+	if mongos.OpID != "shard01:1234" || mongos.Client != "10.0.0.6:40000" {
+		t.Errorf("unexpected mongos operation: %+v", mongos)
+	}
+	if !strings.HasSuffix(mongos.Command, "…") || len(mongos.Command) > maxOpCommandLength+len("…") {
+		t.Errorf("command not truncated: %d bytes", len(mongos.Command))
+	}
+}
+
+func TestCurrentOperationsValidation(t *testing.T) {
+	s := NewService(core.NewAppState(), nil)
+
+	if _, err := s.ListCurrentOperations("conn-1", types.CurrentOpFilter{Match: "{bad"}); err == nil || !strings.Contains(err.Error(), "invalid filter") {
+		t.Errorf("expected invalid filter error, got %v", err)
+	}
+	var notConnected *core.NotConnectedError
+	if _, err := s.ListCurrentOperations("conn-1", types.CurrentOpFilter{Match: `{"active": true}`}); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+	if err := s.KillOperation("conn-1", " "); err == nil {
+		t.Error("expected error for empty operation ID")
+	}
+	if err := s.KillOperation("conn-1", "123"); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
+
+func TestKillOperation_RejectedOnReadOnlyConnection(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	s := NewService(state, nil)
+
+	var readOnly *core.ReadOnlyError
+	if err := s.KillOperation("ro", "123"); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+}
+
+func TestOperationAllowed(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "team", AllowedDatabases: []string{"shop"}},
+		{ID: "open"},
+	}
+	s := NewService(state, nil)
+
+	tests := []struct {
+		connID, ns string
+		want       bool
+	}{
+		{"team", "shop.orders", true},
+		{"team", "billing.invoices", false},
+		{"team", "", false},
+		{"open", "billing.invoices", true},
+		{"open", "", true},
+	}
+	for _, tt := range tests {
+		if got := s.operationAllowed(tt.connID, tt.ns); got != tt.want {
+			t.Errorf("operationAllowed(%q, %q) = %v, want %v", tt.connID, tt.ns, got, tt.want)
+		}
+	}
+}
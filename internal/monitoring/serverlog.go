@@ -0,0 +1,145 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Log types getLog accepts.
+const (
+	LogTypeGlobal          = "global"
+	LogTypeStartupWarnings = "startupWarnings"
+)
+
+// textLogLine matches the plain text log lines of servers before 4.4, e.g.
+// "2019-11-20T10:00:00.123+0000 I  NETWORK  [listener] connection accepted".
+var textLogLine = regexp.MustCompile(`^(\S+)\s+([FEWID]\d?)\s+(\S+)\s+\[([^\]]*)\]\s?(.*)$`)
+
+// jsonLogLine is a structured log line of servers 4.4 and later.
+type jsonLogLine struct {
+	T struct {
+		Date string `json:"$date"`
+	} `json:"t"`
+	S    string          `json:"s"`
+	C    string          `json:"c"`
+	ID   int64           `json:"id"`
+	Ctx  string          `json:"ctx"`
+	Msg  string          `json:"msg"`
+	Attr json.RawMessage `json:"attr"`
+}
+
+// GetServerLog returns the recent log entries a server keeps in memory, from getLog. logType
+// is "global" (the default when empty) or "startupWarnings". Only the last tail entries
+// matching filter are returned, oldest first; a tail of 0 or less returns all of them.
+func (s *Service) GetServerLog(connID, logType string, tail int, filter types.ServerLogFilter) (*types.ServerLog, error) {
+	switch logType {
+	case "":
+		logType = LogTypeGlobal
+	case LogTypeGlobal, LogTypeStartupWarnings:
+	default:
+		return nil, fmt.Errorf("invalid log type %q", logType)
+	}
+
+	client, err := s.state.GetClient(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	var result struct {
+		TotalLinesWritten int64    `bson:"totalLinesWritten"`
+		Log               []string `bson:"log"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "getLog", Value: logType}}).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to get server log: %w", err)
+	}
+
+	serverLog := &types.ServerLog{
+		LogType:           logType,
+		TotalLinesWritten: result.TotalLinesWritten,
+		Entries:           []types.ServerLogEntry{},
+	}
+	for _, line := range result.Log {
+		if entry := parseLogLine(line); matchesLogFilter(entry, filter) {
+			serverLog.Entries = append(serverLog.Entries, entry)
+		}
+	}
+	if tail > 0 && len(serverLog.Entries) > tail {
+		serverLog.Entries = serverLog.Entries[len(serverLog.Entries)-tail:]
+	}
+	return serverLog, nil
+}
+
+// parseLogLine parses a structured or plain text log line. Lines in neither format are kept
+// as the message.
+func parseLogLine(line string) types.ServerLogEntry {
+	entry := types.ServerLogEntry{Raw: line, Message: line}
+
+	var structured jsonLogLine
+	if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &structured) == nil {
+		entry.Time, _ = time.Parse(time.RFC3339Nano, structured.T.Date)
+		entry.Severity = structured.S
+		entry.Component = structured.C
+		entry.ID = structured.ID
+		entry.Context = structured.Ctx
+		entry.Message = structured.Msg
+		if len(structured.Attr) > 0 {
+			entry.Attributes = string(structured.Attr)
+		}
+		return entry
+	}
+
+	if m := textLogLine.FindStringSubmatch(line); m != nil {
+		entry.Time, _ = time.Parse("2006-01-02T15:04:05.000-0700", m[1])
+		entry.Severity = m[2]
+		entry.Component = m[3]
+		entry.Context = m[4]
+		entry.Message = m[5]
+	}
+	return entry
+}
+
+// matchesLogFilter reports whether a log entry is selected by filter.
+func matchesLogFilter(entry types.ServerLogEntry, filter types.ServerLogFilter) bool {
+	if len(filter.Severities) > 0 {
+		found := false
+		for _, sev := range filter.Severities {
+			// "D" selects every debug level (D1-D5)
+			if strings.EqualFold(sev, entry.Severity) || strings.EqualFold(sev, "D") && strings.HasPrefix(entry.Severity, "D") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(filter.Components) > 0 {
+		found := false
+		for _, c := range filter.Components {
+			if strings.EqualFold(c, entry.Component) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		if !strings.Contains(strings.ToLower(entry.Message), search) && !strings.Contains(strings.ToLower(entry.Attributes), search) {
+			return false
+		}
+	}
+	return true
+}
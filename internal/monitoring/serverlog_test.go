@@ -0,0 +1,69 @@
+package monitoring
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseLogLine(t *testing.T) {
+	structured := parseLogLine(`{"t":{"$date":"2026-05-01T10:00:00.123+00:00"},"s":"W","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted","attr":{"remote":"10.0.0.5:5000"}}`)
+	want := time.Date(2026, 5, 1, 10, 0, 0, 123_000_000, time.UTC)
+	if !structured.Time.Equal(want) || structured.Severity != "W" || structured.Component != "NETWORK" {
+		t.Errorf("unexpected structured entry: %+v", structured)
+	}
+	if structured.ID != 22943 || structured.Context != "listener" || structured.Message != "Connection accepted" {
+		t.Errorf("unexpected structured entry: %+v", structured)
+	}
+	if !strings.Contains(structured.Attributes, `"remote"`) {
+		t.Errorf("attributes = %s", structured.Attributes)
+	}
+
+	text := parseLogLine("2019-11-20T10:00:00.123+0000 I  REPL     [rsSync] transition to SECONDARY")
+	if text.Severity != "I" || text.Component != "REPL" || text.Context != "rsSync" || text.Message != "transition to SECONDARY" {
+		t.Errorf("unexpected text entry: %+v", text)
+	}
+	if text.Time.IsZero() {
+		t.Error("text entry time not parsed")
+	}
+
+	other := parseLogLine("** WARNING: Access control is not enabled")
+	if other.Message != other.Raw || other.Severity != "" {
+		t.Errorf("unexpected unparsed entry: %+v", other)
+	}
+}
+
+func TestMatchesLogFilter(t *testing.T) {
+	entry := types.ServerLogEntry{Severity: "D2", Component: "QUERY", Message: "Planner chose COLLSCAN"}
+
+	tests := []struct {
+		name   string
+		filter types.ServerLogFilter
+		want   bool
+	}{
+		{"no filter", types.ServerLogFilter{}, true},
+		{"any debug level", types.ServerLogFilter{Severities: []string{"D"}}, true},
+		{"other severity", types.ServerLogFilter{Severities: []string{"E", "W"}}, false},
+		{"component", types.ServerLogFilter{Components: []string{"query"}}, true},
+		{"other component", types.ServerLogFilter{Components: []string{"REPL"}}, false},
+		{"search", types.ServerLogFilter{Search: "collscan"}, true},
+		{"search miss", types.ServerLogFilter{Search: "IXSCAN"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesLogFilter(entry, tt.filter); got != tt.want {
+				t.Errorf("matchesLogFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetServerLog_InvalidType(t *testing.T) {
+	s := NewService(core.NewAppState(), nil)
+	if _, err := s.GetServerLog("conn-1", "rs", 10, types.ServerLogFilter{}); err == nil || !strings.Contains(err.Error(), "invalid log type") {
+		t.Errorf("expected invalid log type error, got %v", err)
+	}
+}
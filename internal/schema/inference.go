@@ -8,11 +8,12 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/peternagy/mongopal/internal/bsonutil"
 	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
@@ -35,7 +36,7 @@ func (s *Service) InferCollectionSchema(connID, dbName, collName string, sampleS
 		"sampleSize": sampleSize,
 	})
 
-	client, err := s.state.GetClient(connID)
+	client, err := s.state.GetDatabaseClient(connID, dbName)
 	if err != nil {
 		debug.LogSchema("Schema inference failed - not connected", map[string]interface{}{
 			"database":     dbName,
@@ -46,7 +47,7 @@ func (s *Service) InferCollectionSchema(connID, dbName, collName string, sampleS
 		return nil, err
 	}
 
-	ctx, cancel := core.ContextWithTimeout()
+	ctx, cancel := s.state.ContextWithTimeout()
 	defer cancel()
 
 	coll := client.Database(dbName).Collection(collName)
@@ -91,7 +92,7 @@ func (s *Service) InferCollectionSchema(connID, dbName, collName string, sampleS
 	actualSamples := 0
 
 	// Emit initial progress
-	s.state.EmitEvent("schema:progress", map[string]interface{}{
+	s.state.EmitConnectionEvent(connID, "schema:progress", map[string]interface{}{
 		"current": 0,
 		"total":   sampleSize,
 		"phase":   "sampling",
@@ -107,7 +108,7 @@ func (s *Service) InferCollectionSchema(connID, dbName, collName string, sampleS
 		actualSamples++
 
 		// Emit progress update
-		s.state.EmitEvent("schema:progress", map[string]interface{}{
+		s.state.EmitConnectionEvent(connID, "schema:progress", map[string]interface{}{
 			"current": actualSamples,
 			"total":   sampleSize,
 			"phase":   "sampling",
@@ -135,12 +136,17 @@ func (s *Service) InferCollectionSchema(connID, dbName, collName string, sampleS
 		"durationMs": time.Since(start).Milliseconds(),
 	})
 
-	return &types.SchemaResult{
+	result := &types.SchemaResult{
 		Collection: collName,
 		SampleSize: len(samples),
 		TotalDocs:  total,
 		Fields:     schema,
-	}, nil
+	}
+	// Search index detection is informational; ignore failures
+	if caps, err := document.DetectSearchCapabilities(ctx, coll); err == nil {
+		result.Search = &caps
+	}
+	return result, nil
 }
 
 // analyzeDocument recursively analyzes a document's structure.
@@ -157,7 +163,7 @@ func analyzeDocument(prefix string, doc bson.M, counts map[string]int, types map
 			types[fullKey] = make(map[string]bool)
 		}
 
-		typeName := getBsonTypeName(value)
+		typeName := bsonutil.TypeName(value)
 		types[fullKey][typeName] = true
 
 		// Recurse into nested documents
@@ -184,48 +190,6 @@ func analyzeDocument(prefix string, doc bson.M, counts map[string]int, types map
 	}
 }
 
-// getBsonTypeName returns a human-readable type name for a BSON value.
-func getBsonTypeName(value interface{}) string {
-	if value == nil {
-		return "Null"
-	}
-
-	switch v := value.(type) {
-	case primitive.ObjectID:
-		return "ObjectId"
-	case string:
-		return "String"
-	case int32:
-		return "Int32"
-	case int64:
-		return "Int64"
-	case float64:
-		return "Double"
-	case bool:
-		return "Boolean"
-	case primitive.DateTime:
-		return "Date"
-	case primitive.Timestamp:
-		return "Timestamp"
-	case bson.M:
-		return "Object"
-	case bson.A:
-		if len(v) > 0 {
-			elemType := getBsonTypeName(v[0])
-			return "Array<" + elemType + ">"
-		}
-		return "Array"
-	case primitive.Binary:
-		return "Binary"
-	case primitive.Decimal128:
-		return "Decimal128"
-	case primitive.Regex:
-		return "Regex"
-	default:
-		return fmt.Sprintf("%T", value)
-	}
-}
-
 // buildSchemaFields constructs the schema field map from analysis results.
 func buildSchemaFields(counts map[string]int, fieldTypes map[string]map[string]bool, nested map[string][]bson.M, totalSamples int) map[string]types.SchemaField {
 	result := make(map[string]types.SchemaField)
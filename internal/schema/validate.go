@@ -0,0 +1,165 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/document"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Sample size bounds for ValidateCollectionDocuments.
+const (
+	DefaultValidationSampleSize = 1000
+	MaxValidationSampleSize     = 50000
+)
+
+// ValidateCollectionDocuments checks a random sample of documents against the collection's
+// validator and returns the ones that would be rejected, with the rules they break. The
+// $jsonSchema part is evaluated client-side so each violation names its field and keyword;
+// any other query expressions in the validator are checked by the server.
+func (s *Service) ValidateCollectionDocuments(connID, dbName, collName string, sampleSize int) (*types.DocumentValidationResult, error) {
+	if sampleSize <= 0 {
+		sampleSize = DefaultValidationSampleSize
+	}
+	if sampleSize > MaxValidationSampleSize {
+		sampleSize = MaxValidationSampleSize
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+	db := client.Database(dbName)
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: collName}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection info: %w", err)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("collection %s not found", collName)
+	}
+
+	var collOpts struct {
+		Validator        bson.M `bson:"validator"`
+		ValidationLevel  string `bson:"validationLevel"`
+		ValidationAction string `bson:"validationAction"`
+	}
+	if specs[0].Options != nil {
+		if err := bson.Unmarshal(specs[0].Options, &collOpts); err != nil {
+			return nil, fmt.Errorf("failed to read collection options: %w", err)
+		}
+	}
+
+	result := &types.DocumentValidationResult{
+		HasValidator:     len(collOpts.Validator) > 0,
+		ValidationLevel:  collOpts.ValidationLevel,
+		ValidationAction: collOpts.ValidationAction,
+		Invalid:          []types.InvalidDocument{},
+		Warnings:         []string{},
+	}
+	if !result.HasValidator {
+		return result, nil
+	}
+
+	jsonSchema, _ := collOpts.Validator["$jsonSchema"].(bson.M)
+	queryRules := bson.M{}
+	for k, v := range collOpts.Validator {
+		if k != "$jsonSchema" {
+			queryRules[k] = v
+		}
+	}
+
+	debug.LogSchema("Validating documents against collection validator", map[string]interface{}{
+		"database":   dbName,
+		"collection": collName,
+		"sampleSize": sampleSize,
+	})
+
+	coll := db.Collection(collName)
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	checker := newSchemaChecker()
+	var ids []interface{}
+	invalid := map[string]*types.InvalidDocument{}
+	var order []string
+	record := func(id string, violations ...types.SchemaViolation) {
+		doc, ok := invalid[id]
+		if !ok {
+			doc = &types.InvalidDocument{ID: id}
+			invalid[id] = doc
+			order = append(order, id)
+		}
+		doc.Violations = append(doc.Violations, violations...)
+	}
+
+	for cursor.Next(ctx) {
+		result.Checked++
+		idValue := cursor.Current.Lookup("_id")
+		ids = append(ids, idValue)
+		if jsonSchema == nil {
+			continue
+		}
+		if violations := checker.validate(jsonSchema, cursor.Current); len(violations) > 0 {
+			id, err := document.MarshalValue(idValue)
+			if err != nil {
+				return nil, err
+			}
+			record(id, violations...)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to sample documents: %w", err)
+	}
+
+	if len(queryRules) > 0 && len(ids) > 0 {
+		failing, err := failingQueryRules(ctx, coll, queryRules, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range failing {
+			record(id, types.SchemaViolation{Rule: "query", Message: "document does not match the validator's query expression"})
+		}
+	}
+
+	for _, id := range order {
+		result.Invalid = append(result.Invalid, *invalid[id])
+	}
+	result.Warnings = append(result.Warnings, checker.sortedWarnings()...)
+	return result, nil
+}
+
+// failingQueryRules returns the Extended JSON _ids of the given documents that don't match
+// the validator's query expressions.
+func failingQueryRules(ctx context.Context, coll *mongo.Collection, rules bson.M, ids []interface{}) ([]string, error) {
+	filter := bson.M{"_id": bson.M{"$in": ids}, "$nor": bson.A{rules}}
+	cursor, err := coll.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check validator query: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var failing []string
+	for cursor.Next(ctx) {
+		id, err := document.MarshalValue(cursor.Current.Lookup("_id"))
+		if err != nil {
+			return nil, err
+		}
+		failing = append(failing, id)
+	}
+	return failing, cursor.Err()
+}
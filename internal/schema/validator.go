@@ -0,0 +1,460 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// bsonTypeAliases maps $jsonSchema bsonType aliases to BSON types.
+var bsonTypeAliases = map[string][]bsontype.Type{
+	"double":     {bson.TypeDouble},
+	"string":     {bson.TypeString},
+	"object":     {bson.TypeEmbeddedDocument},
+	"array":      {bson.TypeArray},
+	"binData":    {bson.TypeBinary},
+	"undefined":  {bson.TypeUndefined},
+	"objectId":   {bson.TypeObjectID},
+	"bool":       {bson.TypeBoolean},
+	"date":       {bson.TypeDateTime},
+	"null":       {bson.TypeNull},
+	"regex":      {bson.TypeRegex},
+	"dbPointer":  {bson.TypeDBPointer},
+	"javascript": {bson.TypeJavaScript, bson.TypeCodeWithScope},
+	"symbol":     {bson.TypeSymbol},
+	"int":        {bson.TypeInt32},
+	"timestamp":  {bson.TypeTimestamp},
+	"long":       {bson.TypeInt64},
+	"decimal":    {bson.TypeDecimal128},
+	"minKey":     {bson.TypeMinKey},
+	"maxKey":     {bson.TypeMaxKey},
+	"number":     {bson.TypeDouble, bson.TypeInt32, bson.TypeInt64, bson.TypeDecimal128},
+}
+
+// jsonTypeAliases maps JSON Schema "type" names to BSON types.
+var jsonTypeAliases = map[string][]bsontype.Type{
+	"object":  {bson.TypeEmbeddedDocument},
+	"array":   {bson.TypeArray},
+	"number":  {bson.TypeDouble, bson.TypeInt32, bson.TypeInt64, bson.TypeDecimal128},
+	"boolean": {bson.TypeBoolean},
+	"string":  {bson.TypeString},
+	"null":    {bson.TypeNull},
+}
+
+// ignoredKeywords are annotations that don't constrain documents.
+var ignoredKeywords = map[string]bool{"title": true, "description": true}
+
+// schemaChecker evaluates MongoDB's $jsonSchema dialect against documents. Keywords it
+// doesn't support are collected as warnings instead of failing validation.
+type schemaChecker struct {
+	violations []types.SchemaViolation
+	warnings   map[string]bool
+	patterns   map[string]*regexp.Regexp
+}
+
+func newSchemaChecker() *schemaChecker {
+	return &schemaChecker{warnings: map[string]bool{}, patterns: map[string]*regexp.Regexp{}}
+}
+
+// validate checks a document and returns its violations.
+func (c *schemaChecker) validate(schema bson.M, doc bson.Raw) []types.SchemaViolation {
+	c.violations = nil
+	c.check(schema, "", bson.RawValue{Type: bson.TypeEmbeddedDocument, Value: doc})
+	return c.violations
+}
+
+// sortedWarnings returns the collected warnings in a stable order.
+func (c *schemaChecker) sortedWarnings() []string {
+	out := make([]string, 0, len(c.warnings))
+	for w := range c.warnings {
+		out = append(out, w)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (c *schemaChecker) fail(path, rule, format string, args ...interface{}) {
+	c.violations = append(c.violations, types.SchemaViolation{
+		Path:    path,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// matches reports whether val satisfies schema without recording violations.
+func (c *schemaChecker) matches(schema bson.M, path string, val bson.RawValue) bool {
+	saved := c.violations
+	c.violations = nil
+	c.check(schema, path, val)
+	ok := len(c.violations) == 0
+	c.violations = saved
+	return ok
+}
+
+func (c *schemaChecker) check(schema bson.M, path string, val bson.RawValue) {
+	for _, keyword := range sortedKeys(schema) {
+		rule := schema[keyword]
+		switch keyword {
+		case "bsonType":
+			c.checkType(keyword, rule, bsonTypeAliases, path, val)
+		case "type":
+			c.checkType(keyword, rule, jsonTypeAliases, path, val)
+		case "enum":
+			c.checkEnum(rule, path, val)
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			c.checkNumber(keyword, schema, path, val)
+		case "minLength", "maxLength", "pattern":
+			c.checkString(keyword, rule, path, val)
+		case "required", "properties", "additionalProperties", "patternProperties", "minProperties", "maxProperties":
+			c.checkObject(keyword, schema, path, val)
+		case "items", "additionalItems", "minItems", "maxItems", "uniqueItems":
+			c.checkArray(keyword, schema, path, val)
+		case "allOf", "anyOf", "oneOf":
+			c.checkCombinator(keyword, rule, path, val)
+		case "not":
+			if sub, ok := rule.(bson.M); ok && c.matches(sub, path, val) {
+				c.fail(path, keyword, "must not match the \"not\" schema")
+			}
+		default:
+			if !ignoredKeywords[keyword] {
+				c.warnings[fmt.Sprintf("keyword %q is not checked", keyword)] = true
+			}
+		}
+	}
+}
+
+func (c *schemaChecker) checkType(keyword string, rule interface{}, aliases map[string][]bsontype.Type, path string, val bson.RawValue) {
+	var names []string
+	switch r := rule.(type) {
+	case string:
+		names = []string{r}
+	case bson.A:
+		for _, n := range r {
+			names = append(names, bsonutil.ToString(n))
+		}
+	}
+	for _, name := range names {
+		allowed, ok := aliases[name]
+		if !ok {
+			c.warnings[fmt.Sprintf("unknown %s %q", keyword, name)] = true
+			return
+		}
+		for _, t := range allowed {
+			if val.Type == t {
+				return
+			}
+		}
+	}
+	c.fail(path, keyword, "expected %s %s, got %s", keyword, strings.Join(names, " or "), typeAlias(val.Type))
+}
+
+func (c *schemaChecker) checkEnum(rule interface{}, path string, val bson.RawValue) {
+	options, ok := rule.(bson.A)
+	if !ok {
+		return
+	}
+	for _, opt := range options {
+		t, data, err := bson.MarshalValue(opt)
+		if err != nil {
+			continue
+		}
+		candidate := bson.RawValue{Type: t, Value: data}
+		if candidate.Equal(val) {
+			return
+		}
+		a, aok := numericValue(candidate)
+		b, bok := numericValue(val)
+		if aok && bok && a == b {
+			return
+		}
+	}
+	c.fail(path, "enum", "value %s is not one of the allowed values", val.String())
+}
+
+func (c *schemaChecker) checkNumber(keyword string, schema bson.M, path string, val bson.RawValue) {
+	n, ok := numericValue(val)
+	if !ok {
+		return
+	}
+	switch keyword {
+	case "minimum":
+		min := bsonutil.ToFloat64(schema[keyword])
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && n <= min {
+			c.fail(path, keyword, "value %v must be greater than %v", n, min)
+		} else if n < min {
+			c.fail(path, keyword, "value %v is less than the minimum %v", n, min)
+		}
+	case "maximum":
+		max := bsonutil.ToFloat64(schema[keyword])
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive && n >= max {
+			c.fail(path, keyword, "value %v must be less than %v", n, max)
+		} else if n > max {
+			c.fail(path, keyword, "value %v is greater than the maximum %v", n, max)
+		}
+	case "multipleOf":
+		m := bsonutil.ToFloat64(schema[keyword])
+		if m > 0 {
+			q := n / m
+			if math.Abs(q-math.Round(q)) > 1e-9 {
+				c.fail(path, keyword, "value %v is not a multiple of %v", n, m)
+			}
+		}
+	}
+}
+
+func (c *schemaChecker) checkString(keyword string, rule interface{}, path string, val bson.RawValue) {
+	s, ok := val.StringValueOK()
+	if !ok {
+		return
+	}
+	switch keyword {
+	case "minLength":
+		if min := bsonutil.ToInt(rule); utf8.RuneCountInString(s) < min {
+			c.fail(path, keyword, "string is shorter than %d characters", min)
+		}
+	case "maxLength":
+		if max := bsonutil.ToInt(rule); utf8.RuneCountInString(s) > max {
+			c.fail(path, keyword, "string is longer than %d characters", max)
+		}
+	case "pattern":
+		pattern := bsonutil.ToString(rule)
+		re := c.compile(pattern)
+		if re != nil && !re.MatchString(s) {
+			c.fail(path, keyword, "string does not match pattern %s", pattern)
+		}
+	}
+}
+
+func (c *schemaChecker) checkObject(keyword string, schema bson.M, path string, val bson.RawValue) {
+	doc, ok := val.DocumentOK()
+	if !ok {
+		return
+	}
+	elems, err := doc.Elements()
+	if err != nil {
+		return
+	}
+
+	switch keyword {
+	case "required":
+		fields, _ := schema[keyword].(bson.A)
+		for _, f := range fields {
+			name := bsonutil.ToString(f)
+			if _, err := doc.LookupErr(name); err != nil {
+				c.fail(joinPath(path, name), keyword, "required field %q is missing", name)
+			}
+		}
+	case "properties":
+		props, _ := schema[keyword].(bson.M)
+		for _, e := range elems {
+			if sub, ok := props[e.Key()].(bson.M); ok {
+				c.check(sub, joinPath(path, e.Key()), e.Value())
+			}
+		}
+	case "patternProperties":
+		patterns, _ := schema[keyword].(bson.M)
+		for _, e := range elems {
+			for _, pattern := range sortedKeys(patterns) {
+				re := c.compile(pattern)
+				if sub, ok := patterns[pattern].(bson.M); ok && re != nil && re.MatchString(e.Key()) {
+					c.check(sub, joinPath(path, e.Key()), e.Value())
+				}
+			}
+		}
+	case "additionalProperties":
+		props, _ := schema["properties"].(bson.M)
+		patterns, _ := schema["patternProperties"].(bson.M)
+		for _, e := range elems {
+			if _, declared := props[e.Key()]; declared {
+				continue
+			}
+			if c.matchesAnyPattern(patterns, e.Key()) {
+				continue
+			}
+			switch rule := schema[keyword].(type) {
+			case bool:
+				if !rule {
+					c.fail(joinPath(path, e.Key()), keyword, "field %q is not allowed", e.Key())
+				}
+			case bson.M:
+				c.check(rule, joinPath(path, e.Key()), e.Value())
+			}
+		}
+	case "minProperties":
+		if min := bsonutil.ToInt(schema[keyword]); len(elems) < min {
+			c.fail(path, keyword, "object has fewer than %d fields", min)
+		}
+	case "maxProperties":
+		if max := bsonutil.ToInt(schema[keyword]); len(elems) > max {
+			c.fail(path, keyword, "object has more than %d fields", max)
+		}
+	}
+}
+
+func (c *schemaChecker) checkArray(keyword string, schema bson.M, path string, val bson.RawValue) {
+	arr, ok := val.ArrayOK()
+	if !ok {
+		return
+	}
+	values, err := arr.Values()
+	if err != nil {
+		return
+	}
+
+	switch keyword {
+	case "items":
+		switch rule := schema[keyword].(type) {
+		case bson.M:
+			for i, v := range values {
+				c.check(rule, joinPath(path, strconv.Itoa(i)), v)
+			}
+		case bson.A:
+			for i, v := range values {
+				if i >= len(rule) {
+					break
+				}
+				if sub, ok := rule[i].(bson.M); ok {
+					c.check(sub, joinPath(path, strconv.Itoa(i)), v)
+				}
+			}
+		}
+	case "additionalItems":
+		tuple, ok := schema["items"].(bson.A)
+		if !ok {
+			return
+		}
+		for i := len(tuple); i < len(values); i++ {
+			switch rule := schema[keyword].(type) {
+			case bool:
+				if !rule {
+					c.fail(path, keyword, "array has more than %d items", len(tuple))
+					return
+				}
+			case bson.M:
+				c.check(rule, joinPath(path, strconv.Itoa(i)), values[i])
+			}
+		}
+	case "minItems":
+		if min := bsonutil.ToInt(schema[keyword]); len(values) < min {
+			c.fail(path, keyword, "array has fewer than %d items", min)
+		}
+	case "maxItems":
+		if max := bsonutil.ToInt(schema[keyword]); len(values) > max {
+			c.fail(path, keyword, "array has more than %d items", max)
+		}
+	case "uniqueItems":
+		if unique, _ := schema[keyword].(bool); unique {
+			for i := range values {
+				for j := i + 1; j < len(values); j++ {
+					if values[i].Equal(values[j]) {
+						c.fail(path, keyword, "array items %d and %d are equal", i, j)
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *schemaChecker) checkCombinator(keyword string, rule interface{}, path string, val bson.RawValue) {
+	subs, ok := rule.(bson.A)
+	if !ok {
+		return
+	}
+	matched := 0
+	for _, s := range subs {
+		sub, ok := s.(bson.M)
+		if !ok {
+			continue
+		}
+		if keyword == "allOf" {
+			// Report the underlying violations rather than a summary
+			c.check(sub, path, val)
+			continue
+		}
+		if c.matches(sub, path, val) {
+			matched++
+		}
+	}
+	switch {
+	case keyword == "anyOf" && matched == 0:
+		c.fail(path, keyword, "value matches none of the anyOf schemas")
+	case keyword == "oneOf" && matched != 1:
+		c.fail(path, keyword, "value matches %d of the oneOf schemas, expected exactly 1", matched)
+	}
+}
+
+func (c *schemaChecker) matchesAnyPattern(patterns bson.M, key string) bool {
+	for pattern := range patterns {
+		if re := c.compile(pattern); re != nil && re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile caches regular expressions; patterns Go can't compile (some PCRE syntax) are
+// reported as warnings and skipped.
+func (c *schemaChecker) compile(pattern string) *regexp.Regexp {
+	if re, ok := c.patterns[pattern]; ok {
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		c.warnings[fmt.Sprintf("pattern %q is not checked: %v", pattern, err)] = true
+	}
+	c.patterns[pattern] = re
+	return re
+}
+
+// numericValue returns a numeric BSON value as float64.
+func numericValue(val bson.RawValue) (float64, bool) {
+	switch val.Type {
+	case bson.TypeDouble:
+		return val.Double(), true
+	case bson.TypeInt32:
+		return float64(val.Int32()), true
+	case bson.TypeInt64:
+		return float64(val.Int64()), true
+	case bson.TypeDecimal128:
+		f, err := strconv.ParseFloat(val.Decimal128().String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// typeAlias returns the $jsonSchema bsonType alias for a BSON type.
+func typeAlias(t bsontype.Type) string {
+	for alias, ts := range bsonTypeAliases {
+		if alias != "number" && ts[0] == t {
+			return alias
+		}
+	}
+	return t.String()
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m bson.M) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func parseSchema(t *testing.T, schemaJSON string) bson.M {
+	t.Helper()
+	var schema bson.M
+	if err := bson.UnmarshalExtJSON([]byte(schemaJSON), true, &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	return schema
+}
+
+func parseDoc(t *testing.T, docJSON string) bson.Raw {
+	t.Helper()
+	var doc bson.Raw
+	if err := bson.UnmarshalExtJSON([]byte(docJSON), true, &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	return doc
+}
+
+func rules(violations []types.SchemaViolation) []string {
+	out := []string{}
+	for _, v := range violations {
+		out = append(out, v.Path+":"+v.Rule)
+	}
+	return out
+}
+
+func TestSchemaChecker(t *testing.T) {
+	schema := `{
+		"bsonType": "object",
+		"required": ["name", "age"],
+		"properties": {
+			"name": {"bsonType": "string", "minLength": 2, "maxLength": 10},
+			"age": {"bsonType": ["int", "long"], "minimum": 0, "maximum": 150},
+			"email": {"bsonType": "string", "pattern": "^[^@]+@[^@]+$"},
+			"status": {"enum": ["active", "inactive"]},
+			"score": {"bsonType": "number", "multipleOf": 0.5, "exclusiveMaximum": true, "maximum": 10},
+			"tags": {"bsonType": "array", "items": {"bsonType": "string"}, "maxItems": 3, "uniqueItems": true},
+			"address": {
+				"bsonType": "object",
+				"required": ["city"],
+				"additionalProperties": false,
+				"properties": {"city": {"type": "string"}, "zip": {"type": "string"}}
+			}
+		}
+	}`
+
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"valid", `{"name": "Ann", "age": 30, "status": "active", "score": 9.5, "tags": ["a", "b"], "address": {"city": "Oslo"}}`, []string{}},
+		{"long age is allowed", `{"name": "Ann", "age": {"$numberLong": "30"}}`, []string{}},
+		{"missing required", `{"name": "Ann"}`, []string{"age:required"}},
+		{"wrong type", `{"name": "Ann", "age": "30"}`, []string{"age:bsonType"}},
+		{"double age", `{"name": "Ann", "age": 30.5}`, []string{"age:bsonType"}},
+		{"range", `{"name": "Ann", "age": -1}`, []string{"age:minimum"}},
+		{"string length", `{"name": "A", "age": 1}`, []string{"name:minLength"}},
+		{"pattern", `{"name": "Ann", "age": 1, "email": "nope"}`, []string{"email:pattern"}},
+		{"enum", `{"name": "Ann", "age": 1, "status": "deleted"}`, []string{"status:enum"}},
+		{"multipleOf", `{"name": "Ann", "age": 1, "score": 1.2}`, []string{"score:multipleOf"}},
+		{"exclusive maximum", `{"name": "Ann", "age": 1, "score": 10}`, []string{"score:maximum"}},
+		{"array items", `{"name": "Ann", "age": 1, "tags": ["a", 1, "a", "b"]}`, []string{"tags.1:bsonType", "tags:maxItems", "tags:uniqueItems"}},
+		{"nested", `{"name": "Ann", "age": 1, "address": {"zip": 123, "country": "NO"}}`, []string{"address.country:additionalProperties", "address.zip:type", "address.city:required"}},
+	}
+
+	checker := newSchemaChecker()
+	s := parseSchema(t, schema)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(checker.validate(s, parseDoc(t, tt.doc)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if w := checker.sortedWarnings(); len(w) != 0 {
+		t.Errorf("Expected no warnings, got %v", w)
+	}
+}
+
+func TestSchemaChecker_Combinators(t *testing.T) {
+	schema := parseSchema(t, `{
+		"properties": {
+			"a": {"anyOf": [{"bsonType": "string"}, {"bsonType": "int"}]},
+			"b": {"oneOf": [{"bsonType": "number"}, {"bsonType": "int"}]},
+			"c": {"not": {"bsonType": "null"}},
+			"d": {"allOf": [{"bsonType": "string"}, {"minLength": 3}]}
+		}
+	}`)
+	checker := newSchemaChecker()
+
+	if got := checker.validate(schema, parseDoc(t, `{"a": "x", "b": 1.5, "c": 1, "d": "abc"}`)); len(got) != 0 {
+		t.Errorf("Expected valid document, got %v", rules(got))
+	}
+	got := rules(checker.validate(schema, parseDoc(t, `{"a": true, "b": 1, "c": null, "d": "ab"}`)))
+	want := []string{"a:anyOf", "b:oneOf", "c:not", "d:minLength"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+}
+
+func TestSchemaChecker_Warnings(t *testing.T) {
+	schema := parseSchema(t, `{"description": "x", "dependencies": {"a": ["b"]}, "properties": {"a": {"bsonType": "text", "pattern": "(?<=x)y"}}}`)
+	checker := newSchemaChecker()
+
+	if got := checker.validate(schema, parseDoc(t, `{"a": "y"}`)); len(got) != 0 {
+		t.Errorf("Expected unsupported rules to be skipped, got %v", rules(got))
+	}
+	if w := checker.sortedWarnings(); len(w) != 3 {
+		t.Errorf("Expected 3 warnings, got %v", w)
+	}
+}
+
+func TestValidateCollectionDocuments_NotConnected(t *testing.T) {
+	svc := NewService(core.NewAppState())
+
+	var notConnected *core.NotConnectedError
+	if _, err := svc.ValidateCollectionDocuments("conn-1", "db", "coll", 0); !errors.As(err, &notConnected) {
+		t.Errorf("Expected NotConnectedError, got %v", err)
+	}
+}
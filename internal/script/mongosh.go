@@ -5,27 +5,44 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"net/url"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/credential"
 	"github.com/peternagy/mongopal/internal/storage"
 	"github.com/peternagy/mongopal/internal/types"
 )
 
 // Service handles script execution.
 type Service struct {
+	state     *core.AppState
 	connStore *storage.ConnectionService
 }
 
 // NewService creates a new script service.
-func NewService(connStore *storage.ConnectionService) *Service {
+func NewService(state *core.AppState, connStore *storage.ConnectionService) *Service {
 	return &Service{
+		state:     state,
 		connStore: connStore,
 	}
 }
 
+// scriptOperation names script runs in read-only errors. Scripts are refused outright on
+// read-only connections: the shell can reach any method through computed names, so no scan of
+// the script text can tell whether it writes.
+const scriptOperation = "run script"
+
+// checkScriptDatabases rejects scripts on connections with database allow/deny lists, since the
+// shell can reach any database through computed names, and a database the lists do not permit.
+func (s *Service) checkScriptDatabases(connID, dbName string) error {
+	if s.state.HasDatabaseRestrictions(connID) {
+		return fmt.Errorf("this connection restricts database access: scripts cannot be run on it")
+	}
+	return s.state.CheckDatabaseAccess(connID, dbName)
+}
+
 // CheckMongoshAvailable checks if mongosh is installed and available.
 func CheckMongoshAvailable() (bool, string) {
 	// Try mongosh first (modern MongoDB shell)
@@ -44,6 +61,12 @@ func (s *Service) ExecuteScript(connID, script string) (*types.ScriptResult, err
 	if script == "" {
 		return nil, fmt.Errorf("script cannot be empty")
 	}
+	if err := s.state.CheckWritable(connID, scriptOperation); err != nil {
+		return nil, err
+	}
+	if err := s.checkScriptDatabases(connID, ""); err != nil {
+		return nil, err
+	}
 
 	// Check if mongosh is available
 	available, shellPath := CheckMongoshAvailable()
@@ -135,6 +158,12 @@ func (s *Service) ExecuteScriptWithDatabase(connID, dbName, script string) (*typ
 	if dbName == "" {
 		return nil, fmt.Errorf("database name cannot be empty")
 	}
+	if err := s.state.CheckWritable(connID, scriptOperation); err != nil {
+		return nil, err
+	}
+	if err := s.checkScriptDatabases(connID, dbName); err != nil {
+		return nil, err
+	}
 
 	// Check if mongosh is available
 	available, shellPath := CheckMongoshAvailable()
@@ -148,15 +177,9 @@ func (s *Service) ExecuteScriptWithDatabase(connID, dbName, script string) (*typ
 		return nil, err
 	}
 
-	// Parse and modify URI to include database
-	parsedURI, err := url.Parse(uri)
-	if err != nil {
-		return nil, fmt.Errorf("invalid connection URI: %w", err)
-	}
-
-	// Set the database in the path
-	parsedURI.Path = "/" + dbName
-	uriWithDB := parsedURI.String()
+	// Set the database in the path, without a url.Parse roundtrip that can alter the
+	// encoding of credentials and query parameters
+	uriWithDB := credential.SetURIDatabase(uri, dbName)
 
 	// Create a context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
@@ -0,0 +1,71 @@
+package script
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestExecuteScript_ReadOnly(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	svc := &Service{state: state}
+
+	scripts := []string{
+		`db.users.find({})`,
+		`db.users.insertOne({a: 1})`,
+		`db.c["insertOne"]({})`,
+		`db.getCollection("c")["deleteMany"]({})`,
+		`const m = "delete" + "Many"; db.c[m]({})`,
+		`db.users.aggregate([{["$" + "out"]: "copy"}])`,
+	}
+	for _, script := range scripts {
+		var readOnly *core.ReadOnlyError
+		if _, err := svc.ExecuteScript("ro", script); !errors.As(err, &readOnly) {
+			t.Errorf("ExecuteScript(%q) error = %v, want ReadOnlyError", script, err)
+		}
+		if _, err := svc.ExecuteScriptWithDatabase("ro", "app", script); !errors.As(err, &readOnly) {
+			t.Errorf("ExecuteScriptWithDatabase(%q) error = %v, want ReadOnlyError", script, err)
+		}
+	}
+}
+
+func TestCheckScriptDatabases(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "team", AllowedDatabases: []string{"app"}},
+		{ID: "denied", DeniedDatabases: []string{"secret"}},
+		{ID: "open"},
+	}
+	svc := &Service{state: state}
+
+	tests := []struct {
+		name    string
+		connID  string
+		dbName  string
+		wantErr bool
+	}{
+		{"allowed database on restricted connection", "team", "app", true},
+		{"no database on restricted connection", "team", "", true},
+		{"deny list", "denied", "app", true},
+		{"unrestricted connection", "open", "other", false},
+		{"unrestricted connection without database", "open", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.checkScriptDatabases(tt.connID, tt.dbName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Computed database switches never reach the shell on restricted connections
+	for _, script := range []string{`db["getSiblingDB"]("x").users.find()`, `db.getMongo().getDB("x").users.find()`} {
+		if _, err := svc.ExecuteScriptWithDatabase("team", "app", script); err == nil {
+			t.Errorf("expected %q to be refused", script)
+		}
+	}
+}
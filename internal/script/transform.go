@@ -0,0 +1,126 @@
+package script
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// transformMarker prefixes the result lines of a transform run so anything the user's
+// script prints is ignored.
+const transformMarker = "__mongopal_transform__"
+
+// TransformOutput is the result of transforming one document. Doc is canonical Extended
+// JSON, or empty when the script returned null to leave the document alone.
+type TransformOutput struct {
+	Doc   string
+	Error string
+}
+
+// transformLine is one marker line written by the wrapper script.
+type transformLine struct {
+	Index int     `json:"i"`
+	Doc   *string `json:"doc"`
+	Error string  `json:"error"`
+}
+
+// RunTransform applies transformScript to each document (canonical Extended JSON) in a
+// mongosh process started with --nodb, so no connection or credentials are involved.
+// transformScript is the body of a function of doc: it may return a new document, modify
+// doc in place and return nothing, or return null to skip the document. An error thrown by
+// the script is reported for that document only.
+func RunTransform(ctx context.Context, transformScript string, docs []string) ([]TransformOutput, error) {
+	if strings.TrimSpace(transformScript) == "" {
+		return nil, fmt.Errorf("transform script cannot be empty")
+	}
+	available, shellPath := CheckMongoshAvailable()
+	if !available {
+		return nil, fmt.Errorf("mongosh or mongo shell not found. Please install MongoDB Shell: https://www.mongodb.com/try/download/shell")
+	}
+
+	cmd := exec.CommandContext(ctx, shellPath, "--nodb", "--quiet", "--norc")
+	cmd.Stdin = strings.NewReader(buildTransformScript(transformScript, docs))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return nil, fmt.Errorf("transform script failed: %s", msg)
+	}
+	return parseTransformOutput(stdout.Bytes(), len(docs))
+}
+
+// buildTransformScript wraps the user's function body in a loop over the input documents.
+// The documents are embedded as one JSON string literal and parsed with EJSON so every BSON
+// type survives the round trip.
+func buildTransformScript(transformScript string, docs []string) string {
+	input, _ := json.Marshal("[" + strings.Join(docs, ",") + "]")
+
+	var sb strings.Builder
+	sb.WriteString("(() => {\n")
+	sb.WriteString("const __docs = EJSON.parse(" + string(input) + ", { relaxed: false });\n")
+	sb.WriteString("const __transform = function (doc) {\n")
+	sb.WriteString(transformScript)
+	sb.WriteString("\n};\n")
+	sb.WriteString("for (let i = 0; i < __docs.length; i++) {\n")
+	sb.WriteString("  let line;\n")
+	sb.WriteString("  try {\n")
+	sb.WriteString("    let out = __transform(__docs[i]);\n")
+	sb.WriteString("    if (out === undefined) out = __docs[i];\n")
+	sb.WriteString("    line = { i: i, doc: out === null ? null : EJSON.stringify(out, { relaxed: false }) };\n")
+	sb.WriteString("  } catch (e) {\n")
+	sb.WriteString("    line = { i: i, error: String(e && e.message ? e.message : e) };\n")
+	sb.WriteString("  }\n")
+	sb.WriteString("  print('" + transformMarker + "' + JSON.stringify(line));\n")
+	sb.WriteString("}\n")
+	sb.WriteString("})();\n")
+	return sb.String()
+}
+
+// parseTransformOutput collects the marker lines of a transform run into one output per
+// input document.
+func parseTransformOutput(stdout []byte, count int) ([]TransformOutput, error) {
+	outputs := make([]TransformOutput, count)
+	seen := make([]bool, count)
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(text, transformMarker) {
+			continue
+		}
+		var line transformLine
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(text, transformMarker)), &line); err != nil {
+			return nil, fmt.Errorf("unexpected transform output: %w", err)
+		}
+		if line.Index < 0 || line.Index >= count {
+			return nil, fmt.Errorf("unexpected transform output for document %d", line.Index)
+		}
+		seen[line.Index] = true
+		outputs[line.Index].Error = line.Error
+		if line.Doc != nil {
+			outputs[line.Index].Doc = *line.Doc
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transform output: %w", err)
+	}
+	for i, ok := range seen {
+		if !ok {
+			return nil, fmt.Errorf("transform produced no result for document %d", i)
+		}
+	}
+	return outputs, nil
+}
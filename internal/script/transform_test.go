@@ -0,0 +1,61 @@
+package script
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildTransformScript(t *testing.T) {
+	docs := []string{`{"_id":{"$numberInt":"1"},"note":"it's \"quoted\""}`}
+	out := buildTransformScript("doc.n = 1;", docs)
+
+	if !strings.Contains(out, "doc.n = 1;") {
+		t.Error("Expected user script in wrapper")
+	}
+	if !strings.Contains(out, transformMarker) {
+		t.Error("Expected result marker in wrapper")
+	}
+	// Documents are embedded as a single JSON string literal
+	if !strings.Contains(out, `EJSON.parse("[{\"_id\":{\"$numberInt\":\"1\"},\"note\":\"it's \\\"quoted\\\"\"}]"`) {
+		t.Errorf("Documents not embedded as expected:\n%s", out)
+	}
+}
+
+func TestParseTransformOutput(t *testing.T) {
+	stdout := strings.Join([]string{
+		"user output is ignored",
+		transformMarker + `{"i":1,"error":"boom"}`,
+		transformMarker + `{"i":0,"doc":"{\"a\":1}"}`,
+		transformMarker + `{"i":2,"doc":null}`,
+	}, "\n")
+
+	outputs, err := parseTransformOutput([]byte(stdout), 3)
+	if err != nil {
+		t.Fatalf("parseTransformOutput failed: %v", err)
+	}
+	if outputs[0].Doc != `{"a":1}` || outputs[0].Error != "" {
+		t.Errorf("Unexpected output 0: %+v", outputs[0])
+	}
+	if outputs[1].Error != "boom" {
+		t.Errorf("Unexpected output 1: %+v", outputs[1])
+	}
+	if outputs[2].Doc != "" || outputs[2].Error != "" {
+		t.Errorf("Expected skipped output 2, got %+v", outputs[2])
+	}
+}
+
+func TestParseTransformOutput_Missing(t *testing.T) {
+	stdout := transformMarker + `{"i":0,"doc":"{}"}`
+	if _, err := parseTransformOutput([]byte(stdout), 2); err == nil {
+		t.Error("Expected error when a document has no result")
+	}
+	if _, err := parseTransformOutput([]byte(transformMarker+`{"i":5}`), 1); err == nil {
+		t.Error("Expected error for out-of-range index")
+	}
+}
+
+func TestRunTransform_EmptyScript(t *testing.T) {
+	if _, err := RunTransform(t.Context(), " ", nil); err == nil {
+		t.Error("Expected error for empty script")
+	}
+}
@@ -0,0 +1,159 @@
+// Package sqlquery translates simple SQL SELECT statements into MongoDB queries.
+package sqlquery
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies lexer tokens.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokKeyword
+	tokString
+	tokNumber
+	tokOperator
+	tokComma
+	tokLParen
+	tokRParen
+	tokStar
+)
+
+// token is a single lexical token. Keywords are upper-cased in text.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keywords recognized by the parser. Anything else is an identifier.
+var keywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AND": true, "OR": true, "NOT": true,
+	"IN": true, "LIKE": true, "ILIKE": true, "IS": true, "NULL": true, "TRUE": true, "FALSE": true,
+	"BETWEEN": true, "GROUP": true, "ORDER": true, "BY": true, "ASC": true, "DESC": true,
+	"LIMIT": true, "OFFSET": true, "AS": true, "DISTINCT": true, "HAVING": true, "JOIN": true,
+}
+
+// tokenize splits a SQL statement into tokens.
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(sql)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == ';':
+			// Trailing semicolons are allowed; anything after them is not
+			i++
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+			if i < len(runes) {
+				return nil, fmt.Errorf("multiple statements are not supported")
+			}
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: i})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == '*':
+			tokens = append(tokens, token{kind: tokStar, text: "*", pos: i})
+			i++
+		case r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					// '' is an escaped quote
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: start})
+		case r == '"' || r == '`':
+			start := i
+			quote := r
+			i++
+			for i < len(runes) && runes[i] != quote {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated identifier at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start+1 : i]), pos: start})
+			i++
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) && numberAllowed(tokens)):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), pos: start})
+		case strings.ContainsRune("=<>!", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				if two == "<=" || two == ">=" || two == "<>" || two == "!=" {
+					op = two
+				}
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected '!' at position %d", start)
+			}
+			i += len(op)
+			tokens = append(tokens, token{kind: tokOperator, text: op, pos: start})
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' || runes[i] == '$') {
+				i++
+			}
+			word := string(runes[start:i])
+			if upper := strings.ToUpper(word); keywords[upper] {
+				tokens = append(tokens, token{kind: tokKeyword, text: upper, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokIdent, text: word, pos: start})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// numberAllowed reports whether a '-' at this point starts a negative number
+// (i.e. it follows an operator, comma, parenthesis, or keyword rather than a value).
+func numberAllowed(prev []token) bool {
+	if len(prev) == 0 {
+		return true
+	}
+	switch prev[len(prev)-1].kind {
+	case tokOperator, tokComma, tokLParen, tokKeyword:
+		return true
+	}
+	return false
+}
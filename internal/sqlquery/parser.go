@@ -0,0 +1,465 @@
+package sqlquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// aggregateFuncs are the supported SQL aggregate functions.
+var aggregateFuncs = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+}
+
+// selectItem is one entry in the SELECT list.
+type selectItem struct {
+	star   bool   // SELECT * (or COUNT(*) when agg is set)
+	column string // Column name, or the aggregate argument
+	agg    string // Upper-cased aggregate function, empty for plain columns
+	alias  string
+}
+
+// orderItem is one entry in ORDER BY.
+type orderItem struct {
+	field string
+	desc  bool
+}
+
+// statement is a parsed SELECT statement.
+type statement struct {
+	items    []selectItem
+	from     string
+	where    expr
+	groupBy  []string
+	orderBy  []orderItem
+	limit    int64
+	offset   int64
+	hasLimit bool
+}
+
+// expr is a node in a WHERE expression tree.
+type expr interface{}
+
+type andExpr struct{ parts []expr }
+type orExpr struct{ parts []expr }
+type notExpr struct{ inner expr }
+
+type compareExpr struct {
+	field string
+	op    string
+	value interface{}
+}
+
+type inExpr struct {
+	field  string
+	values []interface{}
+	not    bool
+}
+
+type likeExpr struct {
+	field       string
+	pattern     string
+	not         bool
+	insensitive bool
+}
+
+type nullExpr struct {
+	field string
+	not   bool
+}
+
+type betweenExpr struct {
+	field     string
+	low, high interface{}
+	not       bool
+}
+
+// parser is a recursive-descent parser over a token stream.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses a single SELECT statement.
+func parse(sql string) (*statement, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return stmt, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokKeyword && t.text == kw
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.isKeyword(kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.errorf("expected %s", kw)
+	}
+	return nil
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.peek()
+	if t.kind != kind {
+		return t, p.errorf("expected %s", what)
+	}
+	return p.next(), nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.peek().pos)
+}
+
+func (p *parser) parseSelect() (*statement, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	if p.isKeyword("DISTINCT") {
+		return nil, p.errorf("SELECT DISTINCT is not supported (use DistinctValues)")
+	}
+
+	stmt := &statement{}
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.items = append(stmt.items, item)
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.expect(tokIdent, "collection name")
+	if err != nil {
+		return nil, err
+	}
+	stmt.from = from.text
+	if p.isKeyword("JOIN") || p.peek().kind == tokComma {
+		return nil, p.errorf("joins are not supported")
+	}
+
+	if p.acceptKeyword("WHERE") {
+		if stmt.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			t, err := p.expect(tokIdent, "GROUP BY column")
+			if err != nil {
+				return nil, err
+			}
+			stmt.groupBy = append(stmt.groupBy, t.text)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.isKeyword("HAVING") {
+		return nil, p.errorf("HAVING is not supported")
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			t, err := p.expect(tokIdent, "ORDER BY column")
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{field: t.text}
+			if p.acceptKeyword("DESC") {
+				item.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, item)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		n, err := p.parseNonNegativeInt("LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		stmt.limit = n
+		stmt.hasLimit = true
+		if p.acceptKeyword("OFFSET") {
+			if stmt.offset, err = p.parseNonNegativeInt("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseNonNegativeInt(clause string) (int64, error) {
+	t, err := p.expect(tokNumber, clause+" value")
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(t.text, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", clause)
+	}
+	return n, nil
+}
+
+func (p *parser) parseSelectItem() (selectItem, error) {
+	t := p.peek()
+	if t.kind == tokStar {
+		p.next()
+		return selectItem{star: true}, nil
+	}
+	if t.kind != tokIdent {
+		return selectItem{}, p.errorf("expected column")
+	}
+	p.next()
+
+	var item selectItem
+	if p.peek().kind == tokLParen {
+		fn := strings.ToUpper(t.text)
+		if !aggregateFuncs[fn] {
+			return selectItem{}, fmt.Errorf("unsupported function %s", t.text)
+		}
+		p.next()
+		item.agg = fn
+		switch arg := p.next(); {
+		case arg.kind == tokStar && fn == "COUNT":
+			item.star = true
+		case arg.kind == tokIdent:
+			item.column = arg.text
+		default:
+			return selectItem{}, fmt.Errorf("invalid argument to %s", fn)
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return selectItem{}, err
+		}
+	} else {
+		item.column = t.text
+	}
+
+	if p.acceptKeyword("AS") {
+		alias, err := p.expect(tokIdent, "alias")
+		if err != nil {
+			return selectItem{}, err
+		}
+		item.alias = alias.text
+	}
+	return item, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	parts := []expr{left}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, right)
+	}
+	if len(parts) == 1 {
+		return left, nil
+	}
+	return orExpr{parts: parts}, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	parts := []expr{left}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, right)
+	}
+	if len(parts) == 1 {
+		return left, nil
+	}
+	return andExpr{parts: parts}, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	if p.peek().kind == tokLParen {
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	fieldTok, err := p.expect(tokIdent, "column")
+	if err != nil {
+		return nil, err
+	}
+	field := fieldTok.text
+
+	if t := p.peek(); t.kind == tokOperator {
+		p.next()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		if op == "<>" {
+			op = "!="
+		}
+		return compareExpr{field: field, op: op, value: value}, nil
+	}
+
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return nullExpr{field: field, not: not}, nil
+	}
+
+	not := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("IN"):
+		if _, err := p.expect(tokLParen, "("); err != nil {
+			return nil, err
+		}
+		var values []interface{}
+		for {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inExpr{field: field, values: values, not: not}, nil
+
+	case p.isKeyword("LIKE") || p.isKeyword("ILIKE"):
+		insensitive := p.next().text == "ILIKE"
+		pattern, err := p.expect(tokString, "LIKE pattern")
+		if err != nil {
+			return nil, err
+		}
+		return likeExpr{field: field, pattern: pattern.text, not: not, insensitive: insensitive}, nil
+
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return betweenExpr{field: field, low: low, high: high, not: not}, nil
+	}
+
+	return nil, p.errorf("expected comparison after %s", field)
+}
+
+// parseValue parses a literal: string, number, TRUE, FALSE, or NULL.
+func (p *parser) parseValue() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return t.text, nil
+	case tokNumber:
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return f, nil
+	case tokKeyword:
+		switch t.text {
+		case "TRUE":
+			return true, nil
+		case "FALSE":
+			return false, nil
+		case "NULL":
+			return nil, nil
+		}
+	case tokIdent:
+		return nil, fmt.Errorf("comparing columns (%s) is not supported; quote strings with single quotes", t.text)
+	}
+	return nil, fmt.Errorf("expected value at position %d", t.pos)
+}
@@ -0,0 +1,410 @@
+package sqlquery
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Translate converts a SELECT statement into a MongoDB find (filter, projection, sort,
+// skip, limit) or, when it uses aggregates, GROUP BY, or column aliases, an aggregation
+// pipeline. The sort uses the FindDocuments format ("field,-field").
+func Translate(sql string) (*types.SQLTranslation, error) {
+	if strings.TrimSpace(sql) == "" {
+		return nil, fmt.Errorf("SQL statement is required")
+	}
+	stmt, err := parse(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{}
+	if stmt.where != nil {
+		if filter, err = buildFilter(stmt.where); err != nil {
+			return nil, err
+		}
+	}
+	filterJSON, err := marshalRelaxed(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.SQLTranslation{
+		Collection: stmt.from,
+		Filter:     filterJSON,
+		Skip:       stmt.offset,
+		Limit:      stmt.limit,
+	}
+
+	if needsAggregation(stmt) {
+		pipeline, err := buildPipeline(stmt, filter)
+		if err != nil {
+			return nil, err
+		}
+		pipelineJSON, err := marshalRelaxed(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		result.IsAggregation = true
+		result.Pipeline = pipelineJSON
+		return result, nil
+	}
+
+	if projection := buildProjection(stmt.items); len(projection) > 0 {
+		if result.Projection, err = marshalRelaxed(projection); err != nil {
+			return nil, err
+		}
+	}
+
+	sortParts := make([]string, len(stmt.orderBy))
+	for i, o := range stmt.orderBy {
+		if o.desc {
+			sortParts[i] = "-" + o.field
+		} else {
+			sortParts[i] = o.field
+		}
+	}
+	result.Sort = strings.Join(sortParts, ",")
+
+	return result, nil
+}
+
+// needsAggregation reports whether a statement can't be expressed as a plain find.
+func needsAggregation(stmt *statement) bool {
+	if len(stmt.groupBy) > 0 {
+		return true
+	}
+	for _, item := range stmt.items {
+		if item.agg != "" || item.alias != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildProjection returns an inclusion projection for the selected columns, or nil for SELECT *.
+// _id is excluded unless selected, matching SQL semantics.
+func buildProjection(items []selectItem) bson.D {
+	projection := bson.D{}
+	includesID := false
+	for _, item := range items {
+		if item.star {
+			return nil
+		}
+		if item.column == "_id" {
+			includesID = true
+		}
+		projection = append(projection, bson.E{Key: item.column, Value: 1})
+	}
+	if !includesID {
+		projection = append(projection, bson.E{Key: "_id", Value: 0})
+	}
+	return projection
+}
+
+// buildPipeline builds the aggregation pipeline for grouped, aggregated, or aliased selects.
+func buildPipeline(stmt *statement, filter bson.D) (bson.A, error) {
+	pipeline := bson.A{}
+	if len(filter) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: filter}})
+	}
+
+	hasAgg := false
+	for _, item := range stmt.items {
+		if item.agg != "" {
+			hasAgg = true
+		}
+	}
+
+	// Output field name for each ORDER BY reference that names a source column
+	outputNames := map[string]string{}
+
+	if len(stmt.groupBy) > 0 || hasAgg {
+		grouped := map[string]bool{}
+		for _, g := range stmt.groupBy {
+			grouped[g] = true
+		}
+
+		var groupID interface{}
+		switch len(stmt.groupBy) {
+		case 0:
+			groupID = nil
+		case 1:
+			groupID = "$" + stmt.groupBy[0]
+		default:
+			id := bson.D{}
+			for _, g := range stmt.groupBy {
+				id = append(id, bson.E{Key: safeName(g), Value: "$" + g})
+			}
+			groupID = id
+		}
+
+		group := bson.D{{Key: "_id", Value: groupID}}
+		project := bson.D{{Key: "_id", Value: 0}}
+		for _, item := range stmt.items {
+			switch {
+			case item.agg == "" && item.star:
+				return nil, fmt.Errorf("SELECT * cannot be combined with GROUP BY or aggregates")
+			case item.agg == "":
+				if !grouped[item.column] {
+					return nil, fmt.Errorf("column %s must appear in GROUP BY or be used in an aggregate", item.column)
+				}
+				name := item.alias
+				if name == "" {
+					name = safeName(item.column)
+				}
+				source := "$_id"
+				if len(stmt.groupBy) > 1 {
+					source = "$_id." + safeName(item.column)
+				}
+				project = append(project, bson.E{Key: name, Value: source})
+				outputNames[item.column] = name
+			default:
+				name := item.alias
+				if name == "" {
+					name = defaultAggName(item)
+				}
+				group = append(group, bson.E{Key: name, Value: accumulator(item)})
+				project = append(project, bson.E{Key: name, Value: 1})
+			}
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$group", Value: group}},
+			bson.D{{Key: "$project", Value: project}},
+		)
+	} else {
+		// Aliases without aggregation: rename via $project
+		project := bson.D{}
+		includesID := false
+		for _, item := range stmt.items {
+			if item.star {
+				return nil, fmt.Errorf("SELECT * cannot be combined with column aliases")
+			}
+			if item.column == "_id" && item.alias == "" {
+				includesID = true
+			}
+			if item.alias != "" {
+				project = append(project, bson.E{Key: item.alias, Value: "$" + item.column})
+				outputNames[item.column] = item.alias
+			} else {
+				project = append(project, bson.E{Key: item.column, Value: 1})
+			}
+		}
+		if !includesID {
+			project = append(project, bson.E{Key: "_id", Value: 0})
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: project}})
+	}
+
+	if len(stmt.orderBy) > 0 {
+		sortDoc := bson.D{}
+		for _, o := range stmt.orderBy {
+			field := o.field
+			if name, ok := outputNames[field]; ok {
+				field = name
+			}
+			dir := 1
+			if o.desc {
+				dir = -1
+			}
+			sortDoc = append(sortDoc, bson.E{Key: field, Value: dir})
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sortDoc}})
+	}
+	if stmt.offset > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: stmt.offset}})
+	}
+	if stmt.hasLimit {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: stmt.limit}})
+	}
+	return pipeline, nil
+}
+
+// accumulator returns the $group accumulator for an aggregate select item.
+func accumulator(item selectItem) bson.D {
+	if item.agg == "COUNT" {
+		if item.star {
+			return bson.D{{Key: "$sum", Value: 1}}
+		}
+		// COUNT(col) counts non-null values; null and missing sort below every other value
+		return bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$gt", Value: bson.A{"$" + item.column, nil}}}, 1, 0,
+		}}}}}
+	}
+	return bson.D{{Key: "$" + strings.ToLower(item.agg), Value: "$" + item.column}}
+}
+
+// defaultAggName names an unaliased aggregate, e.g. "count" or "sum_price".
+func defaultAggName(item selectItem) string {
+	if item.star {
+		return "count"
+	}
+	return strings.ToLower(item.agg) + "_" + safeName(item.column)
+}
+
+// safeName makes a dotted path usable as a single output field name.
+func safeName(field string) string {
+	return strings.ReplaceAll(field, ".", "_")
+}
+
+// buildFilter converts a WHERE expression into a MongoDB filter.
+func buildFilter(e expr) (bson.D, error) {
+	switch n := e.(type) {
+	case compareExpr:
+		if n.op == "=" {
+			return bson.D{{Key: n.field, Value: n.value}}, nil
+		}
+		op, ok := comparisonOps[n.op]
+		if !ok {
+			return nil, fmt.Errorf("unsupported operator %s", n.op)
+		}
+		return bson.D{{Key: n.field, Value: bson.D{{Key: op, Value: n.value}}}}, nil
+
+	case inExpr:
+		op := "$in"
+		if n.not {
+			op = "$nin"
+		}
+		return bson.D{{Key: n.field, Value: bson.D{{Key: op, Value: bson.A(n.values)}}}}, nil
+
+	case likeExpr:
+		regex := bson.D{{Key: "$regex", Value: likeToRegex(n.pattern)}}
+		if n.insensitive {
+			regex = append(regex, bson.E{Key: "$options", Value: "i"})
+		}
+		if n.not {
+			return bson.D{{Key: n.field, Value: bson.D{{Key: "$not", Value: regex}}}}, nil
+		}
+		return bson.D{{Key: n.field, Value: regex}}, nil
+
+	case nullExpr:
+		if n.not {
+			return bson.D{{Key: n.field, Value: bson.D{{Key: "$ne", Value: nil}}}}, nil
+		}
+		return bson.D{{Key: n.field, Value: nil}}, nil
+
+	case betweenExpr:
+		if n.not {
+			return bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: n.field, Value: bson.D{{Key: "$lt", Value: n.low}}}},
+				bson.D{{Key: n.field, Value: bson.D{{Key: "$gt", Value: n.high}}}},
+			}}}, nil
+		}
+		return bson.D{{Key: n.field, Value: bson.D{{Key: "$gte", Value: n.low}, {Key: "$lte", Value: n.high}}}}, nil
+
+	case notExpr:
+		inner, err := buildFilter(n.inner)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "$nor", Value: bson.A{inner}}}, nil
+
+	case andExpr:
+		parts, err := buildFilters(n.parts)
+		if err != nil {
+			return nil, err
+		}
+		if merged, ok := mergeConditions(parts); ok {
+			return merged, nil
+		}
+		return bson.D{{Key: "$and", Value: toArray(parts)}}, nil
+
+	case orExpr:
+		parts, err := buildFilters(n.parts)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "$or", Value: toArray(parts)}}, nil
+	}
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+// comparisonOps maps SQL comparison operators to MongoDB query operators.
+var comparisonOps = map[string]string{
+	"!=": "$ne",
+	"<":  "$lt",
+	"<=": "$lte",
+	">":  "$gt",
+	">=": "$gte",
+}
+
+func buildFilters(exprs []expr) ([]bson.D, error) {
+	parts := make([]bson.D, 0, len(exprs))
+	for _, e := range exprs {
+		f, err := buildFilter(e)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, f)
+	}
+	return parts, nil
+}
+
+func toArray(parts []bson.D) bson.A {
+	arr := make(bson.A, len(parts))
+	for i, p := range parts {
+		arr[i] = p
+	}
+	return arr
+}
+
+// mergeConditions combines AND-ed conditions into a single document when every condition
+// targets a distinct field, giving the more idiomatic {a: 1, b: {$gt: 2}} form.
+func mergeConditions(parts []bson.D) (bson.D, bool) {
+	merged := bson.D{}
+	seen := map[string]bool{}
+	for _, p := range parts {
+		for _, e := range p {
+			if strings.HasPrefix(e.Key, "$") || seen[e.Key] {
+				return nil, false
+			}
+			seen[e.Key] = true
+			merged = append(merged, e)
+		}
+	}
+	return merged, true
+}
+
+// likeToRegex converts a SQL LIKE pattern (% and _ wildcards) into an anchored regex,
+// dropping anchors where the pattern starts or ends with %.
+func likeToRegex(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	body := sb.String()
+
+	prefix, suffix := "^", "$"
+	if strings.HasPrefix(body, ".*") {
+		body = strings.TrimPrefix(body, ".*")
+		prefix = ""
+	}
+	if strings.HasSuffix(body, ".*") {
+		body = strings.TrimSuffix(body, ".*")
+		suffix = ""
+	}
+	return prefix + body + suffix
+}
+
+// marshalRelaxed renders a document or array as relaxed Extended JSON.
+func marshalRelaxed(v interface{}) (string, error) {
+	b, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, false, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal query: %w", err)
+	}
+	out := strings.TrimSpace(string(b))
+	out = strings.TrimPrefix(out, `{"v":`)
+	out = strings.TrimSuffix(out, "}")
+	return out, nil
+}
@@ -0,0 +1,213 @@
+package sqlquery
+
+import "testing"
+
+func TestTranslate_Find(t *testing.T) {
+	tests := []struct {
+		name       string
+		sql        string
+		collection string
+		filter     string
+		projection string
+		sort       string
+		skip       int64
+		limit      int64
+	}{
+		{
+			name:       "select all",
+			sql:        "SELECT * FROM users",
+			collection: "users",
+			filter:     `{}`,
+		},
+		{
+			name:       "columns and equality",
+			sql:        "SELECT name, email FROM users WHERE active = TRUE",
+			collection: "users",
+			filter:     `{"active":true}`,
+			projection: `{"name":1,"email":1,"_id":0}`,
+		},
+		{
+			name:       "and merges distinct fields",
+			sql:        "SELECT * FROM orders WHERE status = 'paid' AND total >= 100",
+			collection: "orders",
+			filter:     `{"status":"paid","total":{"$gte":100}}`,
+		},
+		{
+			name:       "and on same field uses $and",
+			sql:        "SELECT * FROM orders WHERE total > 1 AND total < 5",
+			collection: "orders",
+			filter:     `{"$and":[{"total":{"$gt":1}},{"total":{"$lt":5}}]}`,
+		},
+		{
+			name:       "or and parentheses",
+			sql:        "SELECT * FROM t WHERE a = 1 AND (b = 'x' OR b <> 'y')",
+			collection: "t",
+			filter:     `{"$and":[{"a":1},{"$or":[{"b":"x"},{"b":{"$ne":"y"}}]}]}`,
+		},
+		{
+			name:       "in, not in, null",
+			sql:        "SELECT * FROM t WHERE a IN (1, 2) AND b NOT IN ('x') AND c IS NOT NULL AND d IS NULL",
+			collection: "t",
+			filter:     `{"a":{"$in":[1,2]},"b":{"$nin":["x"]},"c":{"$ne":null},"d":null}`,
+		},
+		{
+			name:       "like and between",
+			sql:        "SELECT * FROM t WHERE name LIKE 'Jo%' AND age BETWEEN 18 AND 30.5",
+			collection: "t",
+			filter:     `{"name":{"$regex":"^Jo"},"age":{"$gte":18,"$lte":30.5}}`,
+		},
+		{
+			name:       "not like ilike",
+			sql:        "SELECT * FROM t WHERE name NOT ILIKE '%smith'",
+			collection: "t",
+			filter:     `{"name":{"$not":{"$regex":"smith$","$options":"i"}}}`,
+		},
+		{
+			name:       "not expression",
+			sql:        "SELECT * FROM t WHERE NOT (a = 1 OR a = 2)",
+			collection: "t",
+			filter:     `{"$nor":[{"$or":[{"a":1},{"a":2}]}]}`,
+		},
+		{
+			name:       "order, limit, offset, negative numbers, quoted identifiers",
+			sql:        "select _id, `address.city` from \"people\" where score > -5 order by age desc, name limit 10 offset 20;",
+			collection: "people",
+			filter:     `{"score":{"$gt":-5}}`,
+			projection: `{"_id":1,"address.city":1}`,
+			sort:       "-age,name",
+			skip:       20,
+			limit:      10,
+		},
+		{
+			name:       "escaped quote",
+			sql:        "SELECT * FROM t WHERE name = 'O''Brien'",
+			collection: "t",
+			filter:     `{"name":"O'Brien"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Translate(tt.sql)
+			if err != nil {
+				t.Fatalf("Translate failed: %v", err)
+			}
+			if got.IsAggregation {
+				t.Fatalf("Expected find translation, got pipeline %s", got.Pipeline)
+			}
+			if got.Collection != tt.collection {
+				t.Errorf("collection = %q, want %q", got.Collection, tt.collection)
+			}
+			if got.Filter != tt.filter {
+				t.Errorf("filter = %s, want %s", got.Filter, tt.filter)
+			}
+			if got.Projection != tt.projection {
+				t.Errorf("projection = %s, want %s", got.Projection, tt.projection)
+			}
+			if got.Sort != tt.sort {
+				t.Errorf("sort = %q, want %q", got.Sort, tt.sort)
+			}
+			if got.Skip != tt.skip || got.Limit != tt.limit {
+				t.Errorf("skip/limit = %d/%d, want %d/%d", got.Skip, got.Limit, tt.skip, tt.limit)
+			}
+		})
+	}
+}
+
+func TestTranslate_Aggregation(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		pipeline string
+	}{
+		{
+			name:     "count all",
+			sql:      "SELECT COUNT(*) FROM users WHERE active = true",
+			pipeline: `[{"$match":{"active":true}},{"$group":{"_id":null,"count":{"$sum":1}}},{"$project":{"_id":0,"count":1}}]`,
+		},
+		{
+			name: "group by with aggregates and order",
+			sql:  "SELECT status, COUNT(*) AS n, SUM(total) FROM orders GROUP BY status ORDER BY n DESC LIMIT 5",
+			pipeline: `[{"$group":{"_id":"$status","n":{"$sum":1},"sum_total":{"$sum":"$total"}}},` +
+				`{"$project":{"_id":0,"status":"$_id","n":1,"sum_total":1}},{"$sort":{"n":-1}},{"$limit":5}]`,
+		},
+		{
+			name: "multi-column group by",
+			sql:  "SELECT a.b, c, AVG(price) FROM t GROUP BY a.b, c ORDER BY a.b",
+			pipeline: `[{"$group":{"_id":{"a_b":"$a.b","c":"$c"},"avg_price":{"$avg":"$price"}}},` +
+				`{"$project":{"_id":0,"a_b":"$_id.a_b","c":"$_id.c","avg_price":1}},{"$sort":{"a_b":1}}]`,
+		},
+		{
+			name:     "count column",
+			sql:      "SELECT COUNT(email) FROM users",
+			pipeline: `[{"$group":{"_id":null,"count_email":{"$sum":{"$cond":[{"$gt":["$email",null]},1,0]}}}},{"$project":{"_id":0,"count_email":1}}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Translate(tt.sql)
+			if err != nil {
+				t.Fatalf("Translate failed: %v", err)
+			}
+			if !got.IsAggregation {
+				t.Fatal("Expected aggregation translation")
+			}
+			if got.Pipeline != tt.pipeline {
+				t.Errorf("pipeline =\n%s\nwant\n%s", got.Pipeline, tt.pipeline)
+			}
+		})
+	}
+
+	// Aliases alone rename fields via $project
+	got, err := Translate("SELECT name AS fullName, age FROM users ORDER BY name")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	want := `[{"$project":{"fullName":"$name","age":1,"_id":0}},{"$sort":{"fullName":1}}]`
+	if got.Pipeline != want {
+		t.Errorf("alias pipeline = %s, want %s", got.Pipeline, want)
+	}
+}
+
+func TestTranslate_Errors(t *testing.T) {
+	tests := []string{
+		"",
+		"DELETE FROM users",
+		"SELECT * FROM",
+		"SELECT DISTINCT name FROM users",
+		"SELECT * FROM a JOIN b",
+		"SELECT * FROM a, b",
+		"SELECT name, COUNT(*) FROM users GROUP BY status",
+		"SELECT * FROM users GROUP BY status",
+		"SELECT UPPER(name) FROM users",
+		"SELECT * FROM users WHERE a = b",
+		"SELECT * FROM users WHERE name = 'open",
+		"SELECT * FROM users LIMIT -1",
+		"SELECT * FROM users; DROP TABLE users",
+		"SELECT * FROM users WHERE a",
+		"SELECT status FROM t GROUP BY status HAVING COUNT(*) > 1",
+	}
+
+	for _, sql := range tests {
+		if got, err := Translate(sql); err == nil {
+			t.Errorf("Translate(%q) expected error, got %+v", sql, got)
+		}
+	}
+}
+
+func TestLikeToRegex(t *testing.T) {
+	tests := map[string]string{
+		"abc":    "^abc$",
+		"abc%":   "^abc",
+		"%abc":   "abc$",
+		"%a_c%":  "a.c",
+		"a.b%":   `^a\.b`,
+		"100\\%": `^100\\`,
+	}
+	for pattern, want := range tests {
+		if got := likeToRegex(pattern); got != want {
+			t.Errorf("likeToRegex(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// AlertRuleService persists the alert rules of each connection.
+type AlertRuleService struct {
+	configDir string
+	rules     map[string][]types.AlertRule // Connection ID -> rules
+	mu        sync.RWMutex
+}
+
+// NewAlertRuleService creates a new alert rule service.
+func NewAlertRuleService(configDir string) *AlertRuleService {
+	svc := &AlertRuleService{
+		configDir: configDir,
+		rules:     make(map[string][]types.AlertRule),
+	}
+	svc.loadRules()
+	return svc
+}
+
+// alertRulesFile returns the path to the alert rules file.
+func (s *AlertRuleService) alertRulesFile() string {
+	return filepath.Join(s.configDir, "alert_rules.json")
+}
+
+// loadRules loads alert rules from disk.
+func (s *AlertRuleService) loadRules() {
+	data, err := os.ReadFile(s.alertRulesFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: failed to load alert rules: %v\n", err)
+		}
+		return
+	}
+	var rules map[string][]types.AlertRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		fmt.Printf("Warning: failed to parse alert rules: %v\n", err)
+		return
+	}
+	if rules != nil {
+		s.rules = rules
+	}
+}
+
+// persistRules saves alert rules to disk.
+func (s *AlertRuleService) persistRules() error {
+	data, err := json.MarshalIndent(s.rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.alertRulesFile(), data, 0600)
+}
+
+// GetAlertRules returns the alert rules of a connection.
+func (s *AlertRuleService) GetAlertRules(connID string) []types.AlertRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]types.AlertRule{}, s.rules[connID]...)
+}
+
+// SetAlertRules replaces the alert rules of a connection. Rules without an ID get one.
+func (s *AlertRuleService) SetAlertRules(connID string, rules []types.AlertRule) error {
+	ids := make(map[string]bool, len(rules))
+	for i := range rules {
+		r := &rules[i]
+		switch r.Metric {
+		case types.AlertMetricConnections, types.AlertMetricReplicationLag:
+		case types.AlertMetricDiskUsage:
+			if r.Threshold > 100 {
+				return fmt.Errorf("disk usage threshold cannot be above 100%%")
+			}
+		default:
+			return fmt.Errorf("unknown alert metric %q", r.Metric)
+		}
+		if r.Threshold < 0 {
+			return fmt.Errorf("alert threshold cannot be negative")
+		}
+		if r.ID == "" {
+			r.ID = uuid.New().String()
+		}
+		if ids[r.ID] {
+			return fmt.Errorf("duplicate alert rule ID %q", r.ID)
+		}
+		ids[r.ID] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(rules) == 0 {
+		delete(s.rules, connID)
+	} else {
+		s.rules[connID] = rules
+	}
+	if err := s.persistRules(); err != nil {
+		return fmt.Errorf("failed to save alert rules: %w", err)
+	}
+	return nil
+}
+
+// DeleteRulesForConnection removes the alert rules of a connection.
+func (s *AlertRuleService) DeleteRulesForConnection(connID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[connID]; !ok {
+		return nil
+	}
+	delete(s.rules, connID)
+	return s.persistRules()
+}
+
+// ConnectionIDs returns the IDs of the connections that have alert rules.
+func (s *AlertRuleService) ConnectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.rules))
+	for id := range s.rules {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestAlertRuleService_SetAndGet(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewAlertRuleService(dir)
+
+	rules := []types.AlertRule{
+		{Metric: types.AlertMetricConnections, Threshold: 500, Enabled: true},
+		{ID: "lag", Metric: types.AlertMetricReplicationLag, Threshold: 30, Enabled: true},
+	}
+	if err := svc.SetAlertRules("conn-1", rules); err != nil {
+		t.Fatalf("SetAlertRules failed: %v", err)
+	}
+
+	got := svc.GetAlertRules("conn-1")
+	if len(got) != 2 || got[0].ID == "" || got[1].ID != "lag" {
+		t.Fatalf("unexpected rules: %+v", got)
+	}
+	if other := svc.GetAlertRules("conn-2"); len(other) != 0 {
+		t.Errorf("expected no rules for conn-2, got %+v", other)
+	}
+
+	// Rules survive a restart
+	reloaded := NewAlertRuleService(dir)
+	if got := reloaded.GetAlertRules("conn-1"); len(got) != 2 || got[1].Threshold != 30 {
+		t.Errorf("rules not persisted: %+v", got)
+	}
+
+	if err := reloaded.DeleteRulesForConnection("conn-1"); err != nil {
+		t.Fatalf("DeleteRulesForConnection failed: %v", err)
+	}
+	if got := NewAlertRuleService(dir).GetAlertRules("conn-1"); len(got) != 0 {
+		t.Errorf("expected rules to be deleted, got %+v", got)
+	}
+}
+
+func TestAlertRuleService_Validation(t *testing.T) {
+	svc := NewAlertRuleService(t.TempDir())
+	tests := []struct {
+		name   string
+		rules  []types.AlertRule
+		errMsg string
+	}{
+		{"unknown metric", []types.AlertRule{{Metric: "cpu", Threshold: 1}}, "unknown alert metric"},
+		{"negative threshold", []types.AlertRule{{Metric: types.AlertMetricConnections, Threshold: -1}}, "negative"},
+		{"disk over 100", []types.AlertRule{{Metric: types.AlertMetricDiskUsage, Threshold: 101}}, "above 100%"},
+		{"duplicate ID", []types.AlertRule{
+			{ID: "a", Metric: types.AlertMetricConnections},
+			{ID: "a", Metric: types.AlertMetricDiskUsage},
+		}, "duplicate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.SetAlertRules("conn-1", tt.rules)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/credential"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// connectionBackup is the decrypted content of a connection backup file.
+type connectionBackup struct {
+	CreatedAt   time.Time                  `json:"createdAt"`
+	Connections []types.ExtendedConnection `json:"connections"`
+}
+
+// ExportAllConnections writes every saved connection, including its passwords, SSH keys, and
+// TLS material, to a backup file at path encrypted with passphrase. Each connection keeps its
+// ID and folder path so a restore recreates the same setup. Returns the number of connections
+// written.
+func (s *ConnectionService) ExportAllConnections(path, passphrase string) (int, error) {
+	saved, _ := s.ListSavedConnections()
+	conns := make([]types.ExtendedConnection, 0, len(saved))
+	for _, c := range saved {
+		conn, err := s.GetExtendedConnection(c.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load connection %q: %w", c.Name, err)
+		}
+		conn.FolderPath = s.folderPath(conn.FolderID)
+		conn.FolderID = ""
+		conns = append(conns, conn)
+	}
+
+	data, err := encodeConnectionBackup(conns, passphrase)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return 0, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return len(conns), nil
+}
+
+// RestoreConnectionBackup restores the connections of a backup file created by
+// ExportAllConnections, recreating their folders. Connections that are already saved, with
+// the same ID or the same name and URI, are skipped.
+func (s *ConnectionService) RestoreConnectionBackup(path, passphrase string) (*types.ConnectionImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	conns, err := decodeConnectionBackup(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.ConnectionImportResult{Warnings: []string{}}
+	for _, conn := range conns {
+		if _, err := s.GetSavedConnection(conn.ID); err == nil || s.hasSavedConnection(conn.Name, conn.MongoURI) {
+			result.Skipped++
+			continue
+		}
+		if err := s.SaveExtendedConnection(conn); err != nil {
+			return result, fmt.Errorf("failed to restore connection %q: %w", conn.Name, err)
+		}
+		result.Imported++
+	}
+	return result, nil
+}
+
+// folderPath returns the folder names from the root down to the folder with folderID.
+func (s *ConnectionService) folderPath(folderID string) []string {
+	s.state.Mu.RLock()
+	defer s.state.Mu.RUnlock()
+	byID := make(map[string]types.Folder, len(s.state.Folders))
+	for _, f := range s.state.Folders {
+		byID[f.ID] = f
+	}
+	var path []string
+	for id := folderID; id != ""; {
+		f, ok := byID[id]
+		if !ok || len(path) > len(byID) { // Guard against parent cycles
+			break
+		}
+		path = append([]string{f.Name}, path...)
+		id = f.ParentID
+	}
+	return path
+}
+
+// encodeConnectionBackup encrypts connections into the content of a backup file.
+func encodeConnectionBackup(conns []types.ExtendedConnection, passphrase string) ([]byte, error) {
+	plaintext, err := json.Marshal(connectionBackup{CreatedAt: time.Now(), Connections: conns})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode connections: %w", err)
+	}
+	return credential.EncryptBackup(plaintext, passphrase)
+}
+
+// decodeConnectionBackup decrypts the connections of a backup file.
+func decodeConnectionBackup(data []byte, passphrase string) ([]types.ExtendedConnection, error) {
+	plaintext, err := credential.DecryptBackup(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var backup connectionBackup
+	if err := json.Unmarshal(plaintext, &backup); err != nil {
+		return nil, fmt.Errorf("corrupted backup: %w", err)
+	}
+	for i, conn := range backup.Connections {
+		if conn.ID == "" || conn.Name == "" || conn.MongoURI == "" {
+			return nil, fmt.Errorf("corrupted backup: connection %d is incomplete", i+1)
+		}
+	}
+	return backup.Connections, nil
+}
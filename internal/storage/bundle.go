@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/credential"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// bulkBundleVersion is the version of the bulk share format this build can import.
+const bulkBundleVersion = 1
+
+// ImportConnectionBundle decrypts a connection shared with ExportEncryptedConnection and saves
+// it as a new connection, recreating its folder path. Its credentials are stored encrypted like
+// those of any saved connection. A connection already saved with the same name and URI is
+// skipped.
+func (s *ConnectionService) ImportConnectionBundle(bundleJSON, key string) (*types.ConnectionImportResult, error) {
+	conn, err := ParseConnectionBundle(bundleJSON, key)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ConnectionImportResult{Warnings: []string{}}
+	if err := s.saveImportedConnections([]types.ExtendedConnection{conn}, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ImportConnectionBundles imports the connections of a bulk share created with
+// ExportEncryptedConnections. With an empty key, the key included in the share is used.
+// Connections that cannot be decrypted are reported as warnings.
+func (s *ConnectionService) ImportConnectionBundles(bulkJSON, key string) (*types.ConnectionImportResult, error) {
+	conns, warnings, err := ParseConnectionBundles(bulkJSON, key)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ConnectionImportResult{Warnings: append([]string{}, warnings...)}
+	if err := s.saveImportedConnections(conns, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ParseConnectionBundle decrypts a connection share bundle and validates the connection in it.
+func ParseConnectionBundle(bundleJSON, key string) (types.ExtendedConnection, error) {
+	data, err := credential.DecryptFromSharing(strings.TrimSpace(bundleJSON), strings.TrimSpace(key))
+	if err != nil {
+		return types.ExtendedConnection{}, err
+	}
+
+	var conn types.ExtendedConnection
+	if err := json.Unmarshal(data, &conn); err != nil {
+		return types.ExtendedConnection{}, fmt.Errorf("invalid connection in bundle: %w", err)
+	}
+	if conn.MongoURI == "" {
+		// Bundles of unsaved connection forms hold form fields rather than a connection
+		return types.ExtendedConnection{}, fmt.Errorf("bundle does not contain a saved connection")
+	}
+	if !strings.HasPrefix(conn.MongoURI, "mongodb://") && !strings.HasPrefix(conn.MongoURI, "mongodb+srv://") {
+		return types.ExtendedConnection{}, fmt.Errorf("bundle contains an invalid connection URI")
+	}
+	if strings.TrimSpace(conn.Name) == "" {
+		return types.ExtendedConnection{}, fmt.Errorf("bundle contains a connection without a name")
+	}
+	if err := core.ValidateEnvironment(conn.Environment); err != nil {
+		return types.ExtendedConnection{}, err
+	}
+
+	// Always import as a new connection, even if the sender's bundle kept its ID
+	conn.ID = ""
+	conn.FolderID = ""
+	conn.CreatedAt = time.Now()
+	conn.LastAccessedAt = time.Time{}
+	return conn, nil
+}
+
+// ParseConnectionBundles decrypts the connections of a bulk share, with a warning for each
+// connection that could not be imported.
+func ParseConnectionBundles(bulkJSON, key string) ([]types.ExtendedConnection, []string, error) {
+	var bulk types.BulkConnectionShareResult
+	if err := json.Unmarshal([]byte(strings.TrimSpace(bulkJSON)), &bulk); err != nil {
+		return nil, nil, fmt.Errorf("invalid bulk bundle format: %w", err)
+	}
+	if bulk.Version != bulkBundleVersion {
+		return nil, nil, fmt.Errorf("unsupported bulk bundle version %d (update MongoPal to import)", bulk.Version)
+	}
+	if key == "" {
+		key = bulk.Key
+	}
+	if key == "" {
+		return nil, nil, fmt.Errorf("decryption key is required")
+	}
+
+	var conns []types.ExtendedConnection
+	var warnings []string
+	for i, entry := range bulk.Connections {
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("Connection %d", i+1)
+		}
+		conn, err := ParseConnectionBundle(entry.Bundle, key)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: skipped, %v", name, err))
+			continue
+		}
+		conns = append(conns, conn)
+	}
+	if len(conns) == 0 && len(warnings) > 0 {
+		return nil, warnings, fmt.Errorf("no connection in the bundle could be imported: %s", warnings[0])
+	}
+	return conns, warnings, nil
+}
@@ -0,0 +1,330 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/credential"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// Compass encrypts the secrets of exported connections with AES-256-GCM under a key derived
+// from the export passphrase; the base64 payload is salt | iv | tag | ciphertext.
+const (
+	compassSaltSize   = 64
+	compassIVSize     = 16
+	compassTagSize    = 16
+	compassIterations = 100000
+)
+
+// compassFolder is the folder imported Compass connections are placed in; favorites and
+// recent connections get a subfolder each, as Compass groups them.
+const compassFolder = "Compass"
+
+// ErrCompassPassphrase is returned when the secrets of a Compass export cannot be decrypted.
+var ErrCompassPassphrase = errors.New("wrong passphrase for the Compass export")
+
+// compassColors maps Compass favorite colors to their hex values.
+var compassColors = map[string]string{
+	"color1":  "#5fc86e",
+	"color2":  "#326fde",
+	"color3":  "#deb342",
+	"color4":  "#d4366e",
+	"color5":  "#59c1e2",
+	"color6":  "#2c5f4a",
+	"color7":  "#d66531",
+	"color8":  "#773819",
+	"color9":  "#3b8196",
+	"color10": "#ababab",
+}
+
+// compassConnection is a connection in a Compass export file or connection store.
+type compassConnection struct {
+	ID                string `json:"id"`
+	ConnectionOptions struct {
+		ConnectionString string `json:"connectionString"`
+		SSHTunnel        *struct {
+			Host                  string          `json:"host"`
+			Port                  json.RawMessage `json:"port"`
+			Username              string          `json:"username"`
+			Password              string          `json:"password"`
+			IdentityKeyFile       string          `json:"identityKeyFile"`
+			IdentityKeyPassphrase string          `json:"identityKeyPassphrase"`
+		} `json:"sshTunnel"`
+	} `json:"connectionOptions"`
+	Favorite *struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"favorite"`
+	SavedConnectionType string `json:"savedConnectionType"` // "favorite", "recent", or "autoConnectInfo"
+	ConnectionSecrets   string `json:"connectionSecrets"`
+}
+
+// compassSecrets are the credentials Compass keeps apart from the connection options.
+type compassSecrets struct {
+	Password                      string `json:"password"`
+	SSHTunnelPassword             string `json:"sshTunnelPassword"`
+	SSHTunnelPassphrase           string `json:"sshTunnelPassphrase"`
+	TLSCertificateKeyFilePassword string `json:"tlsCertificateKeyFilePassword"`
+}
+
+// CompassConnectionsDir returns where Compass stores its connections, one JSON file each.
+// Their secrets are encrypted with the OS keychain of Compass and cannot be imported.
+func CompassConnectionsDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "MongoDB Compass", "Connections")
+}
+
+// ImportFromCompass imports the connections of a Compass export file, decrypting their
+// passwords with passphrase if the export is protected. With an empty path, the connections
+// Compass stores locally are imported instead, without passwords. Connections already saved
+// with the same name and URI are skipped.
+func (s *ConnectionService) ImportFromCompass(path, passphrase string) (*types.ConnectionImportResult, error) {
+	var files []string
+	if path != "" {
+		files = []string{path}
+	} else {
+		dir := CompassConnectionsDir()
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no Compass connections found in %s; export them from Compass and import the file", dir)
+		}
+		sort.Strings(matches)
+		files = matches
+	}
+
+	var conns []types.ExtendedConnection
+	result := &types.ConnectionImportResult{Warnings: []string{}}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		parsed, warnings, err := ParseCompassConnections(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		conns = append(conns, parsed...)
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+
+	if err := s.saveImportedConnections(conns, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// saveImportedConnections saves connections imported from another tool, counting them on
+// result. Connections already saved with the same name and URI are skipped.
+func (s *ConnectionService) saveImportedConnections(conns []types.ExtendedConnection, result *types.ConnectionImportResult) error {
+	for _, conn := range conns {
+		if s.hasSavedConnection(conn.Name, conn.MongoURI) {
+			result.Skipped++
+			continue
+		}
+		if err := s.SaveExtendedConnection(conn); err != nil {
+			return fmt.Errorf("failed to save connection %q: %w", conn.Name, err)
+		}
+		result.Imported++
+	}
+	return nil
+}
+
+// hasSavedConnection reports whether a connection with the name and URI (ignoring its
+// password) is already saved.
+func (s *ConnectionService) hasSavedConnection(name, uri string) bool {
+	cleanURI, _, _ := credential.ExtractPasswordFromURI(uri)
+	s.state.Mu.RLock()
+	defer s.state.Mu.RUnlock()
+	for _, c := range s.state.SavedConnections {
+		if c.Name == name && c.URI == cleanURI {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCompassConnections converts the connections of a Compass export file ({"connections":
+// [...]}) or of a single stored Compass connection ({"connectionInfo": {...}}).
+func ParseCompassConnections(data []byte, passphrase string) ([]types.ExtendedConnection, []string, error) {
+	var file struct {
+		Connections    []compassConnection `json:"connections"`
+		ConnectionInfo *compassConnection  `json:"connectionInfo"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("not a Compass connections file: %w", err)
+	}
+	exported := file.ConnectionInfo == nil
+	entries := file.Connections
+	if !exported {
+		entries = []compassConnection{*file.ConnectionInfo}
+	}
+
+	var conns []types.ExtendedConnection
+	var warnings []string
+	for _, entry := range entries {
+		// Compass keeps the connection it auto-connects to on launch in the same store
+		if entry.SavedConnectionType == "autoConnectInfo" || entry.ConnectionOptions.ConnectionString == "" {
+			continue
+		}
+		conn, err := convertCompassConnection(entry)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+
+		if entry.ConnectionSecrets != "" {
+			switch {
+			case !exported:
+				warnings = append(warnings, fmt.Sprintf("%s: passwords saved in Compass are protected by its keychain and were not imported", conn.Name))
+			case passphrase == "":
+				warnings = append(warnings, fmt.Sprintf("%s: the export is passphrase protected; enter the passphrase to import passwords", conn.Name))
+			default:
+				secrets, err := decryptCompassSecrets(entry.ConnectionSecrets, passphrase)
+				if err != nil {
+					return nil, nil, err
+				}
+				applyCompassSecrets(&conn, secrets)
+			}
+		}
+		conns = append(conns, conn)
+	}
+	return conns, warnings, nil
+}
+
+// convertCompassConnection converts a Compass connection, without its secrets.
+func convertCompassConnection(entry compassConnection) (types.ExtendedConnection, error) {
+	uri := entry.ConnectionOptions.ConnectionString
+	if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		return types.ExtendedConnection{}, fmt.Errorf("skipped a connection with an invalid URI")
+	}
+
+	conn := types.ExtendedConnection{
+		MongoURI:   uri,
+		Color:      "#4CC38A",
+		CreatedAt:  time.Now(),
+		FolderPath: []string{compassFolder, "Recent"},
+	}
+	if entry.Favorite != nil && entry.Favorite.Name != "" {
+		conn.Name = entry.Favorite.Name
+		conn.FolderPath = []string{compassFolder, "Favorites"}
+		if color, ok := compassColors[entry.Favorite.Color]; ok {
+			conn.Color = color
+		} else if strings.HasPrefix(entry.Favorite.Color, "#") {
+			conn.Color = entry.Favorite.Color // Older Compass versions stored hex colors
+		}
+	} else {
+		conn.Name = compassConnectionName(uri)
+	}
+
+	if tunnel := entry.ConnectionOptions.SSHTunnel; tunnel != nil && tunnel.Host != "" {
+		conn.SSHEnabled = true
+		conn.SSHHost = tunnel.Host
+		conn.SSHUser = tunnel.Username
+		conn.SSHPassword = tunnel.Password
+		conn.SSHPassphrase = tunnel.IdentityKeyPassphrase
+		conn.SSHPort = parseCompassPort(tunnel.Port)
+		if tunnel.IdentityKeyFile != "" {
+			// Compass references the key file; MongoPal stores the key itself
+			if key, err := os.ReadFile(tunnel.IdentityKeyFile); err == nil {
+				conn.SSHPrivateKey = string(key)
+			}
+		}
+	}
+	return conn, nil
+}
+
+// applyCompassSecrets adds decrypted Compass secrets to a converted connection.
+func applyCompassSecrets(conn *types.ExtendedConnection, secrets compassSecrets) {
+	if secrets.Password != "" {
+		if uri, err := credential.InjectPasswordIntoURI(conn.MongoURI, secrets.Password); err == nil {
+			conn.MongoURI = uri
+		}
+	}
+	if secrets.SSHTunnelPassword != "" {
+		conn.SSHPassword = secrets.SSHTunnelPassword
+	}
+	if secrets.SSHTunnelPassphrase != "" {
+		conn.SSHPassphrase = secrets.SSHTunnelPassphrase
+	}
+	if secrets.TLSCertificateKeyFilePassword != "" {
+		conn.TLSKeyPassword = secrets.TLSCertificateKeyFilePassword
+	}
+}
+
+// decryptCompassSecrets decrypts the connectionSecrets of a passphrase protected export.
+func decryptCompassSecrets(encoded, passphrase string) (compassSecrets, error) {
+	var secrets compassSecrets
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) < compassSaltSize+compassIVSize+compassTagSize {
+		return secrets, fmt.Errorf("invalid encrypted secrets in the Compass export")
+	}
+	salt := raw[:compassSaltSize]
+	iv := raw[compassSaltSize : compassSaltSize+compassIVSize]
+	tag := raw[compassSaltSize+compassIVSize : compassSaltSize+compassIVSize+compassTagSize]
+	ciphertext := raw[compassSaltSize+compassIVSize+compassTagSize:]
+
+	key, err := pbkdf2.Key(sha512.New, passphrase, salt, compassIterations, 32)
+	if err != nil {
+		return secrets, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return secrets, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, compassIVSize)
+	if err != nil {
+		return secrets, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	// Go expects the tag after the ciphertext
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), nil)
+	if err != nil {
+		return secrets, ErrCompassPassphrase
+	}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return secrets, fmt.Errorf("invalid encrypted secrets in the Compass export: %w", err)
+	}
+	return secrets, nil
+}
+
+// parseCompassPort reads a port Compass may store as a number or an Extended JSON number.
+func parseCompassPort(raw json.RawMessage) int {
+	var port int
+	if json.Unmarshal(raw, &port) == nil {
+		return port
+	}
+	var ext struct {
+		Int string `json:"$numberInt"`
+	}
+	if json.Unmarshal(raw, &ext) == nil {
+		fmt.Sscanf(ext.Int, "%d", &port)
+	}
+	return port
+}
+
+// compassConnectionName names a recent connection, which Compass leaves unnamed, by its hosts.
+func compassConnectionName(uri string) string {
+	rest := uri[strings.Index(uri, "://")+3:]
+	if at := strings.LastIndex(strings.SplitN(rest, "/", 2)[0], "@"); at >= 0 {
+		rest = rest[at+1:]
+	}
+	hosts := strings.FieldsFunc(rest, func(r rune) bool { return r == '/' || r == '?' })
+	if len(hosts) == 0 {
+		return "Compass connection"
+	}
+	return hosts[0]
+}
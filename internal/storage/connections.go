@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,6 +31,16 @@ func NewConnectionService(state *core.AppState, storage *Service, encStorage *cr
 
 // SaveExtendedConnection saves a connection with all credentials to encrypted storage.
 func (s *ConnectionService) SaveExtendedConnection(conn types.ExtendedConnection) error {
+	if err := core.ValidateEnvironment(conn.Environment); err != nil {
+		return err
+	}
+	if err := core.ValidateDatabasePatterns(conn.AllowedDatabases); err != nil {
+		return err
+	}
+	if err := core.ValidateDatabasePatterns(conn.DeniedDatabases); err != nil {
+		return err
+	}
+
 	// Generate a new ID for imported connections that don't have one
 	if conn.ID == "" {
 		conn.ID = uuid.New().String()
@@ -63,6 +74,10 @@ func (s *ConnectionService) SaveExtendedConnection(conn types.ExtendedConnection
 		if conn.SSHPassphrase == "" {
 			conn.SSHPassphrase = existing.SSHPassphrase
 		}
+		// The pinned SSH host key stays valid as long as the connection uses the same SSH server
+		if conn.SSHHostKeyFingerprint == "" && conn.SSHHost == existing.SSHHost && conn.SSHPort == existing.SSHPort {
+			conn.SSHHostKeyFingerprint = existing.SSHHostKeyFingerprint
+		}
 		if conn.SOCKS5Password == "" {
 			conn.SOCKS5Password = existing.SOCKS5Password
 		}
@@ -145,6 +160,20 @@ func (s *ConnectionService) UpdateFolderID(connID, folderID string) error {
 	return s.encryptedStorage.SaveConnection(connID, conn)
 }
 
+// SetSSHHostKey pins the SHA256 fingerprint of a connection's SSH host key. An empty
+// fingerprint clears the pin, so the next connect pins the key the server presents.
+func (s *ConnectionService) SetSSHHostKey(connID, fingerprint string) error {
+	var conn types.ExtendedConnection
+	if err := s.encryptedStorage.LoadConnection(connID, &conn); err != nil {
+		return fmt.Errorf("failed to load connection: %w", err)
+	}
+	conn.SSHHostKeyFingerprint = fingerprint
+	if err := s.encryptedStorage.SaveConnection(connID, conn); err != nil {
+		return fmt.Errorf("failed to save connection: %w", err)
+	}
+	return nil
+}
+
 // UpdateLastAccessed updates the last accessed time for a connection.
 func (s *ConnectionService) UpdateLastAccessed(connID string) error {
 	// Load full connection from encrypted storage
@@ -220,6 +249,33 @@ func (s *ConnectionService) ListSavedConnections() ([]types.SavedConnection, err
 	return result, nil
 }
 
+// DefaultRecentConnections is how many connections ListRecentConnections returns by default.
+const DefaultRecentConnections = 10
+
+// ListRecentConnections returns the connections most recently connected to, newest first.
+// Connections never connected to are left out. A limit of 0 or less uses the default.
+func (s *ConnectionService) ListRecentConnections(limit int) []types.SavedConnection {
+	if limit <= 0 {
+		limit = DefaultRecentConnections
+	}
+	s.state.Mu.RLock()
+	recent := []types.SavedConnection{}
+	for _, c := range s.state.SavedConnections {
+		if !c.LastAccessedAt.IsZero() {
+			recent = append(recent, c)
+		}
+	}
+	s.state.Mu.RUnlock()
+
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].LastAccessedAt.After(recent[j].LastAccessedAt)
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
+
 // GetSavedConnection returns a single saved connection.
 func (s *ConnectionService) GetSavedConnection(connID string) (types.SavedConnection, error) {
 	s.state.Mu.RLock()
@@ -245,7 +301,7 @@ func (s *ConnectionService) GetExtendedConnection(connID string) (types.Extended
 func (s *ConnectionService) DeleteSavedConnection(connID string) error {
 	// Delete from encrypted storage (also removes encryption key from keyring)
 	if err := s.encryptedStorage.DeleteConnection(connID); err != nil {
-		s.state.EmitEvent("app:warning", map[string]string{
+		s.state.EmitConnectionEvent(connID, "app:warning", map[string]string{
 			"message": "Could not remove encrypted connection file",
 			"detail":  err.Error(),
 		})
@@ -263,6 +319,22 @@ func (s *ConnectionService) DeleteSavedConnection(connID string) error {
 	return &core.ConnectionNotFoundError{ConnID: connID}
 }
 
+// CleanupOrphanedCredentials removes keyring entries left behind for connections
+// that no longer exist (e.g. deleted outside the app). The keyring is probed for the
+// referencedIDs first, since the keyring index misses keys created before it existed.
+// Returns the number removed.
+func (s *ConnectionService) CleanupOrphanedCredentials(referencedIDs []string) (int, error) {
+	s.state.Mu.RLock()
+	validIDs := make(map[string]bool, len(s.state.SavedConnections))
+	for _, c := range s.state.SavedConnections {
+		validIDs[c.ID] = true
+	}
+	s.state.Mu.RUnlock()
+
+	s.encryptedStorage.IndexKeys(referencedIDs)
+	return s.encryptedStorage.DeleteOrphanedKeys(validIDs)
+}
+
 // DuplicateConnection creates a copy of a connection including all credentials.
 func (s *ConnectionService) DuplicateConnection(connID, newName string) (types.SavedConnection, error) {
 	// Load original connection from encrypted storage
@@ -382,9 +454,8 @@ func (s *ConnectionService) GetConnectionURI(connID string) (string, error) {
 
 	// Build URI from FormData if available (preferred path)
 	if extended.FormData != "" {
-		var fd types.ConnectionFormData
-		if err := json.Unmarshal([]byte(extended.FormData), &fd); err == nil {
-			return credential.BuildURIFromFormData(&fd, pw), nil
+		if uri, err := credential.BuildURIFromFormJSON(extended.FormData, pw); err == nil {
+			return uri, nil
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/peternagy/mongopal/internal/core"
 	"github.com/peternagy/mongopal/internal/credential"
@@ -594,3 +595,31 @@ func TestGetConnectionURI_FormDataWithReplicaSet(t *testing.T) {
 		t.Errorf("replicaset should not have directConnection, got %s", uri)
 	}
 }
+
+// =============================================================================
+// ListRecentConnections
+// =============================================================================
+
+func TestListRecentConnections(t *testing.T) {
+	state := core.NewAppState()
+	now := time.Now()
+	state.SavedConnections = []types.SavedConnection{
+		{ID: "old", LastAccessedAt: now.Add(-48 * time.Hour)},
+		{ID: "never"},
+		{ID: "newest", LastAccessedAt: now},
+		{ID: "yesterday", LastAccessedAt: now.Add(-24 * time.Hour)},
+	}
+	svc := NewConnectionService(state, nil, nil)
+
+	var ids []string
+	for _, c := range svc.ListRecentConnections(0) {
+		ids = append(ids, c.ID)
+	}
+	if strings.Join(ids, ",") != "newest,yesterday,old" {
+		t.Errorf("recent = %v, want newest,yesterday,old", ids)
+	}
+
+	if recent := svc.ListRecentConnections(1); len(recent) != 1 || recent[0].ID != "newest" {
+		t.Errorf("expected only the newest connection, got %+v", recent)
+	}
+}
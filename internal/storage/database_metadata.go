@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -194,3 +195,16 @@ func (s *DatabaseMetadataService) CleanupStaleDatabases(connID string, currentDa
 	}
 	return nil
 }
+
+// ConnectionIDs returns the IDs of the connections that have database metadata.
+func (s *DatabaseMetadataService) ConnectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []string
+	for key := range s.data {
+		if id, _, ok := strings.Cut(key, ":"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
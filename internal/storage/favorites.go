@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -280,3 +281,21 @@ func (s *FavoriteService) RemoveFavoritesForConnection(connID string) error {
 	}
 	return nil
 }
+
+// ConnectionIDs returns the IDs of the connections that have favorites.
+func (s *FavoriteService) ConnectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []string
+	for k := range s.collectionFavs {
+		if id, _, ok := strings.Cut(k, ":"); ok {
+			ids = append(ids, id)
+		}
+	}
+	for _, k := range s.databaseFavOrder {
+		if id, _, ok := strings.Cut(strings.TrimPrefix(k, "db:"), ":"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
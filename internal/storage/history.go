@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// MaxQueryHistoryEntries caps the number of persisted history entries.
+// The oldest entries are dropped once the cap is reached.
+const MaxQueryHistoryEntries = 500
+
+// QueryHistoryService records executed queries and persists them to disk.
+// Entries are kept newest first.
+type QueryHistoryService struct {
+	configDir string
+	entries   []types.QueryHistoryEntry
+	mu        sync.RWMutex
+}
+
+// NewQueryHistoryService creates a new query history service.
+func NewQueryHistoryService(configDir string) *QueryHistoryService {
+	svc := &QueryHistoryService{
+		configDir: configDir,
+		entries:   []types.QueryHistoryEntry{},
+	}
+	svc.loadHistory()
+	return svc
+}
+
+// historyFile returns the path to the query history file.
+func (s *QueryHistoryService) historyFile() string {
+	return filepath.Join(s.configDir, "query_history.json")
+}
+
+// loadHistory loads query history from disk.
+func (s *QueryHistoryService) loadHistory() {
+	data, err := os.ReadFile(s.historyFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: failed to load query history: %v\n", err)
+		}
+		s.entries = []types.QueryHistoryEntry{}
+		return
+	}
+	var entries []types.QueryHistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		fmt.Printf("Warning: failed to parse query history: %v\n", err)
+		s.entries = []types.QueryHistoryEntry{}
+		return
+	}
+	if len(entries) > MaxQueryHistoryEntries {
+		entries = entries[:MaxQueryHistoryEntries]
+	}
+	s.entries = entries
+}
+
+// persistHistory saves query history to disk.
+func (s *QueryHistoryService) persistHistory() error {
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.historyFile(), data, 0600)
+}
+
+// RecordQuery adds an executed query to the history.
+// Re-running the same query on the same namespace moves the existing entry to the top
+// instead of adding a duplicate.
+func (s *QueryHistoryService) RecordQuery(entry types.QueryHistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.ExecutedAt.IsZero() {
+		entry.ExecutedAt = time.Now()
+	}
+
+	for i, e := range s.entries {
+		if e.ConnectionID == entry.ConnectionID &&
+			e.Database == entry.Database &&
+			e.Collection == entry.Collection &&
+			e.Filter == entry.Filter &&
+			e.Sort == entry.Sort &&
+			e.Projection == entry.Projection {
+			entry.ID = e.ID
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+
+	s.entries = append([]types.QueryHistoryEntry{entry}, s.entries...)
+	if len(s.entries) > MaxQueryHistoryEntries {
+		s.entries = s.entries[:MaxQueryHistoryEntries]
+	}
+
+	if err := s.persistHistory(); err != nil {
+		return fmt.Errorf("failed to save query history: %w", err)
+	}
+	return nil
+}
+
+// ListHistory returns history entries, newest first, optionally filtered by connection,
+// database, and collection. A limit of 0 or less returns all matching entries.
+func (s *QueryHistoryService) ListHistory(connectionID, database, collection string, limit int) []types.QueryHistoryEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]types.QueryHistoryEntry, 0)
+	for _, e := range s.entries {
+		if connectionID != "" && e.ConnectionID != connectionID {
+			continue
+		}
+		if database != "" && e.Database != database {
+			continue
+		}
+		if collection != "" && e.Collection != collection {
+			continue
+		}
+		result = append(result, e)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// SearchHistory returns entries whose filter, sort, projection, or namespace contains
+// the search text (case-insensitive), optionally restricted to a connection.
+func (s *QueryHistoryService) SearchHistory(connectionID, text string) []types.QueryHistoryEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	needle := strings.ToLower(strings.TrimSpace(text))
+	result := make([]types.QueryHistoryEntry, 0)
+	for _, e := range s.entries {
+		if connectionID != "" && e.ConnectionID != connectionID {
+			continue
+		}
+		if needle != "" {
+			haystack := strings.ToLower(strings.Join([]string{
+				e.Database + "." + e.Collection, e.Filter, e.Sort, e.Projection,
+			}, "\n"))
+			if !strings.Contains(haystack, needle) {
+				continue
+			}
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// ClearHistory removes history entries for a connection, or all entries if connectionID is empty.
+func (s *QueryHistoryService) ClearHistory(connectionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if connectionID == "" {
+		s.entries = []types.QueryHistoryEntry{}
+		return s.persistHistory()
+	}
+
+	filtered := make([]types.QueryHistoryEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if e.ConnectionID != connectionID {
+			filtered = append(filtered, e)
+		}
+	}
+	s.entries = filtered
+	return s.persistHistory()
+}
+
+// ConnectionIDs returns the IDs of the connections that have history entries.
+func (s *QueryHistoryService) ConnectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.entries))
+	for _, e := range s.entries {
+		ids = append(ids, e.ConnectionID)
+	}
+	return ids
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func historyEntry(connID, db, coll, filter string) types.QueryHistoryEntry {
+	return types.QueryHistoryEntry{
+		ConnectionID: connID,
+		Database:     db,
+		Collection:   coll,
+		Filter:       filter,
+	}
+}
+
+func TestQueryHistoryService_RecordAndList(t *testing.T) {
+	svc := NewQueryHistoryService(t.TempDir())
+
+	if err := svc.RecordQuery(historyEntry("conn-1", "db", "users", `{"a": 1}`)); err != nil {
+		t.Fatalf("RecordQuery failed: %v", err)
+	}
+	if err := svc.RecordQuery(historyEntry("conn-1", "db", "orders", `{"b": 2}`)); err != nil {
+		t.Fatalf("RecordQuery failed: %v", err)
+	}
+	if err := svc.RecordQuery(historyEntry("conn-2", "db", "users", `{}`)); err != nil {
+		t.Fatalf("RecordQuery failed: %v", err)
+	}
+
+	all := svc.ListHistory("", "", "", 0)
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(all))
+	}
+	if all[0].ConnectionID != "conn-2" {
+		t.Errorf("Expected newest entry first, got %+v", all[0])
+	}
+	if all[0].ID == "" || all[0].ExecutedAt.IsZero() {
+		t.Error("Expected ID and ExecutedAt to be set")
+	}
+
+	if got := svc.ListHistory("conn-1", "", "", 0); len(got) != 2 {
+		t.Errorf("Expected 2 entries for conn-1, got %d", len(got))
+	}
+	if got := svc.ListHistory("conn-1", "db", "users", 0); len(got) != 1 {
+		t.Errorf("Expected 1 entry for conn-1 db.users, got %d", len(got))
+	}
+	if got := svc.ListHistory("", "", "", 1); len(got) != 1 {
+		t.Errorf("Expected limit to cap results at 1, got %d", len(got))
+	}
+}
+
+func TestQueryHistoryService_DeduplicatesRepeatedQuery(t *testing.T) {
+	svc := NewQueryHistoryService(t.TempDir())
+
+	_ = svc.RecordQuery(historyEntry("conn-1", "db", "users", `{"a": 1}`))
+	_ = svc.RecordQuery(historyEntry("conn-1", "db", "users", `{"b": 1}`))
+	_ = svc.RecordQuery(historyEntry("conn-1", "db", "users", `{"a": 1}`))
+
+	entries := svc.ListHistory("", "", "", 0)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries after duplicate, got %d", len(entries))
+	}
+	if entries[0].Filter != `{"a": 1}` {
+		t.Errorf("Expected repeated query to move to top, got %s", entries[0].Filter)
+	}
+
+	// Same filter with a different sort is a distinct query
+	withSort := historyEntry("conn-1", "db", "users", `{"a": 1}`)
+	withSort.Sort = "-createdAt"
+	_ = svc.RecordQuery(withSort)
+	if got := len(svc.ListHistory("", "", "", 0)); got != 3 {
+		t.Errorf("Expected 3 entries, got %d", got)
+	}
+}
+
+func TestQueryHistoryService_SizeCap(t *testing.T) {
+	svc := NewQueryHistoryService(t.TempDir())
+
+	for i := 0; i < MaxQueryHistoryEntries+10; i++ {
+		entry := historyEntry("conn-1", "db", "c", fmt.Sprintf(`{"n": %d}`, i))
+		if err := svc.RecordQuery(entry); err != nil {
+			t.Fatalf("RecordQuery failed: %v", err)
+		}
+	}
+
+	if got := len(svc.ListHistory("", "", "", 0)); got != MaxQueryHistoryEntries {
+		t.Errorf("Expected history capped at %d, got %d", MaxQueryHistoryEntries, got)
+	}
+}
+
+func TestQueryHistoryService_Search(t *testing.T) {
+	svc := NewQueryHistoryService(t.TempDir())
+
+	_ = svc.RecordQuery(historyEntry("conn-1", "shop", "orders", `{"status": "PAID"}`))
+	_ = svc.RecordQuery(historyEntry("conn-1", "shop", "users", `{"email": "a@b.c"}`))
+	_ = svc.RecordQuery(historyEntry("conn-2", "shop", "orders", `{"status": "open"}`))
+
+	if got := svc.SearchHistory("", "status"); len(got) != 2 {
+		t.Errorf("Expected 2 matches for 'status', got %d", len(got))
+	}
+	if got := svc.SearchHistory("", "paid"); len(got) != 1 {
+		t.Errorf("Expected case-insensitive match for 'paid', got %d", len(got))
+	}
+	if got := svc.SearchHistory("conn-1", "shop.orders"); len(got) != 1 {
+		t.Errorf("Expected 1 namespace match on conn-1, got %d", len(got))
+	}
+	if got := svc.SearchHistory("", ""); len(got) != 3 {
+		t.Errorf("Expected empty search to return all entries, got %d", len(got))
+	}
+}
+
+func TestQueryHistoryService_ClearAndPersist(t *testing.T) {
+	tempDir := t.TempDir()
+	svc := NewQueryHistoryService(tempDir)
+
+	_ = svc.RecordQuery(historyEntry("conn-1", "db", "a", `{}`))
+	_ = svc.RecordQuery(historyEntry("conn-2", "db", "b", `{}`))
+
+	if err := svc.ClearHistory("conn-1"); err != nil {
+		t.Fatalf("ClearHistory failed: %v", err)
+	}
+
+	// Reload from disk
+	svc2 := NewQueryHistoryService(tempDir)
+	entries := svc2.ListHistory("", "", "", 0)
+	if len(entries) != 1 || entries[0].ConnectionID != "conn-2" {
+		t.Fatalf("Expected only conn-2 history after reload, got %+v", entries)
+	}
+
+	if err := svc2.ClearHistory(""); err != nil {
+		t.Fatalf("ClearHistory failed: %v", err)
+	}
+	if got := len(NewQueryHistoryService(tempDir).ListHistory("", "", "", 0)); got != 0 {
+		t.Errorf("Expected empty history after clearing all, got %d", got)
+	}
+}
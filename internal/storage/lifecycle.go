@@ -8,6 +8,9 @@ type ConnectionLifecycle struct {
 	favoriteSvc *FavoriteService
 	dbMetaSvc   *DatabaseMetadataService
 	querySvc    *QueryService
+	historySvc  *QueryHistoryService
+	trashSvc    *TrashService
+	alertSvc    *AlertRuleService
 }
 
 // NewConnectionLifecycle creates a new lifecycle manager.
@@ -16,17 +19,23 @@ func NewConnectionLifecycle(
 	favoriteSvc *FavoriteService,
 	dbMetaSvc *DatabaseMetadataService,
 	querySvc *QueryService,
+	historySvc *QueryHistoryService,
+	trashSvc *TrashService,
+	alertSvc *AlertRuleService,
 ) *ConnectionLifecycle {
 	return &ConnectionLifecycle{
 		connStore:   connStore,
 		favoriteSvc: favoriteSvc,
 		dbMetaSvc:   dbMetaSvc,
 		querySvc:    querySvc,
+		historySvc:  historySvc,
+		trashSvc:    trashSvc,
+		alertSvc:    alertSvc,
 	}
 }
 
 // DeleteConnection deletes a saved connection and cleans up all associated data
-// (favorites, database metadata, saved queries). Cleanup errors are ignored
+// (favorites, database metadata, saved queries, pipelines and templates, query history, trash, alert rules). Cleanup errors are ignored
 // since they are secondary to the primary deletion.
 func (l *ConnectionLifecycle) DeleteConnection(connID string) error {
 	if err := l.connStore.DeleteSavedConnection(connID); err != nil {
@@ -36,5 +45,25 @@ func (l *ConnectionLifecycle) DeleteConnection(connID string) error {
 	_ = l.favoriteSvc.RemoveFavoritesForConnection(connID)
 	_ = l.dbMetaSvc.RemoveMetadataForConnection(connID)
 	_ = l.querySvc.DeleteQueriesForConnection(connID)
+	_ = l.querySvc.DeletePipelinesForConnection(connID)
+	_ = l.querySvc.DeleteTemplatesForConnection(connID)
+	_ = l.historySvc.ClearHistory(connID)
+	_ = l.trashSvc.EmptyTrash(connID)
+	_ = l.alertSvc.DeleteRulesForConnection(connID)
 	return nil
 }
+
+// CleanupOrphanedCredentials removes keyring entries left behind for connections that no
+// longer exist. Connections still referenced by favorites, metadata, queries, history, trash
+// or alert rules are probed too, which finds keys created before the keyring index existed.
+// Returns the number removed.
+func (l *ConnectionLifecycle) CleanupOrphanedCredentials() (int, error) {
+	var referenced []string
+	referenced = append(referenced, l.favoriteSvc.ConnectionIDs()...)
+	referenced = append(referenced, l.dbMetaSvc.ConnectionIDs()...)
+	referenced = append(referenced, l.querySvc.ConnectionIDs()...)
+	referenced = append(referenced, l.historySvc.ConnectionIDs()...)
+	referenced = append(referenced, l.trashSvc.ConnectionIDs()...)
+	referenced = append(referenced, l.alertSvc.ConnectionIDs()...)
+	return l.connStore.CleanupOrphanedCredentials(referenced)
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peternagy/mongopal/internal/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// PipelineNotFoundError is returned when a saved pipeline is not found.
+type PipelineNotFoundError struct {
+	PipelineID string
+}
+
+func (e *PipelineNotFoundError) Error() string {
+	return fmt.Sprintf("saved pipeline not found: %s", e.PipelineID)
+}
+
+// pipelinesFile returns the path to the saved pipelines file.
+func (s *QueryService) pipelinesFile() string {
+	return filepath.Join(s.configDir, "saved_pipelines.json")
+}
+
+// loadPipelines loads saved pipelines from disk.
+func (s *QueryService) loadPipelines() {
+	data, err := os.ReadFile(s.pipelinesFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: failed to load saved pipelines: %v\n", err)
+		}
+		s.pipelines = []types.SavedPipeline{}
+		return
+	}
+	var pipelines []types.SavedPipeline
+	if err := json.Unmarshal(data, &pipelines); err != nil {
+		fmt.Printf("Warning: failed to parse saved pipelines: %v\n", err)
+		s.pipelines = []types.SavedPipeline{}
+		return
+	}
+	s.pipelines = pipelines
+}
+
+// persistPipelines saves pipelines to disk.
+func (s *QueryService) persistPipelines() error {
+	data, err := json.MarshalIndent(s.pipelines, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.pipelinesFile(), data, 0600)
+}
+
+// validatePipeline checks that a pipeline has a name and that every stage is an Extended JSON document.
+func validatePipeline(pipeline types.SavedPipeline) error {
+	if strings.TrimSpace(pipeline.Name) == "" {
+		return fmt.Errorf("pipeline name cannot be empty")
+	}
+	if len(pipeline.Stages) == 0 {
+		return fmt.Errorf("pipeline must have at least one stage")
+	}
+	for i, stage := range pipeline.Stages {
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON([]byte(stage), false, &doc); err != nil {
+			return fmt.Errorf("invalid stage %d: %w", i+1, err)
+		}
+		if len(doc) != 1 || !strings.HasPrefix(doc[0].Key, "$") {
+			return fmt.Errorf("invalid stage %d: a stage must have exactly one $-prefixed key", i+1)
+		}
+	}
+	return nil
+}
+
+// SavePipeline creates or updates a saved pipeline.
+func (s *QueryService) SavePipeline(pipeline types.SavedPipeline) (types.SavedPipeline, error) {
+	if err := validatePipeline(pipeline); err != nil {
+		return types.SavedPipeline{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if pipeline.ID == "" {
+		pipeline.ID = uuid.New().String()
+		pipeline.CreatedAt = now
+		pipeline.UpdatedAt = now
+		s.pipelines = append(s.pipelines, pipeline)
+	} else {
+		found := false
+		for i := range s.pipelines {
+			if s.pipelines[i].ID == pipeline.ID {
+				pipeline.CreatedAt = s.pipelines[i].CreatedAt
+				pipeline.UpdatedAt = now
+				s.pipelines[i] = pipeline
+				found = true
+				break
+			}
+		}
+		if !found {
+			return types.SavedPipeline{}, &PipelineNotFoundError{PipelineID: pipeline.ID}
+		}
+	}
+
+	if err := s.persistPipelines(); err != nil {
+		return types.SavedPipeline{}, fmt.Errorf("failed to save pipeline: %w", err)
+	}
+
+	return pipeline, nil
+}
+
+// GetPipeline returns a saved pipeline by ID.
+func (s *QueryService) GetPipeline(pipelineID string) (types.SavedPipeline, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.pipelines {
+		if p.ID == pipelineID {
+			return p, nil
+		}
+	}
+	return types.SavedPipeline{}, &PipelineNotFoundError{PipelineID: pipelineID}
+}
+
+// ListPipelines returns all saved pipelines, optionally filtered by connection, database, and collection.
+func (s *QueryService) ListPipelines(connectionID, database, collection string) ([]types.SavedPipeline, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]types.SavedPipeline, 0)
+	for _, p := range s.pipelines {
+		if connectionID != "" && p.ConnectionID != connectionID {
+			continue
+		}
+		if database != "" && p.Database != database {
+			continue
+		}
+		if collection != "" && p.Collection != collection {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// DeletePipeline removes a saved pipeline.
+func (s *QueryService) DeletePipeline(pipelineID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, p := range s.pipelines {
+		if p.ID == pipelineID {
+			s.pipelines = append(s.pipelines[:i], s.pipelines[i+1:]...)
+			return s.persistPipelines()
+		}
+	}
+	return &PipelineNotFoundError{PipelineID: pipelineID}
+}
+
+// DeletePipelinesForConnection removes all saved pipelines for a connection.
+func (s *QueryService) DeletePipelinesForConnection(connectionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filtered := make([]types.SavedPipeline, 0)
+	for _, p := range s.pipelines {
+		if p.ConnectionID != connectionID {
+			filtered = append(filtered, p)
+		}
+	}
+	s.pipelines = filtered
+	return s.persistPipelines()
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestQueryService_SavePipeline(t *testing.T) {
+	tempDir := t.TempDir()
+	svc := NewQueryService(tempDir)
+
+	pipeline := types.SavedPipeline{
+		Name:         "Orders by status",
+		Description:  "Counts orders per status",
+		ConnectionID: "conn-1",
+		Database:     "shop",
+		Collection:   "orders",
+		Stages: []string{
+			`{"$match": {"createdAt": {"$gte": {"$date": "2024-01-01T00:00:00Z"}}}}`,
+			`{"$group": {"_id": "$status", "count": {"$sum": 1}}}`,
+		},
+	}
+
+	saved, err := svc.SavePipeline(pipeline)
+	if err != nil {
+		t.Fatalf("SavePipeline failed: %v", err)
+	}
+	if saved.ID == "" || saved.CreatedAt.IsZero() {
+		t.Fatalf("Expected ID and CreatedAt to be set, got %+v", saved)
+	}
+
+	saved.Stages = append(saved.Stages, `{"$sort": {"count": -1}}`)
+	updated, err := svc.SavePipeline(saved)
+	if err != nil {
+		t.Fatalf("SavePipeline update failed: %v", err)
+	}
+	if !updated.CreatedAt.Equal(saved.CreatedAt) {
+		t.Error("Expected CreatedAt to be preserved on update")
+	}
+
+	// Pipelines survive a restart, independently of saved queries
+	reloaded := NewQueryService(tempDir)
+	got, err := reloaded.GetPipeline(saved.ID)
+	if err != nil {
+		t.Fatalf("GetPipeline after reload failed: %v", err)
+	}
+	if len(got.Stages) != 3 {
+		t.Errorf("Expected 3 stages, got %d", len(got.Stages))
+	}
+	queries, _ := reloaded.ListQueries("", "", "")
+	if len(queries) != 0 {
+		t.Errorf("Expected no saved queries, got %d", len(queries))
+	}
+}
+
+func TestQueryService_SavePipelineValidation(t *testing.T) {
+	svc := NewQueryService(t.TempDir())
+
+	tests := map[string]types.SavedPipeline{
+		"empty name":    {Stages: []string{`{"$match": {}}`}},
+		"no stages":     {Name: "p"},
+		"invalid json":  {Name: "p", Stages: []string{`{"$match": `}},
+		"not a stage":   {Name: "p", Stages: []string{`{"match": {}}`}},
+		"two operators": {Name: "p", Stages: []string{`{"$match": {}, "$limit": 1}`}},
+	}
+	for name, pipeline := range tests {
+		if _, err := svc.SavePipeline(pipeline); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	_, err := svc.SavePipeline(types.SavedPipeline{ID: "missing", Name: "p", Stages: []string{`{"$limit": 1}`}})
+	if _, ok := err.(*PipelineNotFoundError); !ok {
+		t.Errorf("Expected PipelineNotFoundError, got %T", err)
+	}
+}
+
+func TestQueryService_ListAndDeletePipelines(t *testing.T) {
+	svc := NewQueryService(t.TempDir())
+
+	stages := []string{`{"$limit": 10}`}
+	p1, _ := svc.SavePipeline(types.SavedPipeline{Name: "a", ConnectionID: "conn-1", Database: "db", Collection: "c1", Stages: stages})
+	_, _ = svc.SavePipeline(types.SavedPipeline{Name: "b", ConnectionID: "conn-1", Database: "db", Collection: "c2", Stages: stages})
+	_, _ = svc.SavePipeline(types.SavedPipeline{Name: "c", ConnectionID: "conn-2", Database: "db", Collection: "c1", Stages: stages})
+
+	if list, _ := svc.ListPipelines("conn-1", "", ""); len(list) != 2 {
+		t.Errorf("Expected 2 pipelines for conn-1, got %d", len(list))
+	}
+	if list, _ := svc.ListPipelines("", "db", "c1"); len(list) != 2 {
+		t.Errorf("Expected 2 pipelines for db.c1, got %d", len(list))
+	}
+
+	if err := svc.DeletePipeline(p1.ID); err != nil {
+		t.Fatalf("DeletePipeline failed: %v", err)
+	}
+	if err := svc.DeletePipeline(p1.ID); err == nil {
+		t.Error("Expected error deleting a missing pipeline")
+	}
+
+	if err := svc.DeletePipelinesForConnection("conn-1"); err != nil {
+		t.Fatalf("DeletePipelinesForConnection failed: %v", err)
+	}
+	list, _ := svc.ListPipelines("", "", "")
+	if len(list) != 1 || list[0].ConnectionID != "conn-2" {
+		t.Errorf("Expected only conn-2 pipeline to remain, got %+v", list)
+	}
+}
@@ -21,10 +21,12 @@ func (e *QueryNotFoundError) Error() string {
 	return fmt.Sprintf("saved query not found: %s", e.QueryID)
 }
 
-// QueryService handles saved query storage operations.
+// QueryService handles saved query, saved pipeline, and document template storage operations.
 type QueryService struct {
 	configDir string
 	queries   []types.SavedQuery
+	pipelines []types.SavedPipeline
+	templates []types.DocumentTemplate
 	mu        sync.RWMutex
 }
 
@@ -33,9 +35,13 @@ func NewQueryService(configDir string) *QueryService {
 	svc := &QueryService{
 		configDir: configDir,
 		queries:   []types.SavedQuery{},
+		pipelines: []types.SavedPipeline{},
+		templates: []types.DocumentTemplate{},
 	}
-	// Load queries on startup
+	// Load queries, pipelines, and templates on startup
 	svc.loadQueries()
+	svc.loadPipelines()
+	svc.loadTemplates()
 	return svc
 }
 
@@ -176,3 +182,21 @@ func (s *QueryService) DeleteQueriesForConnection(connectionID string) error {
 	s.queries = filtered
 	return s.persistQueries()
 }
+
+// ConnectionIDs returns the IDs of the connections that have saved queries, pipelines or
+// templates.
+func (s *QueryService) ConnectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.queries)+len(s.pipelines)+len(s.templates))
+	for _, q := range s.queries {
+		ids = append(ids, q.ConnectionID)
+	}
+	for _, p := range s.pipelines {
+		ids = append(ids, p.ConnectionID)
+	}
+	for _, t := range s.templates {
+		ids = append(ids, t.ConnectionID)
+	}
+	return ids
+}
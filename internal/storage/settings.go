@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// SettingsService handles persistence of global application settings.
+// The active settings live on AppState so every service can read them.
+type SettingsService struct {
+	state     *core.AppState
+	configDir string
+	mu        sync.Mutex
+}
+
+// NewSettingsService creates a new settings service and loads persisted settings into state.
+func NewSettingsService(state *core.AppState, configDir string) *SettingsService {
+	svc := &SettingsService{
+		state:     state,
+		configDir: configDir,
+	}
+	svc.loadSettings()
+	return svc
+}
+
+// settingsFile returns the path to the settings file.
+func (s *SettingsService) settingsFile() string {
+	return filepath.Join(s.configDir, "settings.json")
+}
+
+// loadSettings loads settings from disk, falling back to defaults for missing values.
+func (s *SettingsService) loadSettings() {
+	settings := core.DefaultSettings()
+
+	data, err := os.ReadFile(s.settingsFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: failed to load settings: %v\n", err)
+		}
+		s.state.SetSettings(settings)
+		return
+	}
+
+	// Unmarshal over the defaults so fields absent from older files keep their default
+	if err := json.Unmarshal(data, &settings); err != nil {
+		fmt.Printf("Warning: failed to parse settings: %v\n", err)
+		settings = core.DefaultSettings()
+	}
+	s.state.SetSettings(settings)
+}
+
+// GetSettings returns the current application settings.
+func (s *SettingsService) GetSettings() types.AppSettings {
+	return s.state.GetSettings()
+}
+
+// UpdateSettings validates, persists, and applies new application settings.
+func (s *SettingsService) UpdateSettings(settings types.AppSettings) error {
+	if settings.EstimatedCountThreshold < 0 {
+		return fmt.Errorf("estimated count threshold cannot be negative")
+	}
+	if settings.QueryTimeoutSeconds < 0 {
+		return fmt.Errorf("query timeout cannot be negative")
+	}
+	if settings.ProdDestructiveDelaySeconds < 0 {
+		return fmt.Errorf("prod destructive delay cannot be negative")
+	}
+	switch settings.OutputMode {
+	case "":
+		settings.OutputMode = "canonical"
+	case "canonical", "relaxed":
+	default:
+		return fmt.Errorf("output mode must be \"canonical\" or \"relaxed\"")
+	}
+	switch settings.LegacyUUIDRepresentation {
+	case "", "pythonLegacy", "javaLegacy", "csharpLegacy":
+	default:
+		return fmt.Errorf("legacy UUID representation must be \"pythonLegacy\", \"javaLegacy\" or \"csharpLegacy\"")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := os.WriteFile(s.settingsFile(), data, 0600); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	s.state.SetSettings(settings)
+	return nil
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestSettingsService_Defaults(t *testing.T) {
+	tempDir := t.TempDir()
+	state := core.NewAppState()
+
+	svc := NewSettingsService(state, tempDir)
+
+	got := svc.GetSettings()
+	if got.EstimatedCountThreshold != core.DefaultEstimatedCountThreshold {
+		t.Errorf("Expected default threshold %d, got %d", core.DefaultEstimatedCountThreshold, got.EstimatedCountThreshold)
+	}
+}
+
+func TestSettingsService_UpdateAndReload(t *testing.T) {
+	tempDir := t.TempDir()
+	state := core.NewAppState()
+	svc := NewSettingsService(state, tempDir)
+
+	if err := svc.UpdateSettings(types.AppSettings{EstimatedCountThreshold: 5000}); err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if got := state.GetSettings().EstimatedCountThreshold; got != 5000 {
+		t.Errorf("Expected state threshold 5000, got %d", got)
+	}
+
+	// A fresh service must pick up the persisted value
+	state2 := core.NewAppState()
+	NewSettingsService(state2, tempDir)
+	if got := state2.GetSettings().EstimatedCountThreshold; got != 5000 {
+		t.Errorf("Expected reloaded threshold 5000, got %d", got)
+	}
+}
+
+func TestSettingsService_RejectsNegativeThreshold(t *testing.T) {
+	tempDir := t.TempDir()
+	svc := NewSettingsService(core.NewAppState(), tempDir)
+
+	if err := svc.UpdateSettings(types.AppSettings{EstimatedCountThreshold: -1}); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "settings.json")); !os.IsNotExist(err) {
+		t.Error("Expected settings file not to be written for invalid settings")
+	}
+}
+
+func TestSettingsService_MissingFieldsKeepDefaults(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "settings.json"), []byte(`{}`), 0600); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	state := core.NewAppState()
+	NewSettingsService(state, tempDir)
+	if got := state.GetSettings().EstimatedCountThreshold; got != core.DefaultEstimatedCountThreshold {
+		t.Errorf("Expected default threshold for missing field, got %d", got)
+	}
+}
+
+func TestSettingsService_QueryTimeout(t *testing.T) {
+	tempDir := t.TempDir()
+	state := core.NewAppState()
+	svc := NewSettingsService(state, tempDir)
+
+	if err := svc.UpdateSettings(types.AppSettings{QueryTimeoutSeconds: -5}); err == nil {
+		t.Error("Expected error for negative query timeout")
+	}
+
+	if err := svc.UpdateSettings(types.AppSettings{QueryTimeoutSeconds: 120}); err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if got := state.QueryTimeout(); got != 120*time.Second {
+		t.Errorf("Expected query timeout 120s, got %v", got)
+	}
+}
+
+func TestSettingsService_OutputMode(t *testing.T) {
+	tempDir := t.TempDir()
+	state := core.NewAppState()
+	svc := NewSettingsService(state, tempDir)
+
+	if got := svc.GetSettings().OutputMode; got != "canonical" {
+		t.Errorf("Expected default output mode canonical, got %q", got)
+	}
+	if err := svc.UpdateSettings(types.AppSettings{OutputMode: "pretty"}); err == nil {
+		t.Error("Expected error for unknown output mode")
+	}
+	if err := svc.UpdateSettings(types.AppSettings{}); err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if got := state.GetSettings().OutputMode; got != "canonical" {
+		t.Errorf("Expected empty output mode to default to canonical, got %q", got)
+	}
+	if err := svc.UpdateSettings(types.AppSettings{OutputMode: "relaxed", LocalDates: true}); err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if got := state.GetSettings(); got.OutputMode != "relaxed" || !got.LocalDates {
+		t.Errorf("Expected relaxed output with local dates, got %+v", got)
+	}
+}
+
+func TestSettingsService_LegacyUUIDRepresentation(t *testing.T) {
+	state := core.NewAppState()
+	svc := NewSettingsService(state, t.TempDir())
+
+	if err := svc.UpdateSettings(types.AppSettings{LegacyUUIDRepresentation: "standard"}); err == nil {
+		t.Error("Expected error for a non-legacy UUID representation")
+	}
+	if err := svc.UpdateSettings(types.AppSettings{LegacyUUIDRepresentation: "csharpLegacy"}); err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if got := state.GetSettings().LegacyUUIDRepresentation; got != "csharpLegacy" {
+		t.Errorf("Expected csharpLegacy, got %q", got)
+	}
+}
@@ -0,0 +1,458 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peternagy/mongopal/internal/credential"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// studio3TFolder and robo3TFolder are the folders connections imported from 3T tools are
+// placed in.
+const (
+	studio3TFolder = "Studio 3T"
+	robo3TFolder   = "Robo 3T"
+)
+
+// importAuthMechanisms maps the auth mechanism names used by other tools to the connection
+// form values. An empty value leaves the mechanism to the server's default negotiation.
+var importAuthMechanisms = map[string]string{
+	"":              "",
+	"BASIC":         "",
+	"DEFAULT":       "",
+	"NONE":          "none",
+	"SCRAM-SHA-1":   "scram-sha-1",
+	"SCRAM-SHA-256": "scram-sha-256",
+	"MONGODB-X509":  "x509",
+	"X509":          "x509",
+	"GSSAPI":        "kerberos",
+	"KERBEROS":      "kerberos",
+	"PLAIN":         "plain",
+	"LDAP":          "plain",
+	"MONGODB-AWS":   "mongodb-aws",
+	"AWS":           "mongodb-aws",
+}
+
+// robo3TReadPreferences are Robo 3T's read preferences, which it stores by index.
+var robo3TReadPreferences = []string{"primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest"}
+
+// studio3TConnection is a connection in a Studio 3T connection export.
+type studio3TConnection struct {
+	Name           string `json:"name"`
+	URI            string `json:"uri"`            // Set for connections created from a URI
+	ConnectionType string `json:"connectionType"` // "DIRECT", "REPLICA_SET", "SHARDED", or "SRV"
+	Hosts          []struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	} `json:"hosts"`
+	SRVHost         string `json:"srvHost"`
+	ReplicaSetName  string `json:"replicaSetName"`
+	DefaultDatabase string `json:"defaultDatabase"`
+	ReadPreference  string `json:"readPreference"`
+	Authentication  struct {
+		Mode         string `json:"mode"`
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+		AuthDatabase string `json:"authDatabase"`
+		ServiceName  string `json:"serviceName"` // Kerberos
+	} `json:"authentication"`
+	SSL struct {
+		Enabled                  bool   `json:"enabled"`
+		RootCAFile               string `json:"rootCaFile"`
+		ClientCertFile           string `json:"clientCertFile"`
+		ClientCertPassphrase     string `json:"clientCertPassphrase"`
+		AllowInvalidHostnames    bool   `json:"allowInvalidHostnames"`
+		AllowInvalidCertificates bool   `json:"allowInvalidCertificates"`
+	} `json:"ssl"`
+	SSH struct {
+		Enabled        bool   `json:"enabled"`
+		Host           string `json:"host"`
+		Port           int    `json:"port"`
+		Username       string `json:"username"`
+		Password       string `json:"password"`
+		PrivateKeyFile string `json:"privateKeyFile"`
+		Passphrase     string `json:"passphrase"`
+	} `json:"ssh"`
+	Proxy struct {
+		Type     string `json:"type"` // "NONE", "SOCKS5", or "HTTP"
+		Host     string `json:"host"`
+		Port     int    `json:"port"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"proxy"`
+}
+
+// robo3TConnection is a connection in Robo 3T's robo3t.json.
+type robo3TConnection struct {
+	ConnectionName  string `json:"connectionName"`
+	ServerHost      string `json:"serverHost"`
+	ServerPort      int    `json:"serverPort"`
+	DefaultDatabase string `json:"defaultDatabase"`
+	IsReplicaSet    bool   `json:"isReplicaSet"`
+	ReplicaSet      struct {
+		Members        []string `json:"members"`
+		SetName        string   `json:"setName"`
+		ReadPreference int      `json:"readPreference"`
+	} `json:"replicaSet"`
+	Credentials []struct {
+		DatabaseName string `json:"databaseName"`
+		Enabled      bool   `json:"enabled"`
+		Mechanism    string `json:"mechanism"`
+		UserName     string `json:"userName"`
+		UserPassword string `json:"userPassword"`
+	} `json:"credentials"`
+	SSH struct {
+		Enabled        bool   `json:"enabled"`
+		Host           string `json:"host"`
+		Port           int    `json:"port"`
+		UserName       string `json:"userName"`
+		UserPassword   string `json:"userPassword"`
+		PrivateKeyFile string `json:"privateKeyFile"`
+		Passphrase     string `json:"passphrase"`
+		Method         string `json:"method"` // "password" or "publickey"
+		AskPassword    bool   `json:"askPassword"`
+	} `json:"ssh"`
+	SSL struct {
+		Enabled                  bool   `json:"enabled"`
+		CAFile                   string `json:"caFile"`
+		UsePemCert               bool   `json:"usePemCert"`
+		PemKeyFile               string `json:"pemKeyFile"`
+		PemPassPhrase            string `json:"pemPassPhrase"`
+		CRLFile                  string `json:"crlFile"`
+		AllowInvalidHostnames    bool   `json:"allowInvalidHostnames"`
+		AllowInvalidCertificates bool   `json:"allowInvalidCertificates"`
+	} `json:"ssl"`
+}
+
+// Robo3TConfigPath returns the robo3t.json of the newest Robo 3T version installed, or ""
+// if there is none.
+func Robo3TConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(home, ".3T", "robo-3t", "*", "robo3t.json"))
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1]
+}
+
+// ImportFromStudio3T imports the connections of a Studio 3T connection export file.
+// Connections already saved with the same name and URI are skipped.
+func (s *ConnectionService) ImportFromStudio3T(path string) (*types.ConnectionImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	conns, warnings, err := ParseStudio3TConnections(data)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ConnectionImportResult{Warnings: append([]string{}, warnings...)}
+	if err := s.saveImportedConnections(conns, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ImportFromRobo3T imports the connections of a Robo 3T configuration file, or with an empty
+// path, of the robo3t.json of the local Robo 3T installation. Connections already saved with
+// the same name and URI are skipped.
+func (s *ConnectionService) ImportFromRobo3T(path string) (*types.ConnectionImportResult, error) {
+	if path == "" {
+		path = Robo3TConfigPath()
+		if path == "" {
+			return nil, fmt.Errorf("no Robo 3T configuration found; select its robo3t.json file")
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	conns, warnings, err := ParseRobo3TConnections(data)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ConnectionImportResult{Warnings: append([]string{}, warnings...)}
+	if err := s.saveImportedConnections(conns, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ParseStudio3TConnections converts the connections of a Studio 3T connection export
+// ({"connections": [...]}), with a warning for each setting that could not be imported.
+func ParseStudio3TConnections(data []byte) ([]types.ExtendedConnection, []string, error) {
+	var file struct {
+		Connections []studio3TConnection `json:"connections"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("not a Studio 3T connections file: %w", err)
+	}
+
+	var conns []types.ExtendedConnection
+	var warnings []string
+	for i, entry := range file.Connections {
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("Studio 3T connection %d", i+1)
+		}
+		warn := func(format string, args ...interface{}) {
+			warnings = append(warnings, name+": "+fmt.Sprintf(format, args...))
+		}
+
+		conn := types.ExtendedConnection{
+			Name:       name,
+			Color:      "#4CC38A",
+			CreatedAt:  time.Now(),
+			FolderPath: []string{studio3TFolder},
+		}
+
+		if entry.URI != "" {
+			if !strings.HasPrefix(entry.URI, "mongodb://") && !strings.HasPrefix(entry.URI, "mongodb+srv://") {
+				warn("skipped, invalid URI")
+				continue
+			}
+			conn.MongoURI = entry.URI
+		} else {
+			fd := newImportFormData()
+			fd.DefaultDatabase = entry.DefaultDatabase
+			fd.ReadPreference = entry.ReadPreference
+			switch strings.ToUpper(entry.ConnectionType) {
+			case "SRV":
+				fd.ConnectionType = "srv"
+				fd.SRVHostname = entry.SRVHost
+			case "REPLICA_SET":
+				fd.ConnectionType = "replicaset"
+				fd.ReplicaSetName = entry.ReplicaSetName
+			case "SHARDED":
+				fd.ConnectionType = "sharded"
+			}
+			for _, h := range entry.Hosts {
+				fd.Hosts = append(fd.Hosts, types.HostPort{Host: h.Host, Port: defaultImportPort(h.Port)})
+			}
+			if fd.ConnectionType == "srv" && fd.SRVHostname == "" || fd.ConnectionType != "srv" && len(fd.Hosts) == 0 {
+				warn("skipped, no server address")
+				continue
+			}
+
+			auth := entry.Authentication
+			mechanism, ok := importAuthMechanisms[strings.ToUpper(auth.Mode)]
+			if !ok {
+				warn("authentication mode %q is not supported; the server default is used", auth.Mode)
+			}
+			fd.AuthMechanism = mechanism
+			if mechanism != "none" {
+				fd.Username = auth.Username
+				fd.AuthDatabase = auth.AuthDatabase
+				fd.GSSAPIServiceName = auth.ServiceName
+			}
+			fd.TLSEnabled = entry.SSL.Enabled
+			fd.TLSInsecure = entry.SSL.Enabled && entry.SSL.AllowInvalidCertificates
+			password := ""
+			if fd.Username != "" {
+				password = auth.Password
+			}
+			conn.MongoURI = credential.BuildURIFromFormData(&fd, password)
+		}
+
+		if ssl := entry.SSL; ssl.Enabled {
+			conn.TLSEnabled = true
+			conn.TLSInsecure = ssl.AllowInvalidCertificates
+			if ssl.AllowInvalidHostnames && !ssl.AllowInvalidCertificates {
+				warn("allowing invalid hostnames alone is not supported; the hostname is verified")
+			}
+			conn.TLSCAFile = readImportedFile(ssl.RootCAFile, "CA certificate", warn)
+			conn.TLSCertFile = readImportedFile(ssl.ClientCertFile, "client certificate", warn)
+			conn.TLSKeyPassword = ssl.ClientCertPassphrase
+		}
+
+		if ssh := entry.SSH; ssh.Enabled {
+			conn.SSHEnabled = true
+			conn.SSHHost = ssh.Host
+			conn.SSHPort = ssh.Port
+			conn.SSHUser = ssh.Username
+			conn.SSHPassword = ssh.Password
+			conn.SSHPrivateKey = readImportedFile(ssh.PrivateKeyFile, "SSH private key", warn)
+			conn.SSHPassphrase = ssh.Passphrase
+		}
+
+		switch proxy := entry.Proxy; strings.ToUpper(proxy.Type) {
+		case "", "NONE":
+		case "SOCKS5":
+			conn.SOCKS5Enabled = true
+			conn.SOCKS5Host = proxy.Host
+			conn.SOCKS5Port = proxy.Port
+			conn.SOCKS5User = proxy.Username
+			conn.SOCKS5Password = proxy.Password
+		default:
+			warn("%s proxies are not supported; the proxy was not imported", proxy.Type)
+		}
+
+		conns = append(conns, conn)
+	}
+	return conns, warnings, nil
+}
+
+// ParseRobo3TConnections converts the connections of Robo 3T's robo3t.json, with a warning
+// for each setting that could not be imported.
+func ParseRobo3TConnections(data []byte) ([]types.ExtendedConnection, []string, error) {
+	var file struct {
+		Connections []robo3TConnection `json:"connections"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("not a Robo 3T configuration file: %w", err)
+	}
+
+	var conns []types.ExtendedConnection
+	var warnings []string
+	for i, entry := range file.Connections {
+		name := entry.ConnectionName
+		if name == "" {
+			name = fmt.Sprintf("Robo 3T connection %d", i+1)
+		}
+		warn := func(format string, args ...interface{}) {
+			warnings = append(warnings, name+": "+fmt.Sprintf(format, args...))
+		}
+
+		fd := newImportFormData()
+		fd.DefaultDatabase = entry.DefaultDatabase
+		if entry.IsReplicaSet {
+			fd.ConnectionType = "replicaset"
+			fd.ReplicaSetName = entry.ReplicaSet.SetName
+			for _, member := range entry.ReplicaSet.Members {
+				fd.Hosts = append(fd.Hosts, parseImportedHost(member))
+			}
+			if p := entry.ReplicaSet.ReadPreference; p > 0 && p < len(robo3TReadPreferences) {
+				fd.ReadPreference = robo3TReadPreferences[p]
+			}
+		} else if entry.ServerHost != "" {
+			fd.Hosts = []types.HostPort{{Host: entry.ServerHost, Port: defaultImportPort(entry.ServerPort)}}
+		}
+		if len(fd.Hosts) == 0 {
+			warn("skipped, no server address")
+			continue
+		}
+
+		// Robo 3T keeps a list of credentials but only authenticates with the first enabled one
+		password := ""
+		enabled := 0
+		for _, cred := range entry.Credentials {
+			if !cred.Enabled || cred.UserName == "" {
+				continue
+			}
+			enabled++
+			if enabled > 1 {
+				continue
+			}
+			mechanism, ok := importAuthMechanisms[strings.ToUpper(cred.Mechanism)]
+			if !ok {
+				warn("authentication mechanism %q is not supported; the server default is used", cred.Mechanism)
+			}
+			fd.AuthMechanism = mechanism
+			fd.Username = cred.UserName
+			fd.AuthDatabase = cred.DatabaseName
+			password = cred.UserPassword
+		}
+		if enabled > 1 {
+			warn("only the first of %d credentials was imported", enabled)
+		}
+
+		conn := types.ExtendedConnection{
+			Name:       name,
+			Color:      "#4CC38A",
+			CreatedAt:  time.Now(),
+			FolderPath: []string{robo3TFolder},
+		}
+
+		if ssl := entry.SSL; ssl.Enabled {
+			fd.TLSEnabled = true
+			fd.TLSInsecure = ssl.AllowInvalidCertificates
+			conn.TLSEnabled = true
+			conn.TLSInsecure = ssl.AllowInvalidCertificates
+			if ssl.AllowInvalidHostnames && !ssl.AllowInvalidCertificates {
+				warn("allowing invalid hostnames alone is not supported; the hostname is verified")
+			}
+			if ssl.CRLFile != "" {
+				warn("certificate revocation lists are not supported; %s was not imported", ssl.CRLFile)
+			}
+			conn.TLSCAFile = readImportedFile(ssl.CAFile, "CA certificate", warn)
+			if ssl.UsePemCert {
+				conn.TLSCertFile = readImportedFile(ssl.PemKeyFile, "client certificate", warn)
+				conn.TLSKeyPassword = ssl.PemPassPhrase
+			}
+		}
+
+		if ssh := entry.SSH; ssh.Enabled {
+			conn.SSHEnabled = true
+			conn.SSHHost = ssh.Host
+			conn.SSHPort = ssh.Port
+			conn.SSHUser = ssh.UserName
+			if ssh.Method == "publickey" {
+				conn.SSHPrivateKey = readImportedFile(ssh.PrivateKeyFile, "SSH private key", warn)
+				conn.SSHPassphrase = ssh.Passphrase
+			} else {
+				conn.SSHPassword = ssh.UserPassword
+			}
+			if ssh.AskPassword {
+				warn("Robo 3T asks for the SSH password on connect; enter it in the connection settings")
+			}
+		}
+
+		conn.MongoURI = credential.BuildURIFromFormData(&fd, password)
+		conns = append(conns, conn)
+	}
+	return conns, warnings, nil
+}
+
+// newImportFormData returns the connection form defaults imported connections start from,
+// so that only the imported settings end up in the built URI.
+func newImportFormData() types.ConnectionFormData {
+	return types.ConnectionFormData{
+		ConnectionType: "standalone",
+		AuthMechanism:  "none",
+		AuthDatabase:   "admin",
+		RetryWrites:    true,
+	}
+}
+
+// parseImportedHost parses a "host[:port]" address.
+func parseImportedHost(addr string) types.HostPort {
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		p, _ := strconv.Atoi(port)
+		return types.HostPort{Host: host, Port: defaultImportPort(p)}
+	}
+	return types.HostPort{Host: strings.Trim(addr, "[]"), Port: 27017}
+}
+
+// defaultImportPort returns port, or the MongoDB default port if it is not set.
+func defaultImportPort(port int) int {
+	if port == 0 {
+		return 27017
+	}
+	return port
+}
+
+// readImportedFile returns the content of a certificate or key file another tool references,
+// since MongoPal stores the contents. Unreadable files are reported through warn.
+func readImportedFile(path, what string, warn func(format string, args ...interface{})) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		warn("could not read the %s file %s; add it in the connection settings", what, path)
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peternagy/mongopal/internal/generator"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// TemplateNotFoundError is returned when a document template is not found.
+type TemplateNotFoundError struct {
+	TemplateID string
+}
+
+func (e *TemplateNotFoundError) Error() string {
+	return fmt.Sprintf("document template not found: %s", e.TemplateID)
+}
+
+// templatesFile returns the path to the document templates file.
+func (s *QueryService) templatesFile() string {
+	return filepath.Join(s.configDir, "document_templates.json")
+}
+
+// loadTemplates loads document templates from disk.
+func (s *QueryService) loadTemplates() {
+	data, err := os.ReadFile(s.templatesFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: failed to load document templates: %v\n", err)
+		}
+		s.templates = []types.DocumentTemplate{}
+		return
+	}
+	var templates []types.DocumentTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		fmt.Printf("Warning: failed to parse document templates: %v\n", err)
+		s.templates = []types.DocumentTemplate{}
+		return
+	}
+	s.templates = templates
+}
+
+// persistTemplates saves document templates to disk.
+func (s *QueryService) persistTemplates() error {
+	data, err := json.MarshalIndent(s.templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.templatesFile(), data, 0600)
+}
+
+// validateTemplate checks that a template has a name and collection and that its document
+// parses with every placeholder known.
+func validateTemplate(tmpl types.DocumentTemplate) error {
+	if strings.TrimSpace(tmpl.Name) == "" {
+		return fmt.Errorf("template name cannot be empty")
+	}
+	if tmpl.Database == "" || tmpl.Collection == "" {
+		return fmt.Errorf("template must belong to a collection")
+	}
+	if _, err := generator.ParseTemplate(tmpl.Template); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveTemplate creates or updates a document template.
+func (s *QueryService) SaveTemplate(tmpl types.DocumentTemplate) (types.DocumentTemplate, error) {
+	if err := validateTemplate(tmpl); err != nil {
+		return types.DocumentTemplate{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if tmpl.ID == "" {
+		tmpl.ID = uuid.New().String()
+		tmpl.CreatedAt = now
+		tmpl.UpdatedAt = now
+		s.templates = append(s.templates, tmpl)
+	} else {
+		found := false
+		for i := range s.templates {
+			if s.templates[i].ID == tmpl.ID {
+				tmpl.CreatedAt = s.templates[i].CreatedAt
+				tmpl.UpdatedAt = now
+				s.templates[i] = tmpl
+				found = true
+				break
+			}
+		}
+		if !found {
+			return types.DocumentTemplate{}, &TemplateNotFoundError{TemplateID: tmpl.ID}
+		}
+	}
+
+	if err := s.persistTemplates(); err != nil {
+		return types.DocumentTemplate{}, fmt.Errorf("failed to save template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// GetTemplate returns a document template by ID.
+func (s *QueryService) GetTemplate(templateID string) (types.DocumentTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range s.templates {
+		if t.ID == templateID {
+			return t, nil
+		}
+	}
+	return types.DocumentTemplate{}, &TemplateNotFoundError{TemplateID: templateID}
+}
+
+// ListTemplates returns all document templates, optionally filtered by connection, database, and collection.
+func (s *QueryService) ListTemplates(connectionID, database, collection string) ([]types.DocumentTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]types.DocumentTemplate, 0)
+	for _, t := range s.templates {
+		if connectionID != "" && t.ConnectionID != connectionID {
+			continue
+		}
+		if database != "" && t.Database != database {
+			continue
+		}
+		if collection != "" && t.Collection != collection {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// DeleteTemplate removes a document template.
+func (s *QueryService) DeleteTemplate(templateID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, t := range s.templates {
+		if t.ID == templateID {
+			s.templates = append(s.templates[:i], s.templates[i+1:]...)
+			return s.persistTemplates()
+		}
+	}
+	return &TemplateNotFoundError{TemplateID: templateID}
+}
+
+// DeleteTemplatesForConnection removes all document templates for a connection.
+func (s *QueryService) DeleteTemplatesForConnection(connectionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filtered := make([]types.DocumentTemplate, 0)
+	for _, t := range s.templates {
+		if t.ConnectionID != connectionID {
+			filtered = append(filtered, t)
+		}
+	}
+	s.templates = filtered
+	return s.persistTemplates()
+}
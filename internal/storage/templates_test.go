@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestQueryService_SaveTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	svc := NewQueryService(tempDir)
+
+	tmpl := types.DocumentTemplate{
+		Name:         "New order",
+		ConnectionID: "conn-1",
+		Database:     "shop",
+		Collection:   "orders",
+		Template:     `{"_id": "{{objectId}}", "customer": "{{string}}", "items": "{{array}}", "createdAt": "{{now}}"}`,
+	}
+
+	saved, err := svc.SaveTemplate(tmpl)
+	if err != nil {
+		t.Fatalf("SaveTemplate failed: %v", err)
+	}
+	if saved.ID == "" || saved.CreatedAt.IsZero() {
+		t.Fatalf("Expected ID and CreatedAt to be set, got %+v", saved)
+	}
+
+	saved.Description = "Empty order"
+	updated, err := svc.SaveTemplate(saved)
+	if err != nil {
+		t.Fatalf("SaveTemplate update failed: %v", err)
+	}
+	if !updated.CreatedAt.Equal(saved.CreatedAt) {
+		t.Error("Expected CreatedAt to be preserved on update")
+	}
+
+	// Templates survive a restart
+	reloaded := NewQueryService(tempDir)
+	got, err := reloaded.GetTemplate(saved.ID)
+	if err != nil {
+		t.Fatalf("GetTemplate after reload failed: %v", err)
+	}
+	if got.Description != "Empty order" || got.Template != tmpl.Template {
+		t.Errorf("Unexpected template after reload: %+v", got)
+	}
+}
+
+func TestQueryService_SaveTemplateValidation(t *testing.T) {
+	svc := NewQueryService(t.TempDir())
+
+	tests := map[string]types.DocumentTemplate{
+		"empty name":    {Database: "db", Collection: "c", Template: `{}`},
+		"no collection": {Name: "t", Template: `{}`},
+		"invalid json":  {Name: "t", Database: "db", Collection: "c", Template: `{"a": `},
+		"unknown token": {Name: "t", Database: "db", Collection: "c", Template: `{"a": "{{nope}}"}`},
+	}
+	for name, tmpl := range tests {
+		if _, err := svc.SaveTemplate(tmpl); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	_, err := svc.SaveTemplate(types.DocumentTemplate{ID: "missing", Name: "t", Database: "db", Collection: "c", Template: `{}`})
+	if _, ok := err.(*TemplateNotFoundError); !ok {
+		t.Errorf("Expected TemplateNotFoundError, got %T", err)
+	}
+}
+
+func TestQueryService_ListAndDeleteTemplates(t *testing.T) {
+	svc := NewQueryService(t.TempDir())
+
+	doc := `{"name": "{{string}}"}`
+	t1, _ := svc.SaveTemplate(types.DocumentTemplate{Name: "a", ConnectionID: "conn-1", Database: "db", Collection: "c1", Template: doc})
+	_, _ = svc.SaveTemplate(types.DocumentTemplate{Name: "b", ConnectionID: "conn-1", Database: "db", Collection: "c2", Template: doc})
+	_, _ = svc.SaveTemplate(types.DocumentTemplate{Name: "c", ConnectionID: "conn-2", Database: "db", Collection: "c1", Template: doc})
+
+	if list, _ := svc.ListTemplates("conn-1", "", ""); len(list) != 2 {
+		t.Errorf("Expected 2 templates for conn-1, got %d", len(list))
+	}
+	if list, _ := svc.ListTemplates("", "db", "c1"); len(list) != 2 {
+		t.Errorf("Expected 2 templates for db.c1, got %d", len(list))
+	}
+
+	if err := svc.DeleteTemplate(t1.ID); err != nil {
+		t.Fatalf("DeleteTemplate failed: %v", err)
+	}
+	if err := svc.DeleteTemplate(t1.ID); err == nil {
+		t.Error("Expected error deleting a missing template")
+	}
+
+	if err := svc.DeleteTemplatesForConnection("conn-1"); err != nil {
+		t.Fatalf("DeleteTemplatesForConnection failed: %v", err)
+	}
+	list, _ := svc.ListTemplates("", "", "")
+	if len(list) != 1 || list[0].ConnectionID != "conn-2" {
+		t.Errorf("Expected only conn-2 template to remain, got %+v", list)
+	}
+}
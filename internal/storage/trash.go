@@ -0,0 +1,329 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peternagy/mongopal/internal/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MaxTrashEntriesPerConnection caps the number of deleted documents kept per connection.
+// The oldest entries are dropped once the cap is reached.
+const MaxTrashEntriesPerConnection = 1000
+
+// MaxTrashBytes caps the size of the trash file. Once an append takes the file over the cap,
+// it is rewritten with the newest entries that fit in three quarters of it.
+const MaxTrashBytes = 64 << 20
+
+// TrashEntryNotFoundError is returned when a trash entry is not found.
+type TrashEntryNotFoundError struct {
+	EntryID string
+}
+
+func (e *TrashEntryNotFoundError) Error() string {
+	return fmt.Sprintf("trash entry not found: %s", e.EntryID)
+}
+
+// TrashService keeps copies of deleted documents on disk so deletes can be undone.
+// Entries are appended to a JSON Lines file, oldest first, and kept in memory newest first.
+type TrashService struct {
+	configDir string
+	entries   []types.TrashEntry
+	fileSize  int64 // Includes entries dropped by the per-connection cap since the last rewrite
+	maxBytes  int64
+	mu        sync.RWMutex
+}
+
+// NewTrashService creates a new trash service.
+func NewTrashService(configDir string) *TrashService {
+	svc := &TrashService{
+		configDir: configDir,
+		entries:   []types.TrashEntry{},
+		maxBytes:  MaxTrashBytes,
+	}
+	svc.loadTrash()
+	return svc
+}
+
+// trashFile returns the path to the trash file.
+func (s *TrashService) trashFile() string {
+	return filepath.Join(s.configDir, "trash.jsonl")
+}
+
+// loadTrash loads trash entries from disk.
+// Unreadable lines, such as one cut short by a crash, are skipped.
+func (s *TrashService) loadTrash() {
+	s.entries = []types.TrashEntry{}
+	s.fileSize = 0
+
+	f, err := os.Open(s.trashFile())
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Printf("Warning: failed to load trash: %v\n", err)
+	}
+	if err == nil {
+		defer f.Close()
+		reader := bufio.NewReader(f)
+		for {
+			line, err := reader.ReadBytes('\n')
+			s.fileSize += int64(len(line))
+			if len(bytes.TrimSpace(line)) > 0 {
+				var e types.TrashEntry
+				if jsonErr := json.Unmarshal(line, &e); jsonErr != nil {
+					fmt.Printf("Warning: skipping unreadable trash entry: %v\n", jsonErr)
+				} else {
+					s.entries = append(s.entries, e)
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					fmt.Printf("Warning: failed to load trash: %v\n", err)
+				}
+				break
+			}
+		}
+		slices.Reverse(s.entries)
+	}
+
+	s.entries = capPerConnection(s.entries)
+}
+
+// encodeTrashEntries encodes entries as JSON Lines, in the order given.
+func encodeTrashEntries(entries []types.TrashEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// appendTrash appends entries, oldest first, to the trash file.
+func (s *TrashService) appendTrash(entries []types.TrashEntry) error {
+	data, err := encodeTrashEntries(entries)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.trashFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	n, err := f.Write(data)
+	s.fileSize += int64(n)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// rewriteTrash replaces the trash file with the entries in memory. The file is written
+// aside and renamed over the old one, so a failed write leaves the old file in place.
+func (s *TrashService) rewriteTrash() error {
+	oldestFirst := slices.Clone(s.entries)
+	slices.Reverse(oldestFirst)
+	data, err := encodeTrashEntries(oldestFirst)
+	if err != nil {
+		return err
+	}
+	tmp := s.trashFile() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.trashFile()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.fileSize = int64(len(data))
+	return nil
+}
+
+// compactTrash drops the oldest entries until the rest fit in three quarters of the byte
+// cap and rewrites the trash file with them.
+func (s *TrashService) compactTrash() error {
+	limit := s.maxBytes * 3 / 4
+	var size int64
+	keep := len(s.entries)
+	for i, e := range s.entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		size += int64(len(line)) + 1
+		if size > limit {
+			keep = i
+			break
+		}
+	}
+	s.entries = s.entries[:keep]
+	return s.rewriteTrash()
+}
+
+// capPerConnection keeps the newest MaxTrashEntriesPerConnection entries of each connection.
+func capPerConnection(entries []types.TrashEntry) []types.TrashEntry {
+	kept := make([]types.TrashEntry, 0, len(entries))
+	perConn := map[string]int{}
+	for _, e := range entries {
+		if perConn[e.ConnectionID] >= MaxTrashEntriesPerConnection {
+			continue
+		}
+		perConn[e.ConnectionID]++
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// ArchiveDocuments adds documents deleted from a collection to the trash. The entries are
+// appended to the trash file; it is only rewritten once it grows past the byte cap.
+func (s *TrashService) ArchiveDocuments(connID, dbName, collName string, docs []bson.Raw) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	added := make([]types.TrashEntry, 0, len(docs))
+	for _, doc := range docs {
+		docJSON, err := bson.MarshalExtJSON(doc, true, false)
+		if err != nil {
+			return fmt.Errorf("failed to serialize document: %w", err)
+		}
+		idJSON, err := bson.MarshalExtJSON(bson.D{{Key: "_id", Value: doc.Lookup("_id")}}, true, false)
+		if err != nil {
+			return fmt.Errorf("failed to serialize document id: %w", err)
+		}
+		var id struct {
+			ID json.RawMessage `json:"_id"`
+		}
+		if err := json.Unmarshal(idJSON, &id); err != nil {
+			return fmt.Errorf("failed to serialize document id: %w", err)
+		}
+		added = append(added, types.TrashEntry{
+			ID:           uuid.New().String(),
+			ConnectionID: connID,
+			Database:     dbName,
+			Collection:   collName,
+			DocumentID:   string(id.ID),
+			Document:     string(docJSON),
+			DeletedAt:    now,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.appendTrash(added); err != nil {
+		return fmt.Errorf("failed to save trash: %w", err)
+	}
+	// Newest first, keeping the order the documents were deleted in
+	slices.Reverse(added)
+	s.entries = capPerConnection(append(added, s.entries...))
+
+	if s.fileSize > s.maxBytes {
+		if err := s.compactTrash(); err != nil {
+			return fmt.Errorf("failed to compact trash: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListTrash returns trash entries for a connection, newest first, optionally filtered by
+// database and collection.
+func (s *TrashService) ListTrash(connectionID, database, collection string) []types.TrashEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]types.TrashEntry, 0)
+	for _, e := range s.entries {
+		if e.ConnectionID != connectionID {
+			continue
+		}
+		if database != "" && e.Database != database {
+			continue
+		}
+		if collection != "" && e.Collection != collection {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// GetTrashEntries returns the trash entries with the given IDs, in the order requested.
+func (s *TrashService) GetTrashEntries(ids []string) ([]types.TrashEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byID := make(map[string]types.TrashEntry, len(s.entries))
+	for _, e := range s.entries {
+		byID[e.ID] = e
+	}
+	result := make([]types.TrashEntry, 0, len(ids))
+	for _, id := range ids {
+		e, ok := byID[id]
+		if !ok {
+			return nil, &TrashEntryNotFoundError{EntryID: id}
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// RemoveTrashEntries deletes the given entries from the trash. Unknown IDs are ignored.
+func (s *TrashService) RemoveTrashEntries(ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+	filtered := make([]types.TrashEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if !remove[e.ID] {
+			filtered = append(filtered, e)
+		}
+	}
+	s.entries = filtered
+	return s.rewriteTrash()
+}
+
+// EmptyTrash removes trash entries for a connection, or all entries if connectionID is empty.
+func (s *TrashService) EmptyTrash(connectionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if connectionID == "" {
+		s.entries = []types.TrashEntry{}
+		return s.rewriteTrash()
+	}
+
+	filtered := make([]types.TrashEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if e.ConnectionID != connectionID {
+			filtered = append(filtered, e)
+		}
+	}
+	s.entries = filtered
+	return s.rewriteTrash()
+}
+
+// ConnectionIDs returns the IDs of the connections that have trash entries.
+func (s *TrashService) ConnectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.entries))
+	for _, e := range s.entries {
+		ids = append(ids, e.ConnectionID)
+	}
+	return ids
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func trashDoc(t *testing.T, id interface{}, name string) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(bson.D{{Key: "_id", Value: id}, {Key: "name", Value: name}})
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+	return raw
+}
+
+func TestTrashService_ArchiveAndList(t *testing.T) {
+	svc := NewTrashService(t.TempDir())
+
+	docs := []bson.Raw{trashDoc(t, int32(1), "a"), trashDoc(t, int32(2), "b")}
+	if err := svc.ArchiveDocuments("conn-1", "db", "users", docs); err != nil {
+		t.Fatalf("ArchiveDocuments failed: %v", err)
+	}
+	if err := svc.ArchiveDocuments("conn-2", "db", "users", []bson.Raw{trashDoc(t, "x", "c")}); err != nil {
+		t.Fatalf("ArchiveDocuments failed: %v", err)
+	}
+
+	entries := svc.ListTrash("conn-1", "", "")
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries for conn-1, got %d", len(entries))
+	}
+	if entries[0].DocumentID != `{"$numberInt":"2"}` {
+		t.Errorf("Expected last deleted document first, got %s", entries[0].DocumentID)
+	}
+	if entries[1].Document != `{"_id":{"$numberInt":"1"},"name":"a"}` {
+		t.Errorf("Unexpected archived document: %s", entries[1].Document)
+	}
+	if entries[0].ID == "" || entries[0].DeletedAt.IsZero() {
+		t.Error("Expected ID and DeletedAt to be set")
+	}
+	if got := svc.ListTrash("conn-1", "db", "orders"); len(got) != 0 {
+		t.Errorf("Expected no entries for db.orders, got %d", len(got))
+	}
+}
+
+func TestTrashService_CapsEntriesPerConnection(t *testing.T) {
+	svc := NewTrashService(t.TempDir())
+
+	_ = svc.ArchiveDocuments("conn-2", "db", "users", []bson.Raw{trashDoc(t, "keep", "x")})
+	docs := make([]bson.Raw, MaxTrashEntriesPerConnection+5)
+	for i := range docs {
+		docs[i] = trashDoc(t, int32(i), "n")
+	}
+	if err := svc.ArchiveDocuments("conn-1", "db", "users", docs); err != nil {
+		t.Fatalf("ArchiveDocuments failed: %v", err)
+	}
+
+	entries := svc.ListTrash("conn-1", "", "")
+	if len(entries) != MaxTrashEntriesPerConnection {
+		t.Fatalf("Expected %d entries, got %d", MaxTrashEntriesPerConnection, len(entries))
+	}
+	if entries[len(entries)-1].DocumentID != `{"$numberInt":"5"}` {
+		t.Errorf("Expected oldest documents to be dropped, oldest kept is %s", entries[len(entries)-1].DocumentID)
+	}
+	if got := svc.ListTrash("conn-2", "", ""); len(got) != 1 {
+		t.Errorf("Expected other connection's trash to be untouched, got %d entries", len(got))
+	}
+}
+
+func TestTrashService_GetRemoveAndEmpty(t *testing.T) {
+	tempDir := t.TempDir()
+	svc := NewTrashService(tempDir)
+
+	_ = svc.ArchiveDocuments("conn-1", "db", "users", []bson.Raw{trashDoc(t, int32(1), "a"), trashDoc(t, int32(2), "b")})
+	_ = svc.ArchiveDocuments("conn-2", "db", "users", []bson.Raw{trashDoc(t, int32(3), "c")})
+	entries := svc.ListTrash("conn-1", "", "")
+
+	got, err := svc.GetTrashEntries([]string{entries[1].ID})
+	if err != nil || len(got) != 1 || got[0].ID != entries[1].ID {
+		t.Fatalf("GetTrashEntries = %v, %v", got, err)
+	}
+	var notFound *TrashEntryNotFoundError
+	if _, err := svc.GetTrashEntries([]string{"missing"}); !errors.As(err, &notFound) {
+		t.Errorf("Expected TrashEntryNotFoundError, got %v", err)
+	}
+
+	if err := svc.RemoveTrashEntries([]string{entries[0].ID}); err != nil {
+		t.Fatalf("RemoveTrashEntries failed: %v", err)
+	}
+	if got := NewTrashService(tempDir).ListTrash("conn-1", "", ""); len(got) != 1 {
+		t.Errorf("Expected 1 persisted entry for conn-1, got %d", len(got))
+	}
+
+	if err := svc.EmptyTrash("conn-1"); err != nil {
+		t.Fatalf("EmptyTrash failed: %v", err)
+	}
+	if got := svc.ListTrash("conn-1", "", ""); len(got) != 0 {
+		t.Errorf("Expected conn-1 trash to be empty, got %d", len(got))
+	}
+	if got := svc.ListTrash("conn-2", "", ""); len(got) != 1 {
+		t.Errorf("Expected conn-2 trash to remain, got %d", len(got))
+	}
+	if err := svc.EmptyTrash(""); err != nil {
+		t.Fatalf("EmptyTrash failed: %v", err)
+	}
+	if got := NewTrashService(tempDir).ListTrash("conn-2", "", ""); len(got) != 0 {
+		t.Errorf("Expected all trash to be empty, got %d", len(got))
+	}
+}
+
+func TestTrashService_AppendsAndReloads(t *testing.T) {
+	tempDir := t.TempDir()
+	svc := NewTrashService(tempDir)
+
+	_ = svc.ArchiveDocuments("conn-1", "db", "users", []bson.Raw{trashDoc(t, int32(1), "a")})
+	_ = svc.ArchiveDocuments("conn-1", "db", "users", []bson.Raw{trashDoc(t, int32(2), "b")})
+
+	// A line cut short by a crash is skipped
+	f, err := os.OpenFile(filepath.Join(tempDir, "trash.jsonl"), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open trash file: %v", err)
+	}
+	_, _ = f.WriteString(`{"id":"torn","connectionId":"conn-1"`)
+	f.Close()
+
+	entries := NewTrashService(tempDir).ListTrash("conn-1", "", "")
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 reloaded entries, got %d", len(entries))
+	}
+	if entries[0].DocumentID != `{"$numberInt":"2"}` {
+		t.Errorf("Expected last deleted document first, got %s", entries[0].DocumentID)
+	}
+}
+
+func TestTrashService_CompactsPastByteCap(t *testing.T) {
+	tempDir := t.TempDir()
+	svc := NewTrashService(tempDir)
+	svc.maxBytes = 4096
+
+	for i := 0; i < 50; i++ {
+		if err := svc.ArchiveDocuments("conn-1", "db", "users", []bson.Raw{trashDoc(t, int32(i), "n")}); err != nil {
+			t.Fatalf("ArchiveDocuments failed: %v", err)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(tempDir, "trash.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to stat trash file: %v", err)
+	}
+	if info.Size() > svc.maxBytes {
+		t.Errorf("Expected trash file within %d bytes, got %d", svc.maxBytes, info.Size())
+	}
+	entries := svc.ListTrash("conn-1", "", "")
+	if len(entries) == 0 || len(entries) == 50 {
+		t.Fatalf("Expected the oldest entries to be dropped, got %d entries", len(entries))
+	}
+	if entries[0].DocumentID != `{"$numberInt":"49"}` {
+		t.Errorf("Expected newest entry to be kept, got %s", entries[0].DocumentID)
+	}
+	if got := NewTrashService(tempDir).ListTrash("conn-1", "", ""); len(got) != len(entries) {
+		t.Errorf("Expected %d persisted entries, got %d", len(entries), len(got))
+	}
+}
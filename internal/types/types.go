@@ -22,8 +22,18 @@ type SavedConnection struct {
 	URI            string    `json:"uri"`
 	Color          string    `json:"color"`
 	ReadOnly       bool      `json:"readOnly"`
+	Environment    string    `json:"environment,omitempty"` // "prod", "staging", "dev", or empty
+	AutoConnect    bool      `json:"autoConnect,omitempty"` // Connect when the app starts
 	CreatedAt      time.Time `json:"createdAt"`
 	LastAccessedAt time.Time `json:"lastAccessedAt,omitempty"`
+
+	// Safety settings copied from ExtendedConnection so services can enforce them
+	DestructiveDelay          int  `json:"destructiveDelay,omitempty"`
+	RequireDeleteConfirmation bool `json:"requireDeleteConfirmation,omitempty"`
+
+	// Database access lists copied from ExtendedConnection
+	AllowedDatabases []string `json:"allowedDatabases,omitempty"`
+	DeniedDatabases  []string `json:"deniedDatabases,omitempty"`
 }
 
 // ExtendedConnection contains all connection data including sensitive credentials.
@@ -35,6 +45,8 @@ type ExtendedConnection struct {
 	FolderID       string    `json:"folderId,omitempty"`
 	Color          string    `json:"color"`
 	ReadOnly       bool      `json:"readOnly"`
+	Environment    string    `json:"environment,omitempty"` // "prod", "staging", "dev", or empty; tightens safety policy
+	AutoConnect    bool      `json:"autoConnect,omitempty"` // Connect when the app starts
 	CreatedAt      time.Time `json:"createdAt"`
 	LastAccessedAt time.Time `json:"lastAccessedAt,omitempty"`
 
@@ -49,6 +61,8 @@ type ExtendedConnection struct {
 	SSHPassword   string `json:"sshPassword,omitempty"`   // Stored encrypted
 	SSHPrivateKey string `json:"sshPrivateKey,omitempty"` // Stored encrypted (~3KB)
 	SSHPassphrase string `json:"sshPassphrase,omitempty"` // Stored encrypted
+	// SHA256 fingerprint of the SSH server's host key, pinned on the first connect
+	SSHHostKeyFingerprint string `json:"sshHostKeyFingerprint,omitempty"`
 
 	// TLS/SSL configuration (F074)
 	TLSEnabled        bool   `json:"tlsEnabled"`
@@ -69,6 +83,11 @@ type ExtendedConnection struct {
 	DestructiveDelay          int  `json:"destructiveDelay"`          // Seconds to delay destructive operations
 	RequireDeleteConfirmation bool `json:"requireDeleteConfirmation"` // Require typing "DELETE"
 
+	// Database access lists; names may use glob patterns such as "team_*". When the allow list
+	// is set, only matching databases are listed and usable; denied databases never are.
+	AllowedDatabases []string `json:"allowedDatabases,omitempty"`
+	DeniedDatabases  []string `json:"deniedDatabases,omitempty"`
+
 	// Form state (F074) - stores raw form field values for editing
 	FormData string `json:"formData,omitempty"` // JSON blob of form fields
 
@@ -85,11 +104,39 @@ func (e *ExtendedConnection) ToSavedConnection() SavedConnection {
 		URI:            e.MongoURI,
 		Color:          e.Color,
 		ReadOnly:       e.ReadOnly,
+		Environment:    e.Environment,
+		AutoConnect:    e.AutoConnect,
 		CreatedAt:      e.CreatedAt,
 		LastAccessedAt: e.LastAccessedAt,
+
+		DestructiveDelay:          e.DestructiveDelay,
+		RequireDeleteConfirmation: e.RequireDeleteConfirmation,
+
+		AllowedDatabases: e.AllowedDatabases,
+		DeniedDatabases:  e.DeniedDatabases,
 	}
 }
 
+// DestructiveConfirmation is a backend-issued token that authorizes one destructive operation
+// on a connection with safety settings. It can be confirmed once ValidAfter has passed.
+type DestructiveConfirmation struct {
+	Required         bool      `json:"required"` // False when the connection has no safety settings; no token is issued
+	Token            string    `json:"token,omitempty"`
+	Operation        string    `json:"operation"`
+	Target           string    `json:"target"`
+	DelaySeconds     int       `json:"delaySeconds"`
+	ConfirmationText string    `json:"confirmationText,omitempty"` // Text the user must type, if required
+	ValidAfter       time.Time `json:"validAfter"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+// ConnectionImportResult summarizes an import of connections from another tool.
+type ConnectionImportResult struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`  // Already saved with the same name and URI
+	Warnings []string `json:"warnings"` // Connections or credentials that could not be imported
+}
+
 // ConnectionShareBundle is the encrypted bundle format for sharing connections.
 type ConnectionShareBundle struct {
 	Version int    `json:"v"`
@@ -127,6 +174,14 @@ type ConnectionInfo struct {
 	ServerVersion string `json:"serverVersion"`
 }
 
+// CredentialStoreStatus describes where the encryption keys of saved connections are kept.
+type CredentialStoreStatus struct {
+	Backend          string `json:"backend"` // "keyring" or "vault"
+	KeyringAvailable bool   `json:"keyringAvailable"`
+	VaultExists      bool   `json:"vaultExists"`
+	VaultLocked      bool   `json:"vaultLocked"` // The vault exists but is not unlocked yet
+}
+
 // ConnectionStatus represents the status of a connection.
 type ConnectionStatus struct {
 	Connected bool   `json:"connected"`
@@ -135,14 +190,276 @@ type ConnectionStatus struct {
 
 // TestConnectionResult is the enhanced result from a test connection attempt.
 type TestConnectionResult struct {
-	Success       bool   `json:"success"`
-	Error         string `json:"error,omitempty"`
-	Hint          string `json:"hint,omitempty"`
-	ServerVersion string `json:"serverVersion,omitempty"`
-	Topology      string `json:"topology,omitempty"` // "standalone", "replicaset", "sharded"
-	Latency       int64  `json:"latency"`            // milliseconds
-	TLSEnabled    bool   `json:"tlsEnabled"`
-	ReplicaSet    string `json:"replicaSet,omitempty"`
+	Success       bool     `json:"success"`
+	Error         string   `json:"error,omitempty"`
+	Hint          string   `json:"hint,omitempty"`
+	ServerVersion string   `json:"serverVersion,omitempty"`
+	Topology      string   `json:"topology,omitempty"` // "standalone", "replicaset", "sharded"
+	Latency       int64    `json:"latency"`            // milliseconds
+	TLSEnabled    bool     `json:"tlsEnabled"`
+	ReplicaSet    string   `json:"replicaSet,omitempty"`
+	Warnings      []string `json:"warnings,omitempty"` // Settings that work but are unsafe
+}
+
+// ConnectionHealth is the latest background health check of a connected client.
+type ConnectionHealth struct {
+	ConnectionID string    `json:"connectionId"`
+	Status       string    `json:"status"`          // "healthy", "degraded", or "down"
+	Event        string    `json:"event,omitempty"` // Set on transitions: "degraded", "down", or "recovered"
+	LatencyMS    float64   `json:"latencyMs"`       // Round trip of the last successful ping
+	AvgLatencyMS float64   `json:"avgLatencyMs"`    // Rolling average of recent pings
+	Failures     int       `json:"failures"`        // Consecutive failed pings
+	Error        string    `json:"error,omitempty"`
+	CheckedAt    time.Time `json:"checkedAt"`
+}
+
+// AutoConnectStatus is the progress of connecting a saved connection on startup.
+type AutoConnectStatus struct {
+	ConnectionID string `json:"connectionId"`
+	Status       string `json:"status"` // "connecting", "connected", or "failed"
+	Error        string `json:"error,omitempty"`
+	DurationMS   int64  `json:"durationMs,omitempty"`
+}
+
+// PoolStats summarizes the driver connection pools of a connected client, from pool monitor
+// events collected since it connected.
+type PoolStats struct {
+	ConnectionID string            `json:"connectionId"`
+	Since        time.Time         `json:"since"`
+	Total        ServerPoolStats   `json:"total"`   // Summed across servers; Address and MaxPoolSize are empty
+	Servers      []ServerPoolStats `json:"servers"` // One pool per server, sorted by address
+}
+
+// ServerPoolStats is the connection pool of one server.
+type ServerPoolStats struct {
+	Address          string  `json:"address,omitempty"`
+	MaxPoolSize      uint64  `json:"maxPoolSize,omitempty"`
+	CheckedOut       int64   `json:"checkedOut"`       // Connections in use
+	WaitQueue        int64   `json:"waitQueue"`        // Operations waiting for a connection
+	Open             int64   `json:"open"`             // Connections currently open
+	TotalCreated     int64   `json:"totalCreated"`     // Connections created since connecting
+	TotalClosed      int64   `json:"totalClosed"`      // Connections closed since connecting
+	Checkouts        int64   `json:"checkouts"`        // Successful checkouts
+	CheckoutFailures int64   `json:"checkoutFailures"` // Checkouts that timed out or failed
+	AvgCheckoutMS    float64 `json:"avgCheckoutMs"`    // Average time to check out a connection
+	MaxCheckoutMS    float64 `json:"maxCheckoutMs"`    // Slowest checkout
+	Cleared          int64   `json:"cleared"`          // Times the pool was cleared after errors
+}
+
+// MetricsSample is one serverStatus sample of a connected server. Rates are per second since
+// the previous sample, so the first sample of a poller has none.
+type MetricsSample struct {
+	ConnectionID string            `json:"connectionId"`
+	Time         time.Time         `json:"time"`
+	Opcounters   OpcounterRates    `json:"opcounters"`
+	Connections  ServerConnections `json:"connections"`
+	Memory       ServerMemory      `json:"memory"`
+	Network      NetworkRates      `json:"network"`
+}
+
+// OpcounterRates are the operations per second a server ran, by type.
+type OpcounterRates struct {
+	Insert  float64 `json:"insert"`
+	Query   float64 `json:"query"`
+	Update  float64 `json:"update"`
+	Delete  float64 `json:"delete"`
+	GetMore float64 `json:"getMore"`
+	Command float64 `json:"command"`
+}
+
+// ServerConnections are the client connections of a server.
+type ServerConnections struct {
+	Current      int64 `json:"current"`
+	Available    int64 `json:"available"`
+	TotalCreated int64 `json:"totalCreated"`
+}
+
+// ServerMemory is the memory use of a server process in megabytes.
+type ServerMemory struct {
+	ResidentMB int64 `json:"residentMb"`
+	VirtualMB  int64 `json:"virtualMb"`
+}
+
+// NetworkRates are the network traffic per second of a server.
+type NetworkRates struct {
+	BytesInPerSec  float64 `json:"bytesInPerSec"`
+	BytesOutPerSec float64 `json:"bytesOutPerSec"`
+	RequestsPerSec float64 `json:"requestsPerSec"`
+}
+
+// CurrentOpFilter selects the operations ListCurrentOperations returns.
+type CurrentOpFilter struct {
+	Match           string `json:"match,omitempty"` // Extended JSON $match on currentOp documents
+	IdleConnections bool   `json:"idleConnections"` // Include idle connections and sessions
+	AllUsers        bool   `json:"allUsers"`        // Include operations of other users
+}
+
+// CurrentOperation is an operation in progress on a server, from $currentOp.
+type CurrentOperation struct {
+	OpID        string `json:"opId"` // Number, or "shard:number" on mongos
+	Type        string `json:"type,omitempty"`
+	Op          string `json:"op,omitempty"`
+	Namespace   string `json:"ns,omitempty"`
+	Active      bool   `json:"active"`
+	DurationMs  int64  `json:"durationMs"`
+	PlanSummary string `json:"planSummary,omitempty"`
+	Client      string `json:"client,omitempty"`
+	AppName     string `json:"appName,omitempty"`
+	User        string `json:"user,omitempty"`
+	Host        string `json:"host,omitempty"`
+	Shard       string `json:"shard,omitempty"`
+	Description string `json:"desc,omitempty"`
+	WaitingLock bool   `json:"waitingForLock"`
+	Command     string `json:"command,omitempty"` // Relaxed Extended JSON
+}
+
+// ServerLogFilter selects the log entries GetServerLog returns. Empty lists select all.
+type ServerLogFilter struct {
+	Severities []string `json:"severities,omitempty"` // "F", "E", "W", "I", or "D" (any debug level)
+	Components []string `json:"components,omitempty"` // e.g. "NETWORK", "REPL"
+	Search     string   `json:"search,omitempty"`     // Case-insensitive text in the message
+}
+
+// ServerLog is the recent in-memory log of a server, from getLog.
+type ServerLog struct {
+	LogType           string           `json:"logType"`
+	TotalLinesWritten int64            `json:"totalLinesWritten"`
+	Entries           []ServerLogEntry `json:"entries"`
+}
+
+// ServerLogEntry is one server log line. Lines of servers before 4.4 are plain text and have
+// no ID or attributes.
+type ServerLogEntry struct {
+	Time       time.Time `json:"time"`
+	Severity   string    `json:"severity"`
+	Component  string    `json:"component"`
+	ID         int64     `json:"id,omitempty"`
+	Context    string    `json:"context,omitempty"`
+	Message    string    `json:"message"`
+	Attributes string    `json:"attributes,omitempty"` // JSON object
+	Raw        string    `json:"raw"`
+}
+
+// Metrics an alert rule can watch.
+const (
+	AlertMetricConnections    = "connections"    // Current client connections
+	AlertMetricReplicationLag = "replicationLag" // Seconds the furthest secondary is behind
+	AlertMetricDiskUsage      = "diskUsage"      // Percent of the data volume in use
+)
+
+// AlertRule raises an alert while a metric of a polled connection is above Threshold.
+type AlertRule struct {
+	ID        string  `json:"id"`
+	Metric    string  `json:"metric"`
+	Threshold float64 `json:"threshold"`
+	Enabled   bool    `json:"enabled"`
+}
+
+// Alert is a triggered alert rule. ResolvedAt is set once the metric is back at or below
+// the threshold.
+type Alert struct {
+	ConnectionID string     `json:"connectionId"`
+	RuleID       string     `json:"ruleId"`
+	Metric       string     `json:"metric"`
+	Threshold    float64    `json:"threshold"`
+	Value        float64    `json:"value"`
+	Message      string     `json:"message"`
+	TriggeredAt  time.Time  `json:"triggeredAt"`
+	ResolvedAt   *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// HotCollectionReport is the server time spent per collection, from GetHotCollections.
+type HotCollectionReport struct {
+	ConnectionID    string          `json:"connectionId"`
+	Time            time.Time       `json:"time"`
+	IntervalSeconds float64         `json:"intervalSeconds"` // Time since the previous report; 0 means since the server started
+	Source          string          `json:"source"`          // "top", or "collStats" on mongos
+	Collections     []HotCollection `json:"collections"`     // Busiest first
+}
+
+// HotCollection is the time a server spent on one namespace.
+type HotCollection struct {
+	Namespace   string  `json:"ns"`
+	ReadTimeMs  float64 `json:"readTimeMs"`
+	WriteTimeMs float64 `json:"writeTimeMs"`
+	TotalTimeMs float64 `json:"totalTimeMs"`
+	ReadCount   int64   `json:"readCount"`
+	WriteCount  int64   `json:"writeCount"`
+	TotalCount  int64   `json:"totalCount"`
+}
+
+// =============================================================================
+// User and Role Types
+// =============================================================================
+
+// RoleRef names a role and the database it is defined in.
+type RoleRef struct {
+	Role string `json:"role"`
+	DB   string `json:"db"`
+}
+
+// AuthenticationRestriction limits where a user may authenticate from and to. Entries are
+// IP addresses or CIDR ranges.
+type AuthenticationRestriction struct {
+	ClientSource  []string `json:"clientSource,omitempty"`
+	ServerAddress []string `json:"serverAddress,omitempty"`
+}
+
+// DatabaseUser is a user defined in a database, from usersInfo.
+type DatabaseUser struct {
+	User                       string                      `json:"user"`
+	DB                         string                      `json:"db"`
+	Roles                      []RoleRef                   `json:"roles"`
+	Mechanisms                 []string                    `json:"mechanisms,omitempty"`
+	AuthenticationRestrictions []AuthenticationRestriction `json:"authenticationRestrictions,omitempty"`
+	CustomData                 string                      `json:"customData,omitempty"` // Relaxed Extended JSON
+}
+
+// NewDatabaseUser describes a user for CreateUser. Users of the $external database
+// authenticate externally and have no password.
+type NewDatabaseUser struct {
+	User                       string                      `json:"user"`
+	Password                   string                      `json:"password"`
+	Roles                      []RoleRef                   `json:"roles"`
+	Mechanisms                 []string                    `json:"mechanisms,omitempty"`
+	AuthenticationRestrictions []AuthenticationRestriction `json:"authenticationRestrictions,omitempty"`
+	CustomData                 string                      `json:"customData,omitempty"` // Extended JSON document
+}
+
+// PrivilegeResource is what a privilege applies to: the cluster, any resource, or a database
+// and collection, where an empty name matches all databases or collections.
+type PrivilegeResource struct {
+	DB          string `json:"db"`
+	Collection  string `json:"collection"`
+	Cluster     bool   `json:"cluster,omitempty"`
+	AnyResource bool   `json:"anyResource,omitempty"`
+}
+
+// Privilege grants actions on a resource.
+type Privilege struct {
+	Resource PrivilegeResource `json:"resource"`
+	Actions  []string          `json:"actions"`
+}
+
+// DatabaseRole is a role defined in a database, from rolesInfo. The inherited fields resolve
+// the roles it inherits from, transitively.
+type DatabaseRole struct {
+	Role                       string                      `json:"role"`
+	DB                         string                      `json:"db"`
+	IsBuiltin                  bool                        `json:"isBuiltin"`
+	Roles                      []RoleRef                   `json:"roles"`
+	InheritedRoles             []RoleRef                   `json:"inheritedRoles"`
+	Privileges                 []Privilege                 `json:"privileges"`
+	InheritedPrivileges        []Privilege                 `json:"inheritedPrivileges"`
+	AuthenticationRestrictions []AuthenticationRestriction `json:"authenticationRestrictions,omitempty"`
+}
+
+// NewDatabaseRole describes a custom role for CreateRole.
+type NewDatabaseRole struct {
+	Role                       string                      `json:"role"`
+	Privileges                 []Privilege                 `json:"privileges"`
+	Roles                      []RoleRef                   `json:"roles"`
+	AuthenticationRestrictions []AuthenticationRestriction `json:"authenticationRestrictions,omitempty"`
 }
 
 // =============================================================================
@@ -163,9 +480,12 @@ type ConnectionFormData struct {
 	SRVHostname            string      `json:"srvHostname"`
 	ReplicaSetName         string      `json:"replicaSetName"`
 	DefaultDatabase        string      `json:"defaultDatabase"`
-	AuthMechanism          string      `json:"authMechanism"` // none|scram-sha-1|scram-sha-256|x509|mongodb-aws|kerberos
+	AuthMechanism          string      `json:"authMechanism"` // none|scram-sha-1|scram-sha-256|x509|mongodb-aws|kerberos|plain
 	Username               string      `json:"username"`
 	AuthDatabase           string      `json:"authDatabase"`
+	GSSAPIServiceName      string      `json:"gssapiServiceName,omitempty"`      // Kerberos service principal name, default "mongodb"
+	GSSAPIServiceRealm     string      `json:"gssapiServiceRealm,omitempty"`     // Realm of the service when it differs from the user's
+	GSSAPICanonicalizeHost bool        `json:"gssapiCanonicalizeHost,omitempty"` // Resolve the host's canonical name for the service principal
 	TLSEnabled             bool        `json:"tlsEnabled"`
 	TLSInsecure            bool        `json:"tlsInsecure"`
 	MaxPoolSize            int         `json:"maxPoolSize"`
@@ -195,9 +515,21 @@ type DatabaseInfo struct {
 
 // CollectionInfo describes a MongoDB collection.
 type CollectionInfo struct {
-	Name  string `json:"name"`
-	Type  string `json:"type"`
-	Count int64  `json:"count"`
+	Name       string             `json:"name"`
+	Type       string             `json:"type"`
+	Count      int64              `json:"count"`
+	TimeSeries *TimeSeriesOptions `json:"timeseries,omitempty"` // Set for time-series collections
+	ViewOn     string             `json:"viewOn,omitempty"`     // Source collection of a view
+	Pipeline   string             `json:"pipeline,omitempty"`   // Extended JSON pipeline of a view
+}
+
+// TimeSeriesOptions describes how a time-series collection buckets its measurements.
+type TimeSeriesOptions struct {
+	TimeField            string `json:"timeField"`
+	MetaField            string `json:"metaField,omitempty"`
+	Granularity          string `json:"granularity,omitempty"`          // "seconds", "minutes", or "hours"
+	BucketMaxSpanSeconds int64  `json:"bucketMaxSpanSeconds,omitempty"` // Custom bucketing (6.3+)
+	ExpireAfterSeconds   int64  `json:"expireAfterSeconds,omitempty"`   // Automatic removal of old measurements
 }
 
 // IndexInfo describes a MongoDB index.
@@ -209,15 +541,33 @@ type IndexInfo struct {
 	TTL        int64          `json:"ttl,omitempty"`        // TTL in seconds, 0 if not a TTL index
 	Size       int64          `json:"size"`                 // Index size in bytes
 	UsageCount int64          `json:"usageCount,omitempty"` // Number of operations that used this index
+	UsageSince time.Time      `json:"usageSince,omitempty"` // When UsageCount started counting (server restart or index build)
+	Hidden     bool           `json:"hidden,omitempty"`     // Hidden from the query planner but still maintained
 }
 
 // IndexOptions specifies options for creating an index.
 type IndexOptions struct {
-	Unique             bool   `json:"unique"`
-	Sparse             bool   `json:"sparse"`
-	Background         bool   `json:"background"`
-	ExpireAfterSeconds int64  `json:"expireAfterSeconds,omitempty"` // TTL in seconds
-	Name               string `json:"name,omitempty"`               // Custom index name
+	Unique                  bool       `json:"unique"`
+	Sparse                  bool       `json:"sparse"`
+	Background              bool       `json:"background"`
+	ExpireAfterSeconds      int64      `json:"expireAfterSeconds,omitempty"`      // TTL in seconds
+	Name                    string     `json:"name,omitempty"`                    // Custom index name
+	PartialFilterExpression string     `json:"partialFilterExpression,omitempty"` // Extended JSON filter; only matching documents are indexed
+	Collation               *Collation `json:"collation,omitempty"`
+	WildcardProjection      string     `json:"wildcardProjection,omitempty"` // Extended JSON projection for a "$**" index
+	Weights                 string     `json:"weights,omitempty"`            // Extended JSON field weights for a text index
+	DefaultLanguage         string     `json:"defaultLanguage,omitempty"`    // Text index stemming language
+	LanguageOverride        string     `json:"languageOverride,omitempty"`   // Field holding a per-document language
+}
+
+// IndexBuildProgress is emitted while CreateIndex waits for an index build.
+type IndexBuildProgress struct {
+	Database   string `json:"database"`
+	Collection string `json:"collection"`
+	Index      string `json:"index"`
+	Done       int64  `json:"done"`    // Keys or documents processed in the current phase
+	Total      int64  `json:"total"`   // 0 when the server reports no estimate
+	Message    string `json:"message"` // Build phase as reported by currentOp
 }
 
 // CollectionExportInfo provides collection info for the export modal.
@@ -229,14 +579,191 @@ type CollectionExportInfo struct {
 
 // CollectionStats contains statistics about a MongoDB collection.
 type CollectionStats struct {
-	Namespace      string `json:"namespace"`      // Full namespace (db.collection)
-	Count          int64  `json:"count"`          // Number of documents
-	Size           int64  `json:"size"`           // Total uncompressed size of documents in bytes
-	StorageSize    int64  `json:"storageSize"`    // Storage size on disk in bytes
-	AvgObjSize     int64  `json:"avgObjSize"`     // Average document size in bytes
-	IndexCount     int    `json:"indexCount"`     // Number of indexes
-	TotalIndexSize int64  `json:"totalIndexSize"` // Total size of all indexes in bytes
-	Capped         bool   `json:"capped"`         // Whether collection is capped
+	Namespace        string           `json:"namespace"`              // Full namespace (db.collection)
+	Count            int64            `json:"count"`                  // Number of documents
+	Size             int64            `json:"size"`                   // Total uncompressed size of documents in bytes
+	StorageSize      int64            `json:"storageSize"`            // Storage size on disk in bytes
+	FreeStorageSize  int64            `json:"freeStorageSize"`        // Bytes on disk available for reuse
+	AvgObjSize       int64            `json:"avgObjSize"`             // Average document size in bytes
+	CompressionRatio float64          `json:"compressionRatio"`       // Data size over used storage; 0 if unknown
+	IndexCount       int              `json:"indexCount"`             // Number of indexes
+	TotalIndexSize   int64            `json:"totalIndexSize"`         // Total size of all indexes in bytes
+	IndexSizes       map[string]int64 `json:"indexSizes"`             // Size of each index in bytes, by name
+	Capped           bool             `json:"capped"`                 // Whether collection is capped
+	MaxSize          int64            `json:"maxSize,omitempty"`      // Capped size limit in bytes
+	MaxDocuments     int64            `json:"maxDocuments,omitempty"` // Capped document limit, if any
+	Sharded          bool             `json:"sharded"`                // Whether the collection is sharded
+}
+
+// DatabaseStats summarizes a database and breaks its size down per collection.
+type DatabaseStats struct {
+	Database      string            `json:"database"`
+	Collections   int               `json:"collections"` // Number of collections, excluding views
+	Views         int               `json:"views"`
+	Objects       int64             `json:"objects"`     // Number of documents
+	DataSize      int64             `json:"dataSize"`    // Total uncompressed size of documents in bytes
+	StorageSize   int64             `json:"storageSize"` // Storage allocated for documents in bytes
+	IndexCount    int               `json:"indexCount"`
+	IndexSize     int64             `json:"indexSize"`     // Storage allocated for indexes in bytes
+	TotalSize     int64             `json:"totalSize"`     // Storage plus index size in bytes
+	FsUsedSize    int64             `json:"fsUsedSize"`    // Used space on the data filesystem; 0 if unknown
+	FsTotalSize   int64             `json:"fsTotalSize"`   // Size of the data filesystem; 0 if unknown
+	PerCollection []CollectionStats `json:"perCollection"` // Sorted by name
+}
+
+// ConvertToCappedResult reports a capped conversion or, for a dry run, what it would do.
+type ConvertToCappedResult struct {
+	DryRun         bool     `json:"dryRun"`
+	Count          int64    `json:"count"`          // Documents before conversion
+	DataSize       int64    `json:"dataSize"`       // Uncompressed data size before conversion
+	RequestedSize  int64    `json:"requestedSize"`  // Cap in bytes
+	RequestedMax   int64    `json:"requestedMax"`   // Document cap; 0 for none
+	EstimatedKept  int64    `json:"estimatedKept"`  // Newest documents expected to remain
+	IndexesDropped []string `json:"indexesDropped"` // Indexes other than _id, which the conversion does not keep
+	Warnings       []string `json:"warnings"`
+}
+// TTLExpiryPreview reports how the TTL indexes of a collection affect its documents.
+type TTLExpiryPreview struct {
+	Namespace   string            `json:"namespace"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Indexes     []TTLIndexPreview `json:"indexes"` // Empty if the collection has no TTL index
+}
+
+// TTLIndexPreview counts the documents a TTL index removes, by when they expire.
+type TTLIndexPreview struct {
+	Name               string `json:"name"`
+	Field              string `json:"field"`
+	ExpireAfterSeconds int64  `json:"expireAfterSeconds"`
+	PartialFilter      string `json:"partialFilter,omitempty"` // Only matching documents expire
+	PastDue            int64  `json:"pastDue"`                 // Expired but not yet removed by the TTL monitor
+	WithinHour         int64  `json:"withinHour"`              // Expiring in the next hour
+	WithinDay          int64  `json:"withinDay"`               // Expiring in the next 24 hours, including the next hour
+	NeverExpire        int64  `json:"neverExpire"`             // Documents without a date in the field
+}
+// CollectionValidation is the structured result of the validate command.
+type CollectionValidation struct {
+	Namespace           string            `json:"namespace"`
+	Full                bool              `json:"full"`  // Whether a full validation was requested
+	Valid               bool              `json:"valid"` // False if any shard reported problems
+	Records             int64             `json:"records"`
+	InvalidDocuments    int64             `json:"invalidDocuments"`    // Documents failing the collection's validator
+	CorruptRecords      []string          `json:"corruptRecords"`      // Record IDs of unreadable documents
+	ExtraIndexEntries   int               `json:"extraIndexEntries"`   // Index keys with no matching document
+	MissingIndexEntries int               `json:"missingIndexEntries"` // Documents missing from an index
+	Indexes             []IndexValidation `json:"indexes"`
+	Warnings            []string          `json:"warnings"`
+	Errors              []string          `json:"errors"`
+	Raw                 string            `json:"raw"` // Full validate output as JSON
+}
+
+// IndexValidation reports the validate result for one index.
+type IndexValidation struct {
+	Name  string `json:"name"`
+	Valid bool   `json:"valid"`
+	Keys  int64  `json:"keys"` // Keys traversed
+}
+
+// CompactResult reports a compact run.
+type CompactResult struct {
+	OperationID       string `json:"operationId"` // Matches "compact:progress" events
+	BytesFreed        int64  `json:"bytesFreed"`  // As reported by the server
+	StorageSizeBefore int64  `json:"storageSizeBefore"`
+	StorageSizeAfter  int64  `json:"storageSizeAfter"`
+	DurationMs        int64  `json:"durationMs"`
+}
+
+// CompactProgress is emitted periodically while compact runs. The estimate assumes the file
+// keeps shrinking at the rate seen so far towards the space reported as reusable at the start.
+type CompactProgress struct {
+	OperationID          string `json:"operationId"`
+	Database             string `json:"database"`
+	Collection           string `json:"collection"`
+	StorageSize          int64  `json:"storageSize"`
+	Reclaimable          int64  `json:"reclaimable"` // Reusable bytes when compact started
+	Reclaimed            int64  `json:"reclaimed"`
+	ElapsedMs            int64  `json:"elapsedMs"`
+	EstimatedRemainingMs int64  `json:"estimatedRemainingMs"` // -1 until there is a rate to extrapolate from
+}
+
+// ShardingInfo describes how a collection is distributed in a sharded cluster.
+type ShardingInfo struct {
+	Namespace           string          `json:"namespace"`
+	ClusterSharded      bool            `json:"clusterSharded"`                // Connected through mongos
+	Sharded             bool            `json:"sharded"`                       // Whether the collection is sharded
+	PrimaryShard        string          `json:"primaryShard,omitempty"`        // Shard holding the database's unsharded data
+	ShardKey            string          `json:"shardKey,omitempty"`            // Shard key pattern as JSON
+	Unique              bool            `json:"unique"`                        // Whether the shard key enforces uniqueness
+	BalancingEnabled    bool            `json:"balancingEnabled"`              // False if balancing is disabled for the collection
+	BalancerCompliant   bool            `json:"balancerCompliant"`             // Chunks are distributed as the balancer wants
+	ComplianceViolation string          `json:"complianceViolation,omitempty"` // First reason the collection is not balanced
+	Balancer            *BalancerStatus `json:"balancer,omitempty"`
+	Chunks              []ShardChunks   `json:"chunks"`
+	TotalChunks         int64           `json:"totalChunks"`
+}
+
+// BalancerStatus is the cluster-wide balancer state. Window and Migrations are only filled
+// by GetBalancerStatus.
+type BalancerStatus struct {
+	Mode       string           `json:"mode"`    // "full" or "off"
+	InRound    bool             `json:"inRound"` // Whether a balancing round is running
+	Rounds     int64            `json:"rounds"`  // Balancing rounds since the config server primary started
+	Window     *BalancerWindow  `json:"window,omitempty"`
+	Migrations []ChunkMigration `json:"migrations,omitempty"` // Most recent first
+}
+
+// BalancerWindow is the daily time range, in the config servers' local time, in which the
+// balancer may migrate chunks.
+type BalancerWindow struct {
+	Start string `json:"start"` // "HH:MM"
+	Stop  string `json:"stop"`  // "HH:MM"
+}
+
+// ChunkMigration is a chunk migration recorded in config.changelog.
+type ChunkMigration struct {
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"ns"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Status    string    `json:"status"` // "success", "aborted", or "error"
+	Error     string    `json:"error,omitempty"`
+}
+
+// ShardChunks is the number of chunks of a collection on one shard.
+type ShardChunks struct {
+	Shard   string  `json:"shard"`
+	Chunks  int64   `json:"chunks"`
+	Percent float64 `json:"percent"`
+}
+
+// ClusterTopology describes the members of a sharded cluster.
+type ClusterTopology struct {
+	Version       string          `json:"version"` // Version of the connected mongos
+	ConfigServers ClusterReplSet  `json:"configServers"`
+	Shards        []ClusterShard  `json:"shards"`
+	Routers       []ClusterRouter `json:"routers"`
+}
+
+// ClusterReplSet is a replica set of a cluster and its member hosts. Standalone shards have
+// no set name.
+type ClusterReplSet struct {
+	SetName string   `json:"setName,omitempty"`
+	Hosts   []string `json:"hosts"`
+}
+
+// ClusterShard is a shard of a cluster, from config.shards.
+type ClusterShard struct {
+	ID       string         `json:"id"`
+	ReplSet  ClusterReplSet `json:"replSet"`
+	Draining bool           `json:"draining"`
+	Tags     []string       `json:"tags,omitempty"` // Zones
+}
+
+// ClusterRouter is a mongos router that has pinged the config servers, from config.mongos.
+type ClusterRouter struct {
+	Host          string    `json:"host"`
+	Version       string    `json:"version"`
+	LastPing      time.Time `json:"lastPing"`
+	UptimeSeconds int64     `json:"uptimeSeconds"`
 }
 
 // CollectionProfile is a lightweight summary of collection characteristics,
@@ -250,6 +777,32 @@ type CollectionProfile struct {
 	TopFields       []string `json:"topFields"`        // Top-level field names from sample (for auto-projection)
 }
 
+// ProfilerStatus is the database profiler setting of a database.
+type ProfilerStatus struct {
+	Database   string  `json:"database"`
+	Level      int     `json:"level"`      // 0 off, 1 slow operations, 2 all operations
+	SlowMs     int64   `json:"slowms"`     // Threshold for slow operations
+	SampleRate float64 `json:"sampleRate"` // Fraction of slow operations profiled
+	Filter     string  `json:"filter,omitempty"`
+}
+
+// ProfiledOperation is an operation recorded in a database's system.profile collection.
+type ProfiledOperation struct {
+	Timestamp      time.Time `json:"ts"`
+	Op             string    `json:"op"`
+	Namespace      string    `json:"ns"`
+	Millis         int64     `json:"millis"`
+	PlanSummary    string    `json:"planSummary,omitempty"`
+	KeysExamined   int64     `json:"keysExamined"`
+	DocsExamined   int64     `json:"docsExamined"`
+	NReturned      int64     `json:"nreturned"`
+	ResponseLength int64     `json:"responseLength"`
+	User           string    `json:"user,omitempty"`
+	Client         string    `json:"client,omitempty"`
+	AppName        string    `json:"appName,omitempty"`
+	Command        string    `json:"command,omitempty"` // Relaxed Extended JSON
+}
+
 // =============================================================================
 // Query Types
 // =============================================================================
@@ -264,6 +817,49 @@ type ExplainResult struct {
 	RawExplain       string               `json:"rawExplain"`       // Full explain output as JSON
 }
 
+// IndexSuggestion is a candidate index proposed by SuggestIndexes. Keys and Options can
+// be passed to CreateIndex as they are.
+type IndexSuggestion struct {
+	Keys             string       `json:"keys"` // Extended JSON key specification
+	Options          IndexOptions `json:"options"`
+	Reason           string       `json:"reason"`
+	Sources          []string     `json:"sources"`     // "history" and/or "profiler"
+	Occurrences      int          `json:"occurrences"` // Analyzed queries this index would serve
+	ExampleFilter    string       `json:"exampleFilter"`
+	ExampleSort      string       `json:"exampleSort,omitempty"`
+	DocsExamined     int64        `json:"docsExamined"` // From explain of the example query
+	NReturned        int64        `json:"nReturned"`
+	EstimatedBenefit int64        `json:"estimatedBenefit"` // Documents examined needlessly per example query, times Occurrences
+}
+
+// IndexSuggestionResult is the result of SuggestIndexes, most beneficial suggestion first.
+type IndexSuggestionResult struct {
+	Suggestions     []IndexSuggestion `json:"suggestions"`
+	QueriesAnalyzed int               `json:"queriesAnalyzed"`
+	ProfilerUsed    bool              `json:"profilerUsed"`
+	Notes           []string          `json:"notes,omitempty"`
+}
+
+// BenchmarkResult reports repeated-execution latency for a query, in milliseconds,
+// together with plan statistics from a single explain.
+type BenchmarkResult struct {
+	Iterations        int      `json:"iterations"`
+	DocsReturned      int64    `json:"docsReturned"` // Per run
+	MinMs             float64  `json:"minMs"`
+	MaxMs             float64  `json:"maxMs"`
+	MeanMs            float64  `json:"meanMs"`
+	P50Ms             float64  `json:"p50Ms"`
+	P90Ms             float64  `json:"p90Ms"`
+	P95Ms             float64  `json:"p95Ms"`
+	P99Ms             float64  `json:"p99Ms"`
+	TotalKeysExamined int64    `json:"totalKeysExamined"`
+	TotalDocsExamined int64    `json:"totalDocsExamined"`
+	IndexUsed         string   `json:"indexUsed,omitempty"`
+	IsCollectionScan  bool     `json:"isCollectionScan"`
+	WinningPlan       string   `json:"winningPlan,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
 // QueryPlannerResult contains query planner information.
 type QueryPlannerResult struct {
 	Namespace        string `json:"namespace"`
@@ -284,10 +880,52 @@ type ExecutionStatsResult struct {
 
 // QueryOptions specifies parameters for document queries.
 type QueryOptions struct {
-	Skip       int64  `json:"skip"`
-	Limit      int64  `json:"limit"`
-	Sort       string `json:"sort"`
-	Projection string `json:"projection"`
+	Skip       int64      `json:"skip"`
+	Limit      int64      `json:"limit"`
+	Sort       string     `json:"sort"`
+	Projection string     `json:"projection"`
+	Keyset     bool       `json:"keyset,omitempty"`    // Paginate by _id range instead of skip (implied by AfterID); Sort must be empty or "_id"
+	AfterID    string     `json:"afterId,omitempty"`   // Cursor token from a previous QueryResult.NextCursor
+	CountMode  string     `json:"countMode,omitempty"` // "exact" (default), "async", or "estimated"
+	MaxTimeMS  int64      `json:"maxTimeMS,omitempty"` // Server-side time limit; 0 uses the configured query timeout
+	Collation  *Collation `json:"collation,omitempty"` // Locale-aware matching and sorting
+
+	ReadPreference string `json:"readPreference,omitempty"` // e.g. "secondaryPreferred"; empty uses the connection default
+	ReadConcern    string `json:"readConcern,omitempty"`    // e.g. "majority"; empty uses the connection default
+	OutputMode     string `json:"outputMode,omitempty"`     // "canonical" or "relaxed" Extended JSON; empty uses the app setting
+}
+
+// WriteConcern is the acknowledgment a single write waits for. A nil WriteConcern keeps the
+// connection's write concern (from the URI or the server default).
+type WriteConcern struct {
+	W          interface{} `json:"w,omitempty"`          // "majority", a node count, or a custom tag set name
+	J          bool        `json:"j,omitempty"`          // Wait until the write is in the on-disk journal
+	WTimeoutMS int64       `json:"wtimeoutMs,omitempty"` // Fail the write if not acknowledged in time; 0 waits indefinitely
+}
+
+// AggregateOptions configures RunAggregation.
+type AggregateOptions struct {
+	MaxTimeMS      int64      `json:"maxTimeMS,omitempty"` // Server-side time limit; 0 uses the configured query timeout
+	AllowDiskUse   bool       `json:"allowDiskUse,omitempty"`
+	Collation      *Collation `json:"collation,omitempty"`
+	ReadPreference string     `json:"readPreference,omitempty"` // e.g. "secondaryPreferred"; empty uses the connection default
+	ReadConcern    string     `json:"readConcern,omitempty"`    // e.g. "majority"; empty uses the connection default
+	OutputMode     string     `json:"outputMode,omitempty"`     // "canonical" or "relaxed" Extended JSON; empty uses the app setting
+}
+
+// AggregationResult contains the output documents of an aggregation pipeline.
+type AggregationResult struct {
+	Documents       []string `json:"documents"` // Extended JSON strings
+	ExecutionTimeMs int64    `json:"executionTimeMs"`
+	Truncated       bool     `json:"truncated"` // More than MaxAggregationDocuments were produced
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+// Collation specifies locale-aware string comparison for matching and sorting.
+type Collation struct {
+	Locale    string `json:"locale"`              // ICU locale, e.g. "en" or "de@collation=phonebook"
+	Strength  int    `json:"strength,omitempty"`  // Comparison level 1-5; 0 uses the server default (3)
+	CaseLevel bool   `json:"caseLevel,omitempty"` // Distinguish case at strength 1 or 2
 }
 
 // QueryResult contains the result of a document query.
@@ -297,6 +935,396 @@ type QueryResult struct {
 	HasMore     bool     `json:"hasMore"`
 	QueryTimeMs int64    `json:"queryTimeMs"`
 	Warnings    []string `json:"warnings,omitempty"` // Non-fatal errors during query
+	NextCursor  string   `json:"nextCursor,omitempty"` // Keyset pagination: pass as AfterID to fetch the next page
+	CountID     string   `json:"countId,omitempty"`    // Set when Total is -1; matches the later "query:count" event and GetQueryCount
+}
+
+// DeleteManyResult reports a bulk delete as it starts. For a dry run only Matched and Sample
+// are set; otherwise the outcome arrives as a "delete:done" event.
+type DeleteManyResult struct {
+	OperationID string   `json:"operationId,omitempty"` // Matches "delete:progress" and "delete:done" events; pass to CancelQuery to stop
+	DryRun      bool     `json:"dryRun"`
+	Matched     int64    `json:"matched"`
+	Sample      []string `json:"sample,omitempty"` // Extended JSON of some matching documents (dry run)
+}
+
+// DeleteProgress is emitted after each batch of a bulk delete.
+type DeleteProgress struct {
+	OperationID string `json:"operationId"`
+	Database    string `json:"database"`
+	Collection  string `json:"collection"`
+	Deleted     int64  `json:"deleted"`
+	Total       int64  `json:"total"` // Matched when the delete started
+}
+
+// DeleteDone is emitted once when a bulk delete finishes, fails, or is cancelled.
+type DeleteDone struct {
+	OperationID string `json:"operationId"`
+	Database    string `json:"database"`
+	Collection  string `json:"collection"`
+	Deleted     int64  `json:"deleted"`
+	Cancelled   bool   `json:"cancelled"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CopyDocumentsOptions configures CopyDocuments.
+type CopyDocumentsOptions struct {
+	Mode      string `json:"mode"`      // "skip" (default), "override", or "fail" when a target _id exists
+	BatchSize int    `json:"batchSize"` // Documents per write; 0 uses the default
+}
+
+// CopyDocumentsResult reports a document copy between namespaces.
+type CopyDocumentsResult struct {
+	OperationID string `json:"operationId"` // Matches "copy:progress" events; pass to CancelQuery to stop
+	Total       int64  `json:"total"`       // Documents matching the filter when the copy started
+	Copied      int64  `json:"copied"`
+	Skipped     int64  `json:"skipped"` // Duplicates left untouched in skip mode
+	Cancelled   bool   `json:"cancelled"`
+}
+
+// CopyCollectionResult reports a collection copy.
+type CopyCollectionResult struct {
+	CopyDocumentsResult
+	IndexesCopied int `json:"indexesCopied"`
+}
+
+// CopyProgress is emitted after each batch of a document copy.
+type CopyProgress struct {
+	OperationID string `json:"operationId"`
+	Processed   int64  `json:"processed"`
+	Total       int64  `json:"total"`
+}
+
+// CopyDatabaseOptions configures CopyDatabase.
+type CopyDatabaseOptions struct {
+	Mode           string   `json:"mode"`           // "skip" (default) or "override" for duplicate _ids, "drop" to replace existing target collections, "fail" if any exists
+	Collections    []string `json:"collections"`    // Collections and views to copy; empty copies all
+	IncludeIndexes bool     `json:"includeIndexes"` // Build the source's secondary indexes on the target
+	BatchSize      int      `json:"batchSize"`      // Documents per write; 0 uses the default
+}
+
+// CopyDatabaseResult reports a database copy.
+type CopyDatabaseResult struct {
+	OperationID string                   `json:"operationId"` // Matches "copydb:progress" events; pass to CancelQuery to stop
+	Collections []CopyDatabaseCollection `json:"collections"`
+	Views       int                      `json:"views"` // Views recreated on the target
+	Copied      int64                    `json:"copied"`
+	Skipped     int64                    `json:"skipped"`
+	Cancelled   bool                     `json:"cancelled"`
+	DurationMs  int64                    `json:"durationMs"`
+}
+
+// CopyDatabaseCollection reports the copy of one collection within a database copy.
+type CopyDatabaseCollection struct {
+	Name          string `json:"name"`
+	Copied        int64  `json:"copied"`
+	Skipped       int64  `json:"skipped"`
+	IndexesCopied int    `json:"indexesCopied"`
+}
+
+// CopyDatabaseProgress is emitted after each batch of a database copy.
+type CopyDatabaseProgress struct {
+	OperationID     string `json:"operationId"`
+	Collection      string `json:"collection"`
+	CollectionIndex int    `json:"collectionIndex"` // 1-based
+	CollectionTotal int    `json:"collectionTotal"`
+	Processed       int64  `json:"processed"` // Documents of the current collection
+	Total           int64  `json:"total"`     // Estimated documents in the current collection
+}
+// GenerateResult reports a fake data generation run.
+type GenerateResult struct {
+	OperationID string `json:"operationId"` // Matches "generate:progress" events; pass to CancelQuery to stop
+	Inserted    int64  `json:"inserted"`
+	DurationMs  int64  `json:"durationMs"`
+	Cancelled   bool   `json:"cancelled"`
+}
+
+// GenerateProgress is emitted after each batch of generated documents is inserted.
+type GenerateProgress struct {
+	OperationID string `json:"operationId"`
+	Inserted    int64  `json:"inserted"`
+	Total       int64  `json:"total"`
+}
+
+// InsertManyResult reports a multi-document insert.
+type InsertManyResult struct {
+	InsertedIDs []string        `json:"insertedIds"`
+	Errors      []DocumentError `json:"errors,omitempty"`
+}
+
+// DocumentError describes a failure for one document of a multi-document write.
+type DocumentError struct {
+	Index   int    `json:"index"` // Position of the document in the input
+	Message string `json:"message"`
+}
+
+// FieldDiff is one field-level difference between two documents. Values are canonical
+// Extended JSON; Old* is empty for added fields and New* for removed ones.
+type FieldDiff struct {
+	Path     string `json:"path"` // Dotted path; array elements use their index
+	Kind     string `json:"kind"` // "added", "removed", or "changed"
+	OldValue string `json:"oldValue,omitempty"`
+	NewValue string `json:"newValue,omitempty"`
+	OldType  string `json:"oldType,omitempty"`
+	NewType  string `json:"newType,omitempty"`
+}
+
+// DocumentDiff is the result of comparing two documents.
+type DocumentDiff struct {
+	Identical bool        `json:"identical"`
+	Changes   []FieldDiff `json:"changes"`
+}
+
+// DuplicateGroup is a set of documents sharing the same values for the chosen fields.
+type DuplicateGroup struct {
+	Key       string   `json:"key"` // Shared field values as Extended JSON, e.g. {"email": "a@b.c"}
+	Count     int64    `json:"count"`
+	SampleIDs []string `json:"sampleIds"` // Extended JSON _ids, lowest first
+}
+
+// DeleteDuplicatesResult reports a duplicate cleanup.
+type DeleteDuplicatesResult struct {
+	Groups  int64 `json:"groups"`
+	Deleted int64 `json:"deleted"`
+}
+
+// DocumentSize is the BSON size of one document.
+type DocumentSize struct {
+	ID             string  `json:"id"` // Extended JSON _id
+	Bytes          int64   `json:"bytes"`
+	PercentOfLimit float64 `json:"percentOfLimit"` // Share of the 16MB document limit
+}
+
+// SizeBucket is one bar of a document size histogram, covering [MinBytes, MaxBytes).
+type SizeBucket struct {
+	MinBytes int64 `json:"minBytes"`
+	MaxBytes int64 `json:"maxBytes,omitempty"` // 0 for the open-ended last bucket
+	Count    int64 `json:"count"`
+}
+
+// DocumentSizeReport summarizes the document sizes of a collection.
+type DocumentSizeReport struct {
+	Count      int64          `json:"count"`
+	TotalBytes int64          `json:"totalBytes"`
+	AvgBytes   int64          `json:"avgBytes"`
+	MinBytes   int64          `json:"minBytes"`
+	MaxBytes   int64          `json:"maxBytes"`
+	LimitBytes int64          `json:"limitBytes"`
+	Largest    []DocumentSize `json:"largest"` // Largest first
+	Histogram  []SizeBucket   `json:"histogram"`
+}
+
+// FieldGap counts the documents with one kind of gap in a field: "missing", "null", or "empty".
+type FieldGap struct {
+	Kind      string   `json:"kind"`
+	Count     int64    `json:"count"`
+	Percent   float64  `json:"percent"`   // Share of all documents
+	SampleIDs []string `json:"sampleIds"` // Extended JSON _ids
+}
+
+// MissingFieldsReport summarizes how often a field is missing or blank across a collection.
+type MissingFieldsReport struct {
+	Field   string     `json:"field"`
+	Total   int64      `json:"total"`
+	Present int64      `json:"present"` // Documents with a non-null, non-empty value
+	Gaps    []FieldGap `json:"gaps"`
+}
+
+// BinaryFieldPreview describes a binary field of a document with the start of its contents.
+type BinaryFieldPreview struct {
+	Field       string `json:"field"`
+	Subtype     int    `json:"subtype"`
+	Length      int64  `json:"length"`
+	ContentType string `json:"contentType"` // Sniffed from the content, e.g. "image/png"
+	Snippet     string `json:"snippet"`     // Base64 of up to the first 256KB
+	Truncated   bool   `json:"truncated"`
+}
+
+// TransactionResult reports a committed transaction.
+type TransactionResult struct {
+	Token      string `json:"token"`
+	Operations int    `json:"operations"` // Writes applied in the transaction
+	DurationMs int64  `json:"durationMs"` // Time from BeginTransaction to commit
+}
+
+// MigrationResult reports a collection-wide field migration. For a dry run only Matched is set.
+type MigrationResult struct {
+	OperationID string `json:"operationId,omitempty"` // Matches "migration:progress" events; pass to CancelQuery to stop
+	DryRun      bool   `json:"dryRun"`
+	Matched     int64  `json:"matched"`   // Documents the migration applies to
+	Processed   int64  `json:"processed"` // Documents visited so far
+	Modified    int64  `json:"modified"`
+	Cancelled   bool   `json:"cancelled"`
+}
+
+// ConversionFailure is a document whose value could not be converted to the target type.
+type ConversionFailure struct {
+	ID    string `json:"id"`    // _id as Extended JSON
+	Value string `json:"value"` // Current value as Extended JSON
+}
+
+// FieldConversionPreview reports which values of a field can be converted to a new type.
+type FieldConversionPreview struct {
+	Matched        int64               `json:"matched"` // Documents where the field has the source type
+	Convertible    int64               `json:"convertible"`
+	NotConvertible int64               `json:"notConvertible"`
+	Failures       []ConversionFailure `json:"failures"` // Up to 100 non-convertible values
+}
+
+// FieldConversionResult reports a field type conversion. Documents listed in Failures were left unchanged.
+type FieldConversionResult struct {
+	MigrationResult
+	Failed   int64               `json:"failed"`
+	Failures []ConversionFailure `json:"failures"`
+}
+
+// TransformError is a document the transform script failed on. The document was left unchanged.
+type TransformError struct {
+	ID      string `json:"id"` // _id as Extended JSON
+	Message string `json:"message"`
+}
+
+// TransformPreview shows one document before and after a dry-run transform, as Extended JSON.
+type TransformPreview struct {
+	ID     string `json:"id"`
+	Before string `json:"before"`
+	After  string `json:"after"` // Empty when the script skipped the document
+}
+
+// TransformResult reports a scripted transform. A dry run transforms only a sample and fills
+// Preview; nothing is written.
+type TransformResult struct {
+	MigrationResult
+	Unchanged int64              `json:"unchanged"` // Documents the script skipped or returned as-is
+	Failed    int64              `json:"failed"`
+	Errors    []TransformError   `json:"errors"` // Up to 100 failures
+	Preview   []TransformPreview `json:"preview,omitempty"`
+}
+
+// MigrationProgress is emitted after each batch of a field migration.
+type MigrationProgress struct {
+	OperationID string `json:"operationId"`
+	Database    string `json:"database"`
+	Collection  string `json:"collection"`
+	Processed   int64  `json:"processed"`
+	Modified    int64  `json:"modified"`
+	Total       int64  `json:"total"`
+}
+
+// QueryCount is emitted when a background count for a FindDocuments call completes.
+type QueryCount struct {
+	CountID   string `json:"countId"`
+	Total     int64  `json:"total"`
+	Estimated bool   `json:"estimated"`
+	Error     string `json:"error,omitempty"`
+}
+
+// QueryBatch is emitted for each batch of documents produced by a streaming query.
+type QueryBatch struct {
+	QueryID    string   `json:"queryId"`
+	BatchIndex int      `json:"batchIndex"`
+	Documents  []string `json:"documents"` // Extended JSON strings
+}
+
+// QueryDone is emitted once when a streaming query finishes, fails, or is cancelled.
+type QueryDone struct {
+	QueryID     string   `json:"queryId"`
+	Count       int64    `json:"count"` // Documents streamed
+	QueryTimeMs int64    `json:"queryTimeMs"`
+	Cancelled   bool     `json:"cancelled"`
+	Error       string   `json:"error,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// SearchCapabilities reports which kinds of search indexes exist on a collection.
+type SearchCapabilities struct {
+	HasTextIndex       bool     `json:"hasTextIndex"`
+	TextIndexName      string   `json:"textIndexName,omitempty"`
+	TextFields         []string `json:"textFields,omitempty"`
+	HasAtlasSearch     bool     `json:"hasAtlasSearch"`
+	AtlasSearchIndexes []string `json:"atlasSearchIndexes,omitempty"`
+}
+
+// SearchOptions configures a full-text search.
+type SearchOptions struct {
+	Mode     string   `json:"mode,omitempty"`     // "text", "atlas", or empty to choose from available indexes
+	Index    string   `json:"index,omitempty"`    // Atlas Search index name (default "default")
+	Paths    []string `json:"paths,omitempty"`    // Atlas Search fields; empty searches all fields
+	Filter   string   `json:"filter,omitempty"`   // Additional Extended JSON filter
+	Language string   `json:"language,omitempty"` // $text language override
+	Limit    int64    `json:"limit,omitempty"`
+
+	MaxTimeMS int64 `json:"maxTimeMS,omitempty"` // Server-side time limit; 0 uses the configured query timeout
+}
+
+// ScoredDocument is a search hit with its relevance score.
+type ScoredDocument struct {
+	Document string  `json:"document"` // Extended JSON
+	Score    float64 `json:"score"`
+}
+
+// SearchResult contains search hits ordered by descending score.
+type SearchResult struct {
+	Mode        string           `json:"mode"` // Search mode actually used
+	Results     []ScoredDocument `json:"results"`
+	QueryTimeMs int64            `json:"queryTimeMs"`
+}
+
+// GeoQuery describes a geospatial filter for BuildGeoQuery.
+type GeoQuery struct {
+	Operator          string  `json:"operator"`                    // "near", "within", or "intersects"
+	Field             string  `json:"field"`                       // Field holding GeoJSON or legacy coordinates
+	Longitude         float64 `json:"longitude"`                   // Center point for near and circular within
+	Latitude          float64 `json:"latitude"`                    // Center point for near and circular within
+	MaxDistanceMeters float64 `json:"maxDistanceMeters,omitempty"` // near: max distance; within: circle radius
+	MinDistanceMeters float64 `json:"minDistanceMeters,omitempty"` // near only
+	Geometry          string  `json:"geometry,omitempty"`          // GeoJSON geometry for within/intersects
+}
+
+// GeoGeometry is a GeoJSON geometry.
+type GeoGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// GeoFeature is a GeoJSON feature referencing its source document.
+type GeoFeature struct {
+	Type       string                 `json:"type"` // Always "Feature"
+	Geometry   GeoGeometry            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"` // "id" holds the document _id as Extended JSON
+}
+
+// GeoPointsResult is a GeoJSON FeatureCollection of document locations.
+type GeoPointsResult struct {
+	Type     string       `json:"type"` // Always "FeatureCollection"
+	Features []GeoFeature `json:"features"`
+	Skipped  int          `json:"skipped"` // Documents whose field held no usable geometry
+}
+
+// SQLTranslation is the MongoDB equivalent of a SQL SELECT statement. Plain selects map to
+// find options; statements with aggregates, GROUP BY, or aliases produce a pipeline.
+type SQLTranslation struct {
+	Collection    string `json:"collection"`
+	Filter        string `json:"filter"`               // Extended JSON
+	Projection    string `json:"projection,omitempty"` // Extended JSON
+	Sort          string `json:"sort,omitempty"`       // QueryOptions sort format ("field,-field")
+	Skip          int64  `json:"skip,omitempty"`
+	Limit         int64  `json:"limit,omitempty"`
+	IsAggregation bool   `json:"isAggregation"`
+	Pipeline      string `json:"pipeline,omitempty"` // Extended JSON array of stages
+}
+
+// DistinctValue is a single distinct field value and the number of matching documents containing it.
+type DistinctValue struct {
+	Value string `json:"value"` // Extended JSON
+	Count int64  `json:"count"`
+}
+
+// DistinctValuesResult contains the distinct values of a field, most frequent first.
+type DistinctValuesResult struct {
+	Field     string          `json:"field"`
+	Values    []DistinctValue `json:"values"`
+	Truncated bool            `json:"truncated"` // More distinct values exist beyond the limit
 }
 
 // =============================================================================
@@ -317,6 +1345,7 @@ type SchemaResult struct {
 	SampleSize int                    `json:"sampleSize"`
 	TotalDocs  int64                  `json:"totalDocs"`
 	Fields     map[string]SchemaField `json:"fields"`
+	Search     *SearchCapabilities    `json:"search,omitempty"` // Text/Atlas Search index availability
 }
 
 // =============================================================================
@@ -357,6 +1386,8 @@ type ImportOptions struct {
 	Collections    []string `json:"collections"`    // Collections to import (empty = all, for collection-level imports)
 	SourceDatabase string   `json:"sourceDatabase"` // Source database in archive (for collection-level imports)
 	Mode           string   `json:"mode"`           // "skip" | "override"
+
+	WriteConcern *WriteConcern `json:"writeConcern,omitempty"` // nil keeps the connection default
 }
 
 // ImportPreview contains info about an import file for user selection.
@@ -428,6 +1459,7 @@ type ExportManifestCollection struct {
 	Name       string `json:"name"`
 	DocCount   int64  `json:"docCount"`
 	IndexCount int    `json:"indexCount"`
+	Options    string `json:"options,omitempty"` // Canonical Extended JSON create options (time-series, capped, validator, ...)
 }
 
 // CollectionsImportPreview contains info about an export file for collection import.
@@ -455,16 +1487,19 @@ type CollectionsImportPreviewItem struct {
 
 // JSONExportOptions specifies options for JSON export.
 type JSONExportOptions struct {
-	Filter   string `json:"filter"`   // Optional query filter in Extended JSON format
-	FilePath string `json:"filePath"` // Pre-selected file path; if provided, skip save dialog
-	Pretty   bool   `json:"pretty"`   // Pretty-print output
-	Array    bool   `json:"array"`    // If true, output as JSON array; if false, NDJSON (one doc per line)
+	Filter   string `json:"filter"`             // Optional query filter in Extended JSON format
+	Pipeline string `json:"pipeline,omitempty"` // Optional aggregation pipeline (Extended JSON array); replaces Filter as the data source
+	FilePath string `json:"filePath"`           // Pre-selected file path; if provided, skip save dialog
+	Pretty   bool   `json:"pretty"`             // Pretty-print output
+	Array    bool   `json:"array"`              // If true, output as JSON array; if false, NDJSON (one doc per line)
 }
 
 // JSONImportOptions specifies options for JSON import.
 type JSONImportOptions struct {
 	FilePath string `json:"filePath"` // Path to the JSON/NDJSON file
 	Mode     string `json:"mode"`     // "skip" | "override"
+
+	WriteConcern *WriteConcern `json:"writeConcern,omitempty"` // nil keeps the connection default
 }
 
 // JSONImportPreview contains info about a JSON file for user preview.
@@ -482,11 +1517,12 @@ type JSONImportPreview struct {
 
 // CSVExportOptions specifies options for CSV export.
 type CSVExportOptions struct {
-	Delimiter      string `json:"delimiter"`      // Field delimiter, defaults to comma
-	IncludeHeaders bool   `json:"includeHeaders"` // Whether to include column headers
-	FlattenArrays  bool   `json:"flattenArrays"`  // If true, join arrays with semicolon; if false, create JSON representation
-	Filter         string `json:"filter"`         // Optional query filter in Extended JSON format
-	FilePath       string `json:"filePath"`       // Pre-selected file path; if provided, skip save dialog
+	Delimiter      string `json:"delimiter"`          // Field delimiter, defaults to comma
+	IncludeHeaders bool   `json:"includeHeaders"`     // Whether to include column headers
+	FlattenArrays  bool   `json:"flattenArrays"`      // If true, join arrays with semicolon; if false, create JSON representation
+	Filter         string `json:"filter"`             // Optional query filter in Extended JSON format
+	Pipeline       string `json:"pipeline,omitempty"` // Optional aggregation pipeline (Extended JSON array); replaces Filter as the data source
+	FilePath       string `json:"filePath"`           // Pre-selected file path; if provided, skip save dialog
 }
 
 // =============================================================================
@@ -508,6 +1544,8 @@ type CSVImportOptions struct {
 	FieldNames    []string `json:"fieldNames"`    // Override headers (used if HasHeaders is false or user renames)
 	TypeInference bool     `json:"typeInference"` // Infer types from values
 	Mode          string   `json:"mode"`          // "skip" | "override"
+
+	WriteConcern *WriteConcern `json:"writeConcern,omitempty"` // nil keeps the connection default
 }
 
 // CSVImportPreview contains info about a CSV file for user preview.
@@ -593,17 +1631,177 @@ type ScriptResult struct {
 
 // SavedQuery represents a saved MongoDB query.
 type SavedQuery struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description,omitempty"`
+	ConnectionID string           `json:"connectionId"`
+	Database     string           `json:"database"`
+	Collection   string           `json:"collection"`
+	Query        string           `json:"query"` // May contain {{name}} placeholders
+	Parameters   []QueryParameter `json:"parameters,omitempty"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+}
+
+// QueryParameter defines a {{name}} placeholder in a saved query.
+type QueryParameter struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`              // "string", "int", "long", "double", "bool", "date", "objectId"; empty infers from the value
+	Default string `json:"default,omitempty"` // Used when no value is supplied; a parameter without a default is required
+}
+
+// SavedPipeline represents a named aggregation pipeline saved for a collection.
+type SavedPipeline struct {
 	ID           string    `json:"id"`
 	Name         string    `json:"name"`
 	Description  string    `json:"description,omitempty"`
 	ConnectionID string    `json:"connectionId"`
 	Database     string    `json:"database"`
 	Collection   string    `json:"collection"`
-	Query        string    `json:"query"`
+	Stages       []string  `json:"stages"` // One Extended JSON document per stage
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 }
 
+// DocumentTemplate is a skeleton document saved for a collection. Template is Extended JSON
+// that may contain generator tokens such as {{now}}, {{objectId}}, or {{string}}.
+type DocumentTemplate struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description,omitempty"`
+	ConnectionID string    `json:"connectionId"`
+	Database     string    `json:"database"`
+	Collection   string    `json:"collection"`
+	Template     string    `json:"template"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// QueryHistoryEntry records a single query executed against a collection.
+type QueryHistoryEntry struct {
+	ID           string    `json:"id"`
+	ConnectionID string    `json:"connectionId"`
+	Database     string    `json:"database"`
+	Collection   string    `json:"collection"`
+	Filter       string    `json:"filter"`
+	Sort         string    `json:"sort,omitempty"`
+	Projection   string    `json:"projection,omitempty"`
+	ExecutedAt   time.Time `json:"executedAt"`
+	QueryTimeMs  int64     `json:"queryTimeMs"`
+}
+
+// TrashEntry is a deleted document kept in the local trash so the delete can be undone.
+type TrashEntry struct {
+	ID           string    `json:"id"`
+	ConnectionID string    `json:"connectionId"`
+	Database     string    `json:"database"`
+	Collection   string    `json:"collection"`
+	DocumentID   string    `json:"documentId"` // _id as Extended JSON
+	Document     string    `json:"document"`   // Full document as canonical Extended JSON
+	DeletedAt    time.Time `json:"deletedAt"`
+}
+
+// TrashRestoreResult reports a restore from the trash.
+type TrashRestoreResult struct {
+	Restored int      `json:"restored"`
+	Errors   []string `json:"errors,omitempty"` // One message per entry that could not be restored
+}
+
+// SchemaViolation is a validator rule a document breaks.
+type SchemaViolation struct {
+	Path    string `json:"path"` // Dotted field path; empty for the document itself
+	Rule    string `json:"rule"` // $jsonSchema keyword (e.g. "bsonType", "required"), or "query"
+	Message string `json:"message"`
+}
+
+// InvalidDocument is a document the collection validator would reject.
+type InvalidDocument struct {
+	ID         string            `json:"id"` // _id as Extended JSON
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// DocumentValidationResult reports a check of sampled documents against a collection validator.
+type DocumentValidationResult struct {
+	HasValidator     bool              `json:"hasValidator"`
+	ValidationLevel  string            `json:"validationLevel,omitempty"`
+	ValidationAction string            `json:"validationAction,omitempty"`
+	Checked          int               `json:"checked"`
+	Invalid          []InvalidDocument `json:"invalid"`
+	Warnings         []string          `json:"warnings"` // Validator rules that could not be checked
+}
+
+// =============================================================================
+// GridFS Types
+// =============================================================================
+
+// GridFSFile describes a file stored in a GridFS bucket.
+type GridFSFile struct {
+	ID         string    `json:"id"` // _id as Extended JSON
+	Filename   string    `json:"filename"`
+	Length     int64     `json:"length"`
+	ChunkSize  int32     `json:"chunkSize"`
+	UploadDate time.Time `json:"uploadDate"`
+	Metadata   string    `json:"metadata,omitempty"` // Extended JSON
+}
+
+// GridFSPreview holds the leading bytes of a GridFS file.
+type GridFSPreview struct {
+	File        GridFSFile `json:"file"`
+	ContentType string     `json:"contentType"` // Sniffed from the content
+	IsText      bool       `json:"isText"`
+	Content     string     `json:"content"` // Text, or base64 when IsText is false
+	Truncated   bool       `json:"truncated"`
+}
+
+// =============================================================================
+// Change Stream Types
+// =============================================================================
+
+// ChangeStreamEvent is emitted for each change observed by a collection watch.
+type ChangeStreamEvent struct {
+	WatchID       string `json:"watchId"`
+	OperationType string `json:"operationType"`
+	DocumentKey   string `json:"documentKey,omitempty"` // Extended JSON
+	Event         string `json:"event"`                 // Full change event as Extended JSON
+	ResumeToken   string `json:"resumeToken"`           // Extended JSON, usable to resume the stream
+}
+
+// TailBatch is emitted with documents read from a tailed capped collection.
+type TailBatch struct {
+	TailID    string   `json:"tailId"`
+	Documents []string `json:"documents"` // Extended JSON, in insertion order
+	Backlog   bool     `json:"backlog"`   // Documents that existed when the tail started
+}
+
+// =============================================================================
+// Settings Types
+// =============================================================================
+
+// AppSettings holds global, user-configurable application settings.
+type AppSettings struct {
+	// EstimatedCountThreshold is the collection size above which empty-filter
+	// queries use EstimatedDocumentCount instead of an exact count. 0 disables.
+	EstimatedCountThreshold int64 `json:"estimatedCountThreshold"`
+	// QueryTimeoutSeconds is the default time limit for queries, enforced both
+	// client-side and server-side (maxTimeMS). 0 uses the built-in default.
+	QueryTimeoutSeconds int `json:"queryTimeoutSeconds"`
+	// OutputMode is the Extended JSON mode for query results: "canonical"
+	// preserves BSON types, "relaxed" renders plain numbers and ISO dates.
+	OutputMode string `json:"outputMode"`
+	// LocalDates renders dates in query results as ISO 8601 in the local
+	// timezone instead of UTC.
+	LocalDates bool `json:"localDates"`
+	// LegacyUUIDRepresentation is the byte order legacy subtype 3 UUIDs are read
+	// in for display: "pythonLegacy", "javaLegacy" or "csharpLegacy". Empty shows
+	// them as binary.
+	LegacyUUIDRepresentation string `json:"legacyUuidRepresentation"`
+	// ProdReadOnly makes every prod-tagged connection read-only.
+	ProdReadOnly bool `json:"prodReadOnly"`
+	// ProdDestructiveDelaySeconds is the minimum destructive delay of prod-tagged
+	// connections, which also always require typed confirmation. 0 disables the delay.
+	ProdDestructiveDelaySeconds int `json:"prodDestructiveDelaySeconds"`
+}
+
 // =============================================================================
 // Theme Types
 // =============================================================================
@@ -687,6 +1885,15 @@ type ServerInfo struct {
 	Errors         map[string]string `json:"errors,omitempty"`
 }
 
+// FeatureCompatibility is the feature compatibility version (FCV) of a deployment. While an
+// upgrade or downgrade is in progress, TargetVersion is the version being moved to.
+type FeatureCompatibility struct {
+	Version         string `json:"version"`
+	TargetVersion   string `json:"targetVersion,omitempty"`
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	ServerVersion   string `json:"serverVersion"`
+}
+
 // ServerHostInfo contains host system information.
 type ServerHostInfo struct {
 	Hostname string  `json:"hostname"`
@@ -734,3 +1941,84 @@ type ReplicaSetMember struct {
 	SyncSource string `json:"syncSource,omitempty"`
 	Self       bool   `json:"self"`
 }
+
+// ReplicationLag is a sample of how far each replica set member is behind the primary.
+type ReplicationLag struct {
+	ConnectionID  string      `json:"connectionId"`
+	Time          time.Time   `json:"time"`
+	SetName       string      `json:"setName"`
+	Primary       string      `json:"primary,omitempty"` // Empty while there is no primary
+	MaxLagSeconds float64     `json:"maxLagSeconds"`
+	Members       []MemberLag `json:"members"`
+}
+
+// MemberLag is the replication lag of one replica set member. Arbiters and members without
+// an optime have no lag.
+type MemberLag struct {
+	Name       string    `json:"name"`
+	StateStr   string    `json:"stateStr"`
+	Health     int       `json:"health"`
+	Self       bool      `json:"self"`
+	OptimeDate time.Time `json:"optimeDate"`
+	LagSeconds float64   `json:"lagSeconds"`
+	Lagging    bool      `json:"lagging"` // Lag exceeds the warning threshold
+}
+
+// ReplicaConsistencyReport compares the dbHash of one database across the data-bearing
+// members of a replica set.
+type ReplicaConsistencyReport struct {
+	Database   string               `json:"database"`
+	Members    []MemberHash         `json:"members"`
+	Mismatches []CollectionMismatch `json:"mismatches"`
+	Consistent bool                 `json:"consistent"` // Every member hashed and no collection differs
+}
+
+// MemberHash is the dbHash result of one replica set member.
+type MemberHash struct {
+	Host        string    `json:"host"`
+	State       string    `json:"state"`
+	OptimeDate  time.Time `json:"optimeDate"`
+	MD5         string    `json:"md5,omitempty"`
+	Collections int       `json:"collections"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// CollectionMismatch is a collection whose hash differs between members.
+type CollectionMismatch struct {
+	Collection string            `json:"collection"`
+	Hashes     map[string]string `json:"hashes"` // Member host -> hash; "" if the member lacks the collection
+}
+
+// ReplSetConfig is the configuration of a replica set, from replSetGetConfig.
+type ReplSetConfig struct {
+	SetName string                `json:"setName"`
+	Version int64                 `json:"version"`
+	Term    int64                 `json:"term"`
+	Members []ReplSetMemberConfig `json:"members"`
+}
+
+// ReplSetMemberConfig is the configuration of one replica set member.
+type ReplSetMemberConfig struct {
+	ID                 int               `json:"id"`
+	Host               string            `json:"host"`
+	Priority           float64           `json:"priority"`
+	Votes              int               `json:"votes"`
+	ArbiterOnly        bool              `json:"arbiterOnly"`
+	Hidden             bool              `json:"hidden"`
+	SecondaryDelaySecs int64             `json:"secondaryDelaySecs"`
+	Tags               map[string]string `json:"tags,omitempty"`
+}
+
+// LintIssue is a single problem found by LintQuery.
+type LintIssue struct {
+	Severity string `json:"severity"` // "error", "warning", or "info"
+	Code     string `json:"code"`     // Stable identifier, e.g. "unknown-operator"
+	Path     string `json:"path"`     // Location, e.g. "filter.age.$gte"
+	Message  string `json:"message"`
+}
+
+// LintResult contains the issues found in a query. Valid is false if any issue is an error.
+type LintResult struct {
+	Valid  bool        `json:"valid"`
+	Issues []LintIssue `json:"issues"`
+}
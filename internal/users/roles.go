@@ -0,0 +1,215 @@
+package users
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// ListRoles returns the roles defined in a database with their privileges and the roles and
+// privileges they inherit, sorted by name. Built-in roles are included with showBuiltin.
+func (s *Service) ListRoles(connID, dbName string, showBuiltin bool) ([]types.DatabaseRole, error) {
+	if err := validateUserDatabase(dbName); err != nil {
+		return nil, err
+	}
+
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cmd := bson.D{
+		{Key: "rolesInfo", Value: 1},
+		{Key: "showPrivileges", Value: true},
+		{Key: "showBuiltinRoles", Value: showBuiltin},
+		{Key: "showAuthenticationRestrictions", Value: true},
+	}
+	var result struct {
+		Roles []bson.M `bson:"roles"`
+	}
+	if err := client.Database(dbName).RunCommand(ctx, cmd).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	roles := make([]types.DatabaseRole, 0, len(result.Roles))
+	for _, doc := range result.Roles {
+		roles = append(roles, parseRole(doc))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Role < roles[j].Role })
+	return roles, nil
+}
+
+// CreateRole creates a custom role in a database. Inherited roles without a database are
+// taken from dbName.
+func (s *Service) CreateRole(connID, dbName string, role types.NewDatabaseRole) error {
+	if err := s.state.CheckWritable(connID, "create role"); err != nil {
+		return err
+	}
+
+	if err := validateUserDatabase(dbName); err != nil {
+		return err
+	}
+	if strings.TrimSpace(role.Role) == "" {
+		return fmt.Errorf("role name cannot be empty")
+	}
+	privileges, err := privilegeDocuments(role.Privileges)
+	if err != nil {
+		return err
+	}
+	roles, err := roleDocuments(role.Roles, dbName)
+	if err != nil {
+		return err
+	}
+
+	cmd := bson.D{
+		{Key: "createRole", Value: role.Role},
+		{Key: "privileges", Value: privileges},
+		{Key: "roles", Value: roles},
+	}
+	if len(role.AuthenticationRestrictions) > 0 {
+		cmd = append(cmd, bson.E{Key: "authenticationRestrictions", Value: restrictionDocuments(role.AuthenticationRestrictions)})
+	}
+
+	if err := s.runUserCommand(connID, dbName, cmd); err != nil {
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+	debug.LogConnection("Role created", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"role":         role.Role,
+	})
+	return nil
+}
+
+// UpdateRolePrivileges replaces the privileges of a custom role.
+func (s *Service) UpdateRolePrivileges(connID, dbName, roleName string, privileges []types.Privilege) error {
+	if err := s.state.CheckWritable(connID, "update role privileges"); err != nil {
+		return err
+	}
+
+	if err := validateUserDatabase(dbName); err != nil {
+		return err
+	}
+	if roleName == "" {
+		return fmt.Errorf("role name cannot be empty")
+	}
+	docs, err := privilegeDocuments(privileges)
+	if err != nil {
+		return err
+	}
+
+	cmd := bson.D{{Key: "updateRole", Value: roleName}, {Key: "privileges", Value: docs}}
+	if err := s.runUserCommand(connID, dbName, cmd); err != nil {
+		return fmt.Errorf("failed to update role privileges: %w", err)
+	}
+	return nil
+}
+
+// DropRole removes a custom role from a database. Users and roles granted it lose it.
+func (s *Service) DropRole(connID, dbName, roleName string) error {
+	if err := s.state.CheckWritable(connID, "drop role"); err != nil {
+		return err
+	}
+
+	if err := validateUserDatabase(dbName); err != nil {
+		return err
+	}
+	if roleName == "" {
+		return fmt.Errorf("role name cannot be empty")
+	}
+	if _, err := s.state.GetDatabaseClient(connID, dbName); err != nil {
+		return err
+	}
+	if err := s.state.RequireConfirmation(connID, "drop role", roleName+"@"+dbName); err != nil {
+		return err
+	}
+
+	if err := s.runUserCommand(connID, dbName, bson.D{{Key: "dropRole", Value: roleName}}); err != nil {
+		return fmt.Errorf("failed to drop role: %w", err)
+	}
+	debug.LogConnection("Role dropped", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"role":         roleName,
+	})
+	return nil
+}
+
+// privilegeDocuments validates privileges and converts them to the documents role commands
+// take.
+func privilegeDocuments(privileges []types.Privilege) (bson.A, error) {
+	docs := bson.A{}
+	for i, p := range privileges {
+		if len(p.Actions) == 0 {
+			return nil, fmt.Errorf("privilege %d has no actions", i+1)
+		}
+		for _, a := range p.Actions {
+			if strings.TrimSpace(a) == "" {
+				return nil, fmt.Errorf("privilege %d has an empty action", i+1)
+			}
+		}
+		r := p.Resource
+		var resource bson.D
+		switch {
+		case r.Cluster && r.AnyResource:
+			return nil, fmt.Errorf("privilege %d cannot apply to both the cluster and any resource", i+1)
+		case r.Cluster:
+			resource = bson.D{{Key: "cluster", Value: true}}
+		case r.AnyResource:
+			resource = bson.D{{Key: "anyResource", Value: true}}
+		default:
+			resource = bson.D{{Key: "db", Value: r.DB}, {Key: "collection", Value: r.Collection}}
+		}
+		docs = append(docs, bson.D{{Key: "resource", Value: resource}, {Key: "actions", Value: p.Actions}})
+	}
+	return docs, nil
+}
+
+// parseRole converts a rolesInfo role document.
+func parseRole(doc bson.M) types.DatabaseRole {
+	return types.DatabaseRole{
+		Role:                       bsonutil.ToString(doc["role"]),
+		DB:                         bsonutil.ToString(doc["db"]),
+		IsBuiltin:                  bsonutil.ToBool(doc["isBuiltin"]),
+		Roles:                      parseRoleRefs(doc["roles"]),
+		InheritedRoles:             parseRoleRefs(doc["inheritedRoles"]),
+		Privileges:                 parsePrivileges(doc["privileges"]),
+		InheritedPrivileges:        parsePrivileges(doc["inheritedPrivileges"]),
+		AuthenticationRestrictions: parseRestrictions(doc["authenticationRestrictions"]),
+	}
+}
+
+// parsePrivileges converts an array of {resource, actions} documents.
+func parsePrivileges(v interface{}) []types.Privilege {
+	privileges := []types.Privilege{}
+	arr, _ := v.(bson.A)
+	for _, item := range arr {
+		m, ok := item.(bson.M)
+		if !ok {
+			continue
+		}
+		p := types.Privilege{Actions: toStrings(m["actions"])}
+		if r, ok := m["resource"].(bson.M); ok {
+			p.Resource = types.PrivilegeResource{
+				DB:          bsonutil.ToString(r["db"]),
+				Collection:  bsonutil.ToString(r["collection"]),
+				Cluster:     bsonutil.ToBool(r["cluster"]),
+				AnyResource: bsonutil.ToBool(r["anyResource"]),
+			}
+		}
+		if p.Actions == nil {
+			p.Actions = []string{}
+		}
+		privileges = append(privileges, p)
+	}
+	return privileges
+}
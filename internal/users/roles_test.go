@@ -0,0 +1,94 @@
+package users
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestPrivilegeDocuments(t *testing.T) {
+	docs, err := privilegeDocuments([]types.Privilege{
+		{Resource: types.PrivilegeResource{DB: "shop", Collection: "orders"}, Actions: []string{"find", "update"}},
+		{Resource: types.PrivilegeResource{DB: "shop"}, Actions: []string{"listCollections"}},
+		{Resource: types.PrivilegeResource{Cluster: true}, Actions: []string{"serverStatus"}},
+	})
+	if err != nil {
+		t.Fatalf("privilegeDocuments: %v", err)
+	}
+	want := bson.A{
+		bson.D{{Key: "resource", Value: bson.D{{Key: "db", Value: "shop"}, {Key: "collection", Value: "orders"}}}, {Key: "actions", Value: []string{"find", "update"}}},
+		bson.D{{Key: "resource", Value: bson.D{{Key: "db", Value: "shop"}, {Key: "collection", Value: ""}}}, {Key: "actions", Value: []string{"listCollections"}}},
+		bson.D{{Key: "resource", Value: bson.D{{Key: "cluster", Value: true}}}, {Key: "actions", Value: []string{"serverStatus"}}},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("privilegeDocuments = %v, want %v", docs, want)
+	}
+
+	invalid := []struct {
+		name      string
+		privilege types.Privilege
+		errMsg    string
+	}{
+		{"no actions", types.Privilege{Resource: types.PrivilegeResource{DB: "shop"}}, "no actions"},
+		{"empty action", types.Privilege{Resource: types.PrivilegeResource{DB: "shop"}, Actions: []string{" "}}, "empty action"},
+		{"cluster and any", types.Privilege{Resource: types.PrivilegeResource{Cluster: true, AnyResource: true}, Actions: []string{"find"}}, "both"},
+	}
+	for _, tt := range invalid {
+		if _, err := privilegeDocuments([]types.Privilege{tt.privilege}); err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: error = %v, want containing %q", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	role := parseRole(bson.M{
+		"role":           "orderManager",
+		"db":             "shop",
+		"isBuiltin":      false,
+		"roles":          bson.A{bson.M{"role": "read", "db": "shop"}},
+		"inheritedRoles": bson.A{bson.M{"role": "read", "db": "shop"}},
+		"privileges": bson.A{
+			bson.M{"resource": bson.M{"db": "shop", "collection": "orders"}, "actions": bson.A{"update"}},
+		},
+		"inheritedPrivileges": bson.A{
+			bson.M{"resource": bson.M{"db": "shop", "collection": "orders"}, "actions": bson.A{"update"}},
+			bson.M{"resource": bson.M{"db": "shop", "collection": ""}, "actions": bson.A{"find"}},
+		},
+	})
+	if role.Role != "orderManager" || role.DB != "shop" || role.IsBuiltin {
+		t.Errorf("unexpected role: %+v", role)
+	}
+	if len(role.InheritedRoles) != 1 || len(role.Privileges) != 1 || len(role.InheritedPrivileges) != 2 {
+		t.Errorf("unexpected inheritance: %+v", role)
+	}
+	if p := role.Privileges[0]; p.Resource.Collection != "orders" || !reflect.DeepEqual(p.Actions, []string{"update"}) {
+		t.Errorf("unexpected privilege: %+v", p)
+	}
+}
+
+func TestRoleValidation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	s := NewService(state)
+
+	var readOnly *core.ReadOnlyError
+	if err := s.DropRole("ro", "shop", "orderManager"); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+	if err := s.CreateRole("conn-1", "shop", types.NewDatabaseRole{}); err == nil || !strings.Contains(err.Error(), "role name") {
+		t.Errorf("expected role name error, got %v", err)
+	}
+	if err := s.UpdateRolePrivileges("conn-1", "shop", "r", []types.Privilege{{}}); err == nil || !strings.Contains(err.Error(), "no actions") {
+		t.Errorf("expected privilege error, got %v", err)
+	}
+	var notConnected *core.NotConnectedError
+	if _, err := s.ListRoles("conn-1", "shop", false); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
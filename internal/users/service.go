@@ -0,0 +1,341 @@
+// Package users manages the database users and custom roles of a server.
+package users
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/peternagy/mongopal/internal/bsonutil"
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/database"
+	"github.com/peternagy/mongopal/internal/debug"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+// externalDatabase holds users that authenticate externally (LDAP, Kerberos, X.509).
+const externalDatabase = "$external"
+
+// Service handles user and role management.
+type Service struct {
+	state *core.AppState
+}
+
+// NewService creates a new users service.
+func NewService(state *core.AppState) *Service {
+	return &Service{state: state}
+}
+
+// ListUsers returns the users defined in a database with their roles and authentication
+// restrictions, sorted by name. An empty dbName lists the users of every database the
+// connection may access.
+func (s *Service) ListUsers(connID, dbName string) ([]types.DatabaseUser, error) {
+	cmdDB := dbName
+	usersInfo := interface{}(1)
+	if dbName == "" {
+		// Users of databases the connection may not access are filtered out below
+		cmdDB = "admin"
+		usersInfo = bson.D{{Key: "forAllDBs", Value: true}}
+	} else if err := validateUserDatabase(dbName); err != nil {
+		return nil, err
+	}
+
+	var client *mongo.Client
+	var err error
+	if dbName == "" {
+		client, err = s.state.GetClient(connID)
+	} else {
+		client, err = s.state.GetDatabaseClient(connID, dbName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	cmd := bson.D{
+		{Key: "usersInfo", Value: usersInfo},
+		{Key: "showAuthenticationRestrictions", Value: true},
+	}
+	var result struct {
+		Users []bson.M `bson:"users"`
+	}
+	if err := client.Database(cmdDB).RunCommand(ctx, cmd).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users := make([]types.DatabaseUser, 0, len(result.Users))
+	for _, doc := range result.Users {
+		user := parseUser(doc)
+		if dbName == "" && !s.state.DatabaseAllowed(connID, user.DB) {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].DB != users[j].DB {
+			return users[i].DB < users[j].DB
+		}
+		return users[i].User < users[j].User
+	})
+	return users, nil
+}
+
+// CreateUser creates a user in a database. Roles without a database are granted on dbName.
+func (s *Service) CreateUser(connID, dbName string, user types.NewDatabaseUser) error {
+	if err := s.state.CheckWritable(connID, "create user"); err != nil {
+		return err
+	}
+
+	if err := validateUserDatabase(dbName); err != nil {
+		return err
+	}
+	if strings.TrimSpace(user.User) == "" {
+		return fmt.Errorf("username cannot be empty")
+	}
+	if dbName == externalDatabase {
+		if user.Password != "" {
+			return fmt.Errorf("users of %s authenticate externally and cannot have a password", externalDatabase)
+		}
+	} else if user.Password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	roles, err := roleDocuments(user.Roles, dbName)
+	if err != nil {
+		return err
+	}
+
+	cmd := bson.D{{Key: "createUser", Value: user.User}}
+	if user.Password != "" {
+		cmd = append(cmd, bson.E{Key: "pwd", Value: user.Password})
+	}
+	cmd = append(cmd, bson.E{Key: "roles", Value: roles})
+	if len(user.Mechanisms) > 0 {
+		cmd = append(cmd, bson.E{Key: "mechanisms", Value: user.Mechanisms})
+	}
+	if len(user.AuthenticationRestrictions) > 0 {
+		cmd = append(cmd, bson.E{Key: "authenticationRestrictions", Value: restrictionDocuments(user.AuthenticationRestrictions)})
+	}
+	if strings.TrimSpace(user.CustomData) != "" {
+		var customData bson.D
+		if err := bsonutil.UnmarshalExtJSON([]byte(user.CustomData), false, &customData); err != nil {
+			return fmt.Errorf("invalid custom data: %w", err)
+		}
+		cmd = append(cmd, bson.E{Key: "customData", Value: customData})
+	}
+
+	if err := s.runUserCommand(connID, dbName, cmd); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	debug.LogConnection("User created", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"user":         user.User,
+	})
+	return nil
+}
+
+// UpdateUserRoles replaces the roles of a user. Roles without a database are granted on
+// dbName.
+func (s *Service) UpdateUserRoles(connID, dbName, username string, roles []types.RoleRef) error {
+	if err := s.state.CheckWritable(connID, "update user roles"); err != nil {
+		return err
+	}
+
+	if err := validateUserDatabase(dbName); err != nil {
+		return err
+	}
+	if username == "" {
+		return fmt.Errorf("username cannot be empty")
+	}
+	roleDocs, err := roleDocuments(roles, dbName)
+	if err != nil {
+		return err
+	}
+
+	cmd := bson.D{{Key: "updateUser", Value: username}, {Key: "roles", Value: roleDocs}}
+	if err := s.runUserCommand(connID, dbName, cmd); err != nil {
+		return fmt.Errorf("failed to update user roles: %w", err)
+	}
+	return nil
+}
+
+// ChangeUserPassword sets a new password for a user.
+func (s *Service) ChangeUserPassword(connID, dbName, username, password string) error {
+	if err := s.state.CheckWritable(connID, "change user password"); err != nil {
+		return err
+	}
+
+	if err := validateUserDatabase(dbName); err != nil {
+		return err
+	}
+	if dbName == externalDatabase {
+		return fmt.Errorf("users of %s authenticate externally and have no password", externalDatabase)
+	}
+	if username == "" {
+		return fmt.Errorf("username cannot be empty")
+	}
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+
+	cmd := bson.D{{Key: "updateUser", Value: username}, {Key: "pwd", Value: password}}
+	if err := s.runUserCommand(connID, dbName, cmd); err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+	debug.LogConnection("User password changed", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"user":         username,
+	})
+	return nil
+}
+
+// DropUser removes a user from a database.
+func (s *Service) DropUser(connID, dbName, username string) error {
+	if err := s.state.CheckWritable(connID, "drop user"); err != nil {
+		return err
+	}
+
+	if err := validateUserDatabase(dbName); err != nil {
+		return err
+	}
+	if username == "" {
+		return fmt.Errorf("username cannot be empty")
+	}
+	if _, err := s.state.GetDatabaseClient(connID, dbName); err != nil {
+		return err
+	}
+	if err := s.state.RequireConfirmation(connID, "drop user", username+"@"+dbName); err != nil {
+		return err
+	}
+
+	if err := s.runUserCommand(connID, dbName, bson.D{{Key: "dropUser", Value: username}}); err != nil {
+		return fmt.Errorf("failed to drop user: %w", err)
+	}
+	debug.LogConnection("User dropped", map[string]interface{}{
+		"connectionId": connID,
+		"database":     dbName,
+		"user":         username,
+	})
+	return nil
+}
+
+// runUserCommand runs a user or role management command on dbName.
+func (s *Service) runUserCommand(connID, dbName string, cmd bson.D) error {
+	client, err := s.state.GetDatabaseClient(connID, dbName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.state.ContextWithTimeout()
+	defer cancel()
+
+	return client.Database(dbName).RunCommand(ctx, cmd).Err()
+}
+
+// validateUserDatabase checks the database a user or role is defined in.
+func validateUserDatabase(dbName string) error {
+	if dbName == externalDatabase {
+		return nil
+	}
+	return database.ValidateDatabaseName(dbName)
+}
+
+// roleDocuments converts role references to the documents user commands take, defaulting
+// their database to dbName.
+func roleDocuments(roles []types.RoleRef, dbName string) (bson.A, error) {
+	docs := bson.A{}
+	for _, r := range roles {
+		if strings.TrimSpace(r.Role) == "" {
+			return nil, fmt.Errorf("role name cannot be empty")
+		}
+		db := r.DB
+		if db == "" {
+			db = dbName
+		}
+		docs = append(docs, bson.D{{Key: "role", Value: r.Role}, {Key: "db", Value: db}})
+	}
+	return docs, nil
+}
+
+// restrictionDocuments converts authentication restrictions to command documents.
+func restrictionDocuments(restrictions []types.AuthenticationRestriction) bson.A {
+	docs := bson.A{}
+	for _, r := range restrictions {
+		doc := bson.D{}
+		if len(r.ClientSource) > 0 {
+			doc = append(doc, bson.E{Key: "clientSource", Value: r.ClientSource})
+		}
+		if len(r.ServerAddress) > 0 {
+			doc = append(doc, bson.E{Key: "serverAddress", Value: r.ServerAddress})
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// parseUser converts a usersInfo user document.
+func parseUser(doc bson.M) types.DatabaseUser {
+	user := types.DatabaseUser{
+		User:                       bsonutil.ToString(doc["user"]),
+		DB:                         bsonutil.ToString(doc["db"]),
+		Roles:                      parseRoleRefs(doc["roles"]),
+		Mechanisms:                 toStrings(doc["mechanisms"]),
+		AuthenticationRestrictions: parseRestrictions(doc["authenticationRestrictions"]),
+	}
+	if custom, ok := doc["customData"].(bson.M); ok && len(custom) > 0 {
+		if data, err := bson.MarshalExtJSON(custom, false, false); err == nil {
+			user.CustomData = string(data)
+		}
+	}
+	return user
+}
+
+// parseRoleRefs converts an array of {role, db} documents.
+func parseRoleRefs(v interface{}) []types.RoleRef {
+	refs := []types.RoleRef{}
+	arr, _ := v.(bson.A)
+	for _, item := range arr {
+		if m, ok := item.(bson.M); ok {
+			refs = append(refs, types.RoleRef{Role: bsonutil.ToString(m["role"]), DB: bsonutil.ToString(m["db"])})
+		}
+	}
+	return refs
+}
+
+// parseRestrictions converts authentication restriction documents. rolesInfo nests them in
+// one array per inherited role, so nested arrays are flattened.
+func parseRestrictions(v interface{}) []types.AuthenticationRestriction {
+	var restrictions []types.AuthenticationRestriction
+	arr, _ := v.(bson.A)
+	for _, item := range arr {
+		switch r := item.(type) {
+		case bson.M:
+			restrictions = append(restrictions, types.AuthenticationRestriction{
+				ClientSource:  toStrings(r["clientSource"]),
+				ServerAddress: toStrings(r["serverAddress"]),
+			})
+		case bson.A:
+			restrictions = append(restrictions, parseRestrictions(r)...)
+		}
+	}
+	return restrictions
+}
+
+// toStrings converts an array of strings.
+func toStrings(v interface{}) []string {
+	arr, _ := v.(bson.A)
+	if len(arr) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(arr))
+	for _, item := range arr {
+		out = append(out, bsonutil.ToString(item))
+	}
+	return out
+}
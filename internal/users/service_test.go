@@ -0,0 +1,101 @@
+package users
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/peternagy/mongopal/internal/core"
+	"github.com/peternagy/mongopal/internal/types"
+)
+
+func TestParseUser(t *testing.T) {
+	user := parseUser(bson.M{
+		"user":       "reporter",
+		"db":         "shop",
+		"roles":      bson.A{bson.M{"role": "read", "db": "shop"}, bson.M{"role": "clusterMonitor", "db": "admin"}},
+		"mechanisms": bson.A{"SCRAM-SHA-256"},
+		"authenticationRestrictions": bson.A{
+			bson.M{"clientSource": bson.A{"10.0.0.0/8"}},
+		},
+		"customData": bson.M{"team": "bi"},
+	})
+	wantRoles := []types.RoleRef{{Role: "read", DB: "shop"}, {Role: "clusterMonitor", DB: "admin"}}
+	if user.User != "reporter" || user.DB != "shop" || !reflect.DeepEqual(user.Roles, wantRoles) {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	if !reflect.DeepEqual(user.Mechanisms, []string{"SCRAM-SHA-256"}) {
+		t.Errorf("mechanisms = %v", user.Mechanisms)
+	}
+	if len(user.AuthenticationRestrictions) != 1 || user.AuthenticationRestrictions[0].ClientSource[0] != "10.0.0.0/8" {
+		t.Errorf("restrictions = %+v", user.AuthenticationRestrictions)
+	}
+	if user.CustomData != `{"team":"bi"}` {
+		t.Errorf("customData = %s", user.CustomData)
+	}
+
+	// rolesInfo nests restrictions per inherited role
+	nested := parseRestrictions(bson.A{bson.A{bson.M{"serverAddress": bson.A{"127.0.0.1"}}}})
+	if len(nested) != 1 || nested[0].ServerAddress[0] != "127.0.0.1" {
+		t.Errorf("nested restrictions = %+v", nested)
+	}
+}
+
+func TestRoleDocuments(t *testing.T) {
+	docs, err := roleDocuments([]types.RoleRef{{Role: "readWrite"}, {Role: "read", DB: "reports"}}, "shop")
+	if err != nil {
+		t.Fatalf("roleDocuments: %v", err)
+	}
+	want := bson.A{
+		bson.D{{Key: "role", Value: "readWrite"}, {Key: "db", Value: "shop"}},
+		bson.D{{Key: "role", Value: "read"}, {Key: "db", Value: "reports"}},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("roleDocuments = %v, want %v", docs, want)
+	}
+	if _, err := roleDocuments([]types.RoleRef{{DB: "shop"}}, "shop"); err == nil {
+		t.Error("expected error for role without name")
+	}
+}
+
+func TestUserValidation(t *testing.T) {
+	state := core.NewAppState()
+	state.SavedConnections = []types.SavedConnection{{ID: "ro", ReadOnly: true}}
+	s := NewService(state)
+
+	var readOnly *core.ReadOnlyError
+	if err := s.CreateUser("ro", "shop", types.NewDatabaseUser{User: "a", Password: "secret"}); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+	if err := s.DropUser("ro", "shop", "a"); !errors.As(err, &readOnly) {
+		t.Errorf("expected ReadOnlyError, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		errMsg string
+	}{
+		{"no username", s.CreateUser("conn-1", "shop", types.NewDatabaseUser{Password: "secret"}), "username"},
+		{"no password", s.CreateUser("conn-1", "shop", types.NewDatabaseUser{User: "a"}), "password"},
+		{"external with password", s.CreateUser("conn-1", "$external", types.NewDatabaseUser{User: "CN=a", Password: "x"}), "externally"},
+		{"bad database", s.CreateUser("conn-1", "a/b", types.NewDatabaseUser{User: "a", Password: "x"}), "invalid database name"},
+		{"bad custom data", s.CreateUser("conn-1", "shop", types.NewDatabaseUser{User: "a", Password: "x", CustomData: "{bad"}), "custom data"},
+		{"external password change", s.ChangeUserPassword("conn-1", "$external", "CN=a", "x"), "externally"},
+		{"empty password change", s.ChangeUserPassword("conn-1", "shop", "a", ""), "password"},
+		{"roles without user", s.UpdateUserRoles("conn-1", "shop", "", nil), "username"},
+	}
+	for _, tt := range tests {
+		if tt.err == nil || !strings.Contains(tt.err.Error(), tt.errMsg) {
+			t.Errorf("%s: error = %v, want containing %q", tt.name, tt.err, tt.errMsg)
+		}
+	}
+
+	var notConnected *core.NotConnectedError
+	if _, err := s.ListUsers("conn-1", ""); !errors.As(err, &notConnected) {
+		t.Errorf("expected NotConnectedError, got %v", err)
+	}
+}
@@ -59,7 +59,7 @@ install_system_deps() {
 
     case "$PKG_MGR" in
         pacman)
-            local pkgs="gtk3 webkit2gtk base-devel"
+            local pkgs="gtk3 webkit2gtk base-devel krb5"
             info "Installing: $pkgs"
             sudo pacman -S --needed --noconfirm $pkgs
             ;;
@@ -67,14 +67,14 @@ install_system_deps() {
             sudo apt-get update
             if apt-cache show libwebkit2gtk-4.1-dev &>/dev/null; then
                 info "Using webkit2gtk-4.1 (modern)"
-                sudo apt-get install -y build-essential libgtk-3-dev libwebkit2gtk-4.1-dev
+                sudo apt-get install -y build-essential libgtk-3-dev libwebkit2gtk-4.1-dev libkrb5-dev
             else
                 info "Using webkit2gtk-4.0"
-                sudo apt-get install -y build-essential libgtk-3-dev libwebkit2gtk-4.0-dev
+                sudo apt-get install -y build-essential libgtk-3-dev libwebkit2gtk-4.0-dev libkrb5-dev
             fi
             ;;
         dnf)
-            sudo dnf install -y gtk3-devel webkit2gtk4.1-devel gcc-c++
+            sudo dnf install -y gtk3-devel webkit2gtk4.1-devel gcc-c++ krb5-devel
             ;;
         brew)
             if ! xcode-select -p &>/dev/null; then